@@ -0,0 +1,144 @@
+package lexer
+
+import (
+	"unicode"
+)
+
+/*
+CharClass is a set of characters compiled for fast membership tests.
+Characters below 256 are kept in a bitmap, so classifying ASCII and
+Latin-1 input is a single table lookup. Other characters fall back to
+the ranges and unicode.RangeTable values the class was built from, and
+for a class made by Not, to the class it is the complement of.
+
+Build a class once, for example as a package level variable, and use
+it with Lexer.AcceptClass, Lexer.AcceptClassRun, and Lexer.IsClass.
+*/
+type CharClass struct {
+	bitmap   [4]uint64
+	ranges   []unicode.Range32
+	tables   []*unicode.RangeTable
+	excluded *CharClass
+}
+
+var (
+	// Whitespace characters, as defined by unicode.IsSpace
+	WhitespaceClass = NewCharClass("").AddTable(unicode.White_Space)
+
+	// ASCII digits 0-9
+	DigitClass = NewCharClass("").AddRange('0', '9')
+
+	// Hexadecimal digits 0-9, a-f, and A-F
+	HexDigitClass = NewCharClass("").AddRange('0', '9').AddRange('a', 'f').AddRange('A', 'F')
+
+	// Numeric characters, as defined by unicode.IsNumber
+	NumberClass = NewCharClass("").AddTable(unicode.Number)
+
+	// Letters, as defined by unicode.IsLetter
+	LetterClass = NewCharClass("").AddTable(unicode.Letter)
+
+	// Letters, numbers, and underscore, as commonly allowed in identifiers
+	IdentifierClass = NewCharClass("_").AddTable(unicode.Letter, unicode.Number)
+)
+
+/*
+NewCharClass creates a character class containing each character in chars
+*/
+func NewCharClass(chars string) *CharClass {
+	class := &CharClass{}
+
+	for _, ch := range chars {
+		class.AddRange(ch, ch)
+	}
+
+	return class
+}
+
+/*
+AddRange adds every character from lo to hi, inclusive, to the class.
+Negative characters are left out, as no input holds them. It returns the
+class so calls can be chained.
+*/
+func (class *CharClass) AddRange(lo, hi rune) *CharClass {
+	if lo < 0 {
+		lo = 0
+	}
+
+	for ch := lo; ch <= hi && ch < 256; ch++ {
+		class.bitmap[ch>>6] |= 1 << (uint(ch) & 63)
+	}
+
+	if hi >= 256 {
+		if lo < 256 {
+			lo = 256
+		}
+
+		class.ranges = append(class.ranges, unicode.Range32{Lo: uint32(lo), Hi: uint32(hi), Stride: 1})
+	}
+
+	return class
+}
+
+/*
+AddTable adds every character in the given Unicode tables to the class.
+It returns the class so calls can be chained.
+*/
+func (class *CharClass) AddTable(tables ...*unicode.RangeTable) *CharClass {
+	for ch := rune(0); ch < 256; ch++ {
+		if unicode.IsOneOf(tables, ch) {
+			class.bitmap[ch>>6] |= 1 << (uint(ch) & 63)
+		}
+	}
+
+	class.tables = append(class.tables, tables...)
+	return class
+}
+
+/*
+Contains returns true if ch is a member of the class. EOF is never a
+member of a class.
+*/
+func (class *CharClass) Contains(ch rune) bool {
+	if ch == EOF {
+		return false
+	}
+
+	if ch >= 0 && ch < 256 {
+		return class.bitmap[ch>>6]&(1<<(uint(ch)&63)) != 0
+	}
+
+	return class.containsWide(ch)
+}
+
+/*
+Not returns a new class containing every character not in this class.
+The new class is a class like any other: adding to it adds characters,
+and as it keeps a copy of this class, adding to this class later leaves
+it as it is.
+*/
+func (class *CharClass) Not() *CharClass {
+	excluded := *class
+	excluded.ranges = append([]unicode.Range32(nil), class.ranges...)
+	excluded.tables = append([]*unicode.RangeTable(nil), class.tables...)
+
+	result := &CharClass{excluded: &excluded}
+	for index, bits := range class.bitmap {
+		result.bitmap[index] = ^bits
+	}
+
+	return result
+}
+
+func (class *CharClass) containsWide(ch rune) bool {
+	for _, r := range class.ranges {
+		if uint32(ch) >= r.Lo && uint32(ch) <= r.Hi {
+			return true
+		}
+	}
+
+	if unicode.IsOneOf(class.tables, ch) {
+		return true
+	}
+
+	return class.excluded != nil && !class.excluded.containsWide(ch)
+}
@@ -0,0 +1,85 @@
+package lexer
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestNotSharesNothing(t *testing.T) {
+	class := NewCharClass("").AddRange('Ā', 'ſ').AddRange('Ѐ', 'ӿ').AddRange('Ա', '֏')
+	negated := class.Not()
+
+	negated.AddRange('一', '鿿')
+	class.AddRange('α', 'ω')
+
+	tests := []struct {
+		class *CharClass
+		ch    rune
+		want  bool
+	}{
+		{class, 'Ж', true},
+		{class, 'β', true},
+		{class, '中', false},
+		{negated, 'Ж', false},
+		{negated, 'β', true},
+		{negated, '中', true},
+		{negated, 'a', true},
+	}
+
+	for _, test := range tests {
+		if got := test.class.Contains(test.ch); got != test.want {
+			t.Errorf("Contains(%q) of %p: got %t, want %t", test.ch, test.class, got, test.want)
+		}
+	}
+}
+
+func TestAddToNot(t *testing.T) {
+	class := NewCharClass("abc").AddRange('Ā', 'ſ').AddTable(unicode.Greek)
+	negated := class.Not().AddRange('b', 'b').AddRange('ŀ', 'ł').AddTable(unicode.Greek)
+	twice := negated.Not()
+
+	tests := []struct {
+		class *CharClass
+		ch    rune
+		want  bool
+	}{
+		{negated, 'a', false},
+		{negated, 'b', true},
+		{negated, 'd', true},
+		{negated, 'Ā', false},
+		{negated, 'ŀ', true},
+		{negated, 'β', true},
+		{negated, 'Ж', true},
+		{twice, 'a', true},
+		{twice, 'b', false},
+		{twice, 'd', false},
+		{twice, 'Ā', true},
+		{twice, 'ŀ', false},
+		{twice, 'β', false},
+		{twice, 'Ж', false},
+	}
+
+	for _, test := range tests {
+		if got := test.class.Contains(test.ch); got != test.want {
+			t.Errorf("Contains(%q) of %p: got %t, want %t", test.ch, test.class, got, test.want)
+		}
+	}
+}
+
+func TestAddRangeBelowZero(t *testing.T) {
+	class := NewCharClass("").AddRange(-10, 'b')
+
+	for _, ch := range []rune{1, 'a', 'b'} {
+		if !class.Contains(ch) {
+			t.Errorf("Contains(%q): got false, want true", ch)
+		}
+	}
+
+	if class.Contains('c') {
+		t.Errorf("Contains('c'): got true, want false")
+	}
+
+	if empty := NewCharClass("").AddRange(-10, -1); empty.Contains(1) {
+		t.Errorf("a range below zero added %q", rune(1))
+	}
+}
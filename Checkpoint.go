@@ -0,0 +1,151 @@
+package lexer
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+/*
+Checkpoint records where a lexer was, so that a long run can be
+suspended, persisted, and resumed in another process. Every field is
+exported and made of plain values, so a checkpoint can be saved with
+encoding/gob or encoding/json:
+
+	checkpoint, err := l.Checkpoint()
+	...
+	err = gob.NewEncoder(file).Encode(checkpoint)
+
+Offset, Line and LineStart describe the physical input: the byte offset
+lexing resumes at, the line it is on, and the offset that line starts
+at. Position is the same place as reported in token spans, after any
+line directives. State and Modes hold the registered names of the
+running state and the mode stack, bottom first.
+*/
+type Checkpoint struct {
+	Name      string
+	Offset    int
+	Line      int
+	LineStart int
+	Position  Position
+	State     string
+	Modes     []string
+}
+
+/*
+ResumeLexer creates a lexer that continues from checkpoint over input,
+which must be the same input the checkpoint was taken from
+*/
+func ResumeLexer(checkpoint Checkpoint, input string, options ...Option) (*Lexer, error) {
+	if checkpoint.Offset < 0 || checkpoint.Offset > len(input) {
+		return nil, fmt.Errorf("lexer: checkpoint offset %d is outside the input", checkpoint.Offset)
+	}
+
+	l := NewLexer(checkpoint.Name, input, nil, options...)
+	l.Start = checkpoint.Offset
+	l.Pos = checkpoint.Offset
+	l.cursorOffset = checkpoint.Offset
+	l.cursorLine = checkpoint.Line
+	l.cursorLineStart = checkpoint.LineStart
+
+	if err := l.restore(checkpoint); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+/*
+ResumeReaderLexer creates a lexer that continues from checkpoint,
+reading the rest of the input from reader. The reader must be
+positioned at the checkpoint's Offset, such as a file after a Seek to
+that offset, which is what allows resuming the ingestion of a huge log
+without reading it from the beginning.
+*/
+func ResumeReaderLexer(checkpoint Checkpoint, reader io.Reader, options ...Option) (*Lexer, error) {
+	l := NewReaderLexer(checkpoint.Name, reader, nil, options...)
+	l.base = checkpoint.Offset
+	l.baseLine = checkpoint.Line - 1
+	l.baseLineStart = checkpoint.LineStart
+
+	if err := l.restore(checkpoint); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+/*
+restore sets the state, mode stack and line mapping of a lexer
+positioned at the checkpoint's offset
+*/
+func (lexer *Lexer) restore(checkpoint Checkpoint) error {
+	var err error
+
+	if lexer.State, err = lookupCheckpointState(checkpoint.State); err != nil {
+		return err
+	}
+
+	for _, name := range checkpoint.Modes {
+		state, err := lookupCheckpointState(name)
+
+		if err != nil {
+			return err
+		}
+
+		lexer.modes = append(lexer.modes, state)
+	}
+
+	position := checkpoint.Position
+
+	column := checkpoint.Offset - checkpoint.LineStart + 1
+
+	if position.Filename != checkpoint.Name || position.Line != checkpoint.Line || position.Column != column {
+		lexer.remaps = append(lexer.remaps, lineRemap{
+			offset:       checkpoint.Offset,
+			physicalLine: checkpoint.Line,
+			filename:     position.Filename,
+			line:         position.Line,
+			column:       position.Column,
+		})
+	}
+
+	return nil
+}
+
+/*
+lookupCheckpointState finds a state by its registered name. The empty
+name stands for a lexer that had already finished.
+*/
+func lookupCheckpointState(name string) (LexFn, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	state, ok := LookupState(name)
+
+	if !ok {
+		return nil, fmt.Errorf("lexer: state %q in checkpoint is not registered with NameState", name)
+	}
+
+	return state, nil
+}
+
+/*
+registeredStateName returns the name a state was registered under, as
+only registered states can be found again when a checkpoint is
+restored
+*/
+func registeredStateName(state LexFn) (string, error) {
+	if state == nil {
+		return "", nil
+	}
+
+	name := StateName(state)
+
+	if registered, ok := LookupState(name); ok && reflect.ValueOf(registered).Pointer() == reflect.ValueOf(state).Pointer() {
+		return name, nil
+	}
+
+	return "", fmt.Errorf("lexer: state %s must be registered with NameState to be checkpointed", name)
+}
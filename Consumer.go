@@ -0,0 +1,66 @@
+package lexer
+
+/*
+Consumer receives the tokens of one lexing pass over a source. Begin is
+called with the lexer's name before the first token, Token with every
+token in order, including the EOF token, and End once the pass is over
+with the error that stopped reading the input, if any. Highlighters,
+indexers and linters written as consumers can share a single
+tokenization of each file through a Dispatcher.
+*/
+type Consumer interface {
+	Begin(source string)
+	Token(token Token)
+	End(err error)
+}
+
+/*
+Dispatcher drives a set of consumers from one lexing pass. Each token is
+handed to the consumers in the order they were registered, before the
+next token is lexed, so consumers must copy any token text they keep
+from lexers reading from a reader.
+*/
+type Dispatcher struct {
+	consumers []Consumer
+}
+
+/*
+NewDispatcher creates a dispatcher driving consumers
+*/
+func NewDispatcher(consumers ...Consumer) *Dispatcher {
+	return &Dispatcher{consumers: consumers}
+}
+
+/*
+Register adds consumer to those driven by later passes
+*/
+func (dispatcher *Dispatcher) Register(consumer Consumer) {
+	dispatcher.consumers = append(dispatcher.consumers, consumer)
+}
+
+/*
+Dispatch runs l on the calling goroutine, handing its tokens to every
+registered consumer, and returns the error that stopped reading the
+input, if any, which is also what the consumers' End receives. Dispatch
+is called once per source; a dispatcher must not dispatch two lexers at
+once.
+*/
+func (dispatcher *Dispatcher) Dispatch(l *Lexer) error {
+	for _, consumer := range dispatcher.consumers {
+		consumer.Begin(l.Name)
+	}
+
+	l.RunWith(func(token Token) {
+		for _, consumer := range dispatcher.consumers {
+			consumer.Token(token)
+		}
+	})
+
+	err := l.Err()
+
+	for _, consumer := range dispatcher.consumers {
+		consumer.End(err)
+	}
+
+	return err
+}
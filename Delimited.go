@@ -0,0 +1,70 @@
+package lexer
+
+/*
+AcceptDelimited consumes text enclosed in a pair of delimiters, such as
+a quoted string, starting with the open delimiter at the current
+position and ending with the close delimiter. A character following
+escape is taken literally, so an escaped close delimiter does not end
+the text; pass 0 for no escape character. The open and close
+delimiters may be the same character.
+
+It returns true if the closing delimiter was found. If there is no
+opening delimiter nothing is consumed, and if the input ends before the
+closing delimiter everything up to the end is consumed; both return
+false and can be told apart with IsEOF.
+*/
+func (lexer *Lexer) AcceptDelimited(open, close, escape rune) bool {
+	if lexer.IsEOF() || lexer.Peek() != open {
+		return false
+	}
+
+	lexer.Next()
+
+	for !lexer.IsEOF() {
+		switch ch := lexer.Next(); {
+		case ch == escape && escape != 0:
+			if !lexer.IsEOF() {
+				lexer.Next()
+			}
+
+		case ch == close:
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+AcceptNested works like AcceptDelimited for delimiters that nest, such
+as comments in parentheses that may hold further parenthesized
+comments. The text ends at the close delimiter matching the first open
+delimiter.
+*/
+func (lexer *Lexer) AcceptNested(open, close, escape rune) bool {
+	if lexer.IsEOF() || lexer.Peek() != open {
+		return false
+	}
+
+	lexer.Next()
+	depth := 1
+
+	for !lexer.IsEOF() {
+		switch ch := lexer.Next(); {
+		case ch == escape && escape != 0:
+			if !lexer.IsEOF() {
+				lexer.Next()
+			}
+
+		case ch == open:
+			depth++
+
+		case ch == close:
+			if depth--; depth == 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,148 @@
+package lexer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+/*
+Token, TokenType, Position and LexError implement encoding.TextMarshaler
+so they render as text in templates and other text formats. encoding/json
+and encoding/gob prefer a text form over their own when there is one,
+which would lose fields, so the methods below keep the structured forms
+those encodings had before: numbers for token types and objects for the
+rest. Checkpoints in particular rely on positions keeping their offsets.
+*/
+
+type plainToken Token
+
+type plainPosition Position
+
+type plainLexError LexError
+
+/*
+MarshalJSON writes the type as a number
+*/
+func (tokenType TokenType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(tokenType))
+}
+
+/*
+UnmarshalJSON reads a type written as a number, or as a string as
+MarshalText writes it
+*/
+func (tokenType *TokenType) UnmarshalJSON(data []byte) error {
+	var value int
+
+	if err := json.Unmarshal(data, &value); err == nil {
+		*tokenType = TokenType(value)
+		return nil
+	}
+
+	var text string
+
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+
+	return tokenType.UnmarshalText([]byte(text))
+}
+
+/*
+MarshalJSON writes the token as an object of its fields
+*/
+func (token Token) MarshalJSON() ([]byte, error) {
+	return json.Marshal(plainToken(token))
+}
+
+/*
+UnmarshalJSON reads a token written by MarshalJSON
+*/
+func (token *Token) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*plainToken)(token))
+}
+
+/*
+GobEncode encodes the token's fields
+*/
+func (token Token) GobEncode() ([]byte, error) {
+	return gobEncode(plainToken(token))
+}
+
+/*
+GobDecode decodes a token encoded by GobEncode
+*/
+func (token *Token) GobDecode(data []byte) error {
+	return gobDecode(data, (*plainToken)(token))
+}
+
+/*
+MarshalJSON writes the position as an object of its fields
+*/
+func (position Position) MarshalJSON() ([]byte, error) {
+	return json.Marshal(plainPosition(position))
+}
+
+/*
+UnmarshalJSON reads a position written by MarshalJSON
+*/
+func (position *Position) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*plainPosition)(position))
+}
+
+/*
+GobEncode encodes the position's fields
+*/
+func (position Position) GobEncode() ([]byte, error) {
+	return gobEncode(plainPosition(position))
+}
+
+/*
+GobDecode decodes a position encoded by GobEncode
+*/
+func (position *Position) GobDecode(data []byte) error {
+	return gobDecode(data, (*plainPosition)(position))
+}
+
+/*
+MarshalJSON writes the error as an object of its fields
+*/
+func (err LexError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(plainLexError(err))
+}
+
+/*
+UnmarshalJSON reads an error written by MarshalJSON
+*/
+func (err *LexError) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*plainLexError)(err))
+}
+
+/*
+GobEncode encodes the error's fields
+*/
+func (err LexError) GobEncode() ([]byte, error) {
+	return gobEncode(plainLexError(err))
+}
+
+/*
+GobDecode decodes an error encoded by GobEncode
+*/
+func (err *LexError) GobDecode(data []byte) error {
+	return gobDecode(data, (*plainLexError)(err))
+}
+
+func gobEncode(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	if err := gob.NewEncoder(&buffer).Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func gobDecode(data []byte, value interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
@@ -0,0 +1,143 @@
+package lexer
+
+import (
+	"go/token"
+	"strings"
+)
+
+/*
+WithFileSet adds the input to fset as a token.File, so positions of
+tokens are also available as token.Pos values, with TokenPos, sharing one
+position space with go/ast based analysis of other files in fset. Line
+directives are recorded as alternative line information of the file, so
+fset reports positions as Position does, except for the end of input
+ending in a newline: a token.File has no line after its last newline, so
+that is reported at the end of the last line.
+
+A lexer holding its input in memory adds its file when it is first
+asked for one. A lexer created by NewReaderLexer only knows the size of
+its input once it has read all of it, so its file is added when the run
+finishes with the input exhausted; until then File returns nil and
+TokenPos returns token.NoPos.
+*/
+func WithFileSet(fset *token.FileSet) Option {
+	return func(lexer *Lexer) {
+		lexer.fileSet = fset
+	}
+}
+
+/*
+File returns the token.File of the input in the file set given with
+WithFileSet, or nil if there is none yet
+*/
+func (lexer *Lexer) File() *token.File {
+	if lexer.file == nil && lexer.fileSet != nil && !lexer.streaming {
+		lexer.addFile(len(lexer.Input), lineStarts(nil, 0, lexer.Input))
+	}
+
+	return lexer.file
+}
+
+/*
+TokenPos returns position as a token.Pos in the file set given with
+WithFileSet, or token.NoPos if the lexer has no file
+*/
+func (lexer *Lexer) TokenPos(position Position) token.Pos {
+	file := lexer.File()
+	if file == nil || position.Offset > file.Size() {
+		return token.NoPos
+	}
+
+	return file.Pos(position.Offset)
+}
+
+/*
+addFile adds the file of the input to the file set, with lines starting
+at the offsets in lines and the line directives seen so far
+*/
+func (lexer *Lexer) addFile(size int, lines []int) {
+	lexer.file = lexer.fileSet.AddFile(lexer.Name, -1, size)
+
+	// A newline at the very end starts no line within the file
+	for len(lines) > 0 && lines[len(lines)-1] >= size && lines[len(lines)-1] > 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	lexer.file.SetLines(lines)
+
+	for _, remap := range lexer.remaps {
+		lexer.addLineInfo(remap)
+	}
+}
+
+/*
+addLineInfo records a line directive in the file. A directive without a
+column is given its physical column, so columns are reported unchanged,
+as PositionAt reports them.
+*/
+func (lexer *Lexer) addLineInfo(remap lineRemap) {
+	if remap.offset > lexer.file.Size() {
+		return
+	}
+
+	column := remap.column
+	if column == 0 {
+		column = lexer.file.PositionFor(lexer.file.Pos(remap.offset), false).Column
+	}
+
+	lexer.file.AddLineColumnInfo(remap.offset, remap.filename, remap.line, column)
+}
+
+/*
+recordLines records the starts of the lines begun by newlines in text,
+which starts at offset, for the file of a lexer reading from a reader
+*/
+func (lexer *Lexer) recordLines(offset int, text string) {
+	if lexer.fileSet != nil && lexer.streaming {
+		lexer.lines = lineStarts(lexer.lines, offset, text)
+	}
+}
+
+/*
+finishFile adds the file of a lexer reading from a reader once all of
+its input has been read
+*/
+func (lexer *Lexer) finishFile() {
+	if lexer.fileSet == nil || !lexer.streaming || lexer.file != nil || lexer.reader != nil {
+		return
+	}
+
+	lexer.PositionAt(len(lexer.Input))
+
+	if len(lexer.lines) == 0 {
+		lexer.lines = []int{0}
+	}
+
+	lexer.addFile(lexer.base+len(lexer.Input), lexer.lines)
+}
+
+/*
+lineStarts appends to lines the offsets of the lines begun by newlines
+in text, which starts at offset. The first line of an empty list starts
+at 0, and lines already in the list are not added again, as PositionAt
+may go over the same text twice.
+*/
+func lineStarts(lines []int, offset int, text string) []int {
+	if len(lines) == 0 {
+		lines = append(lines, 0)
+	}
+
+	for {
+		newline := strings.IndexByte(text, '\n')
+		if newline < 0 {
+			return lines
+		}
+
+		offset += newline + 1
+		text = text[newline+1:]
+
+		if offset > lines[len(lines)-1] {
+			lines = append(lines, offset)
+		}
+	}
+}
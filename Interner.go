@@ -0,0 +1,46 @@
+package lexer
+
+import "strings"
+
+/*
+Interner keeps a single copy of each distinct string it has seen. When
+given to a lexer with WithInterner, the text of every emitted token is
+interned, so tokens with the same text share one allocation and no
+longer hold a reference to the lexer's input.
+
+An Interner may be shared by lexers run one after another, for example
+across all files of a batch, but is not safe for concurrent use.
+*/
+type Interner struct {
+	strings map[string]string
+}
+
+/*
+NewInterner creates an empty interner
+*/
+func NewInterner() *Interner {
+	return &Interner{
+		strings: make(map[string]string),
+	}
+}
+
+/*
+Intern returns the shared copy of s, storing a copy of s first if it
+has not been seen before.
+*/
+func (interner *Interner) Intern(s string) string {
+	if result, ok := interner.strings[s]; ok {
+		return result
+	}
+
+	result := strings.Clone(s)
+	interner.strings[result] = result
+	return result
+}
+
+/*
+Len returns the number of distinct strings held by the interner
+*/
+func (interner *Interner) Len() int {
+	return len(interner.strings)
+}
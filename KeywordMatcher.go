@@ -0,0 +1,265 @@
+package lexer
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"slices"
+	"sort"
+)
+
+/*
+KeywordMatcher answers whether a word is one of a fixed set of keywords.
+It is built once from the keyword set as a minimal perfect hash, using
+hash and displace: keywords are first spread over buckets, and each
+bucket is then given the seed of a second hash that places its keywords
+in free slots of a table exactly as large as the set. A lookup hashes
+the word twice and makes a single string comparison.
+
+For keyword sets known at compile time, WriteGo generates an equivalent
+switch-based function.
+*/
+type KeywordMatcher struct {
+	keys  []string
+	types []TokenType
+
+	// seeds holds the seed of each bucket's hash, or for a bucket of a
+	// single keyword its slot as -(slot+1)
+	seeds []int32
+
+	// fallback holds the keywords when a bucket has no seed, which
+	// takes an extraordinarily unlucky hash
+	fallback map[string]TokenType
+}
+
+/*
+keywordSeedLimit bounds the search for the seed of a bucket before
+NewKeywordMatcher falls back to a map
+*/
+const keywordSeedLimit = 1 << 20
+
+/*
+NewKeywordMatcher builds a matcher for the given keywords, each mapped
+to the token type that should be emitted for it. The empty string can
+not be a keyword.
+*/
+func NewKeywordMatcher(keywords map[string]TokenType) *KeywordMatcher {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+
+	sort.Strings(words)
+
+	matcher := &KeywordMatcher{
+		keys:  make([]string, len(words)),
+		types: make([]TokenType, len(words)),
+		seeds: make([]int32, max(len(words), 1)),
+	}
+
+	if !matcher.place(words) {
+		matcher.fallback = make(map[string]TokenType, len(words))
+
+		for slot, word := range words {
+			matcher.keys[slot] = word
+			matcher.fallback[word] = keywords[word]
+		}
+	}
+
+	for slot, word := range matcher.keys {
+		matcher.types[slot] = keywords[word]
+	}
+
+	return matcher
+}
+
+/*
+Lookup returns the token type for word and true if word is a keyword
+*/
+func (matcher *KeywordMatcher) Lookup(word string) (TokenType, bool) {
+	if matcher.fallback != nil {
+		tokenType, ok := matcher.fallback[word]
+		return tokenType, ok
+	}
+
+	if word == "" || len(matcher.keys) == 0 {
+		return 0, false
+	}
+
+	slot := matcher.slot(word)
+	if matcher.keys[slot] == word {
+		return matcher.types[slot], true
+	}
+
+	return 0, false
+}
+
+/*
+WriteGo writes a Go source file to w declaring a function named funcName
+in package packageName. The function has the same signature and result
+as Lookup, but is implemented as a switch on the word's length and
+then the word itself, which the compiler turns into efficient code.
+Use it from a go:generate program to bake a keyword set into a lexer.
+*/
+func (matcher *KeywordMatcher) WriteGo(w io.Writer, packageName, funcName string) error {
+	byLength := map[int][]int{}
+	lengths := []int{}
+
+	for slot, key := range matcher.keys {
+		if key == "" {
+			continue
+		}
+
+		if _, ok := byLength[len(key)]; !ok {
+			lengths = append(lengths, len(key))
+		}
+
+		byLength[len(key)] = append(byLength[len(key)], slot)
+	}
+
+	sort.Ints(lengths)
+
+	source := &bytes.Buffer{}
+	fmt.Fprintf(source, "// Code generated by lexer.KeywordMatcher. DO NOT EDIT.\n\n")
+	fmt.Fprintf(source, "package %s\n\n", packageName)
+	fmt.Fprintf(source, "import \"github.com/adampresley/lexer\"\n\n")
+	fmt.Fprintf(source, "func %s(word string) (lexer.TokenType, bool) {\n", funcName)
+	fmt.Fprintf(source, "switch len(word) {\n")
+
+	for _, length := range lengths {
+		slots := byLength[length]
+		sort.Slice(slots, func(i, j int) bool {
+			return matcher.keys[slots[i]] < matcher.keys[slots[j]]
+		})
+
+		fmt.Fprintf(source, "case %d:\nswitch word {\n", length)
+
+		for _, slot := range slots {
+			fmt.Fprintf(source, "case %q:\nreturn lexer.TokenType(%d), true\n", matcher.keys[slot], matcher.types[slot])
+		}
+
+		fmt.Fprintf(source, "}\n")
+	}
+
+	fmt.Fprintf(source, "}\n\nreturn 0, false\n}\n")
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(formatted)
+	return err
+}
+
+/*
+place spreads words over the buckets and searches each bucket, largest
+first, for a seed placing its words in free slots. It returns false if
+a bucket has no such seed within keywordSeedLimit.
+*/
+func (matcher *KeywordMatcher) place(words []string) bool {
+	size := uint32(len(words))
+	buckets := make([][]string, len(matcher.seeds))
+
+	for _, word := range words {
+		bucket := keywordHash(word, 0) % uint32(len(buckets))
+		buckets[bucket] = append(buckets[bucket], word)
+	}
+
+	order := make([]int, len(buckets))
+	for index := range order {
+		order[index] = index
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(buckets[order[i]]) > len(buckets[order[j]])
+	})
+
+	taken := make([]bool, size)
+	free := 0
+	slots := make([]uint32, 0, 8)
+
+	for _, bucket := range order {
+		bucketWords := buckets[bucket]
+
+		switch len(bucketWords) {
+		case 0:
+			continue
+
+		case 1:
+			for taken[free] {
+				free++
+			}
+
+			taken[free] = true
+			matcher.keys[free] = bucketWords[0]
+			matcher.seeds[bucket] = -int32(free) - 1
+			continue
+		}
+
+		seed := uint32(1)
+
+	search:
+		for ; seed < keywordSeedLimit; seed++ {
+			slots = slots[:0]
+
+			for _, word := range bucketWords {
+				slot := keywordHash(word, seed) % size
+				if taken[slot] || slices.Contains(slots, slot) {
+					continue search
+				}
+
+				slots = append(slots, slot)
+			}
+
+			break
+		}
+
+		if seed == keywordSeedLimit {
+			return false
+		}
+
+		for index, slot := range slots {
+			taken[slot] = true
+			matcher.keys[slot] = bucketWords[index]
+		}
+
+		matcher.seeds[bucket] = int32(seed)
+	}
+
+	return true
+}
+
+/*
+slot returns the slot of the table word would be placed in
+*/
+func (matcher *KeywordMatcher) slot(word string) uint32 {
+	seed := matcher.seeds[keywordHash(word, 0)%uint32(len(matcher.seeds))]
+	if seed < 0 {
+		return uint32(-seed - 1)
+	}
+
+	return keywordHash(word, uint32(seed)) % uint32(len(matcher.keys))
+}
+
+/*
+keywordHash hashes word with FNV-1a started from a basis derived from
+seed, and mixes the result so that every seed gives a different hash
+*/
+func keywordHash(word string, seed uint32) uint32 {
+	h := uint32(2166136261) ^ seed*0x9e3779b9
+
+	for index := 0; index < len(word); index++ {
+		h = (h ^ uint32(word[index])) * 16777619
+	}
+
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+
+	return h ^ h>>16
+}
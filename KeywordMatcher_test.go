@@ -0,0 +1,45 @@
+package lexer
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestKeywordMatcherManyKeywords(t *testing.T) {
+	for _, count := range []int{1, 2, 200, 300, 500, 1000} {
+		keywords := make(map[string]TokenType, count)
+		for index := 0; index < count; index++ {
+			keywords["kw"+strconv.Itoa(index)] = TokenType(index + 10)
+		}
+
+		matcher := NewKeywordMatcher(keywords)
+
+		if matcher.fallback != nil {
+			t.Errorf("%d keywords: fell back to a map", count)
+		}
+
+		for word, want := range keywords {
+			if got, ok := matcher.Lookup(word); !ok || got != want {
+				t.Errorf("%d keywords: Lookup(%q): got %v, %t, want %v, true", count, word, got, ok, want)
+			}
+		}
+
+		for _, word := range []string{"", "kw", "kw-1", "kw" + strconv.Itoa(count), "x"} {
+			if got, ok := matcher.Lookup(word); ok {
+				t.Errorf("%d keywords: Lookup(%q): got %v, true, want false", count, word, got)
+			}
+		}
+	}
+}
+
+func TestKeywordMatcherEmpty(t *testing.T) {
+	matcher := NewKeywordMatcher(map[string]TokenType{"": 1})
+
+	if got, ok := matcher.Lookup(""); ok {
+		t.Errorf("Lookup(\"\"): got %v, true, want false", got)
+	}
+
+	if got, ok := matcher.Lookup("if"); ok {
+		t.Errorf("Lookup(\"if\"): got %v, true, want false", got)
+	}
+}
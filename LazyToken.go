@@ -0,0 +1,51 @@
+package lexer
+
+/*
+LazyToken is a compact token holding only its type and the byte offsets
+of its text in the lexer's input. Lexers run with RunLazy deliver these
+instead of Tokens, skipping the substring, value transform, and position
+work for tokens that are never looked at. Call Materialize to get the
+full Token for the ones that are.
+*/
+type LazyToken struct {
+	Type  TokenType
+	Start int
+	End   int
+
+	transformFn TokenValueTransformer
+	message     string
+}
+
+/*
+Materialize builds the full Token, computing its text, transformed value,
+and span from the lexer that produced it. The lexer's input must not
+have changed since the token was produced.
+*/
+func (token LazyToken) Materialize(lexer *Lexer) Token {
+	result := Token{
+		Type: token.Type,
+		Text: token.Text(lexer.Input),
+		Span: Span{
+			Start: lexer.PositionAt(token.Start),
+			End:   lexer.PositionAt(token.End),
+		},
+	}
+
+	if token.transformFn != nil {
+		result.Value = token.transformFn(result.Text)
+	}
+
+	return result
+}
+
+/*
+Text returns the token's text from the input it was lexed from. For
+error tokens this is the error message.
+*/
+func (token LazyToken) Text(input string) string {
+	if token.Type == TOKEN_ERROR {
+		return token.message
+	}
+
+	return input[token.Start:token.End]
+}
@@ -0,0 +1,36 @@
+package lexer
+
+/*
+LexError describes a problem encountered while lexing. Every call to
+Errorf produces one, and it is handed to the error handler registered
+with WithErrorHandler. Diagnostics of a lesser severity can be
+reported with Lexer.Report. Code is an optional machine readable
+identifier for the kind of problem.
+*/
+type LexError struct {
+	Message  string
+	Span     Span
+	Severity Severity
+	Code     string
+}
+
+/*
+Error returns the error message prefixed with the position it occurred at
+*/
+func (err LexError) Error() string {
+	return err.Span.Start.String() + ": " + err.Message
+}
+
+/*
+String returns the error as Error does
+*/
+func (err LexError) String() string {
+	return err.Error()
+}
+
+/*
+MarshalText returns the error as Error does
+*/
+func (err LexError) MarshalText() ([]byte, error) {
+	return []byte(err.Error()), nil
+}
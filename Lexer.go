@@ -2,7 +2,11 @@ package lexer
 
 import (
 	"fmt"
-	"unicode"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+	"time"
 	"unicode/utf8"
 )
 
@@ -10,6 +14,11 @@ import (
 Lexer object contains the state of our parser and provides
 a stream for accepting tokens.
 
+Start, Pos, and Width are byte offsets into Input, so checking for the
+end of the input is a constant time comparison against len(Input).
+When lexing from a reader, Input only holds a window of the input and
+the offsets are relative to that window; see NewReaderLexer.
+
 Based on work by Rob Pike
 http://cuddle.googlecode.com/hg/talk/lex.html#landing-slide
 */
@@ -22,6 +31,166 @@ type Lexer struct {
 	Start int
 	Pos   int
 	Width int
+
+	errorHandler        func(LexError)
+	suppressErrorTokens bool
+	diagnostics         []LexError
+
+	cursorOffset    int
+	cursorLine      int
+	cursorLineStart int
+
+	streaming     bool
+	reader        io.Reader
+	readBuffer    []byte
+	readSize      int
+	readErr       error
+	base          int
+	baseLine      int
+	baseLineStart int
+
+	remaps []lineRemap
+
+	fileSet *token.FileSet
+	file    *token.File
+	lines   []int
+
+	stats     Stats
+	startTime time.Time
+
+	decodedPos   int
+	decodedRune  rune
+	decodedWidth int
+
+	emitFn   func(Token)
+	lazyFn   func(LazyToken)
+	interner *Interner
+	closed   bool
+
+	stateTiming bool
+	metrics     MetricsSink
+	transitions *TransitionRecorder
+
+	trivia       bool
+	covered      int
+	roundTripErr error
+
+	modes      []LexFn
+	suspend    bool
+	stateLabel string
+
+	logFn func(string)
+}
+
+type lineRemap struct {
+	offset       int
+	physicalLine int
+	filename     string
+	line         int
+	column       int
+}
+
+/*
+Accept consumes the next character if it is one of the characters in
+valid, returning true if it did.
+*/
+func (lexer *Lexer) Accept(valid string) bool {
+	if strings.ContainsRune(valid, lexer.Next()) {
+		return true
+	}
+
+	lexer.Backup()
+	return false
+}
+
+/*
+AcceptClass consumes the next character if it is a member of class,
+returning true if it did.
+*/
+func (lexer *Lexer) AcceptClass(class *CharClass) bool {
+	if class.Contains(lexer.Next()) {
+		return true
+	}
+
+	lexer.Backup()
+	return false
+}
+
+/*
+AcceptClassRun consumes characters for as long as they are members of
+class. It returns the number of bytes consumed. ASCII input is scanned a
+byte at a time against the class bitmap without decoding runes.
+*/
+func (lexer *Lexer) AcceptClassRun(class *CharClass) int {
+	start := lexer.Pos
+	pos := lexer.Pos
+
+	for pos < len(lexer.Input) || lexer.fill(pos+1) {
+		if ch := lexer.Input[pos]; ch < utf8.RuneSelf {
+			if !class.Contains(rune(ch)) {
+				break
+			}
+
+			pos++
+			continue
+		}
+
+		if lexer.reader != nil && pos+utf8.UTFMax > len(lexer.Input) {
+			lexer.fill(pos + utf8.UTFMax)
+		}
+
+		ch, width := utf8.DecodeRuneInString(lexer.Input[pos:])
+		if !class.Contains(ch) {
+			break
+		}
+
+		pos += width
+	}
+
+	lexer.Pos = pos
+	lexer.Width = 0
+	return pos - start
+}
+
+/*
+AcceptNewline consumes a line break of "\n", "\r\n" or a lone "\r",
+returning true if it did. Lexers that stop at either break character
+should consume breaks with it, so a lone "\r" does not leave them stuck.
+*/
+func (lexer *Lexer) AcceptNewline() bool {
+	if lexer.Accept("\r") {
+		lexer.Accept("\n")
+		return true
+	}
+
+	return lexer.Accept("\n")
+}
+
+/*
+AcceptRun consumes characters for as long as they are in valid. It
+returns the number of bytes consumed. When valid only holds ASCII
+characters the input is scanned a byte at a time.
+*/
+func (lexer *Lexer) AcceptRun(valid string) int {
+	start := lexer.Pos
+
+	if isASCII(valid) {
+		pos := lexer.Pos
+		for (pos < len(lexer.Input) || lexer.fill(pos+1)) && lexer.Input[pos] != 0 && strings.IndexByte(valid, lexer.Input[pos]) >= 0 {
+			pos++
+		}
+
+		lexer.Pos = pos
+		lexer.Width = 0
+		return pos - start
+	}
+
+	for ch := lexer.Next(); ch != EOF && strings.ContainsRune(valid, ch); ch = lexer.Next() {
+	}
+
+	lexer.Backup()
+	lexer.Width = 0
+	return lexer.Pos - start
 }
 
 /*
@@ -31,10 +200,98 @@ func (lexer *Lexer) Backup() {
 	lexer.Pos -= lexer.Width
 }
 
+/*
+Batches starts lexing on a new goroutine and returns a channel of token
+batches. Each batch holds up to size tokens, and the channel is closed
+once lexing is done. Receiving tokens a batch at a time amortizes the
+channel synchronization cost over many tokens. Each batch is a new
+slice that the receiver may keep.
+*/
+func (lexer *Lexer) Batches(size int) <-chan []Token {
+	result := make(chan []Token, 4)
+
+	go func() {
+		lexer.RunBatched(size, func(batch []Token) {
+			result <- append(make([]Token, 0, len(batch)), batch...)
+		})
+
+		close(result)
+	}()
+
+	return result
+}
+
+/*
+Collect runs the lexer on the calling goroutine and returns every token
+produced, in order. Like RunWith it bypasses the token channel.
+*/
+func (lexer *Lexer) Collect() []Token {
+	result := []Token{}
+
+	lexer.RunWith(func(token Token) {
+		result = append(result, token)
+	})
+
+	return result
+}
+
+/*
+Checkpoint captures where the lexer is so that lexing can be resumed
+later, possibly in another process, with ResumeLexer or
+ResumeReaderLexer. The state and every state on the mode stack must be
+registered with NameState.
+
+A checkpoint is exact when the lexer is between state functions, as it
+is after Suspend. Taken from inside a state function, such as from an
+emitter, lexing resumes at the start of the current token in the state
+that is running, which is only right for states that can be re-entered
+there.
+*/
+func (lexer *Lexer) Checkpoint() (Checkpoint, error) {
+	position := lexer.PositionAt(lexer.Start)
+
+	checkpoint := Checkpoint{
+		Name:      lexer.Name,
+		Offset:    position.Offset,
+		Line:      lexer.cursorLine,
+		LineStart: lexer.cursorLineStart,
+		Position:  position,
+	}
+
+	var err error
+
+	if checkpoint.State, err = registeredStateName(lexer.State); err != nil {
+		return Checkpoint{}, err
+	}
+
+	for _, mode := range lexer.modes {
+		name, err := registeredStateName(mode)
+
+		if err != nil {
+			return Checkpoint{}, err
+		}
+
+		checkpoint.Modes = append(checkpoint.Modes, name)
+	}
+
+	return checkpoint, nil
+}
+
+/*
+CollectInto runs the lexer on the calling goroutine and appends every
+token produced to arena.
+*/
+func (lexer *Lexer) CollectInto(arena *TokenArena) {
+	lexer.RunWith(func(token Token) {
+		arena.Append(token)
+	})
+}
+
 /*
 CurrentCharacter returns the current character at the position tracker
 */
 func (lexer *Lexer) CurrentCharacter() string {
+	lexer.fill(lexer.Pos + 1)
 	return lexer.Input[lexer.Pos : lexer.Pos+1]
 }
 
@@ -46,6 +303,17 @@ func (lexer *Lexer) CurrentInput() string {
 	return lexer.Input[lexer.Start:lexer.Pos]
 }
 
+/*
+CurrentSpan returns the span of the input from the current lexer start
+position to the current position.
+*/
+func (lexer *Lexer) CurrentSpan() Span {
+	return Span{
+		Start: lexer.PositionAt(lexer.Start),
+		End:   lexer.PositionAt(lexer.Pos),
+	}
+}
+
 /*
 Dec dsecrement the position tracker back a single character
 */
@@ -54,70 +322,186 @@ func (lexer *Lexer) Dec() {
 }
 
 /*
-Discard throws away count characters by skipping right over them.
+Diagnostics returns every diagnostic reported during the run, in the
+order they were reported.
+*/
+func (lexer *Lexer) Diagnostics() []LexError {
+	return lexer.diagnostics
+}
+
+/*
+Discard throws away count characters by skipping right over them. With
+WithTrivia they are emitted as a TOKEN_TRIVIA token instead.
 */
 func (lexer *Lexer) Discard(count int) {
-	lexer.Start += count
-	lexer.Pos = lexer.Start
+	lexer.fill(lexer.Start + count)
+
+	end := lexer.Start + count
+	if end > len(lexer.Input) {
+		end = len(lexer.Input)
+	}
+
+	lexer.Pos = end
+	lexer.Ignore()
 }
 
 /*
-Emit puts a token onto the token channel. The value of this token is
-read from the input based on the current lexer position.
+Emit puts a token onto the token channel. The text of this token is
+read from the input based on the current lexer position. Value is left
+nil so that emitting a token does not allocate.
 */
 func (lexer *Lexer) Emit(tokenType TokenType) {
-	lexer.Tokens <- Token{Type: tokenType, Value: lexer.Input[lexer.Start:lexer.Pos]}
+	start := lexer.Start
 	lexer.Start = lexer.Pos
+
+	if lexer.lazyFn != nil {
+		lexer.sendLazy(LazyToken{Type: tokenType, Start: start, End: lexer.Pos})
+	} else {
+		lexer.send(Token{Type: tokenType, Text: lexer.textAt(start), Span: lexer.spanAt(start)})
+	}
+
+	lexer.release()
+}
+
+/*
+EmitKeyword emits the current input as the keyword's token type if the
+matcher recognizes it, otherwise as the fallback type. This is the usual
+keyword-versus-identifier decision at the end of lexing a word.
+*/
+func (lexer *Lexer) EmitKeyword(matcher *KeywordMatcher, fallback TokenType) {
+	if tokenType, ok := matcher.Lookup(lexer.Input[lexer.Start:lexer.Pos]); ok {
+		lexer.Emit(tokenType)
+		return
+	}
+
+	lexer.Emit(fallback)
+}
+
+/*
+EmitToken puts an already built token onto the token channel without
+reading input or moving the lexer's position. It is meant for lexers
+that replay tokens recorded from an earlier run. Under RunLazy only the
+token's type and offsets are kept, so its text and span are rebuilt
+from the lexer's input when materialized.
+*/
+func (lexer *Lexer) EmitToken(token Token) {
+	if lexer.lazyFn == nil {
+		lexer.send(token)
+		return
+	}
+
+	lazy := LazyToken{
+		Type:  token.Type,
+		Start: token.Span.Start.Offset - lexer.base,
+		End:   token.Span.End.Offset - lexer.base,
+	}
+
+	if token.Type == TOKEN_ERROR {
+		lazy.message = token.Text
+	}
+
+	lexer.sendLazy(lazy)
 }
 
 /*
 EmitWithTransform allows you to put a typed-token onto the channel. The value
 is read from the input based on the current lexer position, and then
 passed to a provided transform function. That is then placed on the token
-channel.
+channel. The untransformed text is kept in the token's Text field.
 */
 func (lexer *Lexer) EmitWithTransform(tokenType TokenType, transformFn TokenValueTransformer) {
-	lexer.Tokens <- Token{Type: tokenType, Value: transformFn(lexer.Input[lexer.Start:lexer.Pos])}
+	start := lexer.Start
 	lexer.Start = lexer.Pos
+
+	if lexer.lazyFn != nil {
+		lexer.sendLazy(LazyToken{Type: tokenType, Start: start, End: lexer.Pos, transformFn: transformFn})
+	} else {
+		text := lexer.textAt(start)
+		lexer.send(Token{Type: tokenType, Text: text, Value: transformFn(text), Span: lexer.spanAt(start)})
+	}
+
+	lexer.release()
+}
+
+/*
+Err returns the error that stopped reading input from a reader, if any.
+Reaching the end of the reader is not an error.
+*/
+func (lexer *Lexer) Err() error {
+	return lexer.readErr
 }
 
 /*
 Errorf returns a token with error information. This conforms to the
-LexFn type
+LexFn type. If an error handler is registered it is called with
+the error before the token is emitted.
 */
 func (lexer *Lexer) Errorf(format string, args ...interface{}) LexFn {
-	lexer.Tokens <- Token{
-		Type:  TOKEN_ERROR,
-		Value: fmt.Sprintf(format, args...),
+	err := LexError{
+		Message:  fmt.Sprintf(format, args...),
+		Span:     lexer.CurrentSpan(),
+		Severity: SEVERITY_ERROR,
+	}
+
+	lexer.Report(err)
+
+	if lexer.suppressErrorTokens {
+		return nil
+	}
+
+	if lexer.lazyFn != nil {
+		lexer.sendLazy(LazyToken{Type: TOKEN_ERROR, Start: lexer.Start, End: lexer.Pos, message: err.Message})
+		return nil
 	}
 
+	lexer.send(Token{
+		Type: TOKEN_ERROR,
+		Text: err.Message,
+		Span: err.Span,
+	})
+
 	return nil
 }
 
 /*
 Ignore disregards the current token by setting the lexer's start
-position to the current reading position.
+position to the current reading position. With WithTrivia the ignored
+input is emitted as a TOKEN_TRIVIA token instead.
 */
 func (lexer *Lexer) Ignore() {
+	if lexer.trivia && lexer.Pos > lexer.Start {
+		lexer.Emit(TOKEN_TRIVIA)
+		return
+	}
+
 	lexer.Start = lexer.Pos
+	lexer.release()
 }
 
 /*
-Inc move the position tracker forward x characters
+Inc move the position tracker forward count bytes, stopping at the
+end of the input
 */
 func (lexer *Lexer) Inc(count int) {
+	lexer.fill(lexer.Pos + count)
 	lexer.Pos += count
 
-	if lexer.Pos > utf8.RuneCountInString(lexer.Input) {
-		lexer.Pos -= (lexer.Pos + count) - utf8.RuneCountInString(lexer.Input)
+	if lexer.Pos > len(lexer.Input) {
+		lexer.Pos = len(lexer.Input)
 	}
 }
 
 /*
 InputToEnd returns a slice of the input from the current lexer position
-to the end of the input string.
+to the end of the input string. It is not available when lexing from a
+reader, as the rest of the input has not been read, and panics if
+called.
 */
 func (lexer *Lexer) InputToEnd() string {
+	if lexer.streaming {
+		panic("lexer: InputToEnd is not available when lexing from a reader")
+	}
+
 	return lexer.Input[lexer.Pos:]
 }
 
@@ -126,7 +510,36 @@ IsEOF returns true if the lexer is at the end of the
 input stream.
 */
 func (lexer *Lexer) IsEOF() bool {
-	return lexer.Pos >= utf8.RuneCountInString(lexer.Input)
+	return lexer.Pos >= len(lexer.Input) && !lexer.fill(lexer.Pos+1)
+}
+
+/*
+AtNUL returns true if the next character is a NUL byte. Peek and Next
+return EOF for a NUL byte as they do at the end of the input, so lexers
+that must get past NUL bytes tell the two apart with AtNUL or IsEOF.
+*/
+func (lexer *Lexer) AtNUL() bool {
+	return !lexer.IsEOF() && lexer.Input[lexer.Pos] == 0
+}
+
+/*
+IsClass returns true if the current character is a member of class
+*/
+func (lexer *Lexer) IsClass(class *CharClass) bool {
+	if lexer.IsEOF() {
+		return false
+	}
+
+	if ch := lexer.Input[lexer.Pos]; ch < utf8.RuneSelf {
+		return class.Contains(rune(ch))
+	}
+
+	if lexer.reader != nil {
+		lexer.fill(lexer.Pos + utf8.UTFMax)
+	}
+
+	ch, _ := utf8.DecodeRuneInString(lexer.Input[lexer.Pos:])
+	return class.Contains(ch)
 }
 
 /*
@@ -140,33 +553,84 @@ func (lexer *Lexer) IsNewline() bool {
 IsNumber returns true if the current character is a number
 */
 func (lexer *Lexer) IsNumber() bool {
-	ch, _ := utf8.DecodeRuneInString(lexer.Input[lexer.Pos:])
-	return unicode.IsNumber(ch)
+	return lexer.IsClass(NumberClass)
 }
 
 /*
 IsWhitespace returns true if then current character is whitespace
 */
 func (lexer *Lexer) IsWhitespace() bool {
-	ch, _ := utf8.DecodeRuneInString(lexer.Input[lexer.Pos:])
-	return unicode.IsSpace(ch)
+	return lexer.IsClass(WhitespaceClass)
 }
 
 /*
 Next reads the next rune (character) from the input stream
-and advances the lexer position.
+and advances the lexer position. ASCII characters are read directly
+without UTF-8 decoding. The last multi-byte rune decoded is remembered,
+so a Peek followed by Next only decodes it once.
 */
 func (lexer *Lexer) Next() rune {
-	if lexer.Pos >= utf8.RuneCountInString(lexer.Input) {
+	if lexer.Pos >= len(lexer.Input) && !lexer.fill(lexer.Pos+1) {
 		lexer.Width = 0
 		return EOF
 	}
 
-	result, width := utf8.DecodeRuneInString(lexer.Input[lexer.Pos:])
+	if ch := lexer.Input[lexer.Pos]; ch < utf8.RuneSelf {
+		lexer.Width = 1
+		lexer.Pos++
+		return rune(ch)
+	}
+
+	if lexer.reader != nil && lexer.Pos+utf8.UTFMax > len(lexer.Input) {
+		lexer.fill(lexer.Pos + utf8.UTFMax)
+	}
+
+	if lexer.decodedWidth == 0 || lexer.decodedPos != lexer.Pos {
+		lexer.decodedRune, lexer.decodedWidth = utf8.DecodeRuneInString(lexer.Input[lexer.Pos:])
+		lexer.decodedPos = lexer.Pos
+	}
 
-	lexer.Width = width
+	lexer.Width = lexer.decodedWidth
 	lexer.Pos += lexer.Width
-	return result
+	return lexer.decodedRune
+}
+
+/*
+LineDirective remaps the positions reported for the input starting at the
+current position. The text from here on is reported as coming from the
+given filename starting at the given line, as with a C preprocessor
+"#line" directive. An empty filename keeps the current filename.
+
+A column other than 0 is the column of the current position, and the
+rest of its line is counted from it, as go/scanner does for a
+//line directive with a column. Later lines, and every line when column
+is 0, keep their physical columns.
+*/
+func (lexer *Lexer) LineDirective(filename string, line int, column int) {
+	physical := lexer.PositionAt(lexer.Pos)
+	if filename == "" {
+		filename = physical.Filename
+	}
+
+	remap := lineRemap{
+		offset:       lexer.base + lexer.Pos,
+		physicalLine: lexer.cursorLine,
+		filename:     filename,
+		line:         line,
+		column:       column,
+	}
+
+	index := sort.Search(len(lexer.remaps), func(i int) bool {
+		return lexer.remaps[i].offset > remap.offset
+	})
+
+	lexer.remaps = append(lexer.remaps, lineRemap{})
+	copy(lexer.remaps[index+1:], lexer.remaps[index:])
+	lexer.remaps[index] = remap
+
+	if lexer.file != nil {
+		lexer.addLineInfo(remap)
+	}
 }
 
 /*
@@ -176,6 +640,37 @@ func (lexer *Lexer) NextToken() Token {
 	return <-lexer.Tokens
 }
 
+/*
+PopState removes the state most recently pushed with PushState and
+returns it, so a state can finish a nested mode with
+
+	return l.PopState()
+
+It returns nil, which ends lexing, when the mode stack is empty.
+*/
+func (lexer *Lexer) PopState() LexFn {
+	if len(lexer.modes) == 0 {
+		return nil
+	}
+
+	state := lexer.modes[len(lexer.modes)-1]
+	lexer.modes = lexer.modes[:len(lexer.modes)-1]
+
+	return state
+}
+
+/*
+PushState saves a state to return to with PopState. This is how lexers
+handle nested modes, such as an expression inside a template string
+that must go back to lexing the string once the expression ends:
+
+	l.PushState(lexTemplateString)
+	return lexExpression
+*/
+func (lexer *Lexer) PushState(state LexFn) {
+	lexer.modes = append(lexer.modes, state)
+}
+
 /*
 Peek returns the next rune in the stream, then puts the lexer
 position back. Basically reads the next rune without consuming
@@ -193,55 +688,512 @@ stream is.
 */
 func (lexer *Lexer) PeekCharacters(numCharacters int) string {
 	end := lexer.Pos + numCharacters
-	if end > utf8.RuneCountInString(lexer.Input) {
-		end = utf8.RuneCountInString(lexer.Input)
+	lexer.fill(end)
+
+	if end > len(lexer.Input) {
+		end = len(lexer.Input)
 	}
 
 	return lexer.Input[lexer.Pos:end]
 }
 
+/*
+PositionAt returns the line and column information for a byte offset
+in the input. Offsets are usually lexer.Start or lexer.Pos. Lookups
+that move forward through the input are cheap, as the last computed
+position is remembered.
+*/
+func (lexer *Lexer) PositionAt(offset int) Position {
+	if offset > len(lexer.Input) {
+		offset = len(lexer.Input)
+	}
+
+	absolute := lexer.base + offset
+
+	if absolute < lexer.cursorOffset || lexer.cursorLine == 0 {
+		lexer.cursorOffset = lexer.base
+		lexer.cursorLine = lexer.baseLine + 1
+		lexer.cursorLineStart = lexer.baseLineStart
+	}
+
+	skipped := lexer.Input[lexer.cursorOffset-lexer.base : offset]
+	if newlines := strings.Count(skipped, NEWLINE); newlines > 0 {
+		lexer.recordLines(lexer.cursorOffset, skipped)
+		lexer.cursorLine += newlines
+		lexer.cursorLineStart = lexer.cursorOffset + strings.LastIndex(skipped, NEWLINE) + 1
+	}
+
+	lexer.cursorOffset = absolute
+
+	result := Position{
+		Filename: lexer.Name,
+		Offset:   absolute,
+		Line:     lexer.cursorLine,
+		Column:   absolute - lexer.cursorLineStart + 1,
+	}
+
+	index := sort.Search(len(lexer.remaps), func(i int) bool {
+		return lexer.remaps[i].offset > absolute
+	})
+
+	if index > 0 {
+		remap := lexer.remaps[index-1]
+
+		if remap.column > 0 && result.Line == remap.physicalLine {
+			result.Column = remap.column + (absolute - remap.offset)
+		}
+
+		result.Filename = remap.filename
+		result.Line = remap.line + (result.Line - remap.physicalLine)
+	}
+
+	return result
+}
+
+/*
+Report records a diagnostic without emitting a token or stopping the
+lexer. Use this for warnings and hints, or for errors the lexer can
+recover from. A zero Severity is reported as SEVERITY_ERROR.
+*/
+func (lexer *Lexer) Report(err LexError) {
+	if err.Severity == 0 {
+		err.Severity = SEVERITY_ERROR
+	}
+
+	if err.Severity == SEVERITY_ERROR {
+		lexer.stats.Errors++
+	}
+
+	lexer.diagnostics = append(lexer.diagnostics, err)
+
+	if lexer.errorHandler != nil {
+		lexer.errorHandler(err)
+	}
+
+	if lexer.metrics != nil {
+		lexer.metrics.ErrorReported(err)
+	}
+}
+
+/*
+Reset prepares the lexer to lex a new input, as if it had just been
+created by NewLexer with the same arguments. Options from the previous
+run are cleared; pass them again if they are still wanted. The token
+channel is reused unless it was closed by a previous Run. Reset must
+not be called while the lexer is running.
+*/
+func (lexer *Lexer) Reset(name string, input string, startFn LexFn, options ...Option) {
+	tokens := lexer.Tokens
+
+	if lexer.closed || tokens == nil {
+		tokens = make(chan Token, 100)
+	} else {
+		for len(tokens) > 0 {
+			<-tokens
+		}
+	}
+
+	remaps := lexer.remaps[:0]
+	tokenCounts := lexer.stats.TokenCounts
+	clear(tokenCounts)
+
+	*lexer = Lexer{
+		Name:   name,
+		Input:  input,
+		State:  startFn,
+		Tokens: tokens,
+		remaps: remaps,
+	}
+
+	lexer.stats.TokenCounts = tokenCounts
+
+	for _, option := range options {
+		option(lexer)
+	}
+}
+
 /*
 Run starts the lexical analysis and feeding tokens into the
 token channel.
 */
 func (lexer *Lexer) Run() {
-	go func() {
-		for {
-			lexer.State = lexer.State(lexer)
-			if lexer.State == nil {
-				break
-			}
-		}
+	lexer.startTime = time.Now()
 
+	go func() {
+		lexer.runStates()
 		lexer.Shutdown()
 	}()
 }
 
+/*
+RunBatched performs the lexical analysis on the calling goroutine, handing
+tokens to batchFn in batches of up to size tokens. The final batch may be
+smaller. The slice passed to batchFn is reused for the next batch, so
+copy any tokens that need to be kept.
+*/
+func (lexer *Lexer) RunBatched(size int, batchFn func([]Token)) {
+	if size < 1 {
+		size = 1
+	}
+
+	batch := make([]Token, 0, size)
+
+	lexer.RunWith(func(token Token) {
+		batch = append(batch, token)
+
+		if len(batch) == size {
+			batchFn(batch)
+			batch = batch[:0]
+		}
+	})
+
+	if len(batch) > 0 {
+		batchFn(batch)
+	}
+}
+
+/*
+RunLazy performs the lexical analysis on the calling goroutine, handing
+each token to lazyFn as a LazyToken. Only the token type and offsets are
+recorded when a token is emitted; use LazyToken.Materialize to get the
+full token for those that are needed.
+*/
+func (lexer *Lexer) RunLazy(lazyFn func(LazyToken)) {
+	lexer.startTime = time.Now()
+	lexer.lazyFn = lazyFn
+
+	lexer.runStates()
+	lexer.finish()
+}
+
+/*
+RunWith performs the lexical analysis on the calling goroutine, handing
+each token to emitFn as it is produced. The token channel is not used,
+which avoids the cost of a channel operation per token. RunWith returns
+once the final state function returns nil.
+*/
+func (lexer *Lexer) RunWith(emitFn func(Token)) {
+	lexer.startTime = time.Now()
+	lexer.emitFn = emitFn
+
+	lexer.runStates()
+	lexer.finish()
+}
+
 /*
 Shutdown closes up the token stream
 */
 func (lexer *Lexer) Shutdown() {
+	lexer.finish()
+	lexer.closed = true
 	close(lexer.Tokens)
 }
 
+/*
+Stats returns a summary of the lexing run. The summary is complete once
+the token channel has been closed, or RunWith has returned; calling
+Stats while the lexer is still running is not safe.
+*/
+func (lexer *Lexer) Stats() Stats {
+	result := lexer.stats
+	result.TokenCounts = make(map[TokenType]int, len(lexer.stats.TokenCounts))
+
+	for tokenType, count := range lexer.stats.TokenCounts {
+		result.TokenCounts[tokenType] = count
+	}
+
+	if lexer.stats.StateDurations != nil {
+		result.StateDurations = make(map[string]time.Duration, len(lexer.stats.StateDurations))
+
+		for state, duration := range lexer.stats.StateDurations {
+			result.StateDurations[state] = duration
+		}
+	}
+
+	return result
+}
+
 /*
 SkipWhitespace skips whitespace characters until we get something meaningful.
+If the whitespace runs to the end of the input an EOF token is emitted.
 */
 func (lexer *Lexer) SkipWhitespace() {
-	var ch rune
+	skipped := lexer.AcceptClassRun(WhitespaceClass)
+	lexer.Ignore()
 
-	for {
-		ch = lexer.Next()
+	if skipped > 0 && lexer.Pos >= len(lexer.Input) {
+		lexer.Emit(TOKEN_EOF)
+	}
+}
 
-		if !unicode.IsSpace(ch) {
-			lexer.Dec()
-			lexer.Start = lexer.Pos
-			break
+/*
+Suspend asks a running lexer to stop at the next point between state
+functions where no token is in progress. The run method that is lexing
+then returns, and the Tokens channel is closed if the lexer was started
+with Run. Take a Checkpoint of a suspended lexer to resume it later, or
+call RunWith or Collect again to carry on in the same process. Suspend
+may be called from an emitter or from a state function.
+*/
+func (lexer *Lexer) Suspend() {
+	lexer.suspend = true
+}
+
+/*
+VerifyRoundTrip checks that the tokens of a finished run, concatenated
+in order with error tokens left out, reproduce the input byte for byte.
+This only holds for lexers created with WithTrivia, and an error is
+returned for any other lexer. The check is made as tokens are emitted,
+so it works for lexers reading from a reader too. The returned error
+gives the position of the first gap or overlap found.
+*/
+func (lexer *Lexer) VerifyRoundTrip() error {
+	if !lexer.trivia {
+		return fmt.Errorf("lexer: round trip verification requires WithTrivia")
+	}
+
+	if lexer.roundTripErr != nil {
+		return lexer.roundTripErr
+	}
+
+	if total := lexer.base + len(lexer.Input); lexer.covered < total {
+		return fmt.Errorf("%s: %d bytes at the end of the input are not covered by any token", lexer.PositionAt(lexer.covered-lexer.base), total-lexer.covered)
+	}
+
+	return nil
+}
+
+func (lexer *Lexer) count(tokenType TokenType, length int) {
+	if lexer.stats.TokenCounts == nil {
+		lexer.stats.TokenCounts = make(map[TokenType]int)
+	}
+
+	lexer.stats.TokenCounts[tokenType]++
+	lexer.stats.Tokens++
+
+	if lexer.metrics != nil {
+		lexer.metrics.TokenEmitted(tokenType, length)
+	}
+}
+
+func (lexer *Lexer) send(token Token) {
+	lexer.count(token.Type, token.Span.Len())
+
+	if lexer.trivia {
+		lexer.cover(token.Type, token.Span.Start.Offset, token.Span.End.Offset)
+	}
+
+	if lexer.emitFn != nil {
+		lexer.emitFn(token)
+		return
+	}
+
+	lexer.Tokens <- token
+}
+
+func (lexer *Lexer) sendLazy(token LazyToken) {
+	lexer.count(token.Type, token.End-token.Start)
+
+	if lexer.trivia {
+		lexer.cover(token.Type, lexer.base+token.Start, lexer.base+token.End)
+	}
+	lexer.lazyFn(token)
+}
+
+/*
+cover checks that a token starts where the previous one ended, so that
+the tokens of a run with trivia reproduce the input. Error tokens are
+skipped as their text is a message rather than input. Offsets are
+absolute.
+*/
+func (lexer *Lexer) cover(tokenType TokenType, start, end int) {
+	if tokenType == TOKEN_ERROR || lexer.roundTripErr != nil {
+		return
+	}
+
+	switch {
+	case start > lexer.covered:
+		lexer.roundTripErr = fmt.Errorf("%s: %d bytes of input before this token are not covered by any token", lexer.PositionAt(start-lexer.base), start-lexer.covered)
+
+	case start < lexer.covered:
+		lexer.roundTripErr = fmt.Errorf("%s: token overlaps the previous token by %d bytes", lexer.PositionAt(start-lexer.base), lexer.covered-start)
+	}
+
+	lexer.covered = end
+}
+
+/*
+textAt returns the text of a token starting at start and ending at the
+current position. Emit moves the start position before sending a
+token, so that emitters see the lexer as it is after the token.
+*/
+func (lexer *Lexer) textAt(start int) string {
+	if lexer.interner != nil {
+		return lexer.interner.Intern(lexer.Input[start:lexer.Pos])
+	}
+
+	return lexer.Input[start:lexer.Pos]
+}
+
+func (lexer *Lexer) spanAt(start int) Span {
+	return Span{
+		Start: lexer.PositionAt(start),
+		End:   lexer.PositionAt(lexer.Pos),
+	}
+}
+
+/*
+Step runs the state function the lexer is in once and moves to the
+state it returns, for stepping through a lexer by hand as a debugger
+does. It does nothing once lexing has ended.
+*/
+func (lexer *Lexer) Step() {
+	if lexer.State == nil {
+		return
+	}
+
+	state := lexer.State
+	lexer.stateLabel = ""
+	lexer.State = state(lexer)
+}
+
+/*
+LabelState names the state the lexer moves to next: the state function
+the running one returns or, before lexing starts, the start state. It
+is for state functions made at run time, such as closures, which share
+their Go function and so can not be told apart by NameState. The label
+lasts until that state function returns.
+*/
+func (lexer *Lexer) LabelState(name string) {
+	lexer.stateLabel = name
+}
+
+/*
+StateName returns the name of the state the lexer is in: the label it
+was given with LabelState, if any, and otherwise the name StateName
+gives its state function
+*/
+func (lexer *Lexer) StateName() string {
+	if lexer.stateLabel != "" && lexer.State != nil {
+		return lexer.stateLabel
+	}
+
+	return StateName(lexer.State)
+}
+
+func (lexer *Lexer) runStates() {
+	if lexer.stateTiming || lexer.transitions != nil {
+		lexer.runObservedStates()
+		return
+	}
+
+	for lexer.State != nil && !lexer.suspended() {
+		lexer.Step()
+	}
+}
+
+/*
+suspended reports whether a requested suspension can take effect,
+which is between state functions when no token is in progress
+*/
+func (lexer *Lexer) suspended() bool {
+	if !lexer.suspend || lexer.Start != lexer.Pos {
+		return false
+	}
+
+	lexer.suspend = false
+	return true
+}
+
+/*
+runObservedStates runs the state functions while timing them and
+recording transitions between them, as configured
+*/
+func (lexer *Lexer) runObservedStates() {
+	if lexer.stateTiming && lexer.stats.StateDurations == nil {
+		lexer.stats.StateDurations = make(map[string]time.Duration)
+	}
+
+	previous := TRANSITION_START
+
+	for lexer.State != nil && !lexer.suspended() {
+		name := lexer.StateName()
+
+		if lexer.transitions != nil {
+			lexer.transitions.Record(previous, name)
+			previous = name
 		}
 
-		if ch == EOF || lexer.Pos >= utf8.RuneCountInString(lexer.Input) {
-			lexer.Emit(TOKEN_EOF)
-			break
+		if !lexer.stateTiming {
+			lexer.Step()
+			continue
+		}
+
+		started := time.Now()
+
+		lexer.Step()
+		lexer.stats.StateDurations[name] += time.Since(started)
+	}
+
+	if lexer.transitions != nil && lexer.State == nil && previous != TRANSITION_START {
+		lexer.transitions.Record(previous, TRANSITION_END)
+	}
+}
+
+func (lexer *Lexer) finish() {
+	lexer.stats.Bytes = lexer.base + lexer.Pos
+	lexer.finishFile()
+
+	if !lexer.startTime.IsZero() {
+		lexer.stats.Duration = time.Since(lexer.startTime)
+	}
+
+	if lexer.metrics != nil {
+		lexer.metrics.RunFinished(lexer.Stats())
+	}
+}
+
+func isASCII(s string) bool {
+	for index := 0; index < len(s); index++ {
+		if s[index] >= utf8.RuneSelf {
+			return false
 		}
 	}
+
+	return true
+}
+
+func (lexer *Lexer) fill(need int) bool {
+	for lexer.reader != nil && len(lexer.Input) < need {
+		count, err := lexer.reader.Read(lexer.readBuffer)
+		if count > 0 {
+			lexer.Input += string(lexer.readBuffer[:count])
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				lexer.readErr = err
+			}
+
+			lexer.reader = nil
+			lexer.readBuffer = nil
+		}
+	}
+
+	return len(lexer.Input) >= need
+}
+
+func (lexer *Lexer) release() {
+	if !lexer.streaming || lexer.Start == 0 {
+		return
+	}
+
+	lexer.PositionAt(lexer.Start)
+	lexer.baseLine = lexer.cursorLine - 1
+	lexer.baseLineStart = lexer.cursorLineStart
+
+	lexer.base += lexer.Start
+	lexer.Input = lexer.Input[lexer.Start:]
+	lexer.Pos -= lexer.Start
+	lexer.Start = 0
+	lexer.decodedWidth = 0
 }
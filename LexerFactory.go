@@ -1,11 +1,16 @@
 package lexer
 
+import "io"
+
+const defaultReadSize = 64 * 1024
+
 /*
 NewLexer starts a new lexer with a given input string. This returns the
 instance of the lexer and a channel of tokens. Reading this stream
-is the way to parse a given input and perform processing.
+is the way to parse a given input and perform processing. Options
+may be provided to configure optional behavior.
 */
-func NewLexer(name string, input string, startFn LexFn) *Lexer {
+func NewLexer(name string, input string, startFn LexFn, options ...Option) *Lexer {
 	l := &Lexer{
 		Name:   name,
 		Input:  input,
@@ -13,5 +18,40 @@ func NewLexer(name string, input string, startFn LexFn) *Lexer {
 		Tokens: make(chan Token, 100),
 	}
 
+	for _, option := range options {
+		option(l)
+	}
+
+	return l
+}
+
+/*
+NewReaderLexer starts a new lexer reading its input from reader. Input is
+read in chunks as the lexer needs it, and input before the lexer's start
+position is released whenever a token is emitted or ignored, so memory
+use is bounded by the size of the longest token plus one read rather
+than by the size of the input.
+
+In this mode Input holds only the current window of the input, and
+Start and Pos are offsets into that window. Token spans and positions
+still refer to the whole input. CurrentInput works as usual, as the
+window always holds the text since the start position, but InputToEnd
+panics because the rest of the input has not been read yet. Tokens
+delivered by RunLazy can not be materialized once their text has been
+released. Token text refers to the window it was read from; use
+WithInterner, or copy the text, to keep tokens without keeping windows
+alive.
+*/
+func NewReaderLexer(name string, reader io.Reader, startFn LexFn, options ...Option) *Lexer {
+	l := NewLexer(name, "", startFn, options...)
+	l.streaming = true
+	l.reader = reader
+
+	if l.readSize < 1 {
+		l.readSize = defaultReadSize
+	}
+
+	l.readBuffer = make([]byte, l.readSize)
+
 	return l
 }
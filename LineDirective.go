@@ -0,0 +1,85 @@
+package lexer
+
+import (
+	"strconv"
+	"strings"
+)
+
+/*
+ParseLineDirective parses the text of a position remapping directive. The
+forms understood are those written by common preprocessors and code
+generators:
+
+	#line 42 "file.c"
+	# 42 "file.c"
+	//line file.go:42
+	//line file.go:42:5
+
+The filename is optional in the first two forms, and the number and
+filename may be separated by any run of spaces and tabs. The column is
+0 unless the directive gives one, as only the last form does. Pass the
+results to Lexer.LineDirective once the directive, and the newline
+ending it, have been consumed.
+*/
+func ParseLineDirective(text string) (filename string, line int, column int, ok bool) {
+	text = strings.TrimSpace(text)
+
+	if rest, found := strings.CutPrefix(text, "//line "); found {
+		rest, line, ok := cutNumber(rest)
+		if !ok {
+			return "", 0, 0, false
+		}
+
+		// With two numbers at the end they are the line and the column,
+		// and with one only the line, as the filename may hold colons
+		if name, number, ok := cutNumber(rest); ok {
+			rest, line, column = name, number, line
+		}
+
+		return strings.TrimSpace(rest), line, column, true
+	}
+
+	if !strings.HasPrefix(text, "#") {
+		return "", 0, 0, false
+	}
+
+	text = strings.TrimSpace(text[1:])
+	text = strings.TrimSpace(strings.TrimPrefix(text, "line"))
+
+	number, quoted := text, ""
+	if end := strings.IndexAny(text, " \t"); end >= 0 {
+		number, quoted = text[:end], strings.TrimSpace(text[end:])
+	}
+
+	line, err := strconv.Atoi(number)
+	if err != nil || line < 1 {
+		return "", 0, 0, false
+	}
+
+	if end := strings.LastIndex(quoted, "\""); strings.HasPrefix(quoted, "\"") && end > 0 {
+		if filename, err = strconv.Unquote(quoted[:end+1]); err != nil {
+			return "", 0, 0, false
+		}
+	}
+
+	return filename, line, 0, true
+}
+
+/*
+cutNumber cuts a positive number following the last colon off text. It
+returns the text before the colon and the number, or false if text does
+not end that way.
+*/
+func cutNumber(text string) (string, int, bool) {
+	colon := strings.LastIndex(text, ":")
+	if colon < 0 {
+		return "", 0, false
+	}
+
+	number, err := strconv.Atoi(text[colon+1:])
+	if err != nil || number < 1 {
+		return "", 0, false
+	}
+
+	return text[:colon], number, true
+}
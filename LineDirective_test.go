@@ -0,0 +1,82 @@
+package lexer
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestParseLineDirective(t *testing.T) {
+	tests := []struct {
+		text     string
+		filename string
+		line     int
+		column   int
+		ok       bool
+	}{
+		{`#line 42 "file.c"`, "file.c", 42, 0, true},
+		{`# 42 "file.c"`, "file.c", 42, 0, true},
+		{"#line\t42\t\"file.c\"", "file.c", 42, 0, true},
+		{`#  line   42    "file.c"`, "file.c", 42, 0, true},
+		{`# 42 "file.c" 1 3`, "file.c", 42, 0, true},
+		{`#line 42`, "", 42, 0, true},
+		{"//line file.go:42", "file.go", 42, 0, true},
+		{"//line file.go:10:5", "file.go", 10, 5, true},
+		{"//line :10:5", "", 10, 5, true},
+		{`//line C:\src\file.go:10`, `C:\src\file.go`, 10, 0, true},
+		{`//line C:\src\file.go:10:5`, `C:\src\file.go`, 10, 5, true},
+		{"//line file.go:x:5", "file.go:x", 5, 0, true},
+		{"//line file.go:0", "", 0, 0, false},
+		{"//line file.go", "", 0, 0, false},
+		{"#line x", "", 0, 0, false},
+		{"#line 42x", "", 0, 0, false},
+	}
+
+	for _, test := range tests {
+		filename, line, column, ok := ParseLineDirective(test.text)
+
+		if filename != test.filename || line != test.line || column != test.column || ok != test.ok {
+			t.Errorf("ParseLineDirective(%q): got %q, %d, %d, %t, want %q, %d, %d, %t", test.text, filename, line, column, ok, test.filename, test.line, test.column, test.ok)
+		}
+	}
+}
+
+func TestLineDirectivePositions(t *testing.T) {
+	tests := []struct {
+		directive string
+		want      []string
+	}{
+		{"//line gen.go:10:5", []string{"gen.go:10:5", "gen.go:10:8", "gen.go:11:1"}},
+		{"//line gen.go:10", []string{"gen.go:10:1", "gen.go:10:4", "gen.go:11:1"}},
+		{`#line 10 "gen.c"`, []string{"gen.c:10:1", "gen.c:10:4", "gen.c:11:1"}},
+	}
+
+	for _, test := range tests {
+		input := test.directive + "\nab cd\nef"
+		start := len(test.directive) + 1
+		offsets := []int{start, start + 3, strings.LastIndex(input, "ef")}
+
+		fset := token.NewFileSet()
+		l := NewLexer("input.go", input, nil, WithFileSet(fset))
+
+		filename, line, column, ok := ParseLineDirective(test.directive)
+		if !ok {
+			t.Fatalf("ParseLineDirective(%q) failed", test.directive)
+		}
+
+		l.Pos = start
+		l.LineDirective(filename, line, column)
+
+		for index, offset := range offsets {
+			position := l.PositionAt(offset)
+
+			if got := position.String(); got != test.want[index] {
+				t.Errorf("after %q, offset %d: got %s, want %s", test.directive, offset, got, test.want[index])
+			}
+
+			if fromSet := fset.Position(l.TokenPos(position)).String(); fromSet != test.want[index] {
+				t.Errorf("after %q, offset %d: file set reports %s, want %s", test.directive, offset, fromSet, test.want[index])
+			}
+		}
+	}
+}
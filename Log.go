@@ -0,0 +1,23 @@
+package lexer
+
+import "fmt"
+
+/*
+Logf formats a line of debugging output and passes it to the logger
+given with WithLogger. It does nothing when there is no logger.
+*/
+func (lexer *Lexer) Logf(format string, args ...interface{}) {
+	if lexer.logFn == nil {
+		return
+	}
+
+	lexer.logFn(fmt.Sprintf(format, args...))
+}
+
+/*
+Logging returns true if the lexer has a logger, so that state functions
+can skip building debugging output no one will see
+*/
+func (lexer *Lexer) Logging() bool {
+	return lexer.logFn != nil
+}
@@ -0,0 +1,47 @@
+package lexer
+
+/*
+MappedFile is a file whose contents are memory mapped, on platforms that
+support it, for lexing without copying. Text returns a string that
+aliases the mapping, and since Emit slices token text straight out of
+the lexer's input, every token produced from that text aliases the
+mapping too. No part of the file is copied unless a token is transformed
+or interned.
+
+Lifetime contract: the text, and the Text of every token lexed from it,
+is only valid until Close is called. Reading them afterwards crashes the
+program. Tokens that must outlive the file should have their text copied
+with strings.Clone, or be lexed with WithInterner.
+
+The file must not be changed while it is open. The mapping is private,
+so the program never writes to the file, but pages of it that have not
+been read yet may still show what another process writes to it. If the
+file is truncated, touching the text past its new end raises SIGBUS,
+which crashes the program and can not be recovered from. Files that
+may change while being lexed should be read with os.ReadFile instead.
+
+On platforms without memory mapping the file is read into memory, and
+Close only releases the reference to it.
+*/
+type MappedFile struct {
+	Name string
+
+	data []byte
+	text string
+}
+
+/*
+Text returns the contents of the file. The string aliases the mapping
+and must not be used after Close.
+*/
+func (file *MappedFile) Text() string {
+	return file.text
+}
+
+/*
+NewLexer creates a lexer over the contents of the file, named after the
+file's path.
+*/
+func (file *MappedFile) NewLexer(startFn LexFn, options ...Option) *Lexer {
+	return NewLexer(file.Name, file.text, startFn, options...)
+}
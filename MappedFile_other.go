@@ -0,0 +1,27 @@
+//go:build !unix
+
+package lexer
+
+import "os"
+
+/*
+OpenMapped reads the file at path. Memory mapping is not available on
+this platform, so the file is read into memory instead.
+*/
+func OpenMapped(path string) (*MappedFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MappedFile{Name: path, data: data, text: string(data)}, nil
+}
+
+/*
+Close releases the file's contents
+*/
+func (file *MappedFile) Close() error {
+	file.data = nil
+	file.text = ""
+	return nil
+}
@@ -0,0 +1,59 @@
+//go:build unix
+
+package lexer
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+/*
+OpenMapped memory maps the file at path for reading. The file must not
+be truncated or written to while it is open, as described by MappedFile.
+*/
+func OpenMapped(path string) (*MappedFile, error) {
+	handle, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer handle.Close()
+
+	info, err := handle.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MappedFile{Name: path}
+	if info.Size() == 0 {
+		return result, nil
+	}
+
+	data, err := syscall.Mmap(int(handle.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, &os.PathError{Op: "mmap", Path: path, Err: err}
+	}
+
+	result.data = data
+	result.text = unsafe.String(&data[0], len(data))
+
+	return result, nil
+}
+
+/*
+Close unmaps the file. Strings obtained from the file, including token
+text, must not be used afterwards.
+*/
+func (file *MappedFile) Close() error {
+	data := file.data
+
+	file.data = nil
+	file.text = ""
+
+	if data == nil {
+		return nil
+	}
+
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,39 @@
+package lexer
+
+/*
+A Mark is a position in the input saved with Lexer.Mark, to go back to
+with Rewind. Marks are the lightweight, in-process counterpart of a
+Checkpoint: they let a state function look ahead to decide how to lex
+what is in front of it, then return to where it started.
+
+	mark := l.Mark()
+	found := scanToClosingBracket(l)
+	l.Rewind(mark)
+*/
+type Mark struct {
+	start int
+	pos   int
+}
+
+/*
+Mark saves the current position to return to with Rewind
+*/
+func (lexer *Lexer) Mark() Mark {
+	return Mark{start: lexer.base + lexer.Start, pos: lexer.base + lexer.Pos}
+}
+
+/*
+Rewind moves the lexer back, or forward, to a position saved with Mark.
+Input that has been emitted or ignored may already have been dropped
+when lexing from a reader, so a mark can only be rewound to while the
+token in progress when it was taken is still in progress. Rewind panics
+if a token has been emitted or input ignored since.
+*/
+func (lexer *Lexer) Rewind(mark Mark) {
+	if mark.start != lexer.base+lexer.Start {
+		panic("lexer: Rewind to a mark taken before the last emitted token")
+	}
+
+	lexer.Pos = mark.pos - lexer.base
+	lexer.Width = 0
+}
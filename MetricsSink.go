@@ -0,0 +1,17 @@
+package lexer
+
+/*
+MetricsSink receives measurements from a lexer as it runs. Register one
+with WithMetrics to feed a monitoring system; the metrics package has
+ready-made sinks for expvar and Prometheus.
+
+TokenEmitted is called for every token with the length of input it
+covers, ErrorReported for every diagnostic, and RunFinished once when
+the run completes. Sinks shared by lexers running concurrently must be
+safe for concurrent use.
+*/
+type MetricsSink interface {
+	TokenEmitted(tokenType TokenType, length int)
+	ErrorReported(err LexError)
+	RunFinished(stats Stats)
+}
@@ -0,0 +1,114 @@
+package lexer
+
+/*
+An Option configures optional behavior of a lexer. Options are passed
+to NewLexer.
+*/
+type Option func(*Lexer)
+
+/*
+WithErrorHandler registers a function that is called with every error
+reported through Errorf or Report. The handler is called in addition to the error
+token being emitted, unless WithoutErrorTokens is also given.
+*/
+func WithErrorHandler(handler func(LexError)) Option {
+	return func(lexer *Lexer) {
+		lexer.errorHandler = handler
+	}
+}
+
+/*
+WithoutErrorTokens stops Errorf from putting TOKEN_ERROR tokens on the
+token channel. Use this with WithErrorHandler when errors are handled
+centrally and parsers should never see them.
+*/
+func WithoutErrorTokens() Option {
+	return func(lexer *Lexer) {
+		lexer.suppressErrorTokens = true
+	}
+}
+
+/*
+WithEmitter delivers tokens to emitFn instead of the token channel. The
+lexer can still be started with Run, in which case emitFn is called
+from the lexing goroutine. Use RunWith to lex on the calling goroutine.
+*/
+func WithEmitter(emitFn func(Token)) Option {
+	return func(lexer *Lexer) {
+		lexer.emitFn = emitFn
+	}
+}
+
+/*
+WithInterner interns the text of every emitted token using interner, so
+repeated lexemes share a single string.
+*/
+func WithInterner(interner *Interner) Option {
+	return func(lexer *Lexer) {
+		lexer.interner = interner
+	}
+}
+
+/*
+WithStateTiming records the time spent in each state function, reported
+by Stats in StateDurations. Timing adds a clock read per state function
+call, so it is off by default.
+*/
+func WithStateTiming() Option {
+	return func(lexer *Lexer) {
+		lexer.stateTiming = true
+	}
+}
+
+/*
+WithReadSize sets the number of bytes read at a time by a lexer created
+with NewReaderLexer. The default is 64 KiB.
+*/
+func WithReadSize(size int) Option {
+	return func(lexer *Lexer) {
+		lexer.readSize = size
+	}
+}
+
+/*
+WithMetrics reports token, error, and run measurements to sink
+*/
+func WithMetrics(sink MetricsSink) Option {
+	return func(lexer *Lexer) {
+		lexer.metrics = sink
+	}
+}
+
+/*
+WithTransitionRecorder records every transition between state functions
+in recorder, for checking the lexer's state machine against its design
+*/
+func WithTransitionRecorder(recorder *TransitionRecorder) Option {
+	return func(lexer *Lexer) {
+		lexer.transitions = recorder
+	}
+}
+
+/*
+WithTrivia emits input that would otherwise be thrown away by Ignore,
+Discard and SkipWhitespace as TOKEN_TRIVIA tokens, so that the token
+stream accounts for every byte of the input. Use VerifyRoundTrip after
+the run to confirm it does. Formatters and refactoring tools need this
+to rewrite source without losing whitespace or comments.
+*/
+func WithTrivia() Option {
+	return func(lexer *Lexer) {
+		lexer.trivia = true
+	}
+}
+
+/*
+WithLogger sends the lexer's debugging output, such as the rule traces
+of the rules package, to logFn a line at a time. Without a logger Logf
+does nothing.
+*/
+func WithLogger(logFn func(message string)) Option {
+	return func(lexer *Lexer) {
+		lexer.logFn = logFn
+	}
+}
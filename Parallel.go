@@ -0,0 +1,174 @@
+package lexer
+
+import (
+	"runtime"
+	"strings"
+)
+
+/*
+ParallelOptions configures LexParallel. Zero values select defaults.
+
+Workers is the number of segments lexed at once and defaults to
+GOMAXPROCS. SegmentSize is the approximate number of bytes in each
+segment and defaults to 1 MiB. Boundary returns the offset of the first
+segment boundary at or after from; the default places boundaries just
+after a newline, which suits line-oriented input. Options are applied to
+the lexer of every segment.
+*/
+type ParallelOptions struct {
+	Workers     int
+	SegmentSize int
+	Boundary    func(input string, from int) int
+	Options     []Option
+}
+
+type segmentResult struct {
+	tokens      []Token
+	diagnostics []LexError
+	remapped    int
+}
+
+/*
+LexParallel splits input into segments, lexes the segments on several
+goroutines, and hands the tokens to emitFn in input order with offsets,
+lines, and columns corrected to refer to the whole input. Each segment is
+lexed from startFn, so segments must begin where the lexer can start
+fresh, such as at the start of a line or record. Only the EOF token of
+the final segment is passed on. Line directives apply only within the
+segment they appear in, where positions after them are reported as the
+directive says, as they would be when lexing the input in one run.
+
+Diagnostics reported by all segments are returned in input order. A
+segment whose lexer stops with an error does not stop other segments.
+*/
+func LexParallel(name string, input string, startFn LexFn, options ParallelOptions, emitFn func(Token)) []LexError {
+	if options.Workers < 1 {
+		options.Workers = runtime.GOMAXPROCS(0)
+	}
+
+	if options.SegmentSize < 1 {
+		options.SegmentSize = 1 << 20
+	}
+
+	if options.Boundary == nil {
+		options.Boundary = lineBoundary
+	}
+
+	segments := splitSegments(input, options.SegmentSize, options.Boundary)
+	results := make([]chan segmentResult, len(segments))
+	diagnostics := []LexError{}
+
+	launched := 0
+	launch := func(index int) {
+		results[index] = make(chan segmentResult, 1)
+
+		go func() {
+			segmentLexer := NewLexer(name, input[segments[index]:segmentEnd(segments, index, len(input))], startFn, options.Options...)
+			tokens := segmentLexer.Collect()
+
+			// Positions from the first line directive on are remapped
+			// already and only need their offsets rebased
+			remapped := len(segmentLexer.Input) + 1
+			if len(segmentLexer.remaps) > 0 {
+				remapped = segmentLexer.remaps[0].offset
+			}
+
+			results[index] <- segmentResult{tokens: tokens, diagnostics: segmentLexer.Diagnostics(), remapped: remapped}
+		}()
+	}
+
+	baseLine := 1
+	for index, start := range segments {
+		for launched < len(segments) && launched < index+options.Workers {
+			launch(launched)
+			launched++
+		}
+
+		result := <-results[index]
+		results[index] = nil
+
+		lineStart := strings.LastIndexByte(input[:start], '\n') + 1
+		base := Position{Filename: name, Offset: start, Line: baseLine, Column: start - lineStart + 1}
+		last := index == len(segments)-1
+
+		for _, token := range result.tokens {
+			if token.Type == TOKEN_EOF && !last {
+				continue
+			}
+
+			token.Span = rebaseSpan(token.Span, base, result.remapped)
+			emitFn(token)
+		}
+
+		for _, diagnostic := range result.diagnostics {
+			diagnostic.Span = rebaseSpan(diagnostic.Span, base, result.remapped)
+			diagnostics = append(diagnostics, diagnostic)
+		}
+
+		baseLine += strings.Count(input[start:segmentEnd(segments, index, len(input))], NEWLINE)
+	}
+
+	return diagnostics
+}
+
+func lineBoundary(input string, from int) int {
+	if index := strings.IndexByte(input[from:], '\n'); index >= 0 {
+		return from + index + 1
+	}
+
+	return len(input)
+}
+
+func splitSegments(input string, size int, boundary func(string, int) int) []int {
+	result := []int{0}
+
+	for start := 0; start+size < len(input); {
+		next := boundary(input, start+size)
+		if next <= start || next >= len(input) {
+			break
+		}
+
+		result = append(result, next)
+		start = next
+	}
+
+	return result
+}
+
+func segmentEnd(segments []int, index int, length int) int {
+	if index+1 < len(segments) {
+		return segments[index+1]
+	}
+
+	return length
+}
+
+func rebaseSpan(span Span, base Position, remapped int) Span {
+	return Span{
+		Start: rebasePosition(span.Start, base, remapped),
+		End:   rebasePosition(span.End, base, remapped),
+	}
+}
+
+/*
+rebasePosition moves a position in a segment to the whole input. The
+line, column and filename of positions at or after the offset remapped,
+where the segment's first line directive took effect, are left as the
+directive made them.
+*/
+func rebasePosition(position Position, base Position, remapped int) Position {
+	if position.Offset >= remapped {
+		position.Offset += base.Offset
+		return position
+	}
+
+	if position.Line == 1 {
+		position.Column += base.Column - 1
+	}
+
+	position.Offset += base.Offset
+	position.Line += base.Line - 1
+	position.Filename = base.Filename
+
+	return position
+}
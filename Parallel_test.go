@@ -0,0 +1,81 @@
+package lexer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/*
+lexDirectiveWords emits words like lexWords, and applies //line
+directives found at the start of a line
+*/
+func lexDirectiveWords(l *Lexer) LexFn {
+	l.SkipWhitespace()
+
+	if l.IsEOF() {
+		l.Emit(TOKEN_EOF)
+		return nil
+	}
+
+	if !strings.HasPrefix(l.Input[l.Pos:], "//line ") {
+		l.AcceptClassRun(wordChars)
+		l.Emit(1)
+
+		return lexDirectiveWords
+	}
+
+	for l.Peek() != '\n' && !l.IsEOF() {
+		l.Next()
+	}
+
+	text := l.Input[l.Start:l.Pos]
+	l.Next()
+	l.Ignore()
+
+	if filename, line, column, ok := ParseLineDirective(text); ok {
+		l.LineDirective(filename, line, column)
+	}
+
+	return lexDirectiveWords
+}
+
+/*
+paragraphBoundary places segment boundaries after blank lines
+*/
+func paragraphBoundary(input string, from int) int {
+	if index := strings.Index(input[from:], "\n\n"); index >= 0 {
+		return from + index + 2
+	}
+
+	return len(input)
+}
+
+func TestLexParallelLineDirective(t *testing.T) {
+	input := "ab cd\nef\n\ngh ij\n\n//line gen.go:100:3\nkl mn\nop\n"
+
+	want := NewLexer("input", input, lexDirectiveWords).Collect()
+
+	got := []Token{}
+	diagnostics := LexParallel("input", input, lexDirectiveWords, ParallelOptions{
+		Workers:     2,
+		SegmentSize: 1,
+		Boundary:    paragraphBoundary,
+	}, func(token Token) {
+		got = append(got, token)
+	})
+
+	if len(diagnostics) != 0 {
+		t.Errorf("got diagnostics %v", diagnostics)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got tokens:\n%v\nwant:\n%v", got, want)
+	}
+
+	for _, token := range got {
+		if token.Text == "kl" && token.Span.Start.String() != "gen.go:100:3" {
+			t.Errorf("got kl at %s, want gen.go:100:3", token.Span.Start)
+		}
+	}
+}
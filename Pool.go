@@ -0,0 +1,31 @@
+package lexer
+
+import "sync"
+
+var lexerPool = sync.Pool{
+	New: func() interface{} {
+		return &Lexer{}
+	},
+}
+
+/*
+Get returns a lexer from a shared pool, reset to lex the given input. It
+takes the same arguments as NewLexer. Return the lexer with Put once its
+tokens have been consumed. Lexers run with RunWith or Collect are fully
+reused, including their token channel, so a pooled lexer lexes without
+allocating the lexer, a channel, or a goroutine.
+*/
+func Get(name string, input string, startFn LexFn, options ...Option) *Lexer {
+	lexer := lexerPool.Get().(*Lexer)
+	lexer.Reset(name, input, startFn, options...)
+	return lexer
+}
+
+/*
+Put returns a lexer to the shared pool. The lexer, and slices returned
+by it such as Diagnostics, must not be used after calling Put.
+*/
+func Put(lexer *Lexer) {
+	lexer.Reset("", "", nil)
+	lexerPool.Put(lexer)
+}
@@ -0,0 +1,69 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+var wordChars = WhitespaceClass.Not()
+
+/*
+lexWords emits each run of non-whitespace as a token of type 1
+*/
+func lexWords(l *Lexer) LexFn {
+	l.SkipWhitespace()
+
+	if l.IsEOF() {
+		return nil
+	}
+
+	l.AcceptClassRun(wordChars)
+	l.Emit(1)
+
+	return lexWords
+}
+
+var request = strings.Repeat("alpha bravo charlie\n", 10)
+
+/*
+BenchmarkGetPut lexes small inputs, as a service lexing request bodies
+would, with lexers from the pool and with a new lexer for each input
+*/
+func BenchmarkGetPut(b *testing.B) {
+	discard := func(Token) {}
+
+	b.Run("Pool", func(b *testing.B) {
+		b.SetBytes(int64(len(request)))
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			l := Get("request", request, lexWords)
+			l.RunWith(discard)
+			Put(l)
+		}
+	})
+
+	b.Run("NewLexer", func(b *testing.B) {
+		b.SetBytes(int64(len(request)))
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			l := NewLexer("request", request, lexWords)
+			l.RunWith(discard)
+		}
+	})
+}
+
+func TestGetPutReuses(t *testing.T) {
+	l := Get("first", "a b", lexWords)
+	first := l.Collect()
+	Put(l)
+
+	l = Get("second", "c d e", lexWords)
+	second := l.Collect()
+	Put(l)
+
+	if len(first) != 2 || len(second) != 3 || second[0].Text != "c" || second[0].Span.Start.Filename != "second" {
+		t.Errorf("got tokens %v then %v, want the words of each input", first, second)
+	}
+}
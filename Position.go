@@ -0,0 +1,84 @@
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Position describes a location in the input. Offset is a byte offset from
+the beginning of the input, Line is 1-based, and Column is the 1-based
+byte offset from the beginning of the line.
+*/
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+/*
+IsValid returns true if the position has a line number
+*/
+func (position Position) IsValid() bool {
+	return position.Line > 0
+}
+
+/*
+String returns the position in the form "file:line:column"
+*/
+func (position Position) String() string {
+	result := position.Filename
+
+	if position.IsValid() {
+		if result != "" {
+			result += ":"
+		}
+
+		result += fmt.Sprintf("%d:%d", position.Line, position.Column)
+	}
+
+	if result == "" {
+		result = "-"
+	}
+
+	return result
+}
+
+/*
+MarshalText returns the position as String does
+*/
+func (position Position) MarshalText() ([]byte, error) {
+	return []byte(position.String()), nil
+}
+
+/*
+UnmarshalText parses a position in any of the forms String returns. The
+text has no offset, so Offset is set to 0.
+*/
+func (position *Position) UnmarshalText(text []byte) error {
+	*position = Position{}
+	rest := string(text)
+
+	if rest == "-" {
+		return nil
+	}
+
+	// The line and column are the last two fields, as file names may
+	// hold colons themselves
+	fields := strings.Split(rest, ":")
+
+	if count := len(fields); count >= 2 {
+		line, lineErr := strconv.Atoi(fields[count-2])
+		column, columnErr := strconv.Atoi(fields[count-1])
+
+		if lineErr == nil && columnErr == nil && line > 0 {
+			position.Line, position.Column = line, column
+			rest = strings.Join(fields[:count-2], ":")
+		}
+	}
+
+	position.Filename = rest
+	return nil
+}
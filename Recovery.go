@@ -0,0 +1,33 @@
+package lexer
+
+import "strings"
+
+/*
+RecoverToNewline returns a LexFn that discards the input through the end of
+the current line, including the newline, and then resumes lexing in the
+given state. This is the usual way to keep going after an error in a
+line-oriented language:
+
+	l.Errorf("unexpected character %q", ch)
+	return lexer.RecoverToNewline(lexStatement)
+*/
+func RecoverToNewline(next LexFn) LexFn {
+	return func(lexer *Lexer) LexFn {
+		for {
+			if index := strings.IndexByte(lexer.Input[lexer.Pos:], '\n'); index >= 0 {
+				lexer.Pos += index + 1
+				break
+			}
+
+			lexer.Pos = len(lexer.Input)
+			lexer.Ignore()
+
+			if lexer.IsEOF() {
+				break
+			}
+		}
+
+		lexer.Ignore()
+		return next
+	}
+}
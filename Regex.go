@@ -0,0 +1,88 @@
+package lexer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+RegexLiteral is the value of a regular expression literal such as
+/ab+c/gi, split into the pattern between the delimiters and the flags
+after them
+*/
+type RegexLiteral struct {
+	Pattern string
+	Flags   string
+}
+
+/*
+AcceptRegex consumes a regular expression literal starting with
+delimiter at the current position, as found in JavaScript, Perl and
+Ruby, followed by any run of characters in flags; pass nil for no
+flags. Within the pattern a backslash escapes the character after it,
+and the delimiter does not end the pattern inside a character class, so
+/[/]/ is a single literal. As in ECMAScript, a ] always ends a character
+class unless escaped.
+
+Whether a delimiter starts a regular expression or is a division
+operator depends on the token before it, which the calling state must
+decide. AcceptRegex returns true if the closing delimiter was found. A
+literal may not span lines: if the pattern reaches a line break or the
+end of the input, everything before it has been consumed and false is
+returned. If there is no opening delimiter nothing is consumed.
+*/
+func (lexer *Lexer) AcceptRegex(delimiter rune, flags *CharClass) bool {
+	if lexer.IsEOF() || lexer.Peek() != delimiter {
+		return false
+	}
+
+	lexer.Next()
+	inClass := false
+
+	for {
+		if lexer.IsEOF() || lexer.Peek() == '\n' || lexer.Peek() == '\r' {
+			return false
+		}
+
+		switch ch := lexer.Next(); {
+		case ch == '\\':
+			if !lexer.IsEOF() && lexer.Peek() != '\n' && lexer.Peek() != '\r' {
+				lexer.Next()
+			}
+
+		case ch == '[':
+			inClass = true
+
+		case ch == ']':
+			inClass = false
+
+		case ch == delimiter && !inClass:
+			if flags != nil {
+				lexer.AcceptClassRun(flags)
+			}
+
+			return true
+		}
+	}
+}
+
+/*
+RegexValue is a TokenValueTransformer for tokens lexed with AcceptRegex.
+It gives the token a RegexLiteral value, taking the first character of
+the text as the delimiter. The pattern is returned as written, escapes
+included, ready to hand to a regular expression compiler.
+*/
+func RegexValue(text string) interface{} {
+	if text == "" {
+		return RegexLiteral{}
+	}
+
+	_, size := utf8.DecodeRuneInString(text)
+	end := strings.LastIndex(text, text[:size])
+
+	if end < size {
+		return RegexLiteral{Pattern: text[size:]}
+	}
+
+	return RegexLiteral{Pattern: text[size:end], Flags: text[end+size:]}
+}
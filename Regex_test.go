@@ -0,0 +1,120 @@
+package lexer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+var regexFlags = NewCharClass("gimsuy")
+
+/*
+lexRegexes emits regular expression literals as type 1 and every other
+character as type 2. An unterminated literal stops with an error.
+*/
+func lexRegexes(l *Lexer) LexFn {
+	l.SkipWhitespace()
+
+	if l.IsEOF() {
+		l.Emit(TOKEN_EOF)
+		return nil
+	}
+
+	if l.AcceptRegex('/', regexFlags) {
+		l.EmitWithTransform(1, RegexValue)
+		return lexRegexes
+	}
+
+	if l.Pos > l.Start {
+		return l.Errorf("unterminated regular expression")
+	}
+
+	l.Next()
+	l.Emit(2)
+
+	return lexRegexes
+}
+
+func TestAcceptRegex(t *testing.T) {
+	tests := []struct {
+		input     string
+		start     int
+		delimiter rune
+		flags     *CharClass
+		want      bool
+		consumed  string
+	}{
+		{input: "/ab+c/gi;", delimiter: '/', flags: regexFlags, want: true, consumed: "/ab+c/gi"},
+		{input: "/ab+c/gi;", delimiter: '/', want: true, consumed: "/ab+c/"},
+		{input: "x = /a/", delimiter: '/', flags: regexFlags, want: false, consumed: ""},
+		{input: "x = /a/", start: 4, delimiter: '/', flags: regexFlags, want: true, consumed: "/a/"},
+		{input: "x = /a/", start: 5, delimiter: '/', flags: regexFlags, want: false, consumed: ""},
+		{input: "//", delimiter: '/', flags: regexFlags, want: true, consumed: "//"},
+		{input: "//g x", delimiter: '/', flags: regexFlags, want: true, consumed: "//g"},
+		{input: "", delimiter: '/', flags: regexFlags, want: false, consumed: ""},
+		{input: "/[/]/i", delimiter: '/', flags: regexFlags, want: true, consumed: "/[/]/i"},
+		{input: "/[\\]/]/", delimiter: '/', want: true, consumed: "/[\\]/]/"},
+		{input: "/a\\/b/", delimiter: '/', want: true, consumed: "/a\\/b/"},
+		{input: "#a/b#", delimiter: '#', want: true, consumed: "#a/b#"},
+		{input: "/ab\ncd/", delimiter: '/', want: false, consumed: "/ab"},
+		{input: "/ab\r\ncd/", delimiter: '/', want: false, consumed: "/ab"},
+		{input: "/ab\\\ncd/", delimiter: '/', want: false, consumed: "/ab\\"},
+		{input: "/ab", delimiter: '/', want: false, consumed: "/ab"},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input, nil)
+		l.Start, l.Pos = test.start, test.start
+
+		if got := l.AcceptRegex(test.delimiter, test.flags); got != test.want {
+			t.Errorf("AcceptRegex(%q) of %q at %d: got %t, want %t", test.delimiter, test.input, test.start, got, test.want)
+		}
+
+		if got := l.Input[test.start:l.Pos]; got != test.consumed {
+			t.Errorf("AcceptRegex(%q) of %q at %d: consumed %q, want %q", test.delimiter, test.input, test.start, got, test.consumed)
+		}
+	}
+}
+
+func TestRegexValue(t *testing.T) {
+	tests := []struct {
+		text string
+		want RegexLiteral
+	}{
+		{text: "/ab+c/gi", want: RegexLiteral{Pattern: "ab+c", Flags: "gi"}},
+		{text: "//", want: RegexLiteral{}},
+		{text: "/a\\/b/", want: RegexLiteral{Pattern: "a\\/b"}},
+		{text: "/ab", want: RegexLiteral{Pattern: "ab"}},
+		{text: "", want: RegexLiteral{}},
+	}
+
+	for _, test := range tests {
+		if got := RegexValue(test.text); got != test.want {
+			t.Errorf("RegexValue(%q): got %+v, want %+v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestAcceptRegexStreaming(t *testing.T) {
+	// Read a byte at a time, so every literal spans several windows and
+	// the emoji is split across reads
+	input := "a /b[/]c\\/d/gi e /é😀/ /f\ng/"
+	want := []string{"a", "/b[/]c\\/d/gi", "e", "/é😀/", "unterminated regular expression"}
+
+	l := NewReaderLexer("test", iotest.OneByteReader(strings.NewReader(input)), lexRegexes, WithReadSize(1))
+	tokens := l.Collect()
+
+	got := []string{}
+	for _, token := range tokens {
+		got = append(got, token.Text)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lexing %q: got tokens %q, want %q", input, got, want)
+	}
+
+	if want := NewLexer("test", input, lexRegexes).Collect(); !reflect.DeepEqual(tokens, want) {
+		t.Errorf("lexing %q: got tokens\n%v\nwant the same as without streaming:\n%v", input, tokens, want)
+	}
+}
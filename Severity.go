@@ -0,0 +1,32 @@
+package lexer
+
+/*
+A Severity describes how serious a reported diagnostic is. The values
+match those used by the Language Server Protocol.
+*/
+type Severity int
+
+const (
+	SEVERITY_ERROR       Severity = 1
+	SEVERITY_WARNING     Severity = 2
+	SEVERITY_INFORMATION Severity = 3
+	SEVERITY_HINT        Severity = 4
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case SEVERITY_ERROR:
+		return "error"
+
+	case SEVERITY_WARNING:
+		return "warning"
+
+	case SEVERITY_INFORMATION:
+		return "information"
+
+	case SEVERITY_HINT:
+		return "hint"
+	}
+
+	return "unknown"
+}
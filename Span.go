@@ -0,0 +1,17 @@
+package lexer
+
+/*
+Span describes a range of the input. Start is the position of the first
+byte in the range, End is the position just past the last byte.
+*/
+type Span struct {
+	Start Position
+	End   Position
+}
+
+/*
+Len returns the number of bytes covered by the span
+*/
+func (span Span) Len() int {
+	return span.End.Offset - span.Start.Offset
+}
@@ -0,0 +1,59 @@
+package lexer
+
+import "bufio"
+
+/*
+AsSplitFunc returns a bufio.SplitFunc that splits input into the tokens
+startFn lexes, leaving out tokens of the types in skip, so code built
+around bufio.Scanner gets quoted strings, escapes and comments handled
+by a real lexer instead of by splitting on spaces:
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(lexer.AsSplitFunc(shell.Start, shell.TOKEN_COMMENT))
+
+	for scanner.Scan() {
+		fmt.Println(scanner.Text())
+	}
+
+Each token is lexed afresh from startFn, so lexers that carry state from
+one token to the next, such as those with modes, do not split right;
+for them use a lexer reading from the reader with NewReaderLexer. A
+token that reaches the end of the data bufio.Scanner holds is lexed
+again with more data, as it may continue. An error token stops the
+scanner with the error, as a LexError whose positions are relative to
+the data the scanner held.
+
+Scanner.Bytes returns the token's text only; its type is not passed on.
+*/
+func AsSplitFunc(startFn LexFn, skip ...TokenType) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+
+		l := &Lexer{Input: string(data), State: startFn}
+		stream := NewTokenStream(l, skip...)
+
+		for {
+			token := stream.Next()
+			start, end := token.Span.Start.Offset, token.Span.End.Offset
+
+			switch {
+			case end >= len(data) && !atEOF:
+				// The token, or the input skipped before the end, may go on
+				return 0, nil, nil
+
+			case token.Type == TOKEN_EOF:
+				return len(data), nil, nil
+
+			case token.Type == TOKEN_ERROR:
+				return 0, nil, LexError{Message: token.Text, Span: token.Span, Severity: SEVERITY_ERROR}
+
+			case end == start:
+				continue
+			}
+
+			return end, data[start:end], nil
+		}
+	}
+}
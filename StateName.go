@@ -0,0 +1,64 @@
+package lexer
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	stateNames   sync.Map
+	statesByName sync.Map
+)
+
+/*
+NameState registers a readable name for a state function. Names are used
+when reporting time spent in each state and anywhere else a state needs
+to be identified. States that are not registered are named after their
+Go function. Registered states can also be looked up by name, which
+is how checkpoints restore the state a lexer was in.
+*/
+func NameState(name string, fn LexFn) {
+	stateNames.Store(reflect.ValueOf(fn).Pointer(), name)
+	statesByName.Store(name, fn)
+}
+
+/*
+LookupState returns the state function registered under name with
+NameState
+*/
+func LookupState(name string) (LexFn, bool) {
+	fn, ok := statesByName.Load(name)
+
+	if !ok {
+		return nil, false
+	}
+
+	return fn.(LexFn), true
+}
+
+/*
+StateName returns the registered name of a state function, falling back
+to the name of the Go function without its package path. Lexer.StateName
+also knows the labels given with Lexer.LabelState.
+*/
+func StateName(fn LexFn) string {
+	if fn == nil {
+		return ""
+	}
+
+	pointer := reflect.ValueOf(fn).Pointer()
+	if name, ok := stateNames.Load(pointer); ok {
+		return name.(string)
+	}
+
+	name := "unknown"
+	if function := runtime.FuncForPC(pointer); function != nil {
+		name = function.Name()
+		name = name[strings.LastIndex(name, "/")+1:]
+	}
+
+	stateNames.Store(pointer, name)
+	return name
+}
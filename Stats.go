@@ -0,0 +1,42 @@
+package lexer
+
+import "time"
+
+/*
+Stats summarizes a lexing run. TokenCounts holds the number of tokens
+emitted for each token type, including error and EOF tokens. Bytes is
+the number of input bytes the lexer advanced over, and Duration is the
+time between the start of Run and the token channel closing.
+StateDurations holds the time spent in each state function, keyed by
+StateName, and is only filled in when WithStateTiming is given.
+*/
+type Stats struct {
+	TokenCounts    map[TokenType]int
+	Tokens         int
+	Errors         int
+	Bytes          int
+	Duration       time.Duration
+	StateDurations map[string]time.Duration
+}
+
+/*
+BytesPerSecond returns the lexing throughput in input bytes per second
+*/
+func (stats Stats) BytesPerSecond() float64 {
+	if stats.Duration <= 0 {
+		return 0
+	}
+
+	return float64(stats.Bytes) / stats.Duration.Seconds()
+}
+
+/*
+TokensPerSecond returns the lexing throughput in tokens per second
+*/
+func (stats Stats) TokensPerSecond() float64 {
+	if stats.Duration <= 0 {
+		return 0
+	}
+
+	return float64(stats.Tokens) / stats.Duration.Seconds()
+}
@@ -1,16 +1,21 @@
 package lexer
 
 /*
-A Token represents a parsed item in a source input. A token has a type
-and a value. These are used to determine what to do next.
+A Token represents a parsed item in a source input. A token has a type,
+the text it was read from, and the span of input that text covers. Value
+holds the result of a TokenValueTransformer and is nil for tokens put
+on the channel with Emit. For error tokens Text holds the error message.
+These are used to determine what to do next.
 */
 type Token struct {
 	Type  TokenType
+	Text  string
 	Value interface{}
+	Span  Span
 }
 
 func (token Token) IsEmpty() bool {
-	return token.Type == 0 && token.Value == nil
+	return token.Type == 0 && token.Text == "" && token.Value == nil
 }
 
 func (token Token) IsEOF() bool {
@@ -21,14 +26,42 @@ func (token Token) IsError() bool {
 	return token.Type == TOKEN_ERROR
 }
 
+func (token Token) IsTrivia() bool {
+	return token.Type == TOKEN_TRIVIA
+}
+
 func (token Token) String() string {
 	switch token.Type {
 	case TOKEN_EOF:
 		return "EOF"
 
 	case TOKEN_ERROR:
-		return (token.Value).(string)
+		return token.Text
+	}
+
+	if value, ok := token.Value.(string); ok && token.Text == "" {
+		return value
+	}
+
+	return token.Text
+}
+
+/*
+ValueOrText returns the transformed value of the token if there is one,
+otherwise the token's text.
+*/
+func (token Token) ValueOrText() interface{} {
+	if token.Value != nil {
+		return token.Value
 	}
 
-	return (token.Value).(string)
+	return token.Text
+}
+
+/*
+MarshalText returns the token as String does, so tokens render as their
+text wherever text is expected
+*/
+func (token Token) MarshalText() ([]byte, error) {
+	return []byte(token.String()), nil
 }
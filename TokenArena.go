@@ -0,0 +1,142 @@
+package lexer
+
+import "unsafe"
+
+/*
+TokenArena stores tokens in large fixed-size chunks instead of one
+growing slice. Appending never copies earlier tokens, and everything is
+released at once with Reset, which keeps the chunks for the next use.
+Collect tokens into an arena with Lexer.CollectInto.
+
+The arena can also hold the strings produced by value transforms, via
+String, so that a whole compilation unit's token data lives in a
+handful of allocations.
+
+A TokenArena is not safe for concurrent use.
+*/
+type TokenArena struct {
+	chunkSize  int
+	chunks     [][]Token
+	length     int
+	bytes      []byte
+	byteChunks [][]byte
+	byteChunk  int
+}
+
+const defaultArenaChunkSize = 4096
+
+/*
+NewTokenArena creates an arena storing chunkSize tokens per chunk. A
+chunkSize less than 1 uses a default of 4096 tokens.
+*/
+func NewTokenArena(chunkSize int) *TokenArena {
+	if chunkSize < 1 {
+		chunkSize = defaultArenaChunkSize
+	}
+
+	return &TokenArena{
+		chunkSize: chunkSize,
+	}
+}
+
+/*
+Append copies token into the arena and returns a pointer to the stored
+token. The pointer is valid until the arena is Reset.
+*/
+func (arena *TokenArena) Append(token Token) *Token {
+	chunkIndex := arena.length / arena.chunkSize
+
+	if chunkIndex == len(arena.chunks) {
+		arena.chunks = append(arena.chunks, make([]Token, arena.chunkSize))
+	}
+
+	result := &arena.chunks[chunkIndex][arena.length%arena.chunkSize]
+	*result = token
+	arena.length++
+
+	return result
+}
+
+/*
+At returns the token at index, in the order tokens were appended
+*/
+func (arena *TokenArena) At(index int) *Token {
+	return &arena.chunks[index/arena.chunkSize][index%arena.chunkSize]
+}
+
+/*
+Each calls fn for every token in the arena in order, stopping early if
+fn returns false.
+*/
+func (arena *TokenArena) Each(fn func(index int, token *Token) bool) {
+	for index := 0; index < arena.length; index++ {
+		if !fn(index, arena.At(index)) {
+			return
+		}
+	}
+}
+
+/*
+Len returns the number of tokens in the arena
+*/
+func (arena *TokenArena) Len() int {
+	return arena.length
+}
+
+/*
+Reset releases every token and string in the arena at once. The chunks
+are kept and reused by later appends.
+*/
+func (arena *TokenArena) Reset() {
+	for index := 0; index*arena.chunkSize < arena.length; index++ {
+		clear(arena.chunks[index])
+	}
+
+	arena.length = 0
+	arena.byteChunk = 0
+
+	if len(arena.byteChunks) > 0 {
+		arena.bytes = arena.byteChunks[0][:0]
+	}
+}
+
+/*
+String copies s into the arena's string storage and returns the copy.
+The returned string must not be used after the arena is Reset.
+*/
+func (arena *TokenArena) String(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	if len(arena.bytes)+len(s) > cap(arena.bytes) {
+		arena.growBytes(len(s))
+	}
+
+	start := len(arena.bytes)
+	arena.bytes = append(arena.bytes, s...)
+
+	return unsafe.String(&arena.bytes[start], len(s))
+}
+
+func (arena *TokenArena) growBytes(need int) {
+	arena.byteChunk++
+
+	for arena.byteChunk < len(arena.byteChunks) {
+		if cap(arena.byteChunks[arena.byteChunk]) >= need {
+			arena.bytes = arena.byteChunks[arena.byteChunk][:0]
+			return
+		}
+
+		arena.byteChunk++
+	}
+
+	size := arena.chunkSize * 16
+	if need > size {
+		size = need
+	}
+
+	arena.bytes = make([]byte, 0, size)
+	arena.byteChunks = append(arena.byteChunks, arena.bytes)
+	arena.byteChunk = len(arena.byteChunks) - 1
+}
@@ -0,0 +1,40 @@
+package lexer
+
+import "strconv"
+
+/*
+TokenNames maps token types to readable names, for output meant for
+people or other tools. Lexers usually declare one alongside their token
+type constants:
+
+	var Names = lexer.TokenNames{
+		TOKEN_IDENT:  "IDENT",
+		TOKEN_NUMBER: "NUMBER",
+	}
+*/
+type TokenNames map[TokenType]string
+
+/*
+Name returns the name of tokenType. TOKEN_EOF, TOKEN_ERROR and
+TOKEN_TRIVIA are named "EOF", "ERROR" and "TRIVIA" unless given other
+names, and types without a name are named after their numeric value.
+Name may be called on a nil map.
+*/
+func (names TokenNames) Name(tokenType TokenType) string {
+	if name, ok := names[tokenType]; ok {
+		return name
+	}
+
+	switch tokenType {
+	case TOKEN_EOF:
+		return "EOF"
+
+	case TOKEN_ERROR:
+		return "ERROR"
+
+	case TOKEN_TRIVIA:
+		return "TRIVIA"
+	}
+
+	return strconv.Itoa(int(tokenType))
+}
@@ -0,0 +1,212 @@
+package lexer
+
+import (
+	"fmt"
+	"time"
+)
+
+/*
+TokenStream hands out a lexer's tokens one at a time with as much
+lookahead as needed, which is what a hand-written recursive descent
+parser wants:
+
+	stream := lexer.NewTokenStream(l, lexer.TOKEN_TRIVIA, TOKEN_COMMENT)
+
+	for stream.Peek().Type == TOKEN_PLUS {
+		stream.Next()
+		...
+	}
+
+The stream runs the lexer on the calling goroutine, only as far as the
+tokens asked for, so nothing is lexed that is not needed and no
+goroutine is left behind when a parser gives up early. Create the
+stream instead of calling Run, RunWith or Collect.
+
+Once the lexer is done every further call returns an EOF token at the
+end of the input, so a parser can read past the end without checking
+for it at every step.
+*/
+type TokenStream struct {
+	lexer    *Lexer
+	skip     []TokenType
+	buffer   []Token
+	head     int
+	want     int
+	finished bool
+}
+
+/*
+UnexpectedTokenError is returned by TokenStream.Expect when the next
+token is not of the expected type
+*/
+type UnexpectedTokenError struct {
+	Token    Token
+	Expected TokenType
+}
+
+/*
+Error returns a message giving the position and text of the unexpected
+token
+*/
+func (err *UnexpectedTokenError) Error() string {
+	if err.Token.Type == TOKEN_EOF {
+		return fmt.Sprintf("%s: unexpected end of input", err.Token.Span.Start)
+	}
+
+	return fmt.Sprintf("%s: unexpected %q", err.Token.Span.Start, err.Token.Text)
+}
+
+/*
+NewTokenStream creates a stream over the tokens of lexer, leaving out
+tokens of the types in skip, such as comments and trivia
+*/
+func NewTokenStream(lexer *Lexer, skip ...TokenType) *TokenStream {
+	stream := &TokenStream{
+		lexer: lexer,
+		skip:  skip,
+	}
+
+	lexer.startTime = time.Now()
+	lexer.emitFn = stream.push
+
+	return stream
+}
+
+/*
+Next consumes and returns the next token
+*/
+func (stream *TokenStream) Next() Token {
+	token := stream.LookAhead(1)
+
+	if stream.head < len(stream.buffer) {
+		stream.head++
+	}
+
+	return token
+}
+
+/*
+Peek returns the next token without consuming it
+*/
+func (stream *TokenStream) Peek() Token {
+	return stream.LookAhead(1)
+}
+
+/*
+LookAhead returns the token n places ahead without consuming anything.
+LookAhead(1) is the next token, the same as Peek.
+*/
+func (stream *TokenStream) LookAhead(n int) Token {
+	if n < 1 {
+		n = 1
+	}
+
+	if !stream.fill(n) {
+		return stream.end()
+	}
+
+	return stream.buffer[stream.head+n-1]
+}
+
+/*
+Is returns true if the next token is of one of the given types
+*/
+func (stream *TokenStream) Is(tokenTypes ...TokenType) bool {
+	next := stream.Peek().Type
+
+	for _, tokenType := range tokenTypes {
+		if next == tokenType {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+Accept consumes the next token if it is of one of the given types,
+returning the token and true if it did
+*/
+func (stream *TokenStream) Accept(tokenTypes ...TokenType) (Token, bool) {
+	if !stream.Is(tokenTypes...) {
+		return Token{}, false
+	}
+
+	return stream.Next(), true
+}
+
+/*
+Expect consumes the next token if it is of the given type, and
+otherwise returns an *UnexpectedTokenError without consuming it
+*/
+func (stream *TokenStream) Expect(tokenType TokenType) (Token, error) {
+	if token, ok := stream.Accept(tokenType); ok {
+		return token, nil
+	}
+
+	return Token{}, &UnexpectedTokenError{Token: stream.Peek(), Expected: tokenType}
+}
+
+/*
+push receives the lexer's tokens, asking it to stop once the stream
+holds as many as were asked for
+*/
+func (stream *TokenStream) push(token Token) {
+	for _, skip := range stream.skip {
+		if token.Type == skip {
+			return
+		}
+	}
+
+	// Consumed tokens are dropped once they make up half the buffer,
+	// which keeps the buffer from growing with the input
+	if stream.head > 0 && stream.head >= len(stream.buffer)/2 {
+		stream.buffer = append(stream.buffer[:0], stream.buffer[stream.head:]...)
+		stream.head = 0
+	}
+
+	stream.buffer = append(stream.buffer, token)
+
+	if len(stream.buffer)-stream.head >= stream.want {
+		stream.lexer.Suspend()
+	}
+}
+
+/*
+fill runs the lexer until n unconsumed tokens are buffered, returning
+false if it finished first
+*/
+func (stream *TokenStream) fill(n int) bool {
+	if len(stream.buffer)-stream.head >= n {
+		return true
+	}
+
+	if stream.finished {
+		return false
+	}
+
+	stream.want = n
+	stream.lexer.runStates()
+
+	if stream.lexer.State == nil {
+		stream.lexer.suspend = false
+		stream.lexer.finish()
+		stream.finished = true
+	}
+
+	return len(stream.buffer)-stream.head >= n
+}
+
+/*
+end returns the token handed out once every token has been consumed:
+the lexer's own EOF token if it emitted one, or else an EOF token at
+the end of the input
+*/
+func (stream *TokenStream) end() Token {
+	if count := len(stream.buffer); count > 0 && stream.buffer[count-1].Type == TOKEN_EOF {
+		return stream.buffer[count-1]
+	}
+
+	position := stream.lexer.PositionAt(stream.lexer.Pos)
+	return Token{Type: TOKEN_EOF, Span: Span{Start: position, End: position}}
+}
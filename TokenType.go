@@ -1,5 +1,10 @@
 package lexer
 
+import (
+	"fmt"
+	"strconv"
+)
+
 /*
 A TokenType defines the types of tokens available. Create your own to describe
 your input
@@ -7,6 +12,49 @@ your input
 type TokenType int
 
 const (
-	TOKEN_ERROR TokenType = -2
-	TOKEN_EOF   TokenType = -1
+	TOKEN_TRIVIA TokenType = -3
+	TOKEN_ERROR  TokenType = -2
+	TOKEN_EOF    TokenType = -1
 )
+
+/*
+String returns "EOF", "ERROR" or "TRIVIA" for the predefined types and
+the numeric value for any other, as a TokenType does not know the names
+of a lexer's types; use TokenNames for those
+*/
+func (tokenType TokenType) String() string {
+	return TokenNames(nil).Name(tokenType)
+}
+
+/*
+MarshalText returns the type as String does
+*/
+func (tokenType TokenType) MarshalText() ([]byte, error) {
+	return []byte(tokenType.String()), nil
+}
+
+/*
+UnmarshalText parses a type written by MarshalText
+*/
+func (tokenType *TokenType) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "EOF":
+		*tokenType = TOKEN_EOF
+
+	case "ERROR":
+		*tokenType = TOKEN_ERROR
+
+	case "TRIVIA":
+		*tokenType = TOKEN_TRIVIA
+
+	default:
+		value, err := strconv.Atoi(string(text))
+		if err != nil {
+			return fmt.Errorf("lexer: invalid token type %q", text)
+		}
+
+		*tokenType = TokenType(value)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package lexer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+/*
+TransitionRecorder records the transitions between state functions
+observed while lexing, identified by their StateName. One recorder may
+be shared by any number of lexers, including ones running
+concurrently, to build up a picture of a state machine over many
+inputs. Attach it with WithTransitionRecorder.
+*/
+type TransitionRecorder struct {
+	mutex       sync.Mutex
+	transitions map[transition]int
+	visits      map[string]int
+}
+
+type transition struct {
+	from string
+	to   string
+}
+
+/*
+Names used for the pseudo states at either end of a run
+*/
+const (
+	TRANSITION_START = "(start)"
+	TRANSITION_END   = "(end)"
+)
+
+/*
+NewTransitionRecorder creates an empty recorder
+*/
+func NewTransitionRecorder() *TransitionRecorder {
+	return &TransitionRecorder{
+		transitions: make(map[transition]int),
+		visits:      make(map[string]int),
+	}
+}
+
+/*
+Record notes a transition from one state to another. Lexers call this
+for each state function they run; from is TRANSITION_START for the
+first state and to is TRANSITION_END for the last.
+*/
+func (recorder *TransitionRecorder) Record(from, to string) {
+	recorder.mutex.Lock()
+	recorder.transitions[transition{from: from, to: to}]++
+
+	if to != TRANSITION_END {
+		recorder.visits[to]++
+	}
+
+	recorder.mutex.Unlock()
+}
+
+/*
+Count returns the number of times the transition from one state to
+another was observed
+*/
+func (recorder *TransitionRecorder) Count(from, to string) int {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return recorder.transitions[transition{from: from, to: to}]
+}
+
+/*
+Unvisited returns the names of the given states that were never
+entered, which usually means they are unreachable
+*/
+func (recorder *TransitionRecorder) Unvisited(states ...LexFn) []string {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	var result []string
+
+	for _, state := range states {
+		if name := StateName(state); recorder.visits[name] == 0 {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+/*
+WriteDOT writes the observed transitions as a Graphviz digraph, with
+each edge labeled by the number of times it was taken. Any states
+given that were never entered are drawn dashed, to make unreachable
+states stand out. Render the output with "dot -Tsvg".
+*/
+func (recorder *TransitionRecorder) WriteDOT(w io.Writer, states ...LexFn) error {
+	unvisited := recorder.Unvisited(states...)
+
+	recorder.mutex.Lock()
+	transitions := make([]transition, 0, len(recorder.transitions))
+	counts := make([]int, 0, len(recorder.transitions))
+
+	for key := range recorder.transitions {
+		transitions = append(transitions, key)
+	}
+
+	sort.Slice(transitions, func(i, j int) bool {
+		if transitions[i].from != transitions[j].from {
+			return transitions[i].from < transitions[j].from
+		}
+
+		return transitions[i].to < transitions[j].to
+	})
+
+	for _, key := range transitions {
+		counts = append(counts, recorder.transitions[key])
+	}
+
+	recorder.mutex.Unlock()
+
+	writer := bufio.NewWriter(w)
+
+	fmt.Fprintln(writer, "digraph states {")
+	fmt.Fprintln(writer, "\tnode [shape=box];")
+	fmt.Fprintf(writer, "\t%s [shape=point];\n", strconv.Quote(TRANSITION_START))
+	fmt.Fprintf(writer, "\t%s [shape=doublecircle, label=\"\", width=0.2];\n", strconv.Quote(TRANSITION_END))
+
+	for _, name := range unvisited {
+		fmt.Fprintf(writer, "\t%s [style=dashed, fontcolor=gray];\n", strconv.Quote(name))
+	}
+
+	for index, key := range transitions {
+		fmt.Fprintf(writer, "\t%s -> %s [label=\"%d\"];\n", strconv.Quote(key.from), strconv.Quote(key.to), counts[index])
+	}
+
+	fmt.Fprintln(writer, "}")
+	return writer.Flush()
+}
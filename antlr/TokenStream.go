@@ -0,0 +1,297 @@
+/*
+Package antlr gives the tokens of a lexer the interface of the ANTLR
+runtimes' CommonTokenStream, so that a parser ported from ANTLR, or
+written in its style, consumes a lexer of this package with few changes:
+
+	stream := antlr.NewTokenStream(l, TOKEN_SPACE, TOKEN_COMMENT)
+
+	for stream.LA(1) == TOKEN_IDENT && stream.LA(2) == TOKEN_COMMA {
+		names = append(names, stream.LT(1).Text)
+		stream.Consume()
+		stream.Consume()
+	}
+
+Tokens of the hidden types play the part of tokens on a hidden channel:
+they are kept in the stream, where Get, Tokens and the HiddenTokens
+methods find them, but LT, LA and Consume pass over them.
+*/
+package antlr
+
+import (
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+TokenStream buffers the tokens of a lexer for index based access, as
+ANTLR's CommonTokenStream does. Tokens are numbered from 0 in the order
+the lexer produced them, hidden or not, up to the first EOF token. The
+lexer runs on the calling goroutine, only as far as the tokens asked
+for.
+
+The stream keeps every token, so Seek may go back to any index and Mark
+and Release, which are there for parsers that call them, pin nothing.
+*/
+type TokenStream struct {
+	source  *lexer.TokenStream
+	hidden  map[lexer.TokenType]bool
+	tokens  []lexer.Token
+	index   int
+	started bool
+	done    bool
+}
+
+/*
+NewTokenStream creates a stream over the tokens of l, with tokens of the
+types in hidden on the hidden channel
+*/
+func NewTokenStream(l *lexer.Lexer, hidden ...lexer.TokenType) *TokenStream {
+	stream := &TokenStream{
+		source: lexer.NewTokenStream(l),
+		hidden: map[lexer.TokenType]bool{},
+	}
+
+	for _, tokenType := range hidden {
+		stream.hidden[tokenType] = true
+	}
+
+	return stream
+}
+
+/*
+LT returns the token k visible tokens ahead, with LT(1) the current
+token, or for negative k the token -k visible tokens back. It returns
+the EOF token past the end and an empty token before the start or for
+k of 0.
+*/
+func (stream *TokenStream) LT(k int) lexer.Token {
+	index := stream.Index()
+
+	switch {
+	case k == 0:
+		return lexer.Token{}
+
+	case k > 0:
+		for n := 1; n < k; n++ {
+			index = stream.nextVisible(index + 1)
+		}
+
+	default:
+		for n := 0; n < -k; n++ {
+			if index = stream.previousVisible(index - 1); index < 0 {
+				return lexer.Token{}
+			}
+		}
+	}
+
+	return stream.tokens[index]
+}
+
+/*
+LA returns the type of the token LT(k) returns
+*/
+func (stream *TokenStream) LA(k int) lexer.TokenType {
+	return stream.LT(k).Type
+}
+
+/*
+Consume moves past the current token to the next visible one. It does
+nothing at the EOF token.
+*/
+func (stream *TokenStream) Consume() {
+	index := stream.Index()
+
+	if stream.tokens[index].Type != lexer.TOKEN_EOF {
+		stream.index = stream.nextVisible(index + 1)
+	}
+}
+
+/*
+Index returns the index of the current token
+*/
+func (stream *TokenStream) Index() int {
+	if !stream.started {
+		stream.started = true
+		stream.index = stream.nextVisible(0)
+	}
+
+	return stream.index
+}
+
+/*
+Seek makes the token at index, or the first visible token after it,
+current
+*/
+func (stream *TokenStream) Seek(index int) {
+	if index < 0 {
+		index = 0
+	}
+
+	stream.started = true
+	stream.index = stream.nextVisible(index)
+}
+
+/*
+Mark returns a marker for Release. The stream keeps every token, so
+markers pin nothing.
+*/
+func (stream *TokenStream) Mark() int {
+	return -1
+}
+
+/*
+Release releases a marker returned by Mark
+*/
+func (stream *TokenStream) Release(marker int) {
+}
+
+/*
+Get returns the token at index, hidden or not, lexing as far as needed.
+Past the end it returns the EOF token.
+*/
+func (stream *TokenStream) Get(index int) lexer.Token {
+	stream.fetch(index)
+
+	if index >= len(stream.tokens) {
+		index = len(stream.tokens) - 1
+	}
+
+	return stream.tokens[index]
+}
+
+/*
+Size returns the number of tokens lexed so far. After Fill it is the
+number of tokens in the stream, including EOF.
+*/
+func (stream *TokenStream) Size() int {
+	return len(stream.tokens)
+}
+
+/*
+Fill lexes the rest of the input
+*/
+func (stream *TokenStream) Fill() {
+	for !stream.done {
+		stream.fetch(len(stream.tokens))
+	}
+}
+
+/*
+Tokens returns the tokens from start to stop inclusive, hidden or not,
+or only those of the given types if any are given
+*/
+func (stream *TokenStream) Tokens(start int, stop int, types ...lexer.TokenType) []lexer.Token {
+	result := []lexer.Token{}
+
+	for index := start; index <= stop && stream.fetch(index); index++ {
+		if len(types) == 0 || hasType(types, stream.tokens[index].Type) {
+			result = append(result, stream.tokens[index])
+		}
+	}
+
+	return result
+}
+
+/*
+HiddenTokensToLeft returns the hidden tokens directly before the token at
+index, such as the comments before a declaration
+*/
+func (stream *TokenStream) HiddenTokensToLeft(index int) []lexer.Token {
+	if !stream.fetch(index) {
+		return nil
+	}
+
+	start := index
+	for start > 0 && stream.hidden[stream.tokens[start-1].Type] {
+		start--
+	}
+
+	return append([]lexer.Token{}, stream.tokens[start:index]...)
+}
+
+/*
+HiddenTokensToRight returns the hidden tokens directly after the token at
+index, such as a comment at the end of its line
+*/
+func (stream *TokenStream) HiddenTokensToRight(index int) []lexer.Token {
+	result := []lexer.Token{}
+
+	for next := index + 1; stream.fetch(next) && stream.hidden[stream.tokens[next].Type]; next++ {
+		result = append(result, stream.tokens[next])
+	}
+
+	return result
+}
+
+/*
+Text returns the text of the tokens from start to stop inclusive, hidden
+or not. Error tokens and EOF add nothing.
+*/
+func (stream *TokenStream) Text(start int, stop int) string {
+	result := strings.Builder{}
+
+	for _, token := range stream.Tokens(start, stop) {
+		if token.Type != lexer.TOKEN_EOF && token.Type != lexer.TOKEN_ERROR {
+			result.WriteString(token.Text)
+		}
+	}
+
+	return result.String()
+}
+
+/*
+fetch lexes until the token at index is buffered, returning false if the
+stream ends before it
+*/
+func (stream *TokenStream) fetch(index int) bool {
+	for len(stream.tokens) <= index {
+		if stream.done {
+			return false
+		}
+
+		token := stream.source.Next()
+		stream.tokens = append(stream.tokens, token)
+		stream.done = token.Type == lexer.TOKEN_EOF
+	}
+
+	return true
+}
+
+/*
+nextVisible returns the index of the first visible token at or after
+index, or of the EOF token
+*/
+func (stream *TokenStream) nextVisible(index int) int {
+	for ; stream.fetch(index); index++ {
+		if !stream.hidden[stream.tokens[index].Type] || stream.tokens[index].Type == lexer.TOKEN_EOF {
+			return index
+		}
+	}
+
+	return len(stream.tokens) - 1
+}
+
+/*
+previousVisible returns the index of the last visible token at or
+before index, or -1
+*/
+func (stream *TokenStream) previousVisible(index int) int {
+	for ; index >= 0; index-- {
+		if !stream.hidden[stream.tokens[index].Type] {
+			return index
+		}
+	}
+
+	return -1
+}
+
+func hasType(types []lexer.TokenType, tokenType lexer.TokenType) bool {
+	for _, candidate := range types {
+		if candidate == tokenType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,35 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+AllocsPerToken lexes input with startFn runs times and returns the
+average number of heap allocations per emitted token. It is built on
+testing.AllocsPerRun, which sets GOMAXPROCS to 1 while it runs and
+counts the allocations of the whole program, so it is meant for tests
+and benchmarks, such as checking that a lexer stays allocation free on
+the emit path, and not for a running service.
+*/
+func AllocsPerToken(input string, startFn lexer.LexFn, runs int) float64 {
+	tokens := 0
+	l := lexer.NewLexer("allocs", input, startFn)
+	count := func(lexer.Token) {
+		tokens++
+	}
+
+	allocs := testing.AllocsPerRun(runs, func() {
+		tokens = 0
+		l.Reset("allocs", input, startFn)
+		l.RunWith(count)
+	})
+
+	if tokens == 0 {
+		return 0
+	}
+
+	return allocs / float64(tokens)
+}
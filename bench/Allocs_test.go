@@ -0,0 +1,47 @@
+package bench
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+lexUpperWords emits each word with its upper case form as its value,
+which allocates a string for every token
+*/
+func lexUpperWords(l *lexer.Lexer) lexer.LexFn {
+	l.SkipWhitespace()
+
+	if l.IsEOF() {
+		return nil
+	}
+
+	for ch := l.Peek(); ch != lexer.EOF && !unicode.IsSpace(ch); ch = l.Peek() {
+		l.Next()
+	}
+
+	l.EmitWithTransform(TOKEN_WORD, func(text string) interface{} {
+		return strings.ToUpper(text)
+	})
+
+	return lexUpperWords
+}
+
+func TestAllocsPerToken(t *testing.T) {
+	input := Log.Text()
+
+	if allocs := AllocsPerToken(input, lexWords, 5); allocs >= 0.01 {
+		t.Errorf("splitting words: got %.3f allocations per token, want none", allocs)
+	}
+
+	if allocs := AllocsPerToken(input, lexUpperWords, 5); allocs < 1 {
+		t.Errorf("upper casing words: got %.3f allocations per token, want at least 1", allocs)
+	}
+
+	if allocs := AllocsPerToken("", lexWords, 5); allocs != 0 {
+		t.Errorf("empty input: got %.3f allocations per token, want 0", allocs)
+	}
+}
@@ -0,0 +1,173 @@
+package bench
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+BenchmarkNext reads every rune of each corpus with Next
+*/
+func BenchmarkNext(b *testing.B) {
+	for _, corpus := range Corpora {
+		b.Run(corpus.Name, func(b *testing.B) {
+			input := corpus.Text()
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				l := lexer.NewLexer(corpus.Name, input, nil)
+				for l.Next() != lexer.EOF {
+				}
+			}
+		})
+	}
+}
+
+/*
+BenchmarkPeek reads every rune of each corpus with a Peek followed by
+Next, which is the pattern most state functions use
+*/
+func BenchmarkPeek(b *testing.B) {
+	for _, corpus := range Corpora {
+		b.Run(corpus.Name, func(b *testing.B) {
+			input := corpus.Text()
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				l := lexer.NewLexer(corpus.Name, input, nil)
+				for l.Peek() != lexer.EOF {
+					l.Next()
+				}
+			}
+		})
+	}
+}
+
+/*
+BenchmarkEmit splits each corpus into words and receives every token
+from the token channel. Besides the usual measurements it reports the
+number of heap allocations per token.
+*/
+func BenchmarkEmit(b *testing.B) {
+	for _, corpus := range Corpora {
+		b.Run(corpus.Name, func(b *testing.B) {
+			input := corpus.Text()
+			tokens := 0
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+
+			before := runtime.MemStats{}
+			runtime.ReadMemStats(&before)
+
+			for i := 0; i < b.N; i++ {
+				l := lexer.NewLexer(corpus.Name, input, lexWords)
+				l.Run()
+
+				for range l.Tokens {
+					tokens++
+				}
+			}
+
+			after := runtime.MemStats{}
+			runtime.ReadMemStats(&after)
+
+			if tokens > 0 {
+				b.ReportMetric(float64(after.Mallocs-before.Mallocs)/float64(tokens), "allocs/token")
+			}
+		})
+	}
+}
+
+var nonWhitespace = lexer.WhitespaceClass.Not()
+
+/*
+BenchmarkSkipWhitespace skips whitespace between words without emitting
+tokens, isolating the cost of SkipWhitespace and AcceptClassRun
+*/
+func BenchmarkSkipWhitespace(b *testing.B) {
+	for _, corpus := range Corpora {
+		b.Run(corpus.Name, func(b *testing.B) {
+			input := corpus.Text()
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				l := lexer.NewLexer(corpus.Name, input, nil)
+
+				for !l.IsEOF() {
+					l.SkipWhitespace()
+
+					l.AcceptClassRun(nonWhitespace)
+
+					l.Ignore()
+				}
+			}
+		})
+	}
+}
+
+/*
+BenchmarkRequests lexes each corpus one small chunk at a time, as a web
+service lexing request bodies would. Lexers come from lexer.Get and are
+returned with lexer.Put in the Pool benchmarks, while each chunk gets a
+new lexer from lexer.NewLexer in the New benchmarks.
+*/
+func BenchmarkRequests(b *testing.B) {
+	for _, pooled := range []bool{false, true} {
+		kind := "New"
+		if pooled {
+			kind = "Pool"
+		}
+
+		for _, corpus := range Corpora {
+			b.Run(kind+"/"+corpus.Name, func(b *testing.B) {
+				chunks := split(corpus.Text(), 1024)
+				b.SetBytes(int64(len(corpus.Text())))
+				b.ReportAllocs()
+
+				discard := func(lexer.Token) {}
+
+				for i := 0; i < b.N; i++ {
+					for _, chunk := range chunks {
+						if pooled {
+							l := lexer.Get(corpus.Name, chunk, lexWords)
+							l.RunWith(discard)
+							lexer.Put(l)
+						} else {
+							l := lexer.NewLexer(corpus.Name, chunk, lexWords)
+							l.RunWith(discard)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+/*
+split splits input into chunks of at least size bytes, ending at line
+breaks
+*/
+func split(input string, size int) []string {
+	result := []string{}
+
+	for len(input) > size {
+		end := size
+		for end < len(input) && input[end] != '\n' {
+			end++
+		}
+
+		result = append(result, input[:end])
+		input = input[end:]
+	}
+
+	if input != "" {
+		result = append(result, input)
+	}
+
+	return result
+}
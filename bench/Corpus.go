@@ -0,0 +1,147 @@
+/*
+Package bench holds reference corpora, a JSON document, a web server log
+and Unicode heavy prose, generated the same on every run so lexer
+performance can be compared across releases. The package's benchmarks
+exercise Next, Peek, Emit and SkipWhitespace over each corpus:
+
+	go test -bench . ./bench
+
+AllocsPerToken measures the allocations of any lexer per token in tests.
+*/
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+/*
+A Corpus is a named, deterministic input used by the benchmarks. The
+same corpus text is produced on every run so results are comparable
+across releases.
+*/
+type Corpus struct {
+	Name string
+
+	once     sync.Once
+	size     int
+	generate func(size int) string
+	text     string
+}
+
+/*
+Text returns the corpus text, generating it on first use
+*/
+func (corpus *Corpus) Text() string {
+	corpus.once.Do(func() {
+		corpus.text = corpus.generate(corpus.size)
+	})
+
+	return corpus.text
+}
+
+var (
+	/*
+		JSON is a large JSON document of nested objects and arrays with
+		string, number, and literal values.
+	*/
+	JSON = &Corpus{Name: "json", size: 1 << 20, generate: generateJSON}
+
+	/*
+		Log is a web server access log in combined log format.
+	*/
+	Log = &Corpus{Name: "log", size: 2 << 20, generate: generateLog}
+
+	/*
+		Unicode is prose mixing Latin, Cyrillic, Greek, CJK, and emoji so
+		that most runes take the multi-byte decoding path.
+	*/
+	Unicode = &Corpus{Name: "unicode", size: 1 << 20, generate: generateUnicode}
+
+	/*
+		Corpora lists every reference corpus
+	*/
+	Corpora = []*Corpus{JSON, Log, Unicode}
+)
+
+var words = []string{
+	"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel",
+	"india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa",
+}
+
+func generateJSON(size int) string {
+	random := rand.New(rand.NewSource(1))
+	builder := strings.Builder{}
+	builder.WriteString("[\n")
+
+	for index := 0; builder.Len() < size; index++ {
+		if index > 0 {
+			builder.WriteString(",\n")
+		}
+
+		fmt.Fprintf(&builder, `  {"id": %d, "name": "%s %s", "score": %.3f, "active": %t, "tags": ["%s", "%s"], "parent": null, "note": "line\nbreak \"quoted\""}`,
+			index,
+			words[random.Intn(len(words))],
+			words[random.Intn(len(words))],
+			random.Float64()*1000,
+			random.Intn(2) == 0,
+			words[random.Intn(len(words))],
+			words[random.Intn(len(words))],
+		)
+	}
+
+	builder.WriteString("\n]\n")
+	return builder.String()
+}
+
+func generateLog(size int) string {
+	random := rand.New(rand.NewSource(2))
+	methods := []string{"GET", "POST", "PUT", "DELETE"}
+	statuses := []int{200, 201, 204, 301, 304, 400, 404, 500}
+	builder := strings.Builder{}
+
+	for index := 0; builder.Len() < size; index++ {
+		fmt.Fprintf(&builder, "10.0.%d.%d - - [10/Oct/2024:13:%02d:%02d -0700] \"%s /%s/%s?page=%d HTTP/1.1\" %d %d \"-\" \"Mozilla/5.0 (X11; Linux x86_64)\"\n",
+			random.Intn(256),
+			random.Intn(256),
+			(index/60)%60,
+			index%60,
+			methods[random.Intn(len(methods))],
+			words[random.Intn(len(words))],
+			words[random.Intn(len(words))],
+			random.Intn(100),
+			statuses[random.Intn(len(statuses))],
+			random.Intn(50000),
+		)
+	}
+
+	return builder.String()
+}
+
+func generateUnicode(size int) string {
+	random := rand.New(rand.NewSource(3))
+	phrases := []string{
+		"Съешь же ещё этих мягких французских булок",
+		"Ξεσκεπάζω την ψυχοφθόρα βδελυγμία",
+		"いろはにほへと ちりぬるを",
+		"天地玄黄 宇宙洪荒",
+		"Zwölf Boxkämpfer jagen Viktor quer über den großen Sylter Deich",
+		"🙂 🚀 ✨ 🎉",
+		"naïve café façade",
+	}
+	builder := strings.Builder{}
+
+	for builder.Len() < size {
+		builder.WriteString(phrases[random.Intn(len(phrases))])
+
+		if random.Intn(8) == 0 {
+			builder.WriteString("\n")
+		} else {
+			builder.WriteString(" ")
+		}
+	}
+
+	return builder.String()
+}
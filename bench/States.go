@@ -0,0 +1,35 @@
+package bench
+
+import (
+	"unicode"
+
+	"github.com/adampresley/lexer"
+)
+
+const (
+	TOKEN_WORD lexer.TokenType = iota + 1
+)
+
+/*
+lexWords splits input into runs of non-whitespace, using SkipWhitespace
+between words and Peek/Next to find the end of each word.
+*/
+func lexWords(l *lexer.Lexer) lexer.LexFn {
+	l.SkipWhitespace()
+
+	if l.IsEOF() {
+		return nil
+	}
+
+	for {
+		ch := l.Peek()
+		if ch == lexer.EOF || unicode.IsSpace(ch) {
+			break
+		}
+
+		l.Next()
+	}
+
+	l.Emit(TOKEN_WORD)
+	return lexWords
+}
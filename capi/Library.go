@@ -0,0 +1,136 @@
+//go:build cgo
+
+/*
+Package capi exports a lexer written with this package as a C library,
+so applications in other languages can link it. A library is a main
+package that sets its language and imports nothing else of capi:
+
+	package main
+
+	import (
+		"github.com/adampresley/lexer/capi"
+		"github.com/adampresley/lexer/presets/calc"
+	)
+
+	func init() {
+		capi.SetLanguage(calc.Start, calc.Names)
+	}
+
+	func main() {}
+
+built as a shared or static library:
+
+	go build -buildmode=c-shared -o libcalc.so .
+
+C code includes lexer.h from this directory and pulls tokens one at a
+time:
+
+	lexer_handle l = lexer_new(source, strlen(source));
+	lexer_token token;
+
+	do {
+		token = lexer_next(l);
+		printf("%s %.*s\n", lexer_token_name(token.type), (int) token.text_length, token.text);
+	} while (token.type != LEXER_TOKEN_EOF);
+
+	lexer_free(l);
+
+Handles are runtime/cgo handles, so they are safe to keep in C memory,
+but a single handle must not be used from two threads at once. Errors
+come back as tokens of type LEXER_TOKEN_ERROR whose text is the message.
+*/
+package capi
+
+/*
+#include <stdlib.h>
+
+#define LEXER_NO_PROTOTYPES
+#include "lexer.h"
+*/
+import "C"
+
+import (
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+
+	"github.com/adampresley/lexer"
+)
+
+var language struct {
+	start   lexer.LexFn
+	names   lexer.TokenNames
+	options []lexer.Option
+}
+
+var (
+	namesMutex sync.Mutex
+	names      = map[lexer.TokenType]*C.char{}
+)
+
+/*
+SetLanguage sets the lexer the library exports: the state it starts in,
+the names lexer_token_name reports and the options every lexer gets.
+Call it from the library's init function.
+*/
+func SetLanguage(start lexer.LexFn, tokenNames lexer.TokenNames, options ...lexer.Option) {
+	language.start = start
+	language.names = tokenNames
+	language.options = options
+}
+
+/*
+handle is what a lexer_handle refers to
+*/
+type handle struct {
+	stream *lexer.TokenStream
+	text   *C.char
+}
+
+//export lexer_new
+func lexer_new(input *C.char, length C.size_t) C.lexer_handle {
+	l := lexer.NewLexer("", C.GoStringN(input, C.int(length)), language.start, language.options...)
+	return C.lexer_handle(cgo.NewHandle(&handle{stream: lexer.NewTokenStream(l)}))
+}
+
+//export lexer_next
+func lexer_next(h C.lexer_handle) C.lexer_token {
+	state := cgo.Handle(h).Value().(*handle)
+	token := state.stream.Next()
+
+	C.free(unsafe.Pointer(state.text))
+	state.text = C.CString(token.Text)
+
+	return C.lexer_token{
+		_type:        C.int32_t(token.Type),
+		text:         state.text,
+		text_length:  C.size_t(len(token.Text)),
+		start_offset: C.int64_t(token.Span.Start.Offset),
+		end_offset:   C.int64_t(token.Span.End.Offset),
+		start_line:   C.int32_t(token.Span.Start.Line),
+		start_column: C.int32_t(token.Span.Start.Column),
+		end_line:     C.int32_t(token.Span.End.Line),
+		end_column:   C.int32_t(token.Span.End.Column),
+	}
+}
+
+//export lexer_free
+func lexer_free(h C.lexer_handle) {
+	state := cgo.Handle(h).Value().(*handle)
+	C.free(unsafe.Pointer(state.text))
+	cgo.Handle(h).Delete()
+}
+
+//export lexer_token_name
+func lexer_token_name(tokenType C.int32_t) *C.char {
+	namesMutex.Lock()
+	defer namesMutex.Unlock()
+
+	name, ok := names[lexer.TokenType(tokenType)]
+	if !ok {
+		name = C.CString(language.names.Name(lexer.TokenType(tokenType)))
+		names[lexer.TokenType(tokenType)] = name
+	}
+
+	return name
+}
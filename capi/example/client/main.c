@@ -0,0 +1,18 @@
+#include <stdio.h>
+#include <string.h>
+
+#include "lexer.h"
+
+int main(int argc, char **argv) {
+	const char *source = argc > 1 ? argv[1] : "2 * (x + 1)";
+	lexer_handle l = lexer_new(source, strlen(source));
+	lexer_token token;
+
+	do {
+		token = lexer_next(l);
+		printf("%d:%d %s %.*s\n", token.start_line, token.start_column, lexer_token_name(token.type), (int) token.text_length, token.text);
+	} while (token.type != LEXER_TOKEN_EOF);
+
+	lexer_free(l);
+	return 0;
+}
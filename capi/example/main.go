@@ -0,0 +1,22 @@
+//go:build cgo
+
+/*
+Command example builds the calc preset as a C library, with client/main.c as a
+program using it:
+
+	go build -buildmode=c-shared -o libcalc.so .
+	cc -I.. -o calc client/main.c -L. -lcalc
+	LD_LIBRARY_PATH=. ./calc "2 * (x + 1)"
+*/
+package main
+
+import (
+	"github.com/adampresley/lexer/capi"
+	"github.com/adampresley/lexer/presets/calc"
+)
+
+func init() {
+	capi.SetLanguage(calc.Start, calc.Names)
+}
+
+func main() {}
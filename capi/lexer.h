@@ -0,0 +1,65 @@
+/*
+lexer.h declares the C interface of a shared library built from a lexer
+with the capi package. See the package documentation for how to build
+one.
+*/
+#ifndef ADAMPRESLEY_LEXER_H
+#define ADAMPRESLEY_LEXER_H
+
+#include <stddef.h>
+#include <stdint.h>
+
+#define LEXER_TOKEN_EOF -1
+#define LEXER_TOKEN_ERROR -2
+#define LEXER_TOKEN_TRIVIA -3
+
+/*
+lexer_handle is an opaque reference to a running lexer
+*/
+typedef uintptr_t lexer_handle;
+
+/*
+lexer_token is a token. text is owned by the lexer and stays valid until
+the next call to lexer_next or lexer_free with the same handle. Offsets
+are in bytes; lines and columns count from 1.
+*/
+typedef struct {
+	int32_t type;
+	const char *text;
+	size_t text_length;
+	int64_t start_offset;
+	int64_t end_offset;
+	int32_t start_line;
+	int32_t start_column;
+	int32_t end_line;
+	int32_t end_column;
+} lexer_token;
+
+#ifndef LEXER_NO_PROTOTYPES
+
+/*
+lexer_new starts lexing length bytes of input, which are copied, so the
+caller may free them at once
+*/
+lexer_handle lexer_new(const char *input, size_t length);
+
+/*
+lexer_next returns the next token. After the token of type
+LEXER_TOKEN_EOF it keeps returning EOF tokens.
+*/
+lexer_token lexer_next(lexer_handle handle);
+
+/*
+lexer_free releases a lexer and the text of its last token
+*/
+void lexer_free(lexer_handle handle);
+
+/*
+lexer_token_name returns the name of a token type. The string is owned
+by the library and is never freed.
+*/
+const char *lexer_token_name(int32_t type);
+
+#endif
+
+#endif
@@ -0,0 +1,180 @@
+//go:build chroma
+
+/*
+Package chroma exposes lexers of this package as chroma lexers, so a
+language with a lexer here is highlighted by everything built on chroma,
+such as Hugo, Goldmark and Gitea, with the same tokens as its own tools.
+With the package imported as lexchroma:
+
+	lexers.Register(lexchroma.New(&chroma.Config{
+		Name:      "SQL dialect",
+		Aliases:   []string{"mysql-ish"},
+		Filenames: []string{"*.msql"},
+	}, sql.Start, sql.Categories))
+
+Token types are given chroma token types through their highlighting
+categories, which presets already declare, and Map overrides the type
+of single token types where a category is too coarse.
+
+The package needs chroma v2 and is only built with the chroma build tag,
+so that the lexer package itself does not depend on it.
+*/
+package chroma
+
+import (
+	"strings"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+	"github.com/alecthomas/chroma/v2"
+)
+
+var categoryTypes = map[highlight.Category]chroma.TokenType{
+	highlight.CATEGORY_NONE:         chroma.Text,
+	highlight.CATEGORY_KEYWORD:      chroma.Keyword,
+	highlight.CATEGORY_IDENTIFIER:   chroma.Name,
+	highlight.CATEGORY_TYPE:         chroma.KeywordType,
+	highlight.CATEGORY_FUNCTION:     chroma.NameFunction,
+	highlight.CATEGORY_STRING:       chroma.LiteralString,
+	highlight.CATEGORY_NUMBER:       chroma.LiteralNumber,
+	highlight.CATEGORY_LITERAL:      chroma.Literal,
+	highlight.CATEGORY_COMMENT:      chroma.Comment,
+	highlight.CATEGORY_OPERATOR:     chroma.Operator,
+	highlight.CATEGORY_PUNCTUATION:  chroma.Punctuation,
+	highlight.CATEGORY_PREPROCESSOR: chroma.CommentPreproc,
+	highlight.CATEGORY_ERROR:        chroma.Error,
+}
+
+/*
+Lexer implements chroma.Lexer over a state function. Text between
+tokens, such as whitespace the lexer ignores, is passed to chroma as
+chroma.Text, so the tokens always add up to the text highlighted.
+*/
+type Lexer struct {
+	config     *chroma.Config
+	start      lexer.LexFn
+	categories highlight.Categories
+	types      map[lexer.TokenType]chroma.TokenType
+	registry   *chroma.LexerRegistry
+	analyser   func(text string) float32
+}
+
+/*
+New creates a chroma lexer described by config, lexing with start and
+giving tokens the chroma type of their category in categories
+*/
+func New(config *chroma.Config, start lexer.LexFn, categories highlight.Categories) *Lexer {
+	return &Lexer{
+		config:     config,
+		start:      start,
+		categories: categories,
+		types:      map[lexer.TokenType]chroma.TokenType{},
+	}
+}
+
+/*
+Map gives tokens of tokenType the chroma type chromaType, in place of
+the type of their category
+*/
+func (adapter *Lexer) Map(tokenType lexer.TokenType, chromaType chroma.TokenType) *Lexer {
+	adapter.types[tokenType] = chromaType
+	return adapter
+}
+
+/*
+Config returns the configuration the lexer was created with
+*/
+func (adapter *Lexer) Config() *chroma.Config {
+	return adapter.config
+}
+
+/*
+Tokenise lexes text and returns an iterator over its chroma tokens. The
+lexer runs to the end of text before the first token is returned.
+*/
+func (adapter *Lexer) Tokenise(options *chroma.TokeniseOptions, text string) (chroma.Iterator, error) {
+	if options != nil && options.EnsureLF {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\r", "\n")
+	}
+
+	tokens := adapter.tokens(text, lexer.NewLexer(adapter.config.Name, text, adapter.start).Collect())
+	index := 0
+
+	return func() chroma.Token {
+		if index >= len(tokens) {
+			return chroma.EOF
+		}
+
+		index++
+		return tokens[index-1]
+	}, nil
+}
+
+/*
+tokens converts the tokens of text to chroma tokens covering all of it
+*/
+func (adapter *Lexer) tokens(text string, tokens []lexer.Token) []chroma.Token {
+	result := []chroma.Token{}
+	pos := 0
+
+	for _, token := range tokens {
+		start, end := token.Span.Start.Offset, token.Span.End.Offset
+		if start < pos || end <= start || end > len(text) {
+			continue
+		}
+
+		if start > pos {
+			result = append(result, chroma.Token{Type: chroma.Text, Value: text[pos:start]})
+		}
+
+		result = append(result, chroma.Token{Type: adapter.chromaType(token.Type), Value: text[start:end]})
+		pos = end
+	}
+
+	if pos < len(text) {
+		result = append(result, chroma.Token{Type: chroma.Text, Value: text[pos:]})
+	}
+
+	return result
+}
+
+/*
+chromaType returns the chroma type of tokens of tokenType
+*/
+func (adapter *Lexer) chromaType(tokenType lexer.TokenType) chroma.TokenType {
+	if chromaType, ok := adapter.types[tokenType]; ok {
+		return chromaType
+	}
+
+	return categoryTypes[adapter.categories.Category(tokenType)]
+}
+
+/*
+SetRegistry records the registry the lexer is registered with
+*/
+func (adapter *Lexer) SetRegistry(registry *chroma.LexerRegistry) chroma.Lexer {
+	adapter.registry = registry
+	return adapter
+}
+
+/*
+SetAnalyser sets the function AnalyseText uses to score text
+*/
+func (adapter *Lexer) SetAnalyser(analyser func(text string) float32) chroma.Lexer {
+	adapter.analyser = analyser
+	return adapter
+}
+
+/*
+AnalyseText scores how likely text is to be in the lexer's language,
+for chroma's lexer detection. Without an analyser it is 0, so the lexer
+is only chosen by name or file name.
+*/
+func (adapter *Lexer) AnalyseText(text string) float32 {
+	if adapter.analyser == nil {
+		return 0
+	}
+
+	return adapter.analyser(text)
+}
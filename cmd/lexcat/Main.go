@@ -0,0 +1,239 @@
+/*
+Command lexcat runs a lexer over files, or standard input, and prints
+the tokens, for trying a lexer out without writing a program around it.
+The lexer is one of the presets, named with -lexer, or is read from a
+rule file with -rules, in any of the forms lexgen reads: a grammar file,
+a flex scanner with the .l extension or terminal definitions with the
+.ebnf extension.
+
+	lexcat [-lexer name | -rules file] [-format table|ndjson|highlight] [file ...]
+	lexcat [-lexer name | -rules file] -debug file
+
+Without files, or for a file named -, standard input is lexed. -format
+table prints a table of the tokens of each file, ndjson a JSON object
+per token, as tokenio.NDJSONWriter writes them, and highlight the input
+with ANSI colors, as highlight.ANSI renders it. Tokens of rule files are
+highlighted by words in their names, such as COMMENT or STRING. -list
+prints the names of the presets.
+
+-debug steps through the lexer on a single file instead, with commands
+read from standard input, as described by debugger.Console: step runs
+one state function at a time, break stops at a token type or state, and
+continue runs to the next breakpoint, or to where a state function is
+stuck without making progress.
+
+Errors of the lexer are printed to standard error. lexcat exits with 1
+if the lexer reported an error in any file, and with 2 if it could not
+run at all, such as for a file that could not be read.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/debugger"
+	"github.com/adampresley/lexer/highlight"
+	"github.com/adampresley/lexer/rules"
+	"github.com/adampresley/lexer/tokenio"
+)
+
+/*
+errLexing is returned by lexFile when the lexer reported errors, which
+have been printed already
+*/
+var errLexing = errors.New("lexing failed")
+
+func main() {
+	presetName := flag.String("lexer", "", "name of the preset lexer to run")
+	ruleFile := flag.String("rules", "", "rule file to read the lexer from")
+	format := flag.String("format", "table", "output format: table, ndjson or highlight")
+	list := flag.Bool("list", false, "print the names of the presets and exit")
+	debug := flag.Bool("debug", false, "step through the lexer on a file, reading commands from standard input")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: lexcat [-lexer name | -rules file] [-format table|ndjson|highlight] [file ...]\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "       lexcat [-lexer name | -rules file] -debug file\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if *list {
+		fmt.Println(strings.Join(presetNames(), "\n"))
+		return
+	}
+
+	if (*presetName == "") == (*ruleFile == "") || !validFormat(*format) || *debug && (flag.NArg() != 1 || flag.Arg(0) == "-") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	lexerDefinition, err := load(*presetName, *ruleFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lexcat:", err)
+		os.Exit(2)
+	}
+
+	if *debug {
+		if err := debugFile(lexerDefinition, flag.Arg(0)); err != nil {
+			fmt.Fprintln(os.Stderr, "lexcat:", err)
+			os.Exit(2)
+		}
+
+		return
+	}
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	status := 0
+
+	for _, path := range paths {
+		err := lexFile(os.Stdout, lexerDefinition, path, *format, len(paths) > 1)
+
+		switch {
+		case errors.Is(err, errLexing):
+			status = max(status, 1)
+
+		case err != nil:
+			fmt.Fprintln(os.Stderr, "lexcat:", err)
+			status = 2
+		}
+	}
+
+	os.Exit(status)
+}
+
+func validFormat(format string) bool {
+	return format == "table" || format == "ndjson" || format == "highlight"
+}
+
+/*
+load returns the preset named presetName, or the lexer of ruleFile
+*/
+func load(presetName string, ruleFile string) (definition, error) {
+	if presetName != "" {
+		preset, ok := presets[presetName]
+		if !ok {
+			return definition{}, fmt.Errorf("no preset named %s; -list prints them", presetName)
+		}
+
+		return preset, nil
+	}
+
+	file, err := os.Open(ruleFile)
+	if err != nil {
+		return definition{}, err
+	}
+
+	defer file.Close()
+
+	var grammar *rules.Grammar
+
+	switch filepath.Ext(ruleFile) {
+	case ".l":
+		grammar, err = rules.ImportFlex(ruleFile, file)
+
+	case ".ebnf":
+		grammar, err = rules.ParseEBNF(ruleFile, file)
+
+	default:
+		grammar, err = rules.ParseGrammar(ruleFile, file)
+	}
+
+	if err != nil {
+		return definition{}, err
+	}
+
+	start, err := grammar.LexFn()
+	if err != nil {
+		return definition{}, err
+	}
+
+	names := grammar.Names()
+	return definition{start: start, names: names, categories: guessCategories(names)}, nil
+}
+
+/*
+lexFile lexes the file at path, or standard input for -, and writes its
+tokens to w in format. With heading the table of each file is headed by
+its name. Errors of the lexer are printed to standard error.
+*/
+func lexFile(w io.Writer, lexerDefinition definition, path string, format string, heading bool) error {
+	var (
+		input []byte
+		err   error
+	)
+
+	if path == "-" {
+		input, err = io.ReadAll(os.Stdin)
+		path = "<stdin>"
+	} else {
+		input, err = os.ReadFile(path)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	l := lexer.NewLexer(path, string(input), lexerDefinition.start)
+	tokens := l.Collect()
+
+	switch format {
+	case "table":
+		if heading {
+			fmt.Fprintf(w, "==> %s <==\n", path)
+		}
+
+		err = tokenio.DumpTable(w, lexerDefinition.names, tokens...)
+
+	case "ndjson":
+		err = tokenio.WriteNDJSON(w, lexerDefinition.names, tokens...)
+
+	case "highlight":
+		err = highlight.ANSI{Categories: lexerDefinition.categories}.Render(w, l.Input, tokens)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	failed := false
+
+	for _, diagnostic := range l.Diagnostics() {
+		fmt.Fprintln(os.Stderr, diagnostic)
+		failed = failed || diagnostic.Severity == lexer.SEVERITY_ERROR
+	}
+
+	if failed {
+		return errLexing
+	}
+
+	return nil
+}
+
+/*
+debugFile steps through the lexer on the file at path, with commands
+read from standard input, which is why the file can not be standard
+input as well
+*/
+func debugFile(lexerDefinition definition, path string) error {
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	l := lexer.NewLexer(path, string(input), lexerDefinition.start)
+	fmt.Println("help lists the commands")
+
+	return debugger.Console(debugger.New(l, lexerDefinition.names), os.Stdin, os.Stdout)
+}
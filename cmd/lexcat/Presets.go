@@ -0,0 +1,114 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+	"github.com/adampresley/lexer/presets/address"
+	"github.com/adampresley/lexer/presets/calc"
+	"github.com/adampresley/lexer/presets/config"
+	"github.com/adampresley/lexer/presets/css"
+	"github.com/adampresley/lexer/presets/csv"
+	"github.com/adampresley/lexer/presets/dockerfile"
+	"github.com/adampresley/lexer/presets/dotenv"
+	"github.com/adampresley/lexer/presets/golike"
+	"github.com/adampresley/lexer/presets/http"
+	"github.com/adampresley/lexer/presets/json"
+	"github.com/adampresley/lexer/presets/logformat"
+	"github.com/adampresley/lexer/presets/markdown"
+	"github.com/adampresley/lexer/presets/querystring"
+	"github.com/adampresley/lexer/presets/sexpr"
+	"github.com/adampresley/lexer/presets/shell"
+	"github.com/adampresley/lexer/presets/sql"
+	"github.com/adampresley/lexer/presets/template"
+	"github.com/adampresley/lexer/presets/uri"
+	"github.com/adampresley/lexer/presets/yaml"
+)
+
+/*
+definition is what lexcat needs of a lexer: where it starts, and how to
+name and highlight its tokens
+*/
+type definition struct {
+	start      lexer.LexFn
+	names      lexer.TokenNames
+	categories highlight.Categories
+}
+
+/*
+presets holds the lexers of the presets directory by name. Presets
+configured by a value get an entry for each of their common
+configurations.
+*/
+var presets = map[string]definition{
+	"accesslog":   {logformat.FORMAT_ACCESS.Start(), logformat.Names, logformat.Categories},
+	"address":     {address.Start, address.Names, address.Categories},
+	"calc":        {calc.Start, calc.Names, calc.Categories},
+	"config":      {config.Start, config.Names, config.Categories},
+	"css":         {css.Start, css.Names, css.Categories},
+	"csv":         {csv.DefaultDialect.Start(), csv.Names, csv.Categories},
+	"dockerfile":  {dockerfile.Start, dockerfile.Names, dockerfile.Categories},
+	"dotenv":      {dotenv.Start, dotenv.Names, dotenv.Categories},
+	"erb":         {template.ERBSyntax.Start(), template.Names, template.Categories},
+	"golike":      {golike.Start, golike.Names, golike.Categories},
+	"http":        {http.Start, http.Names, http.Categories},
+	"json":        {json.Start, json.Names, json.Categories},
+	"logfmt":      {logformat.FORMAT_LOGFMT.Start(), logformat.Names, logformat.Categories},
+	"markdown":    {markdown.Start, markdown.Names, markdown.Categories},
+	"querystring": {querystring.Start, querystring.Names, querystring.Categories},
+	"sexpr":       {sexpr.Start, sexpr.Names, sexpr.Categories},
+	"shell":       {shell.Start, shell.Names, shell.Categories},
+	"sql":         {sql.Start, sql.Names, sql.Categories},
+	"template":    {template.DefaultSyntax.Start(), template.Names, template.Categories},
+	"tsv":         {csv.TabDialect.Start(), csv.Names, csv.Categories},
+	"uri":         {uri.Start, uri.Names, uri.Categories},
+	"yaml":        {yaml.Start, yaml.Names, yaml.Categories},
+}
+
+/*
+presetNames returns the names of the presets in order
+*/
+func presetNames() []string {
+	names := make([]string, 0, len(presets))
+
+	for name := range presets {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+/*
+guessCategories picks highlighting categories for tokens read from a
+rule file by words in their names, as rule files do not say how their
+tokens are highlighted
+*/
+func guessCategories(names lexer.TokenNames) highlight.Categories {
+	categories := highlight.Categories{}
+
+	for tokenType, name := range names {
+		name = strings.ToUpper(name)
+
+		switch {
+		case strings.Contains(name, "COMMENT"):
+			categories[tokenType] = highlight.CATEGORY_COMMENT
+
+		case strings.Contains(name, "KEYWORD"):
+			categories[tokenType] = highlight.CATEGORY_KEYWORD
+
+		case strings.Contains(name, "STRING"), strings.Contains(name, "CHAR"):
+			categories[tokenType] = highlight.CATEGORY_STRING
+
+		case strings.Contains(name, "NUMBER"), strings.Contains(name, "INT"), strings.Contains(name, "FLOAT"):
+			categories[tokenType] = highlight.CATEGORY_NUMBER
+
+		case strings.Contains(name, "IDENT"):
+			categories[tokenType] = highlight.CATEGORY_IDENTIFIER
+		}
+	}
+
+	return categories
+}
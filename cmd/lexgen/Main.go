@@ -0,0 +1,219 @@
+/*
+Command lexgen generates a Go lexer from a lexical grammar file, so that
+a lexer can be reviewed as data and regenerated reproducibly. The
+grammar format is described by rules.Grammar. A file with the .l
+extension is read as a flex scanner instead, as described by
+rules.ImportFlex, which helps move an existing scanner to this package,
+and a file with the .ebnf extension as terminal definitions, as
+described by rules.ParseEBNF, which needs -package.
+
+	lexgen [-o output.go] [-package name] [-overlaps] [-dfa] [-corpus glob] [-test snippet] grammar.lex
+
+Without -o the generated file is written next to the grammar, named
+after it with a .go extension. -package overrides the package declared
+in the grammar. -dfa writes each state's DFA out as Go code, as
+described by rules.Grammar.WriteGoDFA, instead of building it from the rules
+at start up. Rules that can never match are reported as warnings,
+along with rules that overlap when -overlaps is given. -corpus lexes the
+files matching a glob pattern as samples and reports how often each
+rule fired and which characters no rule matched, as described by
+rules.Coverage, failing if a rule never fired. -test lexes a snippet,
+or standard input for -, and prints for each token which rule matched
+and why the others lost, as described by rules.Machine.Test, instead of
+writing the lexer, for trying rules out while writing them. The usual
+way to run it is from a go:generate comment:
+
+	//go:generate go run github.com/adampresley/lexer/cmd/lexgen tiny.lex
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adampresley/lexer/rules"
+)
+
+func main() {
+	output := flag.String("o", "", "file to write the generated lexer to")
+	packageName := flag.String("package", "", "package of the generated lexer, overriding the grammar")
+	overlaps := flag.Bool("overlaps", false, "report rules that overlap as well as rules that can never match")
+	dfa := flag.Bool("dfa", false, "write the DFAs out as Go code instead of building them at start up")
+	corpus := flag.String("corpus", "", "glob pattern of sample files to check the rules' coverage against")
+	test := flag.String("test", "", "snippet to lex and trace instead of writing the lexer, or - for standard input")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: lexgen [-o output.go] [-package name] [-overlaps] [-dfa] [-corpus glob] [-test snippet] grammar.lex\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *output, *packageName, *overlaps, *dfa, *corpus, *test); err != nil {
+		fmt.Fprintln(os.Stderr, "lexgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(input string, output string, packageName string, overlaps bool, dfa bool, corpus string, test string) error {
+	file, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	var grammar *rules.Grammar
+
+	switch filepath.Ext(input) {
+	case ".l":
+		grammar, err = rules.ImportFlex(input, file)
+
+	case ".ebnf":
+		grammar, err = rules.ParseEBNF(input, file)
+
+	default:
+		grammar, err = rules.ParseGrammar(input, file)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if packageName != "" {
+		grammar.Package = packageName
+	}
+
+	if err := reportConflicts(input, grammar, overlaps); err != nil {
+		return err
+	}
+
+	if corpus != "" {
+		if err := reportCoverage(grammar, corpus); err != nil {
+			return err
+		}
+	}
+
+	if test != "" {
+		return traceSnippet(grammar, test)
+	}
+
+	if output == "" {
+		output = strings.TrimSuffix(input, filepath.Ext(input)) + ".go"
+	}
+
+	write := grammar.WriteGo
+	if dfa {
+		write = grammar.WriteGoDFA
+	}
+
+	buffer := bytes.Buffer{}
+	if err := write(&buffer, filepath.Base(input)); err != nil {
+		return err
+	}
+
+	return os.WriteFile(output, buffer.Bytes(), 0o644)
+}
+
+/*
+reportConflicts warns about rules of the grammar that can never match,
+and with overlaps about rules that overlap, giving the grammar line of
+the losing rule
+*/
+func reportConflicts(input string, grammar *rules.Grammar, overlaps bool) error {
+	conflicts, err := grammar.RuleSet(nil).Conflicts()
+	if err != nil {
+		return err
+	}
+
+	var lines, states []string
+	for _, state := range grammar.States {
+		for _, rule := range state.Rules {
+			lines = append(lines, fmt.Sprintf("%s:%d", input, rule.Line))
+			states = append(states, state.Name)
+		}
+	}
+
+	for _, conflict := range conflicts {
+		if conflict.Kind == rules.CONFLICT_SHADOWED || overlaps {
+			fmt.Fprintf(os.Stderr, "%s: %s: %s\n", lines[conflict.Rule], states[conflict.Rule], conflict)
+		}
+	}
+
+	return nil
+}
+
+/*
+reportCoverage lexes the files matching corpus with the grammar and
+prints how its rules fared, failing if a rule never fired
+*/
+func reportCoverage(grammar *rules.Grammar, corpus string) error {
+	paths, err := filepath.Glob(corpus)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("no files match %s", corpus)
+	}
+
+	machine, err := grammar.RuleSet(nil).Compile()
+	if err != nil {
+		return err
+	}
+
+	coverage := machine.Coverage()
+
+	for _, path := range paths {
+		sample, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		coverage.Lex(path, string(sample))
+	}
+
+	fmt.Fprint(os.Stderr, coverage)
+
+	if unused := coverage.Unused(); len(unused) > 0 {
+		return fmt.Errorf("%d rules never fired on %s", len(unused), corpus)
+	}
+
+	return nil
+}
+
+/*
+traceSnippet lexes snippet with the grammar, or standard input for -,
+and prints a line for each token
+*/
+func traceSnippet(grammar *rules.Grammar, snippet string) error {
+	if snippet == "-" {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		snippet = string(input)
+	}
+
+	steps, err := grammar.RuleSet(nil).Test(snippet)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		fmt.Println(step)
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/tokenio"
+)
+
+/*
+pendingWidth is the number of characters of the token in progress shown
+before it is truncated
+*/
+const pendingWidth = 60
+
+const consoleHelp = `commands:
+  step [n], s [n]         run n state functions, 1 by default
+  continue, c             run until a breakpoint, a stuck state or the end
+  break token TYPE        stop after a token of TYPE, a name or a number
+  break state NAME        stop before the state NAME runs
+  breakpoints             list the breakpoints
+  delete, d               remove every breakpoint
+  where, w                show where the lexer is
+  tokens                  list the tokens emitted so far
+  help, h                 show this help
+  quit, q                 stop debugging
+An empty line repeats the last command.
+`
+
+/*
+Console drives a debugger from commands read from in, a line at a time,
+writing where the lexer stops to out, until the quit command or the end
+of in. It is meant for a terminal; help lists the commands. Each stop
+shows the line of input around the lexer's position, with the token in
+progress marked:
+
+	step 4 (step), state calc.start, at input:1:5
+	  1 + 2 * x
+	      ^
+	  pending ""
+	  emitted PLUS "+"
+*/
+func Console(d *Debugger, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	last := ""
+
+	writeStop(out, d, d.Where())
+
+	for {
+		fmt.Fprint(out, "(lexdebug) ")
+
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			line = last
+		}
+
+		last = line
+		fields := strings.Fields(line)
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "step", "s":
+			count := 1
+
+			if len(fields) > 1 {
+				n, err := strconv.Atoi(fields[1])
+				if err != nil || n < 1 {
+					fmt.Fprintf(out, "not a number of steps: %s\n", fields[1])
+					continue
+				}
+
+				count = n
+			}
+
+			stop := d.Step()
+			for index := 1; index < count && stop.Reason != STOP_END; index++ {
+				stop = d.Step()
+			}
+
+			writeStop(out, d, stop)
+
+		case "continue", "c":
+			writeStop(out, d, d.Continue())
+
+		case "break", "b":
+			if err := addBreakpoint(d, fields[1:]); err != nil {
+				fmt.Fprintln(out, err)
+			}
+
+		case "breakpoints":
+			writeBreakpoints(out, d)
+
+		case "delete", "d":
+			d.ClearBreakpoints()
+
+		case "where", "w":
+			writeStop(out, d, d.Where())
+
+		case "tokens":
+			tokenio.DumpTable(out, d.names, d.Tokens()...)
+
+		case "help", "h":
+			fmt.Fprint(out, consoleHelp)
+
+		case "quit", "q":
+			return nil
+
+		default:
+			fmt.Fprintf(out, "unknown command %q; help lists the commands\n", fields[0])
+		}
+	}
+}
+
+/*
+addBreakpoint adds the breakpoint described by the arguments of a break
+command
+*/
+func addBreakpoint(d *Debugger, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: break token TYPE | break state NAME")
+	}
+
+	switch args[0] {
+	case "token":
+		tokenType, ok := d.tokenType(args[1])
+		if !ok {
+			return fmt.Errorf("no token type %s", args[1])
+		}
+
+		d.BreakOnToken(tokenType)
+
+	case "state":
+		d.BreakOnState(args[1])
+
+	default:
+		return fmt.Errorf("usage: break token TYPE | break state NAME")
+	}
+
+	return nil
+}
+
+/*
+tokenType looks up a token type by its name or number
+*/
+func (d *Debugger) tokenType(text string) (lexer.TokenType, bool) {
+	for tokenType, name := range d.names {
+		if name == text {
+			return tokenType, true
+		}
+	}
+
+	for _, tokenType := range []lexer.TokenType{lexer.TOKEN_EOF, lexer.TOKEN_ERROR} {
+		if d.names.Name(tokenType) == text {
+			return tokenType, true
+		}
+	}
+
+	if number, err := strconv.Atoi(text); err == nil {
+		return lexer.TokenType(number), true
+	}
+
+	return 0, false
+}
+
+func writeBreakpoints(out io.Writer, d *Debugger) {
+	for tokenType := range d.tokenBreakpoints {
+		fmt.Fprintf(out, "token %s\n", d.names.Name(tokenType))
+	}
+
+	for name := range d.stateBreakpoints {
+		fmt.Fprintf(out, "state %s\n", name)
+	}
+}
+
+/*
+writeStop writes where the debugger stopped: the step, state and
+position, the line of input around the position with the token in
+progress marked, and the tokens the last step emitted
+*/
+func writeStop(out io.Writer, d *Debugger, stop Stop) {
+	state := stop.State
+	if state == "" {
+		state = "(none)"
+	}
+
+	fmt.Fprintf(out, "step %d (%s), state %s, at %s\n", stop.Step, stop.Reason, state, stop.Position)
+
+	l := d.Lexer()
+	lineStart := strings.LastIndexByte(l.Input[:l.Pos], '\n') + 1
+	lineEnd := len(l.Input)
+
+	if newline := strings.IndexByte(l.Input[l.Pos:], '\n'); newline >= 0 {
+		lineEnd = l.Pos + newline
+	}
+
+	// The token in progress is marked from its start, or the start of
+	// the line, to the position, and the position alone when it is empty
+	markStart := max(l.Start, lineStart)
+	marks := max(1, utf8.RuneCountInString(l.Input[markStart:l.Pos]))
+
+	fmt.Fprintf(out, "  %s\n", strings.TrimSuffix(l.Input[lineStart:lineEnd], "\r"))
+	fmt.Fprintf(out, "  %s%s\n", indent(l.Input[lineStart:markStart]), strings.Repeat("^", marks))
+
+	pending := stop.Pending
+	if utf8.RuneCountInString(pending) > pendingWidth {
+		pending = string([]rune(pending)[:pendingWidth]) + "..."
+	}
+
+	fmt.Fprintf(out, "  pending %q\n", pending)
+
+	for _, token := range stop.Tokens {
+		fmt.Fprintf(out, "  emitted %s %q\n", d.names.Name(token.Type), token.Text)
+	}
+}
+
+/*
+indent returns blanks as wide as text, keeping its tabs so the marks
+line up under the text
+*/
+func indent(text string) string {
+	var result strings.Builder
+
+	for _, ch := range text {
+		if ch == '\t' {
+			result.WriteByte('\t')
+		} else {
+			result.WriteByte(' ')
+		}
+	}
+
+	return result.String()
+}
@@ -0,0 +1,280 @@
+/*
+Package debugger single-steps a lexer through its state functions, for
+finding out why a lexer loops or splits its input into the wrong
+tokens. Each step runs one state function and stops, describing where
+the lexer is: the state it runs next, its position, the text of the
+token in progress and the tokens the step emitted.
+
+	d := debugger.New(calc.NewLexer("input", source), calc.Names)
+	d.BreakOnToken(calc.TOKEN_IDENTIFIER)
+
+	stop := d.Continue()
+	fmt.Println(stop.Reason, stop.State, stop.Position, stop.Pending)
+
+Continue runs until a breakpoint, on a token type or a state, or until
+the lexer is stuck, taking many steps without consuming input or
+emitting a token. Console drives a debugger from commands typed at a
+terminal; the lexcat command's -debug flag runs one.
+*/
+package debugger
+
+import (
+	"github.com/adampresley/lexer"
+)
+
+/*
+A StopReason tells why the debugger stopped
+*/
+type StopReason int
+
+const (
+	// STOP_STEP is a stop after a single step
+	STOP_STEP StopReason = iota
+
+	// STOP_TOKEN is a stop after a step emitted a token of a type with a
+	// breakpoint
+	STOP_TOKEN
+
+	// STOP_STATE is a stop before a state with a breakpoint runs
+	STOP_STATE
+
+	// STOP_STUCK is a stop after STUCK_STEPS steps in a row neither
+	// moved through the input nor emitted a token
+	STOP_STUCK
+
+	// STOP_END is a stop once the last state function has returned nil
+	STOP_END
+)
+
+/*
+STUCK_STEPS is how many steps in a row without progress make Continue
+stop with STOP_STUCK
+*/
+const STUCK_STEPS = 1000
+
+var stopReasonNames = [...]string{
+	STOP_STEP:  "step",
+	STOP_TOKEN: "token breakpoint",
+	STOP_STATE: "state breakpoint",
+	STOP_STUCK: "stuck",
+	STOP_END:   "end of input",
+}
+
+/*
+String returns a short description of the reason
+*/
+func (reason StopReason) String() string {
+	if reason >= 0 && int(reason) < len(stopReasonNames) {
+		return stopReasonNames[reason]
+	}
+
+	return "unknown"
+}
+
+/*
+Stop describes the lexer where the debugger stopped. Step counts the
+state functions run so far and State names the one to run next, which
+is empty at the end. Pending is the text of the token in progress,
+from the lexer's start position to its position, and Tokens holds the
+tokens emitted by the last step.
+*/
+type Stop struct {
+	Reason   StopReason
+	Step     int
+	State    string
+	Position lexer.Position
+	Pending  string
+	Tokens   []lexer.Token
+}
+
+/*
+Debugger steps a lexer through its state functions. It takes over the
+lexer's tokens, so the lexer must not be run by anything else, and it
+keeps every token emitted, returned by Tokens.
+*/
+type Debugger struct {
+	lexer *lexer.Lexer
+	names lexer.TokenNames
+
+	tokenBreakpoints map[lexer.TokenType]bool
+	stateBreakpoints map[string]bool
+
+	tokens  []lexer.Token
+	emitted int
+	steps   int
+	idle    int
+}
+
+/*
+New creates a debugger stepping l from the state it is in. names names
+token types for breakpoints and Console.
+*/
+func New(l *lexer.Lexer, names lexer.TokenNames) *Debugger {
+	d := &Debugger{
+		lexer:            l,
+		names:            names,
+		tokenBreakpoints: map[lexer.TokenType]bool{},
+		stateBreakpoints: map[string]bool{},
+	}
+
+	lexer.WithEmitter(func(token lexer.Token) {
+		d.tokens = append(d.tokens, token)
+	})(l)
+
+	return d
+}
+
+/*
+Lexer returns the lexer being debugged
+*/
+func (d *Debugger) Lexer() *lexer.Lexer {
+	return d.lexer
+}
+
+/*
+Tokens returns every token emitted so far
+*/
+func (d *Debugger) Tokens() []lexer.Token {
+	return d.tokens
+}
+
+/*
+Done returns true once the lexer has no state left to run
+*/
+func (d *Debugger) Done() bool {
+	return d.lexer.State == nil
+}
+
+/*
+BreakOnToken makes Continue stop after a step emitting a token of
+tokenType
+*/
+func (d *Debugger) BreakOnToken(tokenType lexer.TokenType) {
+	d.tokenBreakpoints[tokenType] = true
+}
+
+/*
+BreakOnState makes Continue stop before running the state named name,
+as StateName names it
+*/
+func (d *Debugger) BreakOnState(name string) {
+	d.stateBreakpoints[name] = true
+}
+
+/*
+ClearBreakpoints removes every breakpoint
+*/
+func (d *Debugger) ClearBreakpoints() {
+	clear(d.tokenBreakpoints)
+	clear(d.stateBreakpoints)
+}
+
+/*
+Where describes the lexer as it is, without running it
+*/
+func (d *Debugger) Where() Stop {
+	reason := STOP_STEP
+	if d.Done() {
+		reason = STOP_END
+	}
+
+	return d.stop(reason)
+}
+
+/*
+Step runs one state function and stops. At the end of the input it
+stops with STOP_END without running anything.
+*/
+func (d *Debugger) Step() Stop {
+	if d.Done() {
+		d.emitted = len(d.tokens)
+		return d.stop(STOP_END)
+	}
+
+	d.step()
+
+	if d.Done() {
+		return d.stop(STOP_END)
+	}
+
+	return d.stop(STOP_STEP)
+}
+
+/*
+Continue steps until a step emits a token of a type with a breakpoint,
+the next state has a breakpoint, the lexer is stuck or the input ends.
+The state the lexer is in when Continue is called does not stop it, so
+calling Continue again carries on past a state breakpoint.
+*/
+func (d *Debugger) Continue() Stop {
+	for !d.Done() {
+		d.step()
+
+		switch {
+		case d.Done():
+			return d.stop(STOP_END)
+
+		case d.hitToken():
+			return d.stop(STOP_TOKEN)
+
+		case d.stateBreakpoints[d.lexer.StateName()]:
+			return d.stop(STOP_STATE)
+
+		case d.idle >= STUCK_STEPS:
+			return d.stop(STOP_STUCK)
+		}
+	}
+
+	d.emitted = len(d.tokens)
+	return d.stop(STOP_END)
+}
+
+/*
+step runs the state function the lexer is in, counting the steps in a
+row that did not move or emit anything
+*/
+func (d *Debugger) step() {
+	before := d.offset()
+	d.emitted = len(d.tokens)
+
+	d.lexer.Step()
+	d.steps++
+
+	if d.offset() == before && len(d.tokens) == d.emitted {
+		d.idle++
+	} else {
+		d.idle = 0
+	}
+}
+
+/*
+offset returns the offset of the lexer's position in the whole input
+*/
+func (d *Debugger) offset() int {
+	return d.lexer.PositionAt(d.lexer.Pos).Offset
+}
+
+/*
+hitToken returns true if the last step emitted a token of a type with a
+breakpoint
+*/
+func (d *Debugger) hitToken() bool {
+	for _, token := range d.tokens[d.emitted:] {
+		if d.tokenBreakpoints[token.Type] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *Debugger) stop(reason StopReason) Stop {
+	return Stop{
+		Reason:   reason,
+		Step:     d.steps,
+		State:    d.lexer.StateName(),
+		Position: d.lexer.PositionAt(d.lexer.Pos),
+		Pending:  d.lexer.CurrentInput(),
+		Tokens:   d.tokens[d.emitted:],
+	}
+}
@@ -0,0 +1,97 @@
+package debugger
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/rules"
+)
+
+const (
+	TOKEN_WORD lexer.TokenType = iota + 1
+	TOKEN_QUOTE
+	TOKEN_TEXT
+)
+
+var names = lexer.TokenNames{
+	TOKEN_WORD:  "WORD",
+	TOKEN_QUOTE: "QUOTE",
+	TOKEN_TEXT:  "TEXT",
+}
+
+func quotingRules() *rules.RuleSet {
+	return rules.New().
+		Add("word", `[a-z]+`, TOKEN_WORD).
+		Skip("blank", ` +`).
+		Add("open", `"`, TOKEN_QUOTE).Begin("QUOTED").
+		State("QUOTED").
+		Add("text", `[^"]+`, TOKEN_TEXT).
+		Add("close", `"`, TOKEN_QUOTE).Begin("INITIAL")
+}
+
+func quoting() *rules.Machine {
+	return quotingRules().MustCompile()
+}
+
+func TestStepRules(t *testing.T) {
+	machine := quoting()
+	testStepRules(t, New(machine.NewLexer("test", `ab "c d" e`), names))
+}
+
+func TestStepDynamicRules(t *testing.T) {
+	dynamic, err := rules.NewDynamic(quotingRules())
+	if err != nil {
+		t.Fatalf("NewDynamic: %v", err)
+	}
+
+	testStepRules(t, New(dynamic.NewLexer("test", `ab "c d" e`), names))
+}
+
+func testStepRules(t *testing.T, d *Debugger) {
+	t.Helper()
+
+	want := []struct {
+		state  string
+		tokens int
+	}{
+		{"rules.INITIAL", 1},
+		{"rules.INITIAL", 0},
+		{"rules.QUOTED", 1},
+		{"rules.QUOTED", 1},
+		{"rules.INITIAL", 1},
+		{"rules.INITIAL", 0},
+		{"rules.INITIAL", 1},
+	}
+
+	if stop := d.Where(); stop.State != "rules.INITIAL" {
+		t.Fatalf("got state %s before stepping, want rules.INITIAL", stop.State)
+	}
+
+	for index, step := range want {
+		stop := d.Step()
+
+		if stop.State != step.state || len(stop.Tokens) != step.tokens {
+			t.Fatalf("step %d: got state %s and %d tokens, want state %s and %d tokens", index+1, stop.State, len(stop.Tokens), step.state, step.tokens)
+		}
+	}
+
+	if stop := d.Step(); stop.Reason != STOP_END {
+		t.Errorf("got %s after the last token, want %s", stop.Reason, STOP_END)
+	}
+}
+
+func TestBreakOnRuleState(t *testing.T) {
+	machine := quoting()
+	d := New(machine.NewLexer("test", `ab "c d" e`), names)
+	d.BreakOnState("rules.QUOTED")
+
+	stop := d.Continue()
+
+	if stop.Reason != STOP_STATE || stop.State != "rules.QUOTED" {
+		t.Fatalf("got %s in state %s, want %s in rules.QUOTED", stop.Reason, stop.State, STOP_STATE)
+	}
+
+	if tokens := d.Tokens(); len(tokens) != 2 || tokens[1].Type != TOKEN_QUOTE {
+		t.Errorf("got %d tokens at the breakpoint, want WORD and QUOTE", len(tokens))
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Package goscanner wraps a lexer in the API of go/scanner, so that tools
+built around go/scanner, with positions in a token.FileSet and tokens of
+go/token, can run on a lexer of this package instead:
+
+	s := goscanner.New(golike.Start, goscanner.Tokens{
+		golike.TOKEN_IDENTIFIER: token.IDENT,
+		golike.TOKEN_INT:        token.INT,
+		golike.TOKEN_FLOAT:      token.FLOAT,
+		golike.TOKEN_IMAGINARY:  token.IMAG,
+		golike.TOKEN_STRING:     token.STRING,
+		golike.TOKEN_RAW_STRING: token.STRING,
+		golike.TOKEN_RUNE:       token.CHAR,
+		golike.TOKEN_COMMENT:    token.COMMENT,
+	})
+
+	file := fset.AddFile(name, fset.Base(), len(src))
+	s.Init(file, src, nil, goscanner.ScanComments|goscanner.InsertSemicolons)
+
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		...
+	}
+*/
+package goscanner
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Tokens maps token types to the go/token tokens Scan returns for them
+*/
+type Tokens map[lexer.TokenType]token.Token
+
+/*
+A Mode controls what Scan returns. ScanComments returns tokens mapped to
+token.COMMENT, which are skipped otherwise. InsertSemicolons inserts
+semicolons at line ends as go/scanner does for Go, after identifiers,
+literals, the keywords break, continue, fallthrough and return, the
+operators ++ and --, and closing brackets. Unlike go/scanner, semicolons
+are only inserted when asked for, since most languages do without.
+*/
+type Mode uint
+
+const (
+	ScanComments Mode = 1 << iota
+	InsertSemicolons
+)
+
+/*
+Scanner reads the tokens of a lexer as go/scanner.Scanner reads Go
+source. ErrorCount counts the errors reported, as in go/scanner.
+*/
+type Scanner struct {
+	ErrorCount int
+
+	start  lexer.LexFn
+	tokens Tokens
+	skip   []lexer.TokenType
+
+	file   *token.File
+	err    scanner.ErrorHandler
+	mode   Mode
+	input  string
+	stream *lexer.TokenStream
+
+	insertSemicolon bool
+	lastLine        int
+	lastEnd         int
+}
+
+var operators = map[string]token.Token{}
+
+func init() {
+	for tok := token.ILLEGAL; tok < 128; tok++ {
+		if tok.IsOperator() {
+			operators[tok.String()] = tok
+		}
+	}
+}
+
+/*
+New creates a scanner lexing with start, ready for Init. Scan returns
+the token in tokens for a token's type. Tokens of types not in tokens
+are looked up by their text among Go's operators and keywords, so a
+lexer whose operators and keywords are spelled as in Go only needs its
+identifiers, literals and comments mapped. Tokens of the types in skip
+are left out.
+*/
+func New(start lexer.LexFn, tokens Tokens, skip ...lexer.TokenType) *Scanner {
+	return &Scanner{
+		start:  start,
+		tokens: tokens,
+		skip:   skip,
+	}
+}
+
+/*
+Init prepares the scanner to lex src, as go/scanner's Init does. The
+file's size must match the length of src, and its line table is set
+from src. err, if not nil, is called with each error of the lexer and
+with each token that has no go/token equivalent.
+*/
+func (s *Scanner) Init(file *token.File, src []byte, err scanner.ErrorHandler, mode Mode) {
+	if file.Size() != len(src) {
+		panic(fmt.Sprintf("file size (%d) does not match src len (%d)", file.Size(), len(src)))
+	}
+
+	file.SetLinesForContent(src)
+
+	s.file = file
+	s.err = err
+	s.mode = mode
+	s.input = string(src)
+	s.stream = lexer.NewTokenStream(lexer.NewLexer(file.Name(), s.input, s.start), s.skip...)
+	s.ErrorCount = 0
+	s.insertSemicolon = false
+	s.lastLine = 0
+	s.lastEnd = 0
+}
+
+/*
+Scan returns the position, token and literal of the next token, as
+go/scanner's Scan does. The literal is the token's text for
+identifiers, literals, comments and keywords, "\n" for an inserted
+semicolon, and empty otherwise. Errors of the lexer are reported to the
+error handler and returned as token.ILLEGAL with the text in error.
+*/
+func (s *Scanner) Scan() (token.Pos, token.Token, string) {
+	for {
+		if s.insertSemicolon {
+			if offset, ok := s.lineEnd(); ok {
+				s.insertSemicolon = false
+				return s.file.Pos(offset), token.SEMICOLON, "\n"
+			}
+		}
+
+		next := s.stream.Next()
+		pos := s.file.Pos(next.Span.Start.Offset)
+		text := s.input[next.Span.Start.Offset:next.Span.End.Offset]
+
+		switch next.Type {
+		case lexer.TOKEN_EOF:
+			return s.file.Pos(len(s.input)), token.EOF, ""
+
+		case lexer.TOKEN_ERROR:
+			s.error(next.Span.Start, next.Text)
+			return pos, token.ILLEGAL, text
+		}
+
+		tok := s.lookup(next.Type, text)
+
+		switch {
+		case tok == token.COMMENT:
+			if s.mode&ScanComments == 0 {
+				continue
+			}
+
+			return pos, tok, text
+
+		case tok == token.ILLEGAL:
+			s.error(next.Span.Start, fmt.Sprintf("no Go token for %q", text))
+		}
+
+		s.lastLine = next.Span.End.Line
+		s.lastEnd = next.Span.End.Offset
+		s.insertSemicolon = s.mode&InsertSemicolons != 0 && endsStatement(tok)
+
+		if tok == token.ILLEGAL || tok.IsLiteral() || tok.IsKeyword() {
+			return pos, tok, text
+		}
+
+		return pos, tok, ""
+	}
+}
+
+/*
+lookup returns the go/token token for a token of the lexer
+*/
+func (s *Scanner) lookup(tokenType lexer.TokenType, text string) token.Token {
+	if tok, ok := s.tokens[tokenType]; ok {
+		return tok
+	}
+
+	if tok, ok := operators[text]; ok {
+		return tok
+	}
+
+	if tok := token.Lookup(text); tok.IsKeyword() {
+		return tok
+	}
+
+	return token.ILLEGAL
+}
+
+/*
+lineEnd returns where to insert a semicolon if the line of the last
+token ends before the next token that is not a comment. As in
+go/scanner, it goes before a line comment or a comment spanning lines,
+and after a block comment ending on the line, at the newline or the end
+of input.
+*/
+func (s *Scanner) lineEnd() (int, bool) {
+	for n := 1; ; n++ {
+		next := s.stream.LookAhead(n)
+
+		if next.Type != lexer.TOKEN_EOF && next.Type != lexer.TOKEN_ERROR && s.lookup(next.Type, next.Text) == token.COMMENT {
+			continue
+		}
+
+		if next.Type != lexer.TOKEN_EOF && next.Span.Start.Line == s.lastLine {
+			return 0, false
+		}
+
+		if comment := s.stream.Peek(); n > 1 && s.mode&ScanComments != 0 {
+			if comment.Span.End.Line == s.lastLine && strings.HasSuffix(comment.Text, "*/") {
+				return 0, false
+			}
+
+			return comment.Span.Start.Offset, true
+		}
+
+		if newline := strings.IndexByte(s.input[s.lastEnd:], '\n'); newline >= 0 {
+			return s.lastEnd + newline, true
+		}
+
+		return len(s.input), true
+	}
+}
+
+func (s *Scanner) error(position lexer.Position, message string) {
+	s.ErrorCount++
+
+	if s.err != nil {
+		s.err(s.file.Position(s.file.Pos(position.Offset)), message)
+	}
+}
+
+/*
+endsStatement returns true if a semicolon is inserted after tok at the
+end of a line
+*/
+func endsStatement(tok token.Token) bool {
+	switch tok {
+	case token.IDENT, token.INT, token.FLOAT, token.IMAG, token.CHAR, token.STRING,
+		token.BREAK, token.CONTINUE, token.FALLTHROUGH, token.RETURN,
+		token.INC, token.DEC, token.RPAREN, token.RBRACK, token.RBRACE:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,104 @@
+package highlight
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+A Theme maps categories to ANSI SGR parameters, such as "1;34" for
+bold blue. Categories missing from the theme are written unstyled.
+*/
+type Theme map[Category]string
+
+/*
+DefaultTheme uses the basic sixteen colors so it works in any terminal
+that understands ANSI escapes
+*/
+var DefaultTheme = Theme{
+	CATEGORY_KEYWORD:      "1;35",
+	CATEGORY_TYPE:         "36",
+	CATEGORY_FUNCTION:     "34",
+	CATEGORY_STRING:       "32",
+	CATEGORY_NUMBER:       "33",
+	CATEGORY_LITERAL:      "33",
+	CATEGORY_COMMENT:      "2;3",
+	CATEGORY_OPERATOR:     "35",
+	CATEGORY_PREPROCESSOR: "31",
+	CATEGORY_ERROR:        "1;4;31",
+}
+
+/*
+ANSI renders tokens for a terminal using ANSI color escapes. A nil
+Theme uses DefaultTheme. Text between tokens is copied from the input
+unstyled.
+*/
+type ANSI struct {
+	Categories Categories
+	Theme      Theme
+}
+
+/*
+Render writes input to w with escapes styling each token. The tokens
+must come from lexing input. Styles are reset before each newline so
+the output stays correct when viewed a line at a time, as in a pager.
+*/
+func (renderer ANSI) Render(w io.Writer, input string, tokens []lexer.Token) error {
+	theme := renderer.Theme
+
+	if theme == nil {
+		theme = DefaultTheme
+	}
+
+	writer := bufio.NewWriter(w)
+
+	err := eachSegment(input, tokens, func(token *lexer.Token, text string) error {
+		var style string
+
+		if token != nil {
+			style = theme[renderer.Categories.Category(token.Type)]
+		}
+
+		if style == "" {
+			_, err := writer.WriteString(text)
+			return err
+		}
+
+		for {
+			line, rest, more := strings.Cut(text, "\n")
+
+			if line != "" {
+				writer.WriteString("\x1b[" + style + "m" + line + "\x1b[0m")
+			}
+
+			if !more {
+				break
+			}
+
+			writer.WriteByte('\n')
+			text = rest
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+/*
+Highlight returns input styled by tokens, for embedding snippets in
+error messages and other CLI output
+*/
+func (renderer ANSI) Highlight(input string, tokens []lexer.Token) string {
+	var result strings.Builder
+
+	renderer.Render(&result, input, tokens)
+	return result.String()
+}
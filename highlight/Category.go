@@ -0,0 +1,81 @@
+/*
+Package highlight renders token streams as syntax highlighted output.
+Token types are grouped into a small set of categories, such as
+keywords or strings, which renderers map to styles.
+*/
+package highlight
+
+import "github.com/adampresley/lexer"
+
+/*
+A Category is a broad kind of token used to pick a style. The zero
+value, CATEGORY_NONE, is rendered without any styling.
+*/
+type Category int
+
+const (
+	CATEGORY_NONE Category = iota
+	CATEGORY_KEYWORD
+	CATEGORY_IDENTIFIER
+	CATEGORY_TYPE
+	CATEGORY_FUNCTION
+	CATEGORY_STRING
+	CATEGORY_NUMBER
+	CATEGORY_LITERAL
+	CATEGORY_COMMENT
+	CATEGORY_OPERATOR
+	CATEGORY_PUNCTUATION
+	CATEGORY_PREPROCESSOR
+	CATEGORY_ERROR
+)
+
+var categoryNames = [...]string{
+	CATEGORY_NONE:         "none",
+	CATEGORY_KEYWORD:      "keyword",
+	CATEGORY_IDENTIFIER:   "identifier",
+	CATEGORY_TYPE:         "type",
+	CATEGORY_FUNCTION:     "function",
+	CATEGORY_STRING:       "string",
+	CATEGORY_NUMBER:       "number",
+	CATEGORY_LITERAL:      "literal",
+	CATEGORY_COMMENT:      "comment",
+	CATEGORY_OPERATOR:     "operator",
+	CATEGORY_PUNCTUATION:  "punctuation",
+	CATEGORY_PREPROCESSOR: "preprocessor",
+	CATEGORY_ERROR:        "error",
+}
+
+/*
+String returns the lower case name of the category, which is also
+used as its CSS class name
+*/
+func (category Category) String() string {
+	if category >= 0 && int(category) < len(categoryNames) {
+		return categoryNames[category]
+	}
+
+	return "unknown"
+}
+
+/*
+Categories maps token types to categories. Lexers usually declare one
+next to their TokenNames.
+*/
+type Categories map[lexer.TokenType]Category
+
+/*
+Category returns the category of tokenType. TOKEN_ERROR is
+CATEGORY_ERROR unless mapped otherwise, and unmapped types are
+CATEGORY_NONE. Category may be called on a nil map.
+*/
+func (categories Categories) Category(tokenType lexer.TokenType) Category {
+	if category, ok := categories[tokenType]; ok {
+		return category
+	}
+
+	if tokenType == lexer.TOKEN_ERROR {
+		return CATEGORY_ERROR
+	}
+
+	return CATEGORY_NONE
+}
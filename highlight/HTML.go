@@ -0,0 +1,134 @@
+package highlight
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+DefaultClassPrefix is prepended to every CSS class written by HTML
+when ClassPrefix is empty
+*/
+const DefaultClassPrefix = "tok-"
+
+/*
+HTML renders tokens as HTML. Each token becomes a span with a class
+for its category and, when Names is set, a class for its type name,
+so
+
+	<span class="tok-keyword tok-if">if</span>
+
+Text between tokens, such as skipped whitespace, is copied from the
+input unstyled, so any lexer written against this package can be used
+as a highlighter without changes.
+*/
+type HTML struct {
+	Categories  Categories
+	Names       lexer.TokenNames
+	ClassPrefix string
+
+	// Pre wraps the output in <pre class="prefix + source">
+	Pre bool
+}
+
+/*
+Render writes input to w as HTML, styled by tokens. The tokens must
+come from lexing input, such as the result of Lexer.Collect.
+*/
+func (renderer HTML) Render(w io.Writer, input string, tokens []lexer.Token) error {
+	prefix := renderer.ClassPrefix
+
+	if prefix == "" {
+		prefix = DefaultClassPrefix
+	}
+
+	writer := bufio.NewWriter(w)
+
+	if renderer.Pre {
+		writer.WriteString(`<pre class="` + prefix + `source">`)
+	}
+
+	err := eachSegment(input, tokens, func(token *lexer.Token, text string) error {
+		if token == nil {
+			_, err := writer.WriteString(html.EscapeString(text))
+			return err
+		}
+
+		classes := renderer.classes(prefix, token.Type)
+
+		if classes == "" {
+			_, err := writer.WriteString(html.EscapeString(text))
+			return err
+		}
+
+		writer.WriteString(`<span class="` + classes + `">`)
+		writer.WriteString(html.EscapeString(text))
+		_, err := writer.WriteString("</span>")
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if renderer.Pre {
+		writer.WriteString("</pre>")
+	}
+
+	return writer.Flush()
+}
+
+func (renderer HTML) classes(prefix string, tokenType lexer.TokenType) string {
+	var classes []string
+
+	if category := renderer.Categories.Category(tokenType); category != CATEGORY_NONE {
+		classes = append(classes, prefix+category.String())
+	}
+
+	if name, ok := renderer.Names[tokenType]; ok {
+		classes = append(classes, prefix+className(name))
+	}
+
+	return strings.Join(classes, " ")
+}
+
+/*
+className turns a token type name into a CSS class name by lower
+casing it and replacing anything other than letters, digits, hyphens
+and underscores with a hyphen
+*/
+func className(name string) string {
+	return strings.Map(func(ch rune) rune {
+		switch {
+		case ch >= 'A' && ch <= 'Z':
+			return ch + 'a' - 'A'
+
+		case ch >= 'a' && ch <= 'z', ch >= '0' && ch <= '9', ch == '-', ch == '_':
+			return ch
+		}
+
+		return '-'
+	}, name)
+}
+
+/*
+DefaultCSS is a light stylesheet for the category classes written by
+HTML with the default class prefix
+*/
+const DefaultCSS = `.tok-source { background: #fafafa; color: #24292e; }
+.tok-keyword { color: #d73a49; font-weight: bold; }
+.tok-type { color: #6f42c1; }
+.tok-function { color: #6f42c1; }
+.tok-string { color: #032f62; }
+.tok-number { color: #005cc5; }
+.tok-literal { color: #005cc5; }
+.tok-comment { color: #6a737d; font-style: italic; }
+.tok-operator { color: #d73a49; }
+.tok-punctuation { color: #24292e; }
+.tok-preprocessor { color: #e36209; }
+.tok-error { color: #b31d28; background: #ffeef0; }
+`
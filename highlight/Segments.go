@@ -0,0 +1,53 @@
+package highlight
+
+import "github.com/adampresley/lexer"
+
+/*
+eachSegment walks input in order, calling fn for every token's text and
+for the text between tokens, which is reported with a nil token. Tokens
+are expected in input order; tokens overlapping text already written
+are clipped, and zero length tokens such as EOF are skipped.
+*/
+func eachSegment(input string, tokens []lexer.Token, fn func(token *lexer.Token, text string) error) error {
+	pos := 0
+
+	for index := range tokens {
+		token := &tokens[index]
+		start := clamp(token.Span.Start.Offset, pos, len(input))
+		end := clamp(token.Span.End.Offset, start, len(input))
+
+		if start == end {
+			continue
+		}
+
+		if start > pos {
+			if err := fn(nil, input[pos:start]); err != nil {
+				return err
+			}
+		}
+
+		if err := fn(token, input[start:end]); err != nil {
+			return err
+		}
+
+		pos = end
+	}
+
+	if pos < len(input) {
+		return fn(nil, input[pos:])
+	}
+
+	return nil
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+
+	if value > max {
+		return max
+	}
+
+	return value
+}
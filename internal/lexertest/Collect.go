@@ -0,0 +1,85 @@
+/*
+Package lexertest holds helpers for the tests of the lexers in this
+module. Collect runs a lexer with a time limit, so a state function that
+stops making progress fails its test rather than hanging the test
+binary, and Format writes tokens in a compact form to compare against
+expected output:
+
+	tokens := lexertest.Collect(t, lexer.NewLexer("test", "1 + 2", calc.Start))
+	got := lexertest.Format(calc.Names, tokens)
+*/
+package lexertest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+TIMEOUT is how long Collect lets a lexer run before failing the test
+*/
+const TIMEOUT = 5 * time.Second
+
+/*
+T is the part of testing.TB the helpers use
+*/
+type T interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+/*
+Collect runs l to the end and returns its tokens, including the EOF
+token. The test fails if l panics or does not finish within TIMEOUT.
+*/
+func Collect(t T, l *lexer.Lexer) []lexer.Token {
+	t.Helper()
+
+	type result struct {
+		tokens []lexer.Token
+		panic  interface{}
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{panic: r}
+			}
+		}()
+
+		done <- result{tokens: l.Collect()}
+	}()
+
+	select {
+	case r := <-done:
+		if r.panic != nil {
+			t.Fatalf("lexing %q panicked: %v", l.Input, r.panic)
+		}
+
+		return r.tokens
+
+	case <-time.After(TIMEOUT):
+		t.Fatalf("lexing %q did not finish within %s", l.Input, TIMEOUT)
+		return nil
+	}
+}
+
+/*
+Format writes tokens one per line as their type name and quoted text,
+as in NUMBER "12"
+*/
+func Format(names lexer.TokenNames, tokens []lexer.Token) string {
+	var result strings.Builder
+
+	for _, token := range tokens {
+		fmt.Fprintf(&result, "%s %q\n", names.Name(token.Type), token.Text)
+	}
+
+	return result.String()
+}
@@ -0,0 +1,46 @@
+package lsp
+
+import (
+	"github.com/adampresley/lexer"
+)
+
+/*
+Diagnostic is the LSP representation of a problem in a document, ready
+to be sent in a textDocument/publishDiagnostics notification.
+*/
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Source   string `json:"source,omitempty"`
+	Message  string `json:"message"`
+}
+
+/*
+ToDiagnostic converts a single lexer diagnostic using the given mapper.
+Source names the tool producing the diagnostic and may be empty.
+*/
+func ToDiagnostic(mapper *PositionMapper, err lexer.LexError, source string) Diagnostic {
+	return Diagnostic{
+		Range:    mapper.Range(err.Span),
+		Severity: int(err.Severity),
+		Code:     err.Code,
+		Source:   source,
+		Message:  err.Message,
+	}
+}
+
+/*
+ToDiagnostics converts the diagnostics reported while lexing input into
+LSP diagnostics. The input must be the same text that was lexed.
+*/
+func ToDiagnostics(input string, errors []lexer.LexError, source string) []Diagnostic {
+	mapper := NewPositionMapper(input)
+	result := make([]Diagnostic, 0, len(errors))
+
+	for _, err := range errors {
+		result = append(result, ToDiagnostic(mapper, err, source))
+	}
+
+	return result
+}
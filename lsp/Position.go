@@ -0,0 +1,18 @@
+package lsp
+
+/*
+Position is a zero-based line and character offset as used by the
+Language Server Protocol. Character counts UTF-16 code units.
+*/
+type Position struct {
+	Line      uint32 `json:"line"`
+	Character uint32 `json:"character"`
+}
+
+/*
+Range is a span between two LSP positions. End is exclusive.
+*/
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
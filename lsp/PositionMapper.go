@@ -0,0 +1,79 @@
+package lsp
+
+import (
+	"sort"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+PositionMapper converts byte offsets in a document into LSP positions.
+It indexes the start of each line once so that conversions don't rescan
+the document.
+*/
+type PositionMapper struct {
+	input      string
+	lineStarts []int
+}
+
+/*
+NewPositionMapper creates a mapper for the given document text
+*/
+func NewPositionMapper(input string) *PositionMapper {
+	lineStarts := []int{0}
+
+	for index := 0; index < len(input); index++ {
+		if input[index] == '\n' {
+			lineStarts = append(lineStarts, index+1)
+		}
+	}
+
+	return &PositionMapper{
+		input:      input,
+		lineStarts: lineStarts,
+	}
+}
+
+/*
+Position converts a byte offset into a zero-based line and UTF-16
+character offset. Offsets past the end of the document are clamped.
+*/
+func (mapper *PositionMapper) Position(offset int) Position {
+	if offset > len(mapper.input) {
+		offset = len(mapper.input)
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	line := sort.Search(len(mapper.lineStarts), func(i int) bool {
+		return mapper.lineStarts[i] > offset
+	}) - 1
+
+	character := 0
+	for _, ch := range mapper.input[mapper.lineStarts[line]:offset] {
+		if ch >= 0x10000 {
+			character += 2
+		} else {
+			character++
+		}
+	}
+
+	return Position{
+		Line:      uint32(line),
+		Character: uint32(character),
+	}
+}
+
+/*
+Range converts a lexer span into an LSP range. The byte offsets of the
+span are used, so positions remapped with line directives still refer
+to the document being edited.
+*/
+func (mapper *PositionMapper) Range(span lexer.Span) Range {
+	return Range{
+		Start: mapper.Position(span.Start.Offset),
+		End:   mapper.Position(span.End.Offset),
+	}
+}
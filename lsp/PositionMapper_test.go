@@ -0,0 +1,50 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer"
+)
+
+func TestPositionMapper(t *testing.T) {
+	// é is one UTF-16 code unit in two bytes, 😀 a surrogate pair in four
+	input := "é😀b\r\nc"
+
+	tests := []struct {
+		offset int
+		want   Position
+	}{
+		{offset: 0, want: Position{Line: 0, Character: 0}},
+		{offset: 2, want: Position{Line: 0, Character: 1}},
+		{offset: 6, want: Position{Line: 0, Character: 3}},
+		{offset: 7, want: Position{Line: 0, Character: 4}},
+		{offset: 9, want: Position{Line: 1, Character: 0}},
+		{offset: 10, want: Position{Line: 1, Character: 1}},
+		{offset: 100, want: Position{Line: 1, Character: 1}},
+		{offset: -1, want: Position{Line: 0, Character: 0}},
+	}
+
+	mapper := NewPositionMapper(input)
+
+	for _, test := range tests {
+		if got := mapper.Position(test.offset); got != test.want {
+			t.Errorf("position of offset %d in %q: got %+v, want %+v", test.offset, input, got, test.want)
+		}
+	}
+}
+
+func TestPositionMapperRange(t *testing.T) {
+	input := "😀\r\nab"
+	span := lexer.Span{
+		Start: lexer.Position{Offset: 4, Line: 1, Column: 5},
+		End:   lexer.Position{Offset: 8, Line: 2, Column: 3},
+	}
+	want := Range{
+		Start: Position{Line: 0, Character: 2},
+		End:   Position{Line: 1, Character: 2},
+	}
+
+	if got := NewPositionMapper(input).Range(span); got != want {
+		t.Errorf("range of %+v in %q: got %+v, want %+v", span, input, got, want)
+	}
+}
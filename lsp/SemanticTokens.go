@@ -0,0 +1,228 @@
+package lsp
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+SemanticTokenType is the semantic token type and modifiers a lexer
+token type is reported as, using the names from the LSP specification
+such as "keyword", "string" or "declaration"
+*/
+type SemanticTokenType struct {
+	Type      string
+	Modifiers []string
+}
+
+/*
+SemanticTokensLegend lists the token types and modifiers an encoder
+uses. Send it as the legend of the semanticTokensProvider server
+capability.
+*/
+type SemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+/*
+SemanticTokens is the result of a textDocument/semanticTokens/full
+request
+*/
+type SemanticTokens struct {
+	ResultID string   `json:"resultId,omitempty"`
+	Data     []uint32 `json:"data"`
+}
+
+/*
+SemanticTokenEncoder converts token streams into the delta encoded
+integer form of LSP semantic tokens
+*/
+type SemanticTokenEncoder struct {
+	legend    SemanticTokensLegend
+	types     map[lexer.TokenType]uint32
+	modifiers map[lexer.TokenType]uint32
+}
+
+/*
+NewSemanticTokenEncoder creates an encoder reporting each lexer token
+type as given in mapping. Token types missing from the mapping are left
+out of the result, which is usual for whitespace and punctuation.
+*/
+func NewSemanticTokenEncoder(mapping map[lexer.TokenType]SemanticTokenType) *SemanticTokenEncoder {
+	encoder := &SemanticTokenEncoder{
+		types:     make(map[lexer.TokenType]uint32, len(mapping)),
+		modifiers: make(map[lexer.TokenType]uint32, len(mapping)),
+	}
+
+	typeIndexes := make(map[string]uint32)
+	modifierBits := make(map[string]uint32)
+
+	for _, semantic := range mapping {
+		typeIndexes[semantic.Type] = 0
+
+		for _, modifier := range semantic.Modifiers {
+			modifierBits[modifier] = 0
+		}
+	}
+
+	encoder.legend.TokenTypes = sortedKeys(typeIndexes)
+	encoder.legend.TokenModifiers = sortedKeys(modifierBits)
+
+	for index, name := range encoder.legend.TokenTypes {
+		typeIndexes[name] = uint32(index)
+	}
+
+	for index, name := range encoder.legend.TokenModifiers {
+		modifierBits[name] = 1 << uint(index)
+	}
+
+	for tokenType, semantic := range mapping {
+		encoder.types[tokenType] = typeIndexes[semantic.Type]
+
+		for _, modifier := range semantic.Modifiers {
+			encoder.modifiers[tokenType] |= modifierBits[modifier]
+		}
+	}
+
+	return encoder
+}
+
+/*
+Legend returns the token types and modifiers used by the encoder. The
+order is fixed when the encoder is created.
+*/
+func (encoder *SemanticTokenEncoder) Legend() SemanticTokensLegend {
+	return encoder.legend
+}
+
+/*
+Encode converts tokens lexed from input into semantic tokens. Tokens
+spanning several lines are split into one semantic token per line, as
+clients are not required to support multiline tokens. Tokens must be
+in input order.
+*/
+func (encoder *SemanticTokenEncoder) Encode(input string, tokens []lexer.Token) SemanticTokens {
+	cursor := utf16Cursor{input: input}
+	data := make([]uint32, 0, len(tokens)*5)
+	var previousLine, previousCharacter uint32
+
+	for _, token := range tokens {
+		tokenType, ok := encoder.types[token.Type]
+
+		if !ok {
+			continue
+		}
+
+		start, end := token.Span.Start.Offset, token.Span.End.Offset
+
+		if start < cursor.offset || end > len(input) {
+			continue
+		}
+
+		for start < end {
+			cursor.advance(start)
+			line, character := cursor.line, cursor.character
+
+			cursor.advanceLine(end)
+			length := cursor.character - character
+
+			if length > 0 {
+				if line != previousLine {
+					previousCharacter = 0
+				}
+
+				data = append(data, line-previousLine, character-previousCharacter, length, tokenType, encoder.modifiers[token.Type])
+				previousLine, previousCharacter = line, character
+			}
+
+			start = cursor.offset
+
+			if start < end {
+				// Skip the line break ending this line of the token
+				cursor.advance(start + 1)
+				start = cursor.offset
+			}
+		}
+	}
+
+	return SemanticTokens{
+		Data: data,
+	}
+}
+
+/*
+utf16Cursor walks forward through a document tracking the zero-based
+line and UTF-16 character of a byte offset, so a stream of tokens is
+converted in a single pass
+*/
+type utf16Cursor struct {
+	input     string
+	offset    int
+	line      uint32
+	character uint32
+}
+
+/*
+advance moves the cursor forward to offset
+*/
+func (cursor *utf16Cursor) advance(offset int) {
+	for cursor.offset < offset {
+		cursor.step()
+	}
+}
+
+/*
+advanceLine moves the cursor forward to offset or to the end of the
+current line, whichever comes first. A carriage return before the
+newline is treated as part of the line break.
+*/
+func (cursor *utf16Cursor) advanceLine(offset int) {
+	for cursor.offset < offset {
+		ch := cursor.input[cursor.offset]
+
+		if ch == '\n' || (ch == '\r' && cursor.offset+1 < len(cursor.input) && cursor.input[cursor.offset+1] == '\n') {
+			return
+		}
+
+		cursor.step()
+	}
+}
+
+func (cursor *utf16Cursor) step() {
+	ch := cursor.input[cursor.offset]
+
+	if ch == '\n' {
+		cursor.offset++
+		cursor.line++
+		cursor.character = 0
+		return
+	}
+
+	if ch < utf8.RuneSelf {
+		cursor.offset++
+		cursor.character++
+		return
+	}
+
+	r, width := utf8.DecodeRuneInString(cursor.input[cursor.offset:])
+	cursor.offset += width
+	cursor.character++
+
+	if r >= 0x10000 {
+		cursor.character++
+	}
+}
+
+func sortedKeys(values map[string]uint32) []string {
+	result := make([]string, 0, len(values))
+
+	for key := range values {
+		result = append(result, key)
+	}
+
+	sort.Strings(result)
+	return result
+}
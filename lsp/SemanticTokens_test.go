@@ -0,0 +1,68 @@
+package lsp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adampresley/lexer"
+)
+
+const (
+	testEmoji lexer.TokenType = iota + 1
+	testString
+	testIdentifier
+	testWhitespace
+)
+
+var testMapping = map[lexer.TokenType]SemanticTokenType{
+	testEmoji:      {Type: "keyword"},
+	testString:     {Type: "string"},
+	testIdentifier: {Type: "variable", Modifiers: []string{"readonly"}},
+}
+
+func testToken(tokenType lexer.TokenType, start, end int) lexer.Token {
+	return lexer.Token{
+		Type: tokenType,
+		Span: lexer.Span{
+			Start: lexer.Position{Offset: start},
+			End:   lexer.Position{Offset: end},
+		},
+	}
+}
+
+func TestSemanticTokensLegend(t *testing.T) {
+	want := SemanticTokensLegend{
+		TokenTypes:     []string{"keyword", "string", "variable"},
+		TokenModifiers: []string{"readonly"},
+	}
+
+	if got := NewSemanticTokenEncoder(testMapping).Legend(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got legend %+v, want %+v", got, want)
+	}
+}
+
+func TestSemanticTokensEncode(t *testing.T) {
+	input := "😀 \"ab\r\ncd\" x\n  y"
+	tokens := []lexer.Token{
+		testToken(testEmoji, 0, 4),
+		testToken(testString, 5, 13),
+		testToken(testIdentifier, 14, 15),
+		testToken(testWhitespace, 15, 18),
+		testToken(testIdentifier, 18, 19),
+		testToken(lexer.TOKEN_EOF, 19, 19),
+	}
+
+	// Each token is line delta, start delta, length, type and modifiers.
+	// The string spans a CRLF line break, so it is split in two.
+	want := []uint32{
+		0, 0, 2, 0, 0,
+		0, 3, 3, 1, 0,
+		1, 0, 3, 1, 0,
+		0, 4, 1, 2, 1,
+		1, 2, 1, 2, 1,
+	}
+
+	if got := NewSemanticTokenEncoder(testMapping).Encode(input, tokens).Data; !reflect.DeepEqual(got, want) {
+		t.Errorf("encoding %q:\ngot  %v\nwant %v", input, got, want)
+	}
+}
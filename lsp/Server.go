@@ -0,0 +1,280 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"strconv"
+
+	"github.com/adampresley/lexer"
+)
+
+const (
+	errorParse          = -32700
+	errorMethodNotFound = -32601
+	errorInvalidParams  = -32602
+)
+
+/*
+Server is a minimal language server built from a lexer alone. It keeps
+the documents an editor opens, lexes them on every change, publishes the
+lexer's diagnostics and answers requests for semantic tokens, which is
+enough for highlighting and error squiggles in any editor with LSP
+support:
+
+	func main() {
+		server := lsp.NewServer("calc", calc.Start, map[lexer.TokenType]lsp.SemanticTokenType{
+			calc.TOKEN_NUMBER:     {Type: "number"},
+			calc.TOKEN_IDENTIFIER: {Type: "variable"},
+		})
+
+		if err := server.ServeStdio(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+Documents are synchronized in full on every change. Requests are
+handled one at a time in the order they arrive.
+*/
+type Server struct {
+	name      string
+	start     lexer.LexFn
+	encoder   *SemanticTokenEncoder
+	options   []lexer.Option
+	documents map[string]string
+	writer    io.Writer
+	shutdown  bool
+}
+
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type textDocument struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type documentParams struct {
+	TextDocument   textDocument `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+/*
+NewServer creates a server named name, which is also the source of its
+diagnostics, lexing documents with start and the given options and
+reporting token types as semantic tokens as given in mapping
+*/
+func NewServer(name string, start lexer.LexFn, mapping map[lexer.TokenType]SemanticTokenType, options ...lexer.Option) *Server {
+	return &Server{
+		name:      name,
+		start:     start,
+		encoder:   NewSemanticTokenEncoder(mapping),
+		options:   options,
+		documents: map[string]string{},
+	}
+}
+
+/*
+ServeStdio serves a client talking over standard input and output, the
+way editors start language servers
+*/
+func (server *Server) ServeStdio() error {
+	return server.Serve(os.Stdin, os.Stdout)
+}
+
+/*
+Serve reads JSON-RPC messages from r and writes responses and
+notifications to w until the client sends exit. It returns an error if
+the input ends or the client exits without shutting the server down
+first.
+*/
+func (server *Server) Serve(r io.Reader, w io.Writer) error {
+	reader := textproto.NewReader(bufio.NewReader(r))
+	server.writer = w
+
+	for {
+		request, err := readMessage(reader)
+		if err != nil {
+			return err
+		}
+
+		if request == nil {
+			if err := server.respond(nil, nil, &responseError{Code: errorParse, Message: "invalid JSON"}); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if request.Method == "exit" {
+			if !server.shutdown {
+				return errors.New("lsp: exit without shutdown")
+			}
+
+			return nil
+		}
+
+		if err := server.handle(request); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+handle answers one request or notification
+*/
+func (server *Server) handle(request *message) error {
+	var params documentParams
+
+	if len(request.Params) > 0 {
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return server.respond(request.ID, nil, &responseError{Code: errorInvalidParams, Message: err.Error()})
+		}
+	}
+
+	uri := params.TextDocument.URI
+
+	switch request.Method {
+	case "initialize":
+		return server.respond(request.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": 1,
+				"semanticTokensProvider": map[string]interface{}{
+					"legend": server.encoder.Legend(),
+					"full":   true,
+				},
+			},
+			"serverInfo": map[string]string{"name": server.name},
+		}, nil)
+
+	case "shutdown":
+		server.shutdown = true
+		return server.respond(request.ID, nil, nil)
+
+	case "textDocument/didOpen":
+		server.documents[uri] = params.TextDocument.Text
+		return server.publish(uri)
+
+	case "textDocument/didChange":
+		if count := len(params.ContentChanges); count > 0 {
+			server.documents[uri] = params.ContentChanges[count-1].Text
+		}
+
+		return server.publish(uri)
+
+	case "textDocument/didClose":
+		delete(server.documents, uri)
+		return server.notify("textDocument/publishDiagnostics", map[string]interface{}{"uri": uri, "diagnostics": []Diagnostic{}})
+
+	case "textDocument/semanticTokens/full":
+		tokens, _ := server.lex(uri)
+		return server.respond(request.ID, server.encoder.Encode(server.documents[uri], tokens), nil)
+	}
+
+	if request.ID == nil {
+		// Notifications the server does not know, such as initialized,
+		// need no answer
+		return nil
+	}
+
+	return server.respond(request.ID, nil, &responseError{Code: errorMethodNotFound, Message: "method not found: " + request.Method})
+}
+
+/*
+lex lexes the document with the given URI, returning its tokens and
+diagnostics. The diagnostics are read from the lexer once it is done,
+so an error handler among the server's options still sees every one.
+*/
+func (server *Server) lex(uri string) ([]lexer.Token, []lexer.LexError) {
+	l := lexer.NewLexer(uri, server.documents[uri], server.start, server.options...)
+	tokens := l.Collect()
+
+	return tokens, l.Diagnostics()
+}
+
+/*
+publish sends the diagnostics of the document with the given URI
+*/
+func (server *Server) publish(uri string) error {
+	_, diagnostics := server.lex(uri)
+
+	return server.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": ToDiagnostics(server.documents[uri], diagnostics, server.name),
+	})
+}
+
+/*
+respond answers the request with the given ID with a result or an error
+*/
+func (server *Server) respond(id *json.RawMessage, result interface{}, responseErr *responseError) error {
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+
+	if responseErr != nil {
+		response["error"] = responseErr
+	} else {
+		response["result"] = result
+	}
+
+	return server.write(response)
+}
+
+/*
+notify sends a notification to the client
+*/
+func (server *Server) notify(method string, params interface{}) error {
+	return server.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (server *Server) write(value interface{}) error {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(server.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+/*
+readMessage reads one message with its headers. It returns a nil message
+for a body that is not valid JSON.
+*/
+func readMessage(reader *textproto.Reader) (*message, error) {
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("lsp: invalid Content-Length %q", header.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader.R, body); err != nil {
+		return nil, err
+	}
+
+	request := &message{}
+	if json.Unmarshal(body, request) != nil {
+		return nil, nil
+	}
+
+	return request, nil
+}
@@ -0,0 +1,108 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/presets/calc"
+)
+
+func frame(messages ...string) io.Reader {
+	var buffer bytes.Buffer
+
+	for _, body := range messages {
+		fmt.Fprintf(&buffer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	return &buffer
+}
+
+func unframe(t *testing.T, output []byte) []string {
+	reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(output)))
+	result := []string{}
+
+	for {
+		header, err := reader.ReadMIMEHeader()
+		if err == io.EOF {
+			return result
+		}
+
+		if err != nil {
+			t.Fatalf("reading header: %v", err)
+		}
+
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			t.Fatalf("invalid Content-Length %q", header.Get("Content-Length"))
+		}
+
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader.R, body); err != nil {
+			t.Fatalf("reading body: %v", err)
+		}
+
+		result = append(result, string(body))
+	}
+}
+
+func TestServe(t *testing.T) {
+	handled := []string{}
+
+	server := NewServer("calc", calc.Start, map[lexer.TokenType]SemanticTokenType{
+		calc.TOKEN_NUMBER:     {Type: "number"},
+		calc.TOKEN_IDENTIFIER: {Type: "variable"},
+	}, lexer.WithErrorHandler(func(err lexer.LexError) {
+		handled = append(handled, err.Message)
+	}))
+
+	input := frame(
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.calc","text":"x + 1\n$ 2"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/semanticTokens/full","params":{"textDocument":{"uri":"file:///a.calc"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	)
+
+	want := []string{
+		`{"id":1,"jsonrpc":"2.0","result":{"capabilities":{"semanticTokensProvider":{"full":true,"legend":{"tokenTypes":["number","variable"],"tokenModifiers":[]}},"textDocumentSync":1},"serverInfo":{"name":"calc"}}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"diagnostics":[{"range":{"start":{"line":1,"character":0},"end":{"line":1,"character":1}},"severity":1,"source":"calc","message":"unexpected character '$'"}],"uri":"file:///a.calc"}}`,
+		`{"id":2,"jsonrpc":"2.0","result":{"data":[0,0,1,1,0,0,4,1,0,0,1,2,1,0,0]}}`,
+		`{"id":3,"jsonrpc":"2.0","result":null}`,
+	}
+
+	var output bytes.Buffer
+
+	if err := server.Serve(input, &output); err != nil {
+		t.Fatalf("serving: %v", err)
+	}
+
+	got := unframe(t, output.Bytes())
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got messages:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// The document is lexed once to publish diagnostics and once more
+	// for its semantic tokens
+	if len(handled) != 2 || handled[0] != "unexpected character '$'" || handled[1] != handled[0] {
+		t.Errorf("got errors %q passed to the error handler", handled)
+	}
+}
+
+func TestServeExitWithoutShutdown(t *testing.T) {
+	server := NewServer("calc", calc.Start, nil)
+	input := frame(`{"jsonrpc":"2.0","method":"exit"}`)
+
+	if err := server.Serve(input, ioutil.Discard); err == nil || err.Error() != "lsp: exit without shutdown" {
+		t.Errorf("got error %v, want exit without shutdown", err)
+	}
+}
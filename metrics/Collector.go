@@ -0,0 +1,126 @@
+/*
+Package metrics provides ready-made lexer.MetricsSink implementations
+for publishing lexer measurements through expvar or to Prometheus.
+
+Both sinks keep the same measurements: tokens by type, diagnostics by
+severity, completed runs, bytes lexed, a histogram of run durations, and
+a histogram of token lengths. A sink is safe to share between lexers
+running concurrently, which is the usual setup in a service:
+
+	sink := metrics.NewPrometheusSink(nil)
+	http.Handle("/metrics", sink)
+	...
+	l := lexer.NewLexer(name, input, start, lexer.WithMetrics(sink))
+*/
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	durationBounds    = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+	tokenLengthBounds = []float64{1, 2, 4, 8, 16, 32, 64, 128, 256, 1024}
+)
+
+/*
+collector holds the measurements shared by the sinks and implements
+lexer.MetricsSink. Token types between 0 and 255 are counted in a fixed
+array, so that the per-token path takes no locks: an atomic add for the
+type, and for the length an atomic add to one histogram bucket and to
+the histogram's count, and a compare-and-swap loop on its sum.
+*/
+type collector struct {
+	typeName func(lexer.TokenType) string
+
+	smallTypes [256]atomic.Uint64
+	otherTypes sync.Map
+
+	errors   sync.Map
+	runs     atomic.Uint64
+	bytes    atomic.Uint64
+	duration *histogram
+	length   *histogram
+}
+
+func newCollector(typeName func(lexer.TokenType) string) *collector {
+	if typeName == nil {
+		typeName = lexer.TokenNames(nil).Name
+	}
+
+	return &collector{
+		typeName: typeName,
+		duration: newHistogram(durationBounds),
+		length:   newHistogram(tokenLengthBounds),
+	}
+}
+
+/*
+TokenEmitted counts a token and records its length
+*/
+func (c *collector) TokenEmitted(tokenType lexer.TokenType, length int) {
+	if tokenType >= 0 && tokenType < 256 {
+		c.smallTypes[tokenType].Add(1)
+	} else {
+		counter, _ := c.otherTypes.LoadOrStore(tokenType, &atomic.Uint64{})
+		counter.(*atomic.Uint64).Add(1)
+	}
+
+	c.length.observe(float64(length))
+}
+
+/*
+ErrorReported counts a diagnostic by severity
+*/
+func (c *collector) ErrorReported(err lexer.LexError) {
+	counter, _ := c.errors.LoadOrStore(err.Severity, &atomic.Uint64{})
+	counter.(*atomic.Uint64).Add(1)
+}
+
+/*
+RunFinished counts a completed run, its bytes, and its duration
+*/
+func (c *collector) RunFinished(stats lexer.Stats) {
+	c.runs.Add(1)
+	c.bytes.Add(uint64(stats.Bytes))
+	c.duration.observe(stats.Duration.Seconds())
+}
+
+type namedCount struct {
+	name  string
+	count uint64
+}
+
+func (c *collector) tokenCounts() []namedCount {
+	result := []namedCount{}
+
+	for index := range c.smallTypes {
+		if count := c.smallTypes[index].Load(); count > 0 {
+			result = append(result, namedCount{c.typeName(lexer.TokenType(index)), count})
+		}
+	}
+
+	c.otherTypes.Range(func(key, value interface{}) bool {
+		result = append(result, namedCount{c.typeName(key.(lexer.TokenType)), value.(*atomic.Uint64).Load()})
+		return true
+	})
+
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result
+}
+
+func (c *collector) errorCounts() []namedCount {
+	result := []namedCount{}
+
+	c.errors.Range(func(key, value interface{}) bool {
+		result = append(result, namedCount{key.(lexer.Severity).String(), value.(*atomic.Uint64).Load()})
+		return true
+	})
+
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result
+}
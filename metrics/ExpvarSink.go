@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"expvar"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+ExpvarSink is a lexer.MetricsSink that publishes its measurements as a
+single expvar variable, visible at /debug/vars alongside the process's
+other variables.
+*/
+type ExpvarSink struct {
+	*collector
+}
+
+/*
+NewExpvarSink creates a sink and publishes it with expvar under name.
+typeName converts token types to the names used as keys, and may be nil
+to use the numeric value. Like expvar.Publish, it panics if name is
+already in use.
+*/
+func NewExpvarSink(name string, typeName func(lexer.TokenType) string) *ExpvarSink {
+	sink := &ExpvarSink{collector: newCollector(typeName)}
+	expvar.Publish(name, expvar.Func(sink.snapshot))
+	return sink
+}
+
+func (sink *ExpvarSink) snapshot() interface{} {
+	tokens := map[string]uint64{}
+	for _, count := range sink.tokenCounts() {
+		tokens[count.name] = count.count
+	}
+
+	errors := map[string]uint64{}
+	for _, count := range sink.errorCounts() {
+		errors[count.name] = count.count
+	}
+
+	return map[string]interface{}{
+		"tokens":           tokens,
+		"errors":           errors,
+		"runs":             sink.runs.Load(),
+		"bytes":            sink.bytes.Load(),
+		"duration_seconds": sink.duration.snapshot(),
+		"token_length":     sink.length.snapshot(),
+	}
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/adampresley/lexer"
+)
+
+func TestExpvarSink(t *testing.T) {
+	names := lexer.TokenNames{1: "WORD"}
+	sink := NewExpvarSink("lexer_test", names.Name)
+
+	sink.TokenEmitted(1, 2)
+	sink.TokenEmitted(1, 3)
+	sink.TokenEmitted(lexer.TOKEN_EOF, 0)
+	sink.ErrorReported(lexer.LexError{Message: "check", Severity: lexer.SEVERITY_WARNING})
+	sink.RunFinished(lexer.Stats{Bytes: 6, Duration: 250 * time.Millisecond})
+	sink.RunFinished(lexer.Stats{Bytes: 4, Duration: 2 * time.Second})
+
+	want := `{"bytes":10,` +
+		`"duration_seconds":{"bounds":[0.0001,0.0005,0.001,0.005,0.01,0.05,0.1,0.5,1,5],"buckets":[0,0,0,0,0,0,0,1,1,2],"count":2,"sum":2.25},` +
+		`"errors":{"warning":1},"runs":2,` +
+		`"token_length":{"bounds":[1,2,4,8,16,32,64,128,256,1024],"buckets":[1,2,3,3,3,3,3,3,3,3],"count":3,"sum":5},` +
+		`"tokens":{"EOF":1,"WORD":2}}`
+
+	if got := expvar.Get("lexer_test").String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
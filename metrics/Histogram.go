@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+/*
+histogram counts observations into buckets, in the style of a
+Prometheus histogram. It is safe for concurrent use. Each observation
+is counted in the one bucket it falls in, and snapshots add the buckets
+up into the cumulative counts Prometheus expects.
+*/
+type histogram struct {
+	bounds  []float64
+	buckets []atomic.Uint64
+	count   atomic.Uint64
+	sumBits atomic.Uint64
+}
+
+type histogramSnapshot struct {
+	Bounds  []float64 `json:"bounds"`
+	Buckets []uint64  `json:"buckets"`
+	Count   uint64    `json:"count"`
+	Sum     float64   `json:"sum"`
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds:  bounds,
+		buckets: make([]atomic.Uint64, len(bounds)),
+	}
+}
+
+func (h *histogram) observe(value float64) {
+	h.count.Add(1)
+
+	for index, bound := range h.bounds {
+		if value <= bound {
+			h.buckets[index].Add(1)
+			break
+		}
+	}
+
+	for {
+		old := h.sumBits.Load()
+		if h.sumBits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+value)) {
+			return
+		}
+	}
+}
+
+func (h *histogram) snapshot() histogramSnapshot {
+	result := histogramSnapshot{
+		Bounds:  h.bounds,
+		Buckets: make([]uint64, len(h.bounds)),
+	}
+
+	total := uint64(0)
+
+	for index := range h.buckets {
+		total += h.buckets[index].Load()
+		result.Buckets[index] = total
+	}
+
+	// observe adds to the count before the bucket, so loading the count
+	// after the buckets never leaves it below the last of them
+	result.Count = h.count.Load()
+	result.Sum = math.Float64frombits(h.sumBits.Load())
+
+	return result
+}
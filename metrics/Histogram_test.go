@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	tests := []struct {
+		values  []float64
+		buckets []uint64
+		count   uint64
+		sum     float64
+	}{
+		{nil, []uint64{0, 0, 0}, 0, 0},
+		{[]float64{0}, []uint64{1, 1, 1}, 1, 0},
+		{[]float64{1}, []uint64{1, 1, 1}, 1, 1},
+		{[]float64{1.5}, []uint64{0, 1, 1}, 1, 1.5},
+		{[]float64{2}, []uint64{0, 1, 1}, 1, 2},
+		{[]float64{4}, []uint64{0, 0, 1}, 1, 4},
+		{[]float64{5}, []uint64{0, 0, 0}, 1, 5},
+		{[]float64{1, 2, 3, 4, 5}, []uint64{1, 2, 4}, 5, 15},
+	}
+
+	for _, test := range tests {
+		h := newHistogram([]float64{1, 2, 4})
+
+		for _, value := range test.values {
+			h.observe(value)
+		}
+
+		snapshot := h.snapshot()
+
+		if !reflect.DeepEqual(snapshot.Buckets, test.buckets) || snapshot.Count != test.count || snapshot.Sum != test.sum {
+			t.Errorf("observing %v: got buckets %v, count %d, sum %g, want %v, %d, %g", test.values, snapshot.Buckets, snapshot.Count, snapshot.Sum, test.buckets, test.count, test.sum)
+		}
+	}
+}
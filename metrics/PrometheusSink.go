@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+PrometheusSink is a lexer.MetricsSink that serves its measurements in
+the Prometheus text exposition format. Mount it on the path Prometheus
+scrapes; no Prometheus client library is required.
+
+The metrics exposed are lexer_tokens_total and lexer_errors_total
+counters labelled by type and severity, lexer_runs_total and
+lexer_bytes_total counters, and the lexer_run_duration_seconds and
+lexer_token_length_bytes histograms.
+*/
+type PrometheusSink struct {
+	*collector
+}
+
+/*
+NewPrometheusSink creates a sink. typeName converts token types to the
+values of the "type" label, and may be nil to use the numeric value.
+*/
+func NewPrometheusSink(typeName func(lexer.TokenType) string) *PrometheusSink {
+	return &PrometheusSink{collector: newCollector(typeName)}
+}
+
+/*
+ServeHTTP writes the current measurements in the text exposition format
+*/
+func (sink *PrometheusSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	sink.WriteTo(w)
+}
+
+/*
+WriteTo writes the current measurements in the text exposition format
+*/
+func (sink *PrometheusSink) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{writer: bufio.NewWriter(w)}
+
+	fmt.Fprintf(counter, "# HELP lexer_tokens_total Tokens emitted, by token type.\n# TYPE lexer_tokens_total counter\n")
+	for _, count := range sink.tokenCounts() {
+		fmt.Fprintf(counter, "lexer_tokens_total{type=\"%s\"} %d\n", escapeLabel(count.name), count.count)
+	}
+
+	fmt.Fprintf(counter, "# HELP lexer_errors_total Diagnostics reported, by severity.\n# TYPE lexer_errors_total counter\n")
+	for _, count := range sink.errorCounts() {
+		fmt.Fprintf(counter, "lexer_errors_total{severity=\"%s\"} %d\n", escapeLabel(count.name), count.count)
+	}
+
+	fmt.Fprintf(counter, "# HELP lexer_runs_total Completed lexer runs.\n# TYPE lexer_runs_total counter\n")
+	fmt.Fprintf(counter, "lexer_runs_total %d\n", sink.runs.Load())
+
+	fmt.Fprintf(counter, "# HELP lexer_bytes_total Input bytes lexed.\n# TYPE lexer_bytes_total counter\n")
+	fmt.Fprintf(counter, "lexer_bytes_total %d\n", sink.bytes.Load())
+
+	writeHistogram(counter, "lexer_run_duration_seconds", "Duration of lexer runs.", sink.duration.snapshot())
+	writeHistogram(counter, "lexer_token_length_bytes", "Length of emitted tokens.", sink.length.snapshot())
+
+	if counter.err == nil {
+		counter.err = counter.writer.Flush()
+	}
+
+	return counter.count, counter.err
+}
+
+func writeHistogram(w io.Writer, name string, help string, snapshot histogramSnapshot) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	for index, bound := range snapshot.Bounds {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, strconv.FormatFloat(bound, 'g', -1, 64), snapshot.Buckets[index])
+	}
+
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, snapshot.Count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, strconv.FormatFloat(snapshot.Sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count %d\n", name, snapshot.Count)
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+type countingWriter struct {
+	writer *bufio.Writer
+	count  int64
+	err    error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	count, err := w.writer.Write(p)
+	w.count += int64(count)
+	w.err = err
+
+	return count, err
+}
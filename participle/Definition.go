@@ -0,0 +1,123 @@
+//go:build participle
+
+/*
+Package participle lets parsers written with participle read their
+tokens from lexers of this package, so a grammar can keep a hand-written
+state function lexer, or one built from rules, in place of participle's
+own regular expression lexers. With the package imported as
+lexparticiple:
+
+	definition := lexparticiple.NewDefinition(calc.Start, calc.Names)
+	parser := participle.MustBuild[Expression](participle.Lexer(definition), participle.Elide("SPACE"))
+
+Token types are named for participle by the lexer's TokenNames, so the
+names are what grammar tags and Elide refer to.
+
+The package needs participle v2 and is only built with the participle
+build tag, so that the lexer package itself does not depend on it.
+*/
+package participle
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+	plexer "github.com/alecthomas/participle/v2/lexer"
+)
+
+/*
+Definition implements participle's lexer.Definition, along with its
+StringDefinition and BytesDefinition, over a state function
+*/
+type Definition struct {
+	start   lexer.LexFn
+	names   lexer.TokenNames
+	options []lexer.Option
+}
+
+/*
+NewDefinition creates a definition of lexers starting in start, with
+token types named by names. The options are passed to every lexer.
+*/
+func NewDefinition(start lexer.LexFn, names lexer.TokenNames, options ...lexer.Option) *Definition {
+	return &Definition{
+		start:   start,
+		names:   names,
+		options: options,
+	}
+}
+
+/*
+Symbols returns the token types by name, including EOF. Token types
+keep their values, as TOKEN_EOF and participle's EOF agree.
+*/
+func (definition *Definition) Symbols() map[string]plexer.TokenType {
+	symbols := map[string]plexer.TokenType{
+		"EOF": plexer.EOF,
+	}
+
+	for tokenType, name := range definition.names {
+		symbols[name] = plexer.TokenType(tokenType)
+	}
+
+	return symbols
+}
+
+/*
+Lex creates a lexer over a reader
+*/
+func (definition *Definition) Lex(filename string, r io.Reader) (plexer.Lexer, error) {
+	return newLexer(lexer.NewReaderLexer(filename, r, definition.start, definition.options...)), nil
+}
+
+/*
+LexString creates a lexer over a string
+*/
+func (definition *Definition) LexString(filename string, input string) (plexer.Lexer, error) {
+	return newLexer(lexer.NewLexer(filename, input, definition.start, definition.options...)), nil
+}
+
+/*
+LexBytes creates a lexer over a byte slice
+*/
+func (definition *Definition) LexBytes(filename string, input []byte) (plexer.Lexer, error) {
+	return definition.LexString(filename, string(input))
+}
+
+/*
+Lexer implements participle's lexer.Lexer over a token stream. An
+error token ends parsing with its message and position.
+*/
+type Lexer struct {
+	stream *lexer.TokenStream
+}
+
+func newLexer(l *lexer.Lexer) *Lexer {
+	return &Lexer{stream: lexer.NewTokenStream(l)}
+}
+
+/*
+Next returns the next token, converted to participle's form
+*/
+func (adapter *Lexer) Next() (plexer.Token, error) {
+	token := adapter.stream.Next()
+	position := Position(token.Span.Start)
+
+	if token.Type == lexer.TOKEN_ERROR {
+		return plexer.Token{}, plexer.Errorf(position, "%s", token.Text)
+	}
+
+	return plexer.Token{Type: plexer.TokenType(token.Type), Value: token.Text, Pos: position}, nil
+}
+
+/*
+Position converts a position to participle's form
+*/
+func Position(position lexer.Position) plexer.Position {
+	return plexer.Position{
+		Filename: position.Filename,
+		Offset:   position.Offset,
+		Line:     position.Line,
+		Column:   position.Column,
+	}
+}
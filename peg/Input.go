@@ -0,0 +1,248 @@
+/*
+Package peg runs parsers generated by pigeon, the PEG parser generator,
+on the tokens of a lexer instead of on characters. pigeon parsers only
+read bytes, so Input writes each token as a single character standing
+for its type, from the private use area of Unicode, and translates the
+offsets pigeon reports back to tokens and their positions.
+
+A grammar matches tokens by those characters. WriteRules writes a rule
+for each token type, named after the type, to paste into the grammar:
+
+	NUMBER <- "\ue101"
+	PLUS <- "\ue103"
+
+after which rules read as they would over a lexer in any other parser
+generator:
+
+	Sum <- Term (PLUS Term)*
+
+Actions find the tokens they matched from pigeon's c.pos and c.text,
+with the Input passed to the parser through its global store:
+
+	input := peg.NewInput(calc.NewLexer("expr", source), calc.Names)
+	result, err := Parse("expr", input.Bytes(), GlobalStore("input", input))
+
+	Number <- NUMBER {
+		return c.globalStore["input"].(*peg.Input).Match(c.pos.offset, c.text)[0].Value, nil
+	}
+
+Errors translates the errors Parse returns into lexer errors at the
+positions of the tokens they happened at. The example directory holds a
+complete grammar.
+*/
+package peg
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Base is the character standing for token type 0. Type n is Base + n, so
+the predefined types, which are negative, come just before it.
+*/
+const Base rune = 0xE100
+
+/*
+width is the length in bytes of every character standing for a token
+*/
+const width = 3
+
+/*
+Rune returns the character standing for tokens of tokenType in the
+bytes of an Input. Types from -256 to 6143 have characters.
+*/
+func Rune(tokenType lexer.TokenType) rune {
+	ch := Base + rune(tokenType)
+
+	if ch < 0xE000 || ch > 0xF8FF {
+		panic(fmt.Sprintf("peg: token type %d has no character", tokenType))
+	}
+
+	return ch
+}
+
+/*
+Input holds the tokens of a lexer and their encoding as the bytes a
+pigeon parser reads
+*/
+type Input struct {
+	tokens []lexer.Token
+	eof    lexer.Token
+	text   []byte
+	names  lexer.TokenNames
+}
+
+/*
+NewInput lexes all of l, leaving out tokens of the types in skip and
+the EOF token, which pigeon grammars match with !. instead. names names
+token types in error messages.
+*/
+func NewInput(l *lexer.Lexer, names lexer.TokenNames, skip ...lexer.TokenType) *Input {
+	input := &Input{names: names}
+	stream := lexer.NewTokenStream(l, skip...)
+
+	for {
+		token := stream.Next()
+
+		if token.Type == lexer.TOKEN_EOF {
+			input.eof = token
+			return input
+		}
+
+		input.tokens = append(input.tokens, token)
+		input.text = utf8.AppendRune(input.text, Rune(token.Type))
+	}
+}
+
+/*
+Bytes returns the tokens as the bytes to parse
+*/
+func (input *Input) Bytes() []byte {
+	return input.text
+}
+
+/*
+Tokens returns all the tokens, without the EOF token
+*/
+func (input *Input) Tokens() []lexer.Token {
+	return input.tokens
+}
+
+/*
+Token returns the token at a byte offset of the parser, such as c.pos.offset
+in an action, or the EOF token past the last one
+*/
+func (input *Input) Token(offset int) lexer.Token {
+	index := offset / width
+
+	if index < 0 || index >= len(input.tokens) {
+		return input.eof
+	}
+
+	return input.tokens[index]
+}
+
+/*
+Match returns the tokens an expression matched, from c.pos.offset and
+c.text in its action
+*/
+func (input *Input) Match(offset int, text []byte) []lexer.Token {
+	start, end := offset/width, (offset+len(text))/width
+
+	if start < 0 || end > len(input.tokens) || start > end {
+		return nil
+	}
+
+	return input.tokens[start:end]
+}
+
+/*
+Span returns the span of the input an expression matched, from
+c.pos.offset and c.text in its action. An expression matching nothing
+has an empty span at the token it stopped before.
+*/
+func (input *Input) Span(offset int, text []byte) lexer.Span {
+	tokens := input.Match(offset, text)
+
+	if len(tokens) == 0 {
+		start := input.Token(offset).Span.Start
+		return lexer.Span{Start: start, End: start}
+	}
+
+	return lexer.Span{Start: tokens[0].Span.Start, End: tokens[len(tokens)-1].Span.End}
+}
+
+/*
+Position returns the position in the input of a byte offset of the
+parser
+*/
+func (input *Input) Position(offset int) lexer.Position {
+	return input.Token(offset).Span.Start
+}
+
+var (
+	parserErrorPattern = regexp.MustCompile(`^.*:\d+:\d+ \((\d+)\): (.*)$`)
+	quotedRunePattern  = regexp.MustCompile(`"(\\u[0-9a-fA-F]{4})"`)
+)
+
+/*
+Errors translates an error returned by a pigeon parser into lexer
+errors, one for each error the parser reported, positioned at the
+tokens they happened at. The characters standing for tokens in messages
+are replaced by the names of their types, and an error at an error token
+of the lexer reports the lexer's error instead.
+*/
+func (input *Input) Errors(err error) []lexer.LexError {
+	result := []lexer.LexError{}
+
+	if err == nil {
+		return result
+	}
+
+	for _, line := range strings.Split(err.Error(), "\n") {
+		diagnostic := lexer.LexError{Message: line, Severity: lexer.SEVERITY_ERROR}
+
+		if match := parserErrorPattern.FindStringSubmatch(line); match != nil {
+			offset, _ := strconv.Atoi(match[1])
+			token := input.Token(offset)
+
+			diagnostic.Message = input.describe(match[2]) + ", found " + input.names.Name(token.Type)
+			diagnostic.Span = token.Span
+
+			if token.Type == lexer.TOKEN_ERROR {
+				diagnostic.Message = token.Text
+			}
+		}
+
+		result = append(result, diagnostic)
+	}
+
+	return result
+}
+
+/*
+describe replaces the quoted token characters in a message of the
+parser by the names of their types
+*/
+func (input *Input) describe(message string) string {
+	return quotedRunePattern.ReplaceAllStringFunc(message, func(quoted string) string {
+		ch, err := strconv.Unquote(quoted)
+		if err != nil || utf8.RuneCountInString(ch) != 1 {
+			return quoted
+		}
+
+		r, _ := utf8.DecodeRuneInString(ch)
+		return input.names.Name(lexer.TokenType(r - Base))
+	})
+}
+
+/*
+WriteRules writes a pigeon rule for each token type in names, matching
+tokens of that type, in order of type
+*/
+func WriteRules(w io.Writer, names lexer.TokenNames) error {
+	types := make([]lexer.TokenType, 0, len(names))
+
+	for tokenType := range names {
+		types = append(types, tokenType)
+	}
+
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	result := strings.Builder{}
+
+	for _, tokenType := range types {
+		fmt.Fprintf(&result, "%s <- \"\\u%04x\"\n", names[tokenType], Rune(tokenType))
+	}
+
+	_, err := io.WriteString(w, result.String())
+	return err
+}
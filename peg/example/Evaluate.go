@@ -0,0 +1,34 @@
+//go:build pigeon
+
+/*
+Package example evaluates arithmetic with a parser pigeon generates from
+calc.peg, running on the tokens of the calc preset. The parser is not
+checked in, so generate it before building with the pigeon tag:
+
+	go install github.com/mna/pigeon@latest
+	go generate
+	go build -tags pigeon
+*/
+package example
+
+import (
+	"github.com/adampresley/lexer/peg"
+	"github.com/adampresley/lexer/presets/calc"
+)
+
+//go:generate pigeon -o grammar.go calc.peg
+
+/*
+Evaluate returns the value of the expression in source. A syntax error
+is returned as a lexer error at the token the parser stopped at.
+*/
+func Evaluate(source string) (float64, error) {
+	input := peg.NewInput(calc.NewLexer("expression", source), calc.Names)
+
+	result, err := Parse("expression", input.Bytes(), GlobalStore("input", input))
+	if err != nil {
+		return 0, input.Errors(err)[0]
+	}
+
+	return result.(float64), nil
+}
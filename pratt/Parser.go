@@ -0,0 +1,227 @@
+/*
+Package pratt parses expressions from a lexer.TokenStream by Pratt's
+top down operator precedence, the usual next step after lexing. Tokens
+that start an expression, such as literals, prefix operators and
+opening parentheses, get a prefix function, and tokens that continue
+one, such as binary and postfix operators, get an infix function and a
+binding power. Operators that bind tighter have higher powers.
+
+	parser := pratt.New(stream).
+		Prefix(TOKEN_NUMBER, func(parser *pratt.Parser, token lexer.Token) (interface{}, error) {
+			return strconv.ParseFloat(token.Text, 64)
+		}).
+		Unary(TOKEN_MINUS, 30, func(op lexer.Token, operand interface{}) interface{} {
+			return -operand.(float64)
+		}).
+		Binary(TOKEN_PLUS, 10, add).
+		Binary(TOKEN_STAR, 20, multiply).
+		BinaryRight(TOKEN_CARET, 40, power)
+
+	value, err := parser.Expression()
+
+Results are whatever the functions return, usually nodes of a syntax
+tree.
+*/
+package pratt
+
+import (
+	"github.com/adampresley/lexer"
+)
+
+/*
+PrefixFn parses an expression starting with token, which has been
+consumed
+*/
+type PrefixFn func(parser *Parser, token lexer.Token) (interface{}, error)
+
+/*
+InfixFn parses the rest of an expression continuing left with token,
+which has been consumed
+*/
+type InfixFn func(parser *Parser, left interface{}, token lexer.Token) (interface{}, error)
+
+type infix struct {
+	power int
+	right bool
+	fn    InfixFn
+}
+
+/*
+Parser holds the prefix and infix functions of an expression grammar.
+Functions are registered by token type, and registering a type again
+replaces its function.
+*/
+type Parser struct {
+	stream   *lexer.TokenStream
+	prefixes map[lexer.TokenType]PrefixFn
+	infixes  map[lexer.TokenType]infix
+}
+
+/*
+New creates a parser reading from stream
+*/
+func New(stream *lexer.TokenStream) *Parser {
+	return &Parser{
+		stream:   stream,
+		prefixes: map[lexer.TokenType]PrefixFn{},
+		infixes:  map[lexer.TokenType]infix{},
+	}
+}
+
+/*
+Stream returns the token stream the parser reads from, for prefix and
+infix functions that consume tokens of their own, such as the closing
+parenthesis of a group
+*/
+func (parser *Parser) Stream() *lexer.TokenStream {
+	return parser.stream
+}
+
+/*
+Prefix registers the function parsing expressions that start with
+tokens of tokenType
+*/
+func (parser *Parser) Prefix(tokenType lexer.TokenType, fn PrefixFn) *Parser {
+	parser.prefixes[tokenType] = fn
+	return parser
+}
+
+/*
+Infix registers the function continuing expressions with tokens of
+tokenType, which binds with the given power. The function parses its
+right operand, if it has one, with ParseRight. Operators of the same
+power group to the left.
+*/
+func (parser *Parser) Infix(tokenType lexer.TokenType, power int, fn InfixFn) *Parser {
+	parser.infixes[tokenType] = infix{power: power, fn: fn}
+	return parser
+}
+
+/*
+InfixRight registers an infix function as Infix does, for an operator
+that groups to the right, such as exponentiation or assignment
+*/
+func (parser *Parser) InfixRight(tokenType lexer.TokenType, power int, fn InfixFn) *Parser {
+	parser.infixes[tokenType] = infix{power: power, right: true, fn: fn}
+	return parser
+}
+
+/*
+Unary registers a prefix operator whose operand binds with the given
+power, combined by fn
+*/
+func (parser *Parser) Unary(tokenType lexer.TokenType, power int, fn func(op lexer.Token, operand interface{}) interface{}) *Parser {
+	return parser.Prefix(tokenType, func(parser *Parser, token lexer.Token) (interface{}, error) {
+		operand, err := parser.Parse(power)
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(token, operand), nil
+	})
+}
+
+/*
+Binary registers a binary operator grouping to the left, whose operands
+are combined by fn
+*/
+func (parser *Parser) Binary(tokenType lexer.TokenType, power int, fn func(op lexer.Token, left interface{}, right interface{}) interface{}) *Parser {
+	return parser.Infix(tokenType, power, binary(fn))
+}
+
+/*
+BinaryRight registers a binary operator grouping to the right, whose
+operands are combined by fn
+*/
+func (parser *Parser) BinaryRight(tokenType lexer.TokenType, power int, fn func(op lexer.Token, left interface{}, right interface{}) interface{}) *Parser {
+	return parser.InfixRight(tokenType, power, binary(fn))
+}
+
+/*
+Postfix registers a postfix operator, such as a factorial, whose
+operand is combined by fn
+*/
+func (parser *Parser) Postfix(tokenType lexer.TokenType, power int, fn func(op lexer.Token, operand interface{}) interface{}) *Parser {
+	return parser.Infix(tokenType, power, func(parser *Parser, left interface{}, token lexer.Token) (interface{}, error) {
+		return fn(token, left), nil
+	})
+}
+
+func binary(fn func(op lexer.Token, left interface{}, right interface{}) interface{}) InfixFn {
+	return func(parser *Parser, left interface{}, token lexer.Token) (interface{}, error) {
+		right, err := parser.ParseRight(token)
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(token, left, right), nil
+	}
+}
+
+/*
+Expression parses a whole expression, leaving the tokens after it in
+the stream, so callers expecting the end of input check for EOF
+*/
+func (parser *Parser) Expression() (interface{}, error) {
+	return parser.Parse(0)
+}
+
+/*
+Parse parses an expression whose operators all bind tighter than power.
+Prefix functions call it for their operands, and for groups with a power
+of 0.
+*/
+func (parser *Parser) Parse(power int) (interface{}, error) {
+	token := parser.stream.Next()
+
+	prefix, ok := parser.prefixes[token.Type]
+	if !ok {
+		return nil, unexpected(token)
+	}
+
+	left, err := prefix(parser, token)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		next := parser.stream.Peek()
+
+		operator, ok := parser.infixes[next.Type]
+		if !ok || operator.power <= power {
+			return left, nil
+		}
+
+		parser.stream.Next()
+
+		if left, err = operator.fn(parser, left, next); err != nil {
+			return nil, err
+		}
+	}
+}
+
+/*
+ParseRight parses the right operand of the infix operator token, with
+the operator's power and grouping
+*/
+func (parser *Parser) ParseRight(token lexer.Token) (interface{}, error) {
+	operator := parser.infixes[token.Type]
+
+	if operator.right {
+		return parser.Parse(operator.power - 1)
+	}
+
+	return parser.Parse(operator.power)
+}
+
+/*
+unexpected returns the error for a token that can not start an
+expression. Error tokens give the lexer's error.
+*/
+func unexpected(token lexer.Token) error {
+	if token.Type == lexer.TOKEN_ERROR {
+		return lexer.LexError{Message: token.Text, Span: token.Span, Severity: lexer.SEVERITY_ERROR}
+	}
+
+	return &lexer.UnexpectedTokenError{Token: token}
+}
@@ -0,0 +1,39 @@
+/*
+Package address is a ready-made lexer for email address lists as
+written in To, Cc and From header fields, following the address syntax
+of RFC 5322 section 3.4 with the UTF-8 extension of RFC 6532:
+
+	l := address.NewLexer("to", `"Public, John Q." <jqp@example.com> (work), Team: a@b.example, c@[192.0.2.1];`)
+
+	for _, token := range l.Collect() {
+		...
+	}
+
+Atoms, quoted strings, domain literals, comments and the special
+characters between them are lexed as separate tokens, and folding
+whitespace is skipped, leaving the mailbox and group structure to a
+parser. Quoted strings carry their contents in Value with quoted pairs
+resolved and folding removed. Comments nest, as the RFC allows, and are
+kept as tokens since some mail software puts display names in them.
+*/
+package address
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for an address list held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for an address list read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
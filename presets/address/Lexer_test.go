@@ -0,0 +1,122 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestAddressList(t *testing.T) {
+	input := `"Public, John Q." <jqp@example.com> (work (home)), Team: a@b.example, c@[192.0.2.1];`
+	want := `QUOTED_STRING "\"Public, John Q.\""
+LEFT_ANGLE "<"
+ATOM "jqp"
+AT "@"
+ATOM "example"
+DOT "."
+ATOM "com"
+RIGHT_ANGLE ">"
+COMMENT "(work (home))"
+COMMA ","
+ATOM "Team"
+COLON ":"
+ATOM "a"
+AT "@"
+ATOM "b"
+DOT "."
+ATOM "example"
+COMMA ","
+ATOM "c"
+AT "@"
+DOMAIN_LITERAL "[192.0.2.1]"
+SEMICOLON ";"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestCommentsAndQuoting(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			`a (x\)y) b`,
+			`ATOM "a"
+COMMENT "(x\\)y)"
+ATOM "b"
+EOF ""
+`,
+		},
+		{
+			`"(not a comment)" x`,
+			`QUOTED_STRING "\"(not a comment)\""
+ATOM "x"
+EOF ""
+`,
+		},
+		{
+			"José <jose@exämple.com>",
+			`ATOM "José"
+LEFT_ANGLE "<"
+ATOM "jose"
+AT "@"
+ATOM "exämple"
+DOT "."
+ATOM "com"
+RIGHT_ANGLE ">"
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input)))
+
+		if got != test.want {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+		}
+	}
+}
+
+func TestQuotedStringValues(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`"Public, John Q."`, "Public, John Q."},
+		{`"a\"b"`, `a"b`},
+		{"\"folded\r\n line\"", "folded line"},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if tokens[0].Type != TOKEN_QUOTED_STRING || tokens[0].Value != test.want {
+			t.Errorf("lexing %q: got %s with value %q, want QUOTED_STRING with value %q", test.input, Names.Name(tokens[0].Type), tokens[0].Value, test.want)
+		}
+	}
+}
+
+func TestUnterminated(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`"open`, "test:1:1: unterminated quoted string"},
+		{"[open", "test:1:1: unterminated domain literal"},
+		{"(open (x) a", "test:1:1: unterminated comment"},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input)
+		lexertest.Collect(t, l)
+
+		if diagnostics := l.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Error() != test.want {
+			t.Errorf("lexing %q: got errors %v, want %q", test.input, diagnostics, test.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package address
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	// Folding whitespace, including the line breaks of folded headers
+	whitespace = lexer.NewCharClass(" \t\r\n")
+
+	// atext from RFC 5322 section 3.2.3, extended to all non-ASCII
+	// characters by RFC 6532
+	atext = lexer.NewCharClass("!#$%&'*+-/=?^_`{|}~").
+		AddRange('0', '9').
+		AddRange('a', 'z').
+		AddRange('A', 'Z').
+		AddRange(0x80, unicode.MaxRune)
+)
+
+func init() {
+	lexer.NameState("address.token", Start)
+}
+
+/*
+Start is the only state. It skips folding whitespace and lexes one
+token, using AcceptDelimited and AcceptNested for the quoted forms.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(whitespace)
+	l.Ignore()
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	ch := l.Peek()
+
+	if tokenType, ok := specials[ch]; ok {
+		l.Next()
+		l.Emit(tokenType)
+		return Start
+	}
+
+	switch {
+	case atext.Contains(ch):
+		l.AcceptClassRun(atext)
+		l.Emit(TOKEN_ATOM)
+
+	case ch == '"':
+		if l.AcceptDelimited('"', '"', '\\') {
+			l.EmitWithTransform(TOKEN_QUOTED_STRING, Unquote)
+		} else {
+			unterminated(l, "unterminated quoted string")
+		}
+
+	case ch == '(':
+		if l.AcceptNested('(', ')', '\\') {
+			l.Emit(TOKEN_COMMENT)
+		} else {
+			unterminated(l, "unterminated comment")
+		}
+
+	case ch == '[':
+		if l.AcceptDelimited('[', ']', '\\') {
+			l.Emit(TOKEN_DOMAIN_LITERAL)
+		} else {
+			unterminated(l, "unterminated domain literal")
+		}
+
+	default:
+		l.Next()
+		l.Errorf("unexpected character %q", ch)
+		l.Ignore()
+	}
+
+	return Start
+}
+
+func unterminated(l *lexer.Lexer, message string) {
+	l.Errorf("%s", message)
+	l.Ignore()
+}
+
+/*
+Unquote is the TokenValueTransformer for quoted strings. It removes the
+quotes, resolves quoted pairs, and removes the line breaks of folding
+whitespace, keeping the whitespace that follows them.
+*/
+func Unquote(text string) interface{} {
+	text = text[1 : len(text)-1]
+
+	if !strings.ContainsAny(text, "\\\r\n") {
+		return text
+	}
+
+	result := make([]byte, 0, len(text))
+
+	for index := 0; index < len(text); index++ {
+		switch ch := text[index]; {
+		case ch == '\\' && index+1 < len(text):
+			index++
+			result = append(result, text[index])
+
+		case ch != '\r' && ch != '\n':
+			result = append(result, ch)
+		}
+	}
+
+	return string(result)
+}
@@ -0,0 +1,64 @@
+package address
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_ATOM lexer.TokenType = iota + 1
+	TOKEN_QUOTED_STRING
+	TOKEN_DOMAIN_LITERAL
+	TOKEN_COMMENT
+	TOKEN_DOT
+	TOKEN_AT
+	TOKEN_LEFT_ANGLE
+	TOKEN_RIGHT_ANGLE
+	TOKEN_COMMA
+	TOKEN_COLON
+	TOKEN_SEMICOLON
+)
+
+/*
+Names holds the names of the address token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_ATOM:           "ATOM",
+	TOKEN_QUOTED_STRING:  "QUOTED_STRING",
+	TOKEN_DOMAIN_LITERAL: "DOMAIN_LITERAL",
+	TOKEN_COMMENT:        "COMMENT",
+	TOKEN_DOT:            "DOT",
+	TOKEN_AT:             "AT",
+	TOKEN_LEFT_ANGLE:     "LEFT_ANGLE",
+	TOKEN_RIGHT_ANGLE:    "RIGHT_ANGLE",
+	TOKEN_COMMA:          "COMMA",
+	TOKEN_COLON:          "COLON",
+	TOKEN_SEMICOLON:      "SEMICOLON",
+}
+
+/*
+Categories maps the address token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_ATOM:           highlight.CATEGORY_IDENTIFIER,
+	TOKEN_QUOTED_STRING:  highlight.CATEGORY_STRING,
+	TOKEN_DOMAIN_LITERAL: highlight.CATEGORY_LITERAL,
+	TOKEN_COMMENT:        highlight.CATEGORY_COMMENT,
+	TOKEN_DOT:            highlight.CATEGORY_PUNCTUATION,
+	TOKEN_AT:             highlight.CATEGORY_OPERATOR,
+	TOKEN_LEFT_ANGLE:     highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_ANGLE:    highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMA:          highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COLON:          highlight.CATEGORY_PUNCTUATION,
+	TOKEN_SEMICOLON:      highlight.CATEGORY_PUNCTUATION,
+}
+
+var specials = map[rune]lexer.TokenType{
+	'.': TOKEN_DOT,
+	'@': TOKEN_AT,
+	'<': TOKEN_LEFT_ANGLE,
+	'>': TOKEN_RIGHT_ANGLE,
+	',': TOKEN_COMMA,
+	':': TOKEN_COLON,
+	';': TOKEN_SEMICOLON,
+}
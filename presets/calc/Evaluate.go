@@ -0,0 +1,267 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Function is a function that can be called from an expression. Arity is
+the number of arguments it takes, or -1 for any number of at least one.
+*/
+type Function struct {
+	Arity int
+	Call  func(args []float64) float64
+}
+
+/*
+Functions are the functions known to Evaluate. Add to it to make more
+available.
+*/
+var Functions = map[string]Function{
+	"abs":   unary(math.Abs),
+	"ceil":  unary(math.Ceil),
+	"cos":   unary(math.Cos),
+	"exp":   unary(math.Exp),
+	"floor": unary(math.Floor),
+	"ln":    unary(math.Log),
+	"log":   unary(math.Log10),
+	"round": unary(math.Round),
+	"sin":   unary(math.Sin),
+	"sqrt":  unary(math.Sqrt),
+	"tan":   unary(math.Tan),
+	"max":   {Arity: -1, Call: fold(math.Max)},
+	"min":   {Arity: -1, Call: fold(math.Min)},
+}
+
+/*
+Constants are the names Evaluate knows without being given them as
+variables. Variables of the same name take precedence.
+*/
+var Constants = map[string]float64{
+	"e":  math.E,
+	"pi": math.Pi,
+}
+
+/*
+Evaluate computes the value of an expression. Names in the expression
+are looked up in variables, and then in Constants. The usual precedence
+applies: ^ binds tightest and to the right, then unary minus, then * and
+/, then + and -, so -2^2 is -4.
+
+The returned error gives the position of the first problem found, which
+may be an error from the lexer, a syntax error, or an unknown name.
+*/
+func Evaluate(expression string, variables map[string]float64) (float64, error) {
+	p := &parser{
+		stream:    lexer.NewTokenStream(NewLexer("expression", expression)),
+		variables: variables,
+	}
+
+	value, err := p.expression()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := p.stream.Expect(lexer.TOKEN_EOF); err != nil {
+		return 0, p.check(err)
+	}
+
+	return value, nil
+}
+
+type parser struct {
+	stream    *lexer.TokenStream
+	variables map[string]float64
+}
+
+/*
+expression parses a sum or difference of terms
+*/
+func (p *parser) expression() (float64, error) {
+	value, err := p.term()
+
+	for err == nil && p.stream.Is(TOKEN_PLUS, TOKEN_MINUS) {
+		operator := p.stream.Next()
+
+		var right float64
+		if right, err = p.term(); operator.Type == TOKEN_PLUS {
+			value += right
+		} else {
+			value -= right
+		}
+	}
+
+	return value, err
+}
+
+/*
+term parses a product or quotient of factors
+*/
+func (p *parser) term() (float64, error) {
+	value, err := p.factor()
+
+	for err == nil && p.stream.Is(TOKEN_STAR, TOKEN_SLASH) {
+		operator := p.stream.Next()
+
+		var right float64
+		if right, err = p.factor(); operator.Type == TOKEN_STAR {
+			value *= right
+		} else {
+			value /= right
+		}
+	}
+
+	return value, err
+}
+
+/*
+factor parses a signed power
+*/
+func (p *parser) factor() (float64, error) {
+	if operator, ok := p.stream.Accept(TOKEN_PLUS, TOKEN_MINUS); ok {
+		value, err := p.factor()
+
+		if operator.Type == TOKEN_MINUS {
+			value = -value
+		}
+
+		return value, err
+	}
+
+	return p.power()
+}
+
+/*
+power parses an operand raised to a power. The exponent is a factor, so
+powers are right associative and may be negative, as in 2^-1.
+*/
+func (p *parser) power() (float64, error) {
+	value, err := p.operand()
+
+	if err == nil && p.stream.Is(TOKEN_CARET) {
+		p.stream.Next()
+
+		var exponent float64
+		exponent, err = p.factor()
+		value = math.Pow(value, exponent)
+	}
+
+	return value, err
+}
+
+/*
+operand parses a number, a variable, a function call or an expression in
+parentheses
+*/
+func (p *parser) operand() (float64, error) {
+	token := p.stream.Peek()
+
+	switch {
+	case token.Type == TOKEN_NUMBER:
+		p.stream.Next()
+		return token.Value.(float64), nil
+
+	case token.Type == TOKEN_IDENTIFIER && p.stream.LookAhead(2).Type == TOKEN_LEFT_PAREN:
+		return p.call()
+
+	case token.Type == TOKEN_IDENTIFIER:
+		p.stream.Next()
+
+		if value, ok := p.variables[token.Text]; ok {
+			return value, nil
+		}
+
+		if value, ok := Constants[token.Text]; ok {
+			return value, nil
+		}
+
+		return 0, fmt.Errorf("%s: unknown variable %q", token.Span.Start, token.Text)
+
+	case token.Type == TOKEN_LEFT_PAREN:
+		p.stream.Next()
+
+		value, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = p.stream.Expect(TOKEN_RIGHT_PAREN)
+		return value, p.check(err)
+	}
+
+	_, err := p.stream.Expect(TOKEN_NUMBER)
+	return 0, p.check(err)
+}
+
+/*
+call parses a function call and calls the function
+*/
+func (p *parser) call() (float64, error) {
+	name := p.stream.Next()
+	p.stream.Next()
+
+	function, ok := Functions[name.Text]
+	if !ok {
+		return 0, fmt.Errorf("%s: unknown function %q", name.Span.Start, name.Text)
+	}
+
+	var args []float64
+
+	for !p.stream.Is(TOKEN_RIGHT_PAREN) || len(args) == 0 {
+		if len(args) > 0 {
+			if _, err := p.stream.Expect(TOKEN_COMMA); err != nil {
+				return 0, p.check(err)
+			}
+		}
+
+		arg, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+
+		args = append(args, arg)
+	}
+
+	p.stream.Next()
+
+	if function.Arity >= 0 && len(args) != function.Arity {
+		return 0, fmt.Errorf("%s: %s takes %d arguments, not %d", name.Span.Start, name.Text, function.Arity, len(args))
+	}
+
+	return function.Call(args), nil
+}
+
+/*
+check turns an unexpected error token into the lexer's error message
+*/
+func (p *parser) check(err error) error {
+	if unexpected, ok := err.(*lexer.UnexpectedTokenError); ok && unexpected.Token.Type == lexer.TOKEN_ERROR {
+		return fmt.Errorf("%s: %s", unexpected.Token.Span.Start, unexpected.Token.Text)
+	}
+
+	return err
+}
+
+func unary(fn func(float64) float64) Function {
+	return Function{
+		Arity: 1,
+		Call: func(args []float64) float64 {
+			return fn(args[0])
+		},
+	}
+}
+
+func fold(fn func(float64, float64) float64) func([]float64) float64 {
+	return func(args []float64) float64 {
+		result := args[0]
+
+		for _, arg := range args[1:] {
+			result = fn(result, arg)
+		}
+
+		return result
+	}
+}
@@ -0,0 +1,36 @@
+/*
+Package calc is a ready-made lexer for arithmetic expressions, with an
+evaluator built on it that shows the whole pipeline from text to result:
+
+	value, err := calc.Evaluate("2 * sin(pi / 6) + x^2", map[string]float64{"x": 3})
+
+The lexer recognizes numbers, identifiers, the operators + - * / and ^,
+parentheses, and commas between function arguments. Numbers carry their
+value as a float64. Whitespace is skipped.
+
+Evaluate is a recursive descent parser reading the tokens through a
+lexer.TokenStream, and is written to be read as an example of parsing
+with one. It uses the stream's lookahead to tell a function call, an
+identifier followed by a parenthesis, from a variable.
+*/
+package calc
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for an expression held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for an expression read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
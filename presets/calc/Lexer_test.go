@@ -0,0 +1,98 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestTokens(t *testing.T) {
+	input := "2 * sin(pi / 6) + x^2 - max(a, b)"
+	want := `NUMBER "2"
+STAR "*"
+IDENTIFIER "sin"
+LEFT_PAREN "("
+IDENTIFIER "pi"
+SLASH "/"
+NUMBER "6"
+RIGHT_PAREN ")"
+PLUS "+"
+IDENTIFIER "x"
+CARET "^"
+NUMBER "2"
+MINUS "-"
+IDENTIFIER "max"
+LEFT_PAREN "("
+IDENTIFIER "a"
+COMMA ","
+IDENTIFIER "b"
+RIGHT_PAREN ")"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []float64
+		rest  string
+	}{
+		{"42", []float64{42}, ""},
+		{"2.5", []float64{2.5}, ""},
+		{".5", []float64{0.5}, ""},
+		{"6.02e23", []float64{6.02e23}, ""},
+		{"1.5E-3", []float64{1.5e-3}, ""},
+		{"1.2.3", []float64{1.2, 0.3}, ""},
+		{"2e", []float64{2}, "e"},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		var numbers []float64
+		rest := ""
+
+		for _, token := range tokens {
+			switch token.Type {
+			case TOKEN_NUMBER:
+				numbers = append(numbers, token.Value.(float64))
+			case TOKEN_IDENTIFIER:
+				rest += token.Text
+			}
+		}
+
+		if len(numbers) != len(test.want) || rest != test.rest {
+			t.Errorf("lexing %q: got numbers %v and identifiers %q, want %v and %q", test.input, numbers, rest, test.want, test.rest)
+			continue
+		}
+
+		for index, number := range numbers {
+			if number != test.want[index] {
+				t.Errorf("lexing %q: got number %d = %v, want %v", test.input, index, number, test.want[index])
+			}
+		}
+	}
+}
+
+func TestUnexpectedCharacter(t *testing.T) {
+	input := "3 $ 4"
+	want := `NUMBER "3"
+ERROR "unexpected character '$'"
+NUMBER "4"
+EOF ""
+`
+
+	l := NewLexer("test", input)
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, l)); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+
+	if diagnostics := l.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Error() != "test:1:3: unexpected character '$'" {
+		t.Errorf("lexing %q: got errors %v", input, diagnostics)
+	}
+}
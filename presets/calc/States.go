@@ -0,0 +1,109 @@
+package calc
+
+import (
+	"strconv"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	whitespace      = lexer.WhitespaceClass
+	digits          = lexer.DigitClass
+	identifierStart = lexer.NewCharClass("_").AddRange('a', 'z').AddRange('A', 'Z')
+	identifierChars = lexer.IdentifierClass
+)
+
+var operators = map[rune]lexer.TokenType{
+	'+': TOKEN_PLUS,
+	'-': TOKEN_MINUS,
+	'*': TOKEN_STAR,
+	'/': TOKEN_SLASH,
+	'^': TOKEN_CARET,
+	'(': TOKEN_LEFT_PAREN,
+	')': TOKEN_RIGHT_PAREN,
+	',': TOKEN_COMMA,
+}
+
+func init() {
+	lexer.NameState("calc.start", Start)
+	lexer.NameState("calc.number", lexNumber)
+	lexer.NameState("calc.identifier", lexIdentifier)
+}
+
+/*
+Start is the state between tokens. It skips whitespace and decides
+which state lexes the next token from its first character.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(whitespace)
+	l.Ignore()
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	ch := l.Peek()
+	next := l.PeekCharacters(2)
+
+	switch {
+	case digits.Contains(ch) || (ch == '.' && len(next) == 2 && digits.Contains(rune(next[1]))):
+		return lexNumber
+
+	case identifierStart.Contains(ch):
+		return lexIdentifier
+	}
+
+	l.Next()
+
+	if tokenType, ok := operators[ch]; ok {
+		l.Emit(tokenType)
+		return Start
+	}
+
+	l.Errorf("unexpected character %q", ch)
+	l.Ignore()
+
+	return Start
+}
+
+/*
+lexNumber lexes a number such as 42, 2.5, .5 or 6.02e23. An e that is
+not followed by an exponent is left to be lexed as an identifier, so
+2e reads as 2 followed by e.
+*/
+func lexNumber(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(digits)
+
+	if l.Accept(".") {
+		l.AcceptClassRun(digits)
+	}
+
+	if ch := l.Peek(); ch == 'e' || ch == 'E' {
+		mark := l.Mark()
+		l.Next()
+		l.Accept("+-")
+
+		if l.AcceptClassRun(digits) == 0 {
+			l.Rewind(mark)
+		}
+	}
+
+	l.EmitWithTransform(TOKEN_NUMBER, number)
+	return Start
+}
+
+/*
+lexIdentifier lexes the name of a variable or function
+*/
+func lexIdentifier(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(identifierChars)
+	l.Emit(TOKEN_IDENTIFIER)
+
+	return Start
+}
+
+func number(text string) interface{} {
+	value, _ := strconv.ParseFloat(text, 64)
+	return value
+}
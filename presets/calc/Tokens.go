@@ -0,0 +1,51 @@
+package calc
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_NUMBER lexer.TokenType = iota + 1
+	TOKEN_IDENTIFIER
+	TOKEN_PLUS
+	TOKEN_MINUS
+	TOKEN_STAR
+	TOKEN_SLASH
+	TOKEN_CARET
+	TOKEN_LEFT_PAREN
+	TOKEN_RIGHT_PAREN
+	TOKEN_COMMA
+)
+
+/*
+Names holds the names of the expression token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_NUMBER:      "NUMBER",
+	TOKEN_IDENTIFIER:  "IDENTIFIER",
+	TOKEN_PLUS:        "PLUS",
+	TOKEN_MINUS:       "MINUS",
+	TOKEN_STAR:        "STAR",
+	TOKEN_SLASH:       "SLASH",
+	TOKEN_CARET:       "CARET",
+	TOKEN_LEFT_PAREN:  "LEFT_PAREN",
+	TOKEN_RIGHT_PAREN: "RIGHT_PAREN",
+	TOKEN_COMMA:       "COMMA",
+}
+
+/*
+Categories maps the expression token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_NUMBER:      highlight.CATEGORY_NUMBER,
+	TOKEN_IDENTIFIER:  highlight.CATEGORY_IDENTIFIER,
+	TOKEN_PLUS:        highlight.CATEGORY_OPERATOR,
+	TOKEN_MINUS:       highlight.CATEGORY_OPERATOR,
+	TOKEN_STAR:        highlight.CATEGORY_OPERATOR,
+	TOKEN_SLASH:       highlight.CATEGORY_OPERATOR,
+	TOKEN_CARET:       highlight.CATEGORY_OPERATOR,
+	TOKEN_LEFT_PAREN:  highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_PAREN: highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMA:       highlight.CATEGORY_PUNCTUATION,
+}
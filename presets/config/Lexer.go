@@ -0,0 +1,49 @@
+/*
+Package config is a ready-made lexer for INI and TOML style
+configuration files:
+
+	# Comments start with # or ;
+	[server]
+	host = "example.com"
+	port = 8080
+	debug = false
+	started = 2024-05-27T07:32:00Z
+	tags = ["web", "api"]
+	limits = { requests = 100, burst = 20 }
+
+	[[backends]]
+	name = primary database
+
+Sections, dotted and quoted keys, basic, literal and multi-line strings,
+numbers in every TOML form, booleans, date-times, arrays and inline
+tables are all recognized. As in INI files, a value that is none of
+these runs to the end of its line and is emitted as TOKEN_VALUE.
+TOKEN_NEWLINE ends each key/value pair and section header, except
+inside arrays where line breaks are insignificant.
+
+Arrays and inline tables nest to any depth using the lexer's mode
+stack, and every state is registered with NameState, so lexing can be
+checkpointed and resumed anywhere.
+*/
+package config
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for a configuration file held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for a configuration file read from
+reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
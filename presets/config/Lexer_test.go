@@ -0,0 +1,148 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			"[server]\nhost = \"example.com\" # c\nport = 8080\n; own line\ndebug = false\n",
+			`SECTION_OPEN "["
+KEY "server"
+SECTION_CLOSE "]"
+NEWLINE "\n"
+KEY "host"
+ASSIGN "="
+STRING "\"example.com\""
+COMMENT "# c"
+NEWLINE "\n"
+KEY "port"
+ASSIGN "="
+NUMBER "8080"
+NEWLINE "\n"
+COMMENT "; own line"
+KEY "debug"
+ASSIGN "="
+BOOLEAN "false"
+NEWLINE "\n"
+EOF ""
+`,
+		},
+		{
+			"[[backends]]\n\"quoted.key\".sub = 'literal'\nname = primary database\n",
+			`SECTION_OPEN "[["
+KEY "backends"
+SECTION_CLOSE "]]"
+NEWLINE "\n"
+KEY "\"quoted.key\""
+DOT "."
+KEY "sub"
+ASSIGN "="
+STRING "'literal'"
+NEWLINE "\n"
+KEY "name"
+ASSIGN "="
+VALUE "primary database"
+NEWLINE "\n"
+EOF ""
+`,
+		},
+		{
+			"a = [1,\n \"x\"]\nb = { c = true, d = 1979-05-27T07:32:00Z }\n",
+			`KEY "a"
+ASSIGN "="
+LEFT_BRACKET "["
+NUMBER "1"
+COMMA ","
+STRING "\"x\""
+RIGHT_BRACKET "]"
+NEWLINE "\n"
+KEY "b"
+ASSIGN "="
+LEFT_BRACE "{"
+KEY "c"
+ASSIGN "="
+BOOLEAN "true"
+COMMA ","
+KEY "d"
+ASSIGN "="
+DATETIME "1979-05-27T07:32:00Z"
+RIGHT_BRACE "}"
+NEWLINE "\n"
+EOF ""
+`,
+		},
+		{
+			"a = 0x1F\nb = 1_000\nc = -1.5e3\n",
+			`KEY "a"
+ASSIGN "="
+NUMBER "0x1F"
+NEWLINE "\n"
+KEY "b"
+ASSIGN "="
+NUMBER "1_000"
+NEWLINE "\n"
+KEY "c"
+ASSIGN "="
+NUMBER "-1.5e3"
+NEWLINE "\n"
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input)))
+
+		if got != test.want {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+		}
+	}
+}
+
+func TestStringValues(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`a = "tab\there"`, "tab\there"},
+		{`a = "caf\u00e9"`, "café"},
+		{`a = 'C:\path'`, `C:\path`},
+		{"a = \"\"\"multi\nline\"\"\"", "multi\nline"},
+		{"a = '''raw\\n'''", `raw\n`},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if tokens[2].Type != TOKEN_STRING || tokens[2].Value != test.want {
+			t.Errorf("lexing %q: got %s with value %q, want STRING with value %q", test.input, Names.Name(tokens[2].Type), tokens[2].Value, test.want)
+		}
+	}
+}
+
+func TestUnterminated(t *testing.T) {
+	input := "a = \"open\nb = [1,\n"
+	want := `KEY "a"
+ASSIGN "="
+ERROR "unterminated string"
+NEWLINE "\n"
+KEY "b"
+ASSIGN "="
+LEFT_BRACKET "["
+NUMBER "1"
+COMMA ","
+ERROR "unterminated array"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
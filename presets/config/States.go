@@ -0,0 +1,491 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	blanks    = lexer.NewCharClass(" \t")
+	keyChars  = lexer.NewCharClass("=:[]{}#;\"'.,\r\n").Not()
+	wordChars = lexer.NewCharClass("0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_+-.:")
+	lineChars = lexer.NewCharClass("\r\n").Not()
+)
+
+func init() {
+	lexer.NameState("config.line", Start)
+	lexer.NameState("config.key", lexKey)
+	lexer.NameState("config.section", lexSection)
+	lexer.NameState("config.value", lexValue)
+	lexer.NameState("config.lineEnd", lexLineEnd)
+	lexer.NameState("config.array", lexArray)
+	lexer.NameState("config.inlineKey", lexInlineKey)
+	lexer.NameState("config.inlineValue", lexInlineValue)
+}
+
+/*
+Start is the state at the beginning of a line, where a section header,
+a key, a comment, or a blank line may follow
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	skipBlanks(l)
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	if l.AtNUL() {
+		l.Next()
+		l.Errorf("unexpected character %q", rune(0))
+		l.Ignore()
+
+		return Start
+	}
+
+	switch l.Peek() {
+	case '\r', '\n':
+		l.AcceptNewline()
+		l.Ignore()
+		return Start
+
+	case '#', ';':
+		lexComment(l)
+		return Start
+
+	case '[':
+		l.Next()
+		l.Accept("[")
+		l.Emit(TOKEN_SECTION_OPEN)
+		return lexSection
+	}
+
+	return lexKey
+}
+
+/*
+lexKey lexes the parts of a key up to the assignment. A key alone on a
+line, as used for flags in some INI files, is allowed.
+*/
+func lexKey(l *lexer.Lexer) lexer.LexFn {
+	skipBlanks(l)
+
+	if lexKeyPart(l) {
+		return lexKey
+	}
+
+	if l.Accept("=:") {
+		l.Emit(TOKEN_ASSIGN)
+		return lexValue
+	}
+
+	if atLineEnd(l) {
+		return lexLineEnd
+	}
+
+	return lexUnexpected(l, "expected = after key")
+}
+
+/*
+lexSection lexes the name of a section header up to its closing
+bracket or brackets
+*/
+func lexSection(l *lexer.Lexer) lexer.LexFn {
+	skipBlanks(l)
+
+	if lexKeyPart(l) {
+		return lexSection
+	}
+
+	if l.Accept("]") {
+		l.Accept("]")
+		l.Emit(TOKEN_SECTION_CLOSE)
+		return lexLineEnd
+	}
+
+	return lexUnexpected(l, "unterminated section header")
+}
+
+/*
+lexValue lexes the value of a top level key/value pair. Values that
+are not strings, arrays, inline tables, or a single number, boolean,
+or date-time run to the end of the line as a bare INI value.
+*/
+func lexValue(l *lexer.Lexer) lexer.LexFn {
+	skipBlanks(l)
+
+	if atLineEnd(l) {
+		return lexLineEnd
+	}
+
+	switch l.Peek() {
+	case '"', '\'', '[', '{':
+		return lexValueToken(l, lexLineEnd)
+	}
+
+	if l.AcceptClassRun(wordChars) > 0 {
+		end := l.Pos
+		l.AcceptClassRun(blanks)
+
+		if atLineEnd(l) {
+			l.Pos = end
+			emitWord(l, true)
+			return lexLineEnd
+		}
+	}
+
+	lexBareValue(l)
+	return lexLineEnd
+}
+
+/*
+lexLineEnd expects the end of a line, with an optional comment, after
+a key/value pair or section header
+*/
+func lexLineEnd(l *lexer.Lexer) lexer.LexFn {
+	skipBlanks(l)
+
+	if l.IsEOF() {
+		return Start
+	}
+
+	switch l.Peek() {
+	case '#', ';':
+		lexComment(l)
+		return lexLineEnd
+
+	case '\r', '\n':
+		l.AcceptNewline()
+		l.Emit(TOKEN_NEWLINE)
+		return Start
+	}
+
+	return lexUnexpected(l, "unexpected text after value")
+}
+
+/*
+lexArray lexes the contents of an array, where line breaks and
+comments may appear between elements. The state to return to after the
+closing bracket is on the mode stack.
+*/
+func lexArray(l *lexer.Lexer) lexer.LexFn {
+	skipBlanks(l)
+
+	if l.IsEOF() {
+		l.Errorf("unterminated array")
+		return l.PopState()
+	}
+
+	switch l.Peek() {
+	case '\r', '\n':
+		l.AcceptNewline()
+		l.Ignore()
+		return lexArray
+
+	case '#':
+		lexComment(l)
+		return lexArray
+
+	case ',':
+		l.Next()
+		l.Emit(TOKEN_COMMA)
+		return lexArray
+
+	case ']':
+		l.Next()
+		l.Emit(TOKEN_RIGHT_BRACKET)
+		return l.PopState()
+	}
+
+	return lexValueToken(l, lexArray)
+}
+
+/*
+lexInlineKey lexes the keys of an inline table, along with the commas
+between pairs and the closing brace. Inline tables must fit on one
+line.
+*/
+func lexInlineKey(l *lexer.Lexer) lexer.LexFn {
+	skipBlanks(l)
+
+	if atLineEnd(l) {
+		l.Errorf("unterminated inline table")
+		return l.PopState()
+	}
+
+	switch l.Peek() {
+	case '}':
+		l.Next()
+		l.Emit(TOKEN_RIGHT_BRACE)
+		return l.PopState()
+
+	case ',':
+		l.Next()
+		l.Emit(TOKEN_COMMA)
+		return lexInlineKey
+
+	case '=':
+		l.Next()
+		l.Emit(TOKEN_ASSIGN)
+		return lexInlineValue
+	}
+
+	if lexKeyPart(l) {
+		return lexInlineKey
+	}
+
+	ch := l.Next()
+	l.Errorf("unexpected character %q in inline table", ch)
+	l.Ignore()
+
+	return lexInlineKey
+}
+
+/*
+lexInlineValue lexes the value of a pair in an inline table
+*/
+func lexInlineValue(l *lexer.Lexer) lexer.LexFn {
+	skipBlanks(l)
+
+	if atLineEnd(l) || l.Peek() == '}' || l.Peek() == ',' {
+		return lexInlineKey
+	}
+
+	return lexValueToken(l, lexInlineKey)
+}
+
+/*
+lexValueToken lexes a single value inside an array or inline table, or
+a string, array or inline table at the top level, then continues in
+next. Arrays and inline tables push next onto the mode stack and pop it
+when they close.
+*/
+func lexValueToken(l *lexer.Lexer, next lexer.LexFn) lexer.LexFn {
+	switch ch := l.Peek(); ch {
+	case '"':
+		lexString(l, '"', TOKEN_STRING)
+		return next
+
+	case '\'':
+		lexString(l, '\'', TOKEN_STRING)
+		return next
+
+	case '[':
+		l.Next()
+		l.Emit(TOKEN_LEFT_BRACKET)
+		l.PushState(next)
+		return lexArray
+
+	case '{':
+		l.Next()
+		l.Emit(TOKEN_LEFT_BRACE)
+		l.PushState(next)
+		return lexInlineKey
+	}
+
+	if l.AcceptClassRun(wordChars) > 0 {
+		emitWord(l, false)
+		return next
+	}
+
+	ch := l.Next()
+	l.Errorf("unexpected character %q in value", ch)
+	l.Ignore()
+
+	return next
+}
+
+/*
+lexKeyPart lexes one bare or quoted key, or the dot between the parts
+of a dotted key. It returns false if there is no key at the current
+position.
+*/
+func lexKeyPart(l *lexer.Lexer) bool {
+	switch ch := l.Peek(); {
+	case ch == '.':
+		l.Next()
+		l.Emit(TOKEN_DOT)
+
+	case ch == '"' || ch == '\'':
+		lexString(l, ch, TOKEN_KEY)
+
+	case keyChars.Contains(ch) && !blanks.Contains(ch):
+		l.AcceptClassRun(keyChars)
+
+		// INI keys may contain spaces, but not trailing ones
+		text := l.CurrentInput()
+		l.Pos -= len(text) - len(strings.TrimRight(text, " \t"))
+		l.Emit(TOKEN_KEY)
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+/*
+lexString lexes a basic or literal string, single or multi-line,
+starting at its opening quote
+*/
+func lexString(l *lexer.Lexer, quote rune, tokenType lexer.TokenType) {
+	delimiter := strings.Repeat(string(quote), 3)
+	escapes := quote == '"'
+
+	if l.PeekCharacters(3) == delimiter && tokenType == TOKEN_STRING {
+		l.Inc(3)
+
+		for {
+			if l.IsEOF() {
+				l.Errorf("unterminated multi-line string")
+				l.Ignore()
+				return
+			}
+
+			if l.PeekCharacters(3) == delimiter {
+				l.Inc(3)
+
+				// Up to two quotes just before the delimiter belong to the string
+				l.Accept(string(quote))
+				l.Accept(string(quote))
+				l.EmitWithTransform(tokenType, Unquote)
+				return
+			}
+
+			if ch := l.Next(); ch == '\\' && escapes {
+				l.Next()
+			}
+		}
+	}
+
+	l.Next()
+
+	for {
+		if atLineBreak(l) {
+			l.Errorf("unterminated string")
+			l.Ignore()
+			return
+		}
+
+		switch ch := l.Next(); {
+		case ch == quote:
+			l.EmitWithTransform(tokenType, Unquote)
+			return
+
+		case ch == '\\' && escapes && !atLineBreak(l):
+			l.Next()
+		}
+	}
+}
+
+/*
+lexBareValue lexes an INI style value running to the end of the line.
+A # or ; after whitespace starts a comment, and trailing whitespace is
+not part of the value.
+*/
+func lexBareValue(l *lexer.Lexer) {
+	end := l.Pos
+	blank := false
+
+	for !atLineBreak(l) {
+		ch := l.Peek()
+
+		if (ch == '#' || ch == ';') && blank {
+			break
+		}
+
+		l.Next()
+		blank = blanks.Contains(ch)
+
+		if !blank {
+			end = l.Pos
+		}
+	}
+
+	l.Pos = end
+	l.Emit(TOKEN_VALUE)
+}
+
+/*
+emitWord emits an unquoted word as a boolean, date-time or number. Any
+other word is a bare value when allowed, or an error.
+*/
+func emitWord(l *lexer.Lexer, allowBare bool) {
+	text := l.CurrentInput()
+
+	switch {
+	case text == "true" || text == "false":
+		l.Emit(TOKEN_BOOLEAN)
+
+	case isDateTime(text):
+		l.Emit(TOKEN_DATETIME)
+
+	case isNumber(text):
+		l.Emit(TOKEN_NUMBER)
+
+	case allowBare:
+		l.Emit(TOKEN_VALUE)
+
+	default:
+		l.Errorf("invalid value %q", text)
+		l.Ignore()
+	}
+}
+
+/*
+lexComment lexes a comment through to the end of the line, leaving the
+line break
+*/
+func lexComment(l *lexer.Lexer) {
+	acceptLine(l)
+	l.Emit(TOKEN_COMMENT)
+}
+
+/*
+lexUnexpected reports the rest of the line as an error and carries on
+at the end of the line
+*/
+func lexUnexpected(l *lexer.Lexer, message string) lexer.LexFn {
+	acceptLine(l)
+	l.Errorf("%s", message)
+	l.Ignore()
+
+	return lexLineEnd
+}
+
+func skipBlanks(l *lexer.Lexer) {
+	l.AcceptClassRun(blanks)
+	l.Ignore()
+}
+
+/*
+acceptLine consumes the rest of the line, leaving the line break.
+lineChars does not hold NUL bytes, as no class does, so they are
+consumed one at a time.
+*/
+func acceptLine(l *lexer.Lexer) {
+	for l.AcceptClassRun(lineChars) > 0 || l.AtNUL() {
+		if l.AtNUL() {
+			l.Next()
+		}
+	}
+}
+
+func atLineBreak(l *lexer.Lexer) bool {
+	if l.IsEOF() {
+		return true
+	}
+
+	ch := l.Peek()
+	return ch == '\r' || ch == '\n'
+}
+
+func atLineEnd(l *lexer.Lexer) bool {
+	if atLineBreak(l) {
+		return true
+	}
+
+	ch := l.Peek()
+	return ch == '#' || ch == ';'
+}
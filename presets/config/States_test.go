@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestNULBytes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			"\x00",
+			`ERROR "unexpected character '\\x00'"
+EOF ""
+`,
+		},
+		{
+			"a = 1\x00",
+			`KEY "a"
+ASSIGN "="
+VALUE "1\x00"
+EOF ""
+`,
+		},
+		{
+			"a = x\x00y # c\x00d\nb=1\n",
+			`KEY "a"
+ASSIGN "="
+VALUE "x\x00y"
+COMMENT "# c\x00d"
+NEWLINE "\n"
+KEY "b"
+ASSIGN "="
+NUMBER "1"
+NEWLINE "\n"
+EOF ""
+`,
+		},
+		{
+			"[s\x00]\n",
+			`SECTION_OPEN "["
+KEY "s"
+ERROR "unterminated section header"
+NEWLINE "\n"
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input)))
+
+		if got != test.want {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+		}
+	}
+}
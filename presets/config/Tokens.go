@@ -0,0 +1,72 @@
+package config
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_SECTION_OPEN lexer.TokenType = iota + 1
+	TOKEN_SECTION_CLOSE
+	TOKEN_KEY
+	TOKEN_DOT
+	TOKEN_ASSIGN
+	TOKEN_STRING
+	TOKEN_NUMBER
+	TOKEN_BOOLEAN
+	TOKEN_DATETIME
+	TOKEN_VALUE
+	TOKEN_LEFT_BRACKET
+	TOKEN_RIGHT_BRACKET
+	TOKEN_LEFT_BRACE
+	TOKEN_RIGHT_BRACE
+	TOKEN_COMMA
+	TOKEN_COMMENT
+	TOKEN_NEWLINE
+)
+
+/*
+Names holds the names of the configuration token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_SECTION_OPEN:  "SECTION_OPEN",
+	TOKEN_SECTION_CLOSE: "SECTION_CLOSE",
+	TOKEN_KEY:           "KEY",
+	TOKEN_DOT:           "DOT",
+	TOKEN_ASSIGN:        "ASSIGN",
+	TOKEN_STRING:        "STRING",
+	TOKEN_NUMBER:        "NUMBER",
+	TOKEN_BOOLEAN:       "BOOLEAN",
+	TOKEN_DATETIME:      "DATETIME",
+	TOKEN_VALUE:         "VALUE",
+	TOKEN_LEFT_BRACKET:  "LEFT_BRACKET",
+	TOKEN_RIGHT_BRACKET: "RIGHT_BRACKET",
+	TOKEN_LEFT_BRACE:    "LEFT_BRACE",
+	TOKEN_RIGHT_BRACE:   "RIGHT_BRACE",
+	TOKEN_COMMA:         "COMMA",
+	TOKEN_COMMENT:       "COMMENT",
+	TOKEN_NEWLINE:       "NEWLINE",
+}
+
+/*
+Categories maps the configuration token types to highlighting
+categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_SECTION_OPEN:  highlight.CATEGORY_PREPROCESSOR,
+	TOKEN_SECTION_CLOSE: highlight.CATEGORY_PREPROCESSOR,
+	TOKEN_KEY:           highlight.CATEGORY_IDENTIFIER,
+	TOKEN_DOT:           highlight.CATEGORY_PUNCTUATION,
+	TOKEN_ASSIGN:        highlight.CATEGORY_OPERATOR,
+	TOKEN_STRING:        highlight.CATEGORY_STRING,
+	TOKEN_NUMBER:        highlight.CATEGORY_NUMBER,
+	TOKEN_BOOLEAN:       highlight.CATEGORY_LITERAL,
+	TOKEN_DATETIME:      highlight.CATEGORY_LITERAL,
+	TOKEN_VALUE:         highlight.CATEGORY_STRING,
+	TOKEN_LEFT_BRACKET:  highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_BRACKET: highlight.CATEGORY_PUNCTUATION,
+	TOKEN_LEFT_BRACE:    highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_BRACE:   highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMA:         highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMENT:       highlight.CATEGORY_COMMENT,
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+Unquote is the TokenValueTransformer for strings and quoted keys. It
+removes the quotes and, for basic strings, resolves escapes. In
+multi-line strings a newline straight after the opening quotes is
+dropped, and a backslash at the end of a line joins it to the next
+non-blank text.
+*/
+func Unquote(text string) interface{} {
+	quote := text[:1]
+	delimiter := quote
+
+	if len(text) >= 6 && strings.HasPrefix(text, quote+quote+quote) {
+		delimiter = quote + quote + quote
+	}
+
+	text = text[len(delimiter):]
+	text = strings.TrimSuffix(text, delimiter)
+
+	if len(delimiter) == 3 {
+		if strings.HasPrefix(text, "\r\n") {
+			text = text[2:]
+		} else if strings.HasPrefix(text, "\n") {
+			text = text[1:]
+		}
+	}
+
+	if quote == "'" || strings.IndexByte(text, '\\') < 0 {
+		return text
+	}
+
+	var result strings.Builder
+	result.Grow(len(text))
+
+	for len(text) > 0 {
+		index := strings.IndexByte(text, '\\')
+
+		if index < 0 || index+1 >= len(text) {
+			result.WriteString(text)
+			break
+		}
+
+		result.WriteString(text[:index])
+		text = text[index+1:]
+
+		switch text[0] {
+		case 'b':
+			result.WriteByte('\b')
+
+		case 't':
+			result.WriteByte('\t')
+
+		case 'n':
+			result.WriteByte('\n')
+
+		case 'f':
+			result.WriteByte('\f')
+
+		case 'r':
+			result.WriteByte('\r')
+
+		case 'e':
+			result.WriteByte(0x1b)
+
+		case '"', '\\':
+			result.WriteByte(text[0])
+
+		case 'u', 'U':
+			size := 4
+			if text[0] == 'U' {
+				size = 8
+			}
+
+			if len(text) > size {
+				if value, err := strconv.ParseUint(text[1:size+1], 16, 32); err == nil && utf8.ValidRune(rune(value)) {
+					result.WriteRune(rune(value))
+					text = text[size+1:]
+					continue
+				}
+			}
+
+			result.WriteByte('\\')
+			continue
+
+		case ' ', '\t', '\r', '\n':
+			// A line ending backslash swallows the line break and any
+			// whitespace after it
+			trimmed := strings.TrimLeft(text, " \t")
+
+			if strings.HasPrefix(trimmed, "\n") || strings.HasPrefix(trimmed, "\r\n") {
+				text = strings.TrimLeft(trimmed, " \t\r\n")
+				continue
+			}
+
+			result.WriteByte('\\')
+			continue
+
+		default:
+			result.WriteByte('\\')
+			continue
+		}
+
+		text = text[1:]
+	}
+
+	return result.String()
+}
+
+/*
+isNumber reports whether text is a TOML integer or float, including
+hexadecimal, octal and binary integers, underscores between digits,
+and inf and nan
+*/
+func isNumber(text string) bool {
+	unsigned := strings.TrimLeft(text, "+-")
+
+	if len(text)-len(unsigned) > 1 || unsigned == "" {
+		return false
+	}
+
+	if unsigned == "inf" || unsigned == "nan" {
+		return true
+	}
+
+	if len(unsigned) > 2 && unsigned[0] == '0' && strings.ContainsRune("xob", rune(unsigned[1])) {
+		if len(unsigned) != len(text) {
+			return false
+		}
+
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[unsigned[1]]
+		_, err := strconv.ParseUint(strings.ReplaceAll(unsigned[2:], "_", ""), base, 64)
+
+		return err == nil && validUnderscores(unsigned[2:])
+	}
+
+	if !validUnderscores(unsigned) || (len(unsigned) > 1 && unsigned[0] == '0' && unsigned[1] >= '0' && unsigned[1] <= '9') {
+		return false
+	}
+
+	_, err := strconv.ParseFloat(strings.ReplaceAll(unsigned, "_", ""), 64)
+	return err == nil && !strings.ContainsAny(unsigned, "xXpP") && unsigned[len(unsigned)-1] != '.' && unsigned[0] != '.'
+}
+
+/*
+validUnderscores reports whether every underscore in a number sits
+between two digits
+*/
+func validUnderscores(text string) bool {
+	for index := 0; index < len(text); index++ {
+		if text[index] != '_' {
+			continue
+		}
+
+		if index == 0 || index == len(text)-1 || !isHexDigit(text[index-1]) || !isHexDigit(text[index+1]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isHexDigit(ch byte) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
+/*
+isDateTime reports whether text looks like a TOML date, time, or
+date-time: a full date, a time with seconds, or both joined by T
+*/
+func isDateTime(text string) bool {
+	date, rest, hasTime := strings.Cut(text, "T")
+
+	if !hasTime {
+		if strings.Count(text, ":") >= 2 {
+			return isTime(text)
+		}
+
+		return isDate(text)
+	}
+
+	return isDate(date) && isTime(rest)
+}
+
+func isDate(text string) bool {
+	return len(text) == 10 && digitsAt(text, 0, 4) && text[4] == '-' && digitsAt(text, 5, 2) && text[7] == '-' && digitsAt(text, 8, 2)
+}
+
+/*
+isTime accepts hh:mm:ss with an optional fraction and an optional Z or
+numeric offset
+*/
+func isTime(text string) bool {
+	if len(text) < 8 || !digitsAt(text, 0, 2) || text[2] != ':' || !digitsAt(text, 3, 2) || text[5] != ':' || !digitsAt(text, 6, 2) {
+		return false
+	}
+
+	text = text[8:]
+
+	if strings.HasPrefix(text, ".") {
+		fraction := strings.TrimLeft(text[1:], "0123456789")
+
+		if len(fraction) == len(text)-1 {
+			return false
+		}
+
+		text = fraction
+	}
+
+	switch {
+	case text == "" || text == "Z" || text == "z":
+		return true
+
+	case len(text) == 6 && (text[0] == '+' || text[0] == '-'):
+		return digitsAt(text, 1, 2) && text[3] == ':' && digitsAt(text, 4, 2)
+	}
+
+	return false
+}
+
+func digitsAt(text string, start, count int) bool {
+	for index := start; index < start+count; index++ {
+		if text[index] < '0' || text[index] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,47 @@
+/*
+Package css is a ready-made lexer for style sheets, following the
+tokenization section of the CSS Syntax Module Level 3:
+
+	@media (min-width: 40em) {
+		#nav > a.active:hover { color: rgb(0 0 0 / 50%); margin: -2px }
+		.icon::before { content: "\2713"; background: url(check.svg) }
+	}
+
+Every token of the specification is emitted, including whitespace,
+which is significant between selectors, and <!-- and -->. Comments,
+which the specification drops, are emitted as TOKEN_COMMENT so that
+tools that rewrite style sheets can keep them.
+
+Token values are decoded: identifiers, functions, at-keywords and
+hashes carry their name with escapes resolved and without the @, #, or
+(, and hashes carry a Hash noting whether the name is a valid
+identifier, as an id selector needs. Strings and URLs carry their
+content, and numbers, percentages and dimensions carry a Numeric.
+
+As in the specification, url( followed by a quoted string is a
+function token and a string token, while an unquoted URL is a single
+TOKEN_URL. Input is not preprocessed: a carriage return, form feed or
+CRLF is taken as a newline where one is allowed, and reproduced as
+written in token text.
+*/
+package css
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for a style sheet held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for a style sheet read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
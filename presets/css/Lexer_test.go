@@ -0,0 +1,133 @@
+package css
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestTokens(t *testing.T) {
+	input := "@media (min-width: 40em) {\n#nav > a:hover { color: rgb(0 / 50%) }\n.icon::before { background: url(check.svg) } /* c */ <!-- -->\n}"
+	want := `AT_KEYWORD "@media"
+WHITESPACE " "
+LEFT_PAREN "("
+IDENT "min-width"
+COLON ":"
+WHITESPACE " "
+DIMENSION "40em"
+RIGHT_PAREN ")"
+WHITESPACE " "
+LEFT_BRACE "{"
+WHITESPACE "\n"
+HASH "#nav"
+WHITESPACE " "
+DELIM ">"
+WHITESPACE " "
+IDENT "a"
+COLON ":"
+IDENT "hover"
+WHITESPACE " "
+LEFT_BRACE "{"
+WHITESPACE " "
+IDENT "color"
+COLON ":"
+WHITESPACE " "
+FUNCTION "rgb("
+NUMBER "0"
+WHITESPACE " "
+DELIM "/"
+WHITESPACE " "
+PERCENTAGE "50%"
+RIGHT_PAREN ")"
+WHITESPACE " "
+RIGHT_BRACE "}"
+WHITESPACE "\n"
+DELIM "."
+IDENT "icon"
+COLON ":"
+COLON ":"
+IDENT "before"
+WHITESPACE " "
+LEFT_BRACE "{"
+WHITESPACE " "
+IDENT "background"
+COLON ":"
+WHITESPACE " "
+URL "url(check.svg)"
+WHITESPACE " "
+RIGHT_BRACE "}"
+WHITESPACE " "
+COMMENT "/* c */"
+WHITESPACE " "
+CDO "<!--"
+WHITESPACE " "
+CDC "-->"
+WHITESPACE "\n"
+RIGHT_BRACE "}"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestValues(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{`\31 0`, "10"},
+		{"--custom", "--custom"},
+		{"@import", "import"},
+		{"#nav", Hash{Name: "nav", ID: true}},
+		{"#1a", Hash{Name: "1a", ID: false}},
+		{`"\2713"`, "✓"},
+		{"'a\\\nb'", "ab"},
+		{"url( check.svg )", "check.svg"},
+		{"-2px", Numeric{Value: -2, Integer: true, Unit: "px"}},
+		{"1.5e2%", Numeric{Value: 150, Integer: false, Unit: "%"}},
+		{".5", Numeric{Value: 0.5, Integer: false}},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if len(tokens) != 2 || tokens[0].Value != test.want {
+			t.Errorf("lexing %q: got %v, want a single token with value %#v", test.input, tokens, test.want)
+		}
+	}
+}
+
+func TestMalformedInput(t *testing.T) {
+	tests := []struct {
+		input  string
+		tokens string
+		errors []string
+	}{
+		{"\"a\nb", "BAD_STRING \"\\\"a\"\nWHITESPACE \"\\n\"\nIDENT \"b\"\nEOF \"\"\n", []string{"test:1:1: newline in string"}},
+		{`"open`, "STRING \"\\\"open\"\nEOF \"\"\n", []string{"test:1:1: unterminated string"}},
+		{"url(a b)", "BAD_URL \"url(a b)\"\nEOF \"\"\n", []string{"test:1:1: invalid url"}},
+		{"/* open", "COMMENT \"/* open\"\nEOF \"\"\n", []string{"test:1:1: unterminated comment"}},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input)
+
+		if got := lexertest.Format(Names, lexertest.Collect(t, l)); got != test.tokens {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.tokens)
+		}
+
+		diagnostics := l.Diagnostics()
+		if len(diagnostics) != len(test.errors) {
+			t.Errorf("lexing %q: got errors %v, want %q", test.input, diagnostics, test.errors)
+			continue
+		}
+
+		for index, diagnostic := range diagnostics {
+			if diagnostic.Error() != test.errors[index] {
+				t.Errorf("lexing %q: got error %q, want %q", test.input, diagnostic.Error(), test.errors[index])
+			}
+		}
+	}
+}
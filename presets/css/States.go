@@ -0,0 +1,410 @@
+package css
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+
+// noRune stands in for characters past the end of the input
+const noRune = -1
+
+var (
+	whitespace = lexer.NewCharClass(" \t\r\n\f")
+	digits     = lexer.DigitClass
+	hexDigits  = lexer.HexDigitClass
+)
+
+var punctuation = map[rune]lexer.TokenType{
+	':': TOKEN_COLON,
+	';': TOKEN_SEMICOLON,
+	',': TOKEN_COMMA,
+	'[': TOKEN_LEFT_BRACKET,
+	']': TOKEN_RIGHT_BRACKET,
+	'(': TOKEN_LEFT_PAREN,
+	')': TOKEN_RIGHT_PAREN,
+	'{': TOKEN_LEFT_BRACE,
+	'}': TOKEN_RIGHT_BRACE,
+}
+
+func init() {
+	lexer.NameState("css.start", Start)
+	lexer.NameState("css.comment", lexComment)
+	lexer.NameState("css.string", lexString)
+	lexer.NameState("css.hash", lexHash)
+	lexer.NameState("css.numeric", lexNumeric)
+	lexer.NameState("css.identLike", lexIdentLike)
+	lexer.NameState("css.url", lexURL)
+}
+
+/*
+Start is the state between tokens. It decides which state lexes the
+next token from up to four characters of lookahead, as the
+specification's "consume a token" does.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	if l.AcceptClassRun(whitespace) > 0 {
+		l.Emit(TOKEN_WHITESPACE)
+		return Start
+	}
+
+	next := lookahead(l)
+
+	switch {
+	case next[0] == '/' && next[1] == '*':
+		return lexComment
+
+	case next[0] == '"' || next[0] == '\'':
+		return lexString
+
+	case next[0] == '#' && (isIdentChar(next[1]) || isValidEscape(next[1], next[2])):
+		return lexHash
+
+	case startsNumber(next[0], next[1], next[2]):
+		return lexNumeric
+
+	case next[0] == '-' && next[1] == '-' && next[2] == '>':
+		l.Inc(3)
+		l.Emit(TOKEN_CDC)
+		return Start
+
+	case startsIdent(next[0], next[1], next[2]):
+		return lexIdentLike
+
+	case next[0] == '<' && next[1] == '!' && next[2] == '-' && next[3] == '-':
+		l.Inc(4)
+		l.Emit(TOKEN_CDO)
+		return Start
+
+	case next[0] == '@' && startsIdent(next[1], next[2], next[3]):
+		l.Next()
+		acceptIdentSequence(l)
+		l.EmitWithTransform(TOKEN_AT_KEYWORD, atKeyword)
+		return Start
+	}
+
+	ch := l.Next()
+
+	if tokenType, ok := punctuation[ch]; ok {
+		l.Emit(tokenType)
+		return Start
+	}
+
+	if ch == '\\' {
+		reportAt(l, l.Start, "invalid escape")
+	}
+
+	l.Emit(TOKEN_DELIM)
+	return Start
+}
+
+/*
+lexComment lexes a comment. An unterminated comment runs to the end of
+the input.
+*/
+func lexComment(l *lexer.Lexer) lexer.LexFn {
+	l.Inc(2)
+
+	for !l.IsEOF() && l.PeekCharacters(2) != "*/" {
+		l.Next()
+	}
+
+	if l.IsEOF() {
+		reportAt(l, l.Start, "unterminated comment")
+	} else {
+		l.Inc(2)
+	}
+
+	l.Emit(TOKEN_COMMENT)
+	return Start
+}
+
+/*
+lexString lexes a quoted string. A string may not contain an unescaped
+newline; one that does is a bad string ending before the newline.
+*/
+func lexString(l *lexer.Lexer) lexer.LexFn {
+	quote := l.Next()
+
+	for {
+		if l.IsEOF() {
+			reportAt(l, l.Start, "unterminated string")
+			l.EmitWithTransform(TOKEN_STRING, Unquote)
+			return Start
+		}
+
+		next := lookahead(l)
+
+		switch {
+		case next[0] == quote:
+			l.Next()
+			l.EmitWithTransform(TOKEN_STRING, Unquote)
+			return Start
+
+		case isNewline(next[0]):
+			reportAt(l, l.Start, "newline in string")
+			l.Emit(TOKEN_BAD_STRING)
+			return Start
+
+		case next[0] == '\\' && (next[1] == noRune || isNewline(next[1])):
+			// An escaped newline continues the string on the next line. A
+			// form feed is a newline in CSS as well.
+			l.Next()
+			if !l.AcceptNewline() {
+				l.Accept("\f")
+			}
+
+		case next[0] == '\\':
+			acceptEscape(l)
+
+		default:
+			l.Next()
+		}
+	}
+}
+
+/*
+lexHash lexes a # followed by a name
+*/
+func lexHash(l *lexer.Lexer) lexer.LexFn {
+	l.Next()
+	acceptIdentSequence(l)
+	l.EmitWithTransform(TOKEN_HASH, hash)
+
+	return Start
+}
+
+/*
+lexNumeric lexes a number, and the % or unit after it that makes it a
+percentage or a dimension
+*/
+func lexNumeric(l *lexer.Lexer) lexer.LexFn {
+	acceptNumber(l)
+
+	next := lookahead(l)
+
+	switch {
+	case startsIdent(next[0], next[1], next[2]):
+		acceptIdentSequence(l)
+		l.EmitWithTransform(TOKEN_DIMENSION, numeric)
+
+	case l.Accept("%"):
+		l.EmitWithTransform(TOKEN_PERCENTAGE, numeric)
+
+	default:
+		l.EmitWithTransform(TOKEN_NUMBER, numeric)
+	}
+
+	return Start
+}
+
+/*
+lexIdentLike lexes an identifier, a function name with its parenthesis,
+or an unquoted URL
+*/
+func lexIdentLike(l *lexer.Lexer) lexer.LexFn {
+	acceptIdentSequence(l)
+
+	if l.Peek() != '(' {
+		l.EmitWithTransform(TOKEN_IDENT, ident)
+		return Start
+	}
+
+	isURL := strings.EqualFold(unescape(l.CurrentInput()), "url")
+	l.Next()
+
+	if isURL {
+		// A quoted URL is a function and a string, with any whitespace
+		// before the string left to be its own token
+		mark := l.Mark()
+		l.AcceptClassRun(whitespace)
+		quoted := l.Peek() == '"' || l.Peek() == '\''
+		l.Rewind(mark)
+
+		if !quoted {
+			return lexURL
+		}
+	}
+
+	l.EmitWithTransform(TOKEN_FUNCTION, function)
+	return Start
+}
+
+/*
+lexURL lexes the rest of an unquoted URL after url(. Quotes, open
+parentheses, control characters and whitespace within the URL make it a
+bad URL, which runs to the next close parenthesis.
+*/
+func lexURL(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(whitespace)
+
+	for {
+		if l.IsEOF() {
+			reportAt(l, l.Start, "unterminated url")
+			l.EmitWithTransform(TOKEN_URL, url)
+			return Start
+		}
+
+		next := lookahead(l)
+
+		switch ch := next[0]; {
+		case ch == ')':
+			l.Next()
+			l.EmitWithTransform(TOKEN_URL, url)
+			return Start
+
+		case whitespace.Contains(ch):
+			l.AcceptClassRun(whitespace)
+
+			if !l.IsEOF() && l.Peek() != ')' {
+				return lexBadURL(l)
+			}
+
+		case ch == '"' || ch == '\'' || ch == '(' || isNonPrintable(ch):
+			return lexBadURL(l)
+
+		case ch == '\\':
+			if !isValidEscape(next[0], next[1]) {
+				return lexBadURL(l)
+			}
+
+			acceptEscape(l)
+
+		default:
+			l.Next()
+		}
+	}
+}
+
+/*
+lexBadURL consumes the remnants of a bad URL, up to and including the
+next close parenthesis that is not escaped
+*/
+func lexBadURL(l *lexer.Lexer) lexer.LexFn {
+	for !l.IsEOF() {
+		next := lookahead(l)
+
+		if isValidEscape(next[0], next[1]) {
+			acceptEscape(l)
+			continue
+		}
+
+		if l.Next() == ')' {
+			break
+		}
+	}
+
+	reportAt(l, l.Start, "invalid url")
+	l.Emit(TOKEN_BAD_URL)
+
+	return Start
+}
+
+/*
+acceptIdentSequence consumes identifier characters and escapes
+*/
+func acceptIdentSequence(l *lexer.Lexer) {
+	for {
+		next := lookahead(l)
+
+		switch {
+		case isIdentChar(next[0]):
+			l.Next()
+
+		case isValidEscape(next[0], next[1]):
+			acceptEscape(l)
+
+		default:
+			return
+		}
+	}
+}
+
+/*
+acceptEscape consumes a backslash and the escape after it: up to six
+hex digits and a single whitespace character, or any other character
+*/
+func acceptEscape(l *lexer.Lexer) {
+	l.Next()
+
+	if l.IsEOF() {
+		return
+	}
+
+	count := 0
+	for count < 6 && l.AcceptClass(hexDigits) {
+		count++
+	}
+
+	if count == 0 {
+		l.Next()
+		return
+	}
+
+	if !l.AcceptNewline() {
+		l.Accept(" \t\f")
+	}
+}
+
+/*
+acceptNumber consumes a number with an optional sign, fraction and
+exponent
+*/
+func acceptNumber(l *lexer.Lexer) {
+	l.Accept("+-")
+	l.AcceptClassRun(digits)
+
+	if next := lookahead(l); next[0] == '.' && isDigit(next[1]) {
+		l.Next()
+		l.AcceptClassRun(digits)
+	}
+
+	if next := lookahead(l); (next[0] == 'e' || next[0] == 'E') && (isDigit(next[1]) || (next[1] == '+' || next[1] == '-') && isDigit(next[2])) {
+		l.Next()
+		l.Accept("+-")
+		l.AcceptClassRun(digits)
+	}
+}
+
+/*
+lookahead returns the next four characters without consuming them, with
+noRune for those past the end of the input
+*/
+func lookahead(l *lexer.Lexer) [4]rune {
+	var result [4]rune
+	text := l.PeekCharacters(len(result) * utf8.UTFMax)
+
+	for index := range result {
+		if text == "" {
+			result[index] = noRune
+			continue
+		}
+
+		ch, width := utf8.DecodeRuneInString(text)
+		result[index] = ch
+		text = text[width:]
+	}
+
+	return result
+}
+
+/*
+reportAt reports a problem within the token being lexed, which is still
+emitted
+*/
+func reportAt(l *lexer.Lexer, start int, format string, args ...interface{}) {
+	l.Report(lexer.LexError{
+		Message: fmt.Sprintf(format, args...),
+		Span: lexer.Span{
+			Start: l.PositionAt(start),
+			End:   l.PositionAt(l.Pos),
+		},
+	})
+}
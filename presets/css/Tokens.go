@@ -0,0 +1,95 @@
+package css
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_IDENT lexer.TokenType = iota + 1
+	TOKEN_FUNCTION
+	TOKEN_AT_KEYWORD
+	TOKEN_HASH
+	TOKEN_STRING
+	TOKEN_BAD_STRING
+	TOKEN_URL
+	TOKEN_BAD_URL
+	TOKEN_DELIM
+	TOKEN_NUMBER
+	TOKEN_PERCENTAGE
+	TOKEN_DIMENSION
+	TOKEN_WHITESPACE
+	TOKEN_CDO
+	TOKEN_CDC
+	TOKEN_COLON
+	TOKEN_SEMICOLON
+	TOKEN_COMMA
+	TOKEN_LEFT_BRACKET
+	TOKEN_RIGHT_BRACKET
+	TOKEN_LEFT_PAREN
+	TOKEN_RIGHT_PAREN
+	TOKEN_LEFT_BRACE
+	TOKEN_RIGHT_BRACE
+	TOKEN_COMMENT
+)
+
+/*
+Names holds the names of the CSS token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_IDENT:         "IDENT",
+	TOKEN_FUNCTION:      "FUNCTION",
+	TOKEN_AT_KEYWORD:    "AT_KEYWORD",
+	TOKEN_HASH:          "HASH",
+	TOKEN_STRING:        "STRING",
+	TOKEN_BAD_STRING:    "BAD_STRING",
+	TOKEN_URL:           "URL",
+	TOKEN_BAD_URL:       "BAD_URL",
+	TOKEN_DELIM:         "DELIM",
+	TOKEN_NUMBER:        "NUMBER",
+	TOKEN_PERCENTAGE:    "PERCENTAGE",
+	TOKEN_DIMENSION:     "DIMENSION",
+	TOKEN_WHITESPACE:    "WHITESPACE",
+	TOKEN_CDO:           "CDO",
+	TOKEN_CDC:           "CDC",
+	TOKEN_COLON:         "COLON",
+	TOKEN_SEMICOLON:     "SEMICOLON",
+	TOKEN_COMMA:         "COMMA",
+	TOKEN_LEFT_BRACKET:  "LEFT_BRACKET",
+	TOKEN_RIGHT_BRACKET: "RIGHT_BRACKET",
+	TOKEN_LEFT_PAREN:    "LEFT_PAREN",
+	TOKEN_RIGHT_PAREN:   "RIGHT_PAREN",
+	TOKEN_LEFT_BRACE:    "LEFT_BRACE",
+	TOKEN_RIGHT_BRACE:   "RIGHT_BRACE",
+	TOKEN_COMMENT:       "COMMENT",
+}
+
+/*
+Categories maps the CSS token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_IDENT:         highlight.CATEGORY_IDENTIFIER,
+	TOKEN_FUNCTION:      highlight.CATEGORY_FUNCTION,
+	TOKEN_AT_KEYWORD:    highlight.CATEGORY_KEYWORD,
+	TOKEN_HASH:          highlight.CATEGORY_LITERAL,
+	TOKEN_STRING:        highlight.CATEGORY_STRING,
+	TOKEN_BAD_STRING:    highlight.CATEGORY_ERROR,
+	TOKEN_URL:           highlight.CATEGORY_STRING,
+	TOKEN_BAD_URL:       highlight.CATEGORY_ERROR,
+	TOKEN_DELIM:         highlight.CATEGORY_OPERATOR,
+	TOKEN_NUMBER:        highlight.CATEGORY_NUMBER,
+	TOKEN_PERCENTAGE:    highlight.CATEGORY_NUMBER,
+	TOKEN_DIMENSION:     highlight.CATEGORY_NUMBER,
+	TOKEN_CDO:           highlight.CATEGORY_COMMENT,
+	TOKEN_CDC:           highlight.CATEGORY_COMMENT,
+	TOKEN_COLON:         highlight.CATEGORY_PUNCTUATION,
+	TOKEN_SEMICOLON:     highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMA:         highlight.CATEGORY_PUNCTUATION,
+	TOKEN_LEFT_BRACKET:  highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_BRACKET: highlight.CATEGORY_PUNCTUATION,
+	TOKEN_LEFT_PAREN:    highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_PAREN:   highlight.CATEGORY_PUNCTUATION,
+	TOKEN_LEFT_BRACE:    highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_BRACE:   highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMENT:       highlight.CATEGORY_COMMENT,
+}
@@ -0,0 +1,271 @@
+package css
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+Hash is the value of a hash token. ID is true if the name would be a
+valid identifier, which the name of an id selector must be.
+*/
+type Hash struct {
+	Name string
+	ID   bool
+}
+
+/*
+Numeric is the value of number, percentage and dimension tokens.
+Integer is true if the number was written without a fraction or
+exponent. Unit is empty for numbers, % for percentages, and the unit of
+a dimension with its escapes resolved.
+*/
+type Numeric struct {
+	Value   float64
+	Integer bool
+	Unit    string
+}
+
+func ident(text string) interface{} {
+	return unescape(text)
+}
+
+func function(text string) interface{} {
+	return unescape(text[:len(text)-1])
+}
+
+func atKeyword(text string) interface{} {
+	return unescape(text[1:])
+}
+
+func hash(text string) interface{} {
+	var next [3]rune
+
+	rest := text[1:]
+	for index := range next {
+		next[index] = noRune
+
+		if rest != "" {
+			ch, width := utf8.DecodeRuneInString(rest)
+			next[index] = ch
+			rest = rest[width:]
+		}
+	}
+
+	return Hash{Name: unescape(text[1:]), ID: startsIdent(next[0], next[1], next[2])}
+}
+
+func numeric(text string) interface{} {
+	length := numberLength(text)
+	value, _ := strconv.ParseFloat(text[:length], 64)
+
+	return Numeric{
+		Value:   value,
+		Integer: !strings.ContainsAny(text[:length], ".eE"),
+		Unit:    unescape(text[length:]),
+	}
+}
+
+/*
+numberLength returns the length of the number at the start of text, as
+consumed by acceptNumber
+*/
+func numberLength(text string) int {
+	index := 0
+
+	if index < len(text) && (text[index] == '+' || text[index] == '-') {
+		index++
+	}
+
+	index = skipDigits(text, index)
+
+	if index+1 < len(text) && text[index] == '.' && isDigit(rune(text[index+1])) {
+		index = skipDigits(text, index+1)
+	}
+
+	if index < len(text) && (text[index] == 'e' || text[index] == 'E') {
+		exponent := index + 1
+
+		if exponent < len(text) && (text[exponent] == '+' || text[exponent] == '-') {
+			exponent++
+		}
+
+		if exponent < len(text) && isDigit(rune(text[exponent])) {
+			index = skipDigits(text, exponent)
+		}
+	}
+
+	return index
+}
+
+func skipDigits(text string, index int) int {
+	for index < len(text) && isDigit(rune(text[index])) {
+		index++
+	}
+
+	return index
+}
+
+/*
+Unquote is the TokenValueTransformer for strings. It removes the quotes
+and resolves escapes, and copes with the missing closing quote of an
+unterminated or bad string.
+*/
+func Unquote(text string) interface{} {
+	quote := text[0]
+	body := text[1:]
+
+	end := 0
+	for end < len(body) && body[end] != quote {
+		if body[end] == '\\' {
+			end++
+		}
+
+		end++
+	}
+
+	if end > len(body) {
+		end = len(body)
+	}
+
+	return unescape(body[:end])
+}
+
+/*
+url is the TokenValueTransformer for URLs. It returns the URL between
+the parentheses with surrounding whitespace removed and escapes
+resolved.
+*/
+func url(text string) interface{} {
+	body := text[strings.IndexByte(text, '(')+1:]
+
+	if strings.HasSuffix(body, ")") && !strings.HasSuffix(body, `\)`) {
+		body = body[:len(body)-1]
+	}
+
+	return unescape(strings.Trim(body, " \t\r\n\f"))
+}
+
+/*
+unescape resolves the escapes in an identifier or string. A hex escape
+of zero, a surrogate, or a code point beyond Unicode gives the
+replacement character, and an escaped newline is removed.
+*/
+func unescape(text string) string {
+	if strings.IndexByte(text, '\\') < 0 {
+		return text
+	}
+
+	var result strings.Builder
+	result.Grow(len(text))
+
+	for index := 0; index < len(text); index++ {
+		if text[index] != '\\' {
+			result.WriteByte(text[index])
+			continue
+		}
+
+		index++
+
+		if index == len(text) {
+			break
+		}
+
+		if text[index] == '\r' && index+1 < len(text) && text[index+1] == '\n' {
+			index++
+		}
+
+		if isNewline(rune(text[index])) {
+			continue
+		}
+
+		end := index
+		for end < len(text) && end < index+6 && isHexDigit(text[end]) {
+			end++
+		}
+
+		if end == index {
+			ch, width := utf8.DecodeRuneInString(text[index:])
+			result.WriteRune(ch)
+			index += width - 1
+			continue
+		}
+
+		code, _ := strconv.ParseUint(text[index:end], 16, 32)
+
+		if code == 0 || code > utf8.MaxRune || code >= 0xd800 && code <= 0xdfff {
+			code = utf8.RuneError
+		}
+
+		result.WriteRune(rune(code))
+
+		// A single whitespace character after the digits ends the escape
+		switch {
+		case strings.HasPrefix(text[end:], "\r\n"):
+			end += 2
+
+		case end < len(text) && strings.IndexByte(" \t\r\n\f", text[end]) >= 0:
+			end++
+		}
+
+		index = end - 1
+	}
+
+	return result.String()
+}
+
+func isDigit(ch rune) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isHexDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'f' || ch >= 'A' && ch <= 'F'
+}
+
+func isNewline(ch rune) bool {
+	return ch == '\n' || ch == '\r' || ch == '\f'
+}
+
+func isNonPrintable(ch rune) bool {
+	return ch >= 0 && ch <= 0x08 || ch == 0x0b || ch >= 0x0e && ch <= 0x1f || ch == 0x7f
+}
+
+func isIdentStart(ch rune) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_' || ch >= utf8.RuneSelf
+}
+
+func isIdentChar(ch rune) bool {
+	return isIdentStart(ch) || isDigit(ch) || ch == '-'
+}
+
+func isValidEscape(first, second rune) bool {
+	return first == '\\' && second != noRune && !isNewline(second)
+}
+
+/*
+startsIdent returns true if the three characters would start an
+identifier
+*/
+func startsIdent(first, second, third rune) bool {
+	if first == '-' {
+		return isIdentStart(second) || second == '-' || isValidEscape(second, third)
+	}
+
+	return isIdentStart(first) || isValidEscape(first, second)
+}
+
+/*
+startsNumber returns true if the three characters would start a number
+*/
+func startsNumber(first, second, third rune) bool {
+	switch first {
+	case '+', '-':
+		return isDigit(second) || second == '.' && isDigit(third)
+
+	case '.':
+		return isDigit(second)
+	}
+
+	return isDigit(first)
+}
@@ -0,0 +1,48 @@
+package csv
+
+/*
+An EscapeStyle describes how a quote character is written inside a
+quoted field
+*/
+type EscapeStyle int
+
+const (
+	// ESCAPE_DOUBLED writes a quote as two quotes, as in RFC 4180
+	ESCAPE_DOUBLED EscapeStyle = iota
+
+	// ESCAPE_BACKSLASH writes a quote, or a backslash, after a backslash
+	ESCAPE_BACKSLASH
+)
+
+/*
+Dialect describes a flavor of delimiter separated values. A zero
+Delimiter or Quote uses the RFC 4180 comma or double quote. A zero
+Comment disables comments; otherwise records starting with it are
+skipped.
+*/
+type Dialect struct {
+	Delimiter rune
+	Quote     rune
+	Escape    EscapeStyle
+	Comment   rune
+}
+
+var (
+	// DefaultDialect is CSV as described by RFC 4180
+	DefaultDialect = Dialect{Delimiter: ',', Quote: '"'}
+
+	// TabDialect is tab separated values with RFC 4180 quoting
+	TabDialect = Dialect{Delimiter: '\t', Quote: '"'}
+)
+
+func (dialect Dialect) withDefaults() Dialect {
+	if dialect.Delimiter == 0 {
+		dialect.Delimiter = DefaultDialect.Delimiter
+	}
+
+	if dialect.Quote == 0 {
+		dialect.Quote = DefaultDialect.Quote
+	}
+
+	return dialect
+}
@@ -0,0 +1,36 @@
+/*
+Package csv is a ready-made lexer for delimiter separated values. The
+delimiter, quote character, escape style and comment character are set
+by a Dialect, so the same lexer handles RFC 4180 CSV, TSV, and the many
+variations found in exports from other tools. Quoted fields may span
+lines.
+
+Each field becomes a TOKEN_FIELD token and each line break ending a
+record a TOKEN_RECORD_END token. Quoted fields carry their unquoted
+contents in Value, so Token.ValueOrText gives the contents of any
+field. Unlike encoding/csv the input is lexed as a stream of tokens, so
+records of any length can be processed without holding them in memory.
+
+	l := csv.NewLexer("export.csv", input, csv.DefaultDialect)
+*/
+package csv
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for delimited values held in memory
+*/
+func NewLexer(name string, input string, dialect Dialect, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, dialect.Start(), options...)
+}
+
+/*
+NewReaderLexer creates a lexer for delimited values read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, dialect Dialect, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, dialect.Start(), options...)
+}
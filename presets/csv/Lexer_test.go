@@ -0,0 +1,116 @@
+package csv
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestDialects(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		input   string
+		want    string
+	}{
+		{
+			DefaultDialect,
+			"a,\"b,c\"\r\n,\"x\ny\"\n",
+			`FIELD "a"
+FIELD "\"b,c\""
+RECORD_END "\r\n"
+FIELD ""
+FIELD "\"x\ny\""
+RECORD_END "\n"
+EOF ""
+`,
+		},
+		{
+			DefaultDialect,
+			"a,b",
+			`FIELD "a"
+FIELD "b"
+RECORD_END ""
+EOF ""
+`,
+		},
+		{
+			TabDialect,
+			"a\tb,c\n",
+			`FIELD "a"
+FIELD "b,c"
+RECORD_END "\n"
+EOF ""
+`,
+		},
+		{
+			Dialect{Delimiter: ';', Quote: '\'', Escape: ESCAPE_BACKSLASH, Comment: '#'},
+			"# skipped\na;'b\\';c'\n",
+			`FIELD "a"
+FIELD "'b\\';c'"
+RECORD_END "\n"
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input, test.dialect)))
+
+		if got != test.want {
+			t.Errorf("lexing %q with %+v:\ngot:\n%s\nwant:\n%s", test.input, test.dialect, got, test.want)
+		}
+	}
+}
+
+func TestQuotedFieldValues(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		input   string
+		want    string
+	}{
+		{DefaultDialect, `"b,c"`, "b,c"},
+		{DefaultDialect, `"say ""hi"""`, `say "hi"`},
+		{DefaultDialect, "\"x\r\ny\"", "x\r\ny"},
+		{Dialect{Escape: ESCAPE_BACKSLASH}, `"a\"b\\c"`, `a"b\c`},
+		{Dialect{Quote: '\''}, `'it''s'`, "it's"},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input, test.dialect))
+
+		if tokens[0].Type != TOKEN_FIELD || tokens[0].Value != test.want {
+			t.Errorf("lexing %s: got %s with value %q, want FIELD with value %q", test.input, Names.Name(tokens[0].Type), tokens[0].Value, test.want)
+		}
+	}
+}
+
+func TestMalformedFields(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			`"open`,
+			`ERROR "unterminated quoted field"
+EOF ""
+`,
+		},
+		{
+			`"a"b,c`,
+			`FIELD "\"a\""
+ERROR "unexpected text after quoted field"
+FIELD "c"
+RECORD_END ""
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input, DefaultDialect)))
+
+		if got != test.want {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+		}
+	}
+}
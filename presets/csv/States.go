@@ -0,0 +1,173 @@
+package csv
+
+import (
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+scanner holds the state functions for one dialect. The states are
+methods so that they can read the dialect's settings.
+*/
+type scanner struct {
+	dialect   Dialect
+	unquoted  *lexer.CharClass
+	unquoteFn lexer.TokenValueTransformer
+}
+
+/*
+Start returns the initial state function for lexing this dialect
+*/
+func (dialect Dialect) Start() lexer.LexFn {
+	dialect = dialect.withDefaults()
+
+	s := &scanner{
+		dialect:  dialect,
+		unquoted: lexer.NewCharClass(string(dialect.Delimiter) + "\r\n").Not(),
+	}
+
+	s.unquoteFn = s.unquote
+	return s.lexRecord
+}
+
+/*
+lexRecord starts a record, skipping blank lines and comments
+*/
+func (s *scanner) lexRecord(l *lexer.Lexer) lexer.LexFn {
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	if l.AcceptNewline() {
+		l.Ignore()
+		return s.lexRecord
+	}
+
+	if s.dialect.Comment != 0 && l.Peek() == s.dialect.Comment {
+		return lexer.RecoverToNewline(s.lexRecord)
+	}
+
+	return s.lexField
+}
+
+/*
+lexField lexes a single field, which may be empty. NUL bytes, which no
+class holds, as Peek reads them as lexer.EOF, are reported and kept in
+the field so the record keeps its fields.
+*/
+func (s *scanner) lexField(l *lexer.Lexer) lexer.LexFn {
+	if l.Peek() == s.dialect.Quote {
+		return s.lexQuoted
+	}
+
+	for l.AcceptClassRun(s.unquoted) > 0 || l.AtNUL() {
+		if l.AtNUL() {
+			l.Next()
+			l.Errorf("unexpected character %q", rune(0))
+		}
+	}
+
+	l.Emit(TOKEN_FIELD)
+
+	return s.lexAfterField
+}
+
+/*
+lexQuoted lexes a quoted field, which may contain delimiters, line
+breaks and escaped quotes
+*/
+func (s *scanner) lexQuoted(l *lexer.Lexer) lexer.LexFn {
+	l.Next()
+
+	for {
+		if l.IsEOF() {
+			l.Errorf("unterminated quoted field")
+			l.Ignore()
+			return s.lexRecord
+		}
+
+		ch := l.Next()
+
+		switch {
+		case ch == s.dialect.Quote && s.dialect.Escape == ESCAPE_DOUBLED && l.Peek() == s.dialect.Quote:
+			l.Next()
+
+		case ch == s.dialect.Quote:
+			l.EmitWithTransform(TOKEN_FIELD, s.unquoteFn)
+			return s.lexAfterField
+
+		case ch == '\\' && s.dialect.Escape == ESCAPE_BACKSLASH && !l.IsEOF():
+			l.Next()
+		}
+	}
+}
+
+/*
+lexAfterField decides what follows a field: another field after a
+delimiter, or the end of the record
+*/
+func (s *scanner) lexAfterField(l *lexer.Lexer) lexer.LexFn {
+	if l.IsEOF() {
+		l.Emit(TOKEN_RECORD_END)
+		return s.lexRecord
+	}
+
+	if l.Peek() == s.dialect.Delimiter {
+		l.Next()
+		l.Ignore()
+		return s.lexField
+	}
+
+	if l.AcceptNewline() {
+		l.Emit(TOKEN_RECORD_END)
+		return s.lexRecord
+	}
+
+	// Only text after a closing quote can get here, as in "a"b
+	for l.AcceptClassRun(s.unquoted) > 0 || l.AtNUL() {
+		if l.AtNUL() {
+			l.Next()
+		}
+	}
+
+	l.Errorf("unexpected text after quoted field")
+	l.Ignore()
+
+	return s.lexAfterField
+}
+
+/*
+unquote is the TokenValueTransformer for quoted fields. It removes the
+surrounding quotes and resolves escapes.
+*/
+func (s *scanner) unquote(text string) interface{} {
+	quote := string(s.dialect.Quote)
+	text = strings.TrimSuffix(strings.TrimPrefix(text, quote), quote)
+
+	if s.dialect.Escape == ESCAPE_DOUBLED {
+		if !strings.Contains(text, quote+quote) {
+			return text
+		}
+
+		return strings.ReplaceAll(text, quote+quote, quote)
+	}
+
+	if strings.IndexByte(text, '\\') < 0 {
+		return text
+	}
+
+	var result strings.Builder
+	result.Grow(len(text))
+
+	for index := 0; index < len(text); index++ {
+		if text[index] == '\\' && index+1 < len(text) {
+			index++
+		}
+
+		result.WriteByte(text[index])
+	}
+
+	return result.String()
+}
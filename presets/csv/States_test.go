@@ -0,0 +1,57 @@
+package csv
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestNULBytes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			"\x00",
+			`ERROR "unexpected character '\\x00'"
+FIELD "\x00"
+RECORD_END ""
+EOF ""
+`,
+		},
+		{
+			"a\x00b,c\n",
+			`ERROR "unexpected character '\\x00'"
+FIELD "a\x00b"
+FIELD "c"
+RECORD_END "\n"
+EOF ""
+`,
+		},
+		{
+			"\"a\"\x00,b",
+			`FIELD "\"a\""
+ERROR "unexpected text after quoted field"
+FIELD "b"
+RECORD_END ""
+EOF ""
+`,
+		},
+		{
+			"\"a\x00\",b",
+			`FIELD "\"a\x00\""
+FIELD "b"
+RECORD_END ""
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input, DefaultDialect)))
+
+		if got != test.want {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+		}
+	}
+}
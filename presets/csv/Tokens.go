@@ -0,0 +1,26 @@
+package csv
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_FIELD lexer.TokenType = iota + 1
+	TOKEN_RECORD_END
+)
+
+/*
+Names holds the names of the CSV token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_FIELD:      "FIELD",
+	TOKEN_RECORD_END: "RECORD_END",
+}
+
+/*
+Categories maps the CSV token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_FIELD: highlight.CATEGORY_STRING,
+}
@@ -0,0 +1,54 @@
+/*
+Package dockerfile is a ready-made lexer for Dockerfiles, for linters
+and other tools that inspect container builds:
+
+	# syntax=docker/dockerfile:1
+	FROM golang:1.22 AS build
+	COPY --chown=app:app . /src
+	RUN go build -o /bin/app ./cmd/app && \
+	    strip /bin/app
+	ENTRYPOINT ["/bin/app", "--serve"]
+
+Each instruction starts with a TOKEN_INSTRUCTION whose Value is its
+name in upper case, and ends with a TOKEN_NEWLINE, or at the end of the
+input. Flags such as --chown=app:app are TOKEN_FLAG with a Flag value.
+
+The arguments of RUN, CMD and ENTRYPOINT in shell form are a single
+TOKEN_SHELL_COMMAND, whose Value is the command with its line
+continuations joined and the comment lines among them dropped, ready
+to hand to a shell lexer. Arguments in exec form, a JSON array of
+strings, are lexed as brackets, commas and strings for every
+instruction, with the strings decoded in Value. The arguments of other
+instructions are words, TOKEN_ARGUMENT, with quotes removed in Value.
+
+ONBUILD is followed by the instruction it defers, and HEALTHCHECK by
+its flags and a CMD instruction or NONE. Parser directives at the top of
+the file are TOKEN_DIRECTIVE, and an escape directive changes the
+escape character used for the rest of the file, as in Docker. Line
+continuations outside shell commands are TOKEN_CONTINUATION. Here
+documents are not recognized.
+
+The escape character is tracked per run, so the states of this lexer
+are not registered for checkpoints.
+*/
+package dockerfile
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for a Dockerfile held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for a Dockerfile read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
@@ -0,0 +1,107 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestInstructions(t *testing.T) {
+	input := `# syntax=docker/dockerfile:1
+FROM golang:1.22 AS build
+COPY --chown=app:app . /src
+RUN go build \
+# note
+    && strip /bin/app
+ENTRYPOINT ["/bin/app", "--serve"]
+ONBUILD RUN make
+HEALTHCHECK --interval=5s CMD curl -f x
+`
+	want := `DIRECTIVE "# syntax=docker/dockerfile:1"
+INSTRUCTION "FROM"
+ARGUMENT "golang:1.22"
+ARGUMENT "AS"
+ARGUMENT "build"
+NEWLINE "\n"
+INSTRUCTION "COPY"
+FLAG "--chown=app:app"
+ARGUMENT "."
+ARGUMENT "/src"
+NEWLINE "\n"
+INSTRUCTION "RUN"
+SHELL_COMMAND "go build \\\n# note\n    && strip /bin/app"
+NEWLINE "\n"
+INSTRUCTION "ENTRYPOINT"
+LEFT_BRACKET "["
+STRING "\"/bin/app\""
+COMMA ","
+STRING "\"--serve\""
+RIGHT_BRACKET "]"
+NEWLINE "\n"
+INSTRUCTION "ONBUILD"
+INSTRUCTION "RUN"
+SHELL_COMMAND "make"
+NEWLINE "\n"
+INSTRUCTION "HEALTHCHECK"
+FLAG "--interval=5s"
+INSTRUCTION "CMD"
+SHELL_COMMAND "curl -f x"
+NEWLINE "\n"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestValues(t *testing.T) {
+	tests := []struct {
+		input string
+		index int
+		want  interface{}
+	}{
+		{"# syntax=docker/dockerfile:1\n", 0, Directive{Name: "syntax", Value: "docker/dockerfile:1"}},
+		{"from scratch", 0, "FROM"},
+		{"COPY --chown=app:app a b", 1, Flag{Name: "chown", Value: "app:app"}},
+		{`ENV NAME="a b"`, 1, "NAME=a b"},
+		{"RUN a \\\n# note\n  b", 1, "a   b"},
+		{`CMD ["\u00e9"]`, 2, "é"},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if test.index >= len(tokens) || tokens[test.index].Value != test.want {
+			t.Errorf("lexing %q: got %v, want token %d with value %#v", test.input, tokens, test.index, test.want)
+		}
+	}
+}
+
+func TestEscapeDirective(t *testing.T) {
+	input := "# escape=`\nCOPY a `\n  b"
+	want := "DIRECTIVE \"# escape=`\"\nINSTRUCTION \"COPY\"\nARGUMENT \"a\"\nCONTINUATION \"`\\n\"\nARGUMENT \"b\"\nEOF \"\"\n"
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestMalformedInstructions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"= x", "test:1:1: expected an instruction"},
+		{"COPY a\nb", "test:2:1: unknown instruction B"},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input)
+		lexertest.Collect(t, l)
+
+		if diagnostics := l.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Error() != test.want {
+			t.Errorf("lexing %q: got errors %v, want %q", test.input, diagnostics, test.want)
+		}
+	}
+}
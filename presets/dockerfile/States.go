@@ -0,0 +1,545 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+const blanks = " \t"
+
+var letters = lexer.NewCharClass("").AddRange('a', 'z').AddRange('A', 'Z')
+
+var instructions = map[string]bool{
+	"ADD":         true,
+	"ARG":         true,
+	"CMD":         true,
+	"COPY":        true,
+	"ENTRYPOINT":  true,
+	"ENV":         true,
+	"EXPOSE":      true,
+	"FROM":        true,
+	"HEALTHCHECK": true,
+	"LABEL":       true,
+	"MAINTAINER":  true,
+	"ONBUILD":     true,
+	"RUN":         true,
+	"SHELL":       true,
+	"STOPSIGNAL":  true,
+	"USER":        true,
+	"VOLUME":      true,
+	"WORKDIR":     true,
+}
+
+var directives = map[string]bool{
+	"check":  true,
+	"escape": true,
+	"syntax": true,
+}
+
+/*
+scanner holds the state of one run: the escape character, which an
+escape directive may change, whether directives may still appear, and
+the instruction being lexed
+*/
+type scanner struct {
+	escape      rune
+	directives  bool
+	instruction string
+}
+
+/*
+Start is the state at the beginning of a Dockerfile. It sets up the
+state for a new run, so one lexer can be reused with Reset.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	s := &scanner{escape: '\\', directives: true}
+	return s.lexLine
+}
+
+/*
+lexLine lexes the start of a line: a blank line, a comment, a parser
+directive, or an instruction. Directives are only recognized before
+anything else in the file.
+*/
+func (s *scanner) lexLine(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptRun(blanks)
+	l.Ignore()
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	if l.AcceptNewline() {
+		l.Ignore()
+		s.directives = false
+		return s.lexLine
+	}
+
+	if l.Peek() != '#' {
+		s.directives = false
+		return s.lexInstruction
+	}
+
+	if !s.directives || !s.lexDirective(l) {
+		s.directives = false
+		lexComment(l)
+	}
+
+	l.AcceptNewline()
+	l.Ignore()
+
+	return s.lexLine
+}
+
+/*
+lexDirective lexes a parser directive such as # escape=`, returning
+false without consuming anything if the line is an ordinary comment
+*/
+func (s *scanner) lexDirective(l *lexer.Lexer) bool {
+	mark := l.Mark()
+
+	l.Next()
+	l.AcceptRun(blanks)
+
+	nameStart := l.Pos
+	l.AcceptClassRun(letters)
+	name := strings.ToLower(l.Input[nameStart:l.Pos])
+
+	l.AcceptRun(blanks)
+
+	if !directives[name] || !l.Accept("=") {
+		l.Rewind(mark)
+		return false
+	}
+
+	l.AcceptRun(blanks)
+
+	valueStart := l.Pos
+	for !l.IsEOF() && !isBreak(l.Peek()) {
+		l.Next()
+	}
+
+	if value := strings.TrimSpace(l.Input[valueStart:l.Pos]); name == "escape" {
+		switch value {
+		case `\`, "`":
+			s.escape = rune(value[0])
+
+		default:
+			reportAt(l, l.Start, "invalid escape character %q", value)
+		}
+	}
+
+	l.EmitWithTransform(TOKEN_DIRECTIVE, directive)
+	return true
+}
+
+/*
+lexInstruction lexes the name of an instruction and decides how its
+arguments are lexed
+*/
+func (s *scanner) lexInstruction(l *lexer.Lexer) lexer.LexFn {
+	if l.AcceptClassRun(letters) == 0 {
+		for !l.IsEOF() && !isBreak(l.Peek()) {
+			l.Next()
+		}
+
+		l.Errorf("expected an instruction")
+		l.Ignore()
+
+		return s.lexEnd
+	}
+
+	s.instruction = strings.ToUpper(l.CurrentInput())
+
+	if !instructions[s.instruction] {
+		reportAt(l, l.Start, "unknown instruction %s", s.instruction)
+	}
+
+	l.EmitWithTransform(TOKEN_INSTRUCTION, upper)
+
+	switch s.instruction {
+	case "RUN", "CMD", "ENTRYPOINT", "SHELL":
+		return s.lexCommand
+
+	case "ONBUILD":
+		if s.skipSpace(l) {
+			return s.lexEnd
+		}
+
+		return s.lexInstruction
+	}
+
+	return s.lexArguments
+}
+
+/*
+lexArguments lexes the flags and words of an instruction, or its
+arguments in exec form
+*/
+func (s *scanner) lexArguments(l *lexer.Lexer) lexer.LexFn {
+	if s.skipSpace(l) {
+		return s.lexEnd
+	}
+
+	if s.instruction == "HEALTHCHECK" && s.atWord(l, "CMD") {
+		return s.lexInstruction
+	}
+
+	if l.Peek() == '[' && s.isExecForm(l) {
+		return s.lexExec
+	}
+
+	s.lexWord(l)
+	return s.lexArguments
+}
+
+/*
+lexCommand lexes the flags of a RUN, CMD, ENTRYPOINT or SHELL
+instruction and then its command, in exec or shell form
+*/
+func (s *scanner) lexCommand(l *lexer.Lexer) lexer.LexFn {
+	if s.skipSpace(l) {
+		return s.lexEnd
+	}
+
+	if l.PeekCharacters(2) == "--" {
+		s.lexWord(l)
+		return s.lexCommand
+	}
+
+	if l.Peek() == '[' && s.isExecForm(l) {
+		return s.lexExec
+	}
+
+	if s.instruction == "SHELL" {
+		reportAt(l, l.Start, "SHELL requires a JSON array of strings")
+	}
+
+	return s.lexShellCommand
+}
+
+/*
+lexShellCommand lexes a command in shell form, which runs to the end of
+the instruction
+*/
+func (s *scanner) lexShellCommand(l *lexer.Lexer) lexer.LexFn {
+	for !l.IsEOF() && !isBreak(l.Peek()) {
+		if s.acceptContinuation(l) {
+			s.acceptContinuedLines(l)
+			continue
+		}
+
+		l.Next()
+	}
+
+	l.EmitWithTransform(TOKEN_SHELL_COMMAND, s.command)
+	return s.lexEnd
+}
+
+/*
+lexExec lexes arguments in exec form, which isExecForm has checked
+*/
+func (s *scanner) lexExec(l *lexer.Lexer) lexer.LexFn {
+	l.Next()
+	l.Emit(TOKEN_LEFT_BRACKET)
+
+	for {
+		s.skipSpace(l)
+
+		switch l.Peek() {
+		case ']':
+			l.Next()
+			l.Emit(TOKEN_RIGHT_BRACKET)
+			return s.lexEnd
+
+		case ',':
+			l.Next()
+			l.Emit(TOKEN_COMMA)
+
+		default:
+			if !acceptJSONString(l) {
+				l.Errorf("invalid JSON string")
+				l.Ignore()
+				return s.lexEnd
+			}
+
+			l.EmitWithTransform(TOKEN_STRING, jsonString)
+		}
+	}
+}
+
+/*
+lexEnd lexes the end of an instruction
+*/
+func (s *scanner) lexEnd(l *lexer.Lexer) lexer.LexFn {
+	if !s.skipSpace(l) {
+		for !l.IsEOF() && !isBreak(l.Peek()) {
+			l.Next()
+		}
+
+		l.Errorf("unexpected text after %s arguments", s.instruction)
+		l.Ignore()
+	}
+
+	if l.AcceptNewline() {
+		l.Emit(TOKEN_NEWLINE)
+	}
+
+	return s.lexLine
+}
+
+/*
+lexWord lexes a flag or an argument word. Quotes group words, and the
+escape character takes the character after it literally.
+*/
+func (s *scanner) lexWord(l *lexer.Lexer) {
+	flag := l.PeekCharacters(2) == "--"
+
+	for !s.atWordEnd(l) {
+		switch ch := l.Next(); {
+		case ch == s.escape:
+			if !l.IsEOF() && !isBreak(l.Peek()) {
+				l.Next()
+			}
+
+		case ch == '"' || ch == '\'':
+			s.acceptQuoted(l, ch)
+		}
+	}
+
+	if flag {
+		l.EmitWithTransform(TOKEN_FLAG, s.flag)
+	} else {
+		l.EmitWithTransform(TOKEN_ARGUMENT, s.unquote)
+	}
+}
+
+/*
+lexComment lexes a comment to the end of its line
+*/
+func lexComment(l *lexer.Lexer) {
+	for !l.IsEOF() && !isBreak(l.Peek()) {
+		l.Next()
+	}
+
+	l.Emit(TOKEN_COMMENT)
+}
+
+/*
+acceptQuoted consumes the rest of a quoted part of a word, after its
+opening quote. Quotes do not span lines.
+*/
+func (s *scanner) acceptQuoted(l *lexer.Lexer, quote rune) {
+	for !l.IsEOF() && !isBreak(l.Peek()) {
+		ch := l.Next()
+
+		if ch == quote {
+			return
+		}
+
+		if ch == s.escape && quote == '"' && !l.IsEOF() && !isBreak(l.Peek()) {
+			l.Next()
+		}
+	}
+}
+
+/*
+skipSpace skips the whitespace between the parts of an instruction,
+emitting line continuations and the comment lines within them. It
+returns true at the end of the instruction.
+*/
+func (s *scanner) skipSpace(l *lexer.Lexer) bool {
+	for {
+		l.AcceptRun(blanks)
+		l.Ignore()
+
+		if !s.acceptContinuation(l) {
+			return l.IsEOF() || isBreak(l.Peek())
+		}
+
+		l.Emit(TOKEN_CONTINUATION)
+
+		// Empty lines and comment lines do not end a continued
+		// instruction
+		for {
+			l.AcceptRun(blanks)
+			l.Ignore()
+
+			if l.AcceptNewline() {
+				l.Ignore()
+				continue
+			}
+
+			if l.Peek() != '#' {
+				break
+			}
+
+			lexComment(l)
+		}
+	}
+}
+
+/*
+acceptContinuation consumes the escape character at the end of a line,
+with any whitespace after it and the line break, returning false
+without consuming anything if the lexer is not at one
+*/
+func (s *scanner) acceptContinuation(l *lexer.Lexer) bool {
+	if l.Peek() != s.escape || l.IsEOF() {
+		return false
+	}
+
+	mark := l.Mark()
+
+	l.Next()
+	l.AcceptRun(blanks)
+
+	if l.AcceptNewline() || l.IsEOF() {
+		return true
+	}
+
+	l.Rewind(mark)
+	return false
+}
+
+func (s *scanner) atContinuation(l *lexer.Lexer) bool {
+	mark := l.Mark()
+	result := s.acceptContinuation(l)
+	l.Rewind(mark)
+
+	return result
+}
+
+/*
+acceptContinuedLines consumes the empty lines and comment lines after a
+line continuation, as part of the token being lexed
+*/
+func (s *scanner) acceptContinuedLines(l *lexer.Lexer) {
+	for !l.IsEOF() {
+		mark := l.Mark()
+		l.AcceptRun(blanks)
+
+		if l.AcceptNewline() {
+			continue
+		}
+
+		if l.Peek() != '#' {
+			l.Rewind(mark)
+			return
+		}
+
+		for !l.IsEOF() && !isBreak(l.Peek()) {
+			l.Next()
+		}
+
+		l.AcceptNewline()
+	}
+}
+
+func (s *scanner) atWordEnd(l *lexer.Lexer) bool {
+	return l.IsEOF() || isBreak(l.Peek()) || strings.ContainsRune(blanks, l.Peek()) || s.atContinuation(l)
+}
+
+/*
+atWord returns true if the next word is word, in any case
+*/
+func (s *scanner) atWord(l *lexer.Lexer, word string) bool {
+	start := l.Pos
+	mark := l.Mark()
+	defer l.Rewind(mark)
+
+	l.AcceptClassRun(letters)
+	return strings.EqualFold(l.Input[start:l.Pos], word) && s.atWordEnd(l)
+}
+
+/*
+isExecForm returns true if the rest of the instruction is a JSON array
+of strings. Anything else is taken as shell form or words, as Docker
+does.
+*/
+func (s *scanner) isExecForm(l *lexer.Lexer) bool {
+	mark := l.Mark()
+	defer l.Rewind(mark)
+
+	l.Next()
+	s.skipJSONSpace(l)
+
+	if !l.Accept("]") {
+		for {
+			if !acceptJSONString(l) {
+				return false
+			}
+
+			s.skipJSONSpace(l)
+
+			if l.Accept("]") {
+				break
+			}
+
+			if !l.Accept(",") {
+				return false
+			}
+
+			s.skipJSONSpace(l)
+		}
+	}
+
+	s.skipJSONSpace(l)
+	return l.IsEOF() || isBreak(l.Peek())
+}
+
+func (s *scanner) skipJSONSpace(l *lexer.Lexer) {
+	for {
+		l.AcceptRun(blanks)
+
+		if !s.acceptContinuation(l) {
+			return
+		}
+
+		s.acceptContinuedLines(l)
+	}
+}
+
+/*
+acceptJSONString consumes a JSON string, which may not span lines
+*/
+func acceptJSONString(l *lexer.Lexer) bool {
+	if !l.Accept(`"`) {
+		return false
+	}
+
+	for !l.IsEOF() && !isBreak(l.Peek()) {
+		switch l.Next() {
+		case '"':
+			return true
+
+		case '\\':
+			if !l.IsEOF() && !isBreak(l.Peek()) {
+				l.Next()
+			}
+		}
+	}
+
+	return false
+}
+
+func isBreak(ch rune) bool {
+	return ch == '\r' || ch == '\n'
+}
+
+/*
+reportAt reports a problem within the token being lexed, which is still
+emitted
+*/
+func reportAt(l *lexer.Lexer, start int, format string, args ...interface{}) {
+	l.Report(lexer.LexError{
+		Message: fmt.Sprintf(format, args...),
+		Span: lexer.Span{
+			Start: l.PositionAt(start),
+			End:   l.PositionAt(l.Pos),
+		},
+	})
+}
@@ -0,0 +1,47 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestLoneCarriageReturn(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			"\r",
+			`EOF ""
+`,
+		},
+		{
+			"FROM a\r",
+			`INSTRUCTION "FROM"
+ARGUMENT "a"
+NEWLINE "\r"
+EOF ""
+`,
+		},
+		{
+			"FROM a\rRUN b \\\r c\r\n",
+			`INSTRUCTION "FROM"
+ARGUMENT "a"
+NEWLINE "\r"
+INSTRUCTION "RUN"
+SHELL_COMMAND "b \\\r c"
+NEWLINE "\r\n"
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input)))
+
+		if got != test.want {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+		}
+	}
+}
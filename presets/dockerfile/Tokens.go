@@ -0,0 +1,55 @@
+package dockerfile
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_INSTRUCTION lexer.TokenType = iota + 1
+	TOKEN_FLAG
+	TOKEN_ARGUMENT
+	TOKEN_SHELL_COMMAND
+	TOKEN_LEFT_BRACKET
+	TOKEN_RIGHT_BRACKET
+	TOKEN_COMMA
+	TOKEN_STRING
+	TOKEN_CONTINUATION
+	TOKEN_DIRECTIVE
+	TOKEN_COMMENT
+	TOKEN_NEWLINE
+)
+
+/*
+Names holds the names of the Dockerfile token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_INSTRUCTION:   "INSTRUCTION",
+	TOKEN_FLAG:          "FLAG",
+	TOKEN_ARGUMENT:      "ARGUMENT",
+	TOKEN_SHELL_COMMAND: "SHELL_COMMAND",
+	TOKEN_LEFT_BRACKET:  "LEFT_BRACKET",
+	TOKEN_RIGHT_BRACKET: "RIGHT_BRACKET",
+	TOKEN_COMMA:         "COMMA",
+	TOKEN_STRING:        "STRING",
+	TOKEN_CONTINUATION:  "CONTINUATION",
+	TOKEN_DIRECTIVE:     "DIRECTIVE",
+	TOKEN_COMMENT:       "COMMENT",
+	TOKEN_NEWLINE:       "NEWLINE",
+}
+
+/*
+Categories maps the Dockerfile token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_INSTRUCTION:   highlight.CATEGORY_KEYWORD,
+	TOKEN_FLAG:          highlight.CATEGORY_IDENTIFIER,
+	TOKEN_SHELL_COMMAND: highlight.CATEGORY_STRING,
+	TOKEN_LEFT_BRACKET:  highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_BRACKET: highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMA:         highlight.CATEGORY_PUNCTUATION,
+	TOKEN_STRING:        highlight.CATEGORY_STRING,
+	TOKEN_CONTINUATION:  highlight.CATEGORY_OPERATOR,
+	TOKEN_DIRECTIVE:     highlight.CATEGORY_PREPROCESSOR,
+	TOKEN_COMMENT:       highlight.CATEGORY_COMMENT,
+}
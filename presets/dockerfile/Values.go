@@ -0,0 +1,114 @@
+package dockerfile
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+/*
+Flag is the value of a flag token such as --from=build. Value is empty
+for a flag without one.
+*/
+type Flag struct {
+	Name  string
+	Value string
+}
+
+/*
+Directive is the value of a parser directive such as # syntax=docker/dockerfile:1.
+Name is in lower case.
+*/
+type Directive struct {
+	Name  string
+	Value string
+}
+
+func upper(text string) interface{} {
+	return strings.ToUpper(text)
+}
+
+func directive(text string) interface{} {
+	name, value, _ := strings.Cut(strings.TrimPrefix(text, "#"), "=")
+	return Directive{Name: strings.ToLower(strings.TrimSpace(name)), Value: strings.TrimSpace(value)}
+}
+
+func jsonString(text string) interface{} {
+	var value string
+
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return text[1 : len(text)-1]
+	}
+
+	return value
+}
+
+func (s *scanner) flag(text string) interface{} {
+	name, value, _ := strings.Cut(text[2:], "=")
+	return Flag{Name: name, Value: s.unquote(value).(string)}
+}
+
+/*
+unquote is the TokenValueTransformer for argument words. It removes
+quotes and the escape characters outside single quotes.
+*/
+func (s *scanner) unquote(text string) interface{} {
+	if !strings.ContainsAny(text, `"'`+string(s.escape)) {
+		return text
+	}
+
+	var result strings.Builder
+	result.Grow(len(text))
+
+	var quote rune
+	runes := []rune(text)
+
+	for index := 0; index < len(runes); index++ {
+		switch ch := runes[index]; {
+		case ch == s.escape && quote != '\'' && index+1 < len(runes):
+			index++
+			result.WriteRune(runes[index])
+
+		case quote == 0 && (ch == '"' || ch == '\''):
+			quote = ch
+
+		case ch == quote:
+			quote = 0
+
+		default:
+			result.WriteRune(ch)
+		}
+	}
+
+	return result.String()
+}
+
+/*
+command is the TokenValueTransformer for shell commands. It joins
+continued lines, dropping the escape character ending each and the
+empty and comment lines after it, as Docker does before handing the
+command to the shell.
+*/
+func (s *scanner) command(text string) interface{} {
+	var result strings.Builder
+	result.Grow(len(text))
+
+	escape := string(s.escape)
+	continued := false
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		trimmed := strings.TrimRight(line, blanks)
+
+		if continued && (trimmed == "" || strings.HasPrefix(strings.TrimLeft(trimmed, blanks), "#")) {
+			continue
+		}
+
+		if continued = strings.HasSuffix(trimmed, escape); continued {
+			line = strings.TrimSuffix(trimmed, escape)
+		}
+
+		result.WriteString(line)
+	}
+
+	return strings.TrimSpace(result.String())
+}
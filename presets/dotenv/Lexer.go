@@ -0,0 +1,48 @@
+/*
+Package dotenv is a ready-made lexer for .env files, following the
+rules Docker Compose documents for them:
+
+	# Database settings
+	export DB_HOST=localhost
+	DB_URL="postgres://${DB_USER:-app}@$DB_HOST/app\n"
+	GREETING='Let\'s go, $literally' # inline comment
+	EMPTY=
+	PASSTHROUGH
+
+Each line holds a key, optionally preceded by export, and usually an =
+and a value. A value is unquoted, in single quotes, or in double quotes,
+and quoted values may span lines. The quotes are TOKEN_QUOTE tokens of
+their own, and the text between them is split into TOKEN_VALUE and
+TOKEN_EXPANSION tokens, so that the value of a key is the Values of its
+TOKEN_VALUE tokens joined with its expansions substituted. TOKEN_VALUE
+carries its text with escapes resolved: \n, \r, \t, \\, \" and \$ in
+double quotes, and \' in single quotes. Single-quoted values are never
+expanded.
+
+Expansions are $NAME and ${NAME}, with the operators of ${NAME:-default},
+${NAME-default}, ${NAME:?error}, ${NAME?error}, ${NAME:+replacement}
+and ${NAME+replacement}; each carries an Expansion. Unquoted values end
+at the end of the line or at a # preceded by whitespace, and their
+trailing whitespace is not part of them.
+*/
+package dotenv
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for a .env file held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for a .env file read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
@@ -0,0 +1,101 @@
+package dotenv
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestTokens(t *testing.T) {
+	input := `# Database
+export DB_HOST=localhost
+DB_URL="postgres://${DB_USER:-app}@$DB_HOST/app"
+GREETING='Let\'s go, $x' # inline
+PLAIN=one two  # comment
+EMPTY=
+PASSTHROUGH`
+	want := `COMMENT "# Database"
+EXPORT "export"
+KEY "DB_HOST"
+ASSIGN "="
+VALUE "localhost"
+NEWLINE "\n"
+KEY "DB_URL"
+ASSIGN "="
+QUOTE "\""
+VALUE "postgres://"
+EXPANSION "${DB_USER:-app}"
+VALUE "@"
+EXPANSION "$DB_HOST"
+VALUE "/app"
+QUOTE "\""
+NEWLINE "\n"
+KEY "GREETING"
+ASSIGN "="
+QUOTE "'"
+VALUE "Let\\'s go, $x"
+QUOTE "'"
+COMMENT "# inline"
+NEWLINE "\n"
+KEY "PLAIN"
+ASSIGN "="
+VALUE "one two"
+COMMENT "# comment"
+NEWLINE "\n"
+KEY "EMPTY"
+ASSIGN "="
+NEWLINE "\n"
+KEY "PASSTHROUGH"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestValues(t *testing.T) {
+	tests := []struct {
+		input string
+		index int
+		want  interface{}
+	}{
+		{`A="\$HOME\t\"x\"\n"`, 3, "$HOME\t\"x\"\n"},
+		{"A=\"x\ny\"", 3, "x\ny"},
+		{`A='\'$B\n'`, 3, `'$B\n`},
+		{"A=$B", 2, Expansion{Name: "B"}},
+		{"A=${B}", 2, Expansion{Name: "B"}},
+		{"A=${B-d}", 2, Expansion{Name: "B", Operator: "-", Argument: "d"}},
+		{"A=${B:?missing}", 2, Expansion{Name: "B", Operator: ":?", Argument: "missing"}},
+		{"A=${B:+r}", 2, Expansion{Name: "B", Operator: ":+", Argument: "r"}},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if test.index >= len(tokens) || tokens[test.index].Value != test.want {
+			t.Errorf("lexing %q: got %v, want token %d with value %#v", test.input, tokens, test.index, test.want)
+		}
+	}
+}
+
+func TestMalformedLines(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`A="open`, "test:1:8: unterminated double-quoted value"},
+		{"A='open", "test:1:8: unterminated single-quoted value"},
+		{"A=${B", "test:1:3: unterminated expansion"},
+		{"=x", "test:1:1: expected a key"},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input)
+		lexertest.Collect(t, l)
+
+		if diagnostics := l.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Error() != test.want {
+			t.Errorf("lexing %q: got errors %v, want %q", test.input, diagnostics, test.want)
+		}
+	}
+}
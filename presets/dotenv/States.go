@@ -0,0 +1,315 @@
+package dotenv
+
+import (
+	"github.com/adampresley/lexer"
+)
+
+const blanks = " \t"
+
+var (
+	keyStart  = lexer.NewCharClass("_").AddRange('a', 'z').AddRange('A', 'Z')
+	keyChars  = lexer.NewCharClass("_.-").AddRange('a', 'z').AddRange('A', 'Z').AddRange('0', '9')
+	nameStart = keyStart
+	nameChars = lexer.NewCharClass("_").AddRange('a', 'z').AddRange('A', 'Z').AddRange('0', '9')
+)
+
+func init() {
+	lexer.NameState("dotenv.start", Start)
+	lexer.NameState("dotenv.key", lexKey)
+	lexer.NameState("dotenv.value", lexValue)
+	lexer.NameState("dotenv.unquoted", lexUnquoted)
+	lexer.NameState("dotenv.doubleQuoted", lexDoubleQuoted)
+	lexer.NameState("dotenv.singleQuoted", lexSingleQuoted)
+	lexer.NameState("dotenv.lineEnd", lexLineEnd)
+}
+
+/*
+Start is the state at the beginning of a line. It skips blank lines and
+lexes comment lines and the export prefix.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptRun(blanks)
+	l.Ignore()
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	if l.AcceptNewline() {
+		l.Ignore()
+		return Start
+	}
+
+	if l.Peek() == '#' {
+		lexComment(l)
+		l.AcceptNewline()
+		l.Ignore()
+
+		return Start
+	}
+
+	if next := l.PeekCharacters(7); len(next) == 7 && next[:6] == "export" && (next[6] == ' ' || next[6] == '\t') {
+		l.Inc(6)
+		l.Emit(TOKEN_EXPORT)
+		l.AcceptRun(blanks)
+		l.Ignore()
+	}
+
+	return lexKey
+}
+
+/*
+lexKey lexes a key and the = after it. A key on its own, without an =,
+names a variable to be taken from the environment.
+*/
+func lexKey(l *lexer.Lexer) lexer.LexFn {
+	if !l.AcceptClass(keyStart) {
+		return lexBadLine(l, "expected a key")
+	}
+
+	l.AcceptClassRun(keyChars)
+	l.Emit(TOKEN_KEY)
+
+	l.AcceptRun(blanks)
+	l.Ignore()
+
+	if !l.Accept("=") {
+		if l.IsEOF() || isBreak(l.Peek()) || l.Peek() == '#' {
+			return lexLineEnd
+		}
+
+		return lexBadLine(l, "expected = after key")
+	}
+
+	l.Emit(TOKEN_ASSIGN)
+
+	l.AcceptRun(blanks)
+	l.Ignore()
+
+	return lexValue
+}
+
+/*
+lexValue decides how a value is quoted
+*/
+func lexValue(l *lexer.Lexer) lexer.LexFn {
+	switch l.Peek() {
+	case '"':
+		l.Next()
+		l.Emit(TOKEN_QUOTE)
+		return lexDoubleQuoted
+
+	case '\'':
+		l.Next()
+		l.Emit(TOKEN_QUOTE)
+		return lexSingleQuoted
+	}
+
+	return lexUnquoted
+}
+
+/*
+lexUnquoted lexes an unquoted value, which ends at the end of the line
+or at a comment. Whitespace before the end is not part of the value.
+*/
+func lexUnquoted(l *lexer.Lexer) lexer.LexFn {
+	for !l.IsEOF() && !isBreak(l.Peek()) {
+		if startsExpansion(l) {
+			emitText(l, plain)
+			lexExpansion(l)
+			continue
+		}
+
+		if ch := l.Peek(); ch == ' ' || ch == '\t' {
+			mark := l.Mark()
+			l.AcceptRun(blanks)
+
+			if l.IsEOF() || isBreak(l.Peek()) || l.Peek() == '#' {
+				l.Rewind(mark)
+				break
+			}
+
+			continue
+		}
+
+		l.Next()
+	}
+
+	emitText(l, plain)
+	return lexLineEnd
+}
+
+/*
+lexDoubleQuoted lexes the text and expansions of a double-quoted value,
+up to and including the closing quote
+*/
+func lexDoubleQuoted(l *lexer.Lexer) lexer.LexFn {
+	for {
+		if l.IsEOF() {
+			emitText(l, unescapeDouble)
+			l.Errorf("unterminated double-quoted value")
+			return Start
+		}
+
+		switch {
+		case l.Peek() == '"':
+			emitText(l, unescapeDouble)
+			l.Next()
+			l.Emit(TOKEN_QUOTE)
+
+			return lexLineEnd
+
+		case l.Peek() == '\\':
+			l.Next()
+
+			if !l.IsEOF() {
+				l.Next()
+			}
+
+		case startsExpansion(l):
+			emitText(l, unescapeDouble)
+			lexExpansion(l)
+
+		default:
+			l.Next()
+		}
+	}
+}
+
+/*
+lexSingleQuoted lexes the text of a single-quoted value, up to and
+including the closing quote. Only an escaped quote is special.
+*/
+func lexSingleQuoted(l *lexer.Lexer) lexer.LexFn {
+	for {
+		if l.IsEOF() {
+			emitText(l, unescapeSingle)
+			l.Errorf("unterminated single-quoted value")
+			return Start
+		}
+
+		switch {
+		case l.PeekCharacters(2) == `\'`:
+			l.Inc(2)
+
+		case l.Peek() == '\'':
+			emitText(l, unescapeSingle)
+			l.Next()
+			l.Emit(TOKEN_QUOTE)
+
+			return lexLineEnd
+
+		default:
+			l.Next()
+		}
+	}
+}
+
+/*
+lexLineEnd lexes the rest of a line after a value: whitespace, an
+optional comment, and the line break
+*/
+func lexLineEnd(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptRun(blanks)
+	l.Ignore()
+
+	if l.Peek() == '#' {
+		lexComment(l)
+	}
+
+	if !l.IsEOF() && !isBreak(l.Peek()) {
+		return lexBadLine(l, "unexpected text after value")
+	}
+
+	if l.AcceptNewline() {
+		l.Emit(TOKEN_NEWLINE)
+	}
+
+	return Start
+}
+
+/*
+lexBadLine reports the rest of a line as an error and carries on with
+the next line
+*/
+func lexBadLine(l *lexer.Lexer, message string) lexer.LexFn {
+	for !l.IsEOF() && !isBreak(l.Peek()) {
+		l.Next()
+	}
+
+	l.Errorf("%s", message)
+	l.Ignore()
+
+	if l.AcceptNewline() {
+		l.Emit(TOKEN_NEWLINE)
+	}
+
+	return Start
+}
+
+/*
+lexExpansion lexes an expansion, which startsExpansion has found. Braces
+within a braced expansion nest, so a default may hold expansions of its
+own.
+*/
+func lexExpansion(l *lexer.Lexer) {
+	l.Next()
+
+	if !l.Accept("{") {
+		l.AcceptClassRun(nameChars)
+		l.EmitWithTransform(TOKEN_EXPANSION, expansion)
+		return
+	}
+
+	for depth := 1; depth > 0; {
+		if l.IsEOF() {
+			l.Errorf("unterminated expansion")
+			l.Ignore()
+			return
+		}
+
+		switch l.Next() {
+		case '{':
+			depth++
+
+		case '}':
+			depth--
+		}
+	}
+
+	l.EmitWithTransform(TOKEN_EXPANSION, expansion)
+}
+
+/*
+lexComment lexes a comment to the end of its line
+*/
+func lexComment(l *lexer.Lexer) {
+	for !l.IsEOF() && !isBreak(l.Peek()) {
+		l.Next()
+	}
+
+	l.Emit(TOKEN_COMMENT)
+}
+
+/*
+emitText emits the text of a value lexed so far, if there is any
+*/
+func emitText(l *lexer.Lexer, transform lexer.TokenValueTransformer) {
+	if l.Pos > l.Start {
+		l.EmitWithTransform(TOKEN_VALUE, transform)
+	}
+}
+
+/*
+startsExpansion returns true if the lexer is at a $ starting an
+expansion. A $ followed by anything else is literal text.
+*/
+func startsExpansion(l *lexer.Lexer) bool {
+	next := l.PeekCharacters(2)
+	return len(next) == 2 && next[0] == '$' && (next[1] == '{' || nameStart.Contains(rune(next[1])))
+}
+
+func isBreak(ch rune) bool {
+	return ch == '\r' || ch == '\n'
+}
@@ -0,0 +1,56 @@
+package dotenv
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestLoneCarriageReturn(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			"\r",
+			`EOF ""
+`,
+		},
+		{
+			"A=1\r",
+			`KEY "A"
+ASSIGN "="
+VALUE "1"
+NEWLINE "\r"
+EOF ""
+`,
+		},
+		{
+			"A=1\rB=\"x\"\r# c\r\nC=3",
+			`KEY "A"
+ASSIGN "="
+VALUE "1"
+NEWLINE "\r"
+KEY "B"
+ASSIGN "="
+QUOTE "\""
+VALUE "x"
+QUOTE "\""
+NEWLINE "\r"
+COMMENT "# c"
+KEY "C"
+ASSIGN "="
+VALUE "3"
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input)))
+
+		if got != test.want {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+		}
+	}
+}
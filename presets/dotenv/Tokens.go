@@ -0,0 +1,44 @@
+package dotenv
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_EXPORT lexer.TokenType = iota + 1
+	TOKEN_KEY
+	TOKEN_ASSIGN
+	TOKEN_QUOTE
+	TOKEN_VALUE
+	TOKEN_EXPANSION
+	TOKEN_COMMENT
+	TOKEN_NEWLINE
+)
+
+/*
+Names holds the names of the .env token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_EXPORT:    "EXPORT",
+	TOKEN_KEY:       "KEY",
+	TOKEN_ASSIGN:    "ASSIGN",
+	TOKEN_QUOTE:     "QUOTE",
+	TOKEN_VALUE:     "VALUE",
+	TOKEN_EXPANSION: "EXPANSION",
+	TOKEN_COMMENT:   "COMMENT",
+	TOKEN_NEWLINE:   "NEWLINE",
+}
+
+/*
+Categories maps the .env token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_EXPORT:    highlight.CATEGORY_KEYWORD,
+	TOKEN_KEY:       highlight.CATEGORY_IDENTIFIER,
+	TOKEN_ASSIGN:    highlight.CATEGORY_OPERATOR,
+	TOKEN_QUOTE:     highlight.CATEGORY_STRING,
+	TOKEN_VALUE:     highlight.CATEGORY_STRING,
+	TOKEN_EXPANSION: highlight.CATEGORY_PREPROCESSOR,
+	TOKEN_COMMENT:   highlight.CATEGORY_COMMENT,
+}
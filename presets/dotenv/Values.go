@@ -0,0 +1,87 @@
+package dotenv
+
+import (
+	"strings"
+)
+
+/*
+Expansion is the value of an expansion token. Operator is one of :-
+- :? ? :+ and +, or empty for a plain $NAME or ${NAME}. Argument is the
+text after the operator as written, which may hold further expansions.
+*/
+type Expansion struct {
+	Name     string
+	Operator string
+	Argument string
+}
+
+var operators = []string{":-", ":?", ":+", "-", "?", "+"}
+
+var doubleEscapes = map[byte]byte{
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'\\': '\\',
+	'"':  '"',
+	'$':  '$',
+}
+
+func plain(text string) interface{} {
+	return text
+}
+
+func expansion(text string) interface{} {
+	if !strings.HasPrefix(text, "${") {
+		return Expansion{Name: text[1:]}
+	}
+
+	body := strings.TrimSuffix(text[2:], "}")
+
+	end := 0
+	for end < len(body) && nameChars.Contains(rune(body[end])) {
+		end++
+	}
+
+	result := Expansion{Name: body[:end]}
+
+	for _, operator := range operators {
+		if strings.HasPrefix(body[end:], operator) {
+			result.Operator = operator
+			result.Argument = body[end+len(operator):]
+			break
+		}
+	}
+
+	return result
+}
+
+/*
+unescapeDouble resolves the escapes of double-quoted text. A backslash
+before any other character is kept.
+*/
+func unescapeDouble(text string) interface{} {
+	if strings.IndexByte(text, '\\') < 0 {
+		return text
+	}
+
+	var result strings.Builder
+	result.Grow(len(text))
+
+	for index := 0; index < len(text); index++ {
+		if text[index] == '\\' && index+1 < len(text) {
+			if replacement, ok := doubleEscapes[text[index+1]]; ok {
+				result.WriteByte(replacement)
+				index++
+				continue
+			}
+		}
+
+		result.WriteByte(text[index])
+	}
+
+	return result.String()
+}
+
+func unescapeSingle(text string) interface{} {
+	return strings.ReplaceAll(text, `\'`, "'")
+}
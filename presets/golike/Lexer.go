@@ -0,0 +1,46 @@
+/*
+Package golike is a ready-made lexer for the token shapes shared by Go
+and the C family: identifiers, keywords, integer, floating point and
+imaginary literals in every form Go accepts, interpreted and raw
+strings, rune literals, operators, and both line and block comments.
+
+	l := golike.NewLexer("main.go", input)
+
+	for _, token := range l.Collect() {
+		...
+	}
+
+It is meant as a starting point for your own language as much as a
+lexer in its own right. Copy the package, change the keyword list in
+Tokens.go and the operator table beside it, and adjust the states for
+whatever literal forms your language adds or leaves out. Each token
+shape has a state of its own, so most changes stay local to one
+function.
+
+String and rune tokens carry their decoded value in Value, as a string
+and a rune respectively. Semicolons are not inserted automatically as
+Go's scanner does. A parser for a language with that rule can insert
+them itself by comparing the line of each token with the line of the
+token before it.
+*/
+package golike
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for source held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for source read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
@@ -0,0 +1,111 @@
+package golike
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		input     string
+		tokenType string
+	}{
+		{"0", "INT"},
+		{"0x1F", "INT"},
+		{"0o17", "INT"},
+		{"017", "INT"},
+		{"0b1010", "INT"},
+		{"1_000", "INT"},
+		{"3.14", "FLOAT"},
+		{".5", "FLOAT"},
+		{"1e9", "FLOAT"},
+		{"0x1p-2", "FLOAT"},
+		{"1i", "IMAGINARY"},
+		{"2.5i", "IMAGINARY"},
+		{"0x", "ERROR"},
+		{"1e", "ERROR"},
+		{"08", "ERROR"},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if got := Names.Name(tokens[0].Type); got != test.tokenType || len(tokens) != 2 {
+			t.Errorf("lexing %s: got %s and %d tokens, want %s and 2 tokens", test.input, got, len(tokens), test.tokenType)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	input := "func f() { x := `raw` + \"s\" // c\n/* b */ a <<= b &^ c... }"
+	want := `KEYWORD "func"
+IDENTIFIER "f"
+LEFT_PAREN "("
+RIGHT_PAREN ")"
+LEFT_BRACE "{"
+IDENTIFIER "x"
+OPERATOR ":="
+RAW_STRING "` + "`raw`" + `"
+OPERATOR "+"
+STRING "\"s\""
+COMMENT "// c"
+COMMENT "/* b */"
+IDENTIFIER "a"
+OPERATOR "<<="
+IDENTIFIER "b"
+OPERATOR "&^"
+IDENTIFIER "c"
+OPERATOR "..."
+RIGHT_BRACE "}"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestLiteralValues(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{`"s\t\u00e9"`, "s\té"},
+		{"`raw\\n`", `raw\n`},
+		{`'x'`, 'x'},
+		{`'\u00e9'`, 'é'},
+		{`'\n'`, '\n'},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if tokens[0].Value != test.want {
+			t.Errorf("lexing %s: got value %#v, want %#v", test.input, tokens[0].Value, test.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	input := "\"open\n'ab' /* open"
+	want := []string{
+		"test:1:1: unterminated string",
+		"test:2:1: rune literal must hold exactly one character",
+		"test:2:6: unterminated comment",
+	}
+
+	l := NewLexer("test", input)
+	lexertest.Collect(t, l)
+
+	diagnostics := l.Diagnostics()
+	if len(diagnostics) != len(want) {
+		t.Fatalf("lexing %q: got %d errors, want %d", input, len(diagnostics), len(want))
+	}
+
+	for index, diagnostic := range diagnostics {
+		if got := diagnostic.Error(); got != want[index] {
+			t.Errorf("lexing %q: got error %q, want %q", input, got, want[index])
+		}
+	}
+}
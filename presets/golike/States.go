@@ -0,0 +1,383 @@
+package golike
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	whitespace      = lexer.WhitespaceClass
+	digits          = lexer.DigitClass
+	hexDigits       = lexer.HexDigitClass
+	octalDigits     = lexer.NewCharClass("01234567")
+	identifierStart = lexer.NewCharClass("_").AddTable(unicode.Letter)
+	identifierChars = lexer.IdentifierClass
+	numberChars     = lexer.NewCharClass("_.").AddTable(unicode.Letter, unicode.Number)
+	lineChars       = lexer.NewCharClass("\n").Not()
+	simpleEscapes   = lexer.NewCharClass(`abfnrtv\`)
+)
+
+func init() {
+	lexer.NameState("golike.token", Start)
+	lexer.NameState("golike.identifier", lexIdentifier)
+	lexer.NameState("golike.number", lexNumber)
+	lexer.NameState("golike.string", lexString)
+	lexer.NameState("golike.rawString", lexRawString)
+	lexer.NameState("golike.rune", lexRune)
+	lexer.NameState("golike.lineComment", lexLineComment)
+	lexer.NameState("golike.blockComment", lexBlockComment)
+}
+
+/*
+Start is the state between tokens. It skips whitespace and decides
+which state lexes the next token from its first characters.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(whitespace)
+	l.Ignore()
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	ch := l.Peek()
+	next := l.PeekCharacters(2)
+
+	switch {
+	case next == "//":
+		return lexLineComment
+
+	case next == "/*":
+		return lexBlockComment
+
+	case identifierStart.Contains(ch):
+		return lexIdentifier
+
+	case digits.Contains(ch) || (ch == '.' && len(next) == 2 && digits.Contains(rune(next[1]))):
+		return lexNumber
+
+	case ch == '"':
+		return lexString
+
+	case ch == '`':
+		return lexRawString
+
+	case ch == '\'':
+		return lexRune
+	}
+
+	if operator := matchOperator(l); operator != "" {
+		l.Inc(len(operator))
+		l.Emit(TOKEN_OPERATOR)
+		return Start
+	}
+
+	if tokenType, ok := punctuation[ch]; ok {
+		l.Next()
+		l.Emit(tokenType)
+		return Start
+	}
+
+	if ch == '.' {
+		l.Next()
+		l.Emit(TOKEN_DOT)
+		return Start
+	}
+
+	l.Next()
+	l.Errorf("unexpected character %q", ch)
+	l.Ignore()
+
+	return Start
+}
+
+/*
+lexIdentifier lexes an identifier or keyword
+*/
+func lexIdentifier(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(identifierChars)
+	l.EmitKeyword(keywords, TOKEN_IDENTIFIER)
+
+	return Start
+}
+
+/*
+lexNumber lexes an integer, floating point or imaginary literal. The
+literal is scanned generously, taking every character that could belong
+to a number, and then checked against Go's rules, so that a malformed
+literal such as 0x1.5 or 09 is reported as a whole rather than split
+into several tokens.
+*/
+func lexNumber(l *lexer.Lexer) lexer.LexFn {
+	hex := strings.HasPrefix(strings.ToLower(l.PeekCharacters(2)), "0x")
+
+	for l.AcceptClass(numberChars) {
+		// Exponents may be signed. In hexadecimal literals e is a digit
+		// and only p starts an exponent.
+		switch l.Input[l.Pos-1] {
+		case 'p', 'P':
+			l.Accept("+-")
+
+		case 'e', 'E':
+			if !hex {
+				l.Accept("+-")
+			}
+		}
+	}
+
+	text := l.CurrentInput()
+	tokenType := classifyNumber(text)
+
+	if tokenType == 0 {
+		l.Errorf("invalid number literal %q", text)
+		l.Ignore()
+		return Start
+	}
+
+	l.Emit(tokenType)
+	return Start
+}
+
+/*
+classifyNumber returns the token type of a numeric literal, or zero if
+the literal is malformed
+*/
+func classifyNumber(text string) lexer.TokenType {
+	if imaginary := strings.TrimSuffix(text, "i"); imaginary != text {
+		// Imaginary literals may have leading zeros, which are decimal
+		if isDecimal(imaginary) || classifyNumber(imaginary) != 0 {
+			return TOKEN_IMAGINARY
+		}
+
+		return 0
+	}
+
+	if _, err := strconv.ParseInt(text, 0, 64); err == nil || isRangeError(err) {
+		return TOKEN_INT
+	}
+
+	// Floats need a fraction or exponent, which strconv does not insist
+	// on, and only decimal and hexadecimal floats exist
+	if strings.HasPrefix(strings.ToLower(text), "0x") {
+		if !strings.ContainsAny(text, "pP") {
+			return 0
+		}
+	} else if strings.ContainsAny(text, "bBoO") || !strings.ContainsAny(text, ".eE") {
+		return 0
+	}
+
+	if _, err := strconv.ParseFloat(text, 64); err == nil || isRangeError(err) {
+		return TOKEN_FLOAT
+	}
+
+	return 0
+}
+
+func isDecimal(text string) bool {
+	return text != "" && strings.Trim(text, "0123456789_") == "" && !strings.Contains(text, "__") && text[len(text)-1] != '_'
+}
+
+func isRangeError(err error) bool {
+	numError, ok := err.(*strconv.NumError)
+	return ok && numError.Err == strconv.ErrRange
+}
+
+/*
+lexString lexes an interpreted string, checking its escapes. A string
+left open at the end of a line is reported and lexing resumes on the
+next line.
+*/
+func lexString(l *lexer.Lexer) lexer.LexFn {
+	l.Next()
+
+	for {
+		if l.IsEOF() || l.IsNewline() {
+			l.Errorf("unterminated string")
+			l.Ignore()
+			return Start
+		}
+
+		switch l.Next() {
+		case '"':
+			l.EmitWithTransform(TOKEN_STRING, Unquote)
+			return Start
+
+		case '\\':
+			lexEscape(l, '"')
+		}
+	}
+}
+
+/*
+lexRawString lexes a raw string, which may span lines and has no
+escapes
+*/
+func lexRawString(l *lexer.Lexer) lexer.LexFn {
+	l.Next()
+
+	for {
+		if l.IsEOF() {
+			l.Errorf("unterminated raw string")
+			l.Ignore()
+			return Start
+		}
+
+		if l.Next() == '`' {
+			l.EmitWithTransform(TOKEN_RAW_STRING, Unquote)
+			return Start
+		}
+	}
+}
+
+/*
+lexRune lexes a rune literal, which must hold exactly one character or
+escape
+*/
+func lexRune(l *lexer.Lexer) lexer.LexFn {
+	start := l.Pos
+	l.Next()
+	count := 0
+
+	for {
+		if l.IsEOF() || l.IsNewline() {
+			l.Errorf("unterminated rune literal")
+			l.Ignore()
+			return Start
+		}
+
+		switch l.Next() {
+		case '\'':
+			if count != 1 {
+				reportAt(l, start, "rune literal must hold exactly one character")
+			}
+
+			l.EmitWithTransform(TOKEN_RUNE, UnquoteRune)
+			return Start
+
+		case '\\':
+			lexEscape(l, '\'')
+		}
+
+		count++
+	}
+}
+
+/*
+lexEscape checks the escape sequence following a backslash in a string
+or rune literal quoted with quote. A bad escape is reported without
+ending the literal.
+*/
+func lexEscape(l *lexer.Lexer, quote rune) {
+	start := l.Pos - 1
+
+	if l.AcceptClass(simpleEscapes) || l.Accept(string(quote)) {
+		return
+	}
+
+	var class *lexer.CharClass
+	count := 0
+
+	switch l.Peek() {
+	case 'x':
+		class, count = hexDigits, 2
+
+	case 'u':
+		class, count = hexDigits, 4
+
+	case 'U':
+		class, count = hexDigits, 8
+
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		class, count = octalDigits, 2
+
+	default:
+		reportAt(l, start, "invalid escape sequence")
+		return
+	}
+
+	l.Next()
+
+	for index := 0; index < count; index++ {
+		if !l.AcceptClass(class) {
+			reportAt(l, start, "invalid escape sequence")
+			return
+		}
+	}
+
+	if _, _, _, err := strconv.UnquoteChar(l.Input[start:l.Pos], byte(quote)); err != nil {
+		reportAt(l, start, "escape sequence is an invalid code point")
+	}
+}
+
+/*
+lexLineComment lexes a // comment through to the end of the line
+*/
+func lexLineComment(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(lineChars)
+
+	// A comment ending a Windows line keeps its carriage return out
+	if strings.HasSuffix(l.CurrentInput(), "\r") {
+		l.Pos--
+	}
+
+	l.Emit(TOKEN_COMMENT)
+	return Start
+}
+
+/*
+lexBlockComment lexes a /* comment. Block comments do not nest.
+*/
+func lexBlockComment(l *lexer.Lexer) lexer.LexFn {
+	l.Inc(2)
+
+	for l.PeekCharacters(2) != "*/" {
+		if l.IsEOF() {
+			l.Errorf("unterminated comment")
+			l.Ignore()
+			return Start
+		}
+
+		l.Next()
+	}
+
+	l.Inc(2)
+	l.Emit(TOKEN_COMMENT)
+
+	return Start
+}
+
+/*
+matchOperator returns the longest operator at the current position, or
+an empty string if there is none
+*/
+func matchOperator(l *lexer.Lexer) string {
+	next := l.PeekCharacters(3)
+
+	for _, operator := range operators {
+		if strings.HasPrefix(next, operator) {
+			return operator
+		}
+	}
+
+	return ""
+}
+
+/*
+reportAt reports an error covering the input from start to the current
+position without emitting an error token, for problems inside a token
+that is still emitted
+*/
+func reportAt(l *lexer.Lexer, start int, format string, args ...interface{}) {
+	l.Report(lexer.LexError{
+		Message: fmt.Sprintf(format, args...),
+		Span: lexer.Span{
+			Start: l.PositionAt(start),
+			End:   l.PositionAt(l.Pos),
+		},
+	})
+}
@@ -0,0 +1,133 @@
+package golike
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_IDENTIFIER lexer.TokenType = iota + 1
+	TOKEN_KEYWORD
+	TOKEN_INT
+	TOKEN_FLOAT
+	TOKEN_IMAGINARY
+	TOKEN_STRING
+	TOKEN_RAW_STRING
+	TOKEN_RUNE
+	TOKEN_OPERATOR
+	TOKEN_LEFT_PAREN
+	TOKEN_RIGHT_PAREN
+	TOKEN_LEFT_BRACE
+	TOKEN_RIGHT_BRACE
+	TOKEN_LEFT_BRACKET
+	TOKEN_RIGHT_BRACKET
+	TOKEN_COMMA
+	TOKEN_SEMICOLON
+	TOKEN_DOT
+	TOKEN_COLON
+	TOKEN_COMMENT
+)
+
+/*
+Names holds the names of the token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_IDENTIFIER:    "IDENTIFIER",
+	TOKEN_KEYWORD:       "KEYWORD",
+	TOKEN_INT:           "INT",
+	TOKEN_FLOAT:         "FLOAT",
+	TOKEN_IMAGINARY:     "IMAGINARY",
+	TOKEN_STRING:        "STRING",
+	TOKEN_RAW_STRING:    "RAW_STRING",
+	TOKEN_RUNE:          "RUNE",
+	TOKEN_OPERATOR:      "OPERATOR",
+	TOKEN_LEFT_PAREN:    "LEFT_PAREN",
+	TOKEN_RIGHT_PAREN:   "RIGHT_PAREN",
+	TOKEN_LEFT_BRACE:    "LEFT_BRACE",
+	TOKEN_RIGHT_BRACE:   "RIGHT_BRACE",
+	TOKEN_LEFT_BRACKET:  "LEFT_BRACKET",
+	TOKEN_RIGHT_BRACKET: "RIGHT_BRACKET",
+	TOKEN_COMMA:         "COMMA",
+	TOKEN_SEMICOLON:     "SEMICOLON",
+	TOKEN_DOT:           "DOT",
+	TOKEN_COLON:         "COLON",
+	TOKEN_COMMENT:       "COMMENT",
+}
+
+/*
+Categories maps the token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_IDENTIFIER:    highlight.CATEGORY_IDENTIFIER,
+	TOKEN_KEYWORD:       highlight.CATEGORY_KEYWORD,
+	TOKEN_INT:           highlight.CATEGORY_NUMBER,
+	TOKEN_FLOAT:         highlight.CATEGORY_NUMBER,
+	TOKEN_IMAGINARY:     highlight.CATEGORY_NUMBER,
+	TOKEN_STRING:        highlight.CATEGORY_STRING,
+	TOKEN_RAW_STRING:    highlight.CATEGORY_STRING,
+	TOKEN_RUNE:          highlight.CATEGORY_STRING,
+	TOKEN_OPERATOR:      highlight.CATEGORY_OPERATOR,
+	TOKEN_LEFT_PAREN:    highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_PAREN:   highlight.CATEGORY_PUNCTUATION,
+	TOKEN_LEFT_BRACE:    highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_BRACE:   highlight.CATEGORY_PUNCTUATION,
+	TOKEN_LEFT_BRACKET:  highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_BRACKET: highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMA:         highlight.CATEGORY_PUNCTUATION,
+	TOKEN_SEMICOLON:     highlight.CATEGORY_PUNCTUATION,
+	TOKEN_DOT:           highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COLON:         highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMENT:       highlight.CATEGORY_COMMENT,
+}
+
+var keywords = lexer.NewKeywordMatcher(map[string]lexer.TokenType{
+	"break":       TOKEN_KEYWORD,
+	"case":        TOKEN_KEYWORD,
+	"chan":        TOKEN_KEYWORD,
+	"const":       TOKEN_KEYWORD,
+	"continue":    TOKEN_KEYWORD,
+	"default":     TOKEN_KEYWORD,
+	"defer":       TOKEN_KEYWORD,
+	"else":        TOKEN_KEYWORD,
+	"fallthrough": TOKEN_KEYWORD,
+	"for":         TOKEN_KEYWORD,
+	"func":        TOKEN_KEYWORD,
+	"go":          TOKEN_KEYWORD,
+	"goto":        TOKEN_KEYWORD,
+	"if":          TOKEN_KEYWORD,
+	"import":      TOKEN_KEYWORD,
+	"interface":   TOKEN_KEYWORD,
+	"map":         TOKEN_KEYWORD,
+	"package":     TOKEN_KEYWORD,
+	"range":       TOKEN_KEYWORD,
+	"return":      TOKEN_KEYWORD,
+	"select":      TOKEN_KEYWORD,
+	"struct":      TOKEN_KEYWORD,
+	"switch":      TOKEN_KEYWORD,
+	"type":        TOKEN_KEYWORD,
+	"var":         TOKEN_KEYWORD,
+})
+
+var punctuation = map[rune]lexer.TokenType{
+	'(': TOKEN_LEFT_PAREN,
+	')': TOKEN_RIGHT_PAREN,
+	'{': TOKEN_LEFT_BRACE,
+	'}': TOKEN_RIGHT_BRACE,
+	'[': TOKEN_LEFT_BRACKET,
+	']': TOKEN_RIGHT_BRACKET,
+	',': TOKEN_COMMA,
+	';': TOKEN_SEMICOLON,
+	':': TOKEN_COLON,
+}
+
+/*
+operators lists the operators longest first, so the first one found
+at the current position is the longest match. The dot is lexed as an
+operator only as part of an ellipsis.
+*/
+var operators = []string{
+	"<<=", ">>=", "&^=", "...",
+	"&&", "||", "<-", "++", "--", "==", "!=", "<=", ">=", ":=",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "<<", ">>", "&^",
+	"+", "-", "*", "/", "%", "&", "|", "^", "<", ">", "=", "!", "~",
+}
@@ -0,0 +1,33 @@
+package golike
+
+import (
+	"strconv"
+	"unicode"
+)
+
+/*
+Unquote is the TokenValueTransformer for interpreted and raw strings.
+It returns the decoded string, or the text between the quotes when the
+string has an invalid escape, which was reported while lexing.
+*/
+func Unquote(text string) interface{} {
+	if value, err := strconv.Unquote(text); err == nil {
+		return value
+	}
+
+	return text[1 : len(text)-1]
+}
+
+/*
+UnquoteRune is the TokenValueTransformer for rune literals. It returns
+the rune, or the Unicode replacement character for a malformed literal.
+*/
+func UnquoteRune(text string) interface{} {
+	value, _, tail, err := strconv.UnquoteChar(text[1:len(text)-1], '\'')
+
+	if err != nil || tail != "" {
+		return unicode.ReplacementChar
+	}
+
+	return value
+}
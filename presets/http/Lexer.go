@@ -0,0 +1,59 @@
+/*
+Package http is a ready-made lexer for the head of an HTTP/1.x message
+as described in RFC 7230: the request or status line and the header
+fields. It is meant for proxies and testing tools that need to inspect
+or rewrite messages exactly as they were sent, rather than as net/http
+would normalize them.
+
+Messages are usually lexed straight from a connection:
+
+	l := http.NewReaderLexer("client", conn)
+
+	for token := l.NextToken(); token.Type != lexer.TOKEN_EOF; token = l.NextToken() {
+		...
+	}
+
+Field values are split into the RFC's tokens, separators, quoted
+strings and comments, so a Content-Type such as
+
+	text/html; charset="utf-8"
+
+arrives as TOKEN_TOKEN, TOKEN_SEPARATOR, TOKEN_TOKEN, TOKEN_SEPARATOR,
+TOKEN_TOKEN, TOKEN_SEPARATOR and TOKEN_QUOTED_STRING, the last carrying
+the unescaped string in Value. Status codes carry their number in
+Value. Obsolete line folding is accepted and treated as whitespace, and
+bare LF line endings are accepted alongside CRLF.
+
+The empty line ending the header section is TOKEN_HEADERS_END. Whatever
+follows it is emitted as TOKEN_BODY tokens of at most BodyChunkSize
+bytes each, so a large body streams through without being held in
+memory. Content-Length and chunked transfer coding are not interpreted.
+*/
+package http
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+BodyChunkSize is the largest number of bytes of message body carried
+by a single TOKEN_BODY token
+*/
+const BodyChunkSize = 32 * 1024
+
+/*
+NewLexer creates a lexer for an HTTP message held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for an HTTP message read from reader,
+such as a network connection
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestRequest(t *testing.T) {
+	input := "GET /index.html?q=1 HTTP/1.1\r\n" +
+		"Content-Type: text/html; charset=\"utf-8\"\r\n" +
+		"X-Folded: one\r\n two (note)\r\n" +
+		"\r\n" +
+		"hello"
+
+	want := `METHOD "GET"
+TARGET "/index.html?q=1"
+VERSION "HTTP/1.1"
+NEWLINE "\r\n"
+FIELD_NAME "Content-Type"
+COLON ":"
+TOKEN "text"
+SEPARATOR "/"
+TOKEN "html"
+SEPARATOR ";"
+TOKEN "charset"
+SEPARATOR "="
+QUOTED_STRING "\"utf-8\""
+NEWLINE "\r\n"
+FIELD_NAME "X-Folded"
+COLON ":"
+TOKEN "one"
+TOKEN "two"
+COMMENT "(note)"
+NEWLINE "\r\n"
+HEADERS_END "\r\n"
+BODY "hello"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestResponse(t *testing.T) {
+	input := "HTTP/1.1 404 Not Found\nServer: x\n\n"
+	want := `VERSION "HTTP/1.1"
+STATUS_CODE "404"
+REASON "Not Found"
+NEWLINE "\n"
+FIELD_NAME "Server"
+COLON ":"
+TOKEN "x"
+NEWLINE "\n"
+HEADERS_END "\n"
+EOF ""
+`
+
+	tokens := lexertest.Collect(t, NewLexer("test", input))
+
+	if got := lexertest.Format(Names, tokens); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+
+	if tokens[1].Value != 404 {
+		t.Errorf("got status code value %#v, want 404", tokens[1].Value)
+	}
+}
+
+func TestQuotedStringValue(t *testing.T) {
+	input := "HTTP/1.1 200 OK\r\nA: \"x\\\"y\"\r\n\r\n"
+
+	tokens := lexertest.Collect(t, NewLexer("test", input))
+
+	if tokens[6].Type != TOKEN_QUOTED_STRING || tokens[6].Value != `x"y` {
+		t.Errorf("got %s with value %q, want QUOTED_STRING with value %q", Names.Name(tokens[6].Type), tokens[6].Value, `x"y`)
+	}
+}
+
+func TestBodyChunks(t *testing.T) {
+	body := strings.Repeat("b", BodyChunkSize*2+1)
+	l := NewReaderLexer("test", strings.NewReader("HTTP/1.1 200 OK\r\n\r\n"+body))
+
+	var sizes []int
+	for _, token := range lexertest.Collect(t, l) {
+		if token.Type == TOKEN_BODY {
+			sizes = append(sizes, len(token.Text))
+		}
+	}
+
+	if len(sizes) != 3 || sizes[0] != BodyChunkSize || sizes[1] != BodyChunkSize || sizes[2] != 1 {
+		t.Errorf("got body chunks of %v bytes, want %d, %d and 1", sizes, BodyChunkSize, BodyChunkSize)
+	}
+}
+
+func TestMalformedHead(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"GET / HTTP/1.1\r\nBad Header\r\n\r\n", "test:2:4: expected : after header field name"},
+		{"HTTP/1.1 99x Oops\r\n\r\n", "test:1:10: expected a three digit status code"},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input)
+		lexertest.Collect(t, l)
+
+		if diagnostics := l.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Error() != test.want {
+			t.Errorf("lexing %q: got errors %v, want %q", test.input, diagnostics, test.want)
+		}
+	}
+}
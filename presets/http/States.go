@@ -0,0 +1,374 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	// tchar from RFC 7230 section 3.2.6
+	tchars = lexer.NewCharClass("!#$%&'*+-.^_`|~").AddRange('0', '9').AddRange('a', 'z').AddRange('A', 'Z')
+
+	// The delimiters of RFC 7230 section 3.2.6, less DQUOTE and
+	// parentheses, which start quoted strings and comments
+	separators = lexer.NewCharClass(`,/:;<=>?@[\]{}`)
+
+	whitespace = lexer.NewCharClass(" \t")
+	digits     = lexer.DigitClass
+	lineChars  = lexer.NewCharClass("\r\n").Not()
+
+	// Visible characters of a request target, which ends at whitespace
+	targetChars = lexer.NewCharClass(" \t\r\n").Not()
+)
+
+func init() {
+	lexer.NameState("http.start", Start)
+	lexer.NameState("http.requestLine", lexRequestLine)
+	lexer.NameState("http.statusLine", lexStatusLine)
+	lexer.NameState("http.fieldLine", lexFieldLine)
+	lexer.NameState("http.fieldValue", lexFieldValue)
+	lexer.NameState("http.body", lexBody)
+}
+
+/*
+Start is the state at the beginning of a message. Empty lines before
+the start line are skipped, as RFC 7230 asks of servers, and the start
+line is lexed as a status line if it begins with the HTTP version and
+as a request line otherwise.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	for acceptLineEnd(l) {
+		l.Ignore()
+	}
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	if l.PeekCharacters(5) == "HTTP/" {
+		return lexStatusLine
+	}
+
+	return lexRequestLine
+}
+
+/*
+lexRequestLine lexes the method, request target and HTTP version of a
+request line
+*/
+func lexRequestLine(l *lexer.Lexer) lexer.LexFn {
+	if l.AcceptClassRun(tchars) == 0 {
+		return lexBadLine(l, "invalid request method")
+	}
+
+	l.Emit(TOKEN_METHOD)
+
+	if !skipSpace(l) {
+		return lexBadLine(l, "expected a space after the method")
+	}
+
+	if l.AcceptClassRun(targetChars) == 0 {
+		return lexBadLine(l, "missing request target")
+	}
+
+	l.Emit(TOKEN_TARGET)
+
+	if !skipSpace(l) || !lexVersion(l) {
+		return lexBadLine(l, "expected HTTP version after the request target")
+	}
+
+	return lexStartLineEnd(l)
+}
+
+/*
+lexStatusLine lexes the HTTP version, status code and reason phrase of
+a status line. The reason phrase may be empty.
+*/
+func lexStatusLine(l *lexer.Lexer) lexer.LexFn {
+	if !lexVersion(l) {
+		return lexBadLine(l, "invalid HTTP version")
+	}
+
+	if !skipSpace(l) || l.AcceptClassRun(digits) != 3 {
+		return lexBadLine(l, "expected a three digit status code")
+	}
+
+	l.EmitWithTransform(TOKEN_STATUS_CODE, statusCode)
+
+	if skipSpace(l) && l.AcceptClassRun(lineChars) > 0 {
+		l.Emit(TOKEN_REASON)
+	}
+
+	return lexStartLineEnd(l)
+}
+
+/*
+lexStartLineEnd expects the end of the start line
+*/
+func lexStartLineEnd(l *lexer.Lexer) lexer.LexFn {
+	if !acceptLineEnd(l) {
+		return lexBadLine(l, "unexpected text at the end of the start line")
+	}
+
+	l.Emit(TOKEN_NEWLINE)
+	return lexFieldLine
+}
+
+/*
+lexFieldLine lexes the name of a header field and its colon, or the
+empty line ending the header section
+*/
+func lexFieldLine(l *lexer.Lexer) lexer.LexFn {
+	if acceptLineEnd(l) {
+		l.Emit(TOKEN_HEADERS_END)
+		return lexBody
+	}
+
+	if l.IsEOF() {
+		l.Errorf("message head ends without an empty line")
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	if l.AcceptClassRun(tchars) == 0 {
+		return lexBadLine(l, "invalid header field name")
+	}
+
+	l.Emit(TOKEN_FIELD_NAME)
+
+	// RFC 7230 section 3.2.4 forbids whitespace before the colon, as
+	// servers and proxies disagree on what it means
+	if !l.Accept(":") {
+		return lexBadLine(l, "expected : after header field name")
+	}
+
+	l.Emit(TOKEN_COLON)
+	return lexFieldValue
+}
+
+/*
+lexFieldValue lexes one token, separator, quoted string or comment of a
+header field value, skipping the optional whitespace between them
+*/
+func lexFieldValue(l *lexer.Lexer) lexer.LexFn {
+	skipSpace(l)
+
+	if l.IsEOF() {
+		return lexFieldLine
+	}
+
+	if acceptLineEnd(l) {
+		// Obsolete line folding continues the value on the next line
+		if ch := l.Peek(); ch == ' ' || ch == '\t' {
+			l.Ignore()
+			return lexFieldValue
+		}
+
+		l.Emit(TOKEN_NEWLINE)
+		return lexFieldLine
+	}
+
+	switch ch := l.Peek(); {
+	case tchars.Contains(ch):
+		l.AcceptClassRun(tchars)
+		l.Emit(TOKEN_TOKEN)
+
+	case separators.Contains(ch):
+		l.Next()
+		l.Emit(TOKEN_SEPARATOR)
+
+	case ch == '"':
+		lexQuotedString(l)
+
+	case ch == '(':
+		lexComment(l)
+
+	case ch < 0x20 || ch == 0x7f:
+		l.Next()
+		l.Errorf("control character %q in header field value", ch)
+		l.Ignore()
+
+	default:
+		for ch := l.Peek(); !l.IsEOF() && !tchars.Contains(ch) && !separators.Contains(ch) && ch > ' ' && ch != '"' && ch != '(' && ch != 0x7f; ch = l.Peek() {
+			l.Next()
+		}
+
+		l.Emit(TOKEN_TEXT)
+	}
+
+	return lexFieldValue
+}
+
+/*
+lexQuotedString lexes a quoted string, in which a backslash quotes the
+character after it. Quoted strings may not span lines.
+*/
+func lexQuotedString(l *lexer.Lexer) {
+	l.Next()
+
+	for {
+		if l.IsEOF() || isLineBreak(l.Peek()) {
+			l.Errorf("unterminated quoted string")
+			l.Ignore()
+			return
+		}
+
+		switch l.Next() {
+		case '"':
+			l.EmitWithTransform(TOKEN_QUOTED_STRING, Unquote)
+			return
+
+		case '\\':
+			if !l.IsEOF() && !isLineBreak(l.Peek()) {
+				l.Next()
+			}
+		}
+	}
+}
+
+/*
+lexComment lexes a parenthesized comment, as found in User-Agent and
+Via fields. Comments nest and may hold quoted pairs.
+*/
+func lexComment(l *lexer.Lexer) {
+	depth := 0
+
+	for {
+		if l.IsEOF() || isLineBreak(l.Peek()) {
+			l.Errorf("unterminated comment")
+			l.Ignore()
+			return
+		}
+
+		switch l.Next() {
+		case '(':
+			depth++
+
+		case ')':
+			depth--
+
+			if depth == 0 {
+				l.Emit(TOKEN_COMMENT)
+				return
+			}
+
+		case '\\':
+			if !l.IsEOF() && !isLineBreak(l.Peek()) {
+				l.Next()
+			}
+		}
+	}
+}
+
+/*
+lexBody emits the message body in chunks of at most BodyChunkSize bytes
+*/
+func lexBody(l *lexer.Lexer) lexer.LexFn {
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	l.Inc(BodyChunkSize)
+	l.Emit(TOKEN_BODY)
+
+	return lexBody
+}
+
+/*
+lexBadLine reports the rest of a start or header line as an error and
+carries on with the next header line
+*/
+func lexBadLine(l *lexer.Lexer, message string) lexer.LexFn {
+	for !l.IsEOF() && l.PeekCharacters(2) != "\r\n" && l.Peek() != '\n' {
+		l.Next()
+	}
+
+	l.Errorf("%s", message)
+	l.Ignore()
+
+	if acceptLineEnd(l) {
+		l.Emit(TOKEN_NEWLINE)
+	}
+
+	return lexFieldLine
+}
+
+/*
+lexVersion lexes an HTTP version such as HTTP/1.1, returning false if
+there is none at the current position
+*/
+func lexVersion(l *lexer.Lexer) bool {
+	version := l.PeekCharacters(8)
+
+	if len(version) != 8 || version[:5] != "HTTP/" || !isDigit(version[5]) || version[6] != '.' || !isDigit(version[7]) {
+		return false
+	}
+
+	l.Inc(8)
+	l.Emit(TOKEN_VERSION)
+
+	return true
+}
+
+func skipSpace(l *lexer.Lexer) bool {
+	count := l.AcceptClassRun(whitespace)
+	l.Ignore()
+
+	return count > 0
+}
+
+/*
+acceptLineEnd accepts CRLF or a bare LF
+*/
+func acceptLineEnd(l *lexer.Lexer) bool {
+	if l.PeekCharacters(2) == "\r\n" {
+		l.Inc(2)
+		return true
+	}
+
+	return l.Accept("\n")
+}
+
+func isLineBreak(ch rune) bool {
+	return ch == '\r' || ch == '\n'
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+/*
+statusCode gives a status code token its number as its value
+*/
+func statusCode(text string) interface{} {
+	code, _ := strconv.Atoi(text)
+	return code
+}
+
+/*
+Unquote is the TokenValueTransformer for quoted strings. It removes the
+quotes and the backslashes of quoted pairs.
+*/
+func Unquote(text string) interface{} {
+	text = text[1 : len(text)-1]
+
+	if strings.IndexByte(text, '\\') < 0 {
+		return text
+	}
+
+	result := make([]byte, 0, len(text))
+
+	for index := 0; index < len(text); index++ {
+		if text[index] == '\\' && index+1 < len(text) {
+			index++
+		}
+
+		result = append(result, text[index])
+	}
+
+	return string(result)
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_METHOD lexer.TokenType = iota + 1
+	TOKEN_TARGET
+	TOKEN_VERSION
+	TOKEN_STATUS_CODE
+	TOKEN_REASON
+	TOKEN_FIELD_NAME
+	TOKEN_COLON
+	TOKEN_TOKEN
+	TOKEN_SEPARATOR
+	TOKEN_QUOTED_STRING
+	TOKEN_COMMENT
+	TOKEN_TEXT
+	TOKEN_NEWLINE
+	TOKEN_HEADERS_END
+	TOKEN_BODY
+)
+
+/*
+Names holds the names of the HTTP token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_METHOD:        "METHOD",
+	TOKEN_TARGET:        "TARGET",
+	TOKEN_VERSION:       "VERSION",
+	TOKEN_STATUS_CODE:   "STATUS_CODE",
+	TOKEN_REASON:        "REASON",
+	TOKEN_FIELD_NAME:    "FIELD_NAME",
+	TOKEN_COLON:         "COLON",
+	TOKEN_TOKEN:         "TOKEN",
+	TOKEN_SEPARATOR:     "SEPARATOR",
+	TOKEN_QUOTED_STRING: "QUOTED_STRING",
+	TOKEN_COMMENT:       "COMMENT",
+	TOKEN_TEXT:          "TEXT",
+	TOKEN_NEWLINE:       "NEWLINE",
+	TOKEN_HEADERS_END:   "HEADERS_END",
+	TOKEN_BODY:          "BODY",
+}
+
+/*
+Categories maps the HTTP token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_METHOD:        highlight.CATEGORY_KEYWORD,
+	TOKEN_TARGET:        highlight.CATEGORY_STRING,
+	TOKEN_VERSION:       highlight.CATEGORY_KEYWORD,
+	TOKEN_STATUS_CODE:   highlight.CATEGORY_NUMBER,
+	TOKEN_REASON:        highlight.CATEGORY_STRING,
+	TOKEN_FIELD_NAME:    highlight.CATEGORY_IDENTIFIER,
+	TOKEN_COLON:         highlight.CATEGORY_PUNCTUATION,
+	TOKEN_TOKEN:         highlight.CATEGORY_LITERAL,
+	TOKEN_SEPARATOR:     highlight.CATEGORY_PUNCTUATION,
+	TOKEN_QUOTED_STRING: highlight.CATEGORY_STRING,
+	TOKEN_COMMENT:       highlight.CATEGORY_COMMENT,
+	TOKEN_TEXT:          highlight.CATEGORY_STRING,
+}
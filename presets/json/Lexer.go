@@ -0,0 +1,37 @@
+/*
+Package json is a ready-made lexer for JSON text as described in RFC
+8259. It doubles as the canonical example of writing state functions
+with the lexer package: one state per kind of token, each consuming its
+token, emitting it, and returning the state to continue in.
+
+	l := json.NewLexer("config.json", input)
+
+	for _, token := range l.Collect() {
+		...
+	}
+
+String tokens carry their decoded value in Value. Errors are reported
+through the lexer as usual and lexing resumes after the offending input,
+so a single pass finds every problem in a document.
+*/
+package json
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for JSON held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for JSON read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
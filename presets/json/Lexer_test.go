@@ -0,0 +1,143 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestTokens(t *testing.T) {
+	input := `{"k": [-0, 1.5e+10, 0.25, true, false, null]}`
+	want := `LEFT_BRACE "{"
+STRING "\"k\""
+COLON ":"
+LEFT_BRACKET "["
+NUMBER "-0"
+COMMA ","
+NUMBER "1.5e+10"
+COMMA ","
+NUMBER "0.25"
+COMMA ","
+TRUE "true"
+COMMA ","
+FALSE "false"
+COMMA ","
+NULL "null"
+RIGHT_BRACKET "]"
+RIGHT_BRACE "}"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestStringValues(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`"plain"`, "plain"},
+		{`"a\nb\tc"`, "a\nb\tc"},
+		{`"\"\\\/"`, `"\/`},
+		{`"caf\u00e9"`, "café"},
+		{`"\ud83d\ude00"`, "😀"},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if tokens[0].Type != TOKEN_STRING || tokens[0].Value != test.want {
+			t.Errorf("lexing %s: got %s with value %q, want STRING with value %q", test.input, Names.Name(tokens[0].Type), tokens[0].Value, test.want)
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input  string
+		tokens string
+		errors []string
+	}{
+		{
+			"[1, @, 2]",
+			`LEFT_BRACKET "["
+NUMBER "1"
+COMMA ","
+ERROR "unexpected character '@'"
+COMMA ","
+NUMBER "2"
+RIGHT_BRACKET "]"
+EOF ""
+`,
+			[]string{"test:1:5: unexpected character '@'"},
+		},
+		{
+			"[01, 1., -, 2]",
+			`LEFT_BRACKET "["
+ERROR "invalid number \"01\""
+COMMA ","
+ERROR "invalid number \"1.\""
+COMMA ","
+ERROR "invalid number \"-\""
+COMMA ","
+NUMBER "2"
+RIGHT_BRACKET "]"
+EOF ""
+`,
+			[]string{
+				`test:1:2: invalid number "01"`,
+				`test:1:6: invalid number "1."`,
+				`test:1:10: invalid number "-"`,
+			},
+		},
+		{
+			"[tru, 3]",
+			`LEFT_BRACKET "["
+ERROR "unexpected word \"tru\""
+COMMA ","
+NUMBER "3"
+RIGHT_BRACKET "]"
+EOF ""
+`,
+			[]string{`test:1:2: unexpected word "tru"`},
+		},
+		{
+			"\"abc\n1",
+			`ERROR "unterminated string"
+NUMBER "1"
+EOF ""
+`,
+			[]string{"test:1:1: unterminated string"},
+		},
+		{
+			`"a\qb" 1`,
+			`STRING "\"a\\qb\""
+NUMBER "1"
+EOF ""
+`,
+			[]string{"test:1:3: invalid escape sequence in string"},
+		},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input)
+
+		if got := lexertest.Format(Names, lexertest.Collect(t, l)); got != test.tokens {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.tokens)
+		}
+
+		diagnostics := l.Diagnostics()
+		if len(diagnostics) != len(test.errors) {
+			t.Errorf("lexing %q: got %d errors, want %d", test.input, len(diagnostics), len(test.errors))
+			continue
+		}
+
+		for index, diagnostic := range diagnostics {
+			if got := diagnostic.Error(); got != test.errors[index] {
+				t.Errorf("lexing %q: got error %q, want %q", test.input, got, test.errors[index])
+			}
+		}
+	}
+}
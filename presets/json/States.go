@@ -0,0 +1,187 @@
+package json
+
+import (
+	"fmt"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	whitespace  = lexer.NewCharClass(" \t\r\n")
+	digits      = lexer.DigitClass
+	letters     = lexer.LetterClass
+	hexDigits   = lexer.HexDigitClass
+	escapeChars = lexer.NewCharClass(`"\/bfnrt`)
+)
+
+func init() {
+	lexer.NameState("json.value", Start)
+	lexer.NameState("json.string", lexString)
+	lexer.NameState("json.number", lexNumber)
+	lexer.NameState("json.literal", lexLiteral)
+}
+
+/*
+Start is the state between tokens. It skips whitespace and decides
+which state lexes the next token from its first character.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(whitespace)
+	l.Ignore()
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	ch := l.Peek()
+
+	if tokenType, ok := punctuation[ch]; ok {
+		l.Next()
+		l.Emit(tokenType)
+		return Start
+	}
+
+	switch {
+	case ch == '"':
+		return lexString
+
+	case ch == '-' || digits.Contains(ch):
+		return lexNumber
+
+	case letters.Contains(ch):
+		return lexLiteral
+	}
+
+	l.Next()
+	l.Errorf("unexpected character %q", ch)
+	l.Ignore()
+
+	return Start
+}
+
+/*
+lexString lexes a string from its opening quote to its closing quote,
+checking escapes as it goes. A bad escape or control character is
+reported without ending the string. A string left open at the end of a
+line or the input is reported and lexing resumes on the next line.
+*/
+func lexString(l *lexer.Lexer) lexer.LexFn {
+	l.Next()
+
+	for {
+		if l.IsEOF() || l.IsNewline() {
+			l.Errorf("unterminated string")
+			l.Ignore()
+			return Start
+		}
+
+		switch ch := l.Next(); {
+		case ch == '"':
+			l.EmitWithTransform(TOKEN_STRING, Unquote)
+			return Start
+
+		case ch == '\\':
+			lexEscape(l)
+
+		case ch < 0x20:
+			reportAt(l, l.Pos-l.Width, "control character %q in string", ch)
+		}
+	}
+}
+
+/*
+lexEscape checks the escape sequence following a backslash
+*/
+func lexEscape(l *lexer.Lexer) {
+	start := l.Pos - 1
+
+	if l.AcceptClass(escapeChars) {
+		return
+	}
+
+	if !l.Accept("u") {
+		reportAt(l, start, "invalid escape sequence in string")
+		return
+	}
+
+	for count := 0; count < 4; count++ {
+		if !l.AcceptClass(hexDigits) {
+			reportAt(l, start, "invalid unicode escape in string")
+			return
+		}
+	}
+}
+
+/*
+lexNumber lexes a number following the JSON grammar: an optional minus
+sign, an integer part without leading zeros, and optional fraction and
+exponent parts. A malformed number is reported and skipped.
+*/
+func lexNumber(l *lexer.Lexer) lexer.LexFn {
+	l.Accept("-")
+
+	valid := true
+
+	if l.Accept("0") {
+		valid = !digits.Contains(l.Peek())
+	} else {
+		valid = l.AcceptClassRun(digits) > 0
+	}
+
+	if l.Accept(".") {
+		valid = l.AcceptClassRun(digits) > 0 && valid
+	}
+
+	if l.Accept("eE") {
+		l.Accept("+-")
+		valid = l.AcceptClassRun(digits) > 0 && valid
+	}
+
+	// Anything number-like left over belongs to the same bad number
+	if l.AcceptRun("0123456789.eE+-") > 0 || letters.Contains(l.Peek()) {
+		l.AcceptClassRun(lexer.IdentifierClass)
+		valid = false
+	}
+
+	if !valid {
+		l.Errorf("invalid number %q", l.CurrentInput())
+		l.Ignore()
+		return Start
+	}
+
+	l.Emit(TOKEN_NUMBER)
+	return Start
+}
+
+/*
+lexLiteral lexes true, false and null. Any other word is an error.
+*/
+func lexLiteral(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(lexer.IdentifierClass)
+
+	if tokenType, ok := literals.Lookup(l.CurrentInput()); ok {
+		l.Emit(tokenType)
+		return Start
+	}
+
+	l.Errorf("unexpected word %q", l.CurrentInput())
+	l.Ignore()
+
+	return Start
+}
+
+/*
+reportAt reports an error covering the input from start to the current
+position without emitting an error token, for problems inside a token
+that is still emitted
+*/
+func reportAt(l *lexer.Lexer, start int, format string, args ...interface{}) {
+	l.Report(lexer.LexError{
+		Message: fmt.Sprintf(format, args...),
+		Span: lexer.Span{
+			Start: l.PositionAt(start),
+			End:   l.PositionAt(l.Pos),
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package json
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_LEFT_BRACE lexer.TokenType = iota + 1
+	TOKEN_RIGHT_BRACE
+	TOKEN_LEFT_BRACKET
+	TOKEN_RIGHT_BRACKET
+	TOKEN_COLON
+	TOKEN_COMMA
+	TOKEN_STRING
+	TOKEN_NUMBER
+	TOKEN_TRUE
+	TOKEN_FALSE
+	TOKEN_NULL
+)
+
+/*
+Names holds the names of the JSON token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_LEFT_BRACE:    "LEFT_BRACE",
+	TOKEN_RIGHT_BRACE:   "RIGHT_BRACE",
+	TOKEN_LEFT_BRACKET:  "LEFT_BRACKET",
+	TOKEN_RIGHT_BRACKET: "RIGHT_BRACKET",
+	TOKEN_COLON:         "COLON",
+	TOKEN_COMMA:         "COMMA",
+	TOKEN_STRING:        "STRING",
+	TOKEN_NUMBER:        "NUMBER",
+	TOKEN_TRUE:          "TRUE",
+	TOKEN_FALSE:         "FALSE",
+	TOKEN_NULL:          "NULL",
+}
+
+/*
+Categories maps the JSON token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_LEFT_BRACE:    highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_BRACE:   highlight.CATEGORY_PUNCTUATION,
+	TOKEN_LEFT_BRACKET:  highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_BRACKET: highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COLON:         highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMA:         highlight.CATEGORY_PUNCTUATION,
+	TOKEN_STRING:        highlight.CATEGORY_STRING,
+	TOKEN_NUMBER:        highlight.CATEGORY_NUMBER,
+	TOKEN_TRUE:          highlight.CATEGORY_LITERAL,
+	TOKEN_FALSE:         highlight.CATEGORY_LITERAL,
+	TOKEN_NULL:          highlight.CATEGORY_LITERAL,
+}
+
+var punctuation = map[rune]lexer.TokenType{
+	'{': TOKEN_LEFT_BRACE,
+	'}': TOKEN_RIGHT_BRACE,
+	'[': TOKEN_LEFT_BRACKET,
+	']': TOKEN_RIGHT_BRACKET,
+	':': TOKEN_COLON,
+	',': TOKEN_COMMA,
+}
+
+var literals = lexer.NewKeywordMatcher(map[string]lexer.TokenType{
+	"true":  TOKEN_TRUE,
+	"false": TOKEN_FALSE,
+	"null":  TOKEN_NULL,
+})
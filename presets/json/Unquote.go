@@ -0,0 +1,109 @@
+package json
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+/*
+Unquote is the TokenValueTransformer for string tokens. It removes the
+quotes and decodes escape sequences, including UTF-16 surrogate pairs
+written as two \u escapes. Invalid escapes, already reported while
+lexing, are kept as written.
+*/
+func Unquote(text string) interface{} {
+	if len(text) >= 2 {
+		text = text[1 : len(text)-1]
+	}
+
+	if strings.IndexByte(text, '\\') < 0 {
+		return text
+	}
+
+	var result strings.Builder
+	result.Grow(len(text))
+
+	for len(text) > 0 {
+		index := strings.IndexByte(text, '\\')
+
+		if index < 0 || index+1 >= len(text) {
+			result.WriteString(text)
+			break
+		}
+
+		result.WriteString(text[:index])
+		text = text[index:]
+
+		ch, width := unescape(text)
+		if width == 0 {
+			result.WriteByte('\\')
+			text = text[1:]
+			continue
+		}
+
+		result.WriteRune(ch)
+		text = text[width:]
+	}
+
+	return result.String()
+}
+
+/*
+unescape decodes the escape sequence at the start of text, returning
+the rune and the number of bytes it used, or a width of 0 if the
+escape is invalid
+*/
+func unescape(text string) (rune, int) {
+	switch text[1] {
+	case '"', '\\', '/':
+		return rune(text[1]), 2
+
+	case 'b':
+		return '\b', 2
+
+	case 'f':
+		return '\f', 2
+
+	case 'n':
+		return '\n', 2
+
+	case 'r':
+		return '\r', 2
+
+	case 't':
+		return '\t', 2
+
+	case 'u':
+		first, ok := hex4(text[2:])
+		if !ok {
+			return 0, 0
+		}
+
+		if !utf16.IsSurrogate(first) {
+			return first, 6
+		}
+
+		if len(text) >= 12 && text[6] == '\\' && text[7] == 'u' {
+			if second, ok := hex4(text[8:]); ok {
+				if ch := utf16.DecodeRune(first, second); ch != utf8.RuneError {
+					return ch, 12
+				}
+			}
+		}
+
+		return utf8.RuneError, 6
+	}
+
+	return 0, 0
+}
+
+func hex4(text string) (rune, bool) {
+	if len(text) < 4 {
+		return 0, false
+	}
+
+	value, err := strconv.ParseUint(text[:4], 16, 32)
+	return rune(value), err == nil
+}
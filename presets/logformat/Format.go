@@ -0,0 +1,32 @@
+package logformat
+
+import (
+	"github.com/adampresley/lexer"
+)
+
+/*
+A Format is a log line format the lexer understands
+*/
+type Format int
+
+const (
+	// FORMAT_ACCESS is the Common Log Format and the Combined Log Format
+	// that extends it. Any other format made of bare fields, quoted
+	// fields and bracketed timestamps lexes as well.
+	FORMAT_ACCESS Format = iota
+
+	// FORMAT_LOGFMT is key=value pairs separated by spaces, where a key
+	// without a value is a flag
+	FORMAT_LOGFMT
+)
+
+/*
+Start returns the state that begins lexing a line in the format
+*/
+func (format Format) Start() lexer.LexFn {
+	if format == FORMAT_LOGFMT {
+		return LogfmtStart
+	}
+
+	return AccessStart
+}
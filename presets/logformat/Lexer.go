@@ -0,0 +1,44 @@
+/*
+Package logformat is a ready-made lexer for log lines in the Common and
+Combined access log formats written by Apache, nginx and most other web
+servers, and in logfmt, the key=value format used by many structured
+loggers:
+
+	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /a.gif HTTP/1.0" 200 2326 "-" "curl/8.0"
+
+	ts=2024-05-27T07:32:00Z level=info msg="request done" status=200 cached
+
+The format is chosen when the lexer is created:
+
+	l := logformat.NewReaderLexer("access.log", file, logformat.FORMAT_ACCESS)
+
+Lines are read as a stream and each ends with TOKEN_NEWLINE, so logs of
+any size can be lexed as they are written. A malformed line is reported
+and lexing resumes at the next line.
+
+Bracketed access log timestamps and logfmt values in RFC 3339 form are
+TOKEN_TIMESTAMP tokens with the parsed time.Time in Value. Quoted fields
+carry their unescaped contents in Value, and numbers carry an int64 or,
+failing that, a float64.
+*/
+package logformat
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for log lines held in memory
+*/
+func NewLexer(name string, input string, format Format, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, format.Start(), options...)
+}
+
+/*
+NewReaderLexer creates a lexer for log lines read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, format Format, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, format.Start(), options...)
+}
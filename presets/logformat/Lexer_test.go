@@ -0,0 +1,127 @@
+package logformat
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestAccessLine(t *testing.T) {
+	input := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /a\x41.gif HTTP/1.0" 200 2326 "-" "curl/8.0"` + "\n"
+	want := `FIELD "127.0.0.1"
+FIELD "-"
+FIELD "frank"
+TIMESTAMP "[10/Oct/2000:13:55:36 -0700]"
+QUOTED "\"GET /a\\x41.gif HTTP/1.0\""
+NUMBER "200"
+NUMBER "2326"
+QUOTED "\"-\""
+QUOTED "\"curl/8.0\""
+NEWLINE "\n"
+EOF ""
+`
+
+	tokens := lexertest.Collect(t, NewLexer("test", input, FORMAT_ACCESS))
+
+	if got := lexertest.Format(Names, tokens); got != want {
+		t.Fatalf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+
+	stamp := time.Date(2000, time.October, 10, 13, 55, 36, 0, time.FixedZone("", -7*60*60))
+	if value, ok := tokens[3].Value.(time.Time); !ok || !value.Equal(stamp) {
+		t.Errorf("got timestamp value %v, want %v", tokens[3].Value, stamp)
+	}
+
+	if tokens[4].Value != "GET /aA.gif HTTP/1.0" {
+		t.Errorf("got request value %q, want %q", tokens[4].Value, "GET /aA.gif HTTP/1.0")
+	}
+
+	if tokens[5].Value != int64(200) {
+		t.Errorf("got status value %#v, want 200", tokens[5].Value)
+	}
+}
+
+func TestAccessErrors(t *testing.T) {
+	input := "a [bad stamp] b\nc [open\nd \"open"
+	want := []string{
+		"test:1:3: invalid timestamp [bad stamp]",
+		"test:2:3: unterminated timestamp",
+		"test:3:3: unterminated quoted field",
+	}
+
+	l := NewLexer("test", input, FORMAT_ACCESS)
+	lexertest.Collect(t, l)
+
+	diagnostics := l.Diagnostics()
+	if len(diagnostics) != len(want) {
+		t.Fatalf("lexing %q: got errors %v, want %q", input, diagnostics, want)
+	}
+
+	for index, diagnostic := range diagnostics {
+		if got := diagnostic.Error(); got != want[index] {
+			t.Errorf("lexing %q: got error %q, want %q", input, got, want[index])
+		}
+	}
+}
+
+func TestLogfmtLine(t *testing.T) {
+	input := `ts=2024-05-27T07:32:00Z level=info msg="quote \"inside\"" n=-3 d=1.5 cached empty=` + "\n"
+	want := `KEY "ts"
+EQUALS "="
+TIMESTAMP "2024-05-27T07:32:00Z"
+KEY "level"
+EQUALS "="
+VALUE "info"
+KEY "msg"
+EQUALS "="
+QUOTED "\"quote \\\"inside\\\"\""
+KEY "n"
+EQUALS "="
+NUMBER "-3"
+KEY "d"
+EQUALS "="
+NUMBER "1.5"
+KEY "cached"
+KEY "empty"
+EQUALS "="
+NEWLINE "\n"
+EOF ""
+`
+
+	tokens := lexertest.Collect(t, NewLexer("test", input, FORMAT_LOGFMT))
+
+	if got := lexertest.Format(Names, tokens); got != want {
+		t.Fatalf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+
+	values := map[int]interface{}{
+		2:  time.Date(2024, time.May, 27, 7, 32, 0, 0, time.UTC),
+		8:  `quote "inside"`,
+		11: int64(-3),
+		14: 1.5,
+	}
+
+	for index, want := range values {
+		if got := tokens[index].Value; got != want {
+			t.Errorf("%s %q: got value %#v, want %#v", Names.Name(tokens[index].Type), tokens[index].Text, got, want)
+		}
+	}
+}
+
+func TestStreamedLines(t *testing.T) {
+	line := "level=info msg=\"" + strings.Repeat("x", 100) + "\"\n"
+	input := strings.Repeat(line, 500)
+
+	count := 0
+	for _, token := range lexertest.Collect(t, NewReaderLexer("test", strings.NewReader(input), FORMAT_LOGFMT)) {
+		if token.Type == TOKEN_QUOTED {
+			count++
+		}
+	}
+
+	if count != 500 {
+		t.Errorf("got %d quoted values, want 500", count)
+	}
+}
@@ -0,0 +1,280 @@
+package logformat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+AccessTimeLayout is the layout of the bracketed timestamp in access
+log lines
+*/
+const AccessTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+var (
+	blanks     = lexer.NewCharClass(" \t")
+	digits     = lexer.DigitClass
+	fieldChars = lexer.NewCharClass(" \t\r\n").Not()
+	keyChars   = lexer.NewCharClass(" \t\r\n=\"").Not()
+	stampChars = lexer.NewCharClass("]\r\n").Not()
+)
+
+func init() {
+	lexer.NameState("logformat.access", AccessStart)
+	lexer.NameState("logformat.logfmt", LogfmtStart)
+	lexer.NameState("logformat.logfmtValue", lexLogfmtValue)
+}
+
+/*
+AccessStart is the state between fields of an access log line
+*/
+func AccessStart(l *lexer.Lexer) lexer.LexFn {
+	if next, ok := lexBoundary(l, AccessStart); ok {
+		return next
+	}
+
+	switch l.Peek() {
+	case '[':
+		lexAccessTimestamp(l)
+
+	case '"':
+		lexQuoted(l, unescapeAccess)
+
+	case lexer.EOF:
+		// A NUL byte, as lexBoundary lexed the end of the input, which
+		// no class holds
+		l.Next()
+		l.Errorf("unexpected character %q", rune(0))
+		l.Ignore()
+
+	default:
+		l.AcceptClassRun(fieldChars)
+		emitField(l, TOKEN_FIELD)
+	}
+
+	return AccessStart
+}
+
+/*
+LogfmtStart is the state between pairs of a logfmt line
+*/
+func LogfmtStart(l *lexer.Lexer) lexer.LexFn {
+	if next, ok := lexBoundary(l, LogfmtStart); ok {
+		return next
+	}
+
+	if l.AcceptClassRun(keyChars) == 0 {
+		ch := l.Next()
+		l.AcceptClassRun(fieldChars)
+		l.Errorf("unexpected %q where a key was expected", ch)
+		l.Ignore()
+
+		return LogfmtStart
+	}
+
+	l.Emit(TOKEN_KEY)
+
+	if l.Accept("=") {
+		l.Emit(TOKEN_EQUALS)
+		return lexLogfmtValue
+	}
+
+	return LogfmtStart
+}
+
+/*
+lexLogfmtValue lexes the value after a key's equals sign, which may be
+quoted, bare, or missing altogether
+*/
+func lexLogfmtValue(l *lexer.Lexer) lexer.LexFn {
+	switch {
+	case l.IsEOF() || blanks.Contains(l.Peek()) || l.Peek() == '\r' || l.Peek() == '\n':
+
+	case l.Peek() == '"':
+		lexQuoted(l, unescapeLogfmt)
+
+	default:
+		l.AcceptClassRun(fieldChars)
+
+		if stamp, err := time.Parse(time.RFC3339Nano, l.CurrentInput()); err == nil {
+			l.EmitWithTransform(TOKEN_TIMESTAMP, func(string) interface{} { return stamp })
+			break
+		}
+
+		emitField(l, TOKEN_VALUE)
+	}
+
+	return LogfmtStart
+}
+
+/*
+lexBoundary skips the blanks between fields and lexes the end of a
+line or of the input. It returns the state to continue in and true if
+it reached either, or false if a field follows.
+*/
+func lexBoundary(l *lexer.Lexer, current lexer.LexFn) (lexer.LexFn, bool) {
+	l.AcceptClassRun(blanks)
+	l.Ignore()
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil, true
+	}
+
+	if l.Accept("\r") || l.Peek() == '\n' {
+		l.Accept("\n")
+		l.Emit(TOKEN_NEWLINE)
+		return current, true
+	}
+
+	return nil, false
+}
+
+/*
+lexAccessTimestamp lexes a bracketed timestamp, reporting one that does
+not follow AccessTimeLayout
+*/
+func lexAccessTimestamp(l *lexer.Lexer) {
+	l.Next()
+	l.AcceptClassRun(stampChars)
+
+	if !l.Accept("]") {
+		l.Errorf("unterminated timestamp")
+		l.Ignore()
+		return
+	}
+
+	text := l.CurrentInput()
+	stamp, err := time.Parse(AccessTimeLayout, text[1:len(text)-1])
+
+	if err != nil {
+		reportAt(l, l.Start, "invalid timestamp %s", text)
+		l.Emit(TOKEN_TIMESTAMP)
+		return
+	}
+
+	l.EmitWithTransform(TOKEN_TIMESTAMP, func(string) interface{} { return stamp })
+}
+
+/*
+lexQuoted lexes a double quoted field, in which a backslash escapes the
+character after it, and gives it the value computed by unescape
+*/
+func lexQuoted(l *lexer.Lexer, unescape lexer.TokenValueTransformer) {
+	l.Next()
+
+	for {
+		if ch := l.Peek(); l.IsEOF() || ch == '\r' || ch == '\n' {
+			l.Errorf("unterminated quoted field")
+			l.Ignore()
+			return
+		}
+
+		switch l.Next() {
+		case '"':
+			l.EmitWithTransform(TOKEN_QUOTED, unescape)
+			return
+
+		case '\\':
+			if ch := l.Peek(); ch != '\r' && ch != '\n' {
+				l.Next()
+			}
+		}
+	}
+}
+
+/*
+emitField emits a bare field as a number if it is one, and as
+tokenType otherwise
+*/
+func emitField(l *lexer.Lexer, tokenType lexer.TokenType) {
+	text := l.CurrentInput()
+
+	if text != "" && (digits.Contains(rune(text[0])) || (len(text) > 1 && text[0] == '-' && digits.Contains(rune(text[1])))) {
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			l.EmitWithTransform(TOKEN_NUMBER, number)
+			return
+		}
+	}
+
+	l.Emit(tokenType)
+}
+
+/*
+number gives a number token an int64 value, or a float64 one if it is
+not an integer
+*/
+func number(text string) interface{} {
+	if value, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return value
+	}
+
+	value, _ := strconv.ParseFloat(text, 64)
+	return value
+}
+
+/*
+unescapeAccess removes the quotes of an access log field and resolves
+the escapes web servers write: \" and \\, and \xHH for other bytes
+*/
+func unescapeAccess(text string) interface{} {
+	text = text[1 : len(text)-1]
+
+	if strings.IndexByte(text, '\\') < 0 {
+		return text
+	}
+
+	result := make([]byte, 0, len(text))
+
+	for index := 0; index < len(text); index++ {
+		ch := text[index]
+
+		if ch == '\\' && index+1 < len(text) {
+			index++
+			ch = text[index]
+
+			if ch == 'x' && index+2 < len(text) {
+				if value, err := strconv.ParseUint(text[index+1:index+3], 16, 8); err == nil {
+					ch = byte(value)
+					index += 2
+				}
+			}
+		}
+
+		result = append(result, ch)
+	}
+
+	return string(result)
+}
+
+/*
+unescapeLogfmt removes the quotes of a logfmt value and resolves its Go
+style escapes. A value with an invalid escape keeps its text as written
+between the quotes.
+*/
+func unescapeLogfmt(text string) interface{} {
+	if value, err := strconv.Unquote(text); err == nil {
+		return value
+	}
+
+	return text[1 : len(text)-1]
+}
+
+/*
+reportAt reports an error covering the input from start to the current
+position without emitting an error token, for problems inside a token
+that is still emitted
+*/
+func reportAt(l *lexer.Lexer, start int, format string, args ...interface{}) {
+	l.Report(lexer.LexError{
+		Message: fmt.Sprintf(format, args...),
+		Span: lexer.Span{
+			Start: l.PositionAt(start),
+			End:   l.PositionAt(l.Pos),
+		},
+	})
+}
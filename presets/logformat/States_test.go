@@ -0,0 +1,40 @@
+package logformat
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestAccessNULBytes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			"\x00",
+			`ERROR "unexpected character '\\x00'"
+EOF ""
+`,
+		},
+		{
+			"1.2.3.4 a\x00b \"x\x00\"\n",
+			`FIELD "1.2.3.4"
+FIELD "a"
+ERROR "unexpected character '\\x00'"
+FIELD "b"
+QUOTED "\"x\x00\""
+NEWLINE "\n"
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input, FORMAT_ACCESS)))
+
+		if got != test.want {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+		}
+	}
+}
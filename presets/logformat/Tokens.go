@@ -0,0 +1,44 @@
+package logformat
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_FIELD lexer.TokenType = iota + 1
+	TOKEN_NUMBER
+	TOKEN_TIMESTAMP
+	TOKEN_QUOTED
+	TOKEN_KEY
+	TOKEN_EQUALS
+	TOKEN_VALUE
+	TOKEN_NEWLINE
+)
+
+/*
+Names holds the names of the log token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_FIELD:     "FIELD",
+	TOKEN_NUMBER:    "NUMBER",
+	TOKEN_TIMESTAMP: "TIMESTAMP",
+	TOKEN_QUOTED:    "QUOTED",
+	TOKEN_KEY:       "KEY",
+	TOKEN_EQUALS:    "EQUALS",
+	TOKEN_VALUE:     "VALUE",
+	TOKEN_NEWLINE:   "NEWLINE",
+}
+
+/*
+Categories maps the log token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_FIELD:     highlight.CATEGORY_IDENTIFIER,
+	TOKEN_NUMBER:    highlight.CATEGORY_NUMBER,
+	TOKEN_TIMESTAMP: highlight.CATEGORY_LITERAL,
+	TOKEN_QUOTED:    highlight.CATEGORY_STRING,
+	TOKEN_KEY:       highlight.CATEGORY_KEYWORD,
+	TOKEN_EQUALS:    highlight.CATEGORY_OPERATOR,
+	TOKEN_VALUE:     highlight.CATEGORY_IDENTIFIER,
+}
@@ -0,0 +1,42 @@
+/*
+Package markdown is a ready-made lexer for the inline constructs of
+Markdown: emphasis, strong emphasis, code spans, links, images, and
+backslash escapes. It is meant for static site tools and linters that
+find blocks themselves, or only deal with single-line text such as
+titles, and need the inline structure of each block's text:
+
+	l := markdown.NewLexer("title", "Using *the* `lexer` package, see [the docs](/docs \"Docs\")")
+
+Text between the constructs is TOKEN_TEXT. Emphasis, strong emphasis
+and links are emitted as an opening token, the tokens of their
+contents, and a closing token, so the stream nests like the HTML it
+would be rendered to. The closing token of a link is followed by
+TOKEN_LINK_TARGET, whose Value is a LinkTarget.
+
+Whether a * or [ opens anything depends on what comes later: a * with
+no matching * after it, or a [ not followed eventually by ](target), is
+plain text. The lexer decides by looking ahead with Lexer.Mark and
+rewinding with Lexer.Rewind, so each token is still emitted once, in
+order. Open scopes are kept on the mode stack and every state is
+registered with NameState, so a suspended lexer can be checkpointed
+between any two tokens. The rules follow CommonMark for the common
+cases, including the flanking rules that keep snake_case_words from
+turning into emphasis, without implementing its full delimiter
+algorithm.
+
+The flanking rules look at the character before a delimiter, so the
+lexer needs its input in memory and there is no reader based
+constructor. Inline text comes in blocks small enough for that.
+*/
+package markdown
+
+import (
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for the inline text of a Markdown block
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
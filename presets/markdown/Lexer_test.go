@@ -0,0 +1,123 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestInlineConstructs(t *testing.T) {
+	input := "Using *the* `lexer` **bold _em_** see [the *docs*](/docs \"Docs\") ![img](a.png)\\* x\\\ny"
+	want := `TEXT "Using "
+EMPHASIS_OPEN "*"
+TEXT "the"
+EMPHASIS_CLOSE "*"
+TEXT " "
+CODE "` + "`lexer`" + `"
+TEXT " "
+STRONG_OPEN "**"
+TEXT "bold "
+EMPHASIS_OPEN "_"
+TEXT "em"
+EMPHASIS_CLOSE "_"
+STRONG_CLOSE "**"
+TEXT " see "
+LINK_OPEN "["
+TEXT "the "
+EMPHASIS_OPEN "*"
+TEXT "docs"
+EMPHASIS_CLOSE "*"
+LINK_CLOSE "]"
+LINK_TARGET "(/docs \"Docs\")"
+TEXT " "
+IMAGE_OPEN "!["
+TEXT "img"
+LINK_CLOSE "]"
+LINK_TARGET "(a.png)"
+ESCAPE "\\*"
+TEXT " x"
+LINE_BREAK "\\\n"
+TEXT "y"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestUnmatchedDelimitersAreText(t *testing.T) {
+	tests := []string{
+		"snake_case_words",
+		"a * lone star",
+		"[not a link]",
+		"`open code",
+		"*open emphasis",
+	}
+
+	for _, input := range tests {
+		for _, token := range lexertest.Collect(t, NewLexer("test", input)) {
+			if token.Type != TOKEN_TEXT && token.Type != lexer.TOKEN_EOF {
+				t.Errorf("lexing %q: got %s %q, want only text", input, Names.Name(token.Type), token.Text)
+			}
+		}
+	}
+}
+
+func TestValues(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"`code`", "code"},
+		{"``a ` b``", "a ` b"},
+		{`\*`, "*"},
+		{`[a](/docs "Docs")`, LinkTarget{Destination: "/docs", Title: "Docs"}},
+		{`[a](<x y>)`, LinkTarget{Destination: "x y"}},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+		last := tokens[len(tokens)-2]
+
+		if last.Value != test.want {
+			t.Errorf("lexing %q: got %s with value %#v, want value %#v", test.input, Names.Name(last.Type), last.Value, test.want)
+		}
+	}
+}
+
+func TestCheckpointBetweenTokens(t *testing.T) {
+	input := "a *b [c **d**](/e) `f`* g"
+	want := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input)))
+
+	for stop := 1; stop < strings.Count(want, "\n"); stop++ {
+		var tokens []lexer.Token
+
+		l := NewLexer("test", input)
+		l.RunWith(func(token lexer.Token) {
+			tokens = append(tokens, token)
+
+			if len(tokens) == stop {
+				l.Suspend()
+			}
+		})
+
+		checkpoint, err := l.Checkpoint()
+		if err != nil {
+			t.Fatalf("checkpoint after %d tokens: %v", stop, err)
+		}
+
+		resumed, err := lexer.ResumeLexer(checkpoint, input)
+		if err != nil {
+			t.Fatalf("resuming after %d tokens: %v", stop, err)
+		}
+
+		tokens = append(tokens, lexertest.Collect(t, resumed)...)
+
+		if got := lexertest.Format(Names, tokens); got != want {
+			t.Errorf("resuming after %d tokens:\ngot:\n%s\nwant:\n%s", stop, got, want)
+		}
+	}
+}
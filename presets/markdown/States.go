@@ -0,0 +1,480 @@
+package markdown
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	textChars   = lexer.NewCharClass("\\`*_[]!").Not()
+	punctuation = lexer.NewCharClass("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~")
+)
+
+func init() {
+	lexer.NameState("markdown.inline", Start)
+	lexer.NameState("markdown.emphasisStar", lexEmphasisStar)
+	lexer.NameState("markdown.emphasisUnderscore", lexEmphasisUnderscore)
+	lexer.NameState("markdown.strongStar", lexStrongStar)
+	lexer.NameState("markdown.strongUnderscore", lexStrongUnderscore)
+	lexer.NameState("markdown.linkText", lexLinkText)
+	lexer.NameState("markdown.bracket", lexBracket)
+}
+
+/*
+A scope is what the inline state is inside of: the delimiter that ends
+it, the token emitted for that delimiter, and the state lexing it
+*/
+type scope struct {
+	closer    string
+	closeType lexer.TokenType
+	self      lexer.LexFn
+}
+
+/*
+Start is the state for inline text outside of any emphasis or link
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	return lexInline(l, scope{self: Start})
+}
+
+func lexEmphasisStar(l *lexer.Lexer) lexer.LexFn {
+	return lexInline(l, scope{"*", TOKEN_EMPHASIS_CLOSE, lexEmphasisStar})
+}
+
+func lexEmphasisUnderscore(l *lexer.Lexer) lexer.LexFn {
+	return lexInline(l, scope{"_", TOKEN_EMPHASIS_CLOSE, lexEmphasisUnderscore})
+}
+
+func lexStrongStar(l *lexer.Lexer) lexer.LexFn {
+	return lexInline(l, scope{"**", TOKEN_STRONG_CLOSE, lexStrongStar})
+}
+
+func lexStrongUnderscore(l *lexer.Lexer) lexer.LexFn {
+	return lexInline(l, scope{"__", TOKEN_STRONG_CLOSE, lexStrongUnderscore})
+}
+
+func lexLinkText(l *lexer.Lexer) lexer.LexFn {
+	return lexInline(l, scope{"]", TOKEN_LINK_CLOSE, lexLinkText})
+}
+
+/*
+lexBracket is the state inside a [ that does not start a link, so that
+its ] is not taken for the end of an enclosing link
+*/
+func lexBracket(l *lexer.Lexer) lexer.LexFn {
+	return lexInline(l, scope{"]", TOKEN_TEXT, lexBracket})
+}
+
+/*
+lexInline lexes one token of inline text inside scope s. Openers push
+the current state and closers pop it, so nesting is kept on the mode
+stack.
+*/
+func lexInline(l *lexer.Lexer, s scope) lexer.LexFn {
+	if l.IsEOF() {
+		if s.closer == "" {
+			l.Emit(lexer.TOKEN_EOF)
+			return nil
+		}
+
+		// A scope is only opened once its closer has been seen ahead, but
+		// a scope inside it may still have used that closer up. Close it
+		// here so that opening and closing tokens always pair up.
+		if s.closeType != TOKEN_TEXT {
+			l.Emit(s.closeType)
+		}
+
+		return l.PopState()
+	}
+
+	switch ch := l.Peek(); {
+	case ch == '\\':
+		lexEscape(l)
+
+	case ch == '`':
+		lexCodeSpan(l)
+
+	case ch == '*' || ch == '_':
+		return lexDelimiterRun(l, s)
+
+	case ch == '[' || l.PeekCharacters(2) == "![":
+		return lexOpenBracket(l, s)
+
+	case ch == ']' && s.closer == "]":
+		l.Next()
+
+		if s.closeType != TOKEN_LINK_CLOSE {
+			l.Emit(TOKEN_TEXT)
+			return l.PopState()
+		}
+
+		l.Emit(TOKEN_LINK_CLOSE)
+		scanTarget(l)
+		l.EmitWithTransform(TOKEN_LINK_TARGET, parseTarget)
+
+		return l.PopState()
+
+	default:
+		if l.AcceptClassRun(textChars) == 0 {
+			l.Next()
+		}
+
+		l.Emit(TOKEN_TEXT)
+	}
+
+	return s.self
+}
+
+/*
+lexEscape lexes a backslash and what follows it: an escaped punctuation
+character, a hard line break, or otherwise a literal backslash
+*/
+func lexEscape(l *lexer.Lexer) {
+	l.Next()
+
+	switch {
+	case l.AcceptClass(punctuation):
+		l.EmitWithTransform(TOKEN_ESCAPE, unescapeCharacter)
+
+	case l.Accept("\n") || l.PeekCharacters(2) == "\r\n":
+		l.Accept("\r")
+		l.Accept("\n")
+		l.Emit(TOKEN_LINE_BREAK)
+
+	default:
+		l.Emit(TOKEN_TEXT)
+	}
+}
+
+/*
+lexCodeSpan lexes a code span, which ends at the next run of exactly as
+many backticks as started it. A run of backticks without a match is
+plain text.
+*/
+func lexCodeSpan(l *lexer.Lexer) {
+	count := l.AcceptRun("`")
+	opened := l.Mark()
+
+	if closeCodeSpan(l, count) {
+		l.EmitWithTransform(TOKEN_CODE, codeContent)
+		return
+	}
+
+	l.Rewind(opened)
+	l.Emit(TOKEN_TEXT)
+}
+
+/*
+lexDelimiterRun lexes a run of * or _, which closes the current scope,
+opens emphasis or strong emphasis if a matching closer follows, or is
+otherwise plain text
+*/
+func lexDelimiterRun(l *lexer.Lexer, s scope) lexer.LexFn {
+	ch := l.Peek()
+	before := previousRune(l)
+	count := l.AcceptRun(string(ch))
+	opens, closes := flanking(ch, before, nextRune(l))
+
+	if closes && l.CurrentInput() == s.closer {
+		l.Emit(s.closeType)
+		return l.PopState()
+	}
+
+	if opens && count <= 2 {
+		opened := l.Mark()
+		found := findCloser(l, ch, count)
+		l.Rewind(opened)
+
+		if found {
+			return openEmphasis(l, s, ch, count)
+		}
+	}
+
+	l.Emit(TOKEN_TEXT)
+	return s.self
+}
+
+func openEmphasis(l *lexer.Lexer, s scope, ch rune, count int) lexer.LexFn {
+	l.PushState(s.self)
+
+	switch {
+	case count == 1 && ch == '*':
+		l.Emit(TOKEN_EMPHASIS_OPEN)
+		return lexEmphasisStar
+
+	case count == 1:
+		l.Emit(TOKEN_EMPHASIS_OPEN)
+		return lexEmphasisUnderscore
+
+	case ch == '*':
+		l.Emit(TOKEN_STRONG_OPEN)
+		return lexStrongStar
+	}
+
+	l.Emit(TOKEN_STRONG_OPEN)
+	return lexStrongUnderscore
+}
+
+/*
+lexOpenBracket lexes [ or ![, which opens a link or image if a ]
+followed by a link target comes later. Otherwise the bracket is plain
+text, and the ! of an image is text on its own.
+*/
+func lexOpenBracket(l *lexer.Lexer, s scope) lexer.LexFn {
+	bracket := l.Mark()
+	image := l.Accept("!")
+	l.Next()
+
+	opened := l.Mark()
+	found := findLinkEnd(l)
+	l.Rewind(opened)
+
+	switch {
+	case found && image:
+		l.Emit(TOKEN_IMAGE_OPEN)
+
+	case found:
+		l.Emit(TOKEN_LINK_OPEN)
+
+	case image:
+		l.Rewind(bracket)
+		l.Next()
+		l.Emit(TOKEN_TEXT)
+		return s.self
+
+	default:
+		l.Emit(TOKEN_TEXT)
+		l.PushState(s.self)
+		return lexBracket
+	}
+
+	l.PushState(s.self)
+	return lexLinkText
+}
+
+/*
+findCloser looks ahead for a run of count delimiters ch that can close
+emphasis. It does not look past the end of the enclosing brackets, if
+any, and skips escapes and code spans.
+*/
+func findCloser(l *lexer.Lexer, ch rune, count int) bool {
+	depth := 0
+
+	for !l.IsEOF() {
+		switch next := l.Peek(); {
+		case next == '\\':
+			l.Next()
+			l.Next()
+
+		case next == '`':
+			skipCodeSpan(l)
+
+		case next == '[':
+			l.Next()
+			depth++
+
+		case next == ']':
+			if depth == 0 {
+				return false
+			}
+
+			l.Next()
+			depth--
+
+		case next == ch:
+			before := previousRune(l)
+			run := l.AcceptRun(string(ch))
+
+			if _, closes := flanking(ch, before, nextRune(l)); closes && run == count {
+				return true
+			}
+
+		default:
+			l.Next()
+		}
+	}
+
+	return false
+}
+
+/*
+findLinkEnd looks ahead from just inside a [ for its matching ]
+followed straight away by a valid link target
+*/
+func findLinkEnd(l *lexer.Lexer) bool {
+	depth := 1
+
+	for !l.IsEOF() {
+		switch l.Peek() {
+		case '\\':
+			l.Next()
+			l.Next()
+
+		case '`':
+			skipCodeSpan(l)
+
+		case '[':
+			l.Next()
+			depth++
+
+		case ']':
+			l.Next()
+
+			if depth--; depth == 0 {
+				return l.Peek() == '(' && scanTarget(l)
+			}
+
+		default:
+			l.Next()
+		}
+	}
+
+	return false
+}
+
+/*
+scanTarget consumes a link target: a destination, optionally in angle
+brackets, and an optional title, all in parentheses. It returns false
+if the text at the current position is not a valid target.
+*/
+func scanTarget(l *lexer.Lexer) bool {
+	if !l.Accept("(") {
+		return false
+	}
+
+	l.AcceptRun(" \t\r\n")
+
+	if l.Accept("<") {
+		for !l.Accept(">") {
+			switch l.Next() {
+			case '\\':
+				l.Next()
+
+			case '\n', '<', lexer.EOF:
+				return false
+			}
+		}
+	} else {
+		depth := 0
+
+		for !l.IsEOF() {
+			ch := l.Peek()
+
+			if ch == ')' && depth == 0 || ch <= ' ' {
+				break
+			}
+
+			switch l.Next() {
+			case '\\':
+				l.Next()
+
+			case '(':
+				depth++
+
+			case ')':
+				depth--
+			}
+		}
+
+		if depth != 0 {
+			return false
+		}
+	}
+
+	if l.AcceptRun(" \t\r\n") > 0 {
+		switch ch := l.Peek(); ch {
+		case '"', '\'':
+			if !l.AcceptDelimited(ch, ch, '\\') {
+				return false
+			}
+
+		case '(':
+			if !l.AcceptDelimited('(', ')', '\\') {
+				return false
+			}
+		}
+
+		l.AcceptRun(" \t\r\n")
+	}
+
+	return l.Accept(")")
+}
+
+/*
+closeCodeSpan consumes a code span's contents and closing run of count
+backticks, returning false if there is none
+*/
+func closeCodeSpan(l *lexer.Lexer, count int) bool {
+	for !l.IsEOF() {
+		if l.Peek() != '`' {
+			l.Next()
+			continue
+		}
+
+		if l.AcceptRun("`") == count {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+skipCodeSpan skips a code span during a look ahead, or just its opening
+backticks if it is not closed
+*/
+func skipCodeSpan(l *lexer.Lexer) {
+	count := l.AcceptRun("`")
+	opened := l.Mark()
+
+	if !closeCodeSpan(l, count) {
+		l.Rewind(opened)
+	}
+}
+
+/*
+flanking applies CommonMark's rules for whether a delimiter run
+between before and after can open or close emphasis. Underscores are
+stricter than asterisks so that they do not act inside words.
+*/
+func flanking(ch, before, after rune) (opens, closes bool) {
+	left := !unicode.IsSpace(after) && (!isPunctuation(after) || unicode.IsSpace(before) || isPunctuation(before))
+	right := !unicode.IsSpace(before) && (!isPunctuation(before) || unicode.IsSpace(after) || isPunctuation(after))
+
+	if ch == '*' {
+		return left, right
+	}
+
+	return left && (!right || isPunctuation(before)), right && (!left || isPunctuation(after))
+}
+
+func isPunctuation(ch rune) bool {
+	return unicode.IsPunct(ch) || unicode.IsSymbol(ch)
+}
+
+/*
+previousRune returns the character before the current position, or a
+space at the start of the input, which the flanking rules treat alike
+*/
+func previousRune(l *lexer.Lexer) rune {
+	if l.Pos == 0 {
+		return ' '
+	}
+
+	ch, _ := utf8.DecodeLastRuneInString(l.Input[:l.Pos])
+	return ch
+}
+
+/*
+nextRune returns the character at the current position, or a space at
+the end of the input
+*/
+func nextRune(l *lexer.Lexer) rune {
+	if l.IsEOF() {
+		return ' '
+	}
+
+	return l.Peek()
+}
@@ -0,0 +1,55 @@
+package markdown
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_TEXT lexer.TokenType = iota + 1
+	TOKEN_ESCAPE
+	TOKEN_LINE_BREAK
+	TOKEN_CODE
+	TOKEN_EMPHASIS_OPEN
+	TOKEN_EMPHASIS_CLOSE
+	TOKEN_STRONG_OPEN
+	TOKEN_STRONG_CLOSE
+	TOKEN_LINK_OPEN
+	TOKEN_IMAGE_OPEN
+	TOKEN_LINK_CLOSE
+	TOKEN_LINK_TARGET
+)
+
+/*
+Names holds the names of the Markdown token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_TEXT:           "TEXT",
+	TOKEN_ESCAPE:         "ESCAPE",
+	TOKEN_LINE_BREAK:     "LINE_BREAK",
+	TOKEN_CODE:           "CODE",
+	TOKEN_EMPHASIS_OPEN:  "EMPHASIS_OPEN",
+	TOKEN_EMPHASIS_CLOSE: "EMPHASIS_CLOSE",
+	TOKEN_STRONG_OPEN:    "STRONG_OPEN",
+	TOKEN_STRONG_CLOSE:   "STRONG_CLOSE",
+	TOKEN_LINK_OPEN:      "LINK_OPEN",
+	TOKEN_IMAGE_OPEN:     "IMAGE_OPEN",
+	TOKEN_LINK_CLOSE:     "LINK_CLOSE",
+	TOKEN_LINK_TARGET:    "LINK_TARGET",
+}
+
+/*
+Categories maps the Markdown token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_ESCAPE:         highlight.CATEGORY_LITERAL,
+	TOKEN_CODE:           highlight.CATEGORY_STRING,
+	TOKEN_EMPHASIS_OPEN:  highlight.CATEGORY_PUNCTUATION,
+	TOKEN_EMPHASIS_CLOSE: highlight.CATEGORY_PUNCTUATION,
+	TOKEN_STRONG_OPEN:    highlight.CATEGORY_PUNCTUATION,
+	TOKEN_STRONG_CLOSE:   highlight.CATEGORY_PUNCTUATION,
+	TOKEN_LINK_OPEN:      highlight.CATEGORY_PUNCTUATION,
+	TOKEN_IMAGE_OPEN:     highlight.CATEGORY_PUNCTUATION,
+	TOKEN_LINK_CLOSE:     highlight.CATEGORY_PUNCTUATION,
+	TOKEN_LINK_TARGET:    highlight.CATEGORY_STRING,
+}
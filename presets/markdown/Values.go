@@ -0,0 +1,102 @@
+package markdown
+
+import (
+	"strings"
+)
+
+/*
+LinkTarget is the value of a TOKEN_LINK_TARGET token, with backslash
+escapes resolved in both fields
+*/
+type LinkTarget struct {
+	Destination string
+	Title       string
+}
+
+/*
+parseTarget builds the LinkTarget of a link target already checked by
+scanTarget
+*/
+func parseTarget(text string) interface{} {
+	text = strings.TrimPrefix(text, "(")
+	text = strings.TrimSuffix(text, ")")
+	text = strings.TrimSpace(text)
+
+	var destination string
+
+	if strings.HasPrefix(text, "<") {
+		end := 1
+
+		for end < len(text) && text[end] != '>' {
+			if text[end] == '\\' {
+				end++
+			}
+
+			end++
+		}
+
+		if end >= len(text) {
+			end = len(text) - 1
+		}
+
+		destination, text = text[1:end], text[end+1:]
+	} else if end := strings.IndexAny(text, " \t\r\n"); end >= 0 {
+		destination, text = text[:end], text[end:]
+	} else {
+		destination, text = text, ""
+	}
+
+	target := LinkTarget{Destination: unescape(destination)}
+
+	if title := strings.TrimSpace(text); len(title) >= 2 {
+		target.Title = unescape(title[1 : len(title)-1])
+	}
+
+	return target
+}
+
+/*
+codeContent is the value of a code span: its text without the backtick
+runs, with line endings turned into spaces and, if there is a space at
+both ends, one space stripped from each
+*/
+func codeContent(text string) interface{} {
+	text = strings.Trim(text, "`")
+	text = strings.ReplaceAll(text, "\r\n", " ")
+	text = strings.ReplaceAll(text, "\n", " ")
+
+	if len(text) >= 2 && text[0] == ' ' && text[len(text)-1] == ' ' && strings.Trim(text, " ") != "" {
+		text = text[1 : len(text)-1]
+	}
+
+	return text
+}
+
+/*
+unescapeCharacter is the value of an escape: the character after the
+backslash
+*/
+func unescapeCharacter(text string) interface{} {
+	return text[1:]
+}
+
+/*
+unescape resolves the backslash escapes of ASCII punctuation in text
+*/
+func unescape(text string) string {
+	if strings.IndexByte(text, '\\') < 0 {
+		return text
+	}
+
+	var result strings.Builder
+
+	for index := 0; index < len(text); index++ {
+		if text[index] == '\\' && index+1 < len(text) && punctuation.Contains(rune(text[index+1])) {
+			index++
+		}
+
+		result.WriteByte(text[index])
+	}
+
+	return result.String()
+}
@@ -0,0 +1,75 @@
+/*
+Package querystring is a ready-made lexer for URL query strings and
+application/x-www-form-urlencoded bodies:
+
+	q=go+lexer&page=2&filter=caf%C3%A9
+
+Each pair is a TOKEN_KEY, then optionally a TOKEN_EQUALS and a
+TOKEN_VALUE, and pairs are separated by TOKEN_PAIR_SEP. Keys and values
+carry their decoded text as their value, with + decoded to a space and
+percent escapes to their bytes.
+
+Unlike net/url, the lexer is strict, which suits validating input that
+other software will read. Everything net/url silently accepts or drops
+is reported: malformed percent escapes, characters that must be
+escaped, an = inside a value, semicolons used as separators, empty keys
+and pairs, and escapes that decode to invalid UTF-8. Each problem is a
+diagnostic on the token it was found in, so the tokens still cover the
+whole input. Parse turns a query into url.Values, failing on the first
+problem.
+*/
+package querystring
+
+import (
+	"io"
+	"net/url"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for a query string held in memory. The query
+should not include the leading ?.
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for a query string or form body read
+from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
+
+/*
+Parse decodes a query string into url.Values, returning the first
+problem the lexer found as an error. A key without an = gets an empty
+value, as with net/url.
+*/
+func Parse(query string) (url.Values, error) {
+	l := NewLexer("query", query)
+	tokens := l.Collect()
+
+	if diagnostics := l.Diagnostics(); len(diagnostics) > 0 {
+		return nil, diagnostics[0]
+	}
+
+	values := url.Values{}
+	key := ""
+
+	for _, token := range tokens {
+		switch token.Type {
+		case TOKEN_KEY:
+			key = token.Value.(string)
+			values.Add(key, "")
+
+		case TOKEN_VALUE:
+			added := values[key]
+			added[len(added)-1] = token.Value.(string)
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,82 @@
+package querystring
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestTokens(t *testing.T) {
+	input := "q=go+lexer&page=2&filter=caf%C3%A9&flag"
+	want := `KEY "q"
+EQUALS "="
+VALUE "go+lexer"
+PAIR_SEP "&"
+KEY "page"
+EQUALS "="
+VALUE "2"
+PAIR_SEP "&"
+KEY "filter"
+EQUALS "="
+VALUE "caf%C3%A9"
+PAIR_SEP "&"
+KEY "flag"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestStrictness(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"a=%zz", []string{"test:1:3: invalid percent escape"}},
+		{"a b=1", []string{"test:1:2: character ' ' must be escaped"}},
+		{"a=1=2", []string{"test:1:4: = in a value must be escaped"}},
+		{"a=1;b", []string{"test:1:4: invalid semicolon separator in query"}},
+		{"&&a", []string{"test:1:1: empty pair", "test:1:2: empty pair"}},
+		{"=1", []string{"test:1:1: empty key"}},
+		{"a=%ff", []string{"test:1:3: escapes decode to invalid UTF-8"}},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input)
+		lexertest.Collect(t, l)
+
+		diagnostics := l.Diagnostics()
+		if len(diagnostics) != len(test.want) {
+			t.Errorf("lexing %q: got errors %v, want %q", test.input, diagnostics, test.want)
+			continue
+		}
+
+		for index, diagnostic := range diagnostics {
+			if diagnostic.Error() != test.want[index] {
+				t.Errorf("lexing %q: got error %q, want %q", test.input, diagnostic.Error(), test.want[index])
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	values, err := Parse("q=go+lexer&filter=caf%C3%A9&tag=a&tag=b&flag")
+	want := url.Values{
+		"q":      {"go lexer"},
+		"filter": {"café"},
+		"tag":    {"a", "b"},
+		"flag":   {""},
+	}
+
+	if err != nil || !reflect.DeepEqual(values, want) {
+		t.Errorf("Parse: got %v, %v, want %v", values, err, want)
+	}
+
+	if _, err := Parse("a=1;b=2"); err == nil || err.Error() != "query:1:4: invalid semicolon separator in query" {
+		t.Errorf("Parse of a semicolon separated query: got error %v", err)
+	}
+}
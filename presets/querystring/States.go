@@ -0,0 +1,159 @@
+package querystring
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+literal holds the characters that may appear unescaped in a key or
+value: the unreserved characters and those sub-delimiters of RFC 3986
+that have no meaning in a form encoding, with + standing for a space
+*/
+var literal = lexer.NewCharClass("-._~!$'()*+,:@/?").AddRange('a', 'z').AddRange('A', 'Z').AddRange('0', '9')
+
+func init() {
+	lexer.NameState("querystring.start", Start)
+	lexer.NameState("querystring.afterKey", lexAfterKey)
+	lexer.NameState("querystring.value", lexValue)
+	lexer.NameState("querystring.afterPair", lexAfterPair)
+}
+
+/*
+Start is the state at the beginning of a pair
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	switch l.Peek() {
+	case '&':
+		reportAt(l, l.Pos, "empty pair")
+		return lexAfterPair
+
+	case '=':
+		reportAt(l, l.Pos, "empty key")
+		return lexAfterKey
+	}
+
+	lexComponent(l, "=&")
+	l.EmitWithTransform(TOKEN_KEY, Unescape)
+
+	return lexAfterKey
+}
+
+/*
+lexAfterKey lexes the = between a key and its value, if there is one
+*/
+func lexAfterKey(l *lexer.Lexer) lexer.LexFn {
+	if !l.Accept("=") {
+		return lexAfterPair
+	}
+
+	l.Emit(TOKEN_EQUALS)
+	return lexValue
+}
+
+/*
+lexValue lexes a value, which may be empty. An empty value is not
+emitted.
+*/
+func lexValue(l *lexer.Lexer) lexer.LexFn {
+	if lexComponent(l, "&") {
+		l.EmitWithTransform(TOKEN_VALUE, Unescape)
+	}
+
+	return lexAfterPair
+}
+
+/*
+lexAfterPair lexes the separator after a pair. A separator must be
+followed by another pair.
+*/
+func lexAfterPair(l *lexer.Lexer) lexer.LexFn {
+	if l.IsEOF() {
+		return Start
+	}
+
+	l.Next()
+	l.Emit(TOKEN_PAIR_SEP)
+
+	if l.IsEOF() {
+		reportAt(l, l.Pos, "empty pair")
+	}
+
+	return Start
+}
+
+/*
+lexComponent consumes a key or value up to one of the characters in
+stop, reporting what a strict reader would reject, and returns true if
+it consumed anything
+*/
+func lexComponent(l *lexer.Lexer, stop string) bool {
+	start := l.Pos
+
+	for !l.IsEOF() && !containsRune(stop, l.Peek()) {
+		at := l.Pos
+
+		switch ch := l.Next(); {
+		case ch == '%':
+			if next := l.PeekCharacters(2); len(next) == 2 && isHex(next[0]) && isHex(next[1]) {
+				l.Inc(2)
+			} else {
+				reportAt(l, at, "invalid percent escape")
+			}
+
+		case ch == ';':
+			reportAt(l, at, "invalid semicolon separator in query")
+
+		case ch == '=':
+			reportAt(l, at, "= in a value must be escaped")
+
+		case !literal.Contains(ch):
+			reportAt(l, at, "character %q must be escaped", ch)
+		}
+	}
+
+	if l.Pos == start {
+		return false
+	}
+
+	if decoded := unescape(l.Input[start:l.Pos]); !utf8.ValidString(decoded) {
+		reportAt(l, start, "escapes decode to invalid UTF-8")
+	}
+
+	return true
+}
+
+/*
+reportAt reports a problem with the text from start to the current
+position, without ending the token being lexed
+*/
+func reportAt(l *lexer.Lexer, start int, format string, args ...interface{}) {
+	l.Report(lexer.LexError{
+		Message: fmt.Sprintf(format, args...),
+		Span: lexer.Span{
+			Start: l.PositionAt(start),
+			End:   l.PositionAt(l.Pos),
+		},
+	})
+}
+
+func containsRune(set string, ch rune) bool {
+	for _, member := range set {
+		if member == ch {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isHex(ch byte) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
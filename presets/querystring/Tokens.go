@@ -0,0 +1,33 @@
+package querystring
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_KEY lexer.TokenType = iota + 1
+	TOKEN_EQUALS
+	TOKEN_VALUE
+	TOKEN_PAIR_SEP
+)
+
+/*
+Names holds the names of the query string token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_KEY:      "KEY",
+	TOKEN_EQUALS:   "EQUALS",
+	TOKEN_VALUE:    "VALUE",
+	TOKEN_PAIR_SEP: "PAIR_SEP",
+}
+
+/*
+Categories maps the query string token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_KEY:      highlight.CATEGORY_IDENTIFIER,
+	TOKEN_EQUALS:   highlight.CATEGORY_OPERATOR,
+	TOKEN_VALUE:    highlight.CATEGORY_STRING,
+	TOKEN_PAIR_SEP: highlight.CATEGORY_PUNCTUATION,
+}
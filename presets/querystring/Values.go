@@ -0,0 +1,50 @@
+package querystring
+
+import (
+	"strings"
+)
+
+/*
+Unescape is the TokenValueTransformer for keys and values. It decodes +
+to a space and percent escapes to their bytes. A malformed escape, which
+the lexer reports, is kept as written.
+*/
+func Unescape(text string) interface{} {
+	return unescape(text)
+}
+
+func unescape(text string) string {
+	if strings.IndexAny(text, "%+") < 0 {
+		return text
+	}
+
+	result := make([]byte, 0, len(text))
+
+	for index := 0; index < len(text); index++ {
+		switch ch := text[index]; {
+		case ch == '+':
+			result = append(result, ' ')
+
+		case ch == '%' && index+2 < len(text) && isHex(text[index+1]) && isHex(text[index+2]):
+			result = append(result, unhex(text[index+1])<<4|unhex(text[index+2]))
+			index += 2
+
+		default:
+			result = append(result, ch)
+		}
+	}
+
+	return string(result)
+}
+
+func unhex(ch byte) byte {
+	switch {
+	case ch >= 'a':
+		return ch - 'a' + 10
+
+	case ch >= 'A':
+		return ch - 'A' + 10
+	}
+
+	return ch - '0'
+}
@@ -0,0 +1,43 @@
+/*
+Package sexpr is a ready-made lexer for S-expressions, as written in
+Lisp, Scheme and the many small scripting languages built on them:
+
+	; Comments run to the end of the line
+	(define (square x) (* x x))
+	(display "4² is ")
+	`(result ,(square 4) ,@rest)
+	'(1 2.5 -3/4 #t #\a . tail)
+	#| Block comments #| nest |# |#
+
+Parentheses and square brackets, symbols, strings, numbers, booleans,
+characters and vectors are recognized, along with the quote ', the
+quasiquote `, the unquote , and ,@ markers, and #; which comments out
+the datum after it.
+
+Token values are decoded: strings have their escapes resolved, numbers
+are int64, *big.Int for integers too large for int64, float64 or
+*big.Rat for ratios, booleans are bool and characters are rune.
+Anything that is not a number is a symbol, so + and ... are symbols,
+as are names such as list->vector.
+*/
+package sexpr
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for S-expressions held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for S-expressions read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
@@ -0,0 +1,111 @@
+package sexpr
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestTokens(t *testing.T) {
+	input := "; square\n(define (square x) (* x x))\n`(r ,(sq 4) ,@rest)\n'(a . tail) #(1 2) [b]\n#| outer #| nested |# |# #;(skipped) list->vector ..."
+	want := `COMMENT "; square"
+LEFT_PAREN "("
+SYMBOL "define"
+LEFT_PAREN "("
+SYMBOL "square"
+SYMBOL "x"
+RIGHT_PAREN ")"
+LEFT_PAREN "("
+SYMBOL "*"
+SYMBOL "x"
+SYMBOL "x"
+RIGHT_PAREN ")"
+RIGHT_PAREN ")"
+QUASIQUOTE "` + "`" + `"
+LEFT_PAREN "("
+SYMBOL "r"
+UNQUOTE ","
+LEFT_PAREN "("
+SYMBOL "sq"
+NUMBER "4"
+RIGHT_PAREN ")"
+UNQUOTE_SPLICING ",@"
+SYMBOL "rest"
+RIGHT_PAREN ")"
+QUOTE "'"
+LEFT_PAREN "("
+SYMBOL "a"
+DOT "."
+SYMBOL "tail"
+RIGHT_PAREN ")"
+VECTOR_START "#("
+NUMBER "1"
+NUMBER "2"
+RIGHT_PAREN ")"
+LEFT_BRACKET "["
+SYMBOL "b"
+RIGHT_BRACKET "]"
+COMMENT "#| outer #| nested |# |#"
+DATUM_COMMENT "#;"
+LEFT_PAREN "("
+SYMBOL "skipped"
+RIGHT_PAREN ")"
+SYMBOL "list->vector"
+SYMBOL "..."
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestValues(t *testing.T) {
+	huge, _ := new(big.Int).SetString("99999999999999999999", 10)
+
+	tests := []struct {
+		input string
+		want  any
+	}{
+		{"42", int64(42)},
+		{"-7", int64(-7)},
+		{"99999999999999999999", huge},
+		{"2.5", 2.5},
+		{"-3/4", big.NewRat(-3, 4)},
+		{"#t", true},
+		{"#f", false},
+		{`#\a`, 'a'},
+		{`#\space`, ' '},
+		{`#\x41`, 'A'},
+		{`"4² \"is\""`, `4² "is"`},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if len(tokens) != 2 || !reflect.DeepEqual(tokens[0].Value, test.want) {
+			t.Errorf("lexing %q: got %v, want a single token with value %#v", test.input, tokens, test.want)
+		}
+	}
+}
+
+func TestUnterminated(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`(display "open`, "test:1:10: unterminated string"},
+		{"#| outer #| nested |#", "test:1:1: unterminated block comment"},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input)
+		lexertest.Collect(t, l)
+
+		if diagnostics := l.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Error() != test.want {
+			t.Errorf("lexing %q: got errors %v, want %q", test.input, diagnostics, test.want)
+		}
+	}
+}
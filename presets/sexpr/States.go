@@ -0,0 +1,303 @@
+package sexpr
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	whitespace = lexer.WhitespaceClass
+	hexDigits  = lexer.HexDigitClass
+	lineChars  = lexer.NewCharClass("\n").Not()
+
+	// Characters that may appear in symbols and numbers: everything but
+	// whitespace and the characters that start or end other tokens
+	atomChars = lexer.NewCharClass("()[]\";'`,").AddTable(unicode.White_Space).Not()
+
+	simpleEscapes = lexer.NewCharClass(`abtnr0\"`)
+)
+
+var punctuation = map[rune]lexer.TokenType{
+	'(':  TOKEN_LEFT_PAREN,
+	')':  TOKEN_RIGHT_PAREN,
+	'[':  TOKEN_LEFT_BRACKET,
+	']':  TOKEN_RIGHT_BRACKET,
+	'\'': TOKEN_QUOTE,
+	'`':  TOKEN_QUASIQUOTE,
+}
+
+func init() {
+	lexer.NameState("sexpr.start", Start)
+	lexer.NameState("sexpr.atom", lexAtom)
+	lexer.NameState("sexpr.hash", lexHash)
+	lexer.NameState("sexpr.string", lexString)
+	lexer.NameState("sexpr.lineComment", lexLineComment)
+	lexer.NameState("sexpr.blockComment", lexBlockComment)
+}
+
+/*
+Start is the state between tokens. It skips whitespace and decides
+which state lexes the next token from its first character.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(whitespace)
+	l.Ignore()
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	ch := l.Peek()
+
+	switch ch {
+	case ';':
+		return lexLineComment
+
+	case '"':
+		return lexString
+
+	case '#':
+		return lexHash
+
+	case ',':
+		l.Next()
+
+		if l.Accept("@") {
+			l.Emit(TOKEN_UNQUOTE_SPLICING)
+		} else {
+			l.Emit(TOKEN_UNQUOTE)
+		}
+
+		return Start
+
+	case lexer.EOF:
+		// A NUL byte, as the end of the input was checked above
+		l.Next()
+		l.Errorf("unexpected character %q", rune(0))
+		l.Ignore()
+
+		return Start
+	}
+
+	if tokenType, ok := punctuation[ch]; ok {
+		l.Next()
+		l.Emit(tokenType)
+		return Start
+	}
+
+	return lexAtom
+}
+
+/*
+lexAtom lexes a symbol, a number, or the dot of a dotted pair. An atom
+runs up to the next delimiter and is a number if it reads as one.
+*/
+func lexAtom(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(atomChars)
+	text := l.CurrentInput()
+
+	switch {
+	case text == ".":
+		l.Emit(TOKEN_DOT)
+
+	case isNumber(text):
+		if ParseNumber(text) == nil {
+			reportAt(l, l.Start, "ratio %s has a zero denominator", text)
+		}
+
+		l.EmitWithTransform(TOKEN_NUMBER, ParseNumber)
+
+	default:
+		l.Emit(TOKEN_SYMBOL)
+	}
+
+	return Start
+}
+
+/*
+lexHash lexes the syntax introduced by #: booleans, characters, vector
+openers, datum comments and block comments
+*/
+func lexHash(l *lexer.Lexer) lexer.LexFn {
+	switch l.PeekCharacters(2) {
+	case "#|":
+		return lexBlockComment
+
+	case "#;":
+		l.Inc(2)
+		l.Emit(TOKEN_DATUM_COMMENT)
+		return Start
+
+	case "#(":
+		l.Inc(2)
+		l.Emit(TOKEN_VECTOR_START)
+		return Start
+
+	case `#\`:
+		return lexCharacter(l)
+	}
+
+	l.Next()
+	l.AcceptClassRun(atomChars)
+
+	switch l.CurrentInput() {
+	case "#t", "#true", "#f", "#false":
+		l.EmitWithTransform(TOKEN_BOOLEAN, boolean)
+
+	default:
+		l.Errorf("unknown syntax %s", l.CurrentInput())
+		l.Ignore()
+	}
+
+	return Start
+}
+
+/*
+lexCharacter lexes a character such as #\a, #\space or #\x3bb. The
+character after the backslash is taken even if it is a delimiter, so
+#\( is the open parenthesis character.
+*/
+func lexCharacter(l *lexer.Lexer) lexer.LexFn {
+	l.Inc(2)
+
+	if l.IsEOF() {
+		l.Errorf("missing character after #\\")
+		l.Ignore()
+		return Start
+	}
+
+	if atomChars.Contains(l.Next()) {
+		l.AcceptClassRun(atomChars)
+	}
+
+	if _, ok := parseCharacter(l.CurrentInput()); !ok {
+		reportAt(l, l.Start, "unknown character name %s", l.CurrentInput())
+	}
+
+	l.EmitWithTransform(TOKEN_CHARACTER, Character)
+	return Start
+}
+
+/*
+lexString lexes a string, which may span lines
+*/
+func lexString(l *lexer.Lexer) lexer.LexFn {
+	l.Next()
+
+	for {
+		if l.IsEOF() {
+			l.Errorf("unterminated string")
+			l.Ignore()
+			return Start
+		}
+
+		switch l.Next() {
+		case '"':
+			l.EmitWithTransform(TOKEN_STRING, Unquote)
+			return Start
+
+		case '\\':
+			lexEscape(l)
+		}
+	}
+}
+
+/*
+lexEscape checks the escape sequence after a backslash. Besides the
+usual single character escapes these are \x followed by hex digits and
+a semicolon, and a backslash at the end of a line, which joins it to
+the next.
+*/
+func lexEscape(l *lexer.Lexer) {
+	start := l.Pos - 1
+
+	if l.IsEOF() {
+		return
+	}
+
+	switch ch := l.Next(); {
+	case simpleEscapes.Contains(ch) || ch == '\n':
+
+	case ch == 'x':
+		if l.AcceptClassRun(hexDigits) == 0 || !l.Accept(";") {
+			reportAt(l, start, "invalid \\x escape sequence, expected hex digits and ;")
+		}
+
+	case ch == ' ' || ch == '\t' || ch == '\r':
+		l.AcceptRun(" \t\r")
+
+		if !l.Accept("\n") {
+			reportAt(l, start, "unknown escape sequence \\%c", ch)
+		}
+
+	default:
+		reportAt(l, start, "unknown escape sequence \\%c", ch)
+	}
+}
+
+/*
+lexLineComment lexes a comment from ; to the end of the line
+*/
+func lexLineComment(l *lexer.Lexer) lexer.LexFn {
+	// lineChars does not hold NUL bytes, as no class does, so they are
+	// taken one at a time
+	for l.AcceptClassRun(lineChars) > 0 || l.AtNUL() {
+		if l.AtNUL() {
+			l.Next()
+		}
+	}
+
+	l.Emit(TOKEN_COMMENT)
+
+	return Start
+}
+
+/*
+lexBlockComment lexes a #| |# comment, which may hold further block
+comments
+*/
+func lexBlockComment(l *lexer.Lexer) lexer.LexFn {
+	l.Inc(2)
+	depth := 1
+
+	for depth > 0 {
+		if l.IsEOF() {
+			l.Errorf("unterminated block comment")
+			l.Ignore()
+			return Start
+		}
+
+		switch l.PeekCharacters(2) {
+		case "|#":
+			l.Inc(2)
+			depth--
+
+		case "#|":
+			l.Inc(2)
+			depth++
+
+		default:
+			l.Next()
+		}
+	}
+
+	l.Emit(TOKEN_COMMENT)
+	return Start
+}
+
+/*
+reportAt reports a problem within the token being lexed, which is still
+emitted
+*/
+func reportAt(l *lexer.Lexer, start int, format string, args ...interface{}) {
+	l.Report(lexer.LexError{
+		Message: fmt.Sprintf(format, args...),
+		Span: lexer.Span{
+			Start: l.PositionAt(start),
+			End:   l.PositionAt(l.Pos),
+		},
+	})
+}
@@ -0,0 +1,42 @@
+package sexpr
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestNULBytes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			"\x00",
+			`ERROR "unexpected character '\\x00'"
+EOF ""
+`,
+		},
+		{
+			"(a\x00b \"s\x00\") ; c\x00d\n1",
+			`LEFT_PAREN "("
+SYMBOL "a"
+ERROR "unexpected character '\\x00'"
+SYMBOL "b"
+STRING "\"s\x00\""
+RIGHT_PAREN ")"
+COMMENT "; c\x00d"
+NUMBER "1"
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input)))
+
+		if got != test.want {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package sexpr
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_LEFT_PAREN lexer.TokenType = iota + 1
+	TOKEN_RIGHT_PAREN
+	TOKEN_LEFT_BRACKET
+	TOKEN_RIGHT_BRACKET
+	TOKEN_VECTOR_START
+	TOKEN_SYMBOL
+	TOKEN_STRING
+	TOKEN_NUMBER
+	TOKEN_BOOLEAN
+	TOKEN_CHARACTER
+	TOKEN_QUOTE
+	TOKEN_QUASIQUOTE
+	TOKEN_UNQUOTE
+	TOKEN_UNQUOTE_SPLICING
+	TOKEN_DOT
+	TOKEN_DATUM_COMMENT
+	TOKEN_COMMENT
+)
+
+/*
+Names holds the names of the S-expression token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_LEFT_PAREN:       "LEFT_PAREN",
+	TOKEN_RIGHT_PAREN:      "RIGHT_PAREN",
+	TOKEN_LEFT_BRACKET:     "LEFT_BRACKET",
+	TOKEN_RIGHT_BRACKET:    "RIGHT_BRACKET",
+	TOKEN_VECTOR_START:     "VECTOR_START",
+	TOKEN_SYMBOL:           "SYMBOL",
+	TOKEN_STRING:           "STRING",
+	TOKEN_NUMBER:           "NUMBER",
+	TOKEN_BOOLEAN:          "BOOLEAN",
+	TOKEN_CHARACTER:        "CHARACTER",
+	TOKEN_QUOTE:            "QUOTE",
+	TOKEN_QUASIQUOTE:       "QUASIQUOTE",
+	TOKEN_UNQUOTE:          "UNQUOTE",
+	TOKEN_UNQUOTE_SPLICING: "UNQUOTE_SPLICING",
+	TOKEN_DOT:              "DOT",
+	TOKEN_DATUM_COMMENT:    "DATUM_COMMENT",
+	TOKEN_COMMENT:          "COMMENT",
+}
+
+/*
+Categories maps the S-expression token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_LEFT_PAREN:       highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_PAREN:      highlight.CATEGORY_PUNCTUATION,
+	TOKEN_LEFT_BRACKET:     highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_BRACKET:    highlight.CATEGORY_PUNCTUATION,
+	TOKEN_VECTOR_START:     highlight.CATEGORY_PUNCTUATION,
+	TOKEN_SYMBOL:           highlight.CATEGORY_IDENTIFIER,
+	TOKEN_STRING:           highlight.CATEGORY_STRING,
+	TOKEN_NUMBER:           highlight.CATEGORY_NUMBER,
+	TOKEN_BOOLEAN:          highlight.CATEGORY_LITERAL,
+	TOKEN_CHARACTER:        highlight.CATEGORY_STRING,
+	TOKEN_QUOTE:            highlight.CATEGORY_OPERATOR,
+	TOKEN_QUASIQUOTE:       highlight.CATEGORY_OPERATOR,
+	TOKEN_UNQUOTE:          highlight.CATEGORY_OPERATOR,
+	TOKEN_UNQUOTE_SPLICING: highlight.CATEGORY_OPERATOR,
+	TOKEN_DOT:              highlight.CATEGORY_PUNCTUATION,
+	TOKEN_DATUM_COMMENT:    highlight.CATEGORY_COMMENT,
+	TOKEN_COMMENT:          highlight.CATEGORY_COMMENT,
+}
@@ -0,0 +1,202 @@
+package sexpr
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var escapes = map[byte]byte{
+	'a':  '\a',
+	'b':  '\b',
+	't':  '\t',
+	'n':  '\n',
+	'r':  '\r',
+	'0':  0,
+	'\\': '\\',
+	'"':  '"',
+}
+
+var characterNames = map[string]rune{
+	"alarm":     '\a',
+	"backspace": '\b',
+	"delete":    0x7f,
+	"escape":    0x1b,
+	"linefeed":  '\n',
+	"newline":   '\n',
+	"nul":       0,
+	"null":      0,
+	"return":    '\r',
+	"space":     ' ',
+	"tab":       '\t',
+}
+
+/*
+isNumber returns true if text is an integer such as -42, a decimal such
+as 2.5, .5 or 1e10, or a ratio such as 3/4
+*/
+func isNumber(text string) bool {
+	text = trimSign(text)
+
+	if slash := strings.IndexByte(text, '/'); slash >= 0 {
+		return isDigits(text[:slash]) && isDigits(text[slash+1:])
+	}
+
+	if e := strings.IndexAny(text, "eE"); e >= 0 {
+		if !isDigits(trimSign(text[e+1:])) {
+			return false
+		}
+
+		text = text[:e]
+	}
+
+	whole, fraction := text, ""
+
+	if dot := strings.IndexByte(text, '.'); dot >= 0 {
+		whole, fraction = text[:dot], text[dot+1:]
+	}
+
+	return (whole == "" || isDigits(whole)) && (fraction == "" || isDigits(fraction)) && whole+fraction != ""
+}
+
+func trimSign(text string) string {
+	if strings.HasPrefix(text, "+") || strings.HasPrefix(text, "-") {
+		return text[1:]
+	}
+
+	return text
+}
+
+func isDigits(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	for index := 0; index < len(text); index++ {
+		if text[index] < '0' || text[index] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+ParseNumber is the TokenValueTransformer for numbers. Integers are
+int64, or *big.Int if they do not fit, decimals are float64, and ratios
+are *big.Rat. A ratio with a zero denominator has no value.
+*/
+func ParseNumber(text string) interface{} {
+	if strings.IndexByte(text, '/') >= 0 {
+		if value, ok := new(big.Rat).SetString(text); ok {
+			return value
+		}
+
+		return nil
+	}
+
+	if strings.ContainsAny(text, ".eE") {
+		value, _ := strconv.ParseFloat(text, 64)
+		return value
+	}
+
+	if value, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return value
+	}
+
+	value, _ := new(big.Int).SetString(text, 10)
+	return value
+}
+
+func boolean(text string) interface{} {
+	return text == "#t" || text == "#true"
+}
+
+/*
+Character is the TokenValueTransformer for characters. It returns the
+character as a rune, or the Unicode replacement character for an
+unknown character name.
+*/
+func Character(text string) interface{} {
+	if ch, ok := parseCharacter(text); ok {
+		return ch
+	}
+
+	return unicode.ReplacementChar
+}
+
+func parseCharacter(text string) (rune, bool) {
+	body := text[2:]
+
+	if utf8.RuneCountInString(body) == 1 {
+		ch, _ := utf8.DecodeRuneInString(body)
+		return ch, true
+	}
+
+	if ch, ok := characterNames[body]; ok {
+		return ch, true
+	}
+
+	if body[0] == 'x' {
+		if code, err := strconv.ParseUint(body[1:], 16, 32); err == nil && utf8.ValidRune(rune(code)) {
+			return rune(code), true
+		}
+	}
+
+	return 0, false
+}
+
+/*
+Unquote is the TokenValueTransformer for strings. It removes the quotes
+and resolves escape sequences. Invalid escapes, which were reported
+when lexing, are kept as written.
+*/
+func Unquote(text string) interface{} {
+	text = text[1 : len(text)-1]
+
+	if strings.IndexByte(text, '\\') < 0 {
+		return text
+	}
+
+	var result strings.Builder
+	result.Grow(len(text))
+
+	for index := 0; index < len(text); index++ {
+		if text[index] != '\\' || index+1 == len(text) {
+			result.WriteByte(text[index])
+			continue
+		}
+
+		ch := text[index+1]
+
+		if replacement, ok := escapes[ch]; ok {
+			result.WriteByte(replacement)
+			index++
+			continue
+		}
+
+		if ch == 'x' {
+			if end := strings.IndexByte(text[index:], ';'); end > 2 {
+				if code, err := strconv.ParseUint(text[index+2:index+end], 16, 32); err == nil && utf8.ValidRune(rune(code)) {
+					result.WriteRune(rune(code))
+					index += end
+					continue
+				}
+			}
+		}
+
+		// A line continuation drops the line break and the whitespace
+		// around it
+		if rest := strings.TrimLeft(text[index+1:], " \t\r"); strings.HasPrefix(rest, "\n") {
+			rest = strings.TrimLeft(rest[1:], " \t")
+			index = len(text) - len(rest) - 1
+			continue
+		}
+
+		result.WriteByte('\\')
+	}
+
+	return result.String()
+}
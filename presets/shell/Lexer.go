@@ -0,0 +1,51 @@
+/*
+Package shell is a ready-made lexer splitting POSIX shell command lines
+into words and operators, for programs that accept commands in a shell
+syntax without running a shell:
+
+	l := shell.NewLexer("command", `grep -n "$pattern" *.go | sort > 'out file' 2>&1`)
+
+	for _, token := range l.Collect() {
+		...
+	}
+
+Each word is a single TOKEN_WORD however many quoted and unquoted parts
+it is made of. Its Value holds the word after quote removal: single and
+double quotes are dropped and backslash escapes resolved. Parameter
+expansions, command substitutions and backquotes are kept as written in
+Value, to be expanded by the caller if at all, but their contents are
+lexed so that quotes and parentheses inside them do not end the word
+early.
+
+Quotes and substitutions nest to any depth, as in
+
+	echo "today is $(date "+%A $(echo "(day)")")"
+
+and are tracked with the lexer's mode stack: each nested quote or
+substitution pushes the state to return to when it closes.
+
+Operators, a number directly before a redirection (TOKEN_IO_NUMBER),
+newlines and comments are tokens of their own. Here-document bodies are
+not recognized and are lexed as ordinary lines.
+*/
+package shell
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for a command line or script held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for a script read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
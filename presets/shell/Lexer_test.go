@@ -0,0 +1,100 @@
+package shell
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestTokens(t *testing.T) {
+	input := `grep -n "$p" *.go | sort > 'out file' 2>&1 && echo a\ b || x; # note` + "\nnext"
+	want := `WORD "grep"
+WORD "-n"
+WORD "\"$p\""
+WORD "*.go"
+OPERATOR "|"
+WORD "sort"
+OPERATOR ">"
+WORD "'out file'"
+IO_NUMBER "2"
+OPERATOR ">&"
+WORD "1"
+OPERATOR "&&"
+WORD "echo"
+WORD "a\\ b"
+OPERATOR "||"
+WORD "x"
+OPERATOR ";"
+COMMENT "# note"
+NEWLINE "\n"
+WORD "next"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestOperators(t *testing.T) {
+	input := "a;b&c<<d>>e<&f>|g"
+	want := `WORD "a"
+OPERATOR ";"
+WORD "b"
+OPERATOR "&"
+WORD "c"
+OPERATOR "<<"
+WORD "d"
+OPERATOR ">>"
+WORD "e"
+OPERATOR "<&"
+WORD "f"
+OPERATOR ">|"
+WORD "g"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestQuoteRemoval(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`a"b"'c'\"d`, `abc"d`},
+		{`'a\b'`, `a\b`},
+		{`"a\nb\"c\$d"`, `a\nb"c$d`},
+		{"x\\\ny", "xy"},
+		{`"$(date "+%A $(echo "(day)")")"`, `$(date "+%A $(echo "(day)")")`},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if len(tokens) != 2 || tokens[0].Type != TOKEN_WORD || tokens[0].Value != test.want {
+			t.Errorf("lexing %s: got %s with value %q and %d tokens, want a WORD with value %q", test.input, Names.Name(tokens[0].Type), tokens[0].Value, len(tokens), test.want)
+		}
+	}
+}
+
+func TestUnterminated(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`'open`, "test:1:1: unterminated single-quoted string"},
+		{`"open $(x`, "test:1:1: unterminated command substitution"},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input)
+		lexertest.Collect(t, l)
+
+		if diagnostics := l.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Error() != test.want {
+			t.Errorf("lexing %s: got errors %v, want %q", test.input, diagnostics, test.want)
+		}
+	}
+}
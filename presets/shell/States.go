@@ -0,0 +1,288 @@
+package shell
+
+import (
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	blanks    = lexer.NewCharClass(" \t")
+	digits    = lexer.DigitClass
+	metachars = lexer.NewCharClass(" \t\r\n|&;()<>")
+	lineChars = lexer.NewCharClass("\r\n").Not()
+)
+
+func init() {
+	lexer.NameState("shell.token", Start)
+	lexer.NameState("shell.word", lexWord)
+	lexer.NameState("shell.singleQuoted", lexSingleQuoted)
+	lexer.NameState("shell.doubleQuoted", lexDoubleQuoted)
+	lexer.NameState("shell.substitution", lexSubstitution)
+	lexer.NameState("shell.backquoted", lexBackquoted)
+	lexer.NameState("shell.expansion", lexExpansion)
+}
+
+/*
+Start is the state between tokens. It skips blanks and line
+continuations, and lexes operators, newlines and comments itself.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	for l.AcceptClassRun(blanks) > 0 || acceptContinuation(l) {
+	}
+
+	l.Ignore()
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	switch ch := l.Peek(); {
+	case ch == '\r' || ch == '\n':
+		l.Accept("\r")
+		l.Accept("\n")
+		l.Emit(TOKEN_NEWLINE)
+		return Start
+
+	case ch == '#':
+		l.AcceptClassRun(lineChars)
+		l.Emit(TOKEN_COMMENT)
+		return Start
+
+	case digits.Contains(ch):
+		l.AcceptClassRun(digits)
+
+		if next := l.Peek(); next == '<' || next == '>' {
+			l.Emit(TOKEN_IO_NUMBER)
+			return Start
+		}
+
+		return lexWord
+	}
+
+	if operator := matchOperator(l); operator != "" {
+		l.Inc(len(operator))
+		l.Emit(TOKEN_OPERATOR)
+		return Start
+	}
+
+	return lexWord
+}
+
+/*
+lexWord lexes the unquoted parts of a word, handing quotes and
+substitutions to their own states, until a blank or operator ends the
+word
+*/
+func lexWord(l *lexer.Lexer) lexer.LexFn {
+	for {
+		if l.IsEOF() || metachars.Contains(l.Peek()) {
+			l.EmitWithTransform(TOKEN_WORD, Unquote)
+			return Start
+		}
+
+		if next := enterNested(l, lexWord, true); next != nil {
+			return next
+		}
+
+		if l.Next() == '\\' {
+			l.Next()
+		}
+	}
+}
+
+/*
+lexSingleQuoted lexes the inside of single quotes, where every
+character stands for itself
+*/
+func lexSingleQuoted(l *lexer.Lexer) lexer.LexFn {
+	for {
+		if l.IsEOF() {
+			return unterminated(l, "unterminated single-quoted string")
+		}
+
+		if l.Next() == '\'' {
+			return l.PopState()
+		}
+	}
+}
+
+/*
+lexDoubleQuoted lexes the inside of double quotes, where backslashes
+escape and substitutions and expansions still apply
+*/
+func lexDoubleQuoted(l *lexer.Lexer) lexer.LexFn {
+	for {
+		if l.IsEOF() {
+			return unterminated(l, "unterminated double-quoted string")
+		}
+
+		if next := enterNested(l, lexDoubleQuoted, false); next != nil {
+			return next
+		}
+
+		switch l.Next() {
+		case '"':
+			return l.PopState()
+
+		case '\\':
+			l.Next()
+		}
+	}
+}
+
+/*
+lexSubstitution lexes the command inside $( ), pushing itself for each
+nested parenthesis so the matching one ends the substitution
+*/
+func lexSubstitution(l *lexer.Lexer) lexer.LexFn {
+	for {
+		if l.IsEOF() {
+			return unterminated(l, "unterminated command substitution")
+		}
+
+		if next := enterNested(l, lexSubstitution, true); next != nil {
+			return next
+		}
+
+		switch l.Next() {
+		case '(':
+			l.PushState(lexSubstitution)
+			return lexSubstitution
+
+		case ')':
+			return l.PopState()
+
+		case '\\':
+			l.Next()
+		}
+	}
+}
+
+/*
+lexBackquoted lexes an old style `command` substitution
+*/
+func lexBackquoted(l *lexer.Lexer) lexer.LexFn {
+	for {
+		if l.IsEOF() {
+			return unterminated(l, "unterminated backquote substitution")
+		}
+
+		switch l.Next() {
+		case '`':
+			return l.PopState()
+
+		case '\\':
+			l.Next()
+		}
+	}
+}
+
+/*
+lexExpansion lexes a ${ } parameter expansion, whose word part may hold
+quotes and substitutions of its own
+*/
+func lexExpansion(l *lexer.Lexer) lexer.LexFn {
+	for {
+		if l.IsEOF() {
+			return unterminated(l, "unterminated parameter expansion")
+		}
+
+		if next := enterNested(l, lexExpansion, true); next != nil {
+			return next
+		}
+
+		switch l.Next() {
+		case '}':
+			return l.PopState()
+
+		case '\\':
+			l.Next()
+		}
+	}
+}
+
+/*
+enterNested starts a quote, substitution or expansion at the current
+position, pushing current to return to when it closes. Single and
+double quotes are only recognized when quotes is true, as inside double
+quotes they are ordinary characters. It returns nil if nothing nested
+starts here.
+*/
+func enterNested(l *lexer.Lexer, current lexer.LexFn, quotes bool) lexer.LexFn {
+	var next lexer.LexFn
+	size := 1
+
+	switch start := l.PeekCharacters(2); {
+	case start == "$(":
+		next, size = lexSubstitution, 2
+
+	case start == "${":
+		next, size = lexExpansion, 2
+
+	case start[0] == '`':
+		next = lexBackquoted
+
+	case start[0] == '\'' && quotes:
+		next = lexSingleQuoted
+
+	case start[0] == '"' && quotes:
+		next = lexDoubleQuoted
+
+	default:
+		return nil
+	}
+
+	l.Inc(size)
+	l.PushState(current)
+
+	return next
+}
+
+/*
+unterminated reports a word left open at the end of the input. The
+modes pushed for the word are dropped, as none of them can now close.
+*/
+func unterminated(l *lexer.Lexer, message string) lexer.LexFn {
+	l.Errorf("%s", message)
+	l.Ignore()
+
+	for l.PopState() != nil {
+	}
+
+	return Start
+}
+
+/*
+acceptContinuation accepts a backslash-newline line continuation
+*/
+func acceptContinuation(l *lexer.Lexer) bool {
+	if l.PeekCharacters(3) == "\\\r\n" {
+		l.Inc(3)
+		return true
+	}
+
+	if l.PeekCharacters(2) == "\\\n" {
+		l.Inc(2)
+		return true
+	}
+
+	return false
+}
+
+/*
+matchOperator returns the longest operator at the current position, or
+an empty string if there is none
+*/
+func matchOperator(l *lexer.Lexer) string {
+	next := l.PeekCharacters(3)
+
+	for _, operator := range operators {
+		if strings.HasPrefix(next, operator) {
+			return operator
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,46 @@
+package shell
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_WORD lexer.TokenType = iota + 1
+	TOKEN_IO_NUMBER
+	TOKEN_OPERATOR
+	TOKEN_NEWLINE
+	TOKEN_COMMENT
+)
+
+/*
+Names holds the names of the shell token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_WORD:      "WORD",
+	TOKEN_IO_NUMBER: "IO_NUMBER",
+	TOKEN_OPERATOR:  "OPERATOR",
+	TOKEN_NEWLINE:   "NEWLINE",
+	TOKEN_COMMENT:   "COMMENT",
+}
+
+/*
+Categories maps the shell token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_WORD:      highlight.CATEGORY_STRING,
+	TOKEN_IO_NUMBER: highlight.CATEGORY_NUMBER,
+	TOKEN_OPERATOR:  highlight.CATEGORY_OPERATOR,
+	TOKEN_NEWLINE:   highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMENT:   highlight.CATEGORY_COMMENT,
+}
+
+/*
+operators lists the control and redirection operators longest first,
+so the first one found at the current position is the longest match
+*/
+var operators = []string{
+	"<<-",
+	"&&", "||", ";;", "<<", ">>", "<&", ">&", "<>", ">|",
+	"|", "&", ";", "<", ">", "(", ")",
+}
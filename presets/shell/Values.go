@@ -0,0 +1,146 @@
+package shell
+
+import (
+	"strings"
+)
+
+/*
+Unquote is the TokenValueTransformer for words. It performs the shell's
+quote removal: single quotes keep their contents as written, double
+quotes keep theirs except for backslashes before $, `, ", \ and
+newline, and an unquoted backslash escapes the character after it. A
+backslash-newline disappears entirely. Substitutions and expansions are
+copied through as written.
+*/
+func Unquote(text string) interface{} {
+	if !strings.ContainsAny(text, `'"\`) {
+		return text
+	}
+
+	var result strings.Builder
+	result.Grow(len(text))
+
+	quoted := false
+
+	for index := 0; index < len(text); {
+		ch := text[index]
+
+		switch {
+		case ch == '$' && index+1 < len(text) && (text[index+1] == '(' || text[index+1] == '{'), ch == '`':
+			end := nestedEnd(text, index)
+			result.WriteString(text[index:end])
+			index = end
+
+		case ch == '"':
+			quoted = !quoted
+			index++
+
+		case ch == '\'' && !quoted:
+			end := strings.IndexByte(text[index+1:], '\'')
+			if end < 0 {
+				end = len(text) - index - 1
+			}
+
+			result.WriteString(text[index+1 : index+1+end])
+			index += end + 2
+
+		case ch == '\\' && index+1 < len(text):
+			next := text[index+1]
+
+			switch {
+			case next == '\n':
+
+			case quoted && !strings.ContainsRune("$`\"\\", rune(next)):
+				result.WriteByte('\\')
+				result.WriteByte(next)
+
+			default:
+				result.WriteByte(next)
+			}
+
+			index += 2
+
+		default:
+			result.WriteByte(ch)
+			index++
+		}
+	}
+
+	return result.String()
+}
+
+/*
+nestedEnd returns the offset just past the substitution or expansion
+starting at start, following the same nesting rules as the lexer's
+states with a stack of the characters that close each level
+*/
+func nestedEnd(text string, start int) int {
+	closers := []byte{}
+	index := start
+
+	open := func() bool {
+		switch {
+		case strings.HasPrefix(text[index:], "$("):
+			closers = append(closers, ')')
+			index += 2
+
+		case strings.HasPrefix(text[index:], "${"):
+			closers = append(closers, '}')
+			index += 2
+
+		case text[index] == '`':
+			closers = append(closers, '`')
+			index++
+
+		default:
+			return false
+		}
+
+		return true
+	}
+
+	open()
+
+	for len(closers) > 0 && index < len(text) {
+		closer := closers[len(closers)-1]
+		ch := text[index]
+
+		switch {
+		case closer == '\'':
+			if ch == '\'' {
+				closers = closers[:len(closers)-1]
+			}
+
+			index++
+
+		case ch == '\\':
+			index += 2
+
+		case ch == closer:
+			closers = closers[:len(closers)-1]
+			index++
+
+		case closer == '`':
+			index++
+
+		case open():
+
+		case ch == '"' || (ch == '\'' && closer != '"'):
+			closers = append(closers, ch)
+			index++
+
+		case ch == '(' && closer == ')':
+			closers = append(closers, ')')
+			index++
+
+		default:
+			index++
+		}
+	}
+
+	if index > len(text) {
+		index = len(text)
+	}
+
+	return index
+}
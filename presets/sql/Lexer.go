@@ -0,0 +1,46 @@
+/*
+Package sql is a ready-made lexer for SQL statements, suitable for
+query loggers, formatters and linters that need to see the structure of
+a query without parsing it:
+
+	l := sql.NewLexer("query", "SELECT name FROM \"Users\" WHERE id = ? -- by id")
+
+	for _, token := range l.Collect() {
+		...
+	}
+
+Keywords are matched without regard to case and carry their upper-case
+spelling in Value, so select, Select and SELECT all compare equal.
+Identifiers quoted with double quotes, backticks or square brackets,
+and string literals, including those prefixed with N, X or B, carry
+their unquoted text in Value with doubled quotes collapsed. Line
+comments start with -- and block comments may nest. Positional and
+named parameters (?, $1, :name and @name) have a token type of their
+own.
+
+The lexer covers the common ground of the major dialects rather than
+any one grammar, so a word that is a keyword in one database and an
+identifier in another is lexed as a keyword.
+*/
+package sql
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for SQL held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for SQL read from reader, such as a
+migration file or a stream of logged queries
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
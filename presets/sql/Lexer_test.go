@@ -0,0 +1,88 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestTokens(t *testing.T) {
+	input := "SELECT t.x, \"My Col\" FROM t WHERE a >= 1.5e2 AND b <> 'x' -- trailing\n/* block\n*/ LIMIT ?;"
+	want := `KEYWORD "SELECT"
+IDENTIFIER "t"
+DOT "."
+IDENTIFIER "x"
+COMMA ","
+QUOTED_IDENTIFIER "\"My Col\""
+KEYWORD "FROM"
+IDENTIFIER "t"
+KEYWORD "WHERE"
+IDENTIFIER "a"
+OPERATOR ">="
+NUMBER "1.5e2"
+KEYWORD "AND"
+IDENTIFIER "b"
+OPERATOR "<>"
+STRING "'x'"
+COMMENT "-- trailing"
+COMMENT "/* block\n*/"
+KEYWORD "LIMIT"
+PARAMETER "?"
+SEMICOLON ";"
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestValues(t *testing.T) {
+	tests := []struct {
+		input     string
+		tokenType string
+		want      string
+	}{
+		{"select", "KEYWORD", "SELECT"},
+		{"SeLeCt", "KEYWORD", "SELECT"},
+		{"'it''s'", "STRING", "it's"},
+		{"''", "STRING", ""},
+		{`"My ""Col"""`, "QUOTED_IDENTIFIER", `My "Col"`},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if got := Names.Name(tokens[0].Type); got != test.tokenType || tokens[0].Value != test.want {
+			t.Errorf("lexing %s: got %s with value %q, want %s with value %q", test.input, got, tokens[0].Value, test.tokenType, test.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			"'open",
+			`ERROR "unterminated string"
+EOF ""
+`,
+		},
+		{
+			"/* open",
+			`ERROR "unterminated comment"
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input)))
+
+		if got != test.want {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+		}
+	}
+}
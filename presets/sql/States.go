@@ -0,0 +1,269 @@
+package sql
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	whitespace = lexer.WhitespaceClass
+	digits     = lexer.DigitClass
+	wordStart  = lexer.NewCharClass("_").AddTable(unicode.Letter)
+	wordChars  = lexer.NewCharClass("_$").AddTable(unicode.Letter, unicode.Number)
+	lineChars  = lexer.NewCharClass("\n").Not()
+)
+
+func init() {
+	lexer.NameState("sql.token", Start)
+	lexer.NameState("sql.word", lexWord)
+	lexer.NameState("sql.string", lexString)
+	lexer.NameState("sql.quotedIdentifier", lexQuotedIdentifier)
+	lexer.NameState("sql.number", lexNumber)
+	lexer.NameState("sql.parameter", lexParameter)
+	lexer.NameState("sql.lineComment", lexLineComment)
+	lexer.NameState("sql.blockComment", lexBlockComment)
+}
+
+/*
+Start is the state between tokens. It skips whitespace and decides
+which state lexes the next token from its first characters.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(whitespace)
+	l.Ignore()
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	ch := l.Peek()
+	next := l.PeekCharacters(2)
+
+	switch {
+	case next == "--":
+		return lexLineComment
+
+	case next == "/*":
+		return lexBlockComment
+
+	case ch == '\'':
+		return lexString
+
+	case ch == '"' || ch == '`' || ch == '[':
+		return lexQuotedIdentifier
+
+	case digits.Contains(ch) || (ch == '.' && len(next) == 2 && digits.Contains(rune(next[1]))):
+		return lexNumber
+
+	case ch == '?' || ch == '$' || ch == '@' || (ch == ':' && len(next) == 2 && wordStart.Contains(rune(next[1]))):
+		return lexParameter
+
+	case strings.ContainsRune("NXBnxb", ch) && len(next) == 2 && next[1] == '\'':
+		l.Next()
+		return lexString
+
+	case wordStart.Contains(ch):
+		return lexWord
+	}
+
+	if tokenType, ok := punctuation[ch]; ok {
+		l.Next()
+		l.Emit(tokenType)
+		return Start
+	}
+
+	if operator := matchOperator(l); operator != "" {
+		l.Inc(len(operator))
+		l.Emit(TOKEN_OPERATOR)
+		return Start
+	}
+
+	l.Next()
+	l.Errorf("unexpected character %q", ch)
+	l.Ignore()
+
+	return Start
+}
+
+/*
+lexWord lexes a keyword or an unquoted identifier
+*/
+func lexWord(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(wordChars)
+
+	if _, ok := keywords.Lookup(strings.ToLower(l.CurrentInput())); ok {
+		l.EmitWithTransform(TOKEN_KEYWORD, canonicalKeyword)
+		return Start
+	}
+
+	l.Emit(TOKEN_IDENTIFIER)
+	return Start
+}
+
+/*
+lexString lexes a string literal, along with any N, X or B prefix
+already consumed. Strings may span lines and a doubled quote stands for
+a single one.
+*/
+func lexString(l *lexer.Lexer) lexer.LexFn {
+	l.Next()
+	return lexQuoted(l, '\'', TOKEN_STRING, "unterminated string")
+}
+
+/*
+lexQuotedIdentifier lexes an identifier quoted with double quotes,
+backticks or square brackets
+*/
+func lexQuotedIdentifier(l *lexer.Lexer) lexer.LexFn {
+	closing := l.Next()
+
+	if closing == '[' {
+		closing = ']'
+	}
+
+	return lexQuoted(l, closing, TOKEN_QUOTED_IDENTIFIER, "unterminated quoted identifier")
+}
+
+/*
+lexQuoted lexes up to and including the closing quote, treating a
+doubled closing quote as part of the text
+*/
+func lexQuoted(l *lexer.Lexer, closing rune, tokenType lexer.TokenType, message string) lexer.LexFn {
+	for {
+		if l.IsEOF() {
+			l.Errorf("%s", message)
+			l.Ignore()
+			return Start
+		}
+
+		if l.Next() != closing {
+			continue
+		}
+
+		if l.IsEOF() || l.Peek() != closing {
+			l.EmitWithTransform(tokenType, Unquote)
+			return Start
+		}
+
+		l.Next()
+	}
+}
+
+/*
+lexNumber lexes an integer or decimal number with an optional exponent.
+An exponent marker with no digits after it is left for the next token.
+*/
+func lexNumber(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(digits)
+
+	if l.Accept(".") {
+		l.AcceptClassRun(digits)
+	}
+
+	end := l.Pos
+
+	if l.Accept("eE") {
+		l.Accept("+-")
+
+		if l.AcceptClassRun(digits) == 0 {
+			l.Pos = end
+		}
+	}
+
+	l.Emit(TOKEN_NUMBER)
+	return Start
+}
+
+/*
+lexParameter lexes a bind parameter: ? on its own or numbered, $1,
+:name, or @name. MySQL's @@ system variables are lexed as parameters
+too.
+*/
+func lexParameter(l *lexer.Lexer) lexer.LexFn {
+	switch l.Next() {
+	case '?', '$':
+		l.AcceptClassRun(digits)
+
+	case '@':
+		l.Accept("@")
+		l.AcceptClassRun(wordChars)
+
+	case ':':
+		l.AcceptClassRun(wordChars)
+	}
+
+	if text := l.CurrentInput(); text != "?" && strings.TrimLeft(text, "$@") == "" {
+		l.Errorf("parameter is missing its name")
+		l.Ignore()
+		return Start
+	}
+
+	l.Emit(TOKEN_PARAMETER)
+	return Start
+}
+
+/*
+lexLineComment lexes a -- comment through to the end of the line
+*/
+func lexLineComment(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(lineChars)
+
+	// A comment ending a Windows line keeps its carriage return out
+	if strings.HasSuffix(l.CurrentInput(), "\r") {
+		l.Pos--
+	}
+
+	l.Emit(TOKEN_COMMENT)
+	return Start
+}
+
+/*
+lexBlockComment lexes a /* comment. As in standard SQL and PostgreSQL,
+block comments nest.
+*/
+func lexBlockComment(l *lexer.Lexer) lexer.LexFn {
+	l.Inc(2)
+	depth := 1
+
+	for depth > 0 {
+		switch next := l.PeekCharacters(2); {
+		case l.IsEOF():
+			l.Errorf("unterminated comment")
+			l.Ignore()
+			return Start
+
+		case next == "/*":
+			l.Inc(2)
+			depth++
+
+		case next == "*/":
+			l.Inc(2)
+			depth--
+
+		default:
+			l.Next()
+		}
+	}
+
+	l.Emit(TOKEN_COMMENT)
+	return Start
+}
+
+/*
+matchOperator returns the longest operator at the current position, or
+an empty string if there is none
+*/
+func matchOperator(l *lexer.Lexer) string {
+	next := l.PeekCharacters(3)
+
+	for _, operator := range operators {
+		if strings.HasPrefix(next, operator) {
+			return operator
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,130 @@
+package sql
+
+import (
+	"strings"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_KEYWORD lexer.TokenType = iota + 1
+	TOKEN_IDENTIFIER
+	TOKEN_QUOTED_IDENTIFIER
+	TOKEN_STRING
+	TOKEN_NUMBER
+	TOKEN_PARAMETER
+	TOKEN_OPERATOR
+	TOKEN_LEFT_PAREN
+	TOKEN_RIGHT_PAREN
+	TOKEN_COMMA
+	TOKEN_SEMICOLON
+	TOKEN_DOT
+	TOKEN_COMMENT
+)
+
+/*
+Names holds the names of the SQL token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_KEYWORD:           "KEYWORD",
+	TOKEN_IDENTIFIER:        "IDENTIFIER",
+	TOKEN_QUOTED_IDENTIFIER: "QUOTED_IDENTIFIER",
+	TOKEN_STRING:            "STRING",
+	TOKEN_NUMBER:            "NUMBER",
+	TOKEN_PARAMETER:         "PARAMETER",
+	TOKEN_OPERATOR:          "OPERATOR",
+	TOKEN_LEFT_PAREN:        "LEFT_PAREN",
+	TOKEN_RIGHT_PAREN:       "RIGHT_PAREN",
+	TOKEN_COMMA:             "COMMA",
+	TOKEN_SEMICOLON:         "SEMICOLON",
+	TOKEN_DOT:               "DOT",
+	TOKEN_COMMENT:           "COMMENT",
+}
+
+/*
+Categories maps the SQL token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_KEYWORD:           highlight.CATEGORY_KEYWORD,
+	TOKEN_IDENTIFIER:        highlight.CATEGORY_IDENTIFIER,
+	TOKEN_QUOTED_IDENTIFIER: highlight.CATEGORY_IDENTIFIER,
+	TOKEN_STRING:            highlight.CATEGORY_STRING,
+	TOKEN_NUMBER:            highlight.CATEGORY_NUMBER,
+	TOKEN_PARAMETER:         highlight.CATEGORY_LITERAL,
+	TOKEN_OPERATOR:          highlight.CATEGORY_OPERATOR,
+	TOKEN_LEFT_PAREN:        highlight.CATEGORY_PUNCTUATION,
+	TOKEN_RIGHT_PAREN:       highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMA:             highlight.CATEGORY_PUNCTUATION,
+	TOKEN_SEMICOLON:         highlight.CATEGORY_PUNCTUATION,
+	TOKEN_DOT:               highlight.CATEGORY_PUNCTUATION,
+	TOKEN_COMMENT:           highlight.CATEGORY_COMMENT,
+}
+
+var punctuation = map[rune]lexer.TokenType{
+	'(': TOKEN_LEFT_PAREN,
+	')': TOKEN_RIGHT_PAREN,
+	',': TOKEN_COMMA,
+	';': TOKEN_SEMICOLON,
+	'.': TOKEN_DOT,
+}
+
+/*
+operators lists the operators longest first, so the first one found
+at the current position is the longest match
+*/
+var operators = []string{
+	"->>", "<=>",
+	"<>", "<=", ">=", "!=", "||", "::", "->", "<<", ">>",
+	"=", "<", ">", "+", "-", "*", "/", "%", "^", "&", "|", "~", "!", ":",
+}
+
+/*
+keywordList holds the reserved and commonly used non-reserved words of
+standard SQL and the major dialects
+*/
+var keywordList = []string{
+	"ADD", "ALL", "ALTER", "AND", "ANY", "AS", "ASC", "AUTO_INCREMENT",
+	"BEGIN", "BETWEEN", "BIGINT", "BOOLEAN", "BOTH", "BY",
+	"CASCADE", "CASE", "CAST", "CHAR", "CHECK", "COLLATE", "COLUMN", "COMMIT", "CONSTRAINT", "CREATE", "CROSS", "CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP",
+	"DATABASE", "DATE", "DECIMAL", "DEFAULT", "DELETE", "DESC", "DISTINCT", "DO", "DROP",
+	"ELSE", "END", "ESCAPE", "EXCEPT", "EXISTS", "EXPLAIN",
+	"FALSE", "FETCH", "FIRST", "FLOAT", "FOR", "FOREIGN", "FROM", "FULL", "FUNCTION",
+	"GRANT", "GROUP",
+	"HAVING",
+	"IF", "ILIKE", "IN", "INDEX", "INNER", "INSERT", "INT", "INTEGER", "INTERSECT", "INTERVAL", "INTO", "IS",
+	"JOIN",
+	"KEY",
+	"LAST", "LATERAL", "LEADING", "LEFT", "LIKE", "LIMIT",
+	"MERGE",
+	"NATURAL", "NOT", "NULL", "NULLS", "NUMERIC",
+	"OFFSET", "ON", "OR", "ORDER", "OUTER", "OVER",
+	"PARTITION", "PRIMARY", "PROCEDURE",
+	"RECURSIVE", "REFERENCES", "RETURNING", "REVOKE", "RIGHT", "ROLLBACK", "ROW", "ROWS",
+	"SAVEPOINT", "SCHEMA", "SELECT", "SET", "SMALLINT", "SOME",
+	"TABLE", "TEXT", "THEN", "TIME", "TIMESTAMP", "TO", "TRAILING", "TRANSACTION", "TRIGGER", "TRUE", "TRUNCATE",
+	"UNION", "UNIQUE", "UNKNOWN", "UPDATE", "USING",
+	"VALUES", "VARCHAR", "VIEW",
+	"WHEN", "WHERE", "WINDOW", "WITH",
+}
+
+/*
+keywords matches the lower-case spelling of each keyword, and
+canonical maps that spelling back to the upper-case one given as the
+keyword token's value
+*/
+var (
+	keywords  *lexer.KeywordMatcher
+	canonical = map[string]string{}
+)
+
+func init() {
+	lower := make(map[string]lexer.TokenType, len(keywordList))
+
+	for _, keyword := range keywordList {
+		lower[strings.ToLower(keyword)] = TOKEN_KEYWORD
+		canonical[strings.ToLower(keyword)] = keyword
+	}
+
+	keywords = lexer.NewKeywordMatcher(lower)
+}
@@ -0,0 +1,35 @@
+package sql
+
+import (
+	"strings"
+)
+
+/*
+Unquote is the TokenValueTransformer for strings and quoted identifiers.
+It removes any N, X or B prefix and the quotes, and collapses doubled
+closing quotes to one. The text of X and B strings is left as written.
+*/
+func Unquote(text string) interface{} {
+	text = strings.TrimLeft(text, "NXBnxb")
+
+	closing := text[:1]
+	if closing == "[" {
+		closing = "]"
+	}
+
+	text = text[1 : len(text)-1]
+
+	if !strings.Contains(text, closing+closing) {
+		return text
+	}
+
+	return strings.ReplaceAll(text, closing+closing, closing)
+}
+
+/*
+canonicalKeyword gives a keyword token the upper-case spelling of its
+keyword as its value
+*/
+func canonicalKeyword(text string) interface{} {
+	return canonical[strings.ToLower(text)]
+}
@@ -0,0 +1,46 @@
+/*
+Package template is a ready-made lexer for text with embedded template
+actions, such as Go's text/template, Handlebars or Jinja expressions.
+It splits the input into literal text and the code between a pair of
+delimiters, leaving the code itself to the template language:
+
+	l := template.NewLexer("page", "Hello, {{- .Name -}} !", template.DefaultSyntax)
+
+gives TOKEN_TEXT "Hello, ", TOKEN_LEFT_DELIM "{{-", TOKEN_CODE " .Name ",
+TOKEN_RIGHT_DELIM "-}}" and TOKEN_TEXT " !". The delimiters, the trim
+marker and the quote characters are set by a Syntax.
+
+Text tokens carry their text in Value after trimming: a trim marker
+after the left delimiter removes the whitespace at the end of the text
+before it, and one before the right delimiter removes the whitespace at
+the start of the text after it, as in text/template. Code tokens carry
+their code in Value with surrounding whitespace removed.
+
+Inside an action, the right delimiter does not count while in a quoted
+string, so
+
+	{{ printf "}}" }}
+
+is one action.
+*/
+package template
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for a template held in memory
+*/
+func NewLexer(name string, input string, syntax Syntax, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, syntax.Start(), options...)
+}
+
+/*
+NewReaderLexer creates a lexer for a template read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, syntax Syntax, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, syntax.Start(), options...)
+}
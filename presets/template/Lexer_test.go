@@ -0,0 +1,110 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestSyntaxes(t *testing.T) {
+	tests := []struct {
+		syntax Syntax
+		input  string
+		want   string
+	}{
+		{
+			DefaultSyntax,
+			"Hello, {{ .Name }}!",
+			`TEXT "Hello, "
+LEFT_DELIM "{{"
+CODE " .Name "
+RIGHT_DELIM "}}"
+TEXT "!"
+EOF ""
+`,
+		},
+		{
+			DefaultSyntax,
+			`{{ printf "}}" }}{{ x '}}' }}`,
+			`LEFT_DELIM "{{"
+CODE " printf \"}}\" "
+RIGHT_DELIM "}}"
+LEFT_DELIM "{{"
+CODE " x '}}' "
+RIGHT_DELIM "}}"
+EOF ""
+`,
+		},
+		{
+			DefaultSyntax,
+			"a }} b",
+			`TEXT "a }} b"
+EOF ""
+`,
+		},
+		{
+			ERBSyntax,
+			`<%- x "%>" -%> y`,
+			`LEFT_DELIM "<%-"
+CODE " x \"%>\" "
+RIGHT_DELIM "-%>"
+TEXT " y"
+EOF ""
+`,
+		},
+		{
+			Syntax{Left: "[[", Right: "]]"},
+			"a [[- x ]] b",
+			`TEXT "a "
+LEFT_DELIM "[["
+CODE "- x "
+RIGHT_DELIM "]]"
+TEXT " b"
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input, test.syntax)))
+
+		if got != test.want {
+			t.Errorf("lexing %q with %+v:\ngot:\n%s\nwant:\n%s", test.input, test.syntax, got, test.want)
+		}
+	}
+}
+
+func TestTrimming(t *testing.T) {
+	input := "Hello, \n {{- .Name -}} \n!"
+	want := []interface{}{"Hello,", nil, ".Name", nil, "!", nil}
+
+	tokens := lexertest.Collect(t, NewLexer("test", input, DefaultSyntax))
+	if len(tokens) != len(want) {
+		t.Fatalf("lexing %q: got\n%s", input, lexertest.Format(Names, tokens))
+	}
+
+	for index, token := range tokens {
+		if token.Value != want[index] {
+			t.Errorf("lexing %q: %s %q: got value %#v, want %#v", input, Names.Name(token.Type), token.Text, token.Value, want[index])
+		}
+	}
+}
+
+func TestUnclosedAction(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a {{ open", "test:1:5: unclosed action"},
+		{`{{ "open }}`, "test:1:3: unclosed action"},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input, DefaultSyntax)
+		lexertest.Collect(t, l)
+
+		if diagnostics := l.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Error() != test.want {
+			t.Errorf("lexing %q: got errors %v, want %q", test.input, diagnostics, test.want)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package template
+
+import (
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+const spaces = " \t\r\n"
+
+/*
+scanner holds the state functions for one syntax. The states are
+methods so that they can read the syntax's settings.
+*/
+type scanner struct {
+	syntax    Syntax
+	leftTrim  string
+	rightTrim string
+}
+
+/*
+Start returns the initial state function for lexing templates in this
+syntax
+*/
+func (syntax Syntax) Start() lexer.LexFn {
+	syntax = syntax.withDefaults()
+
+	s := &scanner{syntax: syntax}
+
+	if syntax.TrimMarker != "" {
+		s.leftTrim = syntax.Left + syntax.TrimMarker
+		s.rightTrim = syntax.TrimMarker + syntax.Right
+	}
+
+	return s.lexText
+}
+
+/*
+lexText lexes text up to the next left delimiter
+*/
+func (s *scanner) lexText(l *lexer.Lexer) lexer.LexFn {
+	return s.lexTextTrimming(l, false)
+}
+
+/*
+lexTextTrimmed lexes text following a right delimiter with a trim
+marker
+*/
+func (s *scanner) lexTextTrimmed(l *lexer.Lexer) lexer.LexFn {
+	return s.lexTextTrimming(l, true)
+}
+
+func (s *scanner) lexTextTrimming(l *lexer.Lexer, trimStart bool) lexer.LexFn {
+	s.findLeft(l)
+	trimEnd := s.atLeftTrim(l)
+
+	if l.Pos > l.Start {
+		switch {
+		case trimStart && trimEnd:
+			l.EmitWithTransform(TOKEN_TEXT, trimBoth)
+
+		case trimStart:
+			l.EmitWithTransform(TOKEN_TEXT, trimLeading)
+
+		case trimEnd:
+			l.EmitWithTransform(TOKEN_TEXT, trimTrailing)
+
+		default:
+			l.EmitWithTransform(TOKEN_TEXT, text)
+		}
+	}
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	l.Inc(len(s.syntax.Left))
+
+	if trimEnd {
+		l.Inc(len(s.syntax.TrimMarker))
+	}
+
+	l.Emit(TOKEN_LEFT_DELIM)
+	return s.lexCode
+}
+
+/*
+lexCode lexes the code of an action up to its right delimiter, skipping
+over quoted strings
+*/
+func (s *scanner) lexCode(l *lexer.Lexer) lexer.LexFn {
+	trim := false
+
+	for {
+		if l.IsEOF() {
+			l.Errorf("unclosed action")
+			l.Ignore()
+			l.Emit(lexer.TOKEN_EOF)
+			return nil
+		}
+
+		if s.rightTrim != "" && l.Pos > l.Start && strings.ContainsRune(spaces, rune(l.Input[l.Pos-1])) && l.PeekCharacters(len(s.rightTrim)) == s.rightTrim {
+			trim = true
+			break
+		}
+
+		if l.PeekCharacters(len(s.syntax.Right)) == s.syntax.Right {
+			break
+		}
+
+		if quote := l.Peek(); strings.ContainsRune(s.syntax.Quotes, quote) {
+			s.skipQuoted(l)
+			continue
+		}
+
+		l.Next()
+	}
+
+	if l.Pos > l.Start {
+		l.EmitWithTransform(TOKEN_CODE, code)
+	}
+
+	if trim {
+		l.Inc(len(s.syntax.TrimMarker))
+	}
+
+	l.Inc(len(s.syntax.Right))
+	l.Emit(TOKEN_RIGHT_DELIM)
+
+	if trim {
+		return s.lexTextTrimmed
+	}
+
+	return s.lexText
+}
+
+/*
+skipQuoted consumes a quoted string in code. Backslashes escape in all
+but backtick quoted strings. A string left open runs to the end of the
+input, where the action is reported as unclosed.
+*/
+func (s *scanner) skipQuoted(l *lexer.Lexer) {
+	quote := l.Next()
+	escape := '\\'
+
+	if quote == '`' {
+		escape = 0
+	}
+
+	for !l.IsEOF() {
+		switch l.Next() {
+		case quote:
+			return
+
+		case escape:
+			if escape != 0 {
+				l.Next()
+			}
+		}
+	}
+}
+
+/*
+findLeft moves to the next left delimiter, or the end of the input.
+When lexing from a reader, the end of what has been read so far is kept
+in view so that a delimiter split across reads is still found.
+*/
+func (s *scanner) findLeft(l *lexer.Lexer) {
+	left := s.syntax.Left
+
+	for {
+		if index := strings.Index(l.Input[l.Pos:], left); index >= 0 {
+			l.Pos += index
+			return
+		}
+
+		if keep := len(l.Input) - len(left) + 1; keep > l.Pos {
+			l.Pos = keep
+		}
+
+		if len(l.PeekCharacters(len(left))) < len(left) {
+			l.Pos = len(l.Input)
+			return
+		}
+	}
+}
+
+/*
+atLeftTrim reports whether the lexer is at a left delimiter followed by
+a trim marker and whitespace
+*/
+func (s *scanner) atLeftTrim(l *lexer.Lexer) bool {
+	if s.leftTrim == "" {
+		return false
+	}
+
+	next := l.PeekCharacters(len(s.leftTrim) + 1)
+	return len(next) > len(s.leftTrim) && strings.HasPrefix(next, s.leftTrim) && strings.ContainsRune(spaces, rune(next[len(s.leftTrim)]))
+}
+
+func text(text string) interface{} {
+	return text
+}
+
+func trimLeading(text string) interface{} {
+	return strings.TrimLeft(text, spaces)
+}
+
+func trimTrailing(text string) interface{} {
+	return strings.TrimRight(text, spaces)
+}
+
+func trimBoth(text string) interface{} {
+	return strings.Trim(text, spaces)
+}
+
+func code(text string) interface{} {
+	return strings.Trim(text, spaces)
+}
@@ -0,0 +1,37 @@
+package template
+
+/*
+Syntax describes the delimiters of a template language. An empty Left
+or Right uses {{ or }}. An empty TrimMarker disables trimming; otherwise
+the marker trims whitespace when it directly follows the left delimiter
+or precedes the right one, and is separated from the code by
+whitespace, as in {{- x -}}. Quotes lists the characters that start a
+quoted string in code. The backtick quotes raw strings, and the others
+allow backslash escapes.
+*/
+type Syntax struct {
+	Left       string
+	Right      string
+	TrimMarker string
+	Quotes     string
+}
+
+var (
+	// DefaultSyntax is the syntax of Go's text/template and html/template
+	DefaultSyntax = Syntax{Left: "{{", Right: "}}", TrimMarker: "-", Quotes: "\"'`"}
+
+	// ERBSyntax is the syntax of embedded Ruby and similar templates
+	ERBSyntax = Syntax{Left: "<%", Right: "%>", TrimMarker: "-", Quotes: "\"'"}
+)
+
+func (syntax Syntax) withDefaults() Syntax {
+	if syntax.Left == "" {
+		syntax.Left = DefaultSyntax.Left
+	}
+
+	if syntax.Right == "" {
+		syntax.Right = DefaultSyntax.Right
+	}
+
+	return syntax
+}
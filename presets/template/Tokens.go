@@ -0,0 +1,32 @@
+package template
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_TEXT lexer.TokenType = iota + 1
+	TOKEN_LEFT_DELIM
+	TOKEN_CODE
+	TOKEN_RIGHT_DELIM
+)
+
+/*
+Names holds the names of the template token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_TEXT:        "TEXT",
+	TOKEN_LEFT_DELIM:  "LEFT_DELIM",
+	TOKEN_CODE:        "CODE",
+	TOKEN_RIGHT_DELIM: "RIGHT_DELIM",
+}
+
+/*
+Categories maps the template token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_LEFT_DELIM:  highlight.CATEGORY_PREPROCESSOR,
+	TOKEN_CODE:        highlight.CATEGORY_IDENTIFIER,
+	TOKEN_RIGHT_DELIM: highlight.CATEGORY_PREPROCESSOR,
+}
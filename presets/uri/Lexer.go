@@ -0,0 +1,44 @@
+/*
+Package uri is a ready-made lexer splitting URIs, as described in RFC
+3986, into their components. It serves routing engines that match on
+path segments and link analyzers that pull hosts and query parameters
+out of large lists of links:
+
+	l := uri.NewLexer("link", "https://user@example.com:8080/docs/a%20b/?q=lexer+go&page=2#intro")
+
+gives TOKEN_SCHEME "https", TOKEN_AUTHORITY "user@example.com:8080",
+TOKEN_PATH_SEGMENT tokens "docs", "a%20b" and "", TOKEN_QUERY_KEY and
+TOKEN_QUERY_VALUE tokens for "q", "lexer+go", "page" and "2", and
+TOKEN_FRAGMENT "intro". The delimiters between components are not
+tokens of their own.
+
+Every component but the scheme carries its percent-decoded form in
+Value, produced by the PathUnescape transformer, or by QueryUnescape for
+query keys and values, which also turns + into a space as HTML forms
+expect. Malformed percent-encodings are reported and left as written.
+
+Relative references, such as //example.com/x, /docs or ?page=2, are
+lexed too. Any number of URIs may be given, separated by whitespace.
+*/
+package uri
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for URIs held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for URIs read from reader, one or more
+to a line
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
@@ -0,0 +1,102 @@
+package uri
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+type decoded struct {
+	tokenType lexer.TokenType
+	text      string
+	value     interface{}
+}
+
+func TestComponents(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []decoded
+	}{
+		{
+			"https://user@example.com:8080/docs/a%20b/?q=lexer+go&page=2#intro%21",
+			[]decoded{
+				{TOKEN_SCHEME, "https", nil},
+				{TOKEN_AUTHORITY, "user@example.com:8080", "user@example.com:8080"},
+				{TOKEN_PATH_SEGMENT, "docs", "docs"},
+				{TOKEN_PATH_SEGMENT, "a%20b", "a b"},
+				{TOKEN_PATH_SEGMENT, "", ""},
+				{TOKEN_QUERY_KEY, "q", "q"},
+				{TOKEN_QUERY_VALUE, "lexer+go", "lexer go"},
+				{TOKEN_QUERY_KEY, "page", "page"},
+				{TOKEN_QUERY_VALUE, "2", "2"},
+				{TOKEN_FRAGMENT, "intro%21", "intro!"},
+			},
+		},
+		{
+			"//example.com/x /docs ?page=2 mailto:a@b.c",
+			[]decoded{
+				{TOKEN_AUTHORITY, "example.com", "example.com"},
+				{TOKEN_PATH_SEGMENT, "x", "x"},
+				{TOKEN_PATH_SEGMENT, "docs", "docs"},
+				{TOKEN_QUERY_KEY, "page", "page"},
+				{TOKEN_QUERY_VALUE, "2", "2"},
+				{TOKEN_SCHEME, "mailto", nil},
+				{TOKEN_PATH_SEGMENT, "a@b.c", "a@b.c"},
+			},
+		},
+		{
+			"?flag&=v&k=",
+			[]decoded{
+				{TOKEN_QUERY_KEY, "flag", "flag"},
+				{TOKEN_QUERY_KEY, "", ""},
+				{TOKEN_QUERY_VALUE, "v", "v"},
+				{TOKEN_QUERY_KEY, "k", "k"},
+				{TOKEN_QUERY_VALUE, "", ""},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+
+		if len(tokens) != len(test.want)+1 || tokens[len(tokens)-1].Type != lexer.TOKEN_EOF {
+			t.Errorf("lexing %q: got\n%s", test.input, lexertest.Format(Names, tokens))
+			continue
+		}
+
+		for index, want := range test.want {
+			got := tokens[index]
+
+			if got.Type != want.tokenType || got.Text != want.text || got.Value != want.value {
+				t.Errorf("lexing %q: token %d: got %s %q with value %#v, want %s %q with value %#v", test.input, index, Names.Name(got.Type), got.Text, got.Value, Names.Name(want.tokenType), want.text, want.value)
+			}
+		}
+	}
+}
+
+func TestMalformedPercentEncoding(t *testing.T) {
+	input := "/a%zz?k=%4"
+	want := []string{
+		"test:1:3: invalid percent-encoding",
+		"test:1:9: invalid percent-encoding",
+	}
+
+	l := NewLexer("test", input)
+	tokens := lexertest.Collect(t, l)
+
+	if tokens[0].Value != "a%zz" || tokens[2].Value != "%4" {
+		t.Errorf("lexing %q: got values %q and %q, want them left as written", input, tokens[0].Value, tokens[2].Value)
+	}
+
+	diagnostics := l.Diagnostics()
+	if len(diagnostics) != len(want) {
+		t.Fatalf("lexing %q: got errors %v, want %q", input, diagnostics, want)
+	}
+
+	for index, diagnostic := range diagnostics {
+		if got := diagnostic.Error(); got != want[index] {
+			t.Errorf("lexing %q: got error %q, want %q", input, got, want[index])
+		}
+	}
+}
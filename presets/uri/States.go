@@ -0,0 +1,173 @@
+package uri
+
+import (
+	"github.com/adampresley/lexer"
+)
+
+var (
+	whitespace   = lexer.WhitespaceClass
+	schemeStart  = lexer.NewCharClass("").AddRange('a', 'z').AddRange('A', 'Z')
+	schemeChars  = lexer.NewCharClass("+-.").AddRange('a', 'z').AddRange('A', 'Z').AddRange('0', '9')
+	authority    = lexer.NewCharClass("/?# \t\r\n").Not()
+	segmentChars = lexer.NewCharClass("/?# \t\r\n").Not()
+	keyChars     = lexer.NewCharClass("=&# \t\r\n").Not()
+	valueChars   = lexer.NewCharClass("&# \t\r\n").Not()
+	fragment     = lexer.NewCharClass(" \t\r\n").Not()
+)
+
+func init() {
+	lexer.NameState("uri.start", Start)
+	lexer.NameState("uri.authority", lexAuthority)
+	lexer.NameState("uri.path", lexPath)
+	lexer.NameState("uri.query", lexQuery)
+	lexer.NameState("uri.fragment", lexFragment)
+}
+
+/*
+Start is the state before each URI. It skips whitespace and lexes the
+scheme, if there is one. Whether a run of letters is a scheme is only
+known once the colon after it is seen, so the run is given back when
+there is none.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	l.AcceptClassRun(whitespace)
+	l.Ignore()
+
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	if l.AtNUL() {
+		l.Next()
+		l.Errorf("unexpected character %q", rune(0))
+		l.Ignore()
+
+		return Start
+	}
+
+	if l.AcceptClass(schemeStart) {
+		l.AcceptClassRun(schemeChars)
+
+		if l.Peek() == ':' {
+			l.Emit(TOKEN_SCHEME)
+			l.Next()
+			l.Ignore()
+		} else {
+			l.Pos = l.Start
+		}
+	}
+
+	if l.PeekCharacters(2) == "//" {
+		l.Inc(2)
+		l.Ignore()
+
+		return lexAuthority
+	}
+
+	return lexPath
+}
+
+/*
+lexAuthority lexes the authority following //, which may be empty as
+in file:///etc/hosts
+*/
+func lexAuthority(l *lexer.Lexer) lexer.LexFn {
+	acceptComponent(l, authority)
+	l.EmitWithTransform(TOKEN_AUTHORITY, PathUnescape)
+
+	return lexPath
+}
+
+/*
+lexPath lexes the path one segment at a time. A segment follows every
+slash, even when empty, so a path ending in a slash ends with an empty
+segment.
+*/
+func lexPath(l *lexer.Lexer) lexer.LexFn {
+	slash := l.Accept("/")
+	l.Ignore()
+
+	if acceptComponent(l, segmentChars) > 0 || slash {
+		l.EmitWithTransform(TOKEN_PATH_SEGMENT, PathUnescape)
+	}
+
+	switch {
+	case l.Peek() == '/':
+		return lexPath
+
+	case l.Accept("?"):
+		l.Ignore()
+		return lexQuery
+	}
+
+	return lexFragment
+}
+
+/*
+lexQuery lexes one key and value of the query, separated by an equals
+sign. A key without an equals sign has no value token, while key= has
+an empty one.
+*/
+func lexQuery(l *lexer.Lexer) lexer.LexFn {
+	if acceptComponent(l, keyChars) > 0 || l.Peek() == '=' {
+		l.EmitWithTransform(TOKEN_QUERY_KEY, QueryUnescape)
+	}
+
+	if l.Accept("=") {
+		l.Ignore()
+		acceptComponent(l, valueChars)
+		l.EmitWithTransform(TOKEN_QUERY_VALUE, QueryUnescape)
+	}
+
+	if l.Accept("&") {
+		l.Ignore()
+		return lexQuery
+	}
+
+	return lexFragment
+}
+
+/*
+lexFragment lexes the fragment, if there is one, and ends the URI
+*/
+func lexFragment(l *lexer.Lexer) lexer.LexFn {
+	if l.Accept("#") {
+		l.Ignore()
+		acceptComponent(l, fragment)
+		l.EmitWithTransform(TOKEN_FRAGMENT, PathUnescape)
+	}
+
+	return Start
+}
+
+/*
+acceptComponent consumes a run of characters in class, reporting any
+percent sign not followed by two hexadecimal digits. It returns the
+number of bytes consumed.
+*/
+func acceptComponent(l *lexer.Lexer, class *lexer.CharClass) int {
+	start := l.Pos
+
+	for l.AcceptClass(class) {
+		if l.Input[l.Pos-1] != '%' {
+			continue
+		}
+
+		if escape := l.PeekCharacters(2); len(escape) < 2 || !isHex(escape[0]) || !isHex(escape[1]) {
+			l.Report(lexer.LexError{
+				Message: "invalid percent-encoding",
+				Span: lexer.Span{
+					Start: l.PositionAt(l.Pos - 1),
+					End:   l.PositionAt(l.Pos),
+				},
+			})
+		}
+	}
+
+	return l.Pos - start
+}
+
+func isHex(ch byte) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
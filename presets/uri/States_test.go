@@ -0,0 +1,44 @@
+package uri
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestNULBytes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			"\x00",
+			`ERROR "unexpected character '\\x00'"
+EOF ""
+`,
+		},
+		{
+			"http://h\x00/a?k=v\x00#f\nmailto:x",
+			`SCHEME "http"
+AUTHORITY "h"
+ERROR "unexpected character '\\x00'"
+PATH_SEGMENT "a"
+QUERY_KEY "k"
+QUERY_VALUE "v"
+ERROR "unexpected character '\\x00'"
+FRAGMENT "f"
+SCHEME "mailto"
+PATH_SEGMENT "x"
+EOF ""
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", test.input)))
+
+		if got != test.want {
+			t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package uri
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_SCHEME lexer.TokenType = iota + 1
+	TOKEN_AUTHORITY
+	TOKEN_PATH_SEGMENT
+	TOKEN_QUERY_KEY
+	TOKEN_QUERY_VALUE
+	TOKEN_FRAGMENT
+)
+
+/*
+Names holds the names of the URI token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_SCHEME:       "SCHEME",
+	TOKEN_AUTHORITY:    "AUTHORITY",
+	TOKEN_PATH_SEGMENT: "PATH_SEGMENT",
+	TOKEN_QUERY_KEY:    "QUERY_KEY",
+	TOKEN_QUERY_VALUE:  "QUERY_VALUE",
+	TOKEN_FRAGMENT:     "FRAGMENT",
+}
+
+/*
+Categories maps the URI token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_SCHEME:       highlight.CATEGORY_KEYWORD,
+	TOKEN_AUTHORITY:    highlight.CATEGORY_TYPE,
+	TOKEN_PATH_SEGMENT: highlight.CATEGORY_STRING,
+	TOKEN_QUERY_KEY:    highlight.CATEGORY_IDENTIFIER,
+	TOKEN_QUERY_VALUE:  highlight.CATEGORY_STRING,
+	TOKEN_FRAGMENT:     highlight.CATEGORY_COMMENT,
+}
@@ -0,0 +1,59 @@
+package uri
+
+import (
+	"strings"
+)
+
+/*
+PathUnescape is the TokenValueTransformer for the scheme-independent
+components of a URI. It decodes percent-encoded bytes, leaving any
+malformed percent sign as it is.
+*/
+func PathUnescape(text string) interface{} {
+	return unescape(text, false)
+}
+
+/*
+QueryUnescape is the TokenValueTransformer for query keys and values.
+It decodes percent-encoded bytes and turns + into a space, as in the
+application/x-www-form-urlencoded format.
+*/
+func QueryUnescape(text string) interface{} {
+	return unescape(text, true)
+}
+
+func unescape(text string, plus bool) string {
+	if strings.IndexByte(text, '%') < 0 && (!plus || strings.IndexByte(text, '+') < 0) {
+		return text
+	}
+
+	result := make([]byte, 0, len(text))
+
+	for index := 0; index < len(text); index++ {
+		switch ch := text[index]; {
+		case ch == '%' && index+2 < len(text) && isHex(text[index+1]) && isHex(text[index+2]):
+			result = append(result, unhex(text[index+1])<<4|unhex(text[index+2]))
+			index += 2
+
+		case ch == '+' && plus:
+			result = append(result, ' ')
+
+		default:
+			result = append(result, ch)
+		}
+	}
+
+	return string(result)
+}
+
+func unhex(ch byte) byte {
+	switch {
+	case ch >= 'a':
+		return ch - 'a' + 10
+
+	case ch >= 'A':
+		return ch - 'A' + 10
+	}
+
+	return ch - '0'
+}
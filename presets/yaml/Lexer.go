@@ -0,0 +1,53 @@
+/*
+Package yaml is a ready-made lexer for the scalars, quoting styles,
+anchors, aliases and tags of YAML documents:
+
+	%YAML 1.2
+	---
+	server: &defaults
+	  host: 'example.com'
+	  ports: [80, 443]
+	  banner: "Welcome\tin"
+	  motd: |
+	    Line one
+	    Line two
+	mirror: *defaults
+	created: !!timestamp 2024-05-27
+
+It is not a YAML parser. Structure is left to the consumer: indicators
+such as - ? : and the flow collection brackets are emitted as tokens,
+and indentation can be read from each token's starting column.
+Plain scalars end at the end of their line, so a plain scalar continued
+on a more indented line is emitted as two tokens.
+
+Scalar tokens carry their content in Value: plain scalars as written,
+quoted scalars with their quotes removed, escapes resolved and line
+breaks folded, and block scalars with indentation removed, folding
+applied for > and chomping applied as the header asks. Anchor
+and alias tokens carry the name without & or *.
+
+Block scalars need the indentation of the line they start on, so the
+lexer keeps per-run state beyond its state function and cannot be
+checkpointed.
+*/
+package yaml
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NewLexer creates a lexer for a YAML document held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for a YAML document read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
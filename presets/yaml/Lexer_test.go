@@ -0,0 +1,100 @@
+package yaml
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestTokens(t *testing.T) {
+	input := `%YAML 1.2
+---
+server: &defaults
+  ports: [80, 443]
+  motd: |
+    Line one
+mirror: *defaults
+created: !!timestamp 2024-05-27 # comment
+? key
+- item
+...`
+	want := `DIRECTIVE "%YAML 1.2"
+DOCUMENT_START "---"
+PLAIN_SCALAR "server"
+VALUE_INDICATOR ":"
+ANCHOR "&defaults"
+PLAIN_SCALAR "ports"
+VALUE_INDICATOR ":"
+FLOW_SEQUENCE_START "["
+PLAIN_SCALAR "80"
+FLOW_ENTRY ","
+PLAIN_SCALAR "443"
+FLOW_SEQUENCE_END "]"
+PLAIN_SCALAR "motd"
+VALUE_INDICATOR ":"
+BLOCK_HEADER "|"
+BLOCK_SCALAR "    Line one\n"
+PLAIN_SCALAR "mirror"
+VALUE_INDICATOR ":"
+ALIAS "*defaults"
+PLAIN_SCALAR "created"
+VALUE_INDICATOR ":"
+TAG "!!timestamp"
+PLAIN_SCALAR "2024-05-27"
+COMMENT "# comment"
+KEY_INDICATOR "?"
+PLAIN_SCALAR "key"
+SEQUENCE_ENTRY "-"
+PLAIN_SCALAR "item"
+DOCUMENT_END "..."
+EOF ""
+`
+
+	if got := lexertest.Format(Names, lexertest.Collect(t, NewLexer("test", input))); got != want {
+		t.Errorf("lexing %q:\ngot:\n%s\nwant:\n%s", input, got, want)
+	}
+}
+
+func TestScalarValues(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"plain words", "plain words"},
+		{"'it''s'", "it's"},
+		{`"tab\tand \u00e9"`, "tab\tand é"},
+		{"\"multi\n  line\"", "multi line"},
+		{"&anchor", "anchor"},
+		{"*alias", "alias"},
+		{"|\n  one\n  two\n", "one\ntwo\n"},
+		{">-\n  a\n  b\n", "a b"},
+	}
+
+	for _, test := range tests {
+		tokens := lexertest.Collect(t, NewLexer("test", test.input))
+		last := tokens[len(tokens)-2]
+
+		if last.Value != test.want {
+			t.Errorf("lexing %q: got %s with value %q, want value %q", test.input, Names.Name(last.Type), last.Value, test.want)
+		}
+	}
+}
+
+func TestUnterminatedQuotes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`a: "open`, "test:1:4: unterminated double-quoted scalar"},
+		{"a: 'open", "test:1:4: unterminated single-quoted scalar"},
+	}
+
+	for _, test := range tests {
+		l := NewLexer("test", test.input)
+		lexertest.Collect(t, l)
+
+		if diagnostics := l.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Error() != test.want {
+			t.Errorf("lexing %q: got errors %v, want %q", test.input, diagnostics, test.want)
+		}
+	}
+}
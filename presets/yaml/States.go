@@ -0,0 +1,504 @@
+package yaml
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+const (
+	blanks         = " \t"
+	flowIndicators = ",[]{}"
+)
+
+var (
+	// Characters of anchor, alias and tag names
+	nameChars = lexer.NewCharClass(" \t\r\n" + flowIndicators).Not()
+
+	hexDigits = lexer.HexDigitClass
+)
+
+/*
+scanner holds the state of one run. The indentation of the current line
+decides where a block scalar ends, and the open flow collections decide
+which characters end a plain scalar.
+*/
+type scanner struct {
+	lineIndent int
+	flow       []rune
+	adjacent   bool
+	block      blockHeader
+}
+
+/*
+Start is the state at the beginning of a document. It sets up the state
+for a new run, so one lexer can be reused with Reset.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	s := &scanner{}
+	return s.lexLineStart
+}
+
+/*
+lexLineStart measures the indentation of a line and lexes the document
+markers and directives, which are only recognized at its first column
+*/
+func (s *scanner) lexLineStart(l *lexer.Lexer) lexer.LexFn {
+	s.lineIndent = l.AcceptRun(" ")
+	l.Ignore()
+
+	if s.lineIndent > 0 || len(s.flow) > 0 {
+		return s.lexToken
+	}
+
+	switch marker := l.PeekCharacters(4); {
+	case isMarker(marker, "---"):
+		l.Inc(3)
+		l.Emit(TOKEN_DOCUMENT_START)
+
+		// Block scalars on the marker's line may start at the first column
+		s.lineIndent = -1
+
+	case isMarker(marker, "..."):
+		l.Inc(3)
+		l.Emit(TOKEN_DOCUMENT_END)
+
+	case l.Peek() == '%':
+		for !l.IsEOF() && !isBreak(l.Peek()) {
+			l.Next()
+		}
+
+		l.Emit(TOKEN_DIRECTIVE)
+	}
+
+	return s.lexToken
+}
+
+/*
+lexToken lexes the next indicator, property or scalar on a line
+*/
+func (s *scanner) lexToken(l *lexer.Lexer) lexer.LexFn {
+	if l.AcceptRun(blanks) > 0 {
+		l.Ignore()
+		s.adjacent = false
+	}
+
+	if l.IsEOF() {
+		if len(s.flow) > 0 {
+			l.Errorf("unclosed flow collection")
+			l.Ignore()
+		}
+
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	if l.AcceptNewline() {
+		l.Ignore()
+		s.adjacent = false
+		return s.lexLineStart
+	}
+
+	// In a flow collection a : directly after a quoted scalar or a
+	// collection is a value indicator even without a space, as in JSON
+	adjacent := s.adjacent
+	s.adjacent = false
+
+	inFlow := len(s.flow) > 0
+
+	switch ch := l.Peek(); {
+	case ch == '#':
+		for !l.IsEOF() && !isBreak(l.Peek()) {
+			l.Next()
+		}
+
+		l.Emit(TOKEN_COMMENT)
+
+	case ch == '[':
+		l.Next()
+		l.Emit(TOKEN_FLOW_SEQUENCE_START)
+		s.flow = append(s.flow, ']')
+
+	case ch == '{':
+		l.Next()
+		l.Emit(TOKEN_FLOW_MAPPING_START)
+		s.flow = append(s.flow, '}')
+
+	case ch == ']' || ch == '}':
+		s.lexFlowEnd(l)
+
+	case ch == ',' && inFlow:
+		l.Next()
+		l.Emit(TOKEN_FLOW_ENTRY)
+
+	case ch == '-' && followedByBlank(l):
+		l.Next()
+		l.Emit(TOKEN_SEQUENCE_ENTRY)
+
+	case ch == '?' && followedByBlank(l):
+		l.Next()
+		l.Emit(TOKEN_KEY_INDICATOR)
+
+	case ch == ':' && (followedByBlank(l) || inFlow && (adjacent || followedByFlowIndicator(l))):
+		l.Next()
+		l.Emit(TOKEN_VALUE_INDICATOR)
+
+	case ch == '&':
+		lexName(l, TOKEN_ANCHOR, "anchor")
+
+	case ch == '*':
+		lexName(l, TOKEN_ALIAS, "alias")
+
+	case ch == '!':
+		lexTag(l)
+
+	case ch == '\'':
+		return s.lexSingleQuoted
+
+	case ch == '"':
+		return s.lexDoubleQuoted
+
+	case (ch == '|' || ch == '>') && !inFlow:
+		return s.lexBlockHeader
+
+	case strings.ContainsRune(",|>%@`", ch):
+		l.Next()
+		l.Errorf("unexpected %q", ch)
+		l.Ignore()
+
+	default:
+		s.lexPlain(l)
+	}
+
+	return s.lexToken
+}
+
+/*
+lexFlowEnd lexes the bracket closing a flow collection, which must match
+the bracket that opened it
+*/
+func (s *scanner) lexFlowEnd(l *lexer.Lexer) {
+	ch := l.Next()
+
+	if len(s.flow) == 0 || s.flow[len(s.flow)-1] != ch {
+		l.Errorf("unexpected %q", ch)
+		l.Ignore()
+		return
+	}
+
+	s.flow = s.flow[:len(s.flow)-1]
+	s.adjacent = true
+
+	if ch == ']' {
+		l.Emit(TOKEN_FLOW_SEQUENCE_END)
+	} else {
+		l.Emit(TOKEN_FLOW_MAPPING_END)
+	}
+}
+
+/*
+lexPlain lexes a plain scalar, which ends at the end of the line, at a
+comment, at a : followed by a space, and in a flow collection at the
+flow indicators. Trailing whitespace is not part of the scalar.
+*/
+func (s *scanner) lexPlain(l *lexer.Lexer) {
+	inFlow := len(s.flow) > 0
+
+	for !l.IsEOF() && !isBreak(l.Peek()) {
+		ch := l.Peek()
+
+		if ch == ' ' || ch == '\t' {
+			mark := l.Mark()
+			l.AcceptRun(blanks)
+
+			if l.IsEOF() || isBreak(l.Peek()) || l.Peek() == '#' {
+				l.Rewind(mark)
+				break
+			}
+
+			continue
+		}
+
+		if ch == ':' && (followedByBlank(l) || inFlow && followedByFlowIndicator(l)) {
+			break
+		}
+
+		if inFlow && strings.ContainsRune(flowIndicators, ch) {
+			break
+		}
+
+		l.Next()
+	}
+
+	l.EmitWithTransform(TOKEN_PLAIN_SCALAR, plain)
+}
+
+/*
+lexSingleQuoted lexes a single-quoted scalar, in which a doubled quote
+stands for one quote. Quoted scalars may span lines.
+*/
+func (s *scanner) lexSingleQuoted(l *lexer.Lexer) lexer.LexFn {
+	l.Next()
+
+	for {
+		if l.IsEOF() {
+			l.Errorf("unterminated single-quoted scalar")
+			l.Ignore()
+			return s.lexToken
+		}
+
+		if l.Next() == '\'' {
+			if l.Peek() == '\'' {
+				l.Next()
+				continue
+			}
+
+			l.EmitWithTransform(TOKEN_SINGLE_QUOTED, UnquoteSingle)
+			s.adjacent = true
+
+			return s.lexToken
+		}
+	}
+}
+
+/*
+lexDoubleQuoted lexes a double-quoted scalar, reporting unknown escape
+sequences
+*/
+func (s *scanner) lexDoubleQuoted(l *lexer.Lexer) lexer.LexFn {
+	l.Next()
+
+	for {
+		if l.IsEOF() {
+			l.Errorf("unterminated double-quoted scalar")
+			l.Ignore()
+			return s.lexToken
+		}
+
+		switch l.Next() {
+		case '"':
+			l.EmitWithTransform(TOKEN_DOUBLE_QUOTED, UnquoteDouble)
+			s.adjacent = true
+
+			return s.lexToken
+
+		case '\\':
+			lexEscape(l)
+		}
+	}
+}
+
+/*
+lexEscape checks the escape sequence after a backslash
+*/
+func lexEscape(l *lexer.Lexer) {
+	start := l.Pos - 1
+
+	if l.IsEOF() {
+		return
+	}
+
+	ch := l.Next()
+
+	if _, ok := escapes[ch]; ok || isBreak(ch) {
+		if ch == '\r' {
+			l.Accept("\n")
+		}
+
+		return
+	}
+
+	if size, ok := hexEscapes[ch]; ok {
+		for index := 0; index < size; index++ {
+			if !l.AcceptClass(hexDigits) {
+				reportAt(l, start, "invalid \\%c escape sequence", ch)
+				return
+			}
+		}
+
+		return
+	}
+
+	reportAt(l, start, "unknown escape sequence \\%c", ch)
+}
+
+/*
+lexBlockHeader lexes the indicators starting a literal or folded block
+scalar, and any comment after them on the same line
+*/
+func (s *scanner) lexBlockHeader(l *lexer.Lexer) lexer.LexFn {
+	s.block = blockHeader{folded: l.Next() == '>'}
+
+	for count := 0; count < 2; count++ {
+		ch := l.Peek()
+
+		if (ch == '+' || ch == '-') && s.block.chomp == 0 {
+			s.block.chomp = byte(ch)
+		} else if ch >= '1' && ch <= '9' && s.block.indent == 0 {
+			s.block.indent = int(ch - '0')
+		} else {
+			break
+		}
+
+		l.Next()
+	}
+
+	l.Emit(TOKEN_BLOCK_HEADER)
+
+	l.AcceptRun(blanks)
+	l.Ignore()
+
+	if l.Peek() == '#' {
+		for !l.IsEOF() && !isBreak(l.Peek()) {
+			l.Next()
+		}
+
+		l.Emit(TOKEN_COMMENT)
+	} else if !l.IsEOF() && !isBreak(l.Peek()) {
+		for !l.IsEOF() && !isBreak(l.Peek()) {
+			l.Next()
+		}
+
+		l.Errorf("unexpected text after block scalar header")
+		l.Ignore()
+	}
+
+	l.AcceptNewline()
+	l.Ignore()
+
+	return s.lexBlockScalar
+}
+
+/*
+lexBlockScalar lexes the lines of a block scalar. The content is
+indented by the header's indentation indicator more than the line the
+header is on, or without one by as much as its first non-empty line,
+which must be indented more than the header's line. Empty lines belong
+to the scalar whatever their indentation.
+*/
+func (s *scanner) lexBlockScalar(l *lexer.Lexer) lexer.LexFn {
+	indent := -1
+
+	if s.block.indent > 0 {
+		indent = s.block.indent
+		if s.lineIndent > 0 {
+			indent += s.lineIndent
+		}
+	}
+
+	for !l.IsEOF() {
+		mark := l.Mark()
+		spaces := l.AcceptRun(" ")
+
+		if l.IsEOF() || l.AcceptNewline() {
+			continue
+		}
+
+		if indent < 0 && spaces > s.lineIndent {
+			indent = spaces
+		}
+
+		if indent < 0 || spaces < indent || spaces == 0 && isMarker(l.PeekCharacters(4), "---", "...") {
+			l.Rewind(mark)
+			break
+		}
+
+		for !l.IsEOF() && !isBreak(l.Peek()) {
+			l.Next()
+		}
+
+		l.AcceptNewline()
+	}
+
+	s.block.indent = indent
+	l.EmitWithTransform(TOKEN_BLOCK_SCALAR, s.block.value)
+
+	return s.lexLineStart
+}
+
+/*
+lexName lexes an anchor or alias, which is an indicator followed by a
+name
+*/
+func lexName(l *lexer.Lexer, tokenType lexer.TokenType, what string) {
+	l.Next()
+
+	if l.AcceptClassRun(nameChars) == 0 {
+		l.Errorf("missing %s name", what)
+		l.Ignore()
+		return
+	}
+
+	l.EmitWithTransform(tokenType, name)
+}
+
+/*
+lexTag lexes a tag: a verbatim tag such as !<tag:yaml.org,2002:str>, a
+shorthand such as !!str or !local, or the non-specific tag !
+*/
+func lexTag(l *lexer.Lexer) {
+	l.Next()
+
+	if l.Peek() != '<' {
+		l.AcceptClassRun(nameChars)
+		l.Emit(TOKEN_TAG)
+		return
+	}
+
+	for !l.IsEOF() && !strings.ContainsRune(" \t\r\n", l.Peek()) {
+		if l.Next() == '>' {
+			l.Emit(TOKEN_TAG)
+			return
+		}
+	}
+
+	l.Errorf("unterminated verbatim tag")
+	l.Ignore()
+}
+
+/*
+followedByBlank returns true if the character after the next one is
+whitespace or the end of the input
+*/
+func followedByBlank(l *lexer.Lexer) bool {
+	next := l.PeekCharacters(2)
+	return len(next) < 2 || strings.IndexByte(" \t\r\n", next[1]) >= 0
+}
+
+func followedByFlowIndicator(l *lexer.Lexer) bool {
+	next := l.PeekCharacters(2)
+	return len(next) == 2 && strings.IndexByte(flowIndicators, next[1]) >= 0
+}
+
+/*
+isMarker returns true if text starts with one of the document markers
+followed by whitespace or the end of the input
+*/
+func isMarker(text string, markers ...string) bool {
+	for _, marker := range markers {
+		if strings.HasPrefix(text, marker) && (len(text) == len(marker) || strings.IndexByte(" \t\r\n", text[len(marker)]) >= 0) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isBreak(ch rune) bool {
+	return ch == '\r' || ch == '\n'
+}
+
+/*
+reportAt reports a problem within the token being lexed, which is still
+emitted
+*/
+func reportAt(l *lexer.Lexer, start int, format string, args ...interface{}) {
+	l.Report(lexer.LexError{
+		Message: fmt.Sprintf(format, args...),
+		Span: lexer.Span{
+			Start: l.PositionAt(start),
+			End:   l.PositionAt(l.Pos),
+		},
+	})
+}
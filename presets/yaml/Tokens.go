@@ -0,0 +1,81 @@
+package yaml
+
+import (
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_PLAIN_SCALAR lexer.TokenType = iota + 1
+	TOKEN_SINGLE_QUOTED
+	TOKEN_DOUBLE_QUOTED
+	TOKEN_BLOCK_HEADER
+	TOKEN_BLOCK_SCALAR
+	TOKEN_ANCHOR
+	TOKEN_ALIAS
+	TOKEN_TAG
+	TOKEN_SEQUENCE_ENTRY
+	TOKEN_KEY_INDICATOR
+	TOKEN_VALUE_INDICATOR
+	TOKEN_FLOW_SEQUENCE_START
+	TOKEN_FLOW_SEQUENCE_END
+	TOKEN_FLOW_MAPPING_START
+	TOKEN_FLOW_MAPPING_END
+	TOKEN_FLOW_ENTRY
+	TOKEN_DIRECTIVE
+	TOKEN_DOCUMENT_START
+	TOKEN_DOCUMENT_END
+	TOKEN_COMMENT
+)
+
+/*
+Names holds the names of the YAML token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_PLAIN_SCALAR:        "PLAIN_SCALAR",
+	TOKEN_SINGLE_QUOTED:       "SINGLE_QUOTED",
+	TOKEN_DOUBLE_QUOTED:       "DOUBLE_QUOTED",
+	TOKEN_BLOCK_HEADER:        "BLOCK_HEADER",
+	TOKEN_BLOCK_SCALAR:        "BLOCK_SCALAR",
+	TOKEN_ANCHOR:              "ANCHOR",
+	TOKEN_ALIAS:               "ALIAS",
+	TOKEN_TAG:                 "TAG",
+	TOKEN_SEQUENCE_ENTRY:      "SEQUENCE_ENTRY",
+	TOKEN_KEY_INDICATOR:       "KEY_INDICATOR",
+	TOKEN_VALUE_INDICATOR:     "VALUE_INDICATOR",
+	TOKEN_FLOW_SEQUENCE_START: "FLOW_SEQUENCE_START",
+	TOKEN_FLOW_SEQUENCE_END:   "FLOW_SEQUENCE_END",
+	TOKEN_FLOW_MAPPING_START:  "FLOW_MAPPING_START",
+	TOKEN_FLOW_MAPPING_END:    "FLOW_MAPPING_END",
+	TOKEN_FLOW_ENTRY:          "FLOW_ENTRY",
+	TOKEN_DIRECTIVE:           "DIRECTIVE",
+	TOKEN_DOCUMENT_START:      "DOCUMENT_START",
+	TOKEN_DOCUMENT_END:        "DOCUMENT_END",
+	TOKEN_COMMENT:             "COMMENT",
+}
+
+/*
+Categories maps the YAML token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_PLAIN_SCALAR:        highlight.CATEGORY_LITERAL,
+	TOKEN_SINGLE_QUOTED:       highlight.CATEGORY_STRING,
+	TOKEN_DOUBLE_QUOTED:       highlight.CATEGORY_STRING,
+	TOKEN_BLOCK_HEADER:        highlight.CATEGORY_OPERATOR,
+	TOKEN_BLOCK_SCALAR:        highlight.CATEGORY_STRING,
+	TOKEN_ANCHOR:              highlight.CATEGORY_IDENTIFIER,
+	TOKEN_ALIAS:               highlight.CATEGORY_IDENTIFIER,
+	TOKEN_TAG:                 highlight.CATEGORY_TYPE,
+	TOKEN_SEQUENCE_ENTRY:      highlight.CATEGORY_PUNCTUATION,
+	TOKEN_KEY_INDICATOR:       highlight.CATEGORY_PUNCTUATION,
+	TOKEN_VALUE_INDICATOR:     highlight.CATEGORY_PUNCTUATION,
+	TOKEN_FLOW_SEQUENCE_START: highlight.CATEGORY_PUNCTUATION,
+	TOKEN_FLOW_SEQUENCE_END:   highlight.CATEGORY_PUNCTUATION,
+	TOKEN_FLOW_MAPPING_START:  highlight.CATEGORY_PUNCTUATION,
+	TOKEN_FLOW_MAPPING_END:    highlight.CATEGORY_PUNCTUATION,
+	TOKEN_FLOW_ENTRY:          highlight.CATEGORY_PUNCTUATION,
+	TOKEN_DIRECTIVE:           highlight.CATEGORY_PREPROCESSOR,
+	TOKEN_DOCUMENT_START:      highlight.CATEGORY_PREPROCESSOR,
+	TOKEN_DOCUMENT_END:        highlight.CATEGORY_PREPROCESSOR,
+	TOKEN_COMMENT:             highlight.CATEGORY_COMMENT,
+}
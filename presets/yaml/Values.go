@@ -0,0 +1,299 @@
+package yaml
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The single character escapes of double-quoted scalars
+var escapes = map[rune]string{
+	'0':  "\x00",
+	'a':  "\a",
+	'b':  "\b",
+	't':  "\t",
+	'\t': "\t",
+	'n':  "\n",
+	'v':  "\v",
+	'f':  "\f",
+	'r':  "\r",
+	'e':  "\x1b",
+	' ':  " ",
+	'"':  "\"",
+	'/':  "/",
+	'\\': "\\",
+	'N':  "\u0085",
+	'_':  " ",
+	'L':  " ",
+	'P':  " ",
+}
+
+// The number of hex digits after each code point escape
+var hexEscapes = map[rune]int{
+	'x': 2,
+	'u': 4,
+	'U': 8,
+}
+
+/*
+blockHeader holds the indicators of a block scalar. indent is the
+indentation of its content once lexed, or -1 if it has none.
+*/
+type blockHeader struct {
+	folded bool
+	chomp  byte
+	indent int
+}
+
+func plain(text string) interface{} {
+	return text
+}
+
+func name(text string) interface{} {
+	return text[1:]
+}
+
+/*
+UnquoteSingle is the TokenValueTransformer for single-quoted scalars.
+It removes the quotes, turns doubled quotes into one, and folds line
+breaks.
+*/
+func UnquoteSingle(text string) interface{} {
+	text = text[1 : len(text)-1]
+
+	var result strings.Builder
+	result.Grow(len(text))
+
+	for index := 0; index < len(text); {
+		switch ch := text[index]; {
+		case ch == '\'' && index+1 < len(text):
+			result.WriteByte('\'')
+			index += 2
+
+		case isSpace(ch):
+			index = fold(&result, text, index, false)
+
+		default:
+			result.WriteByte(ch)
+			index++
+		}
+	}
+
+	return result.String()
+}
+
+/*
+UnquoteDouble is the TokenValueTransformer for double-quoted scalars.
+It removes the quotes, resolves escape sequences, and folds line
+breaks. Invalid escapes, which were reported when lexing, are kept as
+written.
+*/
+func UnquoteDouble(text string) interface{} {
+	text = text[1 : len(text)-1]
+
+	var result strings.Builder
+	result.Grow(len(text))
+
+	for index := 0; index < len(text); {
+		ch := text[index]
+
+		if isSpace(ch) {
+			index = fold(&result, text, index, false)
+			continue
+		}
+
+		if ch != '\\' || index+1 == len(text) {
+			result.WriteByte(ch)
+			index++
+			continue
+		}
+
+		escape := rune(text[index+1])
+		index += 2
+
+		if replacement, ok := escapes[escape]; ok {
+			result.WriteString(replacement)
+			continue
+		}
+
+		if isBreak(escape) {
+			// An escaped line break joins the lines without a space
+			if escape == '\r' && index < len(text) && text[index] == '\n' {
+				index++
+			}
+
+			index = fold(&result, text, index, true)
+			continue
+		}
+
+		size := hexEscapes[escape]
+
+		if size > 0 && index+size <= len(text) {
+			if code, err := strconv.ParseUint(text[index:index+size], 16, 32); err == nil && utf8.ValidRune(rune(code)) {
+				result.WriteRune(rune(code))
+				index += size
+				continue
+			}
+		}
+
+		result.WriteByte('\\')
+		result.WriteRune(escape)
+	}
+
+	return result.String()
+}
+
+/*
+fold writes the whitespace starting at index, returning the index after
+it. Whitespace within a line is kept. Whitespace spanning a line break
+is folded: a single line break becomes a space, and each empty line
+after it a line feed. After an escaped line break each empty line is a
+line feed and nothing else is written.
+*/
+func fold(result *strings.Builder, text string, index int, escaped bool) int {
+	end := index
+	breaks := 0
+
+	for end < len(text) && isSpace(text[end]) {
+		if text[end] == '\n' || text[end] == '\r' && (end+1 == len(text) || text[end+1] != '\n') {
+			breaks++
+		}
+
+		end++
+	}
+
+	switch {
+	case escaped:
+		result.WriteString(strings.Repeat("\n", breaks))
+
+	case breaks == 0:
+		result.WriteString(text[index:end])
+
+	case breaks == 1:
+		result.WriteByte(' ')
+
+	default:
+		result.WriteString(strings.Repeat("\n", breaks-1))
+	}
+
+	return end
+}
+
+/*
+value is the TokenValueTransformer for block scalars. It removes the
+content's indentation, folds lines if the scalar is folded, and applies
+the chomping indicator to the line breaks at the end: - strips them all,
++ keeps them all, and without an indicator a single line break is kept.
+*/
+func (header blockHeader) value(text string) interface{} {
+	lines, finalBreak := splitLines(text)
+
+	last := -1
+	for index, line := range lines {
+		if !header.isEmpty(line) {
+			last = index
+		}
+	}
+
+	var result strings.Builder
+
+	empty := 0
+	first := true
+	previousMore := false
+
+	for _, line := range lines[:last+1] {
+		if header.isEmpty(line) {
+			empty++
+			continue
+		}
+
+		line = line[header.indent:]
+
+		// More indented lines are never folded
+		more := line[0] == ' ' || line[0] == '\t'
+
+		switch {
+		case first:
+			result.WriteString(strings.Repeat("\n", empty))
+
+		case header.folded && !more && !previousMore && empty == 0:
+			result.WriteByte(' ')
+
+		case header.folded && !more && !previousMore:
+			result.WriteString(strings.Repeat("\n", empty))
+
+		default:
+			result.WriteString(strings.Repeat("\n", empty+1))
+		}
+
+		result.WriteString(line)
+
+		first = false
+		previousMore = more
+		empty = 0
+	}
+
+	// The line breaks after the last line of content, if any, and after
+	// each empty line following it
+	trailing := len(lines) - last - 1
+	if finalBreak {
+		trailing++
+	}
+
+	// Without content the first line break is the header's own, and an
+	// empty block, as in "a: |+" at the end of the input, has none
+	if last < 0 {
+		trailing = max(trailing-1, 0)
+	}
+
+	if header.chomp == '+' {
+		result.WriteString(strings.Repeat("\n", trailing))
+	} else if header.chomp != '-' && last >= 0 && trailing > 0 {
+		result.WriteByte('\n')
+	}
+
+	return result.String()
+}
+
+/*
+isEmpty returns true if line holds nothing but indentation
+*/
+func (header blockHeader) isEmpty(line string) bool {
+	return strings.TrimLeft(line, " ") == "" && (header.indent < 0 || len(line) <= header.indent)
+}
+
+/*
+splitLines splits text at its line breaks, returning whether the last
+line ended with one
+*/
+func splitLines(text string) ([]string, bool) {
+	var lines []string
+
+	for start, index := 0, 0; index < len(text); index++ {
+		if text[index] != '\n' && text[index] != '\r' {
+			continue
+		}
+
+		lines = append(lines, text[start:index])
+
+		if text[index] == '\r' && index+1 < len(text) && text[index+1] == '\n' {
+			index++
+		}
+
+		start = index + 1
+
+		if start == len(text) {
+			return lines, true
+		}
+	}
+
+	if text == "" {
+		return nil, false
+	}
+
+	return append(lines, text[strings.LastIndexAny(text, "\r\n")+1:]), false
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n'
+}
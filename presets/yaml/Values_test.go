@@ -0,0 +1,41 @@
+package yaml
+
+import (
+	"testing"
+
+	"github.com/adampresley/lexer/internal/lexertest"
+)
+
+func TestKeepChompingEmptyBlock(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"|+", ""},
+		{">+", ""},
+		{"a: |+", ""},
+		{"a: |+\n", ""},
+		{"a: |+\n\n", "\n"},
+		{"a: |+\n  x\n\n", "x\n\n"},
+	}
+
+	for _, test := range tests {
+		found := false
+
+		for _, token := range lexertest.Collect(t, NewLexer("test", test.input)) {
+			if token.Type != TOKEN_BLOCK_SCALAR {
+				continue
+			}
+
+			found = true
+
+			if token.Value != test.want {
+				t.Errorf("lexing %q: got block scalar %q, want %q", test.input, token.Value, test.want)
+			}
+		}
+
+		if !found {
+			t.Errorf("lexing %q: no block scalar", test.input)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package rules
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+/*
+Class defines a named character class for the patterns of rules added
+after it, which use it as \p{name}, or \P{name} for the characters not
+in it, both on their own and inside brackets. Unicode categories and
+scripts such as \p{L}, \p{Nd} and \p{Greek} need no definition, and a
+class may be built from them and from classes defined before it:
+
+	ruleSet.
+		Class("IdentStart", `[\p{L}\p{Nl}_]`).
+		Class("IdentPart", `[\p{IdentStart}\p{Mn}\p{Mc}\p{Nd}\p{Pc}]`).
+		Add("ident", `\p{IdentStart}\p{IdentPart}*`, TOKEN_IDENT)
+
+The pattern must match exactly one character. A name may not be reused
+or hide a Unicode category or script.
+*/
+func (ruleSet *RuleSet) Class(name string, pattern string) *RuleSet {
+	if ruleSet.err != nil {
+		return ruleSet
+	}
+
+	if !isIdentifier(name) || unicode.Categories[name] != nil || unicode.Scripts[name] != nil || name == "Any" {
+		ruleSet.err = fmt.Errorf("rules: invalid class name %q", name)
+		return ruleSet
+	}
+
+	if _, ok := ruleSet.classes[name]; ok {
+		ruleSet.err = fmt.Errorf("rules: class %s defined twice", name)
+		return ruleSet
+	}
+
+	ranges, err := classRanges(ruleSet.expand(pattern))
+	if err != nil {
+		ruleSet.err = fmt.Errorf("rules: class %s: %w", name, err)
+		return ruleSet
+	}
+
+	if ruleSet.classes == nil {
+		ruleSet.classes = map[string][]runeRange{}
+	}
+
+	ruleSet.classes[name] = ranges
+	return ruleSet
+}
+
+/*
+classRanges returns the characters a pattern matching a single
+character matches
+*/
+func classRanges(pattern string) ([]runeRange, error) {
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed = parsed.Simplify()
+
+	switch parsed.Op {
+	case syntax.OpCharClass:
+		ranges := make([]runeRange, 0, len(parsed.Rune)/2)
+		for index := 0; index+1 < len(parsed.Rune); index += 2 {
+			ranges = append(ranges, runeRange{parsed.Rune[index], parsed.Rune[index+1]})
+		}
+
+		return ranges, nil
+
+	case syntax.OpLiteral:
+		if len(parsed.Rune) == 1 {
+			if parsed.Flags&syntax.FoldCase != 0 {
+				return normalizeRanges(foldRanges(parsed.Rune[0])), nil
+			}
+
+			return []runeRange{{parsed.Rune[0], parsed.Rune[0]}}, nil
+		}
+
+	case syntax.OpAnyCharNotNL:
+		return []runeRange{{0, '\n' - 1}, {'\n' + 1, unicode.MaxRune}}, nil
+
+	case syntax.OpAnyChar:
+		return []runeRange{{0, unicode.MaxRune}}, nil
+	}
+
+	return nil, fmt.Errorf("pattern %q does not match a single character", pattern)
+}
+
+/*
+expand replaces the uses of the rule set's classes in a pattern with the
+characters they stand for, leaving Unicode properties to the regexp
+parser, and the uses of its fragments with their patterns. A reference
+to a fragment that is not defined is recorded as an error.
+*/
+func (ruleSet *RuleSet) expand(pattern string) string {
+	if len(ruleSet.classes) == 0 && !strings.Contains(pattern, "{") {
+		return pattern
+	}
+
+	result := strings.Builder{}
+	inClass := false
+
+	for index := 0; index < len(pattern); index++ {
+		ch := pattern[index]
+
+		switch {
+		case ch == '\\' && index+1 < len(pattern):
+			if name, length, ok := classReference(pattern[index:]); ok {
+				if ranges, defined := ruleSet.classes[name]; defined {
+					if pattern[index+1] == 'P' {
+						ranges = subtractRanges([]runeRange{{0, unicode.MaxRune}}, ranges)
+					}
+
+					writeClass(&result, ranges, inClass)
+					index += length - 1
+					continue
+				}
+			}
+
+			result.WriteString(pattern[index : index+2])
+			index++
+			continue
+
+		case ch == '[' && !inClass:
+			inClass = true
+			result.WriteByte(ch)
+
+			// A ] first in a class, after any ^, is a member
+			if index+1 < len(pattern) && pattern[index+1] == '^' {
+				index++
+				result.WriteByte('^')
+			}
+
+			if index+1 < len(pattern) && pattern[index+1] == ']' {
+				index++
+				result.WriteByte(']')
+			}
+
+			continue
+
+		case ch == '[' && strings.HasPrefix(pattern[index:], "[:"):
+			if end := strings.Index(pattern[index:], ":]"); end >= 0 {
+				result.WriteString(pattern[index : index+end+2])
+				index += end + 1
+				continue
+			}
+
+		case ch == ']':
+			inClass = false
+
+		case ch == '{' && !inClass:
+			if name, length, ok := fragmentReference(pattern[index:]); ok {
+				fragment, defined := ruleSet.fragments[name]
+				if !defined && ruleSet.err == nil {
+					ruleSet.err = fmt.Errorf("rules: undefined fragment {%s}", name)
+				}
+
+				result.WriteString("(?:" + fragment + ")")
+				index += length - 1
+				continue
+			}
+		}
+
+		result.WriteByte(ch)
+	}
+
+	return result.String()
+}
+
+/*
+classReference reads a \p{name} or \P{name} at the start of text,
+returning the name and the length of the reference
+*/
+func classReference(text string) (string, int, bool) {
+	if len(text) < 4 || text[1] != 'p' && text[1] != 'P' || text[2] != '{' {
+		return "", 0, false
+	}
+
+	end := strings.IndexByte(text, '}')
+	if end < 0 {
+		return "", 0, false
+	}
+
+	return text[3:end], end + 1, true
+}
+
+/*
+writeClass writes ranges in regexp syntax, as a bracketed class or, with
+inClass, as members of the class being written
+*/
+func writeClass(result *strings.Builder, ranges []runeRange, inClass bool) {
+	if !inClass {
+		if len(ranges) == 0 {
+			result.WriteString(`[^\x00-\x{10FFFF}]`)
+			return
+		}
+
+		result.WriteByte('[')
+	}
+
+	for _, r := range ranges {
+		fmt.Fprintf(result, `\x{%X}`, r.lo)
+
+		if r.hi != r.lo {
+			fmt.Fprintf(result, `-\x{%X}`, r.hi)
+		}
+	}
+
+	if !inClass {
+		result.WriteByte(']')
+	}
+}
+
+func sameRanges(a []runeRange, b []runeRange) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for index := range a {
+		if a[index] != b[index] {
+			return false
+		}
+	}
+
+	return true
+}
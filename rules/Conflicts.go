@@ -0,0 +1,265 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+/*
+A ConflictKind says how two rules of a rule set get in each other's way
+*/
+type ConflictKind int
+
+const (
+	CONFLICT_SHADOWED ConflictKind = iota + 1
+	CONFLICT_OVERLAP
+)
+
+func (kind ConflictKind) String() string {
+	switch kind {
+	case CONFLICT_SHADOWED:
+		return "shadowed"
+
+	case CONFLICT_OVERLAP:
+		return "overlap"
+	}
+
+	return "unknown"
+}
+
+/*
+Conflict describes rules that match the same text. For CONFLICT_SHADOWED
+the rule can never produce a token, as Winner takes everything it
+matches; this is almost always a mistake, such as a keyword added after
+the identifier rule. For CONFLICT_OVERLAP both rules produce tokens but
+some text is matched by both and goes to Winner, which is often
+intended, as with keywords before identifiers, but worth reviewing.
+
+Rule and Winner are indexes into the rule set. Example is the shortest
+input both rules match in full. Winner is -1 and Example empty for a
+rule that matches nothing at all, such as one with an empty class.
+*/
+type Conflict struct {
+	Kind       ConflictKind
+	Rule       int
+	RuleName   string
+	Winner     int
+	WinnerName string
+	Example    string
+}
+
+/*
+String describes the conflict in a sentence for build output
+*/
+func (conflict Conflict) String() string {
+	if conflict.Winner < 0 {
+		return fmt.Sprintf("rule %q can never match: it matches no input", conflict.RuleName)
+	}
+
+	example := strconv.Quote(conflict.Example)
+
+	if conflict.Kind == CONFLICT_SHADOWED {
+		return fmt.Sprintf("rule %q can never match: rule %q wins on everything it matches, such as %s", conflict.RuleName, conflict.WinnerName, example)
+	}
+
+	return fmt.Sprintf("rules %q and %q both match %s, which goes to %q", conflict.WinnerName, conflict.RuleName, example, conflict.WinnerName)
+}
+
+/*
+Conflicts analyzes the rule set for rules that can never match and
+rules that overlap. It fails if the rule set does not compile. The
+analysis follows the DFAs Compile builds: at each input the longest
+match wins and ties go to the earlier rule, so a rule can never match if
+in every state of the DFA where it accepts an earlier rule without
+lookahead accepts too. With MATCH_FIRST an earlier rule that accepted
+anywhere on the way to the state wins too.
+Only rules of the same state are compared. Shadowed rules are listed
+first, then overlaps, each in rule order.
+*/
+func (ruleSet *RuleSet) Conflicts() ([]Conflict, error) {
+	if err := ruleSet.check(); err != nil {
+		return nil, err
+	}
+
+	var shadowed, overlaps []Conflict
+
+	for _, state := range ruleSet.states {
+		rules, indexes := ruleSet.stateRules(state)
+
+		automaton, err := buildNFA(rules)
+		if err != nil {
+			return nil, err
+		}
+
+		builder, start, err := buildDFA(rules, automaton)
+		if err != nil {
+			return nil, err
+		}
+
+		stateShadowed, stateOverlaps := stateConflicts(builder, start, rules, ruleSet.strategy)
+
+		for _, conflicts := range [][]Conflict{stateShadowed, stateOverlaps} {
+			for index := range conflicts {
+				conflicts[index].Rule = indexes[conflicts[index].Rule]
+
+				if conflicts[index].Winner >= 0 {
+					conflicts[index].Winner = indexes[conflicts[index].Winner]
+				}
+			}
+		}
+
+		shadowed = append(shadowed, stateShadowed...)
+		overlaps = append(overlaps, stateOverlaps...)
+	}
+
+	sort.SliceStable(shadowed, func(i, j int) bool {
+		return shadowed[i].Rule < shadowed[j].Rule
+	})
+
+	sort.SliceStable(overlaps, func(i, j int) bool {
+		if overlaps[i].Rule != overlaps[j].Rule {
+			return overlaps[i].Rule < overlaps[j].Rule
+		}
+
+		return overlaps[i].Winner < overlaps[j].Winner
+	})
+
+	return append(append([]Conflict{}, shadowed...), overlaps...), nil
+}
+
+/*
+stateConflicts finds the conflicts among the rules of one state, with
+rule indexes local to the state. It searches the DFA breadth first for
+the shortest input reaching each state, so the first input recorded for
+a rule or pair is the shortest example. For MATCH_FIRST the search also
+carries the earliest rule without lookahead that matched a prefix of
+the input, as that rule wins whatever follows.
+*/
+func stateConflicts(builder *dfaBuilder, start int32, rules []Rule, strategy Strategy) ([]Conflict, []Conflict) {
+	type node struct {
+		state   int32
+		blocker int
+	}
+
+	accepted := make([][]int, len(builder.sets))
+	for state, set := range builder.sets {
+		for _, nfaState := range set {
+			if rule := builder.automaton.states[nfaState].accept; rule >= 0 {
+				accepted[state] = append(accepted[state], rule)
+			}
+		}
+
+		sort.Ints(accepted[state])
+	}
+
+	wins := make([]bool, len(rules))
+	lost := map[int]Conflict{}
+	firstOverlap := map[[2]int]string{}
+
+	examples := map[node]string{{start, -1}: ""}
+	queue := []node{{start, -1}}
+
+	for head := 0; head < len(queue); head++ {
+		current := queue[head]
+		example := examples[current]
+		rulesHere := accepted[current.state]
+
+		// A rule with lookahead gives way to the rules after it when the
+		// lookahead fails, so only the first rule without one blocks
+		// the rest
+		blocker := current.blocker
+		for _, rule := range rulesHere {
+			if rules[rule].Lookahead == "" {
+				if blocker < 0 || rule < blocker {
+					blocker = rule
+				}
+
+				break
+			}
+		}
+
+		for _, rule := range rulesHere {
+			if blocker < 0 || rule <= blocker {
+				wins[rule] = true
+				continue
+			}
+
+			if _, ok := lost[rule]; !ok {
+				lost[rule] = Conflict{Winner: blocker, Example: example}
+			}
+		}
+
+		for index := 1; index < len(rulesHere); index++ {
+			pair := [2]int{rulesHere[0], rulesHere[index]}
+			if _, ok := firstOverlap[pair]; !ok {
+				firstOverlap[pair] = example
+			}
+		}
+
+		if strategy != MATCH_FIRST {
+			blocker = -1
+		}
+
+		for _, transition := range builder.ranges[current.state] {
+			next := node{transition.next, blocker}
+			if _, seen := examples[next]; seen {
+				continue
+			}
+
+			examples[next] = example + string(exampleRune(transition))
+			queue = append(queue, next)
+		}
+	}
+
+	var shadowed, overlaps []Conflict
+
+	for rule, won := range wins {
+		if won {
+			continue
+		}
+
+		conflict := Conflict{Kind: CONFLICT_SHADOWED, Rule: rule, RuleName: rules[rule].Name, Winner: -1}
+
+		if loss, ok := lost[rule]; ok {
+			conflict.Winner = loss.Winner
+			conflict.WinnerName = rules[loss.Winner].Name
+			conflict.Example = loss.Example
+		}
+
+		shadowed = append(shadowed, conflict)
+	}
+
+	for pair, example := range firstOverlap {
+		if !wins[pair[1]] {
+			continue
+		}
+
+		overlaps = append(overlaps, Conflict{
+			Kind:       CONFLICT_OVERLAP,
+			Rule:       pair[1],
+			RuleName:   rules[pair[1]].Name,
+			Winner:     pair[0],
+			WinnerName: rules[pair[0]].Name,
+			Example:    example,
+		})
+	}
+
+	return shadowed, overlaps
+}
+
+/*
+exampleRune picks the character of a transition to use in examples,
+preferring printable characters so examples read well
+*/
+func exampleRune(transition dfaTransition) rune {
+	switch {
+	case transition.lo <= 'a' && transition.hi >= 'a':
+		return 'a'
+
+	case transition.lo < '!' && transition.hi >= '!':
+		return '!'
+	}
+
+	return transition.lo
+}
@@ -0,0 +1,210 @@
+package rules
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Coverage counts how the rules of a machine fare on a corpus of sample
+input, so that a grammar can be checked against real text before it
+ships. A rule that never fired is either dead or untested, and a
+character no rule matched is a gap in the grammar.
+
+	coverage := machine.Coverage()
+
+	for name, sample := range samples {
+		coverage.Lex(name, sample)
+	}
+
+	if !coverage.Complete() {
+		fmt.Print(coverage)
+	}
+
+A Coverage is not safe for concurrent use.
+*/
+type Coverage struct {
+	machine   *Machine
+	hits      []int
+	unmatched map[rune]*UnmatchedCharacter
+	samples   int
+}
+
+/*
+RuleCoverage is the number of tokens a rule matched in a corpus. Rule
+is the index of the rule in the rule set.
+*/
+type RuleCoverage struct {
+	Rule  int
+	Name  string
+	State string
+	Hits  int
+}
+
+/*
+UnmatchedCharacter is a character no rule matched, reported by the
+lexer with Errorf. Count is how often it happened in the corpus and
+First where it happened first.
+*/
+type UnmatchedCharacter struct {
+	Character rune
+	Count     int
+	First     lexer.Position
+}
+
+/*
+Coverage returns a Coverage that lexes samples with a copy of the
+machine, counting the matches of each rule and the characters no rule
+matched. Actions run as usual, but hand-written state functions must
+hand lexing back through the copy's states to be counted, which
+Coverage.StateFn gives.
+*/
+func (machine *Machine) Coverage() *Coverage {
+	covered := *machine
+
+	coverage := &Coverage{
+		machine:   &covered,
+		hits:      make([]int, len(machine.rules)),
+		unmatched: map[rune]*UnmatchedCharacter{},
+	}
+
+	covered.coverage = coverage
+	covered.buildFunctions()
+
+	return coverage
+}
+
+/*
+Lex runs the machine over one sample, counting what it matched. The
+tokens are thrown away. name is used for the positions of unmatched
+characters.
+*/
+func (coverage *Coverage) Lex(name string, input string, options ...lexer.Option) {
+	coverage.samples++
+	coverage.machine.NewLexer(name, input, options...).RunWith(func(lexer.Token) {})
+}
+
+/*
+LexReader runs the machine over one sample read from reader, as Lex does
+*/
+func (coverage *Coverage) LexReader(name string, reader io.Reader, options ...lexer.Option) {
+	coverage.samples++
+	lexer.NewReaderLexer(name, reader, coverage.machine.LexFn(), options...).RunWith(func(lexer.Token) {})
+}
+
+/*
+StateFn returns the state function of the named state of the machine
+being counted, for hand-written state functions to hand lexing back
+to, or nil if there is no such state
+*/
+func (coverage *Coverage) StateFn(state string) lexer.LexFn {
+	return coverage.machine.StateFn(state)
+}
+
+/*
+Samples returns the number of samples lexed
+*/
+func (coverage *Coverage) Samples() int {
+	return coverage.samples
+}
+
+/*
+Rules returns the hit count of every rule, in rule order
+*/
+func (coverage *Coverage) Rules() []RuleCoverage {
+	result := make([]RuleCoverage, len(coverage.hits))
+
+	for index, rule := range coverage.machine.rules {
+		result[index] = RuleCoverage{Rule: index, Name: rule.Name, State: rule.State, Hits: coverage.hits[index]}
+	}
+
+	return result
+}
+
+/*
+Unused returns the rules that matched nothing in the corpus, in rule
+order
+*/
+func (coverage *Coverage) Unused() []RuleCoverage {
+	result := []RuleCoverage{}
+
+	for _, rule := range coverage.Rules() {
+		if rule.Hits == 0 {
+			result = append(result, rule)
+		}
+	}
+
+	return result
+}
+
+/*
+Unmatched returns the characters no rule matched, most frequent first
+*/
+func (coverage *Coverage) Unmatched() []UnmatchedCharacter {
+	result := make([]UnmatchedCharacter, 0, len(coverage.unmatched))
+
+	for _, unmatched := range coverage.unmatched {
+		result = append(result, *unmatched)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+
+		return result[i].Character < result[j].Character
+	})
+
+	return result
+}
+
+/*
+Complete returns true if every rule matched something and every
+character of the corpus was matched by a rule
+*/
+func (coverage *Coverage) Complete() bool {
+	return len(coverage.Unused()) == 0 && len(coverage.unmatched) == 0
+}
+
+/*
+String reports the coverage for build output: a line per rule with its
+hit count, marking the rules that never fired, then a line per
+unmatched character
+*/
+func (coverage *Coverage) String() string {
+	result := strings.Builder{}
+	fmt.Fprintf(&result, "%d samples\n", coverage.samples)
+
+	for _, rule := range coverage.Rules() {
+		mark := ""
+		if rule.Hits == 0 {
+			mark = "  never fired"
+		}
+
+		fmt.Fprintf(&result, "%8d  %s %s%s\n", rule.Hits, rule.State, rule.Name, mark)
+	}
+
+	for _, unmatched := range coverage.Unmatched() {
+		fmt.Fprintf(&result, "%8d  unmatched %q, first at %s\n", unmatched.Count, unmatched.Character, unmatched.First)
+	}
+
+	return result.String()
+}
+
+/*
+miss records a character no rule matched, at the start of the lexer's
+current token
+*/
+func (coverage *Coverage) miss(l *lexer.Lexer, ch rune) {
+	unmatched, ok := coverage.unmatched[ch]
+	if !ok {
+		unmatched = &UnmatchedCharacter{Character: ch, First: l.PositionAt(l.Start)}
+		coverage.unmatched[ch] = unmatched
+	}
+
+	unmatched.Count++
+}
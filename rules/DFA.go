@@ -0,0 +1,449 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const maxDFAStates = 1 << 16
+
+type dfaTransition struct {
+	lo   rune
+	hi   rune
+	next int32
+}
+
+type dfaBuilder struct {
+	automaton *nfa
+	ids       map[string]int32
+	sets      [][]int
+	accepts   []int32
+	ranges    [][]dfaTransition
+}
+
+/*
+dfaTable is the compiled DFA of one state. Its transition table holds a
+row of 128 entries per DFA state for ASCII input, and sorted rune ranges
+for everything else. accepts holds the index in the whole rule set of
+the rule each DFA state accepts, or -1.
+
+When rules of the state have lookahead, choices holds for each DFA state
+the rules it accepts in order of priority, up to the first without
+lookahead, and lookaheads the compiled lookahead of each of them, so
+that a rule whose lookahead fails gives way to the next.
+
+first is set for the MATCH_FIRST strategy.
+*/
+type dfaTable struct {
+	start      int32
+	accepts    []int32
+	ascii      []int32
+	wide       [][]dfaTransition
+	choices    [][]int32
+	lookaheads map[int32]*lookahead
+	first      bool
+}
+
+/*
+lookahead is the compiled lookahead of a rule, matched where the rule's
+match ends
+*/
+type lookahead struct {
+	table   *dfaTable
+	negated bool
+}
+
+/*
+buildTable compiles the rules of one state. indexes gives the index of
+each rule in the whole rule set.
+*/
+func buildTable(rules []Rule, indexes []int, automaton *nfa) (*dfaTable, error) {
+	builder, start, err := buildDFA(rules, automaton)
+	if err != nil {
+		return nil, err
+	}
+
+	lookaheads := map[int32]*lookahead{}
+
+	for index, rule := range rules {
+		if rule.Lookahead == "" {
+			continue
+		}
+
+		compiled, err := buildLookahead(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		lookaheads[int32(indexes[index])] = compiled
+	}
+
+	labels := builder.accepts
+	var lists [][]int32
+
+	if len(lookaheads) > 0 {
+		labels, lists = builder.choices(indexes, lookaheads)
+	}
+
+	start, accepts, ranges := minimize(start, labels, builder.ranges)
+
+	table := &dfaTable{
+		start:   start,
+		accepts: accepts,
+		ascii:   make([]int32, len(accepts)*128),
+		wide:    make([][]dfaTransition, len(accepts)),
+	}
+
+	if lists != nil {
+		table.choices = make([][]int32, len(accepts))
+		table.lookaheads = lookaheads
+
+		for state, label := range accepts {
+			accepts[state] = -1
+
+			if label >= 0 {
+				table.choices[state] = lists[label]
+				accepts[state] = lists[label][0]
+			}
+		}
+	} else {
+		for state, accept := range accepts {
+			if accept >= 0 {
+				accepts[state] = int32(indexes[accept])
+			}
+		}
+	}
+
+	for state, transitions := range ranges {
+		row := table.ascii[state*128 : state*128+128]
+		for index := range row {
+			row[index] = -1
+		}
+
+		for _, transition := range transitions {
+			for ch := transition.lo; ch <= transition.hi && ch < 128; ch++ {
+				row[ch] = transition.next
+			}
+
+			if transition.hi >= 128 {
+				wide := transition
+				if wide.lo < 128 {
+					wide.lo = 128
+				}
+
+				table.wide[state] = append(table.wide[state], wide)
+			}
+		}
+	}
+
+	return table, nil
+}
+
+/*
+buildLookahead compiles the lookahead pattern of a rule
+*/
+func buildLookahead(rule Rule) (*lookahead, error) {
+	rules := []Rule{{Name: rule.Name, Pattern: rule.Lookahead, IgnoreCase: rule.IgnoreCase}}
+
+	automaton, err := buildNFA(rules)
+	if err != nil {
+		return nil, fmt.Errorf("rules: lookahead of %s", strings.TrimPrefix(err.Error(), "rules: "))
+	}
+
+	table, err := buildTable(rules, []int{0}, automaton)
+	if err != nil {
+		return nil, fmt.Errorf("rules: lookahead of %s", strings.TrimPrefix(err.Error(), "rules: "))
+	}
+
+	return &lookahead{table: table, negated: rule.LookaheadNegated}, nil
+}
+
+/*
+choices lists the rules each DFA state accepts, in order of priority up
+to the first without lookahead, as indexes in the whole rule set. It
+returns a label for each DFA state, the same for states with the same
+list, and the list of each label, so that minimize only merges states
+that choose alike.
+*/
+func (builder *dfaBuilder) choices(indexes []int, lookaheads map[int32]*lookahead) ([]int32, [][]int32) {
+	labels := make([]int32, len(builder.sets))
+	lists := [][]int32{}
+	ids := map[string]int32{}
+
+	for state, set := range builder.sets {
+		labels[state] = -1
+
+		var accepted []int
+		for _, nfaState := range set {
+			if rule := builder.automaton.states[nfaState].accept; rule >= 0 {
+				accepted = append(accepted, rule)
+			}
+		}
+
+		if len(accepted) == 0 {
+			continue
+		}
+
+		sort.Ints(accepted)
+
+		list := []int32{}
+		key := strings.Builder{}
+
+		for _, rule := range accepted {
+			global := int32(indexes[rule])
+			list = append(list, global)
+			fmt.Fprintf(&key, "%d,", global)
+
+			if lookaheads[global] == nil {
+				break
+			}
+		}
+
+		id, ok := ids[key.String()]
+		if !ok {
+			id = int32(len(lists))
+			ids[key.String()] = id
+			lists = append(lists, list)
+		}
+
+		labels[state] = id
+	}
+
+	return labels, lists
+}
+
+/*
+buildDFA runs the subset construction, returning the builder holding the
+DFA's states and the index of the start state
+*/
+func buildDFA(rules []Rule, automaton *nfa) (*dfaBuilder, int32, error) {
+	builder := &dfaBuilder{
+		automaton: automaton,
+		ids:       map[string]int32{},
+	}
+
+	start := builder.stateFor(builder.closure([]int{automaton.start}))
+	if builder.accepts[start] >= 0 {
+		return nil, 0, fmt.Errorf("rules: rule %q matches the empty string", rules[builder.accepts[start]].Name)
+	}
+
+	for state := 0; state < len(builder.sets); state++ {
+		if len(builder.sets) > maxDFAStates {
+			return nil, 0, errors.New("rules: rule set is too complex to compile")
+		}
+
+		builder.ranges[state] = builder.transitions(builder.sets[state])
+	}
+
+	return builder, start, nil
+}
+
+func (builder *dfaBuilder) closure(states []int) []int {
+	seen := map[int]bool{}
+	stack := append([]int{}, states...)
+	result := []int{}
+
+	for len(stack) > 0 {
+		state := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if seen[state] {
+			continue
+		}
+
+		seen[state] = true
+		result = append(result, state)
+		stack = append(stack, builder.automaton.states[state].epsilon...)
+	}
+
+	sort.Ints(result)
+	return result
+}
+
+func (builder *dfaBuilder) stateFor(set []int) int32 {
+	keyBuilder := strings.Builder{}
+	for _, state := range set {
+		fmt.Fprintf(&keyBuilder, "%d,", state)
+	}
+
+	key := keyBuilder.String()
+	if id, ok := builder.ids[key]; ok {
+		return id
+	}
+
+	accept := int32(-1)
+	for _, state := range set {
+		if rule := builder.automaton.states[state].accept; rule >= 0 && (accept < 0 || int32(rule) < accept) {
+			accept = int32(rule)
+		}
+	}
+
+	id := int32(len(builder.sets))
+	builder.ids[key] = id
+	builder.sets = append(builder.sets, set)
+	builder.accepts = append(builder.accepts, accept)
+	builder.ranges = append(builder.ranges, nil)
+
+	return id
+}
+
+/*
+transitions computes the outgoing transitions of a DFA state. The ranges
+of all member NFA states are split at every boundary into disjoint
+intervals, each interval leads to the closure of the NFA states reachable
+on it, and neighbouring intervals with the same target are merged.
+*/
+func (builder *dfaBuilder) transitions(set []int) []dfaTransition {
+	boundaries := []rune{}
+
+	for _, state := range set {
+		for _, r := range builder.automaton.states[state].ranges {
+			boundaries = append(boundaries, r.lo, r.hi+1)
+		}
+	}
+
+	if len(boundaries) == 0 {
+		return nil
+	}
+
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i] < boundaries[j] })
+	unique := boundaries[:1]
+	for _, boundary := range boundaries[1:] {
+		if boundary != unique[len(unique)-1] {
+			unique = append(unique, boundary)
+		}
+	}
+
+	targets := make([][]int, len(unique)-1)
+	for _, state := range set {
+		nfaState := builder.automaton.states[state]
+
+		for _, r := range nfaState.ranges {
+			first := sort.Search(len(unique), func(i int) bool { return unique[i] >= r.lo })
+			for interval := first; interval < len(targets) && unique[interval] <= r.hi; interval++ {
+				targets[interval] = append(targets[interval], nfaState.next)
+			}
+		}
+	}
+
+	result := []dfaTransition{}
+	for interval, target := range targets {
+		if len(target) == 0 {
+			continue
+		}
+
+		next := builder.stateFor(builder.closure(target))
+		lo, hi := unique[interval], unique[interval+1]-1
+
+		if hi > unicode.MaxRune {
+			hi = unicode.MaxRune
+		}
+
+		if count := len(result); count > 0 && result[count-1].next == next && result[count-1].hi+1 == lo {
+			result[count-1].hi = hi
+			continue
+		}
+
+		result = append(result, dfaTransition{lo: lo, hi: hi, next: next})
+	}
+
+	return result
+}
+
+/*
+minimize merges the states of a DFA that no input can tell apart, using
+Moore's partition refinement. States start out grouped by the rule they
+accept, and groups are split until every state in a group moves to the
+same groups on the same runes. The subset construction leaves many such
+duplicates behind for rule sets with shared prefixes and suffixes, such
+as keyword lists, and merging them keeps the transition table small
+enough to stay in cache.
+*/
+func minimize(start int32, accepts []int32, ranges [][]dfaTransition) (int32, []int32, [][]dfaTransition) {
+	blocks := make([]int32, len(accepts))
+
+	initial := map[int32]int32{}
+	for state, accept := range accepts {
+		block, ok := initial[accept]
+		if !ok {
+			block = int32(len(initial))
+			initial[accept] = block
+		}
+
+		blocks[state] = block
+	}
+
+	count := len(initial)
+	key := strings.Builder{}
+
+	for {
+		refined := make([]int32, len(accepts))
+		signatures := map[string]int32{}
+
+		for state := range accepts {
+			key.Reset()
+			key.WriteString(strconv.Itoa(int(blocks[state])))
+
+			for _, transition := range mapTransitions(ranges[state], blocks) {
+				fmt.Fprintf(&key, ",%d-%d>%d", transition.lo, transition.hi, transition.next)
+			}
+
+			block, ok := signatures[key.String()]
+			if !ok {
+				block = int32(len(signatures))
+				signatures[key.String()] = block
+			}
+
+			refined[state] = block
+		}
+
+		blocks = refined
+
+		if len(signatures) == count {
+			break
+		}
+
+		count = len(signatures)
+	}
+
+	minimalAccepts := make([]int32, count)
+	minimalRanges := make([][]dfaTransition, count)
+	done := make([]bool, count)
+
+	for state, block := range blocks {
+		if done[block] {
+			continue
+		}
+
+		done[block] = true
+		minimalAccepts[block] = accepts[state]
+		minimalRanges[block] = mapTransitions(ranges[state], blocks)
+	}
+
+	return blocks[start], minimalAccepts, minimalRanges
+}
+
+/*
+mapTransitions renames the targets of transitions to the groups in
+blocks, merging neighbouring ranges that now lead to the same group
+*/
+func mapTransitions(transitions []dfaTransition, blocks []int32) []dfaTransition {
+	result := make([]dfaTransition, 0, len(transitions))
+
+	for _, transition := range transitions {
+		transition.next = blocks[transition.next]
+
+		if count := len(result); count > 0 && result[count-1].next == transition.next && result[count-1].hi+1 == transition.lo {
+			result[count-1].hi = transition.hi
+			continue
+		}
+
+		result = append(result, transition)
+	}
+
+	return result
+}
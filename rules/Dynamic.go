@@ -0,0 +1,180 @@
+package rules
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Dynamic is a compiled rule set whose rules can be changed while lexing,
+for languages where user code defines new operators or a directive
+changes the lexical syntax part way through a file. Each change
+recompiles the rule set and takes effect at the next token, so a rule's
+action can change the rules used for the rest of the input.
+
+Changes apply to every lexer using the Dynamic, so a lexer whose input
+changes its own syntax needs a Dynamic of its own, which NewDynamic makes
+cheaply from a shared rule set.
+*/
+type Dynamic struct {
+	lock      sync.Mutex
+	ruleSet   *RuleSet
+	machine   atomic.Value
+	functions sync.Map
+}
+
+/*
+NewDynamic compiles a copy of the rule set for changing at run time. The
+rule set itself is left as it is.
+*/
+func NewDynamic(ruleSet *RuleSet) (*Dynamic, error) {
+	ruleSet = ruleSet.Clone()
+
+	machine, err := ruleSet.Compile()
+	if err != nil {
+		return nil, err
+	}
+
+	dynamic := &Dynamic{ruleSet: ruleSet}
+	dynamic.machine.Store(machine)
+
+	return dynamic, nil
+}
+
+/*
+Machine returns the machine compiled from the current rules
+*/
+func (dynamic *Dynamic) Machine() *Machine {
+	return dynamic.machine.Load().(*Machine)
+}
+
+/*
+Update changes the rules. updateFn is given a copy of the current rules,
+in the INITIAL state, to change with Add, Remove, MoveBefore and the
+other RuleSet methods. If the changed rules compile they replace the
+current ones from the next token on; otherwise the error is returned and
+lexing continues with the rules as they were.
+*/
+func (dynamic *Dynamic) Update(updateFn func(ruleSet *RuleSet)) error {
+	dynamic.lock.Lock()
+	defer dynamic.lock.Unlock()
+
+	ruleSet := dynamic.ruleSet.Clone()
+	updateFn(ruleSet)
+
+	machine, err := ruleSet.Compile()
+	if err != nil {
+		return err
+	}
+
+	dynamic.ruleSet = ruleSet
+	dynamic.machine.Store(machine)
+
+	return nil
+}
+
+/*
+Add adds a rule to the given state, with the lowest priority in it
+*/
+func (dynamic *Dynamic) Add(state string, name string, pattern string, tokenType lexer.TokenType) error {
+	return dynamic.Update(func(ruleSet *RuleSet) {
+		ruleSet.State(state).Add(name, pattern, tokenType)
+	})
+}
+
+/*
+Remove removes every rule with the given name
+*/
+func (dynamic *Dynamic) Remove(name string) error {
+	return dynamic.Update(func(ruleSet *RuleSet) {
+		ruleSet.Remove(name)
+	})
+}
+
+/*
+MoveBefore gives the rules named name priority over the rule named
+before, as RuleSet.MoveBefore does
+*/
+func (dynamic *Dynamic) MoveBefore(name string, before string) error {
+	return dynamic.Update(func(ruleSet *RuleSet) {
+		ruleSet.MoveBefore(name, before)
+	})
+}
+
+/*
+LexFn returns a state function that lexes with the current rules,
+starting in the INITIAL state. It behaves as Machine.LexFn does, lexing
+a token per call, but picks up the latest rules before each token. Lexing keeps to its state
+by name across changes; if a change removes every rule of the state
+lexing is in, lexing goes back to INITIAL.
+*/
+func (dynamic *Dynamic) LexFn() lexer.LexFn {
+	return dynamic.StateFn(INITIAL)
+}
+
+/*
+StateFn returns the state function lexing in the named state with the
+current rules. Hand-written state functions return it to hand lexing
+back to the rules.
+*/
+func (dynamic *Dynamic) StateFn(state string) lexer.LexFn {
+	if function, ok := dynamic.functions.Load(state); ok {
+		return function.(lexer.LexFn)
+	}
+
+	var function lexer.LexFn = func(l *lexer.Lexer) lexer.LexFn {
+		if l.IsEOF() {
+			l.Emit(lexer.TOKEN_EOF)
+			return nil
+		}
+
+		machine := dynamic.Machine()
+
+		index := 0
+		for candidate, name := range machine.states {
+			if name == state {
+				index = candidate
+			}
+		}
+
+		handOver, next := machine.lexToken(l, index)
+
+		switch {
+		case handOver != nil:
+			return handOver
+
+		case next >= 0:
+			return dynamic.moveTo(l, machine.states[next])
+
+		case index == 0:
+			return dynamic.moveTo(l, INITIAL)
+		}
+
+		return dynamic.moveTo(l, state)
+	}
+
+	stored, _ := dynamic.functions.LoadOrStore(state, function)
+	return stored.(lexer.LexFn)
+}
+
+/*
+moveTo labels the named state as the one l moves to next and returns
+its state function
+*/
+func (dynamic *Dynamic) moveTo(l *lexer.Lexer, state string) lexer.LexFn {
+	l.LabelState("rules." + state)
+	return dynamic.StateFn(state)
+}
+
+/*
+NewLexer creates a lexer for input that lexes with the current rules,
+with its start state labeled rules.INITIAL
+*/
+func (dynamic *Dynamic) NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	l := lexer.NewLexer(name, input, dynamic.LexFn(), options...)
+	l.LabelState("rules." + INITIAL)
+
+	return l
+}
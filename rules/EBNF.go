@@ -0,0 +1,745 @@
+package rules
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+ebnfExpr is a node of a parsed EBNF expression. kind is 'n' for a
+reference to a production, 'l' for a literal, 's' for a character set,
+'|' for alternatives, ' ' for a sequence, '-' for an exception, and the
+operator for ?, * and +.
+*/
+type ebnfExpr struct {
+	kind   byte
+	text   string
+	ranges []runeRange
+	subs   []*ebnfExpr
+	line   int
+}
+
+/*
+ebnfNode is a compiled expression: a pattern in the syntax of the regexp
+package, and the characters it matches if it matches exactly one
+*/
+type ebnfNode struct {
+	pattern string
+	set     []runeRange
+}
+
+type ebnfToken struct {
+	kind byte
+	text string
+	line int
+}
+
+type ebnfParser struct {
+	name        string
+	tokens      []ebnfToken
+	pos         int
+	productions map[string]*ebnfExpr
+	order       []string
+	compiled    map[string]*ebnfNode
+	compiling   map[string]bool
+}
+
+/*
+ParseEBNF derives a Grammar from terminal definitions written in the
+EBNF notation of the W3C XML specification, so that the lexical grammar
+in a language's documentation can be the source the lexer is built
+from:
+
+	Digit   ::= [0-9]
+	Number  ::= Digit+ ('.' Digit+)?
+	Name    ::= [a-zA-Z_] [a-zA-Z0-9_]*
+	String  ::= '"' ([^"\#xA] | '\' Char)* '"'
+	Char    ::= [#x20-#x10FFFF]
+	Space   ::= (#x20 | #x9 | #xA)+
+
+C-style block comments are ignored.
+Expressions may use quoted literals, #xN characters, character classes
+such as [a-z] and [^"], references to other productions, grouping,
+alternatives with |, and the ?, * and + operators. A - B, matching A
+but not B, is supported where both sides match single characters.
+Productions may not refer to themselves, directly or not, as a lexer
+can only recognize regular languages.
+
+tokens names the productions that become rules, in order of priority.
+Without it, every production no other production refers to becomes a
+rule, in the order they are defined; the rest are fragments, inlined
+where they are used. In the example that makes Number, Name, String and
+Space rules. The rules all belong to a state named INITIAL, and the
+grammar has no package name.
+*/
+func ParseEBNF(name string, reader io.Reader, tokens ...string) (*Grammar, error) {
+	source, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	parser := &ebnfParser{
+		name:        name,
+		productions: map[string]*ebnfExpr{},
+		compiled:    map[string]*ebnfNode{},
+		compiling:   map[string]bool{},
+	}
+
+	if err := parser.tokenize(string(source)); err != nil {
+		return nil, err
+	}
+
+	if err := parser.parse(); err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		tokens = parser.unreferenced()
+	}
+
+	state := GrammarState{Name: "INITIAL"}
+
+	for _, token := range tokens {
+		production, ok := parser.productions[token]
+		if !ok {
+			return nil, fmt.Errorf("%s: no production named %s", name, token)
+		}
+
+		node, err := parser.compile(&ebnfExpr{kind: 'n', text: token, line: production.line})
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := New().Add(token, node.pattern, 1).Compile(); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", name, production.line, strings.TrimPrefix(err.Error(), "rules: "))
+		}
+
+		state.Rules = append(state.Rules, GrammarRule{Token: token, Pattern: node.pattern, Line: production.line})
+	}
+
+	grammar := &Grammar{States: []GrammarState{state}}
+	if err := grammar.check(name); err != nil {
+		return nil, err
+	}
+
+	return grammar, nil
+}
+
+/*
+tokenize splits the source into names, literals, characters, classes,
+operators and ::=, dropping comments
+*/
+func (parser *ebnfParser) tokenize(source string) error {
+	line := 1
+
+	for pos := 0; pos < len(source); {
+		ch, width := utf8.DecodeRuneInString(source[pos:])
+		start := pos
+
+		switch {
+		case ch == '\n':
+			line++
+			pos++
+
+		case unicode.IsSpace(ch):
+			pos += width
+
+		case strings.HasPrefix(source[pos:], "/*"):
+			end := strings.Index(source[pos+2:], "*/")
+			if end < 0 {
+				return parser.errorf(line, "unterminated comment")
+			}
+
+			pos += end + 4
+			line += strings.Count(source[start:pos], "\n")
+
+		case strings.HasPrefix(source[pos:], "::="):
+			parser.tokens = append(parser.tokens, ebnfToken{kind: '=', text: "::=", line: line})
+			pos += 3
+
+		case ch == '\'' || ch == '"':
+			end := strings.IndexRune(source[pos+1:], ch)
+			if end < 0 || strings.Contains(source[pos+1:pos+1+end], "\n") {
+				return parser.errorf(line, "unterminated literal")
+			}
+
+			parser.tokens = append(parser.tokens, ebnfToken{kind: 'l', text: source[pos+1 : pos+1+end], line: line})
+			pos += end + 2
+
+		case ch == '#':
+			end := pos + 2
+			for end < len(source) && isHexDigit(source[end]) {
+				end++
+			}
+
+			if !strings.HasPrefix(source[pos:], "#x") || end == pos+2 {
+				return parser.errorf(line, "expected a character such as #x41")
+			}
+
+			parser.tokens = append(parser.tokens, ebnfToken{kind: '#', text: source[pos:end], line: line})
+			pos = end
+
+		case ch == '[':
+			end := pos + 1
+			for end < len(source) && source[end] != ']' && source[end] != '\n' {
+				end++
+			}
+
+			if end == len(source) || source[end] != ']' {
+				return parser.errorf(line, "unterminated character class")
+			}
+
+			parser.tokens = append(parser.tokens, ebnfToken{kind: '[', text: source[pos+1 : end], line: line})
+			pos = end + 1
+
+		case strings.ContainsRune("()|-?*+", ch):
+			parser.tokens = append(parser.tokens, ebnfToken{kind: byte(ch), text: string(ch), line: line})
+			pos++
+
+		case ch == '_' || unicode.IsLetter(ch):
+			end := pos
+			for end < len(source) {
+				next, size := utf8.DecodeRuneInString(source[end:])
+				if next != '_' && !unicode.IsLetter(next) && !unicode.IsDigit(next) {
+					break
+				}
+
+				end += size
+			}
+
+			parser.tokens = append(parser.tokens, ebnfToken{kind: 'n', text: source[pos:end], line: line})
+			pos = end
+
+		default:
+			return parser.errorf(line, "unexpected %q", ch)
+		}
+	}
+
+	return nil
+}
+
+/*
+parse reads the productions. A production runs from its name and ::=
+to the next name followed by ::=.
+*/
+func (parser *ebnfParser) parse() error {
+	for parser.pos < len(parser.tokens) {
+		name := parser.tokens[parser.pos]
+
+		if name.kind != 'n' || parser.pos+1 >= len(parser.tokens) || parser.tokens[parser.pos+1].kind != '=' {
+			return parser.errorf(name.line, "expected a production name and ::=")
+		}
+
+		if _, ok := parser.productions[name.text]; ok {
+			return parser.errorf(name.line, "%s is defined twice", name.text)
+		}
+
+		parser.pos += 2
+
+		expr, err := parser.alternatives()
+		if err != nil {
+			return err
+		}
+
+		if parser.pos < len(parser.tokens) && !parser.atProduction() {
+			token := parser.tokens[parser.pos]
+			return parser.errorf(token.line, "unexpected %q", token.text)
+		}
+
+		expr.line = name.line
+		parser.productions[name.text] = expr
+		parser.order = append(parser.order, name.text)
+	}
+
+	return nil
+}
+
+func (parser *ebnfParser) atProduction() bool {
+	return parser.pos+1 < len(parser.tokens) && parser.tokens[parser.pos].kind == 'n' && parser.tokens[parser.pos+1].kind == '='
+}
+
+/*
+atEnd returns true at the end of an expression: the end of the input,
+the start of the next production, or a closing parenthesis
+*/
+func (parser *ebnfParser) atEnd() bool {
+	return parser.pos >= len(parser.tokens) || parser.atProduction() || parser.tokens[parser.pos].kind == ')' || parser.tokens[parser.pos].kind == '|'
+}
+
+func (parser *ebnfParser) alternatives() (*ebnfExpr, error) {
+	first, err := parser.sequence()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ebnfExpr{kind: '|', subs: []*ebnfExpr{first}, line: first.line}
+
+	for parser.pos < len(parser.tokens) && parser.tokens[parser.pos].kind == '|' {
+		parser.pos++
+
+		next, err := parser.sequence()
+		if err != nil {
+			return nil, err
+		}
+
+		result.subs = append(result.subs, next)
+	}
+
+	if len(result.subs) == 1 {
+		return first, nil
+	}
+
+	return result, nil
+}
+
+func (parser *ebnfParser) sequence() (*ebnfExpr, error) {
+	result := &ebnfExpr{kind: ' '}
+
+	for !parser.atEnd() {
+		item, err := parser.exception()
+		if err != nil {
+			return nil, err
+		}
+
+		result.subs = append(result.subs, item)
+	}
+
+	if len(result.subs) == 0 {
+		line := 0
+		if parser.pos < len(parser.tokens) {
+			line = parser.tokens[parser.pos].line
+		} else if len(parser.tokens) > 0 {
+			line = parser.tokens[len(parser.tokens)-1].line
+		}
+
+		return nil, parser.errorf(line, "expected an expression")
+	}
+
+	result.line = result.subs[0].line
+
+	if len(result.subs) == 1 {
+		return result.subs[0], nil
+	}
+
+	return result, nil
+}
+
+func (parser *ebnfParser) exception() (*ebnfExpr, error) {
+	left, err := parser.repetition()
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.pos >= len(parser.tokens) || parser.tokens[parser.pos].kind != '-' {
+		return left, nil
+	}
+
+	parser.pos++
+
+	if parser.atEnd() {
+		return nil, parser.errorf(left.line, "expected an expression after -")
+	}
+
+	right, err := parser.repetition()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ebnfExpr{kind: '-', subs: []*ebnfExpr{left, right}, line: left.line}, nil
+}
+
+func (parser *ebnfParser) repetition() (*ebnfExpr, error) {
+	result, err := parser.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for parser.pos < len(parser.tokens) && strings.ContainsRune("?*+", rune(parser.tokens[parser.pos].kind)) {
+		result = &ebnfExpr{kind: parser.tokens[parser.pos].kind, subs: []*ebnfExpr{result}, line: result.line}
+		parser.pos++
+	}
+
+	return result, nil
+}
+
+func (parser *ebnfParser) primary() (*ebnfExpr, error) {
+	token := parser.tokens[parser.pos]
+	parser.pos++
+
+	switch token.kind {
+	case 'n':
+		return &ebnfExpr{kind: 'n', text: token.text, line: token.line}, nil
+
+	case 'l':
+		if token.text == "" {
+			return nil, parser.errorf(token.line, "empty literal")
+		}
+
+		return &ebnfExpr{kind: 'l', text: token.text, line: token.line}, nil
+
+	case '#':
+		ch, err := parseHexChar(token.text)
+		if err != nil {
+			return nil, parser.errorf(token.line, "%s", err)
+		}
+
+		return &ebnfExpr{kind: 's', ranges: []runeRange{{ch, ch}}, line: token.line}, nil
+
+	case '[':
+		ranges, err := parseEBNFClass(token.text)
+		if err != nil {
+			return nil, parser.errorf(token.line, "%s", err)
+		}
+
+		return &ebnfExpr{kind: 's', ranges: ranges, line: token.line}, nil
+
+	case '(':
+		if parser.atEnd() {
+			return nil, parser.errorf(token.line, "empty group")
+		}
+
+		result, err := parser.alternatives()
+		if err != nil {
+			return nil, err
+		}
+
+		if parser.pos >= len(parser.tokens) || parser.tokens[parser.pos].kind != ')' {
+			return nil, parser.errorf(token.line, "unclosed (")
+		}
+
+		parser.pos++
+		return result, nil
+	}
+
+	return nil, parser.errorf(token.line, "unexpected %q", token.text)
+}
+
+/*
+unreferenced returns the productions no other production refers to, in
+the order they were defined
+*/
+func (parser *ebnfParser) unreferenced() []string {
+	referenced := map[string]bool{}
+
+	var walk func(name string, expr *ebnfExpr)
+	walk = func(name string, expr *ebnfExpr) {
+		if expr.kind == 'n' && expr.text != name {
+			referenced[expr.text] = true
+		}
+
+		for _, sub := range expr.subs {
+			walk(name, sub)
+		}
+	}
+
+	for name, production := range parser.productions {
+		walk(name, production)
+	}
+
+	result := []string{}
+	for _, name := range parser.order {
+		if !referenced[name] {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+/*
+compile translates an expression into a pattern
+*/
+func (parser *ebnfParser) compile(expr *ebnfExpr) (*ebnfNode, error) {
+	switch expr.kind {
+	case 'n':
+		if node, ok := parser.compiled[expr.text]; ok {
+			return node, nil
+		}
+
+		production, ok := parser.productions[expr.text]
+		if !ok {
+			return nil, parser.errorf(expr.line, "undefined production %s", expr.text)
+		}
+
+		if parser.compiling[expr.text] {
+			return nil, parser.errorf(expr.line, "%s refers to itself, which a lexer can not match", expr.text)
+		}
+
+		parser.compiling[expr.text] = true
+		node, err := parser.compile(production)
+		parser.compiling[expr.text] = false
+
+		if err != nil {
+			return nil, err
+		}
+
+		parser.compiled[expr.text] = node
+		return node, nil
+
+	case 'l':
+		if utf8.RuneCountInString(expr.text) == 1 {
+			ch, _ := utf8.DecodeRuneInString(expr.text)
+			return setNode([]runeRange{{ch, ch}}), nil
+		}
+
+		return &ebnfNode{pattern: regexp.QuoteMeta(expr.text)}, nil
+
+	case 's':
+		return setNode(expr.ranges), nil
+
+	case '|':
+		nodes, err := parser.compileAll(expr.subs)
+		if err != nil {
+			return nil, err
+		}
+
+		union := []runeRange{}
+		patterns := make([]string, len(nodes))
+
+		for index, node := range nodes {
+			patterns[index] = node.pattern
+
+			if union != nil && node.set != nil {
+				union = append(union, node.set...)
+			} else {
+				union = nil
+			}
+		}
+
+		if union != nil {
+			return setNode(normalizeRanges(union)), nil
+		}
+
+		return &ebnfNode{pattern: "(?:" + strings.Join(patterns, "|") + ")"}, nil
+
+	case ' ':
+		nodes, err := parser.compileAll(expr.subs)
+		if err != nil {
+			return nil, err
+		}
+
+		pattern := strings.Builder{}
+		for _, node := range nodes {
+			pattern.WriteString(node.pattern)
+		}
+
+		return &ebnfNode{pattern: pattern.String()}, nil
+
+	case '-':
+		nodes, err := parser.compileAll(expr.subs)
+		if err != nil {
+			return nil, err
+		}
+
+		if nodes[0].set == nil || nodes[1].set == nil {
+			return nil, parser.errorf(expr.line, "- is only supported between expressions matching a single character")
+		}
+
+		return setNode(subtractRanges(nodes[0].set, nodes[1].set)), nil
+	}
+
+	node, err := parser.compile(expr.subs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := node.pattern
+	if node.set == nil {
+		pattern = "(?:" + pattern + ")"
+	}
+
+	return &ebnfNode{pattern: pattern + string(expr.kind)}, nil
+}
+
+func (parser *ebnfParser) compileAll(exprs []*ebnfExpr) ([]*ebnfNode, error) {
+	nodes := make([]*ebnfNode, len(exprs))
+
+	for index, expr := range exprs {
+		node, err := parser.compile(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes[index] = node
+	}
+
+	return nodes, nil
+}
+
+func (parser *ebnfParser) errorf(line int, format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d: %s", parser.name, line, fmt.Sprintf(format, args...))
+}
+
+/*
+setNode makes the pattern for a set of characters
+*/
+func setNode(ranges []runeRange) *ebnfNode {
+	if len(ranges) == 1 && ranges[0].lo == ranges[0].hi {
+		return &ebnfNode{pattern: regexp.QuoteMeta(string(ranges[0].lo)), set: ranges}
+	}
+
+	pattern := strings.Builder{}
+	pattern.WriteByte('[')
+
+	for _, r := range ranges {
+		pattern.WriteString(classChar(r.lo))
+
+		if r.hi != r.lo {
+			pattern.WriteByte('-')
+			pattern.WriteString(classChar(r.hi))
+		}
+	}
+
+	pattern.WriteByte(']')
+
+	if len(ranges) == 0 {
+		return &ebnfNode{pattern: `[^\x00-\x{10FFFF}]`, set: ranges}
+	}
+
+	return &ebnfNode{pattern: pattern.String(), set: ranges}
+}
+
+func classChar(ch rune) string {
+	if ch < utf8.RuneSelf && (ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch)) {
+		return string(ch)
+	}
+
+	return `\x{` + strconv.FormatInt(int64(ch), 16) + `}`
+}
+
+/*
+parseEBNFClass parses the inside of a character class such as a-z_ or
+^#x0-#x1F, giving its ranges in order
+*/
+func parseEBNFClass(text string) ([]runeRange, error) {
+	negate := strings.HasPrefix(text, "^")
+	if negate {
+		text = text[1:]
+	}
+
+	var ranges []runeRange
+
+	next := func() (rune, error) {
+		if strings.HasPrefix(text, "#x") {
+			end := 2
+			for end < len(text) && isHexDigit(text[end]) {
+				end++
+			}
+
+			ch, err := parseHexChar(text[:end])
+			text = text[end:]
+			return ch, err
+		}
+
+		ch, width := utf8.DecodeRuneInString(text)
+		text = text[width:]
+		return ch, nil
+	}
+
+	for text != "" {
+		lo, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		hi := lo
+
+		if len(text) > 1 && text[0] == '-' {
+			text = text[1:]
+
+			if hi, err = next(); err != nil {
+				return nil, err
+			}
+
+			if hi < lo {
+				return nil, fmt.Errorf("invalid character range %q-%q", lo, hi)
+			}
+		}
+
+		ranges = append(ranges, runeRange{lo, hi})
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("empty character class")
+	}
+
+	ranges = normalizeRanges(ranges)
+
+	if negate {
+		ranges = subtractRanges([]runeRange{{0, unicode.MaxRune}}, ranges)
+	}
+
+	return ranges, nil
+}
+
+func parseHexChar(text string) (rune, error) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(text, "#x"), 16, 32)
+	if err != nil || value > unicode.MaxRune {
+		return 0, fmt.Errorf("invalid character %s", text)
+	}
+
+	return rune(value), nil
+}
+
+/*
+normalizeRanges sorts ranges and merges those that touch or overlap
+*/
+func normalizeRanges(ranges []runeRange) []runeRange {
+	sorted := append([]runeRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].lo < sorted[j].lo })
+
+	result := []runeRange{}
+	for _, r := range sorted {
+		if count := len(result); count > 0 && r.lo <= result[count-1].hi+1 {
+			if r.hi > result[count-1].hi {
+				result[count-1].hi = r.hi
+			}
+
+			continue
+		}
+
+		result = append(result, r)
+	}
+
+	return result
+}
+
+/*
+subtractRanges returns the characters in ranges that are not in remove.
+Both must be normalized.
+*/
+func subtractRanges(ranges []runeRange, remove []runeRange) []runeRange {
+	result := []runeRange{}
+
+	for _, r := range ranges {
+		lo := r.lo
+
+		for _, cut := range remove {
+			if cut.hi < lo || cut.lo > r.hi {
+				continue
+			}
+
+			if cut.lo > lo {
+				result = append(result, runeRange{lo, cut.lo - 1})
+			}
+
+			lo = cut.hi + 1
+		}
+
+		if lo <= r.hi {
+			result = append(result, runeRange{lo, r.hi})
+		}
+	}
+
+	return result
+}
+
+func isHexDigit(ch byte) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
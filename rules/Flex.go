@@ -0,0 +1,486 @@
+package rules
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	flexReturn = regexp.MustCompile(`\breturn\b(?:\s*\(?\s*([A-Za-z_][A-Za-z0-9_]*)\s*\)?\s*;)?`)
+	flexBegin  = regexp.MustCompile(`\bBEGIN\b\s*\(?\s*([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+/*
+flexImport holds what has been read of a flex file so far
+*/
+type flexImport struct {
+	name        string
+	line        int
+	definitions map[string]string
+	inclusive   []string
+	states      []string
+	rules       map[string][]GrammarRule
+	caseless    bool
+}
+
+/*
+ImportFlex converts a flex scanner definition to a Grammar, from which
+WriteGo generates Go code and LexFn builds a lexer at run time. name is
+used in error messages.
+
+The subset of flex understood covers what most scanners use. In the
+definitions section, name definitions are expanded where {name} is
+used, %s and %x declare inclusive and exclusive start conditions, and
+%option case-insensitive makes every pattern ignore case; code blocks,
+indented lines and other options are skipped. In the rules section,
+patterns may be prefixed with start conditions such as <STRING> or
+<*>, and quoted strings in patterns are matched literally. The user
+code section is ignored.
+
+Actions are C and are not translated. Instead a rule produces the token
+named by the first return in its action, and moves to the start
+condition named by its BEGIN. A rule that returns anything but a name,
+such as a character, produces CHAR tokens, and a rule that returns
+nothing, such as one skipping whitespace, becomes a skip rule named
+IGNORE. A | action shares the action of the next rule.
+Trailing context, as in r/s, becomes the rule's lookahead. <<EOF>> rules
+are dropped, and anchors and REJECT are not supported.
+*/
+func ImportFlex(name string, reader io.Reader) (*Grammar, error) {
+	flex := &flexImport{
+		name:        name,
+		definitions: map[string]string{},
+		states:      []string{"INITIAL"},
+		rules:       map[string][]GrammarRule{},
+	}
+
+	scanner := bufio.NewScanner(reader)
+	section := 1
+	codeEnd := ""
+
+	type pending struct {
+		conditions []string
+		pattern    string
+		line       int
+	}
+
+	var shared []pending
+
+	for scanner.Scan() {
+		flex.line++
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "%%"):
+			section++
+			continue
+
+		case codeEnd != "":
+			if strings.HasPrefix(line, codeEnd) {
+				codeEnd = ""
+			}
+
+			continue
+
+		case section > 2:
+			continue
+
+		case strings.HasPrefix(line, "%{"):
+			codeEnd = "%}"
+			continue
+
+		case strings.HasPrefix(line, "%top{"):
+			codeEnd = "}"
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+
+		if section == 1 {
+			if err := flex.definition(line); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(line, "/*") {
+			continue
+		}
+
+		conditions, rest, err := flex.conditions(line)
+		if err != nil {
+			return nil, err
+		}
+
+		pattern, action := splitFlexRule(rest)
+
+		if strings.TrimSpace(action) == "|" {
+			shared = append(shared, pending{conditions: conditions, pattern: pattern, line: flex.line})
+			continue
+		}
+
+		action, err = flex.readAction(action, scanner)
+		if err != nil {
+			return nil, err
+		}
+
+		rules := append(shared, pending{conditions: conditions, pattern: pattern, line: flex.line})
+		shared = nil
+
+		for _, rule := range rules {
+			if rule.pattern == "<<EOF>>" {
+				continue
+			}
+
+			if err := flex.addRule(rule.conditions, rule.pattern, action, rule.line); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	// States are kept in the order they were declared, so INITIAL comes
+	// first and lexing starts there
+	grammar := &Grammar{IgnoreCase: flex.caseless}
+	for _, state := range flex.states {
+		if rules := flex.rules[state]; len(rules) > 0 {
+			grammar.States = append(grammar.States, GrammarState{Name: state, Rules: rules})
+		}
+	}
+
+	if err := grammar.check(name); err != nil {
+		return nil, err
+	}
+
+	return grammar, nil
+}
+
+/*
+definition reads a line of the definitions section
+*/
+func (flex *flexImport) definition(line string) error {
+	if strings.HasPrefix(line, "/*") {
+		return nil
+	}
+
+	keyword, rest := splitWord(line)
+
+	switch keyword {
+	case "%s", "%S", "%x", "%X":
+		for _, state := range strings.Fields(rest) {
+			flex.states = append(flex.states, state)
+
+			if keyword == "%s" || keyword == "%S" {
+				flex.inclusive = append(flex.inclusive, state)
+			}
+		}
+
+		return nil
+
+	case "%option":
+		for _, option := range strings.Fields(rest) {
+			if option == "case-insensitive" || option == "caseless" {
+				flex.caseless = true
+			}
+		}
+
+		return nil
+	}
+
+	if strings.HasPrefix(keyword, "%") {
+		return nil
+	}
+
+	pattern, err := flex.translate(rest)
+	if err != nil {
+		return err
+	}
+
+	flex.definitions[keyword] = pattern
+	return nil
+}
+
+/*
+conditions splits the start condition prefix off a rule
+*/
+func (flex *flexImport) conditions(line string) ([]string, string, error) {
+	if !strings.HasPrefix(line, "<") || strings.HasPrefix(line, "<<EOF>>") {
+		return nil, line, nil
+	}
+
+	end := strings.IndexByte(line, '>')
+	if end < 0 {
+		return nil, "", flex.errorf("unterminated start condition")
+	}
+
+	names := strings.Split(line[1:end], ",")
+	if len(names) == 1 && names[0] == "*" {
+		return flex.states, line[end+1:], nil
+	}
+
+	for _, name := range names {
+		if !flex.declared(name) {
+			return nil, "", flex.errorf("undeclared start condition %s", name)
+		}
+	}
+
+	return names, line[end+1:], nil
+}
+
+/*
+readAction reads the rest of an action, which continues over following
+lines while its braces are open
+*/
+func (flex *flexImport) readAction(action string, scanner *bufio.Scanner) (string, error) {
+	depth := strings.Count(action, "{") - strings.Count(action, "}")
+
+	for depth > 0 {
+		if !scanner.Scan() {
+			return "", flex.errorf("unterminated action")
+		}
+
+		flex.line++
+		line := scanner.Text()
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		action += "\n" + line
+	}
+
+	return action, nil
+}
+
+/*
+addRule adds a rule to the states it applies to. A rule without start
+conditions applies to INITIAL and to every inclusive state.
+*/
+func (flex *flexImport) addRule(conditions []string, pattern string, action string, line int) error {
+	pattern, context := splitFlexContext(pattern)
+
+	translated, err := flex.translate(pattern)
+	if err != nil {
+		return err
+	}
+
+	rule := GrammarRule{Token: "IGNORE", Pattern: translated, Skip: true, Line: line}
+
+	if context != "" {
+		if rule.Lookahead, err = flex.translate(context); err != nil {
+			return err
+		}
+	}
+
+	if match := flexReturn.FindStringSubmatch(action); match != nil {
+		rule.Token = "CHAR"
+		rule.Skip = false
+		if match[1] != "" {
+			rule.Token = match[1]
+		}
+	}
+
+	if match := flexBegin.FindStringSubmatch(action); match != nil {
+		if !flex.declared(match[1]) {
+			return flex.errorf("undeclared start condition %s", match[1])
+		}
+
+		rule.Next = match[1]
+	}
+
+	check := New().Add(rule.Token, rule.Pattern, 1)
+	if rule.Lookahead != "" {
+		check.FollowedBy(rule.Lookahead)
+	}
+
+	if _, err := check.Compile(); err != nil {
+		return fmt.Errorf("%s:%d: %s", flex.name, line, strings.TrimPrefix(err.Error(), "rules: "))
+	}
+
+	if len(conditions) == 0 {
+		conditions = append([]string{"INITIAL"}, flex.inclusive...)
+	}
+
+	for _, condition := range conditions {
+		flex.rules[condition] = append(flex.rules[condition], rule)
+	}
+
+	return nil
+}
+
+/*
+translate rewrites a flex pattern in the syntax of the regexp package,
+expanding name definitions and quoting quoted strings
+*/
+func (flex *flexImport) translate(pattern string) (string, error) {
+	result := strings.Builder{}
+
+	for index := 0; index < len(pattern); index++ {
+		switch ch := pattern[index]; ch {
+		case '\\':
+			result.WriteByte(ch)
+
+			if index+1 < len(pattern) {
+				index++
+				result.WriteByte(pattern[index])
+			}
+
+		case '"':
+			literal := strings.Builder{}
+
+			for index++; index < len(pattern) && pattern[index] != '"'; index++ {
+				if pattern[index] == '\\' && index+1 < len(pattern) {
+					index++
+				}
+
+				literal.WriteByte(pattern[index])
+			}
+
+			result.WriteString(regexp.QuoteMeta(literal.String()))
+
+		case '[':
+			end := flexClassEnd(pattern, index)
+			result.WriteString(pattern[index:end])
+			index = end - 1
+
+		case '{':
+			end := strings.IndexByte(pattern[index:], '}')
+			if end < 0 || !unicode.IsLetter(rune(pattern[index+1])) && pattern[index+1] != '_' {
+				result.WriteByte(ch)
+				continue
+			}
+
+			name := pattern[index+1 : index+end]
+			definition, ok := flex.definitions[name]
+			if !ok {
+				return "", flex.errorf("undefined name {%s}", name)
+			}
+
+			result.WriteString("(?:" + definition + ")")
+			index += end
+
+		case '/':
+			return "", flex.errorf("trailing context may only be given once")
+
+		default:
+			result.WriteByte(ch)
+		}
+	}
+
+	return result.String(), nil
+}
+
+func (flex *flexImport) declared(state string) bool {
+	for _, declared := range flex.states {
+		if declared == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (flex *flexImport) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d: %s", flex.name, flex.line, fmt.Sprintf(format, args...))
+}
+
+/*
+splitFlexRule splits a rule into its pattern and action. The pattern
+ends at the first whitespace outside quotes and brackets.
+*/
+func splitFlexRule(line string) (string, string) {
+	for index := 0; index < len(line); index++ {
+		switch line[index] {
+		case '\\':
+			index++
+
+		case '"':
+			for index++; index < len(line) && line[index] != '"'; index++ {
+				if line[index] == '\\' {
+					index++
+				}
+			}
+
+		case '[':
+			index = flexClassEnd(line, index) - 1
+
+		case ' ', '\t':
+			return line[:index], strings.TrimSpace(line[index:])
+		}
+	}
+
+	return line, ""
+}
+
+/*
+splitFlexContext splits a pattern into the pattern proper and the
+trailing context after its /, if it has one
+*/
+func splitFlexContext(pattern string) (string, string) {
+	for index := 0; index < len(pattern); index++ {
+		switch pattern[index] {
+		case '\\':
+			index++
+
+		case '"':
+			for index++; index < len(pattern) && pattern[index] != '"'; index++ {
+				if pattern[index] == '\\' {
+					index++
+				}
+			}
+
+		case '[':
+			index = flexClassEnd(pattern, index) - 1
+
+		case '/':
+			return pattern[:index], pattern[index+1:]
+		}
+	}
+
+	return pattern, ""
+}
+
+/*
+flexClassEnd returns the index just past the character class starting
+at start. A ] first in the class, after any ^, is a member, as are the
+brackets of [:alpha:] style classes.
+*/
+func flexClassEnd(pattern string, start int) int {
+	index := start + 1
+
+	if index < len(pattern) && pattern[index] == '^' {
+		index++
+	}
+
+	if index < len(pattern) && pattern[index] == ']' {
+		index++
+	}
+
+	for index < len(pattern) {
+		switch {
+		case pattern[index] == '\\':
+			index += 2
+
+		case strings.HasPrefix(pattern[index:], "[:"):
+			if end := strings.Index(pattern[index:], ":]"); end >= 0 {
+				index += end + 2
+			} else {
+				index++
+			}
+
+		case pattern[index] == ']':
+			return index + 1
+
+		default:
+			index++
+		}
+	}
+
+	return len(pattern)
+}
@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+/*
+Fragment defines a named piece of pattern for the patterns of rules
+added after it, which use it as {name}, so that long patterns can be
+built from parts with names of their own, as lex definitions are:
+
+	ruleSet.
+		Fragment("DIGIT", `[0-9]`).
+		Fragment("EXP", `[eE][+-]?{DIGIT}+`).
+		Add("float", `{DIGIT}+\.{DIGIT}*{EXP}?`, TOKEN_FLOAT)
+
+A fragment may use classes and fragments defined before it. It is
+grouped where it is used, so a quantifier after the reference applies
+to all of it. Braces that name no fragment, such as the {2,3} of a
+repetition, are left alone, but a reference to an undefined fragment
+is an error.
+*/
+func (ruleSet *RuleSet) Fragment(name string, pattern string) *RuleSet {
+	if ruleSet.err != nil {
+		return ruleSet
+	}
+
+	if !isIdentifier(name) {
+		ruleSet.err = fmt.Errorf("rules: invalid fragment name %q", name)
+		return ruleSet
+	}
+
+	if _, ok := ruleSet.fragments[name]; ok {
+		ruleSet.err = fmt.Errorf("rules: fragment %s defined twice", name)
+		return ruleSet
+	}
+
+	pattern = ruleSet.expand(pattern)
+	if ruleSet.err != nil {
+		return ruleSet
+	}
+
+	if _, err := syntax.Parse(pattern, syntax.Perl); err != nil {
+		ruleSet.err = fmt.Errorf("rules: fragment %s: %w", name, err)
+		return ruleSet
+	}
+
+	if ruleSet.fragments == nil {
+		ruleSet.fragments = map[string]string{}
+	}
+
+	ruleSet.fragments[name] = pattern
+	return ruleSet
+}
+
+/*
+fragmentReference reads a {name} at the start of text, returning the
+name and the length of the reference
+*/
+func fragmentReference(text string) (string, int, bool) {
+	end := strings.IndexByte(text, '}')
+	if end < 0 || !isIdentifier(text[1:end]) {
+		return "", 0, false
+	}
+
+	return text[1:end], end + 1, true
+}
@@ -0,0 +1,294 @@
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+type generatedRule struct {
+	Token     string
+	Pattern   string
+	Lookahead string
+	Negated   bool
+	Skip      bool
+	Index     int
+	Next      string
+}
+
+type generatedDefinition struct {
+	Method  string
+	Name    string
+	Pattern string
+}
+
+type generatedState struct {
+	Name     string
+	Function string
+	Machine  string
+	Rules    []generatedRule
+	Moves    []generatedRule
+	Skips    bool
+}
+
+var generatedTemplate = template.Must(template.New("lexer").Parse(`// Code generated by lexgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/rules"
+)
+{{template "tokens" .}}
+var (
+{{- range $index, $state := .States}}
+{{- if $index}}
+{{end}}
+	{{.Machine}} = rules.New().
+	{{- if $.IgnoreCase}}
+		CaseInsensitive().
+	{{- end}}
+	{{- if $.First}}
+		Strategy(rules.MATCH_FIRST).
+	{{- end}}
+	{{- range $.Definitions}}
+		{{.Method}}("{{.Name}}", {{.Pattern}}).
+	{{- end}}
+	{{- range .Rules}}
+		{{- if .Skip}}
+		Skip("{{.Token}}", {{.Pattern}}).
+		{{- else}}
+		Add("{{.Token}}", {{.Pattern}}, TOKEN_{{.Token}}).
+		{{- end}}
+		{{- if .Lookahead}}{{if .Negated}}NotFollowedBy{{else}}FollowedBy{{end}}({{.Lookahead}}).{{end}}
+	{{- end}}
+		MustCompile()
+{{- end}}
+)
+{{template "constructors" .}}
+{{- range .States}}
+/*
+{{.Function}} lexes the {{.Name}} state
+*/
+func {{.Function}}(l *lexer.Lexer) lexer.LexFn {
+	for !l.IsEOF() {
+		rule, length := {{.Machine}}.MatchLexer(l)
+
+		if rule < 0 {
+			ch := l.Next()
+			l.Errorf("unexpected character %q", ch)
+			l.Ignore()
+			continue
+		}
+
+		l.Inc(length)
+{{- if .Skips}}
+
+		if {{.Machine}}.Rules()[rule].Skip {
+			l.Ignore()
+		} else {
+			l.Emit({{.Machine}}.Rules()[rule].Type)
+		}
+{{- else}}
+		l.Emit({{.Machine}}.Rules()[rule].Type)
+{{- end}}
+{{- if .Moves}}
+
+		switch rule {
+		{{- range .Moves}}
+		case {{.Index}}:
+			return {{.Next}}
+		{{- end}}
+		}
+{{- end}}
+	}
+
+	l.Emit(lexer.TOKEN_EOF)
+	return nil
+}
+{{end}}
+{{- define "tokens"}}
+const (
+{{- range $index, $token := .Tokens}}
+	TOKEN_{{$token}}{{if eq $index 0}} lexer.TokenType = iota + 1{{end}}
+{{- end}}
+)
+
+/*
+Names holds the names of the token types
+*/
+var Names = lexer.TokenNames{
+{{- range .Tokens}}
+	TOKEN_{{.}}: "{{.}}",
+{{- end}}
+}
+{{end}}
+{{- define "constructors"}}
+func init() {
+{{- range .States}}
+	lexer.NameState("{{$.Package}}.{{.Name}}", {{.Function}})
+{{- end}}
+}
+
+/*
+NewLexer creates a lexer for input held in memory
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer for input read from reader
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
+{{end}}`))
+
+/*
+WriteGo writes Go source for a lexer of the grammar to w, in the
+grammar's package. source names the grammar file in the header that
+marks the file as generated.
+
+The generated file declares a TOKEN_ constant for each token, Names,
+NewLexer and NewReaderLexer, and a state function for each state, the
+first of them named Start. Each state's rules are compiled into a
+Machine when the package is initialized. Input no rule matches is
+reported with Errorf a character at a time, as Machine.LexFn does.
+*/
+func (grammar *Grammar) WriteGo(w io.Writer, source string) error {
+	data, err := grammar.generated(source)
+	if err != nil {
+		return err
+	}
+
+	return writeGenerated(w, generatedTemplate, data)
+}
+
+/*
+generated gathers what the templates need to write a lexer for the
+grammar
+*/
+func (grammar *Grammar) generated(source string) (map[string]interface{}, error) {
+	if grammar.Package == "" {
+		return nil, errors.New("rules: grammar has no package name")
+	}
+
+	names := map[string]string{}
+	states := make([]generatedState, len(grammar.States))
+
+	for index, state := range grammar.States {
+		name := camelCase(state.Name)
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("rules: states %s and %s have the same name in Go", other, state.Name)
+		}
+
+		names[name] = state.Name
+		states[index] = generatedState{Name: state.Name, Function: "lex" + name, Machine: "machine" + name}
+
+		if index == 0 {
+			states[index].Function = "Start"
+		}
+	}
+
+	for index, state := range grammar.States {
+		for ruleIndex, rule := range state.Rules {
+			generated := generatedRule{Token: rule.Token, Pattern: goString(rule.Pattern), Skip: rule.Skip, Index: ruleIndex}
+			states[index].Skips = states[index].Skips || rule.Skip
+
+			if rule.Lookahead != "" {
+				generated.Lookahead = goString(rule.Lookahead)
+				generated.Negated = rule.LookaheadNegated
+			}
+
+			states[index].Rules = append(states[index].Rules, generated)
+
+			if rule.Next != "" && rule.Next != state.Name {
+				for _, target := range states {
+					if target.Name == rule.Next {
+						generated.Next = target.Function
+					}
+				}
+
+				states[index].Moves = append(states[index].Moves, generated)
+			}
+		}
+	}
+
+	definitions := make([]generatedDefinition, len(grammar.Definitions))
+	for index, definition := range grammar.Definitions {
+		definitions[index] = generatedDefinition{Method: "Class", Name: definition.Name, Pattern: goString(definition.Pattern)}
+
+		if definition.Fragment {
+			definitions[index].Method = "Fragment"
+		}
+	}
+
+	return map[string]interface{}{
+		"Source":      source,
+		"Package":     grammar.Package,
+		"IgnoreCase":  grammar.IgnoreCase,
+		"First":       grammar.Strategy == MATCH_FIRST,
+		"Definitions": definitions,
+		"Tokens":      grammar.Tokens(),
+		"States":      states,
+	}, nil
+}
+
+/*
+writeGenerated executes a template and writes the result to w,
+formatted
+*/
+func writeGenerated(w io.Writer, generator *template.Template, data map[string]interface{}) error {
+	buffer := bytes.Buffer{}
+	if err := generator.Execute(&buffer, data); err != nil {
+		return err
+	}
+
+	formatted, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return fmt.Errorf("rules: generated code does not parse: %w", err)
+	}
+
+	_, err = w.Write(formatted)
+	return err
+}
+
+/*
+camelCase turns a state name such as IN_COMMENT into InComment
+*/
+func camelCase(name string) string {
+	result := strings.Builder{}
+
+	for _, word := range strings.Split(name, "_") {
+		for index, ch := range word {
+			if index == 0 {
+				result.WriteRune(unicode.ToUpper(ch))
+			} else {
+				result.WriteRune(unicode.ToLower(ch))
+			}
+		}
+	}
+
+	return result.String()
+}
+
+/*
+goString writes a pattern as a Go string literal, raw where possible so
+that the generated code reads like the grammar
+*/
+func goString(pattern string) string {
+	if strconv.CanBackquote(pattern) {
+		return "`" + pattern + "`"
+	}
+
+	return strconv.Quote(pattern)
+}
@@ -0,0 +1,357 @@
+package rules
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+type generatedBranch struct {
+	Condition string
+	Next      int32
+}
+
+type generatedDFAState struct {
+	Index    int
+	Branches []generatedBranch
+}
+
+type generatedAccept struct {
+	States string
+	Rule   int32
+}
+
+type generatedDFA struct {
+	Name     string
+	Function string
+	Match    string
+	Start    int32
+	States   []generatedDFAState
+	Accepts  []generatedAccept
+}
+
+var generatedDFATemplate = template.Must(generatedTemplate.New("dfa").Parse(`// Code generated by lexgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"io"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+{{template "tokens" .}}
+/*
+ruleTokens holds the token type of each rule
+*/
+var ruleTokens = [...]lexer.TokenType{
+{{- range .RuleTokens}}
+	{{.}},
+{{- end}}
+}
+{{template "constructors" .}}
+/*
+matchLexer finds the longest match at the lexer's position with match,
+extending the window of a lexer reading from a reader for as long as
+the DFA is still running at its end
+*/
+func matchLexer(l *lexer.Lexer, match func(string) (int, int, bool)) (int, int) {
+	for {
+		rule, length, exhausted := match(l.Input[l.Pos:])
+		available := len(l.Input) - l.Pos
+
+		if !exhausted || len(l.PeekCharacters(available*2+utf8.UTFMax)) == available {
+			return rule, length
+		}
+	}
+}
+{{- range .DFAs}}
+
+/*
+{{.Function}} lexes the {{.Name}} state
+*/
+func {{.Function}}(l *lexer.Lexer) lexer.LexFn {
+	for !l.IsEOF() {
+		rule, length := matchLexer(l, {{.Match}})
+
+		if rule < 0 {
+			ch := l.Next()
+			l.Errorf("unexpected character %q", ch)
+			l.Ignore()
+			continue
+		}
+
+		l.Inc(length)
+{{- if $.Skips}}
+
+		switch rule {
+		case {{$.Skips}}:
+			l.Ignore()
+		default:
+			l.Emit(ruleTokens[rule])
+		}
+{{- else}}
+		l.Emit(ruleTokens[rule])
+{{- end}}
+{{- if $.Moves}}
+
+		switch rule {
+		{{- range $.Moves}}
+		case {{.Index}}:
+			return {{.Next}}
+		{{- end}}
+		}
+{{- end}}
+	}
+
+	l.Emit(lexer.TOKEN_EOF)
+	return nil
+}
+
+/*
+{{.Match}} runs the DFA of the {{.Name}} state over input, returning the
+rule of the longest match and its length, or -1 and 0, and whether input
+ran out while the DFA could still have matched more
+*/
+func {{.Match}}(input string) (rule int, length int, exhausted bool) {
+	rule = -1
+	state := {{.Start}}
+
+	for pos := 0; pos < len(input); {
+		r, width := rune(input[pos]), 1
+
+		if r >= utf8.RuneSelf {
+			if !utf8.FullRuneInString(input[pos:]) {
+				return rule, length, true
+			}
+
+			r, width = utf8.DecodeRuneInString(input[pos:])
+		}
+
+		switch state {
+		{{- range .States}}
+		case {{.Index}}:
+		{{- if .Branches}}
+			switch {
+			{{- range .Branches}}
+			case {{.Condition}}:
+				state = {{.Next}}
+			{{- end}}
+			default:
+				return rule, length, false
+			}
+		{{- else}}
+			return rule, length, false
+		{{- end}}
+		{{- end}}
+		}
+
+		pos += width
+
+		switch state {
+		{{- range .Accepts}}
+		case {{.States}}:
+		{{- if and $.First (ne .Rule 0)}}
+			if rule < 0 || rule >= {{.Rule}} {
+				rule, length = {{.Rule}}, pos
+			}
+		{{- else}}
+			rule, length = {{.Rule}}, pos
+		{{- end}}
+		{{- end}}
+		}
+	}
+
+	return rule, length, true
+}
+{{- end}}
+`))
+
+/*
+WriteGoDFA writes Go source for a lexer of the grammar to w as WriteGo
+does, but with each state's DFA compiled to Go code, a switch on the
+DFA state holding a switch on the character, instead of built from the
+rules when the package is initialized. The lexer then needs neither the
+rules package nor any start up work, and runs as fast as hand-written
+code while producing tokens, positions and diagnostics through the
+lexer package as usual. Rules with lookahead can not be written this
+way.
+*/
+func (grammar *Grammar) WriteGoDFA(w io.Writer, source string) error {
+	data, err := grammar.generated(source)
+	if err != nil {
+		return err
+	}
+
+	ruleSet := grammar.RuleSet(nil)
+
+	for _, rule := range ruleSet.Rules() {
+		if rule.Lookahead != "" {
+			return fmt.Errorf("rules: rule %q has lookahead, which compiled DFAs do not support", rule.Name)
+		}
+	}
+
+	machine, err := ruleSet.Compile()
+	if err != nil {
+		return err
+	}
+
+	states := data["States"].([]generatedState)
+	dfas := make([]generatedDFA, len(states))
+	ruleTokens := []string{}
+	moves := []generatedRule{}
+	skips := []string{}
+
+	for index, state := range states {
+		for _, move := range state.Moves {
+			move.Index += len(ruleTokens)
+			moves = append(moves, move)
+		}
+
+		for _, rule := range state.Rules {
+			if rule.Skip {
+				skips = append(skips, strconv.Itoa(len(ruleTokens)))
+				ruleTokens = append(ruleTokens, "lexer.TOKEN_TRIVIA")
+			} else {
+				ruleTokens = append(ruleTokens, "TOKEN_"+rule.Token)
+			}
+		}
+
+		dfas[index] = generatedDFA{
+			Name:     state.Name,
+			Function: state.Function,
+			Match:    "match" + strings.TrimPrefix(state.Machine, "machine"),
+		}
+
+		dfas[index].fill(machine.tables[index])
+	}
+
+	data["DFAs"] = dfas
+	data["RuleTokens"] = ruleTokens
+	data["Moves"] = moves
+	data["Skips"] = strings.Join(skips, ", ")
+
+	return writeGenerated(w, generatedDFATemplate, data)
+}
+
+/*
+fill describes the states and accepting states of a compiled table for
+the template
+*/
+func (dfa *generatedDFA) fill(table *dfaTable) {
+	dfa.Start = table.start
+	accepting := map[int32][]string{}
+
+	for state := range table.accepts {
+		dfaState := generatedDFAState{Index: state}
+		targets := map[int32][]dfaTransition{}
+		order := []int32{}
+
+		for _, transition := range table.transitions(int32(state)) {
+			if _, ok := targets[transition.next]; !ok {
+				order = append(order, transition.next)
+			}
+
+			targets[transition.next] = append(targets[transition.next], transition)
+		}
+
+		for _, next := range order {
+			dfaState.Branches = append(dfaState.Branches, generatedBranch{Condition: rangeCondition(targets[next]), Next: next})
+		}
+
+		dfa.States = append(dfa.States, dfaState)
+
+		if accept := table.accepts[state]; accept >= 0 {
+			accepting[accept] = append(accepting[accept], strconv.Itoa(state))
+		}
+	}
+
+	rules := make([]int32, 0, len(accepting))
+	for rule := range accepting {
+		rules = append(rules, rule)
+	}
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i] < rules[j] })
+
+	for _, rule := range rules {
+		dfa.Accepts = append(dfa.Accepts, generatedAccept{States: strings.Join(accepting[rule], ", "), Rule: rule})
+	}
+}
+
+/*
+transitions rebuilds the sorted transitions of a DFA state from its
+ASCII row and its wide ranges
+*/
+func (table *dfaTable) transitions(state int32) []dfaTransition {
+	result := []dfaTransition{}
+	row := table.ascii[int(state)<<7 : int(state)<<7+128]
+
+	for ch := 0; ch < 128; ch++ {
+		if row[ch] < 0 {
+			continue
+		}
+
+		if count := len(result); count > 0 && result[count-1].next == row[ch] && result[count-1].hi == rune(ch-1) {
+			result[count-1].hi = rune(ch)
+			continue
+		}
+
+		result = append(result, dfaTransition{lo: rune(ch), hi: rune(ch), next: row[ch]})
+	}
+
+	for _, wide := range table.wide[state] {
+		if count := len(result); count > 0 && result[count-1].next == wide.next && result[count-1].hi+1 == wide.lo {
+			result[count-1].hi = wide.hi
+			continue
+		}
+
+		result = append(result, wide)
+	}
+
+	return result
+}
+
+/*
+rangeCondition writes a Go condition testing r against ranges
+*/
+func rangeCondition(ranges []dfaTransition) string {
+	conditions := make([]string, len(ranges))
+
+	for index, r := range ranges {
+		switch {
+		case r.lo == r.hi:
+			conditions[index] = "r == " + runeLiteral(r.lo)
+
+		case r.lo == 0 && r.hi == unicode.MaxRune:
+			conditions[index] = "true"
+
+		case r.lo == 0:
+			conditions[index] = "r <= " + runeLiteral(r.hi)
+
+		case r.hi == unicode.MaxRune:
+			conditions[index] = "r >= " + runeLiteral(r.lo)
+
+		default:
+			conditions[index] = "r >= " + runeLiteral(r.lo) + " && r <= " + runeLiteral(r.hi)
+		}
+	}
+
+	return strings.Join(conditions, " || ")
+}
+
+/*
+runeLiteral writes a rune as a Go character literal where it is
+printable, and as a number otherwise
+*/
+func runeLiteral(ch rune) string {
+	if ch < unicode.MaxRune && unicode.IsPrint(ch) && ch != utf8.RuneError {
+		return strconv.QuoteRune(ch)
+	}
+
+	return fmt.Sprintf("0x%X", ch)
+}
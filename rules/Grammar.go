@@ -0,0 +1,477 @@
+package rules
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Grammar is a lexical grammar read from a grammar file by ParseGrammar.
+It names the tokens of a lexer, the states it moves between and the
+rules of each state, so that a lexer can be reviewed as data and
+regenerated from it with WriteGo.
+
+A grammar file holds one declaration per line. Blank lines and lines
+starting with # are ignored.
+
+	# A tiny language with strings
+	package tiny
+
+	state INITIAL
+		NUMBER  `[0-9]+`
+		IDENT   `[a-zA-Z_][a-zA-Z0-9_]*`
+		SPACE   `\s+`
+		QUOTE   `"`  -> STRING
+
+	state STRING
+		TEXT    `[^"\\]+`
+		ESCAPE  `\\.`
+		QUOTE   `"`  -> INITIAL
+
+A rule is a token name followed by its pattern, written as a Go string
+literal, and optionally by -> and the state to move to after the token.
+Between the two, / and a second pattern makes the rule match only where
+the input after it matches that pattern, and !/ only where it does not:
+
+	INT     `[0-9]+`  !/ `\.[0-9]`
+
+A rule starting with skip throws its matches away, as RuleSet.Skip
+does, and its name is not a token:
+
+	skip SPACE `\s+`
+
+A class line defines a named character class for the patterns after it,
+as RuleSet.Class does, and a fragment line a named piece of pattern, as
+RuleSet.Fragment does:
+
+	class    IdentStart `[\p{L}_]`
+	fragment DIGITS     `[0-9](?:_?[0-9])*`
+
+The line option case-insensitive makes every rule match without regard
+to case, as RuleSet.CaseInsensitive does, and option first-match makes
+the first rule to match win over longer matches of later rules, as
+MATCH_FIRST does.
+
+Rules before the first state line belong to a state named INITIAL. The
+first state is where lexing starts. Tokens are numbered in the order
+they first appear, and a token may be produced by rules in several
+states.
+*/
+type Grammar struct {
+	Package     string
+	IgnoreCase  bool
+	Strategy    Strategy
+	Definitions []GrammarDefinition
+	States      []GrammarState
+}
+
+/*
+GrammarDefinition is a named character class of a grammar, or a named
+fragment if Fragment is set. Definitions are kept in the order they were
+read, since each may use those before it.
+*/
+type GrammarDefinition struct {
+	Name     string
+	Pattern  string
+	Fragment bool
+	Line     int
+}
+
+/*
+GrammarState is a named group of rules in a grammar. Only the rules of
+the current state are tried.
+*/
+type GrammarState struct {
+	Name  string
+	Rules []GrammarRule
+}
+
+/*
+GrammarRule is a rule of a grammar. Lookahead, LookaheadNegated and
+Skip are as in Rule. Next is the name of the state to move to after matching, or
+empty to stay. Line is the line of the
+grammar file the rule was read from.
+*/
+type GrammarRule struct {
+	Token            string
+	Pattern          string
+	Lookahead        string
+	LookaheadNegated bool
+	Skip             bool
+	Next             string
+	Line             int
+}
+
+/*
+ParseGrammar reads a grammar file. name is used in error messages,
+which give the line of the problem. Every pattern is compiled, so a
+grammar that parses without error generates a lexer that compiles.
+*/
+func ParseGrammar(name string, reader io.Reader) (*Grammar, error) {
+	grammar := &Grammar{}
+	definitions := New()
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+
+	fail := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%s:%d: %s", name, lineNumber, fmt.Sprintf(format, args...))
+	}
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		keyword, rest := splitWord(line)
+
+		switch keyword {
+		case "package":
+			if grammar.Package != "" {
+				return nil, fail("package declared twice")
+			}
+
+			if !isIdentifier(rest) {
+				return nil, fail("invalid package name %q", rest)
+			}
+
+			grammar.Package = rest
+
+		case "state":
+			if !isIdentifier(rest) {
+				return nil, fail("invalid state name %q", rest)
+			}
+
+			if grammar.State(rest) != nil {
+				return nil, fail("state %s declared twice", rest)
+			}
+
+			grammar.States = append(grammar.States, GrammarState{Name: rest})
+
+		case "option":
+			switch rest {
+			case "case-insensitive":
+				grammar.IgnoreCase = true
+
+			case "first-match":
+				grammar.Strategy = MATCH_FIRST
+
+			default:
+				return nil, fail("unknown option %q", rest)
+			}
+
+		case "class", "fragment":
+			definition, err := parseGrammarDefinition(keyword, rest)
+			if err != nil {
+				return nil, fail("%s", err)
+			}
+
+			definition.Line = lineNumber
+
+			if definitions.define(definition); definitions.err != nil {
+				return nil, fail("%s", strings.TrimPrefix(definitions.err.Error(), "rules: "))
+			}
+
+			grammar.Definitions = append(grammar.Definitions, definition)
+
+		default:
+			skip := keyword == "skip"
+			if skip {
+				keyword, rest = splitWord(rest)
+			}
+
+			rule, err := parseGrammarRule(keyword, rest)
+			if err != nil {
+				return nil, fail("%s", err)
+			}
+
+			rule.Skip = skip
+
+			rule.Line = lineNumber
+
+			check := definitions.Clone().Add(rule.Token, rule.Pattern, 1)
+			if rule.Lookahead != "" {
+				check.FollowedBy(rule.Lookahead)
+			}
+
+			if _, err := check.Compile(); err != nil {
+				return nil, fail("%s", strings.TrimPrefix(err.Error(), "rules: "))
+			}
+
+			if len(grammar.States) == 0 {
+				grammar.States = append(grammar.States, GrammarState{Name: "INITIAL"})
+			}
+
+			state := &grammar.States[len(grammar.States)-1]
+			state.Rules = append(state.Rules, rule)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if err := grammar.check(name); err != nil {
+		return nil, err
+	}
+
+	return grammar, nil
+}
+
+/*
+check makes sure every state has rules and every rule moves to a state
+that exists
+*/
+func (grammar *Grammar) check(name string) error {
+	if len(grammar.States) == 0 {
+		return fmt.Errorf("%s: grammar has no rules", name)
+	}
+
+	for _, state := range grammar.States {
+		if len(state.Rules) == 0 {
+			return fmt.Errorf("%s: state %s has no rules", name, state.Name)
+		}
+
+		for _, rule := range state.Rules {
+			if rule.Next != "" && grammar.State(rule.Next) == nil {
+				return fmt.Errorf("%s:%d: unknown state %s", name, rule.Line, rule.Next)
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+State returns the state with the given name, or nil if there is none
+*/
+func (grammar *Grammar) State(name string) *GrammarState {
+	for index := range grammar.States {
+		if grammar.States[index].Name == name {
+			return &grammar.States[index]
+		}
+	}
+
+	return nil
+}
+
+/*
+Tokens returns the names of the grammar's tokens in the order they
+first appear, leaving out the names of skip rules. The token type of each is its index plus one.
+*/
+func (grammar *Grammar) Tokens() []string {
+	seen := map[string]bool{}
+	result := []string{}
+
+	for _, state := range grammar.States {
+		for _, rule := range state.Rules {
+			if !rule.Skip && !seen[rule.Token] {
+				seen[rule.Token] = true
+				result = append(result, rule.Token)
+			}
+		}
+	}
+
+	return result
+}
+
+/*
+Names returns the names of the grammar's token types, for printing
+tokens lexed with LexFn
+*/
+func (grammar *Grammar) Names() lexer.TokenNames {
+	names := lexer.TokenNames{}
+
+	for index, token := range grammar.Tokens() {
+		names[lexer.TokenType(index+1)] = token
+	}
+
+	return names
+}
+
+/*
+LexFn compiles the grammar into a state function, for lexing with a
+grammar read at run time instead of generated code. It behaves as the
+code WriteGo generates does, with the same token types.
+*/
+func (grammar *Grammar) LexFn() (lexer.LexFn, error) {
+	if err := grammar.check("grammar"); err != nil {
+		return nil, err
+	}
+
+	types := map[string]lexer.TokenType{}
+	for index, token := range grammar.Tokens() {
+		types[token] = lexer.TokenType(index + 1)
+	}
+
+	machine, err := grammar.RuleSet(types).Compile()
+	if err != nil {
+		return nil, err
+	}
+
+	return machine.LexFn(), nil
+}
+
+/*
+RuleSet builds a rule set from the grammar, with a state for each of
+its states and the token types given by types. The first state of the
+grammar takes the place of INITIAL.
+*/
+func (grammar *Grammar) RuleSet(types map[string]lexer.TokenType) *RuleSet {
+	ruleSet := New()
+
+	if grammar.IgnoreCase {
+		ruleSet.CaseInsensitive()
+	}
+
+	ruleSet.Strategy(grammar.Strategy)
+
+	for _, definition := range grammar.Definitions {
+		ruleSet.define(definition)
+	}
+
+	for index, state := range grammar.States {
+		if index > 0 {
+			ruleSet.State(state.Name)
+		}
+
+		for _, rule := range state.Rules {
+			if rule.Skip {
+				ruleSet.Skip(rule.Token, rule.Pattern)
+			} else {
+				ruleSet.Add(rule.Token, rule.Pattern, types[rule.Token])
+			}
+
+			switch {
+			case rule.Lookahead == "":
+				// The rule matches whatever follows it
+
+			case rule.LookaheadNegated:
+				ruleSet.NotFollowedBy(rule.Lookahead)
+
+			default:
+				ruleSet.FollowedBy(rule.Lookahead)
+			}
+
+			switch rule.Next {
+			case "", state.Name:
+				// The rule stays in its state
+
+			case grammar.States[0].Name:
+				ruleSet.Begin(INITIAL)
+
+			default:
+				ruleSet.Begin(rule.Next)
+			}
+		}
+	}
+
+	return ruleSet
+}
+
+/*
+parseGrammarRule parses the pattern and optional target state following
+a token name
+*/
+func parseGrammarRule(token string, rest string) (GrammarRule, error) {
+	if !isIdentifier(token) {
+		return GrammarRule{}, fmt.Errorf("invalid token name %q", token)
+	}
+
+	literal, err := strconv.QuotedPrefix(rest)
+	if err != nil {
+		return GrammarRule{}, fmt.Errorf("expected a quoted pattern after %s", token)
+	}
+
+	pattern, _ := strconv.Unquote(literal)
+	rule := GrammarRule{Token: token, Pattern: pattern}
+
+	rest = strings.TrimSpace(rest[len(literal):])
+
+	if slash, after := splitWord(rest); slash == "/" || slash == "!/" {
+		literal, err := strconv.QuotedPrefix(after)
+		if err != nil {
+			return GrammarRule{}, fmt.Errorf("expected a quoted lookahead after %s", slash)
+		}
+
+		rule.Lookahead, _ = strconv.Unquote(literal)
+		rule.LookaheadNegated = slash == "!/"
+		rest = strings.TrimSpace(after[len(literal):])
+	}
+
+	if rest == "" {
+		return rule, nil
+	}
+
+	arrow, next := splitWord(rest)
+	if arrow != "->" || !isIdentifier(next) {
+		return GrammarRule{}, fmt.Errorf("unexpected %q after pattern", rest)
+	}
+
+	rule.Next = next
+	return rule, nil
+}
+
+/*
+parseGrammarDefinition parses the name and pattern of a class or
+fragment line
+*/
+func parseGrammarDefinition(keyword string, rest string) (GrammarDefinition, error) {
+	name, rest := splitWord(rest)
+	if !isIdentifier(name) {
+		return GrammarDefinition{}, fmt.Errorf("invalid %s name %q", keyword, name)
+	}
+
+	literal, err := strconv.QuotedPrefix(rest)
+	if err != nil || strings.TrimSpace(rest[len(literal):]) != "" {
+		return GrammarDefinition{}, fmt.Errorf("expected a quoted pattern after %s %s", keyword, name)
+	}
+
+	pattern, _ := strconv.Unquote(literal)
+	return GrammarDefinition{Name: name, Pattern: pattern, Fragment: keyword == "fragment"}, nil
+}
+
+/*
+define adds a class or fragment of a grammar to the rule set
+*/
+func (ruleSet *RuleSet) define(definition GrammarDefinition) *RuleSet {
+	if definition.Fragment {
+		return ruleSet.Fragment(definition.Name, definition.Pattern)
+	}
+
+	return ruleSet.Class(definition.Name, definition.Pattern)
+}
+
+/*
+splitWord splits off the first whitespace separated word of line
+*/
+func splitWord(line string) (string, string) {
+	end := strings.IndexFunc(line, unicode.IsSpace)
+	if end < 0 {
+		return line, ""
+	}
+
+	return line[:end], strings.TrimSpace(line[end:])
+}
+
+func isIdentifier(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	for index, ch := range text {
+		if ch != '_' && !unicode.IsLetter(ch) && (index == 0 || !unicode.IsDigit(ch)) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,349 @@
+package rules
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Machine is a compiled rule set. Each state's transition table holds a
+row of 128 entries per DFA state for ASCII input, and sorted rune ranges
+for everything else, so matching a token is a loop of table lookups with
+no function calls for ASCII text.
+
+A Machine is immutable and safe for use by many lexers at once.
+*/
+type Machine struct {
+	rules     []Rule
+	states    []string
+	tables    []*dfaTable
+	next      []int
+	functions []lexer.LexFn
+	traces    []*nfa
+	steps     *[]Step
+	coverage  *Coverage
+	strategy  Strategy
+}
+
+/*
+Rules returns the rules the machine was compiled from
+*/
+func (machine *Machine) Rules() []Rule {
+	return machine.rules
+}
+
+/*
+Scan matches tokens back to back from the start of input, calling
+matchFn with the rule index and byte offsets of each match. It starts
+in the INITIAL state and follows the moves of rules made with Begin, but
+does not call actions. It stops when no rule matches, when matchFn
+returns false, or at the end of input, and returns the offset it stopped
+at. Scan does no position tracking or token construction, making it the
+fastest way to run a machine when only offsets are needed.
+*/
+func (machine *Machine) Scan(input string, matchFn func(rule int, start int, end int) bool) int {
+	pos := 0
+	table := machine.tables[0]
+
+	for pos < len(input) {
+		rule, length, _ := table.match(input[pos:], true)
+		if rule < 0 || !matchFn(rule, pos, pos+length) {
+			break
+		}
+
+		if next := machine.next[rule]; next >= 0 {
+			table = machine.tables[next]
+		}
+
+		pos += length
+	}
+
+	return pos
+}
+
+/*
+States returns the number of states in the machine's DFAs
+*/
+func (machine *Machine) States() int {
+	count := 0
+
+	for _, table := range machine.tables {
+		count += len(table.accepts)
+	}
+
+	return count
+}
+
+/*
+StateNames returns the names of the rule set states the machine was
+compiled from, starting with INITIAL
+*/
+func (machine *Machine) StateNames() []string {
+	return machine.states
+}
+
+/*
+Match finds the longest prefix of input matched by any rule of the
+INITIAL state. It returns the index of the matching rule and the length
+of the match in bytes, or -1 and 0 if no rule matches.
+*/
+func (machine *Machine) Match(input string) (rule int, length int) {
+	rule, length, _ = machine.tables[0].match(input, true)
+	return rule, length
+}
+
+/*
+LexFn returns a state function that lexes using the machine, starting
+in the INITIAL state. At each position it emits a token for the longest
+match, ignores it for a skip rule, or calls the rule's action. Input
+that no rule matches is reported with Errorf a character at a time,
+after which lexing continues. A TOKEN_EOF token is emitted at the end of
+input. Each state of the rule set has a state function of its own, which
+the lexer moves to when a rule begins the state. The state functions lex
+a token per call and label the state they move to with its name, as in
+rules.INITIAL, with Lexer.LabelState.
+*/
+func (machine *Machine) LexFn() lexer.LexFn {
+	return machine.functions[0]
+}
+
+/*
+StateFn returns the state function lexing in the named state, or nil if
+the machine has no such state. Hand-written state functions return it
+to hand lexing back to the rules.
+*/
+func (machine *Machine) StateFn(state string) lexer.LexFn {
+	for index, name := range machine.states {
+		if name == state {
+			return machine.functions[index]
+		}
+	}
+
+	return nil
+}
+
+/*
+buildFunctions creates the state function of each state. Each call lexes
+a single token, so debuggers and state timing see every token, and each
+labels the state it moves to with the state's name, such as
+rules.INITIAL.
+*/
+func (machine *Machine) buildFunctions() {
+	machine.functions = make([]lexer.LexFn, len(machine.tables))
+
+	for index := range machine.tables {
+		state := index
+
+		machine.functions[index] = func(l *lexer.Lexer) lexer.LexFn {
+			if l.IsEOF() {
+				l.Emit(lexer.TOKEN_EOF)
+				return nil
+			}
+
+			handOver, next := machine.lexToken(l, state)
+
+			if handOver != nil {
+				return handOver
+			}
+
+			if next >= 0 {
+				return machine.moveTo(l, next)
+			}
+
+			return machine.moveTo(l, state)
+		}
+	}
+}
+
+/*
+moveTo labels the state with the given index as the one l moves to
+next and returns its state function
+*/
+func (machine *Machine) moveTo(l *lexer.Lexer, state int) lexer.LexFn {
+	l.LabelState("rules." + machine.states[state])
+	return machine.functions[state]
+}
+
+/*
+lexToken lexes one token in the state with the given index. It returns
+the state function an action handed lexing to, if any, and the index
+of the state a rule began, or -1.
+*/
+func (machine *Machine) lexToken(l *lexer.Lexer, state int) (lexer.LexFn, int) {
+	rule, length := machine.tables[state].matchLexer(l)
+
+	if machine.traces != nil && (l.Logging() || machine.steps != nil) {
+		machine.trace(l, state, rule, length)
+	}
+
+	if rule < 0 {
+		ch := l.Next()
+
+		if machine.coverage != nil {
+			machine.coverage.miss(l, ch)
+		}
+
+		l.Errorf("unexpected character %q", ch)
+		l.Ignore()
+		return nil, -1
+	}
+
+	l.Inc(length)
+
+	if machine.coverage != nil {
+		machine.coverage.hits[rule]++
+	}
+
+	var handOver lexer.LexFn
+	if action := machine.rules[rule].Action; action != nil {
+		handOver = action(l, l.CurrentInput())
+	}
+
+	if l.Pos > l.Start {
+		if machine.rules[rule].Skip {
+			l.Ignore()
+		} else {
+			l.Emit(machine.rules[rule].Type)
+		}
+	}
+
+	return handOver, machine.next[rule]
+}
+
+/*
+NewLexer creates a lexer for input that lexes using the machine, with
+its start state labeled rules.INITIAL
+*/
+func (machine *Machine) NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	l := lexer.NewLexer(name, input, machine.LexFn(), options...)
+	l.LabelState("rules." + machine.states[0])
+
+	return l
+}
+
+/*
+MatchLexer finds the longest match of the INITIAL state's rules at the
+lexer's current position without consuming it, returning the rule index
+and length as Match does. When the lexer reads from a reader the window
+is extended for as long as the DFA is still running at its end. State
+functions that mix rules with hand-written code, including generated
+ones, match with it.
+*/
+func (machine *Machine) MatchLexer(l *lexer.Lexer) (int, int) {
+	return machine.tables[0].matchLexer(l)
+}
+
+func (table *dfaTable) matchLexer(l *lexer.Lexer) (int, int) {
+	atEnd := false
+
+	for {
+		rule, length, exhausted := table.match(l.Input[l.Pos:], atEnd)
+		available := len(l.Input) - l.Pos
+
+		if !exhausted || atEnd {
+			return rule, length
+		}
+
+		if len(l.PeekCharacters(available*2+utf8.UTFMax)) == available {
+			if table.lookaheads == nil {
+				return rule, length
+			}
+
+			// Lookaheads still waiting for input fail or pass at the end
+			atEnd = true
+		}
+	}
+}
+
+/*
+match runs the DFA over input. Exhausted is true when input ran out
+while the DFA could still have matched more, or a lookahead needed more
+input to decide; atEnd says input is all there is, so lookaheads decide
+without it.
+*/
+func (table *dfaTable) match(input string, atEnd bool) (rule int, length int, exhausted bool) {
+	state := table.start
+	rule = -1
+
+	for pos := 0; pos < len(input); {
+		var next int32
+
+		if ch := input[pos]; ch < utf8.RuneSelf {
+			next = table.ascii[int(state)<<7|int(ch)]
+			pos++
+		} else {
+			if !utf8.FullRuneInString(input[pos:]) {
+				return rule, length, true
+			}
+
+			r, width := utf8.DecodeRuneInString(input[pos:])
+			next = table.step(state, r)
+			pos += width
+		}
+
+		if next < 0 {
+			return rule, length, false
+		}
+
+		state = next
+		accept := table.accepts[state]
+
+		if accept >= 0 && table.choices != nil {
+			chosen, waiting := table.choose(state, input[pos:], atEnd)
+			if waiting {
+				return rule, length, true
+			}
+
+			accept = chosen
+		}
+
+		// The first match strategy keeps the first rule that matched
+		// for as long as it goes on matching
+		if accept >= 0 && (!table.first || rule < 0 || int(accept) <= rule) {
+			rule, length = int(accept), pos
+		}
+	}
+
+	return rule, length, true
+}
+
+/*
+choose picks the first of the rules a DFA state accepts whose lookahead
+holds on rest, the input after the match, or -1 if none does. Waiting
+is true when a lookahead needs more input than rest to decide.
+*/
+func (table *dfaTable) choose(state int32, rest string, atEnd bool) (chosen int32, waiting bool) {
+	for _, rule := range table.choices[state] {
+		check := table.lookaheads[rule]
+		if check == nil {
+			return rule, false
+		}
+
+		matched, _, exhausted := check.table.match(rest, atEnd)
+		if matched < 0 && exhausted && !atEnd {
+			return -1, true
+		}
+
+		if (matched >= 0) != check.negated {
+			return rule, false
+		}
+	}
+
+	return -1, false
+}
+
+func (table *dfaTable) step(state int32, ch rune) int32 {
+	transitions := table.wide[state]
+
+	index := sort.Search(len(transitions), func(i int) bool {
+		return transitions[i].hi >= ch
+	})
+
+	if index < len(transitions) && transitions[index].lo <= ch {
+		return transitions[index].next
+	}
+
+	return -1
+}
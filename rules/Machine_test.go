@@ -0,0 +1,157 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+matchTests pairs rule sets with inputs. Each rule set is compiled into a
+machine, whose longest match of each input is compared with the longest
+match of the patterns compiled by regexp.
+*/
+var matchTests = []struct {
+	patterns []string
+	inputs   []string
+}{
+	{
+		[]string{`abc`},
+		[]string{"", "a", "ab", "abc", "abcd", "xabc", "ABC"},
+	},
+	{
+		[]string{`[a-z]+`, `[0-9]+`, `\s+`},
+		[]string{"", "hello world", "42abc", "  \t\nx", "!", "é"},
+	},
+	{
+		[]string{`[0-9]+`, `[0-9]+\.[0-9]+`, `[0-9]+(\.[0-9]+)?[eE][+-]?[0-9]+`},
+		[]string{"1", "12.5", "12.", "1e10", "1.5E-3x", "1.e5", ".5"},
+	},
+	{
+		[]string{`if`, `else`, `[a-z]+`},
+		[]string{"if", "iffy", "else", "elsewhere", "i", "IF"},
+	},
+	{
+		[]string{`"([^"\\]|\\.)*"`},
+		[]string{`"abc"`, `"a\"b" rest`, `"unterminated`, `"\\"`, `""`, `"é\n"`},
+	},
+	{
+		[]string{`/\*([^*]|\*+[^*/])*\*+/`, `//[^\n]*`},
+		[]string{"/* a */", "/** a **/ b", "/* a", "// line\nnext", "/ x"},
+	},
+	{
+		[]string{`a{2,3}`, `(ab|ba){2}`, `x?y*z`},
+		[]string{"a", "aa", "aaaa", "abba", "abab", "baab", "z", "xyyz", "yz", "xz"},
+	},
+	{
+		[]string{`[^\n]`, `.`, `\n`},
+		[]string{"\n", "a", "日本", "\x00"},
+	},
+	{
+		[]string{`(?i)select`, `[\p{L}_][\p{L}\p{N}_]*`},
+		[]string{"SELECT", "Select *", "selected", "_x1", "名前2 ", "9"},
+	},
+	{
+		[]string{`<=|>=|<|>|=`, `[<>]{2}`},
+		[]string{"<", "<=", "<<", "><", "=>", ">=="},
+	},
+}
+
+func TestMatchAgainstRegexp(t *testing.T) {
+	for _, test := range matchTests {
+		machine, expressions := compileBoth(t, test.patterns)
+
+		for _, input := range test.inputs {
+			wantRule, wantLength := longestMatch(expressions, input)
+			rule, length := machine.Match(input)
+
+			if rule != wantRule || length != wantLength {
+				t.Errorf("matching %q against %q: got rule %d of length %d, want rule %d of length %d", input, test.patterns, rule, length, wantRule, wantLength)
+			}
+		}
+	}
+}
+
+func TestLexAgainstRegexp(t *testing.T) {
+	for _, test := range matchTests {
+		machine, expressions := compileBoth(t, test.patterns)
+
+		for _, input := range test.inputs {
+			var want []lexer.Token
+
+			for rest := input; rest != ""; {
+				rule, length := longestMatch(expressions, rest)
+
+				if rule < 0 {
+					_, length = utf8.DecodeRuneInString(rest)
+					want = append(want, lexer.Token{Type: lexer.TOKEN_ERROR})
+				} else {
+					want = append(want, lexer.Token{Type: lexer.TokenType(rule + 1), Text: rest[:length]})
+				}
+
+				rest = rest[length:]
+			}
+
+			got := machine.NewLexer("test", input).Collect()
+			got = got[:len(got)-1]
+
+			if len(got) != len(want) {
+				t.Errorf("lexing %q with %q: got %d tokens, want %d", input, test.patterns, len(got), len(want))
+				continue
+			}
+
+			for index := range want {
+				if got[index].Type != want[index].Type || want[index].Type != lexer.TOKEN_ERROR && got[index].Text != want[index].Text {
+					t.Errorf("lexing %q with %q: token %d is %d %q, want %d %q", input, test.patterns, index, got[index].Type, got[index].Text, want[index].Type, want[index].Text)
+				}
+			}
+		}
+	}
+}
+
+/*
+compileBoth compiles patterns into a machine with a rule for each, of
+the token type one past its index, and into regular expressions matching
+the longest prefix of their input
+*/
+func compileBoth(t *testing.T, patterns []string) (*Machine, []*regexp.Regexp) {
+	t.Helper()
+
+	ruleSet := New()
+	expressions := make([]*regexp.Regexp, len(patterns))
+
+	for index, pattern := range patterns {
+		ruleSet.Add(fmt.Sprintf("rule%d", index), pattern, lexer.TokenType(index+1))
+
+		expressions[index] = regexp.MustCompile(`\A(?:` + pattern + `)`)
+		expressions[index].Longest()
+	}
+
+	machine, err := ruleSet.Compile()
+	if err != nil {
+		t.Fatalf("compiling %q: %s", patterns, err)
+	}
+
+	return machine, expressions
+}
+
+/*
+longestMatch returns the index of the expression matching the longest
+prefix of input, the first one on a tie, and the length of its match,
+or -1 and 0 if none matches. Empty matches do not count, as rules that
+match the empty string do not compile.
+*/
+func longestMatch(expressions []*regexp.Regexp, input string) (int, int) {
+	rule, length := -1, 0
+
+	for index, expression := range expressions {
+		if match := expression.FindStringIndex(input); match != nil && match[1] > length {
+			rule, length = index, match[1]
+		}
+	}
+
+	return rule, length
+}
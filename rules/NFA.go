@@ -0,0 +1,206 @@
+package rules
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"unicode"
+)
+
+type runeRange struct {
+	lo rune
+	hi rune
+}
+
+/*
+nfaState is a state of a Thompson NFA. A state either consumes a rune in
+one of its ranges and moves to next, or moves to its epsilon states
+without consuming anything. Accept holds the index of the rule matched
+on reaching the state, or -1.
+*/
+type nfaState struct {
+	ranges  []runeRange
+	next    int
+	epsilon []int
+	accept  int
+}
+
+type nfa struct {
+	states []nfaState
+	start  int
+}
+
+func buildNFA(rules []Rule) (*nfa, error) {
+	automaton := &nfa{}
+	automaton.start = automaton.newState()
+
+	for index, rule := range rules {
+		flags := syntax.Perl
+		if rule.IgnoreCase {
+			flags |= syntax.FoldCase
+		}
+
+		parsed, err := syntax.Parse(rule.Pattern, flags)
+		if err != nil {
+			return nil, fmt.Errorf("rules: rule %q: %w", rule.Name, err)
+		}
+
+		start, end, err := automaton.build(parsed.Simplify())
+		if err != nil {
+			return nil, fmt.Errorf("rules: rule %q: %w", rule.Name, err)
+		}
+
+		automaton.states[end].accept = index
+		automaton.addEpsilon(automaton.start, start)
+	}
+
+	return automaton, nil
+}
+
+func (automaton *nfa) newState() int {
+	automaton.states = append(automaton.states, nfaState{next: -1, accept: -1})
+	return len(automaton.states) - 1
+}
+
+func (automaton *nfa) addEpsilon(from, to int) {
+	automaton.states[from].epsilon = append(automaton.states[from].epsilon, to)
+}
+
+func (automaton *nfa) addRanges(ranges []runeRange) (int, int) {
+	start := automaton.newState()
+	end := automaton.newState()
+
+	automaton.states[start].ranges = ranges
+	automaton.states[start].next = end
+
+	return start, end
+}
+
+func (automaton *nfa) build(re *syntax.Regexp) (int, int, error) {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		state := automaton.newState()
+		return state, state, nil
+
+	case syntax.OpNoMatch:
+		return automaton.newState(), automaton.newState(), nil
+
+	case syntax.OpLiteral:
+		start := automaton.newState()
+		end := start
+
+		for _, ch := range re.Rune {
+			ranges := []runeRange{{ch, ch}}
+			if re.Flags&syntax.FoldCase != 0 {
+				ranges = foldRanges(ch)
+			}
+
+			next := automaton.newState()
+			automaton.states[end].ranges = ranges
+			automaton.states[end].next = next
+			end = next
+		}
+
+		return start, end, nil
+
+	case syntax.OpCharClass:
+		ranges := make([]runeRange, 0, len(re.Rune)/2)
+		for index := 0; index+1 < len(re.Rune); index += 2 {
+			ranges = append(ranges, runeRange{re.Rune[index], re.Rune[index+1]})
+		}
+
+		start, end := automaton.addRanges(ranges)
+		return start, end, nil
+
+	case syntax.OpAnyCharNotNL:
+		start, end := automaton.addRanges([]runeRange{{0, '\n' - 1}, {'\n' + 1, unicode.MaxRune}})
+		return start, end, nil
+
+	case syntax.OpAnyChar:
+		start, end := automaton.addRanges([]runeRange{{0, unicode.MaxRune}})
+		return start, end, nil
+
+	case syntax.OpCapture:
+		return automaton.build(re.Sub[0])
+
+	case syntax.OpConcat:
+		start := automaton.newState()
+		end := start
+
+		for _, sub := range re.Sub {
+			subStart, subEnd, err := automaton.build(sub)
+			if err != nil {
+				return 0, 0, err
+			}
+
+			automaton.addEpsilon(end, subStart)
+			end = subEnd
+		}
+
+		return start, end, nil
+
+	case syntax.OpAlternate:
+		start := automaton.newState()
+		end := automaton.newState()
+
+		for _, sub := range re.Sub {
+			subStart, subEnd, err := automaton.build(sub)
+			if err != nil {
+				return 0, 0, err
+			}
+
+			automaton.addEpsilon(start, subStart)
+			automaton.addEpsilon(subEnd, end)
+		}
+
+		return start, end, nil
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		subStart, subEnd, err := automaton.build(re.Sub[0])
+		if err != nil {
+			return 0, 0, err
+		}
+
+		start := automaton.newState()
+		end := automaton.newState()
+
+		automaton.addEpsilon(start, subStart)
+		automaton.addEpsilon(subEnd, end)
+
+		if re.Op != syntax.OpPlus {
+			automaton.addEpsilon(start, end)
+		}
+
+		if re.Op != syntax.OpQuest {
+			automaton.addEpsilon(subEnd, subStart)
+		}
+
+		return start, end, nil
+	}
+
+	return 0, 0, fmt.Errorf("%s is not supported in rule patterns", describeOp(re.Op))
+}
+
+func foldRanges(ch rune) []runeRange {
+	result := []runeRange{{ch, ch}}
+
+	for folded := unicode.SimpleFold(ch); folded != ch; folded = unicode.SimpleFold(folded) {
+		result = append(result, runeRange{folded, folded})
+	}
+
+	return result
+}
+
+func describeOp(op syntax.Op) string {
+	switch op {
+	case syntax.OpBeginLine, syntax.OpBeginText:
+		return "a start anchor"
+
+	case syntax.OpEndLine, syntax.OpEndText:
+		return "an end anchor"
+
+	case syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return "a word boundary"
+	}
+
+	return fmt.Sprintf("operator %d", op)
+}
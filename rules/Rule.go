@@ -0,0 +1,47 @@
+package rules
+
+import "github.com/adampresley/lexer"
+
+/*
+A Rule describes one kind of token in a declarative rule set. Pattern is
+a regular expression in the syntax of the regexp package, matched at the
+current input position. Type is the token type emitted for the match.
+State is the state the rule belongs to, and Next the state to move to
+after it matches, or empty to stay. Action, if set, is called for each
+match in place of emitting the token.
+
+Lookahead, if set, is a pattern that must match the input following the
+match, or with LookaheadNegated must not, for the rule to match. It is
+not part of the match and does not count towards its length.
+
+IgnoreCase matches Pattern and Lookahead without regard to case, as the
+(?i) flag does. Skip throws the match away instead of emitting it, as
+with whitespace and comments; lexers created with lexer.WithTrivia emit
+it as trivia.
+*/
+type Rule struct {
+	Name             string
+	Pattern          string
+	Type             lexer.TokenType
+	State            string
+	Next             string
+	Action           Action
+	Lookahead        string
+	LookaheadNegated bool
+	IgnoreCase       bool
+	Skip             bool
+}
+
+/*
+An Action handles the matches of a rule whose tokens need more than
+emitting: updating a symbol table, pushing a mode, or giving the token
+a value. It is called with the match consumed but not yet emitted, and
+may emit it with a transform, ignore it, or consume further input. Any
+of the match still pending when the action returns is emitted with the
+rule's token type.
+
+Returning nil carries on with the rules, in the state the rule begins
+if it has one. Returning a state function hands lexing over to it; a
+hand-written state can hand it back with Machine.StateFn.
+*/
+type Action func(l *lexer.Lexer, match string) lexer.LexFn
@@ -0,0 +1,573 @@
+/*
+Package rules builds lexers from declarative rule sets. Each rule pairs
+a regular expression with a token type. A rule set is compiled into
+minimal deterministic finite automata, one for each state, run by a
+table-driven scanning loop, so a lexer described as data performs like
+a generated scanner while still producing ordinary tokens through the
+lexer package's Lexer.
+
+	machine, err := rules.New().
+		Add("number", `[0-9]+`, TOKEN_NUMBER).
+		Add("ident", `[a-zA-Z_][a-zA-Z0-9_]*`, TOKEN_IDENT).
+		Add("space", `\s+`, TOKEN_SPACE).
+		Compile()
+
+	l := machine.NewLexer("input", input)
+
+At each position the longest match wins. When rules match the same
+length, the rule added first wins. Strategy selects the first matching
+rule instead, for specifications that order tokens by precedence.
+
+Modal grammars, where strings, comments or embedded code follow rules
+of their own, group rules into named states. Rules belong to the state
+named by the last call to State, INITIAL until State is called, and only
+the rules of the current state are tried. Begin makes the rule added
+last move the lexer to another state:
+
+	machine, err := rules.New().
+		Add("ident", `[a-z]+`, TOKEN_IDENT).
+		Add("quote", `"`, TOKEN_QUOTE).Begin("STRING").
+		State("STRING").
+		Add("text", `[^"\\]+`, TOKEN_TEXT).
+		Add("escape", `\\.`, TOKEN_ESCAPE).
+		Add("quote", `"`, TOKEN_QUOTE).Begin(rules.INITIAL).
+		Compile()
+*/
+package rules
+
+import (
+	"fmt"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+INITIAL is the name of the state lexing starts in
+*/
+const INITIAL = "INITIAL"
+
+/*
+RuleSet is an ordered collection of rules waiting to be compiled
+*/
+type RuleSet struct {
+	rules     []Rule
+	last      int
+	states    []string
+	current   string
+	classes   map[string][]runeRange
+	fragments map[string]string
+	caseless  bool
+	strategy  Strategy
+	err       error
+}
+
+/*
+New creates an empty rule set
+*/
+func New() *RuleSet {
+	return &RuleSet{
+		last:    -1,
+		states:  []string{INITIAL},
+		current: INITIAL,
+	}
+}
+
+/*
+Add appends a rule to the current state of the rule set. It returns the
+rule set so calls can be chained. Classes defined with Class are
+expanded in pattern as the rule is added.
+*/
+func (ruleSet *RuleSet) Add(name string, pattern string, tokenType lexer.TokenType) *RuleSet {
+	pattern = ruleSet.expand(pattern)
+	ruleSet.rules = append(ruleSet.rules, Rule{Name: name, Pattern: pattern, Type: tokenType, State: ruleSet.current})
+	ruleSet.last = len(ruleSet.rules) - 1
+	return ruleSet
+}
+
+/*
+Skip appends a rule to the current state whose matches are thrown away,
+so that whitespace and comments need no token type:
+
+	ruleSet.
+		Skip("space", `\s+`).
+		Skip("comment", `//[^\n]*`)
+
+Skipped input goes through Lexer.Ignore, so a lexer created with
+lexer.WithTrivia emits it as TOKEN_TRIVIA tokens instead.
+*/
+func (ruleSet *RuleSet) Skip(name string, pattern string) *RuleSet {
+	ruleSet.Add(name, pattern, lexer.TOKEN_TRIVIA)
+	ruleSet.rules[ruleSet.last].Skip = true
+
+	return ruleSet
+}
+
+/*
+Override replaces the first rule named name in the current state with a
+new rule, keeping its place and so its priority. The rule's state moves,
+actions and lookahead are dropped, and the methods that change the rule
+added last, such as Begin, change it instead. It is how a rule set
+derived with Include adjusts a rule it inherited:
+
+	dsl := rules.New().Include(cLike).
+		Override("ident", `[a-zA-Z_$][a-zA-Z0-9_$]*`, TOKEN_IDENT)
+*/
+func (ruleSet *RuleSet) Override(name string, pattern string, tokenType lexer.TokenType) *RuleSet {
+	for index, rule := range ruleSet.rules {
+		if rule.Name == name && rule.State == ruleSet.current {
+			pattern = ruleSet.expand(pattern)
+			ruleSet.rules[index] = Rule{Name: name, Pattern: pattern, Type: tokenType, State: ruleSet.current}
+			ruleSet.last = index
+
+			return ruleSet
+		}
+	}
+
+	if ruleSet.err == nil {
+		ruleSet.err = fmt.Errorf("rules: Override(%q) names no rule of state %s", name, ruleSet.current)
+	}
+
+	return ruleSet
+}
+
+/*
+Include adds the rules of other to the rule set, after its own, so that
+a family of lexers can share a base rule set instead of copies of it.
+The rules of other's INITIAL state join the current state, and rules
+moving to INITIAL move to the current state, so other can also be
+included as a group of rules into a state of a larger rule set. Its
+other states are declared and filled as they are named, and its classes
+and fragments become available to the rules added after it. Rules of a case
+insensitive rule set keep ignoring case.
+
+	cLike := rules.New().
+		Add("ident", `[a-zA-Z_][a-zA-Z0-9_]*`, TOKEN_IDENT).
+		Add("quote", `"`, TOKEN_QUOTE).Begin("STRING").
+		State("STRING").
+		Add("text", `[^"\\]+|\\.`, TOKEN_TEXT).
+		Add("quote", `"`, TOKEN_QUOTE).Begin(rules.INITIAL)
+
+	shell := rules.New().Include(cLike).
+		Add("variable", `\$[a-z]+`, TOKEN_VARIABLE)
+
+Include records an error, reported by Compile, if other has one or
+defines a class or fragment with the same name as one of the rule
+set's but a different meaning.
+*/
+func (ruleSet *RuleSet) Include(other *RuleSet) *RuleSet {
+	if ruleSet.err != nil {
+		return ruleSet
+	}
+
+	if other.err != nil {
+		ruleSet.err = other.err
+		return ruleSet
+	}
+
+	for name, ranges := range other.classes {
+		if existing, ok := ruleSet.classes[name]; ok && !sameRanges(existing, ranges) {
+			ruleSet.err = fmt.Errorf("rules: included class %s differs from the class of the same name", name)
+			return ruleSet
+		}
+
+		if ruleSet.classes == nil {
+			ruleSet.classes = map[string][]runeRange{}
+		}
+
+		ruleSet.classes[name] = ranges
+	}
+
+	for name, fragment := range other.fragments {
+		if existing, ok := ruleSet.fragments[name]; ok && existing != fragment {
+			ruleSet.err = fmt.Errorf("rules: included fragment %s differs from the fragment of the same name", name)
+			return ruleSet
+		}
+
+		if ruleSet.fragments == nil {
+			ruleSet.fragments = map[string]string{}
+		}
+
+		ruleSet.fragments[name] = fragment
+	}
+
+	target := func(state string) string {
+		if state == INITIAL {
+			return ruleSet.current
+		}
+
+		return state
+	}
+
+	for _, state := range other.states {
+		if state != INITIAL && ruleSet.stateIndex(state) < 0 {
+			ruleSet.states = append(ruleSet.states, state)
+		}
+	}
+
+	for _, rule := range other.Rules() {
+		rule.State = target(rule.State)
+
+		if rule.Next != "" {
+			rule.Next = target(rule.Next)
+		}
+
+		ruleSet.rules = append(ruleSet.rules, rule)
+	}
+
+	ruleSet.last = -1
+	return ruleSet
+}
+
+/*
+State makes name the current state, so that the rules added after it
+belong to it, and declares the state if it is new
+*/
+func (ruleSet *RuleSet) State(name string) *RuleSet {
+	if ruleSet.stateIndex(name) < 0 {
+		ruleSet.states = append(ruleSet.states, name)
+	}
+
+	ruleSet.current = name
+	return ruleSet
+}
+
+/*
+Begin makes the rule added last move the lexer to the named state after
+it matches. The state may be declared later; Compile fails if it never
+is.
+*/
+func (ruleSet *RuleSet) Begin(state string) *RuleSet {
+	if rule := ruleSet.lastRule(fmt.Sprintf("Begin(%q)", state)); rule != nil {
+		rule.Next = state
+	}
+
+	return ruleSet
+}
+
+/*
+Action attaches an action to the rule added last, to be called for each
+of its matches in place of emitting the token:
+
+	ruleSet.Add("ident", `[a-z]+`, TOKEN_IDENT).Action(func(l *lexer.Lexer, match string) lexer.LexFn {
+		if typeNames[match] {
+			l.Emit(TOKEN_TYPE_NAME)
+		}
+
+		return nil
+	})
+*/
+func (ruleSet *RuleSet) Action(action Action) *RuleSet {
+	if rule := ruleSet.lastRule("Action"); rule != nil {
+		rule.Action = action
+	}
+
+	return ruleSet
+}
+
+/*
+FollowedBy makes the rule added last match only where pattern matches
+the input after it, as trailing context r/s does in flex. The text
+pattern matches is left for the next token. When the lookahead fails the
+rule gives way to the rules it would have beaten:
+
+	ruleSet.
+		Add("float", `[0-9]+\.[0-9]*`, TOKEN_FLOAT).NotFollowedBy(`\.`).
+		Add("int", `[0-9]+`, TOKEN_INT)
+
+lexes 1..2 as an int, a range operator and an int, where 1.5 is a
+float.
+*/
+func (ruleSet *RuleSet) FollowedBy(pattern string) *RuleSet {
+	return ruleSet.lookahead("FollowedBy", pattern, false)
+}
+
+/*
+NotFollowedBy makes the rule added last match only where pattern does
+not match the input after it
+*/
+func (ruleSet *RuleSet) NotFollowedBy(pattern string) *RuleSet {
+	return ruleSet.lookahead("NotFollowedBy", pattern, true)
+}
+
+func (ruleSet *RuleSet) lookahead(method string, pattern string, negated bool) *RuleSet {
+	if rule := ruleSet.lastRule(fmt.Sprintf("%s(%q)", method, pattern)); rule != nil {
+		rule.Lookahead = ruleSet.expand(pattern)
+		rule.LookaheadNegated = negated
+	}
+
+	return ruleSet
+}
+
+/*
+IgnoreCase makes the rule added last match without regard to case, so
+that a keyword such as SELECT needs no pattern like [Ss][Ee][Ll]...
+*/
+func (ruleSet *RuleSet) IgnoreCase() *RuleSet {
+	if rule := ruleSet.lastRule("IgnoreCase"); rule != nil {
+		rule.IgnoreCase = true
+	}
+
+	return ruleSet
+}
+
+/*
+CaseInsensitive makes every rule of the rule set, whether added before
+or after it, match without regard to case, for languages such as SQL
+and BASIC whose keywords and names ignore case throughout
+*/
+func (ruleSet *RuleSet) CaseInsensitive() *RuleSet {
+	ruleSet.caseless = true
+	return ruleSet
+}
+
+/*
+lastRule returns the rule the methods changing the rule added last
+change, recording an error naming call if there is none
+*/
+func (ruleSet *RuleSet) lastRule(call string) *Rule {
+	if ruleSet.last < 0 {
+		if ruleSet.err == nil {
+			ruleSet.err = fmt.Errorf("rules: %s called before any rule was added", call)
+		}
+
+		return nil
+	}
+
+	return &ruleSet.rules[ruleSet.last]
+}
+
+/*
+Remove removes every rule with the given name, in all states
+*/
+func (ruleSet *RuleSet) Remove(name string) *RuleSet {
+	kept := ruleSet.rules[:0]
+
+	for _, rule := range ruleSet.rules {
+		if rule.Name != name {
+			kept = append(kept, rule)
+		}
+	}
+
+	ruleSet.rules = kept
+	ruleSet.last = -1
+
+	return ruleSet
+}
+
+/*
+MoveBefore changes the priority of the rules named name, moving them to
+just before the first rule named before, so that they win the ties
+against it they used to lose. Both must be in the same state.
+*/
+func (ruleSet *RuleSet) MoveBefore(name string, before string) *RuleSet {
+	var moved, rest []Rule
+
+	for _, rule := range ruleSet.rules {
+		if rule.Name == name {
+			moved = append(moved, rule)
+		} else {
+			rest = append(rest, rule)
+		}
+	}
+
+	target := -1
+	for index, rule := range rest {
+		if rule.Name == before {
+			target = index
+			break
+		}
+	}
+
+	if len(moved) == 0 || target < 0 {
+		if ruleSet.err == nil {
+			ruleSet.err = fmt.Errorf("rules: MoveBefore(%q, %q) names a rule that does not exist", name, before)
+		}
+
+		return ruleSet
+	}
+
+	for _, rule := range moved {
+		if rule.State != rest[target].State {
+			if ruleSet.err == nil {
+				ruleSet.err = fmt.Errorf("rules: MoveBefore(%q, %q) names rules of different states", name, before)
+			}
+
+			return ruleSet
+		}
+	}
+
+	result := append([]Rule{}, rest[:target]...)
+	result = append(result, moved...)
+	ruleSet.rules = append(result, rest[target:]...)
+	ruleSet.last = -1
+
+	return ruleSet
+}
+
+/*
+Clone returns a copy of the rule set that can be changed without
+affecting the original. The copy's current state is INITIAL.
+*/
+func (ruleSet *RuleSet) Clone() *RuleSet {
+	classes := make(map[string][]runeRange, len(ruleSet.classes))
+	for name, ranges := range ruleSet.classes {
+		classes[name] = ranges
+	}
+
+	fragments := make(map[string]string, len(ruleSet.fragments))
+	for name, fragment := range ruleSet.fragments {
+		fragments[name] = fragment
+	}
+
+	return &RuleSet{
+		rules:     append([]Rule{}, ruleSet.rules...),
+		last:      -1,
+		states:    append([]string{}, ruleSet.states...),
+		current:   INITIAL,
+		classes:   classes,
+		fragments: fragments,
+		caseless:  ruleSet.caseless,
+		strategy:  ruleSet.strategy,
+		err:       ruleSet.err,
+	}
+}
+
+/*
+Rules returns the rules in the order they were added, with IgnoreCase
+set on all of them if the rule set is case insensitive
+*/
+func (ruleSet *RuleSet) Rules() []Rule {
+	if !ruleSet.caseless {
+		return ruleSet.rules
+	}
+
+	rules := append([]Rule{}, ruleSet.rules...)
+	for index := range rules {
+		rules[index].IgnoreCase = true
+	}
+
+	return rules
+}
+
+/*
+States returns the names of the rule set's states in the order they
+were declared, starting with INITIAL
+*/
+func (ruleSet *RuleSet) States() []string {
+	return ruleSet.states
+}
+
+/*
+Compile compiles the rule set into a Machine, with a DFA for each state.
+It fails if a pattern is not valid, uses a feature the DFA can not
+express such as anchors or word boundaries, or can match the empty
+string, if a state has no rules, or if a rule begins a state that was
+never declared.
+*/
+func (ruleSet *RuleSet) Compile() (*Machine, error) {
+	if err := ruleSet.check(); err != nil {
+		return nil, err
+	}
+
+	machine := &Machine{
+		rules:    ruleSet.Rules(),
+		states:   ruleSet.states,
+		strategy: ruleSet.strategy,
+		next:     make([]int, len(ruleSet.rules)),
+	}
+
+	for index, rule := range ruleSet.rules {
+		machine.next[index] = -1
+
+		if rule.Next != "" && rule.Next != rule.State {
+			machine.next[index] = ruleSet.stateIndex(rule.Next)
+		}
+	}
+
+	for _, state := range ruleSet.states {
+		rules, indexes := ruleSet.stateRules(state)
+
+		automaton, err := buildNFA(rules)
+		if err != nil {
+			return nil, err
+		}
+
+		table, err := buildTable(rules, indexes, automaton)
+		if err != nil {
+			return nil, err
+		}
+
+		table.first = ruleSet.strategy == MATCH_FIRST
+
+		machine.tables = append(machine.tables, table)
+	}
+
+	machine.buildFunctions()
+	return machine, nil
+}
+
+/*
+MustCompile is like Compile but panics if the rule set does not compile.
+It is meant for rule sets fixed at build time, such as those in
+generated code, held in package variables.
+*/
+func (ruleSet *RuleSet) MustCompile() *Machine {
+	machine, err := ruleSet.Compile()
+	if err != nil {
+		panic(err)
+	}
+
+	return machine
+}
+
+/*
+check reports errors recorded while building the rule set, states
+without rules, and moves to undeclared states
+*/
+func (ruleSet *RuleSet) check() error {
+	if ruleSet.err != nil {
+		return ruleSet.err
+	}
+
+	for _, state := range ruleSet.states {
+		if rules, _ := ruleSet.stateRules(state); len(rules) == 0 && (state != INITIAL || len(ruleSet.states) > 1) {
+			return fmt.Errorf("rules: state %s has no rules", state)
+		}
+	}
+
+	for _, rule := range ruleSet.rules {
+		if rule.Next != "" && ruleSet.stateIndex(rule.Next) < 0 {
+			return fmt.Errorf("rules: rule %q begins undeclared state %s", rule.Name, rule.Next)
+		}
+	}
+
+	return nil
+}
+
+/*
+stateRules returns the rules of a state, along with the index of each
+in the whole rule set
+*/
+func (ruleSet *RuleSet) stateRules(state string) ([]Rule, []int) {
+	var rules []Rule
+	var indexes []int
+
+	for index, rule := range ruleSet.Rules() {
+		if rule.State == state {
+			rules = append(rules, rule)
+			indexes = append(indexes, index)
+		}
+	}
+
+	return rules, indexes
+}
+
+func (ruleSet *RuleSet) stateIndex(name string) int {
+	for index, state := range ruleSet.states {
+		if state == name {
+			return index
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,48 @@
+package rules
+
+/*
+A Strategy decides which rule wins when several match at the same
+position. MATCH_LONGEST, the default, takes the longest match of any
+rule, with ties going to the rule added first, as lex and flex do and as
+most language specifications mean by maximal munch. MATCH_FIRST takes
+the rule added first among those that match at all, with its longest
+match, whatever longer matches later rules have, as specifications that
+list tokens in order of precedence and grammars ported from PEGs or
+regular expression alternation mean.
+*/
+type Strategy int
+
+const (
+	MATCH_LONGEST Strategy = iota
+	MATCH_FIRST
+)
+
+func (strategy Strategy) String() string {
+	switch strategy {
+	case MATCH_LONGEST:
+		return "longest match"
+
+	case MATCH_FIRST:
+		return "first match"
+	}
+
+	return "unknown"
+}
+
+/*
+Strategy sets how the rule set chooses between rules that match at the
+same position. The default is MATCH_LONGEST. It applies to every state,
+and Machine.Strategy reports the strategy a machine was compiled with.
+*/
+func (ruleSet *RuleSet) Strategy(strategy Strategy) *RuleSet {
+	ruleSet.strategy = strategy
+	return ruleSet
+}
+
+/*
+Strategy returns how the machine chooses between rules that match at
+the same position
+*/
+func (machine *Machine) Strategy() Strategy {
+	return machine.strategy
+}
@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Step is one token of a snippet lexed by Test: the rule that matched in
+what state at what position, and how each of the other rules of the
+state fared. Rule is -1 and Text the character reported when no rule
+matched.
+*/
+type Step struct {
+	State    string
+	Rule     int
+	RuleName string
+	Text     string
+	Position lexer.Position
+	Tried    []Attempt
+}
+
+/*
+Attempt is how a rule that lost fared at a step. Length is the length
+of its own longest match, or -1 if it matched nothing, and Reason why it
+lost: "no match", "shorter", "declared later" or "lookahead failed".
+*/
+type Attempt struct {
+	Rule   int
+	Name   string
+	Length int
+	Reason string
+}
+
+/*
+String describes the step in a line, as Trace logs it
+*/
+func (step Step) String() string {
+	tried := make([]string, len(step.Tried))
+
+	for index, attempt := range step.Tried {
+		if attempt.Length < 0 {
+			tried[index] = fmt.Sprintf("%s (%s)", attempt.Name, attempt.Reason)
+		} else {
+			tried[index] = fmt.Sprintf("%s (%d bytes, %s)", attempt.Name, attempt.Length, attempt.Reason)
+		}
+	}
+
+	summary := ""
+	if len(tried) > 0 {
+		summary = "; tried " + strings.Join(tried, ", ")
+	}
+
+	if step.Rule < 0 {
+		return fmt.Sprintf("no rule matched %q at %s in %s%s", step.Text, step.Position, step.State, summary)
+	}
+
+	return fmt.Sprintf("%s matched %q at %s in %s%s", step.RuleName, step.Text, step.Position, step.State, summary)
+}
+
+/*
+Test lexes a snippet with the machine and returns a step for every
+token, for trying rules out while writing them: a rule set can be edited
+and tested again without building anything, as lexgen -test does with
+a grammar file. Actions run as usual. Positions are lines and columns
+of the snippet.
+*/
+func (machine *Machine) Test(snippet string) []Step {
+	steps := []Step{}
+
+	tester := *machine.Trace()
+	tester.steps = &steps
+	tester.buildFunctions()
+
+	tester.NewLexer("", snippet).RunWith(func(lexer.Token) {})
+	return steps
+}
+
+/*
+Test compiles the rule set and lexes a snippet with it, as Machine.Test
+does
+*/
+func (ruleSet *RuleSet) Test(snippet string) ([]Step, error) {
+	machine, err := ruleSet.Compile()
+	if err != nil {
+		return nil, err
+	}
+
+	return machine.Test(snippet), nil
+}
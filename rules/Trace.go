@@ -0,0 +1,137 @@
+package rules
+
+import (
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Trace returns a copy of the machine that logs, for every token, which
+rule matched at what position and how each of the other rules of the
+state fared: how much it matched and why it lost, or that it matched
+nothing. The lines go to the lexer's logger, given with
+lexer.WithLogger, and nothing is logged by lexers without one.
+
+	ident matched "iff" at tiny:1:1 in INITIAL; tried kw_if (2 bytes, shorter), number (no match)
+
+Tracing runs each rule on its own alongside the DFA, so it is many times
+slower than lexing and is meant for debugging precedence in rule tables.
+*/
+func (machine *Machine) Trace() *Machine {
+	traced := *machine
+	traced.traces = make([]*nfa, len(machine.rules))
+	traced.steps = nil
+
+	for index, rule := range machine.rules {
+		// The rules compiled once already, so they compile again
+		traced.traces[index], _ = buildNFA([]Rule{rule})
+	}
+
+	traced.buildFunctions()
+	return &traced
+}
+
+/*
+trace logs the outcome of matching a token in the state with the given
+index, before the lexer moves past it, or records it for Test
+*/
+func (machine *Machine) trace(l *lexer.Lexer, state int, rule int, length int) {
+	step := machine.step(l, state, rule, length)
+
+	if machine.steps != nil {
+		*machine.steps = append(*machine.steps, step)
+	}
+
+	if l.Logging() {
+		l.Logf("%s", step)
+	}
+}
+
+/*
+step describes the outcome of matching a token in the state with the
+given index, running every other rule of the state on its own to say
+why it lost
+*/
+func (machine *Machine) step(l *lexer.Lexer, state int, rule int, length int) Step {
+	input := l.Input[l.Pos:]
+
+	step := Step{
+		State:    machine.states[state],
+		Rule:     rule,
+		Position: l.PositionAt(l.Pos),
+	}
+
+	if rule >= 0 {
+		step.RuleName = machine.rules[rule].Name
+		step.Text = input[:length]
+	} else {
+		ch, width := utf8.DecodeRuneInString(input)
+		if ch != utf8.RuneError || width > 0 {
+			step.Text = input[:width]
+		}
+	}
+
+	for index, candidate := range machine.rules {
+		if candidate.State != machine.states[state] || index == rule {
+			continue
+		}
+
+		attempt := Attempt{Rule: index, Name: candidate.Name, Length: machine.traces[index].longest(input)}
+
+		switch {
+		case attempt.Length < 0:
+			attempt.Reason = "no match"
+
+		case rule >= 0 && attempt.Length < length:
+			attempt.Reason = "shorter"
+
+		case rule >= 0 && index > rule && (attempt.Length == length || machine.strategy == MATCH_FIRST):
+			attempt.Reason = "declared later"
+
+		default:
+			attempt.Reason = "lookahead failed"
+		}
+
+		step.Tried = append(step.Tried, attempt)
+	}
+
+	return step
+}
+
+/*
+longest runs the NFA over input, returning the length of the longest
+prefix it accepts, or -1 if it accepts none
+*/
+func (automaton *nfa) longest(input string) int {
+	builder := &dfaBuilder{automaton: automaton}
+	current := builder.closure([]int{automaton.start})
+	longest := -1
+
+	for pos := 0; ; {
+		for _, state := range current {
+			if automaton.states[state].accept >= 0 {
+				longest = pos
+			}
+		}
+
+		if pos >= len(input) || len(current) == 0 {
+			return longest
+		}
+
+		ch, width := utf8.DecodeRuneInString(input[pos:])
+		pos += width
+
+		var next []int
+		for _, state := range current {
+			for _, r := range automaton.states[state].ranges {
+				if r.lo <= ch && ch <= r.hi {
+					next = append(next, automaton.states[state].next)
+					break
+				}
+			}
+		}
+
+		current = builder.closure(next)
+	}
+}
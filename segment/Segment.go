@@ -0,0 +1,270 @@
+/*
+Package segment splits natural language text into words and sentences by
+the boundary rules of Unicode Standard Annex #29, so tokenizers for
+prose, such as those of search indexers or chat filters, are built on
+the same lexers and tokens as those for code:
+
+	l := segment.NewLexer("message", "Don't panic! It's 3.14 km.")
+	stream := lexer.NewTokenStream(l, segment.TOKEN_SPACE)
+
+gives the tokens "Don't", "panic", "!", "It's", "3.14", "km" and ".", as
+apostrophes and decimal points inside words and numbers do not break
+them. Words, Sentences and Segments split a string directly, and
+AcceptWord and AcceptSentence let the states of any lexer consume
+prose, such as the text of comments or of a markup language.
+
+The standard library does not carry the Word_Break and Sentence_Break
+properties the rules are written against, so they are derived from the
+general categories, scripts and properties of the unicode package. The
+results match the annex for letters, numbers, punctuation and spaces in
+the common scripts. Scripts written without spaces, such as Thai and
+Chinese, are split into single characters, which is what the annex
+leaves for dictionary based segmentation to refine.
+*/
+package segment
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+const (
+	TOKEN_WORD lexer.TokenType = iota + 1
+	TOKEN_NUMBER
+	TOKEN_IDEOGRAPH
+	TOKEN_EMOJI
+	TOKEN_PUNCTUATION
+	TOKEN_SYMBOL
+	TOKEN_SPACE
+	TOKEN_SENTENCE
+)
+
+/*
+Names holds the names of the segment token types
+*/
+var Names = lexer.TokenNames{
+	TOKEN_WORD:        "WORD",
+	TOKEN_NUMBER:      "NUMBER",
+	TOKEN_IDEOGRAPH:   "IDEOGRAPH",
+	TOKEN_EMOJI:       "EMOJI",
+	TOKEN_PUNCTUATION: "PUNCTUATION",
+	TOKEN_SYMBOL:      "SYMBOL",
+	TOKEN_SPACE:       "SPACE",
+	TOKEN_SENTENCE:    "SENTENCE",
+}
+
+/*
+Categories maps the segment token types to highlighting categories
+*/
+var Categories = highlight.Categories{
+	TOKEN_NUMBER:      highlight.CATEGORY_NUMBER,
+	TOKEN_PUNCTUATION: highlight.CATEGORY_PUNCTUATION,
+	TOKEN_SYMBOL:      highlight.CATEGORY_OPERATOR,
+}
+
+/*
+lookahead is how far past the end of a segment the text is read before
+the segment is taken as complete, as the rules look past a boundary to
+decide it
+*/
+const lookahead = 64
+
+func init() {
+	lexer.NameState("segment.start", Start)
+	lexer.NameState("segment.sentences", StartSentences)
+}
+
+/*
+NewLexer creates a lexer splitting text held in memory into words
+*/
+func NewLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, Start, options...)
+}
+
+/*
+NewReaderLexer creates a lexer splitting text read from reader into
+words
+*/
+func NewReaderLexer(name string, reader io.Reader, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewReaderLexer(name, reader, Start, options...)
+}
+
+/*
+NewSentenceLexer creates a lexer splitting text held in memory into
+sentences
+*/
+func NewSentenceLexer(name string, input string, options ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(name, input, StartSentences, options...)
+}
+
+/*
+Start emits each word segment of the input as a token whose type tells
+what it holds. Spaces are emitted too, as TOKEN_SPACE; leave them out
+with a TokenStream.
+*/
+func Start(l *lexer.Lexer) lexer.LexFn {
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	l.Emit(AcceptWord(l))
+	return Start
+}
+
+/*
+StartSentences emits each sentence of the input as a TOKEN_SENTENCE
+token, with the spaces and line break that end it
+*/
+func StartSentences(l *lexer.Lexer) lexer.LexFn {
+	if l.IsEOF() {
+		l.Emit(lexer.TOKEN_EOF)
+		return nil
+	}
+
+	AcceptSentence(l)
+	l.Emit(TOKEN_SENTENCE)
+
+	return StartSentences
+}
+
+/*
+AcceptWord consumes the word segment at the lexer's position, returning
+the type of token it makes, or 0 at the end of the input
+*/
+func AcceptWord(l *lexer.Lexer) lexer.TokenType {
+	var kind wordKind
+
+	accept(l, func(text string) int {
+		var length int
+		length, kind = wordLength(text)
+
+		return length
+	})
+
+	return kind.tokenType()
+}
+
+/*
+AcceptSentence consumes the sentence at the lexer's position, returning
+true if there was one
+*/
+func AcceptSentence(l *lexer.Lexer) bool {
+	return accept(l, sentenceLength) > 0
+}
+
+/*
+WordLength returns the length in bytes of the word segment at the start
+of text
+*/
+func WordLength(text string) int {
+	length, _ := wordLength(text)
+	return length
+}
+
+/*
+SentenceLength returns the length in bytes of the sentence at the start
+of text
+*/
+func SentenceLength(text string) int {
+	return sentenceLength(text)
+}
+
+/*
+Segments splits text at every word boundary, so joining the segments
+gives text back
+*/
+func Segments(text string) []string {
+	return split(text, WordLength)
+}
+
+/*
+Words returns the word segments of text that hold words, numbers,
+ideographs or emoji, leaving out spaces and punctuation, as a search
+index would take them
+*/
+func Words(text string) []string {
+	words := []string{}
+
+	for text != "" {
+		length, kind := wordLength(text)
+
+		switch kind.tokenType() {
+		case TOKEN_WORD, TOKEN_NUMBER, TOKEN_IDEOGRAPH, TOKEN_EMOJI:
+			words = append(words, text[:length])
+		}
+
+		text = text[length:]
+	}
+
+	return words
+}
+
+/*
+Sentences splits text into sentences, each with the spaces and line
+break that end it
+*/
+func Sentences(text string) []string {
+	return split(text, sentenceLength)
+}
+
+func split(text string, length func(text string) int) []string {
+	result := []string{}
+
+	for text != "" {
+		n := length(text)
+		result = append(result, text[:n])
+		text = text[n:]
+	}
+
+	return result
+}
+
+/*
+accept consumes the segment length finds at the lexer's position. Lexers
+reading from a reader get more of the input until the segment ends well
+before the end of what has been read.
+*/
+func accept(l *lexer.Lexer, length func(text string) int) int {
+	for window := 4 * lookahead; ; window *= 2 {
+		text := l.PeekCharacters(window)
+		n := length(text)
+
+		if len(text) < window || n+lookahead <= len(text) {
+			l.Inc(n)
+			return n
+		}
+	}
+}
+
+/*
+tokenType returns the type of token a word segment makes
+*/
+func (kind wordKind) tokenType() lexer.TokenType {
+	switch {
+	case kind.letters:
+		return TOKEN_WORD
+
+	case kind.digits:
+		return TOKEN_NUMBER
+
+	case kind.ideographs:
+		return TOKEN_IDEOGRAPH
+
+	case kind.pictographs:
+		return TOKEN_EMOJI
+
+	case kind.spaces:
+		return TOKEN_SPACE
+
+	case kind.punctuation:
+		return TOKEN_PUNCTUATION
+
+	case kind.symbols:
+		return TOKEN_SYMBOL
+	}
+
+	return 0
+}
@@ -0,0 +1,269 @@
+package segment
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+sentenceClass is the Sentence_Break property of a character
+*/
+type sentenceClass int
+
+const (
+	sentenceOther sentenceClass = iota
+	sentenceCR
+	sentenceLF
+	sentenceSep
+	sentenceExtend
+	sentenceFormat
+	sentenceSp
+	sentenceLower
+	sentenceUpper
+	sentenceOLetter
+	sentenceNumeric
+	sentenceATerm
+	sentenceSTerm
+	sentenceClose
+	sentenceSContinue
+)
+
+var (
+	aTerm = map[rune]bool{
+		'.': true, 0x2024: true, 0xFE52: true, 0xFF0E: true,
+	}
+
+	sTerm = map[rune]bool{
+		'!': true, '?': true, 0x0589: true, 0x061F: true, 0x06D4: true,
+		0x0700: true, 0x0701: true, 0x0702: true, 0x07F9: true, 0x0964: true,
+		0x0965: true, 0x203C: true, 0x203D: true, 0x2047: true, 0x2048: true,
+		0x2049: true, 0x3002: true, 0xFE56: true, 0xFE57: true, 0xFF01: true,
+		0xFF1F: true, 0xFF61: true,
+	}
+
+	sContinue = map[rune]bool{
+		',': true, '-': true, ':': true, 0x055D: true, 0x060C: true,
+		0x060D: true, 0x07F8: true, 0x1802: true, 0x1808: true, 0x2013: true,
+		0x2014: true, 0x3001: true, 0xFE10: true, 0xFE11: true, 0xFE13: true,
+		0xFE31: true, 0xFE32: true, 0xFE50: true, 0xFE51: true, 0xFE55: true,
+		0xFE58: true, 0xFE63: true, 0xFF0C: true, 0xFF0D: true, 0xFF1A: true,
+		0xFF64: true,
+	}
+
+	closePunctuation = []*unicode.RangeTable{unicode.Ps, unicode.Pe, unicode.Pi, unicode.Pf, unicode.Quotation_Mark}
+	lower            = []*unicode.RangeTable{unicode.Ll, unicode.Other_Lowercase}
+	upper            = []*unicode.RangeTable{unicode.Lu, unicode.Lt, unicode.Other_Uppercase}
+)
+
+/*
+classifySentence returns the Sentence_Break property of ch, derived from
+the tables of the unicode package
+*/
+func classifySentence(ch rune) sentenceClass {
+	switch {
+	case ch == '\r':
+		return sentenceCR
+
+	case ch == '\n':
+		return sentenceLF
+
+	case ch == 0x0085 || ch == 0x2028 || ch == 0x2029:
+		return sentenceSep
+
+	case ch == 0x200C || ch == 0x200D || unicode.IsOneOf(extend, ch):
+		return sentenceExtend
+
+	case unicode.Is(unicode.Cf, ch):
+		return sentenceFormat
+
+	case unicode.IsSpace(ch):
+		return sentenceSp
+
+	case aTerm[ch]:
+		return sentenceATerm
+
+	case sTerm[ch]:
+		return sentenceSTerm
+
+	case sContinue[ch]:
+		return sentenceSContinue
+
+	case unicode.IsOneOf(closePunctuation, ch):
+		return sentenceClose
+
+	case unicode.Is(unicode.Nd, ch):
+		return sentenceNumeric
+
+	case unicode.IsOneOf(lower, ch):
+		return sentenceLower
+
+	case unicode.IsOneOf(upper, ch):
+		return sentenceUpper
+
+	case unicode.IsOneOf(alphabetic, ch):
+		return sentenceOLetter
+	}
+
+	return sentenceOther
+}
+
+func (class sentenceClass) isParaSep() bool {
+	return class == sentenceSep || class == sentenceCR || class == sentenceLF
+}
+
+func (class sentenceClass) isSATerm() bool {
+	return class == sentenceATerm || class == sentenceSTerm
+}
+
+/*
+sentenceScanner walks text by characters, skipping the Extend and
+Format characters rule SB5 of UAX #29 attaches to the one before them
+*/
+type sentenceScanner struct {
+	text string
+	pos  int
+}
+
+/*
+peek returns the class of the character at pos and the position after
+it and the characters attached to it
+*/
+func (scanner sentenceScanner) peek(pos int) (sentenceClass, int) {
+	if pos >= len(scanner.text) {
+		return sentenceOther, pos
+	}
+
+	ch, size := utf8.DecodeRuneInString(scanner.text[pos:])
+	class := classifySentence(ch)
+	end := pos + size
+
+	if class.isParaSep() {
+		return class, end
+	}
+
+	for end < len(scanner.text) {
+		ch, size = utf8.DecodeRuneInString(scanner.text[end:])
+
+		if next := classifySentence(ch); next != sentenceExtend && next != sentenceFormat {
+			break
+		}
+
+		end += size
+	}
+
+	return class, end
+}
+
+/*
+sentenceLength returns the length in bytes of the sentence at the start
+of text, by the sentence boundary rules of UAX #29
+*/
+func sentenceLength(text string) int {
+	scanner := sentenceScanner{text: text}
+	previous := sentenceOther
+
+	for scanner.pos < len(text) {
+		class, end := scanner.peek(scanner.pos)
+
+		switch {
+		// SB3, SB4
+		case class == sentenceCR:
+			if end < len(text) && text[end] == '\n' {
+				end++
+			}
+
+			return end
+
+		case class.isParaSep():
+			return end
+
+		case class.isSATerm():
+			next, join := scanner.afterTerm(previous, class, end)
+			if !join {
+				return next
+			}
+
+			scanner.pos, previous = next, sentenceOther
+			continue
+		}
+
+		scanner.pos, previous = end, class
+	}
+
+	return len(text)
+}
+
+/*
+afterTerm applies rules SB6 to SB11 to the terminator of class term
+ending at pos, preceded by previous. It returns where the sentence ends
+and false if it ends after the terminator, or where to go on and true if
+it does not.
+*/
+func (scanner sentenceScanner) afterTerm(previous sentenceClass, term sentenceClass, pos int) (int, bool) {
+	next, _ := scanner.peek(pos)
+
+	// SB6, SB7
+	if term == sentenceATerm && next == sentenceNumeric {
+		return pos, true
+	}
+
+	if term == sentenceATerm && (previous == sentenceUpper || previous == sentenceLower) && next == sentenceUpper {
+		return pos, true
+	}
+
+	// The terminator takes the closing punctuation and spaces after it
+	for next == sentenceClose {
+		_, pos = scanner.peek(pos)
+		next, _ = scanner.peek(pos)
+	}
+
+	for next == sentenceSp {
+		_, pos = scanner.peek(pos)
+		next, _ = scanner.peek(pos)
+	}
+
+	// SB8
+	if term == sentenceATerm && scanner.lowerFollows(pos) {
+		return pos, true
+	}
+
+	// SB8a
+	if next == sentenceSContinue || next.isSATerm() {
+		return pos, true
+	}
+
+	// SB9 to SB11
+	if next == sentenceCR {
+		_, pos = scanner.peek(pos)
+
+		if pos < len(scanner.text) && scanner.text[pos] == '\n' {
+			pos++
+		}
+	} else if next.isParaSep() {
+		_, pos = scanner.peek(pos)
+	}
+
+	return pos, false
+}
+
+/*
+lowerFollows returns true if the first letter or terminator from pos on
+is a lower case letter, as rule SB8 asks
+*/
+func (scanner sentenceScanner) lowerFollows(pos int) bool {
+	for pos < len(scanner.text) {
+		class, end := scanner.peek(pos)
+
+		switch {
+		case class == sentenceLower:
+			return true
+
+		case class == sentenceOLetter || class == sentenceUpper || class.isParaSep() || class.isSATerm():
+			return false
+		}
+
+		pos = end
+	}
+
+	return false
+}
@@ -0,0 +1,381 @@
+package segment
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+wordClass is the Word_Break property of a character
+*/
+type wordClass int
+
+const (
+	wordOther wordClass = iota
+	wordCR
+	wordLF
+	wordNewline
+	wordExtend
+	wordZWJ
+	wordFormat
+	wordRegionalIndicator
+	wordKatakana
+	wordHebrewLetter
+	wordALetter
+	wordSingleQuote
+	wordDoubleQuote
+	wordMidNumLet
+	wordMidLetter
+	wordMidNum
+	wordNumeric
+	wordExtendNumLet
+	wordSpace
+	wordIdeograph
+	wordPictographic
+)
+
+var (
+	midLetter = map[rune]bool{
+		':': true, 0x00B7: true, 0x0387: true, 0x055F: true, 0x05F4: true,
+		0x2027: true, 0xFE13: true, 0xFE55: true, 0xFF1A: true,
+	}
+
+	midNum = map[rune]bool{
+		',': true, ';': true, 0x037E: true, 0x0589: true, 0x060C: true,
+		0x060D: true, 0x066C: true, 0x07F8: true, 0x2044: true, 0xFE10: true,
+		0xFE14: true, 0xFE50: true, 0xFE54: true, 0xFF0C: true, 0xFF1B: true,
+	}
+
+	midNumLet = map[rune]bool{
+		'.': true, 0x2018: true, 0x2019: true, 0x2024: true, 0xFE52: true,
+		0xFF07: true, 0xFF0E: true,
+	}
+
+	katakanaExtra = map[rune]bool{
+		0x3031: true, 0x3032: true, 0x3033: true, 0x3034: true, 0x3035: true,
+		0x309B: true, 0x309C: true, 0x30A0: true, 0x30FC: true, 0xFF70: true,
+	}
+
+	// Scripts written without spaces between words, whose letters
+	// UAX #29 leaves to dictionary based segmentation
+	complexScripts = []*unicode.RangeTable{unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar}
+
+	alphabetic = []*unicode.RangeTable{unicode.L, unicode.Nl, unicode.Other_Alphabetic}
+	extend     = []*unicode.RangeTable{unicode.Mn, unicode.Me, unicode.Mc, unicode.Other_Grapheme_Extend}
+)
+
+/*
+classifyWord returns the Word_Break property of ch, derived from the
+tables of the unicode package
+*/
+func classifyWord(ch rune) wordClass {
+	switch {
+	case ch == '\r':
+		return wordCR
+
+	case ch == '\n':
+		return wordLF
+
+	case ch == '\v' || ch == '\f' || ch == 0x0085 || ch == 0x2028 || ch == 0x2029:
+		return wordNewline
+
+	case ch == 0x200D:
+		return wordZWJ
+
+	case ch == 0x200C || ch >= 0x1F3FB && ch <= 0x1F3FF || unicode.IsOneOf(extend, ch):
+		return wordExtend
+
+	case ch >= 0x1F1E6 && ch <= 0x1F1FF:
+		return wordRegionalIndicator
+
+	case ch == 0x200B:
+		return wordOther
+
+	case unicode.Is(unicode.Cf, ch):
+		return wordFormat
+
+	case ch == '\'':
+		return wordSingleQuote
+
+	case ch == '"':
+		return wordDoubleQuote
+
+	case midNumLet[ch]:
+		return wordMidNumLet
+
+	case midLetter[ch]:
+		return wordMidLetter
+
+	case midNum[ch]:
+		return wordMidNum
+
+	case ch == 0x202F || unicode.Is(unicode.Pc, ch):
+		return wordExtendNumLet
+
+	case unicode.Is(unicode.Zs, ch):
+		if ch == 0x00A0 || ch == 0x2007 {
+			return wordOther
+		}
+
+		return wordSpace
+
+	case unicode.Is(unicode.Nd, ch):
+		return wordNumeric
+
+	case unicode.Is(unicode.Katakana, ch) || katakanaExtra[ch]:
+		return wordKatakana
+
+	case unicode.Is(unicode.Ideographic, ch) || unicode.Is(unicode.Hiragana, ch):
+		return wordIdeograph
+
+	case unicode.Is(unicode.Hebrew, ch) && unicode.IsLetter(ch):
+		return wordHebrewLetter
+
+	case unicode.IsOneOf(alphabetic, ch):
+		if unicode.IsOneOf(complexScripts, ch) {
+			return wordIdeograph
+		}
+
+		return wordALetter
+
+	case isPictographic(ch):
+		return wordPictographic
+	}
+
+	return wordOther
+}
+
+/*
+isPictographic approximates the Extended_Pictographic property, which
+the unicode package does not have, by the symbols of the emoji blocks
+*/
+func isPictographic(ch rune) bool {
+	if ch == 0x00A9 || ch == 0x00AE || ch == 0x203C || ch == 0x2049 || ch == 0x2122 {
+		return true
+	}
+
+	return (ch >= 0x2600 && ch <= 0x27BF || ch >= 0x1F000 && ch <= 0x1FAFF) && unicode.Is(unicode.So, ch)
+}
+
+func (class wordClass) isAHLetter() bool {
+	return class == wordALetter || class == wordHebrewLetter
+}
+
+func (class wordClass) isMidLetterQ() bool {
+	return class == wordMidLetter || class == wordMidNumLet || class == wordSingleQuote
+}
+
+func (class wordClass) isMidNumQ() bool {
+	return class == wordMidNum || class == wordMidNumLet || class == wordSingleQuote
+}
+
+func (class wordClass) isIgnorable() bool {
+	return class == wordExtend || class == wordFormat || class == wordZWJ
+}
+
+/*
+wordUnit is a character with the Extend, Format and ZWJ characters
+that follow it, which rule WB4 of UAX #29 treats as one
+*/
+type wordUnit struct {
+	class  wordClass
+	length int
+	zwj    bool
+}
+
+/*
+nextWordUnit returns the unit at the start of text, or a unit of length
+0 at the end of text
+*/
+func nextWordUnit(text string) wordUnit {
+	if text == "" {
+		return wordUnit{class: wordOther}
+	}
+
+	ch, size := utf8.DecodeRuneInString(text)
+	unit := wordUnit{class: classifyWord(ch), length: size, zwj: ch == 0x200D}
+
+	if unit.class == wordCR || unit.class == wordLF || unit.class == wordNewline {
+		return unit
+	}
+
+	for unit.length < len(text) {
+		ch, size = utf8.DecodeRuneInString(text[unit.length:])
+
+		class := classifyWord(ch)
+		if !class.isIgnorable() {
+			break
+		}
+
+		unit.length += size
+		unit.zwj = class == wordZWJ
+	}
+
+	return unit
+}
+
+/*
+wordKind describes what a word segment holds, which decides the type of
+its token
+*/
+type wordKind struct {
+	letters     bool
+	digits      bool
+	ideographs  bool
+	pictographs bool
+	spaces      bool
+	punctuation bool
+	symbols     bool
+}
+
+/*
+wordLength returns the length in bytes of the word segment at the start
+of text, by the word boundary rules of UAX #29, and what it holds
+*/
+func wordLength(text string) (int, wordKind) {
+	var kind wordKind
+
+	if text == "" {
+		return 0, kind
+	}
+
+	first := nextWordUnit(text)
+	kind.add(first.class, text)
+
+	if first.class == wordCR && len(text) > 1 && text[1] == '\n' {
+		return 2, kind
+	}
+
+	if first.class == wordCR || first.class == wordLF || first.class == wordNewline {
+		return first.length, kind
+	}
+
+	before, last := wordUnit{}, first
+	regionalIndicators := 0
+	pos := first.length
+
+	if first.class == wordRegionalIndicator {
+		regionalIndicators = 1
+	}
+
+	for pos < len(text) {
+		current := nextWordUnit(text[pos:])
+		next := nextWordUnit(text[pos+current.length:])
+
+		if !joinWords(before, last, current, next, regionalIndicators) {
+			break
+		}
+
+		if current.class == wordRegionalIndicator {
+			regionalIndicators++
+		} else {
+			regionalIndicators = 0
+		}
+
+		kind.add(current.class, text[pos:])
+		before, last = last, current
+		pos += current.length
+	}
+
+	return pos, kind
+}
+
+/*
+joinWords returns true if there is no word boundary between last and
+current, given the units before and after them
+*/
+func joinWords(before, last, current, next wordUnit, regionalIndicators int) bool {
+	l, c := last.class, current.class
+	b, n := before.class, next.class
+
+	switch {
+	// WB3a, WB3b
+	case c == wordCR || c == wordLF || c == wordNewline:
+		return false
+
+	// WB3c
+	case last.zwj && c == wordPictographic:
+		return true
+
+	// WB3d
+	case l == wordSpace && c == wordSpace:
+		return true
+
+	// WB5, WB6, WB7
+	case l.isAHLetter() && c.isAHLetter():
+		return true
+
+	case l.isAHLetter() && c.isMidLetterQ() && n.isAHLetter():
+		return true
+
+	case b.isAHLetter() && l.isMidLetterQ() && c.isAHLetter():
+		return true
+
+	// WB7a, WB7b, WB7c
+	case l == wordHebrewLetter && c == wordSingleQuote:
+		return true
+
+	case l == wordHebrewLetter && c == wordDoubleQuote && n == wordHebrewLetter:
+		return true
+
+	case b == wordHebrewLetter && l == wordDoubleQuote && c == wordHebrewLetter:
+		return true
+
+	// WB8, WB9, WB10, WB11, WB12
+	case (l == wordNumeric || l.isAHLetter()) && (c == wordNumeric || c.isAHLetter()):
+		return true
+
+	case b == wordNumeric && l.isMidNumQ() && c == wordNumeric:
+		return true
+
+	case l == wordNumeric && c.isMidNumQ() && n == wordNumeric:
+		return true
+
+	// WB13, WB13a, WB13b
+	case l == wordKatakana && c == wordKatakana:
+		return true
+
+	case (l.isAHLetter() || l == wordNumeric || l == wordKatakana || l == wordExtendNumLet) && c == wordExtendNumLet:
+		return true
+
+	case l == wordExtendNumLet && (c.isAHLetter() || c == wordNumeric || c == wordKatakana):
+		return true
+
+	// WB15, WB16
+	case l == wordRegionalIndicator && c == wordRegionalIndicator:
+		return regionalIndicators%2 == 1
+	}
+
+	return false
+}
+
+func (kind *wordKind) add(class wordClass, text string) {
+	switch class {
+	case wordALetter, wordHebrewLetter, wordKatakana:
+		kind.letters = true
+
+	case wordNumeric:
+		kind.digits = true
+
+	case wordIdeograph:
+		kind.ideographs = true
+
+	case wordPictographic, wordRegionalIndicator:
+		kind.pictographs = true
+
+	case wordSpace, wordCR, wordLF, wordNewline:
+		kind.spaces = true
+
+	default:
+		switch ch, _ := utf8.DecodeRuneInString(text); {
+		case unicode.IsSpace(ch):
+			kind.spaces = true
+
+		case unicode.IsPunct(ch):
+			kind.punctuation = true
+
+		default:
+			kind.symbols = true
+		}
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Package textscanner wraps a lexer in the API of the standard library's
+text/scanner, so that code written against text/scanner can move to a
+lexer of this package one call site at a time. Scan returns the same
+token classes, such as scanner.Ident and scanner.Int, and the embedded
+scanner.Position and TokenText work as they do there, with one
+difference: Column counts bytes from the start of the line, as the
+lexer and go/token do, where text/scanner counts characters. The two
+agree on lines that are plain ASCII.
+
+	s := textscanner.New(golike.NewLexer(name, input), textscanner.Classes{
+		golike.TOKEN_IDENTIFIER: scanner.Ident,
+		golike.TOKEN_KEYWORD:    scanner.Ident,
+		golike.TOKEN_INT:        scanner.Int,
+		golike.TOKEN_FLOAT:      scanner.Float,
+		golike.TOKEN_STRING:     scanner.String,
+		golike.TOKEN_RAW_STRING: scanner.RawString,
+		golike.TOKEN_RUNE:       scanner.Char,
+	}, golike.TOKEN_COMMENT)
+
+	for tok := s.Scan(); tok != scanner.EOF; tok = s.Scan() {
+		fmt.Println(s.Position, s.TokenText())
+	}
+*/
+package textscanner
+
+import (
+	"fmt"
+	"os"
+	"text/scanner"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Classes maps token types to the text/scanner token classes Scan returns
+for them
+*/
+type Classes map[lexer.TokenType]rune
+
+/*
+Scanner reads the tokens of a lexer as text/scanner.Scanner reads
+characters. Position is the position of the token most recently
+returned by Scan, and ErrorCount and Error are as in text/scanner: Error
+is called for every error of the lexer, and for tokens with no class,
+and prints to standard error when nil.
+*/
+type Scanner struct {
+	scanner.Position
+
+	Error      func(s *Scanner, message string)
+	ErrorCount int
+
+	stream  *lexer.TokenStream
+	classes Classes
+	token   lexer.Token
+}
+
+/*
+New creates a scanner over the tokens of l, leaving out tokens of the
+types in skip, as text/scanner leaves out whitespace. Scan returns the
+class in classes for a token's type. A token whose type has no class
+and whose text is a single character is returned as that character, as
+text/scanner returns operators and punctuation. Tokens text/scanner has
+no class for, such as multi-character operators, can be given classes
+of their own below scanner.Comment.
+*/
+func New(l *lexer.Lexer, classes Classes, skip ...lexer.TokenType) *Scanner {
+	return &Scanner{
+		stream:  lexer.NewTokenStream(l, skip...),
+		classes: classes,
+	}
+}
+
+/*
+Scan reads the next token and returns its class, or scanner.EOF at the
+end of input. Error tokens and tokens with no class are passed to Error
+and skipped.
+*/
+func (s *Scanner) Scan() rune {
+	for {
+		s.token = s.stream.Next()
+		s.Position = position(s.token.Span.Start)
+
+		if class, ok := s.class(s.token); ok {
+			return class
+		}
+
+		if s.token.Type == lexer.TOKEN_ERROR {
+			s.error(s.token.Text)
+		} else {
+			s.error(fmt.Sprintf("no scanner class for %q", s.token.Text))
+		}
+	}
+}
+
+/*
+Peek returns the class of the next token without reading it. Unlike
+text/scanner, where Peek looks at the next character, it looks at the
+next whole token.
+*/
+func (s *Scanner) Peek() rune {
+	for n := 1; ; n++ {
+		if class, ok := s.class(s.stream.LookAhead(n)); ok {
+			return class
+		}
+	}
+}
+
+/*
+TokenText returns the text of the token most recently returned by Scan
+*/
+func (s *Scanner) TokenText() string {
+	return s.token.Text
+}
+
+/*
+Token returns the token most recently returned by Scan, for code that
+has moved on to using tokens directly
+*/
+func (s *Scanner) Token() lexer.Token {
+	return s.token
+}
+
+/*
+Pos returns the position just after the token most recently returned by
+Scan
+*/
+func (s *Scanner) Pos() scanner.Position {
+	return position(s.token.Span.End)
+}
+
+/*
+class returns the class of token, or false if it has none
+*/
+func (s *Scanner) class(token lexer.Token) (rune, bool) {
+	switch token.Type {
+	case lexer.TOKEN_EOF:
+		return scanner.EOF, true
+
+	case lexer.TOKEN_ERROR:
+		return 0, false
+	}
+
+	if class, ok := s.classes[token.Type]; ok {
+		return class, true
+	}
+
+	if ch, width := utf8.DecodeRuneInString(token.Text); width > 0 && width == len(token.Text) {
+		return ch, true
+	}
+
+	return 0, false
+}
+
+func (s *Scanner) error(message string) {
+	s.ErrorCount++
+
+	if s.Error != nil {
+		s.Error(s, message)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: %s\n", s.Position, message)
+}
+
+/*
+position converts a position to text/scanner's form. The column is
+kept as a byte count, as the text of the line is not at hand for a
+lexer reading from a stream.
+*/
+func position(position lexer.Position) scanner.Position {
+	return scanner.Position{
+		Filename: position.Filename,
+		Offset:   position.Offset,
+		Line:     position.Line,
+		Column:   position.Column,
+	}
+}
@@ -0,0 +1,88 @@
+package textscanner
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"text/scanner"
+
+	"github.com/adampresley/lexer/presets/golike"
+)
+
+var classes = Classes{
+	golike.TOKEN_IDENTIFIER: scanner.Ident,
+	golike.TOKEN_INT:        scanner.Int,
+	golike.TOKEN_FLOAT:      scanner.Float,
+	golike.TOKEN_STRING:     scanner.String,
+	golike.TOKEN_RAW_STRING: scanner.RawString,
+}
+
+func TestScan(t *testing.T) {
+	// Columns count bytes, so the é before each token after it adds two
+	input := "x := \"é\" + 1.5 // c\n\tyé <<= `r` @"
+	want := `test:1:1 Ident "x"
+test:1:6 String "\"é\""
+test:1:11 "+" "+"
+test:1:13 Float "1.5"
+test:2:2 Ident "yé"
+test:2:10 RawString "` + "`r`" + `"
+test:2:15 EOF ""
+`
+	wantErrors := `test:1:3: no scanner class for ":="
+test:2:6: no scanner class for "<<="
+test:2:14: unexpected character '@'
+`
+
+	var errors strings.Builder
+	s := New(golike.NewLexer("test", input), classes, golike.TOKEN_COMMENT)
+	s.Error = func(s *Scanner, message string) {
+		fmt.Fprintf(&errors, "%s: %s\n", s.Position, message)
+	}
+
+	var got strings.Builder
+	for {
+		tok := s.Scan()
+		fmt.Fprintf(&got, "%s %s %q\n", s.Position, scanner.TokenString(tok), s.TokenText())
+
+		if tok == scanner.EOF {
+			break
+		}
+	}
+
+	if got.String() != want {
+		t.Errorf("scanning %q:\ngot:\n%s\nwant:\n%s", input, got.String(), want)
+	}
+
+	if errors.String() != wantErrors || s.ErrorCount != 3 {
+		t.Errorf("scanning %q: got %d errors:\n%s\nwant:\n%s", input, s.ErrorCount, errors.String(), wantErrors)
+	}
+}
+
+func TestPeekAndPos(t *testing.T) {
+	input := "a := b"
+	s := New(golike.NewLexer("test", input), classes)
+	s.Error = func(*Scanner, string) {}
+
+	if got := s.Peek(); got != scanner.Ident {
+		t.Errorf("Peek before Scan: got %s, want Ident", scanner.TokenString(got))
+	}
+
+	s.Scan()
+
+	// Peek skips the := as Scan would
+	if got := s.Peek(); got != scanner.Ident {
+		t.Errorf("Peek after %q: got %s, want Ident", s.TokenText(), scanner.TokenString(got))
+	}
+
+	if got := s.Pos().String(); got != "test:1:2" {
+		t.Errorf("Pos after %q: got %s, want test:1:2", s.TokenText(), got)
+	}
+
+	if got := s.Scan(); got != scanner.Ident || s.TokenText() != "b" || s.Token().Type != golike.TOKEN_IDENTIFIER {
+		t.Errorf("second Scan: got %s %q, want Ident \"b\"", scanner.TokenString(got), s.TokenText())
+	}
+
+	if s.ErrorCount != 1 {
+		t.Errorf("got %d errors, want 1", s.ErrorCount)
+	}
+}
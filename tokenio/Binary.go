@@ -0,0 +1,476 @@
+package tokenio
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+var (
+	/*
+		ErrInvalidRecording is returned when reading data that is not a
+		token stream written by a BinaryWriter
+	*/
+	ErrInvalidRecording = errors.New("tokenio: invalid token recording")
+
+	/*
+		ErrSourceChanged is returned when a recording is read against
+		source that differs from the source it was recorded from
+	*/
+	ErrSourceChanged = errors.New("tokenio: source does not match recording")
+)
+
+var binaryMagic = [5]byte{'L', 'X', 'T', 'S', 1}
+
+/*
+Every record starts with a byte holding its kind in the low two bits.
+Token records keep their flags in the remaining bits.
+*/
+const (
+	recordEnd byte = iota
+	recordToken
+	recordDiagnostic
+
+	recordKindMask byte = 3
+)
+
+const (
+	flagText byte = 4 << iota
+	flagValue
+	flagStartFilename
+	flagEndFilename
+	flagSingleLine
+)
+
+/*
+BinaryWriter records a complete token stream in a compact binary form
+that can be replayed later without lexing again. The recording starts
+with a SHA-256 hash of the source, and token text is only stored when
+it differs from the source, so a typical token takes six bytes.
+Transformed values are stored in their string form.
+*/
+type BinaryWriter struct {
+	w      *bufio.Writer
+	source string
+	buf    []byte
+
+	previous lexer.Position
+	filename string
+}
+
+/*
+NewBinaryWriter creates a writer recording tokens lexed from source
+under the lexer name, and writes the recording header
+*/
+func NewBinaryWriter(w io.Writer, name string, source string) (*BinaryWriter, error) {
+	writer := &BinaryWriter{
+		w:      bufio.NewWriter(w),
+		source: source,
+	}
+
+	hash := sha256.Sum256([]byte(source))
+
+	writer.buf = append(writer.buf, binaryMagic[:]...)
+	writer.buf = append(writer.buf, hash[:]...)
+	writer.buf = appendBytes(writer.buf, name)
+
+	_, err := writer.w.Write(writer.buf)
+	return writer, err
+}
+
+/*
+Write records a single token. Tokens must be written in the order the
+lexer produced them.
+*/
+func (writer *BinaryWriter) Write(token lexer.Token) error {
+	var flags byte
+	start, end := token.Span.Start, token.Span.End
+
+	if token.Text != writer.sourceText(start.Offset, end.Offset) {
+		flags |= flagText
+	}
+
+	if token.Value != nil {
+		flags |= flagValue
+	}
+
+	if start.Filename != writer.filename {
+		flags |= flagStartFilename
+	}
+
+	if end.Filename != start.Filename {
+		flags |= flagEndFilename
+	}
+
+	if end.Line == start.Line && end.Column-start.Column == end.Offset-start.Offset {
+		flags |= flagSingleLine
+	}
+
+	buf := append(writer.buf[:0], recordToken|flags)
+	buf = binary.AppendUvarint(buf, zigzag(int64(token.Type)))
+	buf = writer.appendSpan(buf, token.Span, flags&flagSingleLine != 0)
+
+	if flags&flagStartFilename != 0 {
+		buf = appendBytes(buf, start.Filename)
+	}
+
+	if flags&flagEndFilename != 0 {
+		buf = appendBytes(buf, end.Filename)
+	}
+
+	if flags&flagText != 0 {
+		buf = appendBytes(buf, token.Text)
+	}
+
+	if flags&flagValue != 0 {
+		buf = appendBytes(buf, csvValue(token))
+	}
+
+	writer.buf = buf
+	writer.filename = end.Filename
+
+	_, err := writer.w.Write(buf)
+	return err
+}
+
+/*
+WriteDiagnostic records a diagnostic. It is replayed just before the
+first token that starts at or after the diagnostic.
+*/
+func (writer *BinaryWriter) WriteDiagnostic(diagnostic lexer.LexError) error {
+	buf := append(writer.buf[:0], recordDiagnostic)
+	buf = appendBytes(buf, diagnostic.Message)
+	buf = binary.AppendUvarint(buf, uint64(diagnostic.Severity))
+	buf = appendBytes(buf, diagnostic.Code)
+	buf = appendBytes(buf, diagnostic.Span.Start.Filename)
+	buf = appendBytes(buf, diagnostic.Span.End.Filename)
+
+	// Diagnostic positions are written absolutely so they do not
+	// disturb the deltas between tokens
+	for _, value := range [...]int{
+		diagnostic.Span.Start.Offset, diagnostic.Span.Start.Line, diagnostic.Span.Start.Column,
+		diagnostic.Span.End.Offset, diagnostic.Span.End.Line, diagnostic.Span.End.Column,
+	} {
+		buf = binary.AppendUvarint(buf, zigzag(int64(value)))
+	}
+
+	writer.buf = buf
+
+	_, err := writer.w.Write(buf)
+	return err
+}
+
+/*
+Close ends the recording and flushes it. It does not close the
+underlying writer.
+*/
+func (writer *BinaryWriter) Close() error {
+	if err := writer.w.WriteByte(recordEnd); err != nil {
+		return err
+	}
+
+	return writer.w.Flush()
+}
+
+func (writer *BinaryWriter) sourceText(start, end int) string {
+	if start < 0 || start > end || end > len(writer.source) {
+		return ""
+	}
+
+	return writer.source[start:end]
+}
+
+/*
+appendSpan writes the span as deltas from the end of the previous
+token, which keeps almost every number to a single byte. The start
+column is relative to the previous column when the line is unchanged,
+and single line spans omit their end line and column entirely.
+*/
+func (writer *BinaryWriter) appendSpan(buf []byte, span lexer.Span, singleLine bool) []byte {
+	start, end := span.Start, span.End
+	column := start.Column
+
+	if start.Line == writer.previous.Line {
+		column -= writer.previous.Column
+	}
+
+	buf = binary.AppendUvarint(buf, zigzag(int64(start.Offset-writer.previous.Offset)))
+	buf = binary.AppendUvarint(buf, zigzag(int64(start.Line-writer.previous.Line)))
+	buf = binary.AppendUvarint(buf, zigzag(int64(column)))
+	buf = binary.AppendUvarint(buf, zigzag(int64(end.Offset-start.Offset)))
+
+	if !singleLine {
+		buf = binary.AppendUvarint(buf, zigzag(int64(end.Line-start.Line)))
+		buf = binary.AppendUvarint(buf, zigzag(int64(end.Column)))
+	}
+
+	writer.previous = end
+	return buf
+}
+
+/*
+WriteBinary records tokens and diagnostics lexed from source to w
+*/
+func WriteBinary(w io.Writer, name string, source string, tokens []lexer.Token, diagnostics []lexer.LexError) error {
+	writer, err := NewBinaryWriter(w, name, source)
+
+	if err != nil {
+		return err
+	}
+
+	for _, diagnostic := range diagnostics {
+		if err = writer.WriteDiagnostic(diagnostic); err != nil {
+			return err
+		}
+	}
+
+	for _, token := range tokens {
+		if err = writer.Write(token); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+/*
+Recording is a token stream read back from its binary form
+*/
+type Recording struct {
+	Name        string
+	SourceHash  [sha256.Size]byte
+	Tokens      []lexer.Token
+	Diagnostics []lexer.LexError
+}
+
+/*
+ReadRecording reads a recording written by a BinaryWriter. Token text
+that was not stored is taken from source, which must be the source the
+recording was made from; ErrSourceChanged is returned otherwise.
+*/
+func ReadRecording(r io.Reader, source string) (*Recording, error) {
+	reader := bufio.NewReader(r)
+	recording := &Recording{}
+
+	var magic [len(binaryMagic)]byte
+
+	if _, err := io.ReadFull(reader, magic[:]); err != nil || magic != binaryMagic {
+		return nil, ErrInvalidRecording
+	}
+
+	if _, err := io.ReadFull(reader, recording.SourceHash[:]); err != nil {
+		return nil, ErrInvalidRecording
+	}
+
+	if recording.SourceHash != sha256.Sum256([]byte(source)) {
+		return nil, ErrSourceChanged
+	}
+
+	decoder := &binaryDecoder{r: reader, source: source}
+	recording.Name = decoder.string()
+
+	for decoder.err == nil {
+		header, err := reader.ReadByte()
+
+		if err != nil {
+			return nil, ErrInvalidRecording
+		}
+
+		switch kind := header & recordKindMask; kind {
+		case recordEnd:
+			return recording, nil
+
+		case recordToken:
+			recording.Tokens = append(recording.Tokens, decoder.token(header&^recordKindMask))
+
+		case recordDiagnostic:
+			recording.Diagnostics = append(recording.Diagnostics, decoder.diagnostic())
+
+		default:
+			return nil, fmt.Errorf("%w: unknown record kind %d", ErrInvalidRecording, kind)
+		}
+	}
+
+	return nil, decoder.err
+}
+
+/*
+Lexer returns a lexer that replays the recording as if it were lexing
+source live. Its tokens arrive through Run, RunWith and the other run
+methods as usual, and diagnostics are passed to Report, so error
+handlers and metrics see them, just before the first token at or
+after their position.
+*/
+func (recording *Recording) Lexer(source string, options ...lexer.Option) *lexer.Lexer {
+	tokens, diagnostics := recording.Tokens, recording.Diagnostics
+
+	replay := func(l *lexer.Lexer) lexer.LexFn {
+		for _, token := range tokens {
+			for len(diagnostics) > 0 && diagnostics[0].Span.Start.Offset <= token.Span.Start.Offset {
+				l.Report(diagnostics[0])
+				diagnostics = diagnostics[1:]
+			}
+
+			if end := token.Span.End.Offset; end > l.Pos && end <= len(l.Input) {
+				l.Start, l.Pos = end, end
+			}
+
+			l.EmitToken(token)
+		}
+
+		for _, diagnostic := range diagnostics {
+			l.Report(diagnostic)
+		}
+
+		return nil
+	}
+
+	return lexer.NewLexer(recording.Name, source, replay, options...)
+}
+
+/*
+Replay reads a recording and returns a lexer replaying it against
+source
+*/
+func Replay(r io.Reader, source string, options ...lexer.Option) (*lexer.Lexer, error) {
+	recording, err := ReadRecording(r, source)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recording.Lexer(source, options...), nil
+}
+
+/*
+binaryDecoder reads the fields of a recording, remembering the first
+error so callers can check once per record
+*/
+type binaryDecoder struct {
+	r      *bufio.Reader
+	source string
+	err    error
+
+	previous lexer.Position
+	filename string
+}
+
+func (decoder *binaryDecoder) token(flags byte) lexer.Token {
+	token := lexer.Token{
+		Type: lexer.TokenType(unzigzag(decoder.uvarint())),
+	}
+
+	start := lexer.Position{
+		Offset: decoder.previous.Offset + decoder.int(),
+		Line:   decoder.previous.Line + decoder.int(),
+		Column: decoder.int(),
+	}
+
+	if start.Line == decoder.previous.Line {
+		start.Column += decoder.previous.Column
+	}
+
+	length := decoder.int()
+	end := lexer.Position{
+		Offset: start.Offset + length,
+		Line:   start.Line,
+		Column: start.Column + length,
+	}
+
+	if flags&flagSingleLine == 0 {
+		end.Line += decoder.int()
+		end.Column = decoder.int()
+	}
+
+	start.Filename = decoder.filename
+
+	if flags&flagStartFilename != 0 {
+		start.Filename = decoder.string()
+	}
+
+	end.Filename = start.Filename
+
+	if flags&flagEndFilename != 0 {
+		end.Filename = decoder.string()
+	}
+
+	if flags&flagText != 0 {
+		token.Text = decoder.string()
+	} else if start.Offset >= 0 && start.Offset <= end.Offset && end.Offset <= len(decoder.source) {
+		token.Text = decoder.source[start.Offset:end.Offset]
+	} else if decoder.err == nil {
+		decoder.err = ErrInvalidRecording
+	}
+
+	if flags&flagValue != 0 {
+		token.Value = decoder.string()
+	}
+
+	token.Span = lexer.Span{Start: start, End: end}
+	decoder.previous = end
+	decoder.filename = end.Filename
+
+	return token
+}
+
+func (decoder *binaryDecoder) diagnostic() lexer.LexError {
+	diagnostic := lexer.LexError{
+		Message:  decoder.string(),
+		Severity: lexer.Severity(decoder.uvarint()),
+		Code:     decoder.string(),
+	}
+
+	diagnostic.Span.Start.Filename = decoder.string()
+	diagnostic.Span.End.Filename = decoder.string()
+
+	for _, field := range [...]*int{
+		&diagnostic.Span.Start.Offset, &diagnostic.Span.Start.Line, &diagnostic.Span.Start.Column,
+		&diagnostic.Span.End.Offset, &diagnostic.Span.End.Line, &diagnostic.Span.End.Column,
+	} {
+		*field = decoder.int()
+	}
+
+	return diagnostic
+}
+
+func (decoder *binaryDecoder) uvarint() uint64 {
+	if decoder.err != nil {
+		return 0
+	}
+
+	value, err := binary.ReadUvarint(decoder.r)
+
+	if err != nil {
+		decoder.err = ErrInvalidRecording
+	}
+
+	return value
+}
+
+func (decoder *binaryDecoder) int() int {
+	return int(unzigzag(decoder.uvarint()))
+}
+
+func (decoder *binaryDecoder) string() string {
+	length := decoder.uvarint()
+
+	if decoder.err != nil || length == 0 {
+		return ""
+	}
+
+	// Copying rather than allocating length bytes up front keeps a
+	// corrupt length from causing a huge allocation
+	var data bytes.Buffer
+
+	if _, err := io.CopyN(&data, decoder.r, int64(length)); err != nil {
+		decoder.err = ErrInvalidRecording
+	}
+
+	return data.String()
+}
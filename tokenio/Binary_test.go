@@ -0,0 +1,129 @@
+package tokenio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/adampresley/lexer"
+)
+
+func TestBinaryTokenSize(t *testing.T) {
+	source := "ab cd"
+	tokens := []lexer.Token{
+		{Type: 1, Text: "ab", Span: lexer.Span{Start: lexer.Position{Offset: 0, Line: 1, Column: 1}, End: lexer.Position{Offset: 2, Line: 1, Column: 3}}},
+		{Type: 1, Text: "cd", Span: lexer.Span{Start: lexer.Position{Offset: 3, Line: 1, Column: 4}, End: lexer.Position{Offset: 5, Line: 1, Column: 6}}},
+		{Type: 1, Text: "CD", Span: lexer.Span{Start: lexer.Position{Offset: 3, Line: 1, Column: 4}, End: lexer.Position{Offset: 5, Line: 1, Column: 6}}},
+	}
+
+	// magic, source hash, length prefixed name and end record
+	header := len(binaryMagic) + sha256.Size + 2 + 1
+
+	tests := []struct {
+		tokens []lexer.Token
+		want   int
+	}{
+		{nil, header},
+		{tokens[:1], header + 6},
+		{tokens[:2], header + 12},
+		{[]lexer.Token{tokens[0], tokens[2]}, header + 6 + 6 + 3},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := WriteBinary(&buf, "t", source, test.tokens, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.Len() != test.want {
+			t.Errorf("recording %d tokens: got %d bytes, want %d", len(test.tokens), buf.Len(), test.want)
+		}
+
+		recording, err := ReadRecording(&buf, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(recording.Tokens, test.tokens) {
+			t.Errorf("recording %d tokens: read %#v", len(test.tokens), recording.Tokens)
+		}
+	}
+}
+
+func TestReadRecordingInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, "t", "ab", stringTokens, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	corrupt := append([]byte{'X'}, data[1:]...)
+
+	tests := []struct {
+		data []byte
+		want error
+	}{
+		{corrupt, ErrInvalidRecording},
+		{data[:len(data)-1], ErrInvalidRecording},
+		{data[:10], ErrInvalidRecording},
+	}
+
+	for index, test := range tests {
+		if _, err := ReadRecording(bytes.NewReader(test.data), "ab"); !errors.Is(err, test.want) {
+			t.Errorf("test %d: got %v, want %v", index, err, test.want)
+		}
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	tokens, diagnostics := sample(t)
+
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, "sample.go", sampleSource, tokens, diagnostics); err != nil {
+		t.Fatal(err)
+	}
+
+	recording, err := ReadRecording(bytes.NewReader(buf.Bytes()), sampleSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if recording.Name != "sample.go" {
+		t.Errorf("got name %q, want sample.go", recording.Name)
+	}
+
+	compareTokens(t, recording.Tokens, stringValues(tokens))
+
+	if !reflect.DeepEqual(recording.Diagnostics, diagnostics) {
+		t.Errorf("got diagnostics %v, want %v", recording.Diagnostics, diagnostics)
+	}
+
+	if _, err := ReadRecording(bytes.NewReader(buf.Bytes()), sampleSource+" "); !errors.Is(err, ErrSourceChanged) {
+		t.Errorf("reading against changed source: got %v, want %v", err, ErrSourceChanged)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	tokens, diagnostics := sample(t)
+
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, "sample.go", sampleSource, tokens, diagnostics); err != nil {
+		t.Fatal(err)
+	}
+
+	var reported []lexer.LexError
+	l, err := Replay(&buf, sampleSource, lexer.WithErrorHandler(func(diagnostic lexer.LexError) {
+		reported = append(reported, diagnostic)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compareTokens(t, l.Collect(), stringValues(tokens))
+
+	if !reflect.DeepEqual(reported, diagnostics) {
+		t.Errorf("got reported diagnostics %v, want %v", reported, diagnostics)
+	}
+}
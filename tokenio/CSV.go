@@ -0,0 +1,130 @@
+package tokenio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/adampresley/lexer"
+)
+
+var csvHeader = []string{"offset", "line", "column", "type", "value"}
+
+/*
+CSVWriter writes tokens as rows of offset, line, column, type and value,
+for spreadsheets and diffable golden files. The value column holds the
+transformed value when there is one, otherwise the token's text.
+*/
+type CSVWriter struct {
+	writer        *csv.Writer
+	names         lexer.TokenNames
+	headerWritten bool
+	record        []string
+}
+
+/*
+NewCSVWriter creates a writer producing comma separated values
+*/
+func NewCSVWriter(w io.Writer, names lexer.TokenNames) *CSVWriter {
+	return newCSVWriter(w, names, ',')
+}
+
+/*
+NewTSVWriter creates a writer producing tab separated values. Values
+containing tabs, quotes or newlines are quoted as in CSV.
+*/
+func NewTSVWriter(w io.Writer, names lexer.TokenNames) *CSVWriter {
+	return newCSVWriter(w, names, '\t')
+}
+
+func newCSVWriter(w io.Writer, names lexer.TokenNames, comma rune) *CSVWriter {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	return &CSVWriter{
+		writer: writer,
+		names:  names,
+		record: make([]string, len(csvHeader)),
+	}
+}
+
+/*
+Write writes a single token as one row, preceded by the header row on
+the first call. Rows are buffered until Flush is called.
+*/
+func (writer *CSVWriter) Write(token lexer.Token) error {
+	if err := writer.WriteHeader(); err != nil {
+		return err
+	}
+
+	writer.record[0] = strconv.Itoa(token.Span.Start.Offset)
+	writer.record[1] = strconv.Itoa(token.Span.Start.Line)
+	writer.record[2] = strconv.Itoa(token.Span.Start.Column)
+	writer.record[3] = writer.names.Name(token.Type)
+	writer.record[4] = csvValue(token)
+
+	return writer.writer.Write(writer.record)
+}
+
+/*
+WriteHeader writes the header row if it has not been written yet. It
+only needs to be called directly to produce a header for an empty
+stream.
+*/
+func (writer *CSVWriter) WriteHeader() error {
+	if writer.headerWritten {
+		return nil
+	}
+
+	writer.headerWritten = true
+	return writer.writer.Write(csvHeader)
+}
+
+/*
+Flush writes any buffered rows and returns the first error encountered
+*/
+func (writer *CSVWriter) Flush() error {
+	writer.writer.Flush()
+	return writer.writer.Error()
+}
+
+/*
+WriteCSV writes tokens to w as comma separated values with a header row
+*/
+func WriteCSV(w io.Writer, names lexer.TokenNames, tokens ...lexer.Token) error {
+	return writeAll(NewCSVWriter(w, names), tokens)
+}
+
+/*
+WriteTSV writes tokens to w as tab separated values with a header row
+*/
+func WriteTSV(w io.Writer, names lexer.TokenNames, tokens ...lexer.Token) error {
+	return writeAll(NewTSVWriter(w, names), tokens)
+}
+
+func writeAll(writer *CSVWriter, tokens []lexer.Token) error {
+	if err := writer.WriteHeader(); err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if err := writer.Write(token); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+func csvValue(token lexer.Token) string {
+	if token.Value == nil {
+		return token.Text
+	}
+
+	if value, ok := token.Value.(string); ok {
+		return value
+	}
+
+	return fmt.Sprint(token.Value)
+}
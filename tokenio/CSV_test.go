@@ -0,0 +1,87 @@
+package tokenio
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/adampresley/lexer/presets/golike"
+)
+
+func TestWriteCSV(t *testing.T) {
+	tests := []struct {
+		write func(buf *bytes.Buffer) error
+		want  string
+	}{
+		{
+			func(buf *bytes.Buffer) error { return WriteCSV(buf, golike.Names, stringTokens...) },
+			"offset,line,column,type,value\n3,2,1,STRING,<a>\t\n10,2,8,EOF,\n",
+		},
+		{
+			func(buf *bytes.Buffer) error { return WriteTSV(buf, golike.Names, stringTokens...) },
+			"offset\tline\tcolumn\ttype\tvalue\n3\t2\t1\tSTRING\t\"<a>\t\"\n10\t2\t8\tEOF\t\n",
+		},
+		{
+			func(buf *bytes.Buffer) error { return WriteCSV(buf, golike.Names) },
+			"offset,line,column,type,value\n",
+		},
+	}
+
+	for index, test := range tests {
+		var buf bytes.Buffer
+		if err := test.write(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); got != test.want {
+			t.Errorf("test %d: got %q, want %q", index, got, test.want)
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	tokens, _ := sample(t)
+
+	for _, comma := range []rune{',', '\t'} {
+		var buf bytes.Buffer
+		var err error
+
+		if comma == ',' {
+			err = WriteCSV(&buf, golike.Names, tokens...)
+		} else {
+			err = WriteTSV(&buf, golike.Names, tokens...)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reader := csv.NewReader(&buf)
+		reader.Comma = comma
+
+		records, err := reader.ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(records) != len(tokens)+1 || !reflect.DeepEqual(records[0], csvHeader) {
+			t.Fatalf("got %d records starting with %q, want the header and %d tokens", len(records), records[0], len(tokens))
+		}
+
+		for index, token := range tokens {
+			want := []string{
+				strconv.Itoa(token.Span.Start.Offset),
+				strconv.Itoa(token.Span.Start.Line),
+				strconv.Itoa(token.Span.Start.Column),
+				golike.Names.Name(token.Type),
+				csvValue(token),
+			}
+
+			if !reflect.DeepEqual(records[index+1], want) {
+				t.Errorf("separated by %q, token %d: got %q, want %q", comma, index, records[index+1], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package tokenio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+A ChangeKind describes how a token differs between two token streams
+*/
+type ChangeKind int
+
+const (
+	CHANGE_INSERT ChangeKind = iota + 1
+	CHANGE_DELETE
+	CHANGE_MODIFY
+)
+
+func (kind ChangeKind) String() string {
+	switch kind {
+	case CHANGE_INSERT:
+		return "insert"
+
+	case CHANGE_DELETE:
+		return "delete"
+
+	case CHANGE_MODIFY:
+		return "modify"
+	}
+
+	return "unknown"
+}
+
+/*
+TokenChange is a single difference between two token streams. OldIndex
+is -1 for insertions and NewIndex is -1 for deletions, with Old or New
+left empty to match. A modification pairs a deleted token with the
+token inserted in its place.
+*/
+type TokenChange struct {
+	Kind     ChangeKind
+	OldIndex int
+	NewIndex int
+	Old      lexer.Token
+	New      lexer.Token
+}
+
+/*
+TypeChanged returns true if a modification changed the token type
+*/
+func (change TokenChange) TypeChanged() bool {
+	return change.Kind == CHANGE_MODIFY && change.Old.Type != change.New.Type
+}
+
+/*
+ValueChanged returns true if a modification changed the token's text or
+value
+*/
+func (change TokenChange) ValueChanged() bool {
+	return change.Kind == CHANGE_MODIFY && (change.Old.Text != change.New.Text || csvValue(change.Old) != csvValue(change.New))
+}
+
+/*
+DiffTokens returns the changes that turn the old token stream into the
+new one. Tokens are compared by type, text and value; spans are ignored
+so that an edit early in the input does not mark every later token as
+changed. The result is a shortest edit script, found with Myers'
+algorithm, in stream order.
+*/
+func DiffTokens(old, new []lexer.Token) []TokenChange {
+	equal := func(i, j int) bool {
+		return old[i].Type == new[j].Type && old[i].Text == new[j].Text && csvValue(old[i]) == csvValue(new[j])
+	}
+
+	// Common prefixes and suffixes are trimmed first, which makes the
+	// usual case of a few local edits cheap
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && equal(prefix, prefix) {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix && equal(len(old)-1-suffix, len(new)-1-suffix) {
+		suffix++
+	}
+
+	edits := editScript(len(old)-prefix-suffix, len(new)-prefix-suffix, func(i, j int) bool {
+		return equal(prefix+i, prefix+j)
+	})
+
+	var result []TokenChange
+	var deleted, inserted []int
+
+	flush := func() {
+		paired := len(deleted)
+		if len(inserted) < paired {
+			paired = len(inserted)
+		}
+
+		for index := 0; index < paired; index++ {
+			result = append(result, TokenChange{Kind: CHANGE_MODIFY, OldIndex: deleted[index], NewIndex: inserted[index], Old: old[deleted[index]], New: new[inserted[index]]})
+		}
+
+		for _, index := range deleted[paired:] {
+			result = append(result, TokenChange{Kind: CHANGE_DELETE, OldIndex: index, NewIndex: -1, Old: old[index]})
+		}
+
+		for _, index := range inserted[paired:] {
+			result = append(result, TokenChange{Kind: CHANGE_INSERT, OldIndex: -1, NewIndex: index, New: new[index]})
+		}
+
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+
+	for _, edit := range edits {
+		switch edit.kind {
+		case editKeep:
+			flush()
+
+		case editDelete:
+			deleted = append(deleted, prefix+edit.index)
+
+		case editInsert:
+			inserted = append(inserted, prefix+edit.index)
+		}
+	}
+
+	flush()
+	return result
+}
+
+/*
+WriteDiff writes changes to w in a readable form, one line per change,
+for golden test failures and audit logs. Modified tokens are marked with
+a tilde, followed by the old and the new token, deleted tokens with a
+minus and inserted tokens with a plus:
+
+	~ 2:1 NUMBER "12" -> 2:1 NUMBER "13"
+	- 3:5 IDENT "foo"
+	+ 3:9 IDENT "bar"
+*/
+func WriteDiff(w io.Writer, names lexer.TokenNames, changes []TokenChange) error {
+	writer := bufio.NewWriter(w)
+
+	for _, change := range changes {
+		switch change.Kind {
+		case CHANGE_INSERT:
+			fmt.Fprintf(writer, "+ %s\n", diffToken(names, change.New))
+
+		case CHANGE_DELETE:
+			fmt.Fprintf(writer, "- %s\n", diffToken(names, change.Old))
+
+		case CHANGE_MODIFY:
+			fmt.Fprintf(writer, "~ %s -> %s\n", diffToken(names, change.Old), diffToken(names, change.New))
+		}
+	}
+
+	return writer.Flush()
+}
+
+func diffToken(names lexer.TokenNames, token lexer.Token) string {
+	return fmt.Sprintf("%d:%d %s %s", token.Span.Start.Line, token.Span.Start.Column, names.Name(token.Type), strconv.Quote(csvValue(token)))
+}
+
+type editKind int
+
+const (
+	editKeep editKind = iota
+	editDelete
+	editInsert
+)
+
+/*
+edit is one step of an edit script. index is into the old sequence for
+keeps and deletions, and into the new sequence for insertions.
+*/
+type edit struct {
+	kind  editKind
+	index int
+}
+
+/*
+editScript finds a shortest edit script turning a sequence of n items
+into one of m items using Myers' O(ND) algorithm. Only the diagonals
+reached at each step are kept for backtracking, so memory grows with
+the square of the number of differences rather than the input size.
+*/
+func editScript(n, m int, equal func(i, j int) bool) []edit {
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+	distance := -1
+
+	for d := 0; d <= max && distance < 0; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && equal(x, y) {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				distance = d
+				break
+			}
+		}
+
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+
+	var edits []edit
+	x, y := n, m
+
+	for d := distance; d > 0; d-- {
+		previous := trace[d-1]
+		reached := func(k int) int {
+			return previous[k+d-1]
+		}
+
+		k := x - y
+		previousK := k - 1
+
+		if k == -d || (k != d && reached(k-1) < reached(k+1)) {
+			previousK = k + 1
+		}
+
+		previousX := reached(previousK)
+		previousY := previousX - previousK
+
+		for x > previousX && y > previousY {
+			x--
+			y--
+			edits = append(edits, edit{kind: editKeep, index: x})
+		}
+
+		if previousK == k+1 {
+			edits = append(edits, edit{kind: editInsert, index: previousY})
+		} else {
+			edits = append(edits, edit{kind: editDelete, index: previousX})
+		}
+
+		x, y = previousX, previousY
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, edit{kind: editKeep, index: x})
+	}
+
+	for left, right := 0, len(edits)-1; left < right; left, right = left+1, right-1 {
+		edits[left], edits[right] = edits[right], edits[left]
+	}
+
+	return edits
+}
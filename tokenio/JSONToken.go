@@ -0,0 +1,93 @@
+/*
+Package tokenio reads and writes token streams in formats meant for
+other tools and processes, such as NDJSON for piping into jq.
+*/
+package tokenio
+
+import (
+	"github.com/adampresley/lexer"
+)
+
+/*
+JSONPosition is the JSON representation of a lexer.Position
+*/
+type JSONPosition struct {
+	Filename string `json:"file,omitempty"`
+	Offset   int    `json:"offset"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+/*
+JSONSpan is the JSON representation of a lexer.Span
+*/
+type JSONSpan struct {
+	Start JSONPosition `json:"start"`
+	End   JSONPosition `json:"end"`
+}
+
+/*
+JSONToken is the JSON representation of a token. Type holds the token
+type's name and TypeID its numeric value. Value is only present for
+tokens with a transformed value.
+*/
+type JSONToken struct {
+	Type   string      `json:"type"`
+	TypeID int         `json:"type_id"`
+	Text   string      `json:"text"`
+	Value  interface{} `json:"value,omitempty"`
+	Span   JSONSpan    `json:"span"`
+}
+
+/*
+ToJSONToken converts a token to its JSON representation
+*/
+func ToJSONToken(names lexer.TokenNames, token lexer.Token) JSONToken {
+	return JSONToken{
+		Type:   names.Name(token.Type),
+		TypeID: int(token.Type),
+		Text:   token.Text,
+		Value:  token.Value,
+		Span: JSONSpan{
+			Start: toJSONPosition(token.Span.Start),
+			End:   toJSONPosition(token.Span.End),
+		},
+	}
+}
+
+/*
+Token converts the JSON representation back to a token. Transformed
+values are restored as decoded by encoding/json.
+*/
+func (token JSONToken) Token() lexer.Token {
+	return lexer.Token{
+		Type:  lexer.TokenType(token.TypeID),
+		Text:  token.Text,
+		Value: token.Value,
+		Span: lexer.Span{
+			Start: token.Span.Start.Position(),
+			End:   token.Span.End.Position(),
+		},
+	}
+}
+
+/*
+Position converts the JSON representation back to a lexer.Position
+*/
+func (position JSONPosition) Position() lexer.Position {
+	return lexer.Position{
+		Filename: position.Filename,
+		Offset:   position.Offset,
+		Line:     position.Line,
+		Column:   position.Column,
+	}
+}
+
+func toJSONPosition(position lexer.Position) JSONPosition {
+	return JSONPosition{
+		Filename: position.Filename,
+		Offset:   position.Offset,
+		Line:     position.Line,
+		Column:   position.Column,
+	}
+}
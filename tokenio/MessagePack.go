@@ -0,0 +1,422 @@
+package tokenio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+ErrInvalidMessagePack is returned when decoding data that is not a
+token or diagnostic encoded by the MessagePack writer
+*/
+var ErrInvalidMessagePack = errors.New("tokenio: invalid MessagePack data")
+
+/*
+AppendMsgpackToken appends the MessagePack encoding of a token to buf.
+Tokens and diagnostics are encoded as arrays rather than maps to keep
+them compact. A token is
+
+	[type, text, value, start, end]
+
+and a diagnostic is
+
+	[message, severity, code, start, end]
+
+where value is nil or the string form of a transformed value, and
+start and end are positions encoded as [filename, offset, line, column].
+Type names are not included; consumers map the numeric type themselves.
+*/
+func AppendMsgpackToken(buf []byte, token lexer.Token) []byte {
+	buf = append(buf, 0x95)
+	buf = appendMsgpackInt(buf, int64(token.Type))
+	buf = appendMsgpackString(buf, token.Text)
+
+	if token.Value == nil {
+		buf = append(buf, 0xc0)
+	} else {
+		buf = appendMsgpackString(buf, csvValue(token))
+	}
+
+	buf = appendMsgpackPosition(buf, token.Span.Start)
+	return appendMsgpackPosition(buf, token.Span.End)
+}
+
+/*
+AppendMsgpackDiagnostic appends the MessagePack encoding of a
+diagnostic to buf
+*/
+func AppendMsgpackDiagnostic(buf []byte, diagnostic lexer.LexError) []byte {
+	buf = append(buf, 0x95)
+	buf = appendMsgpackString(buf, diagnostic.Message)
+	buf = appendMsgpackInt(buf, int64(diagnostic.Severity))
+	buf = appendMsgpackString(buf, diagnostic.Code)
+	buf = appendMsgpackPosition(buf, diagnostic.Span.Start)
+	return appendMsgpackPosition(buf, diagnostic.Span.End)
+}
+
+/*
+MsgpackWriter writes a sequence of MessagePack encoded tokens and
+diagnostics to an underlying writer. Writes are buffered until Flush.
+*/
+type MsgpackWriter struct {
+	w   *bufio.Writer
+	buf []byte
+}
+
+/*
+NewMsgpackWriter creates a MessagePack writer
+*/
+func NewMsgpackWriter(w io.Writer) *MsgpackWriter {
+	return &MsgpackWriter{
+		w: bufio.NewWriter(w),
+	}
+}
+
+/*
+Write writes a single token
+*/
+func (writer *MsgpackWriter) Write(token lexer.Token) error {
+	writer.buf = AppendMsgpackToken(writer.buf[:0], token)
+	_, err := writer.w.Write(writer.buf)
+	return err
+}
+
+/*
+WriteDiagnostic writes a single diagnostic
+*/
+func (writer *MsgpackWriter) WriteDiagnostic(diagnostic lexer.LexError) error {
+	writer.buf = AppendMsgpackDiagnostic(writer.buf[:0], diagnostic)
+	_, err := writer.w.Write(writer.buf)
+	return err
+}
+
+/*
+Flush writes any buffered data to the underlying writer
+*/
+func (writer *MsgpackWriter) Flush() error {
+	return writer.w.Flush()
+}
+
+/*
+WriteMsgpack writes tokens to w as a sequence of MessagePack arrays
+*/
+func WriteMsgpack(w io.Writer, tokens ...lexer.Token) error {
+	writer := NewMsgpackWriter(w)
+
+	for _, token := range tokens {
+		if err := writer.Write(token); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+/*
+MsgpackReader reads tokens and diagnostics written by a MsgpackWriter.
+The caller must know which of the two comes next in the stream.
+*/
+type MsgpackReader struct {
+	r *bufio.Reader
+}
+
+/*
+NewMsgpackReader creates a MessagePack reader
+*/
+func NewMsgpackReader(r io.Reader) *MsgpackReader {
+	return &MsgpackReader{
+		r: bufio.NewReader(r),
+	}
+}
+
+/*
+Read reads the next token. It returns io.EOF when the stream ends
+cleanly between tokens.
+*/
+func (reader *MsgpackReader) Read() (lexer.Token, error) {
+	var token lexer.Token
+
+	if err := reader.readArrayHeader(5, true); err != nil {
+		return token, err
+	}
+
+	tokenType, err := reader.readInt()
+
+	if err != nil {
+		return token, err
+	}
+
+	token.Type = lexer.TokenType(tokenType)
+
+	if token.Text, err = reader.readString(); err != nil {
+		return token, err
+	}
+
+	if value, isNil, err := reader.readNilOrString(); err != nil {
+		return token, err
+	} else if !isNil {
+		token.Value = value
+	}
+
+	token.Span, err = reader.readSpan()
+	return token, err
+}
+
+/*
+ReadDiagnostic reads the next diagnostic. It returns io.EOF when the
+stream ends cleanly.
+*/
+func (reader *MsgpackReader) ReadDiagnostic() (lexer.LexError, error) {
+	var diagnostic lexer.LexError
+	var err error
+
+	if err = reader.readArrayHeader(5, true); err != nil {
+		return diagnostic, err
+	}
+
+	if diagnostic.Message, err = reader.readString(); err != nil {
+		return diagnostic, err
+	}
+
+	severity, err := reader.readInt()
+
+	if err != nil {
+		return diagnostic, err
+	}
+
+	diagnostic.Severity = lexer.Severity(severity)
+
+	if diagnostic.Code, err = reader.readString(); err != nil {
+		return diagnostic, err
+	}
+
+	diagnostic.Span, err = reader.readSpan()
+	return diagnostic, err
+}
+
+func (reader *MsgpackReader) readSpan() (lexer.Span, error) {
+	var span lexer.Span
+	var err error
+
+	if span.Start, err = reader.readPosition(); err != nil {
+		return span, err
+	}
+
+	span.End, err = reader.readPosition()
+	return span, err
+}
+
+func (reader *MsgpackReader) readPosition() (lexer.Position, error) {
+	var position lexer.Position
+	var err error
+
+	if err = reader.readArrayHeader(4, false); err != nil {
+		return position, err
+	}
+
+	if position.Filename, err = reader.readString(); err != nil {
+		return position, err
+	}
+
+	fields := [3]*int{&position.Offset, &position.Line, &position.Column}
+
+	for _, field := range fields {
+		value, err := reader.readInt()
+
+		if err != nil {
+			return position, err
+		}
+
+		*field = int(value)
+	}
+
+	return position, nil
+}
+
+func (reader *MsgpackReader) readArrayHeader(length int, atStart bool) error {
+	b, err := reader.r.ReadByte()
+
+	if err != nil {
+		if err == io.EOF && atStart {
+			return io.EOF
+		}
+
+		return ErrInvalidMessagePack
+	}
+
+	if b != 0x90|byte(length) {
+		return fmt.Errorf("%w: expected array of %d, found 0x%02x", ErrInvalidMessagePack, length, b)
+	}
+
+	return nil
+}
+
+func (reader *MsgpackReader) readInt() (int64, error) {
+	b, err := reader.r.ReadByte()
+
+	if err != nil {
+		return 0, ErrInvalidMessagePack
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	}
+
+	var size int
+
+	switch b {
+	case 0xcc, 0xd0:
+		size = 1
+
+	case 0xcd, 0xd1:
+		size = 2
+
+	case 0xce, 0xd2:
+		size = 4
+
+	case 0xcf, 0xd3:
+		size = 8
+
+	default:
+		return 0, fmt.Errorf("%w: expected integer, found 0x%02x", ErrInvalidMessagePack, b)
+	}
+
+	var data [8]byte
+
+	if _, err = io.ReadFull(reader.r, data[8-size:]); err != nil {
+		return 0, ErrInvalidMessagePack
+	}
+
+	value := binary.BigEndian.Uint64(data[:])
+
+	if b >= 0xd0 {
+		shift := uint(64 - size*8)
+		return int64(value<<shift) >> shift, nil
+	}
+
+	return int64(value), nil
+}
+
+func (reader *MsgpackReader) readString() (string, error) {
+	value, isNil, err := reader.readNilOrString()
+
+	if err == nil && isNil {
+		err = fmt.Errorf("%w: unexpected nil", ErrInvalidMessagePack)
+	}
+
+	return value, err
+}
+
+func (reader *MsgpackReader) readNilOrString() (string, bool, error) {
+	b, err := reader.r.ReadByte()
+
+	if err != nil {
+		return "", false, ErrInvalidMessagePack
+	}
+
+	var length uint64
+
+	switch {
+	case b == 0xc0:
+		return "", true, nil
+
+	case b&0xe0 == 0xa0:
+		length = uint64(b & 0x1f)
+
+	case b >= 0xd9 && b <= 0xdb:
+		size := 1 << (b - 0xd9)
+		var data [8]byte
+
+		if _, err = io.ReadFull(reader.r, data[8-size:]); err != nil {
+			return "", false, ErrInvalidMessagePack
+		}
+
+		length = binary.BigEndian.Uint64(data[:])
+
+	default:
+		return "", false, fmt.Errorf("%w: expected string, found 0x%02x", ErrInvalidMessagePack, b)
+	}
+
+	data := make([]byte, length)
+
+	if _, err = io.ReadFull(reader.r, data); err != nil {
+		return "", false, ErrInvalidMessagePack
+	}
+
+	return string(data), false, nil
+}
+
+func appendMsgpackPosition(buf []byte, position lexer.Position) []byte {
+	buf = append(buf, 0x94)
+	buf = appendMsgpackString(buf, position.Filename)
+	buf = appendMsgpackInt(buf, int64(position.Offset))
+	buf = appendMsgpackInt(buf, int64(position.Line))
+	return appendMsgpackInt(buf, int64(position.Column))
+}
+
+/*
+appendMsgpackInt appends value using the smallest integer encoding
+that holds it
+*/
+func appendMsgpackInt(buf []byte, value int64) []byte {
+	switch {
+	case value >= 0 && value <= 0x7f:
+		return append(buf, byte(value))
+
+	case value < 0 && value >= -32:
+		return append(buf, byte(value))
+
+	case value >= 0 && value <= math.MaxUint8:
+		return append(buf, 0xcc, byte(value))
+
+	case value >= 0 && value <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xcd), uint16(value))
+
+	case value >= 0 && value <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xce), uint32(value))
+
+	case value >= math.MinInt8 && value < 0:
+		return append(buf, 0xd0, byte(value))
+
+	case value >= math.MinInt16 && value < 0:
+		return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(value))
+
+	case value >= math.MinInt32 && value < 0:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(value))
+
+	case value >= 0:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcf), uint64(value))
+	}
+
+	return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(value))
+}
+
+func appendMsgpackString(buf []byte, value string) []byte {
+	length := len(value)
+
+	switch {
+	case length <= 31:
+		buf = append(buf, 0xa0|byte(length))
+
+	case length <= math.MaxUint8:
+		buf = append(buf, 0xd9, byte(length))
+
+	case length <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(length))
+
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(length))
+	}
+
+	return append(buf, value...)
+}
@@ -0,0 +1,143 @@
+package tokenio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adampresley/lexer"
+)
+
+func TestAppendMsgpackToken(t *testing.T) {
+	got := AppendMsgpackToken(nil, stringTokens[1])
+	want := []byte{
+		0x95, // [type, text, value, start, end]
+		0xff, // -1
+		0xa0, // ""
+		0xc0, // nil
+		0x94, 0xa0, 0x0a, 0x02, 0x08,
+		0x94, 0xa0, 0x0a, 0x02, 0x08,
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestMsgpackIntegers(t *testing.T) {
+	tests := []struct {
+		value int64
+		want  []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0xcc, 0x80}},
+		{255, []byte{0xcc, 0xff}},
+		{256, []byte{0xcd, 0x01, 0x00}},
+		{65536, []byte{0xce, 0x00, 0x01, 0x00, 0x00}},
+		{math.MaxUint32 + 1, []byte{0xcf, 0, 0, 0, 0x01, 0, 0, 0, 0}},
+		{-1, []byte{0xff}},
+		{-32, []byte{0xe0}},
+		{-33, []byte{0xd0, 0xdf}},
+		{-129, []byte{0xd1, 0xff, 0x7f}},
+		{-32769, []byte{0xd2, 0xff, 0xff, 0x7f, 0xff}},
+		{math.MinInt64, []byte{0xd3, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+	}
+
+	for _, test := range tests {
+		got := appendMsgpackInt(nil, test.value)
+
+		if !bytes.Equal(got, test.want) {
+			t.Errorf("appendMsgpackInt(%d): got % x, want % x", test.value, got, test.want)
+		}
+
+		value, err := NewMsgpackReader(bytes.NewReader(got)).readInt()
+		if err != nil || value != test.value {
+			t.Errorf("reading % x: got %d, %v, want %d", got, value, err, test.value)
+		}
+	}
+}
+
+func TestMsgpackLongStrings(t *testing.T) {
+	for _, length := range []int{31, 32, 255, 256, 65535, 65536} {
+		token := lexer.Token{Text: strings.Repeat("x", length)}
+
+		var buf bytes.Buffer
+		if err := WriteMsgpack(&buf, token); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := NewMsgpackReader(&buf).Read()
+		if err != nil || got.Text != token.Text {
+			t.Errorf("text of %d bytes: got %d bytes, %v", length, len(got.Text), err)
+		}
+	}
+}
+
+func TestMsgpackReaderInvalid(t *testing.T) {
+	data := AppendMsgpackToken(nil, stringTokens[0])
+
+	if _, err := NewMsgpackReader(bytes.NewReader(data[:len(data)-1])).Read(); !errors.Is(err, ErrInvalidMessagePack) {
+		t.Errorf("truncated token: got %v, want %v", err, ErrInvalidMessagePack)
+	}
+
+	if _, err := NewMsgpackReader(bytes.NewReader([]byte{0x93})).Read(); !errors.Is(err, ErrInvalidMessagePack) {
+		t.Errorf("array of 3: got %v, want %v", err, ErrInvalidMessagePack)
+	}
+}
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	tokens, diagnostics := sample(t)
+
+	var buf bytes.Buffer
+	writer := NewMsgpackWriter(&buf)
+
+	for _, token := range tokens {
+		if err := writer.Write(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, diagnostic := range diagnostics {
+		if err := writer.WriteDiagnostic(diagnostic); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := NewMsgpackReader(&buf)
+	got := make([]lexer.Token, len(tokens))
+
+	for index := range got {
+		token, err := reader.Read()
+		if err != nil {
+			t.Fatalf("reading token %d: %s", index, err)
+		}
+
+		got[index] = token
+	}
+
+	compareTokens(t, got, stringValues(tokens))
+
+	for index, want := range diagnostics {
+		diagnostic, err := reader.ReadDiagnostic()
+		if err != nil {
+			t.Fatalf("reading diagnostic %d: %s", index, err)
+		}
+
+		if !reflect.DeepEqual(diagnostic, want) {
+			t.Errorf("diagnostic %d: got %v, want %v", index, diagnostic, want)
+		}
+	}
+
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("reading past the end: got %v, want io.EOF", err)
+	}
+}
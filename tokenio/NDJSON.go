@@ -0,0 +1,72 @@
+package tokenio
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+NDJSONWriter writes tokens as newline delimited JSON, one JSONToken
+object per line, ready for jq and other line oriented tools.
+*/
+type NDJSONWriter struct {
+	encoder *json.Encoder
+	names   lexer.TokenNames
+}
+
+/*
+NewNDJSONWriter creates a writer that names token types using names,
+which may be nil.
+*/
+func NewNDJSONWriter(w io.Writer, names lexer.TokenNames) *NDJSONWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	return &NDJSONWriter{
+		encoder: encoder,
+		names:   names,
+	}
+}
+
+/*
+Write writes a single token as one line of JSON
+*/
+func (writer *NDJSONWriter) Write(token lexer.Token) error {
+	return writer.encoder.Encode(ToJSONToken(writer.names, token))
+}
+
+/*
+WriteNDJSON writes tokens to w as newline delimited JSON
+*/
+func WriteNDJSON(w io.Writer, names lexer.TokenNames, tokens ...lexer.Token) error {
+	writer := NewNDJSONWriter(w, names)
+
+	for _, token := range tokens {
+		if err := writer.Write(token); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+StreamNDJSON writes every token received from tokens to w as newline
+delimited JSON until the channel is closed, such as the Tokens channel
+of a running lexer. If writing fails the remaining tokens are still
+drained so the lexer can finish, and the first error is returned.
+*/
+func StreamNDJSON(w io.Writer, names lexer.TokenNames, tokens <-chan lexer.Token) error {
+	writer := NewNDJSONWriter(w, names)
+	var result error
+
+	for token := range tokens {
+		if result == nil {
+			result = writer.Write(token)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,79 @@
+package tokenio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/presets/golike"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, golike.Names, stringTokens...); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"type":"STRING","type_id":6,"text":"\"<a>\\t\"","value":"<a>\t","span":{"start":{"file":"t.go","offset":3,"line":2,"column":1},"end":{"file":"t.go","offset":10,"line":2,"column":8}}}
+{"type":"EOF","type_id":-1,"text":"","span":{"start":{"offset":10,"line":2,"column":8},"end":{"offset":10,"line":2,"column":8}}}
+`
+
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	tokens := make(chan lexer.Token, len(stringTokens))
+
+	for _, token := range stringTokens {
+		tokens <- token
+	}
+
+	close(tokens)
+
+	var streamed, written bytes.Buffer
+	if err := StreamNDJSON(&streamed, golike.Names, tokens); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteNDJSON(&written, golike.Names, stringTokens...); err != nil {
+		t.Fatal(err)
+	}
+
+	if streamed.String() != written.String() {
+		t.Errorf("streamed:\n%s\nwritten:\n%s", streamed.String(), written.String())
+	}
+}
+
+func TestNDJSONRoundTrip(t *testing.T) {
+	tokens, _ := sample(t)
+
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, golike.Names, tokens...); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []lexer.Token
+	scanner := bufio.NewScanner(&buf)
+
+	for scanner.Scan() {
+		var token JSONToken
+
+		if err := json.Unmarshal(scanner.Bytes(), &token); err != nil {
+			t.Fatalf("decoding line %d: %s", len(got)+1, err)
+		}
+
+		if want := golike.Names.Name(token.Token().Type); token.Type != want {
+			t.Errorf("line %d: got type name %s, want %s", len(got)+1, token.Type, want)
+		}
+
+		got = append(got, token.Token())
+	}
+
+	// encoding/json decodes numbers as float64, which print as the
+	// values they were decoded from
+	compareTokens(t, stringValues(got), stringValues(tokens))
+}
@@ -0,0 +1,435 @@
+package tokenio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+ErrInvalidProtobuf is returned when decoding data that is not a valid
+encoding of the messages in tokens.proto
+*/
+var ErrInvalidProtobuf = errors.New("tokenio: invalid protobuf data")
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+/*
+AppendProtoToken appends the protobuf encoding of a Token message to
+buf. Transformed values are encoded in their string form.
+*/
+func AppendProtoToken(buf []byte, names lexer.TokenNames, token lexer.Token) []byte {
+	buf = appendTag(buf, 1, wireVarint)
+	buf = binary.AppendUvarint(buf, zigzag(int64(token.Type)))
+	buf = appendString(buf, 2, names.Name(token.Type))
+	buf = appendString(buf, 3, token.Text)
+	buf = appendMessage(buf, 4, func(buf []byte) []byte {
+		return appendSpan(buf, token.Span)
+	})
+
+	if token.Value != nil {
+		buf = appendTag(buf, 5, wireBytes)
+		buf = appendBytes(buf, csvValue(token))
+	}
+
+	return buf
+}
+
+/*
+UnmarshalProtoToken decodes a Token message
+*/
+func UnmarshalProtoToken(data []byte) (lexer.Token, error) {
+	var token lexer.Token
+
+	err := eachField(data, func(field int, wireType int, value uint64, bytes []byte) error {
+		var err error
+
+		switch {
+		case field == 1 && wireType == wireVarint:
+			token.Type = lexer.TokenType(unzigzag(value))
+
+		case field == 3 && wireType == wireBytes:
+			token.Text = string(bytes)
+
+		case field == 4 && wireType == wireBytes:
+			token.Span, err = decodeSpan(bytes)
+
+		case field == 5 && wireType == wireBytes:
+			token.Value = string(bytes)
+		}
+
+		return err
+	})
+
+	return token, err
+}
+
+/*
+AppendProtoDiagnostic appends the protobuf encoding of a Diagnostic
+message to buf
+*/
+func AppendProtoDiagnostic(buf []byte, diagnostic lexer.LexError) []byte {
+	buf = appendString(buf, 1, diagnostic.Message)
+	buf = appendMessage(buf, 2, func(buf []byte) []byte {
+		return appendSpan(buf, diagnostic.Span)
+	})
+	buf = appendVarint(buf, 3, uint64(diagnostic.Severity))
+	buf = appendString(buf, 4, diagnostic.Code)
+
+	return buf
+}
+
+/*
+UnmarshalProtoDiagnostic decodes a Diagnostic message
+*/
+func UnmarshalProtoDiagnostic(data []byte) (lexer.LexError, error) {
+	var diagnostic lexer.LexError
+
+	err := eachField(data, func(field int, wireType int, value uint64, bytes []byte) error {
+		var err error
+
+		switch {
+		case field == 1 && wireType == wireBytes:
+			diagnostic.Message = string(bytes)
+
+		case field == 2 && wireType == wireBytes:
+			diagnostic.Span, err = decodeSpan(bytes)
+
+		case field == 3 && wireType == wireVarint:
+			diagnostic.Severity = lexer.Severity(int32(value))
+
+		case field == 4 && wireType == wireBytes:
+			diagnostic.Code = string(bytes)
+		}
+
+		return err
+	})
+
+	return diagnostic, err
+}
+
+/*
+MarshalProtoStream encodes a complete token stream and its diagnostics
+as a TokenStream message
+*/
+func MarshalProtoStream(names lexer.TokenNames, tokens []lexer.Token, diagnostics []lexer.LexError) []byte {
+	var buf []byte
+
+	for _, token := range tokens {
+		buf = appendMessage(buf, 1, func(buf []byte) []byte {
+			return AppendProtoToken(buf, names, token)
+		})
+	}
+
+	for _, diagnostic := range diagnostics {
+		buf = appendMessage(buf, 2, func(buf []byte) []byte {
+			return AppendProtoDiagnostic(buf, diagnostic)
+		})
+	}
+
+	return buf
+}
+
+/*
+UnmarshalProtoStream decodes a TokenStream message
+*/
+func UnmarshalProtoStream(data []byte) ([]lexer.Token, []lexer.LexError, error) {
+	var tokens []lexer.Token
+	var diagnostics []lexer.LexError
+
+	err := eachField(data, func(field int, wireType int, value uint64, bytes []byte) error {
+		if wireType != wireBytes {
+			return nil
+		}
+
+		switch field {
+		case 1:
+			token, err := UnmarshalProtoToken(bytes)
+			tokens = append(tokens, token)
+			return err
+
+		case 2:
+			diagnostic, err := UnmarshalProtoDiagnostic(bytes)
+			diagnostics = append(diagnostics, diagnostic)
+			return err
+		}
+
+		return nil
+	})
+
+	return tokens, diagnostics, err
+}
+
+/*
+ProtoWriter writes tokens as a stream of length delimited Token
+messages, the framing used by writeDelimitedTo and parseDelimitedFrom
+in the other protobuf runtimes.
+*/
+type ProtoWriter struct {
+	w     io.Writer
+	names lexer.TokenNames
+	buf   []byte
+}
+
+/*
+NewProtoWriter creates a writer of length delimited Token messages
+*/
+func NewProtoWriter(w io.Writer, names lexer.TokenNames) *ProtoWriter {
+	return &ProtoWriter{
+		w:     w,
+		names: names,
+	}
+}
+
+/*
+Write writes a single token
+*/
+func (writer *ProtoWriter) Write(token lexer.Token) error {
+	writer.buf = appendMessage(writer.buf[:0], 0, func(buf []byte) []byte {
+		return AppendProtoToken(buf, writer.names, token)
+	})
+
+	_, err := writer.w.Write(writer.buf)
+	return err
+}
+
+/*
+ProtoReader reads a stream of length delimited Token messages written
+by a ProtoWriter or any other protobuf runtime
+*/
+type ProtoReader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+/*
+NewProtoReader creates a reader of length delimited Token messages
+*/
+func NewProtoReader(r io.Reader) *ProtoReader {
+	return &ProtoReader{
+		r: bufio.NewReader(r),
+	}
+}
+
+/*
+Read reads the next token. It returns io.EOF when the stream ends
+cleanly between messages.
+*/
+func (reader *ProtoReader) Read() (lexer.Token, error) {
+	length, err := binary.ReadUvarint(reader.r)
+
+	if err != nil {
+		if err == io.EOF {
+			return lexer.Token{}, io.EOF
+		}
+
+		return lexer.Token{}, ErrInvalidProtobuf
+	}
+
+	if uint64(cap(reader.buf)) < length {
+		reader.buf = make([]byte, length)
+	}
+
+	reader.buf = reader.buf[:length]
+
+	if _, err = io.ReadFull(reader.r, reader.buf); err != nil {
+		return lexer.Token{}, ErrInvalidProtobuf
+	}
+
+	return UnmarshalProtoToken(reader.buf)
+}
+
+func appendSpan(buf []byte, span lexer.Span) []byte {
+	buf = appendMessage(buf, 1, func(buf []byte) []byte {
+		return appendPosition(buf, span.Start)
+	})
+
+	return appendMessage(buf, 2, func(buf []byte) []byte {
+		return appendPosition(buf, span.End)
+	})
+}
+
+func appendPosition(buf []byte, position lexer.Position) []byte {
+	buf = appendString(buf, 1, position.Filename)
+	buf = appendVarint(buf, 2, uint64(position.Offset))
+	buf = appendVarint(buf, 3, uint64(position.Line))
+	return appendVarint(buf, 4, uint64(position.Column))
+}
+
+func decodeSpan(data []byte) (lexer.Span, error) {
+	var span lexer.Span
+
+	err := eachField(data, func(field int, wireType int, value uint64, bytes []byte) error {
+		var err error
+
+		if wireType != wireBytes {
+			return nil
+		}
+
+		switch field {
+		case 1:
+			span.Start, err = decodePosition(bytes)
+
+		case 2:
+			span.End, err = decodePosition(bytes)
+		}
+
+		return err
+	})
+
+	return span, err
+}
+
+func decodePosition(data []byte) (lexer.Position, error) {
+	var position lexer.Position
+
+	err := eachField(data, func(field int, wireType int, value uint64, bytes []byte) error {
+		switch {
+		case field == 1 && wireType == wireBytes:
+			position.Filename = string(bytes)
+
+		case field == 2 && wireType == wireVarint:
+			position.Offset = int(int64(value))
+
+		case field == 3 && wireType == wireVarint:
+			position.Line = int(int64(value))
+
+		case field == 4 && wireType == wireVarint:
+			position.Column = int(int64(value))
+		}
+
+		return nil
+	})
+
+	return position, err
+}
+
+/*
+eachField calls fn for every field in an encoded message. value holds
+varint and fixed width values, bytes the contents of length delimited
+fields. Unknown fields are passed along and may simply be ignored.
+*/
+func eachField(data []byte, fn func(field int, wireType int, value uint64, bytes []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+
+		if n <= 0 || key>>3 == 0 {
+			return ErrInvalidProtobuf
+		}
+
+		data = data[n:]
+		field, wireType := int(key>>3), int(key&7)
+		var value uint64
+		var bytes []byte
+
+		switch wireType {
+		case wireVarint:
+			if value, n = binary.Uvarint(data); n <= 0 {
+				return ErrInvalidProtobuf
+			}
+
+		case wireFixed64:
+			if n = 8; len(data) < n {
+				return ErrInvalidProtobuf
+			}
+
+			value = binary.LittleEndian.Uint64(data)
+
+		case wireFixed32:
+			if n = 4; len(data) < n {
+				return ErrInvalidProtobuf
+			}
+
+			value = uint64(binary.LittleEndian.Uint32(data))
+
+		case wireBytes:
+			length, m := binary.Uvarint(data)
+
+			if m <= 0 || length > uint64(len(data)-m) {
+				return ErrInvalidProtobuf
+			}
+
+			bytes = data[m : m+int(length)]
+			n = m + int(length)
+
+		default:
+			return fmt.Errorf("%w: unsupported wire type %d", ErrInvalidProtobuf, wireType)
+		}
+
+		data = data[n:]
+
+		if err := fn(field, wireType, value, bytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func appendTag(buf []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(buf, uint64(field)<<3|uint64(wireType))
+}
+
+func appendVarint(buf []byte, field int, value uint64) []byte {
+	if value == 0 {
+		return buf
+	}
+
+	buf = appendTag(buf, field, wireVarint)
+	return binary.AppendUvarint(buf, value)
+}
+
+func appendString(buf []byte, field int, value string) []byte {
+	if value == "" {
+		return buf
+	}
+
+	buf = appendTag(buf, field, wireBytes)
+	return appendBytes(buf, value)
+}
+
+func appendBytes(buf []byte, value string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+/*
+appendMessage appends a length delimited embedded message built by fn.
+The message is built in place and shifted once its length is known.
+A field of 0 writes only the length prefix, for delimited streams.
+*/
+func appendMessage(buf []byte, field int, fn func(buf []byte) []byte) []byte {
+	if field != 0 {
+		buf = appendTag(buf, field, wireBytes)
+	}
+
+	start := len(buf)
+	buf = fn(buf)
+	length := len(buf) - start
+
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], uint64(length))
+
+	buf = append(buf, prefix[:n]...)
+	copy(buf[start+n:], buf[start:start+length])
+	copy(buf[start:], prefix[:n])
+
+	return buf
+}
+
+func zigzag(value int64) uint64 {
+	return uint64(value<<1) ^ uint64(value>>63)
+}
+
+func unzigzag(value uint64) int64 {
+	return int64(value>>1) ^ -int64(value&1)
+}
@@ -0,0 +1,99 @@
+package tokenio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/presets/golike"
+)
+
+func TestAppendProtoToken(t *testing.T) {
+	got := AppendProtoToken(nil, golike.Names, stringTokens[1])
+	want := []byte{
+		0x08, 0x01, // type -1, zigzag encoded
+		0x12, 0x03, 'E', 'O', 'F',
+		0x22, 0x10, // span
+		0x0a, 0x06, 0x10, 0x0a, 0x18, 0x02, 0x20, 0x08,
+		0x12, 0x06, 0x10, 0x0a, 0x18, 0x02, 0x20, 0x08,
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestUnmarshalProtoToken(t *testing.T) {
+	data := AppendProtoToken(nil, golike.Names, stringTokens[0])
+
+	// fields of a newer schema are skipped
+	unknown := append(appendTag(nil, 9, wireFixed64), 1, 2, 3, 4, 5, 6, 7, 8)
+	unknown = append(appendTag(unknown, 10, wireFixed32), 1, 2, 3, 4)
+
+	token, err := UnmarshalProtoToken(append(data, unknown...))
+	if err != nil || !reflect.DeepEqual(token, stringTokens[0]) {
+		t.Errorf("with unknown fields: got %#v, %v, want %#v", token, err, stringTokens[0])
+	}
+
+	if _, err := UnmarshalProtoToken(data[:len(data)-1]); !errors.Is(err, ErrInvalidProtobuf) {
+		t.Errorf("missing its last byte: got %v, want %v", err, ErrInvalidProtobuf)
+	}
+}
+
+func TestProtoReaderTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewProtoWriter(&buf, golike.Names).Write(stringTokens[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := NewProtoReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+
+	if _, err := reader.Read(); err != ErrInvalidProtobuf {
+		t.Errorf("got %v, want %v", err, ErrInvalidProtobuf)
+	}
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	tokens, diagnostics := sample(t)
+
+	var buf bytes.Buffer
+	writer := NewProtoWriter(&buf, golike.Names)
+
+	for _, token := range tokens {
+		if err := writer.Write(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader := NewProtoReader(&buf)
+	got := make([]lexer.Token, len(tokens))
+
+	for index := range got {
+		token, err := reader.Read()
+		if err != nil {
+			t.Fatalf("reading token %d: %s", index, err)
+		}
+
+		got[index] = token
+	}
+
+	compareTokens(t, got, stringValues(tokens))
+
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("reading past the end: got %v, want io.EOF", err)
+	}
+
+	streamTokens, streamDiagnostics, err := UnmarshalProtoStream(MarshalProtoStream(golike.Names, tokens, diagnostics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compareTokens(t, streamTokens, stringValues(tokens))
+
+	if !reflect.DeepEqual(streamDiagnostics, diagnostics) {
+		t.Errorf("got diagnostics %v, want %v", streamDiagnostics, diagnostics)
+	}
+}
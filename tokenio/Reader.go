@@ -0,0 +1,151 @@
+package tokenio
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+TokenWriter writes tokens one at a time. Every writer of this package
+implements it.
+*/
+type TokenWriter interface {
+	Write(token lexer.Token) error
+}
+
+/*
+TokenReader is an io.Reader of the serialized tokens of a lexer, for
+passing them through standard io plumbing, such as compression or a
+network connection, without collecting them first:
+
+	reader := tokenio.NewNDJSONReader(l, Names)
+	io.Copy(gzipWriter, reader)
+
+TokenReader is an io.WriterTo as well, so io.Copy hands the tokens to
+the destination as they are serialized.
+
+The lexer runs on the calling goroutine, a few tokens at a time, as Read
+asks for data. Writers with a Flush method are flushed after every
+token and writers with a Close method are closed after the EOF token,
+which ends the data.
+*/
+type TokenReader struct {
+	stream *lexer.TokenStream
+	buffer bytes.Buffer
+	writer TokenWriter
+	done   bool
+	err    error
+}
+
+/*
+NewTokenReader creates a reader of the tokens of l, serialized by the
+writer newWriter creates on the reader's buffer
+*/
+func NewTokenReader(l *lexer.Lexer, newWriter func(w io.Writer) (TokenWriter, error)) *TokenReader {
+	reader := &TokenReader{stream: lexer.NewTokenStream(l)}
+	reader.writer, reader.err = newWriter(&reader.buffer)
+
+	return reader
+}
+
+/*
+NewNDJSONReader creates a reader of the tokens of l as newline delimited
+JSON, as NDJSONWriter writes them
+*/
+func NewNDJSONReader(l *lexer.Lexer, names lexer.TokenNames) *TokenReader {
+	return NewTokenReader(l, func(w io.Writer) (TokenWriter, error) {
+		return NewNDJSONWriter(w, names), nil
+	})
+}
+
+/*
+NewBinaryReader creates a reader of the tokens of l as a binary
+recording, as BinaryWriter writes it. The lexer must hold its input in
+memory, as the recording starts with a hash of it.
+*/
+func NewBinaryReader(l *lexer.Lexer) *TokenReader {
+	return NewTokenReader(l, func(w io.Writer) (TokenWriter, error) {
+		return NewBinaryWriter(w, l.Name, l.Input)
+	})
+}
+
+/*
+Read reads serialized tokens into p, lexing more tokens as needed
+*/
+func (reader *TokenReader) Read(p []byte) (int, error) {
+	for reader.buffer.Len() == 0 && !reader.done && reader.err == nil {
+		reader.err = reader.next()
+	}
+
+	if reader.buffer.Len() > 0 {
+		return reader.buffer.Read(p)
+	}
+
+	if reader.err != nil {
+		return 0, reader.err
+	}
+
+	return 0, io.EOF
+}
+
+/*
+WriteTo writes the serialized tokens to w, lexing until the EOF token
+has been written, so io.Copy moves tokens without a buffer of its own
+*/
+func (reader *TokenReader) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+
+	for {
+		n, err := reader.buffer.WriteTo(w)
+		total += n
+
+		if err != nil {
+			return total, err
+		}
+
+		if reader.done || reader.err != nil {
+			return total, reader.err
+		}
+
+		reader.err = reader.next()
+	}
+}
+
+/*
+next serializes the next token, closing the writer after EOF
+*/
+func (reader *TokenReader) next() error {
+	token := reader.stream.Next()
+	reader.done = token.Type == lexer.TOKEN_EOF
+
+	return writeToken(reader.writer, token)
+}
+
+/*
+writeToken writes token with writer, flushing writers with a Flush
+method after it and closing writers with a Close method after the EOF
+token
+*/
+func writeToken(writer TokenWriter, token lexer.Token) error {
+	if err := writer.Write(token); err != nil {
+		return err
+	}
+
+	if flusher, ok := writer.(interface{ Flush() error }); ok {
+		if err := flusher.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if token.Type != lexer.TOKEN_EOF {
+		return nil
+	}
+
+	if closer, ok := writer.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package tokenio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/adampresley/lexer/presets/golike"
+)
+
+func TestNDJSONReader(t *testing.T) {
+	tokens, _ := sample(t)
+
+	var want bytes.Buffer
+	if err := WriteNDJSON(&want, golike.Names, tokens...); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := io.ReadAll(iotest.OneByteReader(NewNDJSONReader(golike.NewLexer("sample.go", sampleSource), golike.Names)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != want.String() {
+		t.Errorf("read one byte at a time:\ngot:\n%s\nwant:\n%s", got, want.String())
+	}
+
+	var copied bytes.Buffer
+	if _, err := NewNDJSONReader(golike.NewLexer("sample.go", sampleSource), golike.Names).WriteTo(&copied); err != nil {
+		t.Fatal(err)
+	}
+
+	if copied.String() != want.String() {
+		t.Errorf("WriteTo:\ngot:\n%s\nwant:\n%s", copied.String(), want.String())
+	}
+}
+
+func TestTokenReaderWriterError(t *testing.T) {
+	failed := io.ErrClosedPipe
+
+	reader := NewTokenReader(golike.NewLexer("sample.go", sampleSource), func(w io.Writer) (TokenWriter, error) {
+		return nil, failed
+	})
+
+	if _, err := io.ReadAll(reader); err != failed {
+		t.Errorf("got %v, want %v", err, failed)
+	}
+}
+
+func TestBinaryReaderRoundTrip(t *testing.T) {
+	tokens, _ := sample(t)
+
+	data, err := io.ReadAll(NewBinaryReader(golike.NewLexer("sample.go", sampleSource)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recording, err := ReadRecording(bytes.NewReader(data), sampleSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compareTokens(t, recording.Tokens, stringValues(tokens))
+}
@@ -0,0 +1,84 @@
+package tokenio
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/presets/golike"
+)
+
+const sampleSource = "x := `a\nb` + 0x1F // c\n$y := \"é\\n\"\n"
+
+/*
+sample lexes sampleSource, which spans lines and holds tokens with
+transformed values and an error, returning its tokens and diagnostics
+*/
+func sample(t *testing.T) ([]lexer.Token, []lexer.LexError) {
+	t.Helper()
+
+	l := golike.NewLexer("sample.go", sampleSource)
+	tokens := l.Collect()
+
+	if len(l.Diagnostics()) == 0 {
+		t.Fatalf("sample has no diagnostics")
+	}
+
+	return tokens, l.Diagnostics()
+}
+
+/*
+stringTokens holds a token with a transformed value and characters that
+need escaping in most formats, followed by an EOF token without a
+filename, for tests of the exact output of a writer
+*/
+var stringTokens = []lexer.Token{
+	{
+		Type:  golike.TOKEN_STRING,
+		Text:  `"<a>\t"`,
+		Value: "<a>\t",
+		Span: lexer.Span{
+			Start: lexer.Position{Filename: "t.go", Offset: 3, Line: 2, Column: 1},
+			End:   lexer.Position{Filename: "t.go", Offset: 10, Line: 2, Column: 8},
+		},
+	},
+	{
+		Type: lexer.TOKEN_EOF,
+		Span: lexer.Span{
+			Start: lexer.Position{Offset: 10, Line: 2, Column: 8},
+			End:   lexer.Position{Offset: 10, Line: 2, Column: 8},
+		},
+	},
+}
+
+/*
+stringValues returns tokens with their values in string form, as the
+binary formats store them
+*/
+func stringValues(tokens []lexer.Token) []lexer.Token {
+	result := make([]lexer.Token, len(tokens))
+
+	for index, token := range tokens {
+		if token.Value != nil {
+			token.Value = csvValue(token)
+		}
+
+		result[index] = token
+	}
+
+	return result
+}
+
+func compareTokens(t *testing.T, got []lexer.Token, want []lexer.Token) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(got), len(want))
+	}
+
+	for index := range want {
+		if !reflect.DeepEqual(got[index], want[index]) {
+			t.Errorf("token %d: got %#v, want %#v", index, got[index], want[index])
+		}
+	}
+}
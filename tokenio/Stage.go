@@ -0,0 +1,98 @@
+package tokenio
+
+import (
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Stage is an io.ReaderFrom that lexes what it reads and writes the
+serialized tokens to a destination, so tokenizing drops into existing io
+plumbing as one stage of a pipeline:
+
+	stage := tokenio.NewNDJSONStage(os.Stdout, "stdin", calc.Start, calc.Names)
+	_, err := stage.ReadFrom(os.Stdin)
+
+The input is read in chunks as the lexer needs it, as NewReaderLexer
+reads it, so inputs of any size stream through. On the output side,
+TokenReader is an io.Reader and io.WriterTo of serialized tokens. Each
+call to ReadFrom is a lexing pass of its own, written with a new writer.
+*/
+type Stage struct {
+	w         io.Writer
+	name      string
+	startFn   lexer.LexFn
+	newWriter func(w io.Writer) (TokenWriter, error)
+	options   []lexer.Option
+}
+
+/*
+NewStage creates a stage lexing its input from startFn and writing the
+tokens with the writer newWriter creates on w. name names the input in
+token positions.
+*/
+func NewStage(w io.Writer, name string, startFn lexer.LexFn, newWriter func(w io.Writer) (TokenWriter, error), options ...lexer.Option) *Stage {
+	return &Stage{
+		w:         w,
+		name:      name,
+		startFn:   startFn,
+		newWriter: newWriter,
+		options:   options,
+	}
+}
+
+/*
+NewNDJSONStage creates a stage writing tokens as newline delimited JSON,
+as NDJSONWriter writes them
+*/
+func NewNDJSONStage(w io.Writer, name string, startFn lexer.LexFn, names lexer.TokenNames, options ...lexer.Option) *Stage {
+	return NewStage(w, name, startFn, func(w io.Writer) (TokenWriter, error) {
+		return NewNDJSONWriter(w, names), nil
+	}, options...)
+}
+
+/*
+ReadFrom lexes everything read from r, writing the tokens as they are
+produced, and returns the number of bytes read. It stops at the first
+error writing tokens or reading r; reaching the end of r is not an
+error.
+*/
+func (stage *Stage) ReadFrom(r io.Reader) (int64, error) {
+	counter := &countingReader{reader: r}
+
+	writer, err := stage.newWriter(stage.w)
+	if err != nil {
+		return 0, err
+	}
+
+	l := lexer.NewReaderLexer(stage.name, counter, stage.startFn, stage.options...)
+	stream := lexer.NewTokenStream(l)
+
+	for {
+		token := stream.Next()
+
+		if err := writeToken(writer, token); err != nil {
+			return counter.count, err
+		}
+
+		if token.Type == lexer.TOKEN_EOF {
+			return counter.count, l.Err()
+		}
+	}
+}
+
+/*
+countingReader counts the bytes read through it
+*/
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (counter *countingReader) Read(p []byte) (int, error) {
+	n, err := counter.reader.Read(p)
+	counter.count += int64(n)
+
+	return n, err
+}
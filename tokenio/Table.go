@@ -0,0 +1,62 @@
+package tokenio
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+tableValueWidth is the number of characters of a token's value shown
+by DumpTable before it is truncated
+*/
+const tableValueWidth = 40
+
+/*
+DumpTable writes tokens to w as an aligned table of index, span, type
+name and quoted value, for reading during debugging sessions. Long
+values are truncated.
+
+	#  SPAN         TYPE    VALUE
+	0  1:1-1:4      IDENT   "let"
+	1  1:5-1:6      EQUALS  "="
+*/
+func DumpTable(w io.Writer, names lexer.TokenNames, tokens ...lexer.Token) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "#\tSPAN\tTYPE\tVALUE")
+
+	for index, token := range tokens {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", index, tableSpan(token.Span), names.Name(token.Type), tableValue(token))
+	}
+
+	return writer.Flush()
+}
+
+/*
+tableSpan formats a span as line:column-line:column. Filenames are
+left out to keep the table narrow.
+*/
+func tableSpan(span lexer.Span) string {
+	return fmt.Sprintf("%d:%d-%d:%d", span.Start.Line, span.Start.Column, span.End.Line, span.End.Column)
+}
+
+func tableValue(token lexer.Token) string {
+	value := csvValue(token)
+
+	if utf8.RuneCountInString(value) <= tableValueWidth {
+		return strconv.Quote(value)
+	}
+
+	cut := 0
+
+	for count := 0; count < tableValueWidth-1; count++ {
+		_, width := utf8.DecodeRuneInString(value[cut:])
+		cut += width
+	}
+
+	return strconv.Quote(value[:cut]) + "…"
+}
@@ -0,0 +1,167 @@
+package tokenio
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+TreeSitterTypes maps token types to the names of tree-sitter node types,
+such as "identifier" or "string". Tokens of types that are not mapped
+become anonymous nodes named by their text, as tree-sitter names
+keywords and punctuation.
+*/
+type TreeSitterTypes map[lexer.TokenType]string
+
+/*
+TreeSitterPoint is a row and column in a tree-sitter tree, both from 0,
+with the column counted in bytes
+*/
+type TreeSitterPoint struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+}
+
+/*
+TreeSitterNode is a token as a leaf node of a tree-sitter tree, with the
+fields of tree-sitter's node API. Error tokens become named ERROR nodes.
+*/
+type TreeSitterNode struct {
+	Type       string          `json:"type"`
+	Named      bool            `json:"named"`
+	StartByte  int             `json:"start_byte"`
+	EndByte    int             `json:"end_byte"`
+	StartPoint TreeSitterPoint `json:"start_point"`
+	EndPoint   TreeSitterPoint `json:"end_point"`
+}
+
+/*
+ToTreeSitterNodes converts the tokens lexed from source to tree-sitter
+nodes, leaving out EOF and trivia. Rows and byte columns are computed
+from source, so they do not depend on how the lexer counts columns.
+*/
+func ToTreeSitterNodes(source string, types TreeSitterTypes, tokens ...lexer.Token) []TreeSitterNode {
+	points := newPointIndex(source)
+	nodes := []TreeSitterNode{}
+
+	for _, token := range tokens {
+		if token.Type == lexer.TOKEN_EOF || token.Type == lexer.TOKEN_TRIVIA {
+			continue
+		}
+
+		start, end := token.Span.Start.Offset, token.Span.End.Offset
+		node := TreeSitterNode{
+			StartByte:  start,
+			EndByte:    end,
+			StartPoint: points.point(start),
+			EndPoint:   points.point(end),
+		}
+
+		switch name, ok := types[token.Type]; {
+		case ok:
+			node.Type, node.Named = name, true
+
+		case token.Type == lexer.TOKEN_ERROR:
+			node.Type, node.Named = "ERROR", true
+
+		default:
+			node.Type = token.Text
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
+
+/*
+WriteTreeSitterTree writes the tokens lexed from source as the tree
+tree-sitter parse prints, a root node of type root with the named
+tokens as its children, each with its range, for comparing with the
+output of a tree-sitter grammar:
+
+	(source_file [0, 0] - [1, 0]
+	  (identifier [0, 0] - [0, 3])
+	  (number [0, 6] - [0, 8]))
+*/
+func WriteTreeSitterTree(w io.Writer, source string, root string, types TreeSitterTypes, tokens ...lexer.Token) error {
+	end := newPointIndex(source).point(len(source))
+	result := strings.Builder{}
+
+	fmt.Fprintf(&result, "(%s [0, 0] - [%d, %d]", root, end.Row, end.Column)
+
+	for _, node := range ToTreeSitterNodes(source, types, tokens...) {
+		if node.Named {
+			fmt.Fprintf(&result, "\n  (%s [%d, %d] - [%d, %d])", node.Type, node.StartPoint.Row, node.StartPoint.Column, node.EndPoint.Row, node.EndPoint.Column)
+		}
+	}
+
+	result.WriteString(")\n")
+
+	_, err := io.WriteString(w, result.String())
+	return err
+}
+
+/*
+WriteTreeSitterTest writes the tokens lexed from source as a test of a
+tree-sitter corpus file, titled title, with the tree as a root node of
+type root holding the named tokens. Corpus files built this way check
+that a tree-sitter grammar tokenizes as the lexer prototyping it does.
+
+	==================
+	assignment
+	==================
+
+	let x = 42
+
+	---
+
+	(source_file
+	  (identifier)
+	  (number))
+*/
+func WriteTreeSitterTest(w io.Writer, title string, source string, root string, types TreeSitterTypes, tokens ...lexer.Token) error {
+	rule := strings.Repeat("=", 18)
+	result := strings.Builder{}
+
+	fmt.Fprintf(&result, "%s\n%s\n%s\n\n%s\n\n---\n\n(%s", rule, title, rule, strings.TrimRight(source, "\n"), root)
+
+	for _, node := range ToTreeSitterNodes(source, types, tokens...) {
+		if node.Named {
+			fmt.Fprintf(&result, "\n  (%s)", node.Type)
+		}
+	}
+
+	result.WriteString(")\n\n")
+
+	_, err := io.WriteString(w, result.String())
+	return err
+}
+
+/*
+pointIndex converts byte offsets of a source to tree-sitter points
+*/
+type pointIndex struct {
+	lineStarts []int
+}
+
+func newPointIndex(source string) pointIndex {
+	index := pointIndex{lineStarts: []int{0}}
+
+	for offset := 0; offset < len(source); offset++ {
+		if source[offset] == '\n' {
+			index.lineStarts = append(index.lineStarts, offset+1)
+		}
+	}
+
+	return index
+}
+
+func (index pointIndex) point(offset int) TreeSitterPoint {
+	row := sort.Search(len(index.lineStarts), func(i int) bool { return index.lineStarts[i] > offset }) - 1
+	return TreeSitterPoint{Row: row, Column: offset - index.lineStarts[row]}
+}
@@ -0,0 +1,149 @@
+package tokenio
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+XMLWriter writes a token stream as an XML document with one element
+per token:
+
+	<tokens name="input.txt">
+	  <token type="IDENT" type-id="3" file="input.txt" start="0" end="3" line="1" column="1" end-line="1" end-column="4">let</token>
+	</tokens>
+
+The element text is the token's text. Transformed values are written
+in a value attribute in their string form.
+*/
+type XMLWriter struct {
+	w       *bufio.Writer
+	encoder *xml.Encoder
+	names   lexer.TokenNames
+	started bool
+	name    string
+}
+
+type xmlToken struct {
+	XMLName   xml.Name `xml:"token"`
+	Type      string   `xml:"type,attr"`
+	TypeID    int      `xml:"type-id,attr"`
+	File      string   `xml:"file,attr,omitempty"`
+	Start     int      `xml:"start,attr"`
+	End       int      `xml:"end,attr"`
+	Line      int      `xml:"line,attr"`
+	Column    int      `xml:"column,attr"`
+	EndLine   int      `xml:"end-line,attr"`
+	EndColumn int      `xml:"end-column,attr"`
+	Value     *string  `xml:"value,attr"`
+	Text      string   `xml:",chardata"`
+}
+
+/*
+NewXMLWriter creates a writer for a document whose root element is
+named after the lexer name given
+*/
+func NewXMLWriter(w io.Writer, name string, names lexer.TokenNames) *XMLWriter {
+	buffered := bufio.NewWriter(w)
+	encoder := xml.NewEncoder(buffered)
+	encoder.Indent("", "  ")
+
+	return &XMLWriter{
+		w:       buffered,
+		encoder: encoder,
+		names:   names,
+		name:    name,
+	}
+}
+
+/*
+Write writes a single token element, preceded by the XML declaration
+and root element on the first call
+*/
+func (writer *XMLWriter) Write(token lexer.Token) error {
+	if err := writer.start(); err != nil {
+		return err
+	}
+
+	element := xmlToken{
+		Type:      writer.names.Name(token.Type),
+		TypeID:    int(token.Type),
+		File:      token.Span.Start.Filename,
+		Start:     token.Span.Start.Offset,
+		End:       token.Span.End.Offset,
+		Line:      token.Span.Start.Line,
+		Column:    token.Span.Start.Column,
+		EndLine:   token.Span.End.Line,
+		EndColumn: token.Span.End.Column,
+		Text:      token.Text,
+	}
+
+	if token.Value != nil {
+		value := csvValue(token)
+		element.Value = &value
+	}
+
+	return writer.encoder.Encode(element)
+}
+
+/*
+Close ends the document and flushes it. It does not close the
+underlying writer.
+*/
+func (writer *XMLWriter) Close() error {
+	if err := writer.start(); err != nil {
+		return err
+	}
+
+	if err := writer.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "tokens"}}); err != nil {
+		return err
+	}
+
+	if err := writer.encoder.Flush(); err != nil {
+		return err
+	}
+
+	if _, err := writer.w.WriteString("\n"); err != nil {
+		return err
+	}
+
+	return writer.w.Flush()
+}
+
+func (writer *XMLWriter) start() error {
+	if writer.started {
+		return nil
+	}
+
+	writer.started = true
+
+	if _, err := writer.w.WriteString(xml.Header); err != nil {
+		return err
+	}
+
+	root := xml.StartElement{Name: xml.Name{Local: "tokens"}}
+
+	if writer.name != "" {
+		root.Attr = append(root.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: writer.name})
+	}
+
+	return writer.encoder.EncodeToken(root)
+}
+
+/*
+WriteXML writes tokens to w as a complete XML document
+*/
+func WriteXML(w io.Writer, name string, names lexer.TokenNames, tokens ...lexer.Token) error {
+	writer := NewXMLWriter(w, name, names)
+
+	for _, token := range tokens {
+		if err := writer.Write(token); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,80 @@
+package tokenio
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/presets/golike"
+)
+
+func TestWriteXML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXML(&buf, "t.go", golike.Names, stringTokens...); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<tokens name="t.go">
+  <token type="STRING" type-id="6" file="t.go" start="3" end="10" line="2" column="1" end-line="2" end-column="8" value="&lt;a&gt;&#x9;">&#34;&lt;a&gt;\t&#34;</token>
+  <token type="EOF" type-id="-1" start="10" end="10" line="2" column="8" end-line="2" end-column="8"></token>
+</tokens>
+`
+
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteXMLEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXML(&buf, "", golike.Names); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := buf.String(), xml.Header+"<tokens></tokens>\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestXMLRoundTrip(t *testing.T) {
+	tokens, _ := sample(t)
+
+	var buf bytes.Buffer
+	if err := WriteXML(&buf, "sample.go", golike.Names, tokens...); err != nil {
+		t.Fatal(err)
+	}
+
+	var document struct {
+		Name   string     `xml:"name,attr"`
+		Tokens []xmlToken `xml:"token"`
+	}
+
+	if err := xml.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+
+	if document.Name != "sample.go" {
+		t.Errorf("got name %q, want sample.go", document.Name)
+	}
+
+	got := make([]lexer.Token, len(document.Tokens))
+
+	for index, element := range document.Tokens {
+		got[index] = lexer.Token{
+			Type: lexer.TokenType(element.TypeID),
+			Text: element.Text,
+			Span: lexer.Span{
+				Start: lexer.Position{Filename: element.File, Offset: element.Start, Line: element.Line, Column: element.Column},
+				End:   lexer.Position{Filename: element.File, Offset: element.End, Line: element.EndLine, Column: element.EndColumn},
+			},
+		}
+
+		if element.Value != nil {
+			got[index].Value = *element.Value
+		}
+	}
+
+	compareTokens(t, got, stringValues(tokens))
+}
@@ -0,0 +1,206 @@
+//go:build grpc
+
+package tokenrpc
+
+import (
+	"fmt"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/tokenio"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+/*
+LexRequest is the LexRequest message of lexer.proto
+*/
+type LexRequest struct {
+	Language string
+	Name     string
+	Source   []byte
+}
+
+/*
+LexEvent is the LexEvent message of lexer.proto, holding either a token
+or a diagnostic. Names names token types when the event is encoded; the
+name is not decoded.
+*/
+type LexEvent struct {
+	Token      *lexer.Token
+	Diagnostic *lexer.LexError
+	Names      lexer.TokenNames
+}
+
+/*
+Marshal encodes the request
+*/
+func (request *LexRequest) Marshal() ([]byte, error) {
+	var buf []byte
+
+	if request.Language != "" {
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendString(buf, request.Language)
+	}
+
+	if request.Name != "" {
+		buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+		buf = protowire.AppendString(buf, request.Name)
+	}
+
+	if len(request.Source) > 0 {
+		buf = protowire.AppendTag(buf, 3, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, request.Source)
+	}
+
+	return buf, nil
+}
+
+/*
+Unmarshal decodes a request, replacing the fields of request
+*/
+func (request *LexRequest) Unmarshal(data []byte) error {
+	*request = LexRequest{}
+
+	return eachField(data, func(field protowire.Number, wireType protowire.Type, bytes []byte) {
+		if wireType != protowire.BytesType {
+			return
+		}
+
+		switch field {
+		case 1:
+			request.Language = string(bytes)
+
+		case 2:
+			request.Name = string(bytes)
+
+		case 3:
+			request.Source = append([]byte(nil), bytes...)
+		}
+	})
+}
+
+/*
+Marshal encodes the event
+*/
+func (event *LexEvent) Marshal() ([]byte, error) {
+	var buf []byte
+
+	switch {
+	case event.Token != nil:
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, tokenio.AppendProtoToken(nil, event.Names, *event.Token))
+
+	case event.Diagnostic != nil:
+		buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, tokenio.AppendProtoDiagnostic(nil, *event.Diagnostic))
+	}
+
+	return buf, nil
+}
+
+/*
+Unmarshal decodes an event, replacing the fields of event
+*/
+func (event *LexEvent) Unmarshal(data []byte) error {
+	var err error
+	*event = LexEvent{}
+
+	fieldErr := eachField(data, func(field protowire.Number, wireType protowire.Type, bytes []byte) {
+		if wireType != protowire.BytesType || err != nil {
+			return
+		}
+
+		switch field {
+		case 1:
+			token, tokenErr := tokenio.UnmarshalProtoToken(bytes)
+			event.Token, event.Diagnostic, err = &token, nil, tokenErr
+
+		case 2:
+			diagnostic, diagnosticErr := tokenio.UnmarshalProtoDiagnostic(bytes)
+			event.Token, event.Diagnostic, err = nil, &diagnostic, diagnosticErr
+		}
+	})
+
+	if fieldErr != nil {
+		return fieldErr
+	}
+
+	return err
+}
+
+/*
+eachField calls fn for every field of an encoded message, with the
+contents of length delimited fields
+*/
+func eachField(data []byte, fn func(field protowire.Number, wireType protowire.Type, bytes []byte)) error {
+	for len(data) > 0 {
+		field, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return tokenio.ErrInvalidProtobuf
+		}
+
+		data = data[n:]
+
+		var bytes []byte
+
+		if wireType == protowire.BytesType {
+			bytes, n = protowire.ConsumeBytes(data)
+		} else {
+			n = protowire.ConsumeFieldValue(field, wireType, data)
+		}
+
+		if n < 0 {
+			return tokenio.ErrInvalidProtobuf
+		}
+
+		data = data[n:]
+		fn(field, wireType, bytes)
+	}
+
+	return nil
+}
+
+/*
+Codec is the gRPC codec of the service. It encodes the messages of this
+package by hand and any other protobuf message as usual, so the service
+shares a server with generated ones. Pass it to grpc.NewServer with
+ServerOption.
+*/
+type Codec struct{}
+
+/*
+Name returns "proto", as the codec speaks the protobuf wire format
+*/
+func (Codec) Name() string {
+	return "proto"
+}
+
+/*
+Marshal encodes a message
+*/
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	switch message := v.(type) {
+	case interface{ Marshal() ([]byte, error) }:
+		return message.Marshal()
+
+	case proto.Message:
+		return proto.Marshal(message)
+	}
+
+	return nil, fmt.Errorf("tokenrpc: can not marshal %T", v)
+}
+
+/*
+Unmarshal decodes a message into v
+*/
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	switch message := v.(type) {
+	case interface{ Unmarshal(data []byte) error }:
+		return message.Unmarshal(data)
+
+	case proto.Message:
+		return proto.Unmarshal(data, message)
+	}
+
+	return fmt.Errorf("tokenrpc: can not unmarshal %T", v)
+}
@@ -0,0 +1,247 @@
+//go:build grpc
+
+/*
+Package tokenrpc serves lexers over gRPC, so teams working in several
+languages can share one tokenizer for a language of their own. The
+service is described by lexer.proto in this directory; clients generate
+their stubs from it, while the Go side encodes its messages by hand and
+needs no generated code. The package is built with the grpc build tag,
+so the rest of the module does not depend on gRPC:
+
+	go build -tags grpc
+
+A server registers its languages and the service:
+
+	lexers := tokenrpc.NewServer().
+		Register("calc", tokenrpc.Language{Start: calc.Start, Names: calc.Names})
+
+	server := grpc.NewServer(tokenrpc.ServerOption())
+	lexers.RegisterService(server)
+	server.Serve(listener)
+
+Lex takes a whole source text and LexChunks a text sent in chunks, and
+both stream back LexEvent messages, each holding a token or a
+diagnostic, as they are found. Errors come back as diagnostics only, not
+as error tokens as well.
+*/
+package tokenrpc
+
+import (
+	"github.com/adampresley/lexer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/*
+Language describes a lexer the server offers
+*/
+type Language struct {
+	Start   lexer.LexFn
+	Names   lexer.TokenNames
+	Options []lexer.Option
+}
+
+/*
+LexerServer is the interface of the Lexer service, which Server
+implements
+*/
+type LexerServer interface {
+	Lex(request *LexRequest, stream grpc.ServerStream) error
+	LexChunks(stream grpc.ServerStream) error
+}
+
+/*
+ServiceDesc describes the Lexer service of lexer.proto to gRPC
+*/
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "adampresley.lexer.rpc.Lexer",
+	HandlerType: (*LexerServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Lex",
+			Handler:       lexHandler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "LexChunks",
+			Handler:       lexChunksHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "tokenrpc/lexer.proto",
+}
+
+/*
+ServerOption returns the option grpc.NewServer needs to encode the
+messages of the service
+*/
+func ServerOption() grpc.ServerOption {
+	return grpc.ForceServerCodec(Codec{})
+}
+
+/*
+Server implements the Lexer service for the languages registered with
+it
+*/
+type Server struct {
+	languages map[string]Language
+}
+
+/*
+NewServer creates a server without languages
+*/
+func NewServer() *Server {
+	return &Server{
+		languages: map[string]Language{},
+	}
+}
+
+/*
+Register offers language under name. Registering a name again replaces
+its language.
+*/
+func (server *Server) Register(name string, language Language) *Server {
+	server.languages[name] = language
+	return server
+}
+
+/*
+RegisterService registers the service with a gRPC server
+*/
+func (server *Server) RegisterService(registrar grpc.ServiceRegistrar) {
+	registrar.RegisterService(&ServiceDesc, server)
+}
+
+/*
+Lex streams the tokens and diagnostics of the source of request
+*/
+func (server *Server) Lex(request *LexRequest, stream grpc.ServerStream) error {
+	language, err := server.language(request.Language)
+	if err != nil {
+		return err
+	}
+
+	return server.send(stream, language, func(options []lexer.Option) *lexer.Lexer {
+		return lexer.NewLexer(request.Name, string(request.Source), language.Start, options...)
+	})
+}
+
+/*
+LexChunks streams the tokens and diagnostics of a source sent in chunks.
+Tokens are sent as soon as the chunks received complete them.
+*/
+func (server *Server) LexChunks(stream grpc.ServerStream) error {
+	first := &LexRequest{}
+
+	if err := stream.RecvMsg(first); err != nil {
+		return err
+	}
+
+	language, err := server.language(first.Language)
+	if err != nil {
+		return err
+	}
+
+	reader := &chunkReader{stream: stream, chunk: first.Source}
+
+	return server.send(stream, language, func(options []lexer.Option) *lexer.Lexer {
+		return lexer.NewReaderLexer(first.Name, reader, language.Start, options...)
+	})
+}
+
+/*
+language returns the language registered under name. An empty name
+selects the only language when there is just one.
+*/
+func (server *Server) language(name string) (Language, error) {
+	if name == "" && len(server.languages) == 1 {
+		for _, language := range server.languages {
+			return language, nil
+		}
+	}
+
+	language, ok := server.languages[name]
+	if !ok {
+		return Language{}, status.Errorf(codes.NotFound, "tokenrpc: unknown language %q", name)
+	}
+
+	return language, nil
+}
+
+/*
+send lexes with the lexer newLexer creates from the given options,
+sending each diagnostic and token as an event. It stops at the first
+error sending, such as when the client goes away.
+*/
+func (server *Server) send(stream grpc.ServerStream, language Language, newLexer func(options []lexer.Option) *lexer.Lexer) error {
+	var diagnostics []lexer.LexError
+
+	options := append([]lexer.Option{}, language.Options...)
+	options = append(options, lexer.WithoutErrorTokens(), lexer.WithErrorHandler(func(err lexer.LexError) {
+		diagnostics = append(diagnostics, err)
+	}))
+
+	l := newLexer(options)
+	tokens := lexer.NewTokenStream(l)
+
+	for {
+		token := tokens.Next()
+
+		for index := range diagnostics {
+			if err := stream.SendMsg(&LexEvent{Diagnostic: &diagnostics[index]}); err != nil {
+				return err
+			}
+		}
+
+		diagnostics = diagnostics[:0]
+
+		if err := stream.SendMsg(&LexEvent{Token: &token, Names: language.Names}); err != nil {
+			return err
+		}
+
+		if token.Type == lexer.TOKEN_EOF {
+			return l.Err()
+		}
+	}
+}
+
+func lexHandler(srv interface{}, stream grpc.ServerStream) error {
+	request := &LexRequest{}
+
+	if err := stream.RecvMsg(request); err != nil {
+		return err
+	}
+
+	return srv.(LexerServer).Lex(request, stream)
+}
+
+func lexChunksHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LexerServer).LexChunks(stream)
+}
+
+/*
+chunkReader reads the source chunks of a LexChunks call
+*/
+type chunkReader struct {
+	stream grpc.ServerStream
+	chunk  []byte
+}
+
+func (reader *chunkReader) Read(p []byte) (int, error) {
+	for len(reader.chunk) == 0 {
+		request := &LexRequest{}
+
+		if err := reader.stream.RecvMsg(request); err != nil {
+			return 0, err
+		}
+
+		reader.chunk = request.Source
+	}
+
+	n := copy(p, reader.chunk)
+	reader.chunk = reader.chunk[n:]
+
+	return n, nil
+}
@@ -0,0 +1,206 @@
+//go:build js && wasm
+
+/*
+Package wasm exposes lexers to JavaScript when a program is compiled
+with GOOS=js GOARCH=wasm, for live playgrounds of a language in the
+browser. Everything runs on the calling JavaScript thread through
+callbacks; no goroutines or channels are involved, so lexing a buffer on
+every keystroke costs no more than the lexing itself.
+
+A program registers its languages and then waits, so the functions stay
+callable:
+
+	func main() {
+		wasm.Register("calc", wasm.Language{
+			Start:      calc.Start,
+			Names:      calc.Names,
+			Categories: calc.Categories,
+		})
+
+		wasm.Wait()
+	}
+
+after which JavaScript calls
+
+	calc.lex(source, token => console.log(token.type, token.text), error => console.error(error.message))
+	const tokens = calc.tokens(source)
+	editor.innerHTML = calc.highlight(source)
+
+Tokens are plain objects with the fields type, typeId, text, start and
+end, the last two holding offset, line and column. Errors have the
+fields message, severity, code, start and end. The example directory
+holds a complete playground.
+*/
+package wasm
+
+import (
+	"strings"
+	"syscall/js"
+
+	"github.com/adampresley/lexer"
+	"github.com/adampresley/lexer/highlight"
+)
+
+/*
+Language describes a lexer to expose to JavaScript. Names and Categories
+are optional; without Names token types are reported by number, and
+without Categories highlight leaves all text unstyled.
+*/
+type Language struct {
+	Start      lexer.LexFn
+	Names      lexer.TokenNames
+	Categories highlight.Categories
+	Options    []lexer.Option
+}
+
+/*
+Register sets a global JavaScript object named name with the functions
+lex, tokens and highlight for language
+*/
+func Register(name string, language Language) {
+	js.Global().Set(name, language.Object())
+}
+
+/*
+Wait blocks forever, keeping a program's registered functions callable
+after main would otherwise return
+*/
+func Wait() {
+	select {}
+}
+
+/*
+Object returns the JavaScript object Register sets, for programs that
+place it somewhere other than a global
+*/
+func (language Language) Object() js.Value {
+	return js.ValueOf(map[string]interface{}{
+		"lex":       js.FuncOf(language.lex),
+		"tokens":    js.FuncOf(language.tokens),
+		"highlight": js.FuncOf(language.highlight),
+	})
+}
+
+/*
+Lex lexes input, calling onToken with each token and onError with each
+error as they are found. Either callback may be nil.
+*/
+func (language Language) Lex(input string, onToken func(token lexer.Token), onError func(err lexer.LexError)) {
+	options := append([]lexer.Option{}, language.Options...)
+
+	if onError != nil {
+		options = append(options, lexer.WithErrorHandler(onError))
+	}
+
+	lexer.NewLexer("playground", input, language.Start, options...).RunWith(func(token lexer.Token) {
+		if onToken != nil {
+			onToken(token)
+		}
+	})
+}
+
+/*
+lex implements lex(input, onToken, onError) for JavaScript
+*/
+func (language Language) lex(this js.Value, args []js.Value) interface{} {
+	onToken, onError := callback(args, 1), callback(args, 2)
+
+	language.Lex(argument(args, 0), func(token lexer.Token) {
+		if onToken.Truthy() {
+			onToken.Invoke(language.tokenObject(token))
+		}
+	}, func(err lexer.LexError) {
+		if onError.Truthy() {
+			onError.Invoke(errorObject(err))
+		}
+	})
+
+	return nil
+}
+
+/*
+tokens implements tokens(input) for JavaScript, returning an array of
+all the tokens of input
+*/
+func (language Language) tokens(this js.Value, args []js.Value) interface{} {
+	result := []interface{}{}
+
+	language.Lex(argument(args, 0), func(token lexer.Token) {
+		result = append(result, language.tokenObject(token))
+	}, nil)
+
+	return js.ValueOf(result)
+}
+
+/*
+highlight implements highlight(input) for JavaScript, returning input as
+HTML, as highlight.HTML renders it
+*/
+func (language Language) highlight(this js.Value, args []js.Value) interface{} {
+	input := argument(args, 0)
+	tokens := []lexer.Token{}
+
+	language.Lex(input, func(token lexer.Token) {
+		tokens = append(tokens, token)
+	}, nil)
+
+	result := strings.Builder{}
+	renderer := highlight.HTML{Categories: language.Categories, Names: language.Names}
+
+	if err := renderer.Render(&result, input, tokens); err != nil {
+		return js.Global().Get("Error").New(err.Error())
+	}
+
+	return result.String()
+}
+
+func (language Language) tokenObject(token lexer.Token) map[string]interface{} {
+	return map[string]interface{}{
+		"type":   language.Names.Name(token.Type),
+		"typeId": int(token.Type),
+		"text":   token.Text,
+		"start":  positionObject(token.Span.Start),
+		"end":    positionObject(token.Span.End),
+	}
+}
+
+func errorObject(err lexer.LexError) map[string]interface{} {
+	return map[string]interface{}{
+		"message":  err.Message,
+		"severity": err.Severity.String(),
+		"code":     err.Code,
+		"start":    positionObject(err.Span.Start),
+		"end":      positionObject(err.Span.End),
+	}
+}
+
+func positionObject(position lexer.Position) map[string]interface{} {
+	return map[string]interface{}{
+		"offset": position.Offset,
+		"line":   position.Line,
+		"column": position.Column,
+	}
+}
+
+/*
+argument returns the string argument at index, or "" when it is missing
+*/
+func argument(args []js.Value, index int) string {
+	if index >= len(args) || args[index].Type() != js.TypeString {
+		return ""
+	}
+
+	return args[index].String()
+}
+
+/*
+callback returns the function argument at index, or undefined when it is
+missing
+*/
+func callback(args []js.Value, index int) js.Value {
+	if index >= len(args) || args[index].Type() != js.TypeFunction {
+		return js.Undefined()
+	}
+
+	return args[index]
+}
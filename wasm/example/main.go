@@ -0,0 +1,29 @@
+//go:build js && wasm
+
+/*
+Command example is a playground for the calc preset running in the
+browser. Build it and copy the JavaScript support file next to it, then
+serve the directory:
+
+	GOOS=js GOARCH=wasm go build -o playground.wasm .
+	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+
+index.html loads playground.wasm and highlights the expression typed
+into it, listing the lexer's errors below.
+*/
+package main
+
+import (
+	"github.com/adampresley/lexer/presets/calc"
+	"github.com/adampresley/lexer/wasm"
+)
+
+func main() {
+	wasm.Register("calc", wasm.Language{
+		Start:      calc.Start,
+		Names:      calc.Names,
+		Categories: calc.Categories,
+	})
+
+	wasm.Wait()
+}
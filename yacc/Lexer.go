@@ -0,0 +1,144 @@
+/*
+Package yacc feeds the tokens of a lexer to parsers generated by goyacc.
+
+goyacc declares the lexer a parser reads from as an interface of its own
+package, yyLexer, whose Lex method takes a pointer to the parser's value
+type, yySymType. Since that type differs from parser to parser, Lexer
+can not implement the interface itself, but does everything short of
+filling in the value, so the parser's package needs only a few lines:
+
+	type yyLex struct {
+		*yacc.Lexer
+	}
+
+	func (l yyLex) Lex(lval *yySymType) int {
+		token, code := l.Next()
+		lval.token = token
+		return code
+	}
+
+	...
+	l := lexer.NewLexer(name, input, calc.Start)
+	adapter := yacc.NewLexer(l, map[lexer.TokenType]int{calc.TOKEN_NUMBER: NUMBER}, calc.TOKEN_SPACE)
+
+	yyParse(yyLex{adapter})
+
+	if err := adapter.Err(); err != nil {
+		...
+	}
+*/
+package yacc
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/adampresley/lexer"
+)
+
+/*
+Lexer hands out the tokens of a token stream with the token codes of a
+goyacc parser. Codes are looked up in the map given to NewLexer. A token
+whose type is not in it and whose text is a single character gets the
+character as its code, which is how yacc grammars refer to literal
+tokens such as '+', so only named tokens need mapping. End of input is
+code 0.
+
+Error tokens are not passed to the parser. Their messages, and those
+the parser reports through Error, are kept in order and returned by
+Errors and Err.
+*/
+type Lexer struct {
+	stream *lexer.TokenStream
+	codes  map[lexer.TokenType]int
+	last   lexer.Token
+	errors []lexer.LexError
+}
+
+/*
+NewLexer creates an adapter over the tokens of l, leaving out tokens of
+the types in skip, such as whitespace and comments. codes maps token
+types to the token constants goyacc generated.
+*/
+func NewLexer(l *lexer.Lexer, codes map[lexer.TokenType]int, skip ...lexer.TokenType) *Lexer {
+	return NewStreamLexer(lexer.NewTokenStream(l, skip...), codes)
+}
+
+/*
+NewStreamLexer creates an adapter over a token stream a caller has
+already set up, possibly after reading some of it
+*/
+func NewStreamLexer(stream *lexer.TokenStream, codes map[lexer.TokenType]int) *Lexer {
+	return &Lexer{
+		stream: stream,
+		codes:  codes,
+	}
+}
+
+/*
+Next returns the next token for the parser and its code. Tokens whose
+type has no code and that are not a single character are reported as
+errors and skipped.
+*/
+func (adapter *Lexer) Next() (lexer.Token, int) {
+	for {
+		token := adapter.stream.Next()
+		adapter.last = token
+
+		switch {
+		case token.Type == lexer.TOKEN_EOF:
+			return token, 0
+
+		case token.Type == lexer.TOKEN_ERROR:
+			adapter.errors = append(adapter.errors, lexer.LexError{Message: token.Text, Span: token.Span, Severity: lexer.SEVERITY_ERROR})
+			continue
+		}
+
+		if code, ok := adapter.codes[token.Type]; ok {
+			return token, code
+		}
+
+		if ch, width := utf8.DecodeRuneInString(token.Text); width > 0 && width == len(token.Text) {
+			return token, int(ch)
+		}
+
+		adapter.errors = append(adapter.errors, lexer.LexError{Message: "no parser token for " + strconv.Quote(token.Text), Span: token.Span, Severity: lexer.SEVERITY_ERROR})
+	}
+}
+
+/*
+Error records a message from the parser, such as "syntax error", at the
+span of the last token handed out. It implements the Error method of
+yyLexer.
+*/
+func (adapter *Lexer) Error(message string) {
+	adapter.errors = append(adapter.errors, lexer.LexError{Message: message, Span: adapter.last.Span, Severity: lexer.SEVERITY_ERROR})
+}
+
+/*
+Last returns the last token handed out, for parser actions that need
+its position
+*/
+func (adapter *Lexer) Last() lexer.Token {
+	return adapter.last
+}
+
+/*
+Errors returns the errors of the lexer and the parser, in the order
+they happened
+*/
+func (adapter *Lexer) Errors() []lexer.LexError {
+	return adapter.errors
+}
+
+/*
+Err returns the first error of the lexer or the parser, or nil if there
+was none
+*/
+func (adapter *Lexer) Err() error {
+	if len(adapter.errors) == 0 {
+		return nil
+	}
+
+	return adapter.errors[0]
+}